@@ -4,11 +4,16 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/sllt/kite/pkg/kite/cli/bootstrap"
+	"github.com/sllt/kite/pkg/kite/cli/configprint"
 	"github.com/sllt/kite/pkg/kite/cli/create"
 	"github.com/sllt/kite/pkg/kite/cli/migration"
+	"github.com/sllt/kite/pkg/kite/cli/replay"
+	"github.com/sllt/kite/pkg/kite/cli/upgrade"
 	"github.com/sllt/kite/pkg/kite/cli/wrap"
+	"github.com/sllt/kite/pkg/kite/version"
 	"github.com/urfave/cli/v3"
 )
 
@@ -26,12 +31,18 @@ func main() {
 						Name: "project-name",
 					},
 				},
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{
+						Name:  "with",
+						Usage: "Generate deployment files for these components: docker, k8s, helm, postgres, mysql, redis",
+					},
+				},
 				Action: func(ctx context.Context, cmd *cli.Command) error {
 					name := cmd.StringArg("project-name")
 					if name == "" {
 						return fmt.Errorf("please provide a project name, e.g.: kite init myproject")
 					}
-					return bootstrap.Create(name)
+					return bootstrap.Create(name, cmd.StringSlice("with")...)
 				},
 			},
 			{
@@ -90,6 +101,200 @@ func main() {
 							return nil
 						},
 					},
+					{
+						Name:  "up",
+						Usage: "Run the pending migrations of the project, e.g. kite migrate up --only sql --tag schema",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "dir",
+								Usage: "Project directory (default: current directory)",
+								Value: ".",
+							},
+							&cli.StringSliceFlag{
+								Name:  "only",
+								Usage: "Run only the migrations of these datasources, e.g. sql, mongo, cassandra or redis",
+							},
+							&cli.StringSliceFlag{
+								Name:  "tag",
+								Usage: "Run only the migrations with these tags, e.g. schema or data",
+							},
+						},
+						Action: func(ctx context.Context, cmd *cli.Command) error {
+							return migration.Up(cmd.String("dir"), cmd.StringSlice("only"), cmd.StringSlice("tag"))
+						},
+					},
+				},
+			},
+			{
+				Name:  "config",
+				Usage: "Inspect the configuration of the project",
+				Commands: []*cli.Command{
+					{
+						Name:  "print",
+						Usage: "Print the effective configuration, with secrets masked, as logged by the application at startup",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "dir",
+								Usage: "Project directory (default: current directory)",
+								Value: ".",
+							},
+							&cli.BoolFlag{
+								Name:  "json",
+								Usage: "Print the report as JSON",
+							},
+						},
+						Action: func(ctx context.Context, cmd *cli.Command) error {
+							result, err := configprint.Print(cmd.String("dir"), cmd.Bool("json"))
+							if err != nil {
+								return err
+							}
+							fmt.Println(result)
+							return nil
+						},
+					},
+				},
+			},
+			{
+				Name:  "replay",
+				Usage: "Resend the requests captured by the request capture of an application to a local build",
+				Arguments: []cli.Argument{
+					&cli.StringArg{
+						Name: "file",
+					},
+				},
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "target",
+						Usage: "Base URL of the application the requests are sent to",
+						Value: "http://localhost:8000",
+					},
+					&cli.StringSliceFlag{
+						Name:  "header",
+						Usage: "Header set on every request, e.g. \"Authorization: Bearer <token>\" for the masked credentials",
+					},
+					&cli.DurationFlag{
+						Name:  "timeout",
+						Usage: "Timeout of each request",
+						Value: 30 * time.Second,
+					},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					file := cmd.StringArg("file")
+					if file == "" {
+						return fmt.Errorf("please provide the file of the captured requests, e.g.: kite replay captured.jsonl")
+					}
+					result, err := replay.Replay(ctx, file, replay.Options{
+						Target:  cmd.String("target"),
+						Headers: cmd.StringSlice("header"),
+						Timeout: cmd.Duration("timeout"),
+					})
+					if result != "" {
+						fmt.Println(result)
+					}
+					return err
+				},
+			},
+			{
+				Name:  "upgrade",
+				Usage: "Upgrade the kite version of the project and apply the codemods for breaking changes",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "to",
+						Usage: "Version to upgrade to (default: latest)",
+					},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					result, err := upgrade.Upgrade(".", cmd.String("to"))
+					if err != nil {
+						return err
+					}
+					fmt.Println(result)
+					return nil
+				},
+			},
+			{
+				Name:  "version",
+				Usage: "Print the CLI version and the kite version it was built with",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "check",
+						Usage: "Warn when the kite version of the project differs from the one of the CLI",
+					},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					fmt.Printf("kite-cli %s (kite %s)\n", CLIVersion, version.Framework)
+					if !cmd.Bool("check") {
+						return nil
+					}
+					warning, err := upgrade.CheckVersion(".", version.Framework)
+					if err != nil {
+						return err
+					}
+					if warning != "" {
+						fmt.Fprintln(os.Stderr, warning)
+					}
+					return nil
+				},
+			},
+			{
+				Name:  "generate",
+				Usage: "Generate Kite code from API specifications",
+				Commands: []*cli.Command{
+					{
+						Name:  "server",
+						Usage: "Generate a Kite REST server from an OpenAPI spec: types, handler interface, routes and stubs",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "spec",
+								Usage:    "Path to the OpenAPI spec file, in JSON",
+								Required: true,
+							},
+							&cli.StringFlag{
+								Name:  "out",
+								Usage: "Output directory, whose name is the package name",
+								Value: "api",
+							},
+							wrapForceFlag(),
+							wrapDiffFlag(),
+						},
+						Action: func(ctx context.Context, cmd *cli.Command) error {
+							result, err := wrap.BuildRESTKiteServer(cmd.String("spec"), cmd.String("out"), wrapOptions(cmd))
+							if err != nil {
+								return err
+							}
+							fmt.Println(result)
+							return nil
+						},
+					},
+				},
+			},
+			{
+				Name:  "proto",
+				Usage: "Check proto files",
+				Commands: []*cli.Command{
+					{
+						Name:  "check",
+						Usage: "Lint a proto file and detect the changes breaking its generated clients and servers",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "proto",
+								Usage:    "Path to the proto file",
+								Required: true,
+							},
+							&cli.StringFlag{
+								Name:  "against",
+								Usage: "Previous version to detect breaking changes against, a git revision (git:main) or a file",
+							},
+						},
+						Action: func(ctx context.Context, cmd *cli.Command) error {
+							result, err := wrap.CheckProto(cmd.String("proto"), cmd.String("against"))
+							if err != nil {
+								return err
+							}
+							fmt.Println(result)
+							return nil
+						},
+					},
 				},
 			},
 			{
@@ -113,11 +318,14 @@ func main() {
 										Name:  "out",
 										Usage: "Output directory (default: same as proto file)",
 									},
+									wrapForceFlag(),
+									wrapDiffFlag(),
+									wrapAgainstFlag(),
 								},
 								Action: func(ctx context.Context, cmd *cli.Command) error {
 									protoPath := cmd.String("proto")
 									outDir := cmd.String("out")
-									result, err := wrap.BuildGRPCKiteServer(protoPath, outDir)
+									result, err := wrap.BuildGRPCKiteServer(protoPath, outDir, wrapOptions(cmd))
 									if err != nil {
 										return err
 									}
@@ -138,11 +346,14 @@ func main() {
 										Name:  "out",
 										Usage: "Output directory (default: same as proto file)",
 									},
+									wrapForceFlag(),
+									wrapDiffFlag(),
+									wrapAgainstFlag(),
 								},
 								Action: func(ctx context.Context, cmd *cli.Command) error {
 									protoPath := cmd.String("proto")
 									outDir := cmd.String("out")
-									result, err := wrap.BuildGRPCKiteClient(protoPath, outDir)
+									result, err := wrap.BuildGRPCKiteClient(protoPath, outDir, wrapOptions(cmd))
 									if err != nil {
 										return err
 									}
@@ -187,3 +398,32 @@ func newCreateCommand(createType, usage string) *cli.Command {
 		},
 	}
 }
+
+func wrapForceFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "force",
+		Usage: "Overwrite existing server files even if hand-written code would be lost",
+	}
+}
+
+func wrapDiffFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "diff",
+		Usage: "Preview the changes as a diff without writing any file",
+	}
+}
+
+func wrapAgainstFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "against",
+		Usage: "Check the proto file against a previous version, e.g. git:main, before generating the code",
+	}
+}
+
+func wrapOptions(cmd *cli.Command) wrap.GenerateOptions {
+	return wrap.GenerateOptions{
+		Force:   cmd.Bool("force"),
+		Diff:    cmd.Bool("diff"),
+		Against: cmd.String("against"),
+	}
+}
@@ -44,6 +44,11 @@ export const navigation = [
                 href: "/docs/advanced-guide/using-cron",
                 desc: "Learn how to schedule and manage cron jobs in your application for automated tasks and background processes with Kite's CRON job management."
             },
+            {
+                title: 'In-Process Events',
+                href: '/docs/advanced-guide/in-process-events',
+                desc: "Learn how to decouple the modules of a service with typed events published on the in-process event bus, and how to share them with other services through PubSub."
+            },
             {
                 title: 'Overriding Default',
                 href: '/docs/advanced-guide/overriding-default',
@@ -89,6 +94,11 @@ export const navigation = [
                 href: '/docs/advanced-guide/rbac',
                 desc: "Implement comprehensive Role-Based Access Control with support for roles, permissions, hierarchy, JWT integration, and fine-grained permission-based authorization."
             },
+            {
+                title: 'Multi-Tenancy',
+                href: '/docs/advanced-guide/multi-tenancy',
+                desc: "Resolve the tenant of each request from its host, header or path, and route the SQL queries of each tenant to its own database or schema."
+            },
             {
                 title: 'Circuit Breaker Support',
                 href: '/docs/advanced-guide/circuit-breaker',
@@ -162,7 +172,7 @@ export const navigation = [
             {
                 title: 'Profiling in Kite Applications',
                 href: '/docs/advanced-guide/debugging',
-                desc: "Discover Kite auto-enables pprof profiling by leveraging its built-in configurations."
+                desc: "Discover how to enable pprof profiling, runtime statistics and goroutine dumps in Kite applications."
             },
             {
                 title: 'Building CLI Applications',
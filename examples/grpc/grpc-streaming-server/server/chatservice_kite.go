@@ -14,8 +14,6 @@ import (
 	"github.com/sllt/kite/pkg/kite/infra"
 	kitegRPC "github.com/sllt/kite/pkg/kite/grpc"
 	"google.golang.org/grpc"
-	
-	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 // NewChatServiceKiteServer creates a new instance of ChatServiceKiteServer
@@ -268,7 +266,7 @@ func RegisterChatServiceServerWithKite(app *kite.App, srv ChatServiceServerWithK
 
 		RegisterChatServiceServer(s, wrapper)
 
-		wrapper.Server.SetServingStatus("Hello", healthpb.HealthCheckResponse_SERVING)
+		app.MonitorGRPCHealth(wrapper.Server, "ChatService")
 	})
 }
 
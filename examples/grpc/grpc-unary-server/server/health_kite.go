@@ -44,7 +44,7 @@ func registerServerWithKite(app *kite.App, srv any, registerFunc func(grpc.Servi
 		app.Metrics().NewHistogram("app_gRPC-Stream_stats", "Duration of gRPC stream in milliseconds.", gRPCBuckets...)
 
 		healthpb.RegisterHealthServer(s, h.Server)
-		h.Server.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+		app.MonitorGRPCHealth(h.Server, "")
 		healthServerRegistered = true
 	}
 
@@ -16,8 +16,6 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
-	
-	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 // NewHelloKiteServer creates a new instance of HelloKiteServer
@@ -111,7 +109,7 @@ func RegisterHelloServerWithKite(app *kite.App, srv HelloServerWithKite) {
 
 		RegisterHelloServer(s, wrapper)
 
-		wrapper.Server.SetServingStatus("Hello", healthpb.HealthCheckResponse_SERVING)
+		app.MonitorGRPCHealth(wrapper.Server, "Hello")
 	})
 }
 
@@ -0,0 +1,210 @@
+package kite
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+
+	kiteHTTP "github.com/sllt/kite/pkg/kite/http"
+	"github.com/sllt/kite/pkg/kite/http/middleware"
+	"github.com/sllt/kite/pkg/kite/logging"
+)
+
+const defaultAdminAPIPrefix = "/admin"
+
+// CacheInvalidator removes the entries of keys from a cache, or all of its entries when keys is empty.
+type CacheInvalidator func(ctx context.Context, keys ...string) error
+
+// AdminAPIConfig holds the configuration of the admin API, see App.UseAdminAPI.
+type AdminAPIConfig struct {
+	// Prefix is the path of the admin API on the admin listener. Defaults to "/admin".
+	Prefix string
+	// Auth authenticates the requests of the admin API. Defaults to the API keys of ADMIN_API_KEYS, separated by
+	// commas. The admin API is not served when neither is set.
+	Auth func(http.Handler) http.Handler
+	// Caches are the caches invalidated by the admin API, by name.
+	Caches map[string]CacheInvalidator
+}
+
+// adminSubscription is the state of a subscription reported by the admin API.
+type adminSubscription struct {
+	Topic  string `json:"topic"`
+	Paused bool   `json:"paused"`
+}
+
+// UseAdminAPI registers the admin API on the listener of HTTP_ADMIN_PORT, for the operations which every service
+// needs at runtime:
+//
+//	GET  /admin/routes                         the routes of the HTTP server
+//	GET  /admin/config                         the effective configuration, with its secrets masked
+//	GET  /admin/health                         the health of the application and of each of its datasources
+//	POST /admin/caches/{name}/invalidate       invalidates the keys of the body {"keys":[...]}, or the whole cache
+//	GET  /admin/subscriptions                  the subscriptions and whether they are paused
+//	POST /admin/subscriptions/{topic}/pause    pauses a subscription, see Subscription.Pause
+//	POST /admin/subscriptions/{topic}/resume   resumes a subscription
+//	PUT  /admin/log-level                      changes the log level with the body {"level":"DEBUG"}
+//
+// It is not registered when HTTP_ADMIN_PORT is not set, or when neither config.Auth nor ADMIN_API_KEYS is set, as it
+// must not be served without authentication.
+func (a *App) UseAdminAPI(config AdminAPIConfig) {
+	if a.adminServer == nil {
+		a.container.Logger.Errorf("HTTP_ADMIN_PORT is not set, the admin API is not served")
+
+		return
+	}
+
+	if config.Prefix == "" {
+		config.Prefix = defaultAdminAPIPrefix
+	}
+
+	if config.Auth != nil {
+		a.adminAuth = config.Auth
+	}
+
+	auth := a.adminAPIAuth()
+	if auth == nil {
+		a.container.Logger.Errorf("the admin API is not served, set ADMIN_API_KEYS or AdminAPIConfig.Auth to protect it")
+
+		return
+	}
+
+	a.Admin().Group(config.Prefix, func(g *RouteGroup) {
+		g.Use(auth)
+
+		g.GET("/routes", a.adminRoutesHandler)
+		g.GET("/config", a.adminConfigHandler)
+		g.GET("/health", healthHandler)
+		g.POST("/caches/{name}/invalidate", adminCacheHandler(config.Caches))
+		g.GET("/subscriptions", a.adminSubscriptionsHandler)
+		g.POST("/subscriptions/{topic}/pause", a.adminSubscriptionHandler((*Subscription).Pause))
+		g.POST("/subscriptions/{topic}/resume", a.adminSubscriptionHandler((*Subscription).Resume))
+		g.PUT("/log-level", a.adminLogLevelHandler)
+	})
+}
+
+// adminAPIAuth returns the authentication of the admin API, the AdminAPIConfig.Auth given to UseAdminAPI or else the
+// API keys of ADMIN_API_KEYS, or nil when neither is set.
+func (a *App) adminAPIAuth() func(http.Handler) http.Handler {
+	if a.adminAuth != nil {
+		return a.adminAuth
+	}
+
+	var keys []string
+
+	for _, key := range strings.Split(a.Config.Get("ADMIN_API_KEYS"), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	return middleware.APIKeyAuthMiddleware(middleware.APIKeyAuthProvider{}, keys...)
+}
+
+// requireAdminAuth protects the admin endpoints registered outside of UseAdminAPI, e.g. by UseBodyLogging, with the
+// authentication of the admin API. It is resolved when the admin server starts, so that UseAdminAPI may be called after
+// them, and the endpoints are not served when neither AdminAPIConfig.Auth nor ADMIN_API_KEYS is set.
+func (a *App) requireAdminAuth(next http.Handler) http.Handler {
+	if auth := a.adminAPIAuth(); auth != nil {
+		return auth(next)
+	}
+
+	a.container.Logger.Errorf("the admin endpoints are not served, set ADMIN_API_KEYS or AdminAPIConfig.Auth to protect them")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		kiteHTTP.NewResponder(w, r.Method).Respond(nil, kiteHTTP.ErrorInvalidRoute{})
+	})
+}
+
+func (a *App) adminRoutesHandler(*Context) (any, error) {
+	if a.httpServer == nil {
+		return []routeInfo{}, nil
+	}
+
+	return a.httpServer.registry.routes(a.getRequestTimeout()), nil
+}
+
+func (a *App) adminConfigHandler(*Context) (any, error) {
+	return a.configReport(), nil
+}
+
+func adminCacheHandler(caches map[string]CacheInvalidator) Handler {
+	return func(c *Context) (any, error) {
+		name := c.PathParam("name")
+
+		invalidate, ok := caches[name]
+		if !ok {
+			return nil, kiteHTTP.ErrorEntityNotFound{Name: "cache", Value: name}
+		}
+
+		var body struct {
+			Keys []string `json:"keys"`
+		}
+
+		// the requests without a body, and so without a content type, invalidate the whole cache.
+		if err := c.Bind(&body); err != nil {
+			return nil, err
+		}
+
+		if err := invalidate(c, body.Keys...); err != nil {
+			return nil, err
+		}
+
+		c.Infof("cache %s invalidated, keys: %v", name, body.Keys)
+
+		return body, nil
+	}
+}
+
+func (a *App) adminSubscriptionsHandler(*Context) (any, error) {
+	subscriptions := make([]adminSubscription, 0, len(a.subscriptionManager.subscriptions))
+
+	for topic, sub := range a.subscriptionManager.subscriptions {
+		subscriptions = append(subscriptions, adminSubscription{Topic: topic, Paused: sub.Paused()})
+	}
+
+	sort.Slice(subscriptions, func(i, j int) bool { return subscriptions[i].Topic < subscriptions[j].Topic })
+
+	return subscriptions, nil
+}
+
+func (a *App) adminSubscriptionHandler(change func(*Subscription)) Handler {
+	return func(c *Context) (any, error) {
+		topic := c.PathParam("topic")
+
+		sub, ok := a.subscriptionManager.subscriptions[topic]
+		if !ok {
+			return nil, kiteHTTP.ErrorEntityNotFound{Name: "subscription", Value: topic}
+		}
+
+		change(sub)
+
+		c.Infof("subscription %s paused: %t", topic, sub.Paused())
+
+		return adminSubscription{Topic: topic, Paused: sub.Paused()}, nil
+	}
+}
+
+func (a *App) adminLogLevelHandler(c *Context) (any, error) {
+	var body struct {
+		Level string `json:"level"`
+	}
+
+	if err := c.Bind(&body); err != nil {
+		return nil, err
+	}
+
+	level := logging.GetLevelFromString(body.Level)
+	if level.String() != strings.ToUpper(body.Level) {
+		return nil, kiteHTTP.ErrorInvalidParam{Params: []string{"level"}}
+	}
+
+	a.container.Logger.ChangeLevel(level)
+	c.Infof("log level changed to %s", level)
+
+	return body, nil
+}
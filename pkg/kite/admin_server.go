@@ -0,0 +1,106 @@
+package kite
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/sllt/kite/pkg/kite/http/middleware"
+	"github.com/sllt/kite/pkg/kite/metrics"
+	"github.com/sllt/kite/pkg/kite/service"
+)
+
+// initAdminServer initializes the internal HTTP listener of HTTP_ADMIN_PORT, which is not started when it is not set.
+func (a *App) initAdminServer() {
+	value := a.Config.Get("HTTP_ADMIN_PORT")
+	if value == "" || value == "0" {
+		return
+	}
+
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 0 {
+		a.container.Logger.Errorf("invalid HTTP_ADMIN_PORT %q, the admin server is not started", value)
+
+		return
+	}
+
+	if a.httpServer != nil && port == a.httpServer.port {
+		a.container.Logger.Errorf("HTTP_ADMIN_PORT must differ from HTTP_PORT, the admin server is not started")
+
+		return
+	}
+
+	if !isPortAvailable(port) {
+		a.container.Logger.Fatalf("admin port %d is blocked or unreachable", port)
+	}
+
+	a.adminServer = newHTTPServer(a.container, port, middleware.GetConfigs(a.Config))
+}
+
+// Admin returns the route group of the internal HTTP listener of HTTP_ADMIN_PORT, for the endpoints which must not be
+// exposed on the public port, e.g. to flush caches or toggle features:
+//
+//	app.Admin().POST("/cache/flush", flushCache)
+//
+// The admin listener serves the health checks, the metrics at /metrics and, when enabled, the diagnostics under
+// /debug/ as well. When HTTP_ADMIN_PORT is not set, the routes of the returned group are not served anywhere.
+func (a *App) Admin() *RouteGroup {
+	if a.adminServer == nil {
+		a.container.Logger.Errorf("HTTP_ADMIN_PORT is not set, the admin routes are not served")
+
+		return &RouteGroup{node: &GroupNode{}}
+	}
+
+	return &RouteGroup{node: a.adminServer.registry.root, app: a}
+}
+
+// startAdminServer starts the admin server if configured.
+func (a *App) startAdminServer(wg *sync.WaitGroup) {
+	if a.adminServer != nil {
+		wg.Add(1)
+		a.adminServerSetup()
+
+		go func(s *httpServer) {
+			defer wg.Done()
+
+			s.run(a.container)
+		}(a.adminServer)
+	}
+}
+
+// adminServerSetup registers the built-in admin endpoints and compiles the admin routes.
+func (a *App) adminServerSetup() {
+	s := a.adminServer
+
+	s.registry.root.routes = append(s.registry.root.routes,
+		RouteDef{Method: http.MethodGet, Pattern: service.HealthPath, Handler: a.readinessHandler, source: builtinRouteSource},
+		RouteDef{Method: http.MethodGet, Pattern: service.AlivePath, Handler: liveHandler, source: builtinRouteSource},
+	)
+
+	if err := s.registry.checkConflicts(); err != nil {
+		a.container.Logger.Fatalf("%v", err)
+	}
+
+	s.registry.errorMode = a.httpErrorMode()
+	s.registry.compile(s.router.Mux(), a.container, a.getRequestTimeout())
+
+	s.router.Handle("/metrics", metrics.GetHandler(a.container.Metrics()))
+
+	if a.debugEnabled() {
+		if debug := a.newDebugHandler(); debug != nil {
+			s.router.Handle("/debug/*", debug)
+		}
+	}
+
+	s.router.NotFound(handler{
+		function:  catchAllHandler,
+		container: a.container,
+	})
+
+	s.router.MethodNotAllowed(handler{
+		function:  methodNotAllowedHandler,
+		container: a.container,
+	})
+
+	s.recordRegisteredMethods()
+}
@@ -0,0 +1,417 @@
+package kite
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sllt/kite/pkg/kite/http/middleware"
+	"github.com/sllt/kite/pkg/kite/service"
+	"github.com/sllt/kite/pkg/kite/testutil"
+)
+
+func TestApp_AdminServer(t *testing.T) {
+	configs := testutil.NewServerConfigs(t)
+	adminPort := testutil.GetFreePort(t)
+
+	t.Setenv("HTTP_ADMIN_PORT", strconv.Itoa(adminPort))
+
+	app := New()
+
+	app.GET("/hello", func(*Context) (any, error) {
+		return helloWorld, nil
+	})
+
+	app.Admin().Group("/cache").POST("/flush", func(*Context) (any, error) {
+		return "flushed", nil
+	})
+
+	assert.Equal(t, adminPort, app.configReport().Ports["HTTP_ADMIN_PORT"])
+
+	go app.Run()
+
+	t.Cleanup(func() { _ = app.Shutdown(t.Context()) })
+
+	time.Sleep(100 * time.Millisecond)
+
+	public := fmt.Sprintf("http://localhost:%d", configs.HTTPPort)
+	admin := fmt.Sprintf("http://localhost:%d", adminPort)
+
+	tests := []struct {
+		desc   string
+		method string
+		url    string
+		status int
+	}{
+		{"public route on public port", http.MethodGet, public + "/hello", http.StatusOK},
+		{"public route on admin port", http.MethodGet, admin + "/hello", http.StatusNotFound},
+		{"admin route on admin port", http.MethodPost, admin + "/cache/flush", http.StatusCreated},
+		{"admin route on public port", http.MethodPost, public + "/cache/flush", http.StatusNotFound},
+		{"health check on admin port", http.MethodGet, admin + service.AlivePath, http.StatusOK},
+		{"health check on public port", http.MethodGet, public + service.AlivePath, http.StatusOK},
+		{"metrics on admin port", http.MethodGet, admin + "/metrics", http.StatusOK},
+		{"metrics on public port", http.MethodGet, public + "/metrics", http.StatusNotFound},
+	}
+
+	client := &http.Client{Timeout: time.Second}
+
+	for i, tc := range tests {
+		req, err := http.NewRequestWithContext(t.Context(), tc.method, tc.url, http.NoBody)
+		require.NoError(t, err)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err, "TEST[%d], Failed.\n%s", i, tc.desc)
+
+		assert.Equal(t, tc.status, resp.StatusCode, "TEST[%d], Failed.\n%s", i, tc.desc)
+
+		resp.Body.Close()
+	}
+}
+
+func TestApp_AdminServerDisabled(t *testing.T) {
+	testutil.NewServerConfigs(t)
+
+	logs := testutil.StderrOutputForFunc(func() {
+		app := New()
+
+		assert.Nil(t, app.adminServer)
+
+		// the routes of the admin group are not served on the public port.
+		app.Admin().GET("/flags", func(*Context) (any, error) { return nil, nil })
+
+		assert.Empty(t, app.httpServer.registry.root.routes)
+		assert.NotContains(t, app.configReport().Ports, "HTTP_ADMIN_PORT")
+	})
+
+	assert.Contains(t, logs, "HTTP_ADMIN_PORT is not set")
+}
+
+func TestApp_AdminServerInvalidPort(t *testing.T) {
+	configs := testutil.NewServerConfigs(t)
+
+	for _, port := range []string{"admin", strconv.Itoa(configs.HTTPPort)} {
+		t.Setenv("HTTP_ADMIN_PORT", port)
+
+		logs := testutil.StderrOutputForFunc(func() {
+			assert.Nil(t, New().adminServer, port)
+		})
+
+		assert.Contains(t, logs, "the admin server is not started", port)
+	}
+}
+
+func TestApp_UseBodyLogging_AdminToggle(t *testing.T) {
+	testutil.NewServerConfigs(t)
+	adminPort := testutil.GetFreePort(t)
+
+	t.Setenv("HTTP_ADMIN_PORT", strconv.Itoa(adminPort))
+	t.Setenv("ADMIN_API_KEYS", "admin-key")
+
+	app := New()
+
+	bodyLogger := app.UseBodyLogging(middleware.BodyLogConfig{})
+
+	go app.Run()
+
+	t.Cleanup(func() { _ = app.Shutdown(t.Context()) })
+
+	time.Sleep(100 * time.Millisecond)
+
+	assert.False(t, bodyLogger.Enabled())
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodPut,
+		fmt.Sprintf("http://localhost:%d/body-logging", adminPort), strings.NewReader(`{"enabled":true}`))
+	require.NoError(t, err)
+
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: time.Second}
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.False(t, bodyLogger.Enabled())
+
+	req, err = http.NewRequestWithContext(t.Context(), http.MethodPut,
+		fmt.Sprintf("http://localhost:%d/body-logging", adminPort), strings.NewReader(`{"enabled":true}`))
+	require.NoError(t, err)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Api-Key", "admin-key")
+
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, bodyLogger.Enabled())
+}
+
+func TestApp_UseRequestCapture_Admin(t *testing.T) {
+	configs := testutil.NewServerConfigs(t)
+	adminPort := testutil.GetFreePort(t)
+
+	t.Setenv("HTTP_ADMIN_PORT", strconv.Itoa(adminPort))
+	t.Setenv("HTTP_CAPTURE_ENABLED", "true")
+	t.Setenv("ADMIN_API_KEYS", "admin-key")
+
+	app := New()
+
+	app.GET("/orders", func(*Context) (any, error) {
+		return nil, errTest
+	})
+
+	capture, err := app.UseRequestCapture(middleware.CaptureConfig{MinStatus: http.StatusInternalServerError})
+	require.NoError(t, err)
+
+	go app.Run()
+
+	t.Cleanup(func() { _ = app.Shutdown(t.Context()) })
+
+	time.Sleep(100 * time.Millisecond)
+
+	assert.True(t, capture.Enabled())
+
+	client := &http.Client{Timeout: time.Second}
+
+	resp, err := client.Get(fmt.Sprintf("http://localhost:%d/orders?token=abc", configs.HTTPPort))
+	require.NoError(t, err)
+
+	resp.Body.Close()
+
+	resp, err = client.Get(fmt.Sprintf("http://localhost:%d/request-capture", adminPort))
+	require.NoError(t, err)
+
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet,
+		fmt.Sprintf("http://localhost:%d/request-capture", adminPort), http.NoBody)
+	require.NoError(t, err)
+
+	req.Header.Set("X-Api-Key", "admin-key")
+
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), `"uri":"/orders?token=[REDACTED]"`)
+	assert.Contains(t, string(body), `"status":500`)
+
+	req, err = http.NewRequestWithContext(t.Context(), http.MethodPut,
+		fmt.Sprintf("http://localhost:%d/request-capture", adminPort), strings.NewReader(`{"enabled":false}`))
+	require.NoError(t, err)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Api-Key", "admin-key")
+
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.False(t, capture.Enabled())
+}
+
+func TestApp_UseRequestCapture_AdminWithoutAuth(t *testing.T) {
+	testutil.NewServerConfigs(t)
+	adminPort := testutil.GetFreePort(t)
+
+	t.Setenv("HTTP_ADMIN_PORT", strconv.Itoa(adminPort))
+
+	app := New()
+
+	_, err := app.UseRequestCapture(middleware.CaptureConfig{})
+	require.NoError(t, err)
+
+	go app.Run()
+
+	t.Cleanup(func() { _ = app.Shutdown(t.Context()) })
+
+	time.Sleep(100 * time.Millisecond)
+
+	// the capture is not served without the authentication of the admin API.
+	resp, err := (&http.Client{Timeout: time.Second}).Get(fmt.Sprintf("http://localhost:%d/request-capture", adminPort))
+	require.NoError(t, err)
+
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestApp_UseAdminAPI(t *testing.T) {
+	testutil.NewServerConfigs(t)
+	adminPort := testutil.GetFreePort(t)
+
+	t.Setenv("HTTP_ADMIN_PORT", strconv.Itoa(adminPort))
+	t.Setenv("ADMIN_API_KEYS", "admin-key")
+
+	app := New()
+
+	app.GET("/hello", func(*Context) (any, error) {
+		return helloWorld, nil
+	})
+
+	var invalidated []string
+
+	app.UseAdminAPI(AdminAPIConfig{Caches: map[string]CacheInvalidator{
+		"users": func(_ context.Context, keys ...string) error {
+			invalidated = keys
+
+			return nil
+		},
+	}})
+
+	go app.Run()
+
+	t.Cleanup(func() { _ = app.Shutdown(t.Context()) })
+
+	time.Sleep(100 * time.Millisecond)
+
+	testCases := []struct {
+		desc       string
+		method     string
+		path       string
+		body       string
+		key        string
+		statusCode int
+		contains   string
+	}{
+		{desc: "unauthenticated", method: http.MethodGet, path: "/admin/routes", statusCode: http.StatusUnauthorized},
+		{desc: "routes", method: http.MethodGet, path: "/admin/routes", key: "admin-key",
+			statusCode: http.StatusOK, contains: `"pattern":"/hello"`},
+		{desc: "config", method: http.MethodGet, path: "/admin/config", key: "admin-key",
+			statusCode: http.StatusOK, contains: `"HTTP_ADMIN_PORT"`},
+		{desc: "health", method: http.MethodGet, path: "/admin/health", key: "admin-key",
+			statusCode: http.StatusOK, contains: `"status"`},
+		{desc: "invalidate cache", method: http.MethodPost, path: "/admin/caches/users/invalidate", key: "admin-key",
+			body: `{"keys":["42"]}`, statusCode: http.StatusCreated},
+		{desc: "unknown cache", method: http.MethodPost, path: "/admin/caches/orders/invalidate", key: "admin-key",
+			statusCode: http.StatusNotFound},
+		{desc: "subscriptions", method: http.MethodGet, path: "/admin/subscriptions", key: "admin-key",
+			statusCode: http.StatusOK, contains: `"data":[]`},
+		{desc: "unknown subscription", method: http.MethodPost, path: "/admin/subscriptions/orders/pause",
+			key: "admin-key", statusCode: http.StatusNotFound},
+		{desc: "invalid log level", method: http.MethodPut, path: "/admin/log-level", key: "admin-key",
+			body: `{"level":"verbose"}`, statusCode: http.StatusBadRequest},
+		{desc: "log level", method: http.MethodPut, path: "/admin/log-level", key: "admin-key",
+			body: `{"level":"debug"}`, statusCode: http.StatusOK},
+	}
+
+	for i, tc := range testCases {
+		var body io.Reader
+		if tc.body != "" {
+			body = strings.NewReader(tc.body)
+		}
+
+		req, err := http.NewRequestWithContext(t.Context(), tc.method,
+			fmt.Sprintf("http://localhost:%d%s", adminPort, tc.path), body)
+		require.NoError(t, err, "TEST[%d], Failed.\n%s", i, tc.desc)
+
+		if tc.body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		if tc.key != "" {
+			req.Header.Set("X-Api-Key", tc.key)
+		}
+
+		resp, err := (&http.Client{Timeout: time.Second}).Do(req)
+		require.NoError(t, err, "TEST[%d], Failed.\n%s", i, tc.desc)
+
+		respBody, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		assert.Equal(t, tc.statusCode, resp.StatusCode, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Contains(t, string(respBody), tc.contains, "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+
+	assert.Equal(t, []string{"42"}, invalidated)
+}
+
+func TestApp_UseAdminAPI_WithoutAuth(t *testing.T) {
+	testutil.NewServerConfigs(t)
+	adminPort := testutil.GetFreePort(t)
+
+	t.Setenv("HTTP_ADMIN_PORT", strconv.Itoa(adminPort))
+
+	var app *App
+
+	logs := testutil.StderrOutputForFunc(func() {
+		app = New()
+		app.UseAdminAPI(AdminAPIConfig{})
+	})
+
+	assert.Contains(t, logs, "the admin API is not served")
+
+	go app.Run()
+
+	t.Cleanup(func() { _ = app.Shutdown(t.Context()) })
+
+	time.Sleep(100 * time.Millisecond)
+
+	resp, err := (&http.Client{Timeout: time.Second}).Get(fmt.Sprintf("http://localhost:%d/admin/log-level", adminPort))
+	require.NoError(t, err)
+
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestApp_UseAdminAPI_CustomAuth(t *testing.T) {
+	testutil.NewServerConfigs(t)
+	adminPort := testutil.GetFreePort(t)
+
+	t.Setenv("HTTP_ADMIN_PORT", strconv.Itoa(adminPort))
+
+	app := New()
+
+	app.UseAdminAPI(AdminAPIConfig{Auth: middleware.BasicAuthMiddleware(middleware.BasicAuthProvider{
+		Users: map[string]string{"admin": "secret"},
+	})})
+
+	go app.Run()
+
+	t.Cleanup(func() { _ = app.Shutdown(t.Context()) })
+
+	time.Sleep(100 * time.Millisecond)
+
+	client := &http.Client{Timeout: time.Second}
+	url := fmt.Sprintf("http://localhost:%d/admin/routes", adminPort)
+
+	resp, err := client.Get(url)
+	require.NoError(t, err)
+
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, url, http.NoBody)
+	require.NoError(t, err)
+
+	req.SetBasicAuth("admin", "secret")
+
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
@@ -0,0 +1,38 @@
+package kite
+
+// AfterHandlerFunc post-processes the result and the error returned by a handler, before they are responded. It
+// returns the result and the error responded instead, which are usually the ones it received, possibly modified.
+type AfterHandlerFunc func(ctx *Context, result any, err error) (any, error)
+
+// AfterHandler registers hooks which run after every HTTP handler, including the ones of the groups, and before the
+// response is written, in the order they are registered:
+//
+//	app.AfterHandler(func(ctx *kite.Context, result any, err error) (any, error) {
+//		if user, ok := result.(User); ok && ctx.GetAuthInfo().GetClaims()["role"] != "admin" {
+//			user.Email = ""
+//
+//			return user, err
+//		}
+//
+//		return result, err
+//	})
+//
+// They gather in one place the processing shared by every response, e.g. adding HATEOAS links, masking fields by role
+// or recording business audit data, which the handlers would otherwise repeat. The hooks run after the Kite
+// middlewares, so they see the result of the whole handler chain. A hook which panics is recovered like a handler.
+func (a *App) AfterHandler(hooks ...AfterHandlerFunc) {
+	if !a.canMutateRoutes("register after handler hooks") {
+		return
+	}
+
+	a.httpServer.registry.afterHandlers = append(a.httpServer.registry.afterHandlers, hooks...)
+}
+
+// runAfterHandlers passes the result and the error of the handler through the hooks.
+func runAfterHandlers(c *Context, hooks []AfterHandlerFunc, result any, err error) (any, error) {
+	for _, hook := range hooks {
+		result, err = hook(c, result, err)
+	}
+
+	return result, err
+}
@@ -0,0 +1,116 @@
+package kite
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sllt/kite/pkg/kite/config"
+	kiteHTTP "github.com/sllt/kite/pkg/kite/http"
+	"github.com/sllt/kite/pkg/kite/infra"
+)
+
+var errUserNotFound = errors.New("user not found")
+
+type afterHandlerUser struct {
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+}
+
+// maskEmail removes the email of the users, for the requests which are not made by an admin.
+func maskEmail(ctx *Context, result any, err error) (any, error) {
+	if user, ok := result.(afterHandlerUser); ok && ctx.Param("role") != "admin" {
+		user.Email = ""
+
+		return user, err
+	}
+
+	return result, err
+}
+
+// notFound turns errUserNotFound into a 404.
+func notFound(_ *Context, result any, err error) (any, error) {
+	if errors.Is(err, errUserNotFound) {
+		return result, kiteHTTP.ErrorEntityNotFound{Name: "name", Value: "bob"}
+	}
+
+	return result, err
+}
+
+func TestApp_AfterHandler(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		target     string
+		statusCode int
+		body       string
+	}{
+		{desc: "masked result", target: "/users/alice", statusCode: http.StatusOK,
+			body: `{"code":0,"data":{"name":"alice"},"message":"ok"}`},
+		{desc: "unmasked result", target: "/users/alice?role=admin", statusCode: http.StatusOK,
+			body: `{"code":0,"data":{"name":"alice","email":"alice@example.com"},"message":"ok"}`},
+		{desc: "replaced error", target: "/users/bob", statusCode: http.StatusNotFound,
+			body: `{"code":404,"data":null,"message":"No entity found with name: bob"}`},
+		{desc: "hook of a group route", target: "/v2/users/alice", statusCode: http.StatusOK,
+			body: `{"code":0,"data":{"name":"alice"},"message":"ok"}`},
+	}
+
+	getUser := func(ctx *Context) (any, error) {
+		if name := ctx.PathParam("name"); name == "alice" {
+			return afterHandlerUser{Name: name, Email: name + "@example.com"}, nil
+		}
+
+		return nil, errUserNotFound
+	}
+
+	app := &App{
+		httpServer:     &httpServer{registry: newRouteRegistry()},
+		container:      infra.NewContainer(config.NewMockConfig(nil)),
+		httpRegistered: true,
+	}
+
+	app.GET("/users/{name}", getUser)
+	app.Group("/v2").GET("/users/{name}", getUser)
+	app.AfterHandler(maskEmail, notFound)
+
+	mux := chi.NewRouter()
+	app.httpServer.registry.compile(mux, app.container, 0)
+
+	for i, tc := range testCases {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.target, http.NoBody))
+
+		assert.Equal(t, tc.statusCode, rec.Code, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.JSONEq(t, tc.body, rec.Body.String(), "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestApp_AfterHandler_Panic(t *testing.T) {
+	h := handler{
+		function:  func(*Context) (any, error) { return "ok", nil },
+		container: infra.NewContainer(config.NewMockConfig(nil)),
+		afterHandlers: []AfterHandlerFunc{func(*Context, any, error) (any, error) {
+			panic("boom")
+		}},
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code, "a panicking hook is recovered like a handler")
+}
+
+func TestApp_AfterHandler_AfterCompile(t *testing.T) {
+	app := &App{
+		httpServer: &httpServer{registry: newRouteRegistry()},
+		container:  infra.NewContainer(config.NewMockConfig(nil)),
+	}
+
+	app.httpServer.registry.compile(chi.NewRouter(), app.container, 0)
+	app.AfterHandler(maskEmail)
+
+	assert.Empty(t, app.httpServer.registry.afterHandlers, "the hooks registered after the compilation are ignored")
+}
@@ -0,0 +1,91 @@
+package kite
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	kiteHTTP "github.com/sllt/kite/pkg/kite/http"
+	"github.com/sllt/kite/pkg/kite/http/middleware"
+)
+
+// AddAPIKeyCommands adds the "apikey create" and "apikey revoke" sub-commands to a CLI application, to mint and
+// revoke the keys of the store used by middleware.APIKeys:
+//
+//	app := kite.NewCMD()
+//	app.AddAPIKeyCommands(middleware.NewSQLAPIKeyStore(app.SQL()))
+//	app.Run()
+//
+//	./apikeys apikey create -name=billing -scopes=invoices:read,invoices:write -rps=10 -burst=20 -expires=720h
+//	./apikeys apikey revoke -id=3f2a9c1b7d4e
+func (a *App) AddAPIKeyCommands(store middleware.APIKeyStore) {
+	a.SubCommand("apikey create", func(ctx *Context) (any, error) {
+		return createAPIKey(ctx, store)
+	}, AddDescription("Mint an API key"),
+		AddHelp("apikey create -name=<name> [-scopes=<scope>,<scope>] [-rps=<requests per second>] [-burst=<burst>] "+
+			"[-expires=<duration>]"))
+
+	a.SubCommand("apikey revoke", func(ctx *Context) (any, error) {
+		id := ctx.Param("id")
+		if id == "" {
+			return nil, kiteHTTP.ErrorMissingParam{Params: []string{"id"}}
+		}
+
+		if err := store.Revoke(ctx, id); err != nil {
+			return nil, err
+		}
+
+		return fmt.Sprintf("Revoked API key %s", id), nil
+	}, AddDescription("Revoke an API key"), AddHelp("apikey revoke -id=<id>"))
+}
+
+func createAPIKey(ctx *Context, store middleware.APIKeyStore) (any, error) {
+	spec := middleware.APIKeyRecord{Name: ctx.Param("name")}
+	if spec.Name == "" {
+		return nil, kiteHTTP.ErrorMissingParam{Params: []string{"name"}}
+	}
+
+	if ctx.Param("scopes") != "" {
+		spec.Scopes = ctx.Params("scopes")
+	}
+
+	var invalid []string
+
+	if rps := ctx.Param("rps"); rps != "" {
+		value, err := strconv.ParseFloat(rps, 64)
+		if err != nil || value <= 0 {
+			invalid = append(invalid, "rps")
+		}
+
+		spec.RequestsPerSecond = value
+	}
+
+	if burst := ctx.Param("burst"); burst != "" {
+		value, err := strconv.Atoi(burst)
+		if err != nil || value <= 0 {
+			invalid = append(invalid, "burst")
+		}
+
+		spec.Burst = value
+	}
+
+	if expires := ctx.Param("expires"); expires != "" {
+		value, err := time.ParseDuration(expires)
+		if err != nil || value <= 0 {
+			invalid = append(invalid, "expires")
+		}
+
+		spec.ExpiresAt = time.Now().Add(value).UTC()
+	}
+
+	if len(invalid) > 0 {
+		return nil, kiteHTTP.ErrorInvalidParam{Params: invalid}
+	}
+
+	key, record, err := middleware.MintAPIKey(ctx, store, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return fmt.Sprintf("Created API key %s (id %s), it is only shown once:\n%s", record.Name, record.ID, key), nil
+}
@@ -0,0 +1,77 @@
+package kite
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sllt/kite/pkg/kite/cmd/terminal"
+	"github.com/sllt/kite/pkg/kite/config"
+	"github.com/sllt/kite/pkg/kite/http/middleware"
+	"github.com/sllt/kite/pkg/kite/infra"
+	"github.com/sllt/kite/pkg/kite/logging"
+	"github.com/sllt/kite/pkg/kite/testutil"
+)
+
+func runAPIKeyCommand(t *testing.T, store middleware.APIKeyStore, args ...string) string {
+	t.Helper()
+
+	os.Args = append([]string{""}, args...)
+
+	app := &App{cmd: &cmd{out: terminal.New()}}
+	app.AddAPIKeyCommands(store)
+
+	return testutil.StdoutOutputForFunc(func() {
+		app.cmd.Run(infra.NewContainer(config.NewEnvFile("", logging.NewMockLogger(logging.DEBUG))))
+	})
+}
+
+func TestApp_AddAPIKeyCommands(t *testing.T) {
+	store := middleware.NewMemoryAPIKeyStore()
+
+	output := runAPIKeyCommand(t, store, "apikey", "create", "-name=ci", "-scopes=orders:read,orders:write",
+		"-rps=10", "-expires=24h")
+
+	key := regexp.MustCompile(`kite_[0-9a-f]+_\S+`).FindString(output)
+	require.NotEmpty(t, key, output)
+
+	record, err := store.Get(context.Background(), middleware.HashAPIKey(key))
+	require.NoError(t, err)
+	require.NotNil(t, record)
+
+	assert.Contains(t, output, "Created API key ci (id "+record.ID+")")
+	assert.Equal(t, []string{"orders:read", "orders:write"}, record.Scopes)
+	assert.InDelta(t, 10, record.RequestsPerSecond, 0)
+	assert.False(t, record.ExpiresAt.IsZero())
+
+	output = runAPIKeyCommand(t, store, "apikey", "revoke", "-id="+record.ID)
+	assert.Contains(t, output, "Revoked API key "+record.ID)
+
+	record, err = store.Get(context.Background(), middleware.HashAPIKey(key))
+	require.NoError(t, err)
+	assert.True(t, record.Revoked)
+}
+
+func TestApp_AddAPIKeyCommands_InvalidParams(t *testing.T) {
+	testCases := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"apikey", "create"}, "missing parameter(s): name"},
+		{[]string{"apikey", "create", "-name=ci", "-rps=fast", "-expires=soon"}, "invalid parameter(s): rps, expires"},
+		{[]string{"apikey", "revoke"}, "missing parameter(s): id"},
+		{[]string{"apikey", "revoke", "-id=unknown"}, middleware.ErrAPIKeyNotFound.Error()},
+	}
+
+	for i, tc := range testCases {
+		output := testutil.StderrOutputForFunc(func() {
+			runAPIKeyCommand(t, middleware.NewMemoryAPIKeyStore(), tc.args...)
+		})
+
+		assert.Contains(t, output, tc.expected, "TEST[%d], Failed.\n%v", i, tc.args)
+	}
+}
@@ -0,0 +1,319 @@
+package kite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sllt/kite/pkg/kite/datasource/sql/qb"
+	kiteHTTP "github.com/sllt/kite/pkg/kite/http"
+	"github.com/sllt/kite/pkg/kite/migration"
+)
+
+const (
+	redactedValue = "[REDACTED]"
+
+	// createAuditLogTable uses portable column types only, so that the same migration runs on MySQL, PostgreSQL and SQLite.
+	createAuditLogTable = `CREATE TABLE IF NOT EXISTS kite_audit_log (
+    occurred_at TIMESTAMP NOT NULL,
+    trace_id VARCHAR(64),
+    subject VARCHAR(255),
+    method VARCHAR(10) NOT NULL,
+    route VARCHAR(512) NOT NULL,
+    resource_ids TEXT,
+    status INT NOT NULL,
+    error TEXT,
+    diff TEXT
+);`
+
+	insertAuditLog = `INSERT INTO kite_audit_log (occurred_at, trace_id, subject, method, route, resource_ids, status, error, diff)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+)
+
+var errAuditSinkMissing = errors.New("audit sink is not configured")
+
+// AuditEntry records who did what for a single request.
+type AuditEntry struct {
+	Time        time.Time         `json:"time"`
+	TraceID     string            `json:"trace_id,omitempty"`
+	Subject     string            `json:"subject,omitempty"`
+	Method      string            `json:"method"`
+	Route       string            `json:"route"`
+	ResourceIDs map[string]string `json:"resource_ids,omitempty"`
+	Status      int               `json:"status"`
+	Error       string            `json:"error,omitempty"`
+	Diff        map[string]any    `json:"diff,omitempty"`
+}
+
+// AuditSink persists audit entries.
+type AuditSink interface {
+	Write(ctx context.Context, entry *AuditEntry) error
+}
+
+// AuditConfig configures the audit middleware.
+type AuditConfig struct {
+	// Sink receives the audit entries. Use NewSQLAuditSink or NewPubSubAuditSink, or a custom implementation.
+	Sink AuditSink
+	// Methods restricts the HTTP methods that are audited. Defaults to POST, PUT, PATCH and DELETE.
+	Methods []string
+	// Subject overrides how the acting subject is resolved. By default, the JWT "sub" claim, the basic
+	// auth username or a masked API key is used, in that order.
+	Subject func(c *Context) string
+	// Diff is called after the handler returns and may describe the change made by the request,
+	// e.g. the before and after state of the resource.
+	Diff func(c *Context, result any, err error) map[string]any
+	// RedactFields lists field names (case-insensitive) whose values are replaced by "[REDACTED]"
+	// in resource IDs and diffs.
+	RedactFields []string
+}
+
+// EnableAudit registers the audit middleware for all routes of the application.
+// See [AuditMiddleware] for details.
+func (a *App) EnableAudit(cfg AuditConfig) {
+	if cfg.Sink == nil {
+		a.container.Error(errAuditSinkMissing)
+
+		return
+	}
+
+	a.UseMiddleware(AuditMiddleware(cfg))
+}
+
+// AuditMiddleware returns a KiteMiddleware that records an AuditEntry for every request of the
+// configured methods after its handler has run. Failures to write an entry are logged and never
+// affect the response.
+func AuditMiddleware(cfg AuditConfig) KiteMiddleware {
+	if len(cfg.Methods) == 0 {
+		cfg.Methods = []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	}
+
+	if cfg.Subject == nil {
+		cfg.Subject = defaultAuditSubject
+	}
+
+	redact := make(map[string]struct{}, len(cfg.RedactFields))
+	for _, f := range cfg.RedactFields {
+		redact[strings.ToLower(f)] = struct{}{}
+	}
+
+	return func(next Handler) Handler {
+		return func(c *Context) (any, error) {
+			result, err := next(c)
+
+			rctx := chi.RouteContext(c.Context)
+			if rctx == nil || cfg.Sink == nil || !slices.Contains(cfg.Methods, rctx.RouteMethod) {
+				return result, err
+			}
+
+			entry := &AuditEntry{
+				Time:        time.Now().UTC(),
+				TraceID:     c.GetCorrelationID(),
+				Subject:     cfg.Subject(c),
+				Method:      rctx.RouteMethod,
+				Route:       rctx.RoutePattern(),
+				ResourceIDs: auditResourceIDs(rctx, redact),
+				Status:      auditStatus(rctx.RouteMethod, result, err),
+			}
+
+			if err != nil {
+				entry.Error = err.Error()
+			}
+
+			if cfg.Diff != nil {
+				entry.Diff = redactMap(cfg.Diff(c, result, err), redact)
+			}
+
+			if writeErr := cfg.Sink.Write(context.WithoutCancel(c.Context), entry); writeErr != nil {
+				c.Errorf("failed to write audit entry for %s %s: %v", entry.Method, entry.Route, writeErr)
+			}
+
+			return result, err
+		}
+	}
+}
+
+func defaultAuditSubject(c *Context) string {
+	info := c.GetAuthInfo()
+
+	if sub, err := info.GetClaims().GetSubject(); err == nil && sub != "" {
+		return sub
+	}
+
+	if username := info.GetUsername(); username != "" {
+		return username
+	}
+
+	if key := info.GetAPIKey(); key != "" {
+		return "apikey:" + maskSecret(key)
+	}
+
+	return ""
+}
+
+// maskSecret keeps the last 4 characters of a secret so that it can be identified without being leaked.
+func maskSecret(s string) string {
+	const visible = 4
+
+	if len(s) <= visible {
+		return strings.Repeat("*", len(s))
+	}
+
+	return strings.Repeat("*", len(s)-visible) + s[len(s)-visible:]
+}
+
+func auditResourceIDs(rctx *chi.Context, redact map[string]struct{}) map[string]string {
+	if len(rctx.URLParams.Keys) == 0 {
+		return nil
+	}
+
+	ids := make(map[string]string, len(rctx.URLParams.Keys))
+
+	for i, key := range rctx.URLParams.Keys {
+		if key == "*" || i >= len(rctx.URLParams.Values) {
+			continue
+		}
+
+		value := rctx.URLParams.Values[i]
+		if _, ok := redact[strings.ToLower(key)]; ok {
+			value = redactedValue
+		}
+
+		ids[key] = value
+	}
+
+	return ids
+}
+
+func redactMap(m map[string]any, redact map[string]struct{}) map[string]any {
+	if m == nil || len(redact) == 0 {
+		return m
+	}
+
+	out := make(map[string]any, len(m))
+
+	for k, v := range m {
+		if _, ok := redact[strings.ToLower(k)]; ok {
+			out[k] = redactedValue
+			continue
+		}
+
+		if nested, ok := v.(map[string]any); ok {
+			v = redactMap(nested, redact)
+		}
+
+		out[k] = v
+	}
+
+	return out
+}
+
+// auditStatus derives the HTTP status code the responder will use for the handler result.
+func auditStatus(method string, result any, err error) int {
+	if err != nil {
+		var sc kiteHTTP.StatusCodeResponder
+		if errors.As(err, &sc) {
+			return sc.StatusCode()
+		}
+
+		return http.StatusInternalServerError
+	}
+
+	switch method {
+	case http.MethodPost:
+		if result != nil {
+			return http.StatusCreated
+		}
+
+		return http.StatusAccepted
+	case http.MethodDelete:
+		return http.StatusNoContent
+	default:
+		return http.StatusOK
+	}
+}
+
+// AuditSQL is the subset of the SQL datasource used by the SQL audit sink.
+type AuditSQL interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	Dialect() string
+}
+
+type sqlAuditSink struct {
+	db AuditSQL
+}
+
+// NewSQLAuditSink creates an AuditSink which inserts entries into the kite_audit_log table.
+// The table can be created with [AuditLogMigration].
+func NewSQLAuditSink(db AuditSQL) AuditSink {
+	return &sqlAuditSink{db: db}
+}
+
+func (s *sqlAuditSink) Write(ctx context.Context, e *AuditEntry) error {
+	query := qb.RebindWithDialect(s.db.Dialect(), insertAuditLog)
+
+	var ids, diff sql.NullString
+
+	if len(e.ResourceIDs) > 0 {
+		b, _ := json.Marshal(e.ResourceIDs)
+		ids = sql.NullString{String: string(b), Valid: true}
+	}
+
+	if len(e.Diff) > 0 {
+		b, err := json.Marshal(e.Diff)
+		if err != nil {
+			return err
+		}
+
+		diff = sql.NullString{String: string(b), Valid: true}
+	}
+
+	_, err := s.db.ExecContext(ctx, query, e.Time, e.TraceID, e.Subject, e.Method, e.Route, ids, e.Status, e.Error, diff)
+
+	return err
+}
+
+// AuditLogMigration returns a migration which creates the kite_audit_log table used by the SQL audit sink.
+//
+//	app.Migrate(map[int64]migration.Migrate{
+//		20240101000000: kite.AuditLogMigration(),
+//	})
+func AuditLogMigration() migration.Migrate {
+	return migration.Migrate{
+		UP: func(d migration.Datasource) error {
+			_, err := d.SQL.Exec(createAuditLogTable)
+
+			return err
+		},
+	}
+}
+
+// AuditPublisher is the subset of the PubSub client used by the pubsub audit sink.
+type AuditPublisher interface {
+	Publish(ctx context.Context, topic string, message []byte) error
+}
+
+type pubSubAuditSink struct {
+	publisher AuditPublisher
+	topic     string
+}
+
+// NewPubSubAuditSink creates an AuditSink which publishes entries as JSON messages to the given topic.
+func NewPubSubAuditSink(publisher AuditPublisher, topic string) AuditSink {
+	return &pubSubAuditSink{publisher: publisher, topic: topic}
+}
+
+func (s *pubSubAuditSink) Write(ctx context.Context, e *AuditEntry) error {
+	msg, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	return s.publisher.Publish(ctx, s.topic, msg)
+}
@@ -0,0 +1,130 @@
+package kite
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sllt/kite/pkg/kite/config"
+	kiteHTTP "github.com/sllt/kite/pkg/kite/http"
+	"github.com/sllt/kite/pkg/kite/infra"
+)
+
+type recordingAuditSink struct {
+	entries []*AuditEntry
+}
+
+func (s *recordingAuditSink) Write(_ context.Context, e *AuditEntry) error {
+	s.entries = append(s.entries, e)
+	return nil
+}
+
+func serveAudited(t *testing.T, cfg AuditConfig, method, pattern, target string, h Handler) *httptest.ResponseRecorder {
+	t.Helper()
+
+	reg := newRouteRegistry()
+	mux := chi.NewRouter()
+	container := infra.NewContainer(config.NewMockConfig(nil))
+
+	reg.root.kiteMWs = append(reg.root.kiteMWs, AuditMiddleware(cfg))
+	reg.root.routes = append(reg.root.routes, RouteDef{Method: method, Pattern: pattern, Handler: h})
+	reg.compile(mux, container, 0)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(method, target, http.NoBody))
+
+	return rec
+}
+
+func TestAuditMiddleware_RecordsEntry(t *testing.T) {
+	sink := &recordingAuditSink{}
+
+	cfg := AuditConfig{
+		Sink:         sink,
+		Subject:      func(*Context) string { return "user-1" },
+		RedactFields: []string{"token", "card"},
+		Diff: func(_ *Context, result any, _ error) map[string]any {
+			return map[string]any{
+				"after": map[string]any{"status": result, "card": "4111"},
+				"token": "secret",
+			}
+		},
+	}
+
+	rec := serveAudited(t, cfg, http.MethodPut, "/orders/{id}/tokens/{token}", "/orders/42/tokens/abc",
+		func(*Context) (any, error) { return "shipped", nil })
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	require.Len(t, sink.entries, 1)
+
+	e := sink.entries[0]
+	assert.Equal(t, "user-1", e.Subject)
+	assert.Equal(t, http.MethodPut, e.Method)
+	assert.Equal(t, "/orders/{id}/tokens/{token}", e.Route)
+	assert.Equal(t, map[string]string{"id": "42", "token": redactedValue}, e.ResourceIDs)
+	assert.Equal(t, http.StatusOK, e.Status)
+	assert.Equal(t, redactedValue, e.Diff["token"])
+	assert.Equal(t, map[string]any{"status": "shipped", "card": redactedValue}, e.Diff["after"])
+}
+
+func TestAuditMiddleware_RecordsErrorStatus(t *testing.T) {
+	sink := &recordingAuditSink{}
+
+	serveAudited(t, AuditConfig{Sink: sink}, http.MethodDelete, "/orders/{id}", "/orders/7",
+		func(*Context) (any, error) { return nil, kiteHTTP.ErrorEntityNotFound{Name: "id", Value: "7"} })
+
+	require.Len(t, sink.entries, 1)
+	assert.Equal(t, http.StatusNotFound, sink.entries[0].Status)
+	assert.Contains(t, sink.entries[0].Error, "No entity found")
+}
+
+func TestAuditMiddleware_SkipsSafeMethods(t *testing.T) {
+	sink := &recordingAuditSink{}
+
+	serveAudited(t, AuditConfig{Sink: sink}, http.MethodGet, "/orders/{id}", "/orders/7",
+		func(*Context) (any, error) { return "order", nil })
+
+	assert.Empty(t, sink.entries)
+}
+
+func TestSQLAuditSink_Write(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	mock.ExpectExec("INSERT INTO kite_audit_log").
+		WithArgs(sqlmock.AnyArg(), "trace", "user-1", http.MethodPost, "/orders", sqlmock.AnyArg(),
+			http.StatusCreated, "", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	sink := NewSQLAuditSink(dialectSQL{DB: db, dialect: "postgres"})
+
+	err = sink.Write(context.Background(), &AuditEntry{
+		TraceID: "trace", Subject: "user-1", Method: http.MethodPost, Route: "/orders", Status: http.StatusCreated,
+	})
+
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func Test_maskSecret(t *testing.T) {
+	assert.Equal(t, "****", maskSecret("abcd"))
+	assert.Equal(t, "******7890", maskSecret("1234567890"))
+}
+
+type dialectSQL struct {
+	*sql.DB
+	dialect string
+}
+
+func (d dialectSQL) Dialect() string {
+	return d.dialect
+}
@@ -1,15 +1,17 @@
 package kite
 
 import (
+	"context"
 	"net/http"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"google.golang.org/grpc"
 
-	"github.com/sllt/kite/pkg/kite/infra"
+	"github.com/sllt/kite/pkg/kite/auth"
 	grpcMiddleware "github.com/sllt/kite/pkg/kite/grpc/middleware"
 	"github.com/sllt/kite/pkg/kite/http/middleware"
+	"github.com/sllt/kite/pkg/kite/infra"
 )
 
 // EnableBasicAuth enables basic authentication for the application.
@@ -126,6 +128,23 @@ func (a *App) EnableOAuth(jwksEndpoint string,
 		grpcMiddleware.OAuthStreamInterceptor(publicKeyProvider, options...))
 }
 
+// UseOIDC enables the OpenID Connect login flow for the HTTP server.
+//
+// It discovers the endpoints of config.IssuerURL and serves /auth/login, /auth/callback and /auth/logout.
+// Once signed in, the ID token claims of the user are kept in an encrypted session cookie and are available
+// to the handlers through ctx.GetAuthInfo().GetClaims(). Requests without a session are not rejected, handlers
+// redirect to /auth/login?return_to=<path> when a page requires a login.
+func (a *App) UseOIDC(config auth.OIDCConfig) {
+	oidc, err := auth.NewOIDC(context.Background(), config, a.Logger())
+	if err != nil {
+		a.Logger().Errorf("failed to enable OIDC login: %v", err)
+
+		return
+	}
+
+	a.Use(oidc.Middleware())
+}
+
 func (a *App) addAuthMiddleware(httpMW func(http.Handler) http.Handler,
 	grpcUnary grpc.UnaryServerInterceptor, grpcStream grpc.StreamServerInterceptor) {
 	if a.httpServer != nil {
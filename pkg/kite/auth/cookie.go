@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// maxCookieSize is the size above which browsers may drop a cookie.
+const maxCookieSize = 4096
+
+var (
+	errCookieTooLarge = errors.New("cookie value exceeds 4096 bytes")
+	errCookieInvalid  = errors.New("cookie value is invalid")
+)
+
+// cookieCodec encrypts and authenticates cookie values with AES-GCM, so that they can be neither read nor
+// forged by the client. The cookie name is used as additional data, which prevents swapping values between cookies.
+type cookieCodec struct {
+	aead   cipher.AEAD
+	secure bool
+}
+
+func newCookieCodec(secret string, secure bool) (*cookieCodec, error) {
+	key := sha256.Sum256([]byte(secret))
+
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cookieCodec{aead: aead, secure: secure}, nil
+}
+
+func (c *cookieCodec) encode(name string, v any) (string, error) {
+	plain, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	value := base64.RawURLEncoding.EncodeToString(c.aead.Seal(nonce, nonce, plain, []byte(name)))
+	if len(name)+len(value) > maxCookieSize {
+		return "", errCookieTooLarge
+	}
+
+	return value, nil
+}
+
+func (c *cookieCodec) decode(name, value string, v any) error {
+	sealed, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return errCookieInvalid
+	}
+
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+
+	plain, err := c.aead.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return errCookieInvalid
+	}
+
+	return json.Unmarshal(plain, v)
+}
+
+// set writes v to the cookie name. The cookie is HttpOnly and SameSite=Lax, so that it is sent on the top-level
+// redirect back from the identity provider but not on cross-site subrequests.
+func (c *cookieCodec) set(w http.ResponseWriter, name, path string, v any, ttl time.Duration) error {
+	value, err := c.encode(name, v)
+	if err != nil {
+		return err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		MaxAge:   int(ttl.Seconds()),
+		Expires:  time.Now().Add(ttl),
+		HttpOnly: true,
+		Secure:   c.secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return nil
+}
+
+func (c *cookieCodec) get(r *http.Request, name string, v any) error {
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return err
+	}
+
+	return c.decode(name, cookie.Value, v)
+}
+
+func (c *cookieCodec) clear(w http.ResponseWriter, name, path string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    "",
+		Path:     path,
+		MaxAge:   -1,
+		Expires:  time.Unix(0, 0),
+		HttpOnly: true,
+		Secure:   c.secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
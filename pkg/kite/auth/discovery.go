@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const discoveryPath = "/.well-known/openid-configuration"
+
+// providerMetadata holds the fields of the OpenID provider metadata used by kite.
+type providerMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	EndSessionEndpoint    string `json:"end_session_endpoint"`
+}
+
+// discover fetches the provider metadata of issuer, as defined by OpenID Connect Discovery 1.0.
+func discover(ctx context.Context, client *http.Client, issuer string) (*providerMetadata, error) {
+	var metadata providerMetadata
+
+	if err := getJSON(ctx, client, strings.TrimSuffix(issuer, "/")+discoveryPath, &metadata); err != nil {
+		return nil, fmt.Errorf("%w: %w", errDiscovery, err)
+	}
+
+	// the issuer must match exactly, or the iss claim of the ID tokens would never validate.
+	if metadata.Issuer != issuer {
+		return nil, fmt.Errorf("%w: issuer %q does not match %q", errDiscovery, metadata.Issuer, issuer)
+	}
+
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: authorization_endpoint, token_endpoint and jwks_uri are required", errDiscovery)
+	}
+
+	return &metadata, nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned status %d", url, resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// keySet caches the RSA keys of the provider. Keys are fetched again when a token is signed with an unknown
+// key ID, which happens after the provider rotates its keys.
+type keySet struct {
+	client *http.Client
+	url    string
+
+	mu   sync.RWMutex
+	keys map[string]*rsa.PublicKey
+}
+
+type jsonWebKey struct {
+	ID       string `json:"kid"`
+	Type     string `json:"kty"`
+	Modulus  string `json:"n"`
+	Exponent string `json:"e"`
+}
+
+func (s *keySet) keyFunc(ctx context.Context) jwt.Keyfunc {
+	return func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		s.mu.RLock()
+		key, ok := s.keys[kid]
+		s.mu.RUnlock()
+
+		if ok {
+			return key, nil
+		}
+
+		if err := s.refresh(ctx); err != nil {
+			return nil, err
+		}
+
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+
+		if key, ok = s.keys[kid]; ok {
+			return key, nil
+		}
+
+		return nil, fmt.Errorf("%w: %q", errUnknownKey, kid)
+	}
+}
+
+func (s *keySet) refresh(ctx context.Context) error {
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+
+	if err := getJSON(ctx, s.client, s.url, &jwks); err != nil {
+		return err
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(jwks.Keys))
+
+	for _, jwk := range jwks.Keys {
+		if jwk.Type != "RSA" {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(jwk.Modulus)
+		if err != nil {
+			continue
+		}
+
+		e, err := base64.RawURLEncoding.DecodeString(jwk.Exponent)
+		if err != nil {
+			continue
+		}
+
+		keys[jwk.ID] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,392 @@
+// Package auth provides the OpenID Connect login flow for server-rendered applications and internal tools.
+//
+// The OIDC middleware serves the login, callback and logout endpoints, keeps the claims of the signed-in user in
+// an encrypted session cookie, and exposes them to the handlers through ctx.GetAuthInfo().GetClaims().
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+
+	kiteHttp "github.com/sllt/kite/pkg/kite/http"
+	"github.com/sllt/kite/pkg/kite/http/middleware"
+	"github.com/sllt/kite/pkg/kite/logging"
+)
+
+const (
+	defaultCookieName   = "kite_session"
+	defaultSessionTTL   = 8 * time.Hour
+	defaultLoginPath    = "/auth/login"
+	defaultCallbackPath = "/auth/callback"
+	defaultLogoutPath   = "/auth/logout"
+
+	// loginStateTTL bounds the time the user has to sign in at the identity provider.
+	loginStateTTL = 10 * time.Minute
+
+	minCookieSecretLength = 32
+)
+
+var (
+	errIssuerURL    = errors.New("oidc: IssuerURL is required")
+	errClientID     = errors.New("oidc: ClientID is required")
+	errRedirectURL  = errors.New("oidc: RedirectURL is required")
+	errCookieSecret = fmt.Errorf("oidc: CookieSecret must be at least %d bytes", minCookieSecretLength)
+	errDiscovery    = errors.New("oidc: provider discovery failed")
+	errUnknownKey   = errors.New("oidc: unknown signing key")
+	errNoIDToken    = errors.New("oidc: token response does not contain an id_token")
+	errNonce        = errors.New("oidc: nonce does not match")
+)
+
+// OIDCConfig configures the OpenID Connect login flow.
+type OIDCConfig struct {
+	// IssuerURL is the URL of the identity provider, e.g. https://accounts.google.com.
+	// The provider endpoints are discovered from IssuerURL + "/.well-known/openid-configuration".
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the absolute URL of the callback endpoint registered at the provider.
+	RedirectURL string
+	// Scopes requested in addition to "openid". Defaults to "profile" and "email".
+	Scopes []string
+
+	// CookieSecret encrypts the session cookie. It must be at least 32 bytes long and shared by every
+	// instance of the application.
+	CookieSecret string
+	// CookieName is the name of the session cookie. Defaults to "kite_session".
+	CookieName string
+	// SessionTTL is the lifetime of the session. Defaults to 8 hours.
+	SessionTTL time.Duration
+	// InsecureCookies drops the Secure attribute of the cookies, for local development over plain HTTP.
+	InsecureCookies bool
+
+	// LoginPath, CallbackPath and LogoutPath default to /auth/login, /auth/callback and /auth/logout.
+	LoginPath    string
+	CallbackPath string
+	LogoutPath   string
+	// PostLogoutRedirectURL is where the user lands after signing out. Defaults to "/".
+	PostLogoutRedirectURL string
+
+	// HTTPClient is used to talk to the provider. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// OIDC implements the authorization code flow with PKCE against an OpenID Connect provider.
+type OIDC struct {
+	config   OIDCConfig
+	metadata *providerMetadata
+	oauth    *oauth2.Config
+	keys     *keySet
+	cookies  *cookieCodec
+	logger   logging.Logger
+}
+
+// loginState is kept in a short-lived cookie between the login redirect and the callback.
+type loginState struct {
+	State    string `json:"s"`
+	Nonce    string `json:"n"`
+	Verifier string `json:"v"`
+	ReturnTo string `json:"r"`
+}
+
+// session is the content of the session cookie.
+type session struct {
+	Claims  jwt.MapClaims `json:"c"`
+	Expires int64         `json:"e"`
+}
+
+// NewOIDC validates the config and discovers the endpoints of the provider.
+func NewOIDC(ctx context.Context, config OIDCConfig, logger logging.Logger) (*OIDC, error) {
+	switch {
+	case config.IssuerURL == "":
+		return nil, errIssuerURL
+	case config.ClientID == "":
+		return nil, errClientID
+	case config.RedirectURL == "":
+		return nil, errRedirectURL
+	case len(config.CookieSecret) < minCookieSecretLength:
+		return nil, errCookieSecret
+	}
+
+	setDefaults(&config)
+
+	metadata, err := discover(ctx, config.HTTPClient, config.IssuerURL)
+	if err != nil {
+		return nil, err
+	}
+
+	cookies, err := newCookieCodec(config.CookieSecret, !config.InsecureCookies)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OIDC{
+		config:   config,
+		metadata: metadata,
+		oauth: &oauth2.Config{
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			RedirectURL:  config.RedirectURL,
+			Scopes:       append([]string{"openid"}, config.Scopes...),
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  metadata.AuthorizationEndpoint,
+				TokenURL: metadata.TokenEndpoint,
+			},
+		},
+		keys:    &keySet{client: config.HTTPClient, url: metadata.JWKSURI},
+		cookies: cookies,
+		logger:  logger,
+	}, nil
+}
+
+func setDefaults(config *OIDCConfig) {
+	if config.Scopes == nil {
+		config.Scopes = []string{"profile", "email"}
+	}
+
+	if config.CookieName == "" {
+		config.CookieName = defaultCookieName
+	}
+
+	if config.SessionTTL <= 0 {
+		config.SessionTTL = defaultSessionTTL
+	}
+
+	if config.LoginPath == "" {
+		config.LoginPath = defaultLoginPath
+	}
+
+	if config.CallbackPath == "" {
+		config.CallbackPath = defaultCallbackPath
+	}
+
+	if config.LogoutPath == "" {
+		config.LogoutPath = defaultLogoutPath
+	}
+
+	if config.PostLogoutRedirectURL == "" {
+		config.PostLogoutRedirectURL = "/"
+	}
+
+	if config.HTTPClient == nil {
+		config.HTTPClient = http.DefaultClient
+	}
+}
+
+// Middleware serves the login, callback and logout endpoints, and adds the claims of the signed-in user to the
+// context of every other request. Requests without a valid session pass through without claims, so handlers
+// decide which pages require a login.
+func (o *OIDC) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.URL.Path == o.config.LoginPath && r.Method == http.MethodGet:
+				o.login(w, r)
+			case r.URL.Path == o.config.CallbackPath && r.Method == http.MethodGet:
+				o.callback(w, r)
+			case r.URL.Path == o.config.LogoutPath && (r.Method == http.MethodGet || r.Method == http.MethodPost):
+				o.logout(w, r)
+			default:
+				if claims := o.claims(r); claims != nil {
+					*r = *r.WithContext(context.WithValue(r.Context(), middleware.JWTClaim, claims))
+				}
+
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// login redirects the user to the provider. The optional return_to query parameter is the local path the user
+// is sent to after signing in.
+func (o *OIDC) login(w http.ResponseWriter, r *http.Request) {
+	state := loginState{
+		State:    randomString(),
+		Nonce:    randomString(),
+		Verifier: oauth2.GenerateVerifier(),
+		ReturnTo: localPath(r.URL.Query().Get("return_to")),
+	}
+
+	if err := o.cookies.set(w, o.stateCookieName(), o.config.CallbackPath, state, loginStateTTL); err != nil {
+		o.respondError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, o.oauth.AuthCodeURL(state.State,
+		oauth2.S256ChallengeOption(state.Verifier), oauth2.SetAuthURLParam("nonce", state.Nonce)), http.StatusFound)
+}
+
+// callback exchanges the authorization code for tokens, verifies the ID token and starts the session.
+func (o *OIDC) callback(w http.ResponseWriter, r *http.Request) {
+	var state loginState
+
+	if err := o.cookies.get(r, o.stateCookieName(), &state); err != nil {
+		o.respondError(w, r, loginError{reason: "login state is missing or expired"})
+		return
+	}
+
+	o.cookies.clear(w, o.stateCookieName(), o.config.CallbackPath)
+
+	query := r.URL.Query()
+
+	if providerErr := query.Get("error"); providerErr != "" {
+		o.respondError(w, r, loginError{reason: "provider returned " + providerErr})
+		return
+	}
+
+	if subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(state.State)) != 1 {
+		o.respondError(w, r, loginError{reason: "state does not match"})
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), oauth2.HTTPClient, o.config.HTTPClient)
+
+	token, err := o.oauth.Exchange(ctx, query.Get("code"), oauth2.VerifierOption(state.Verifier))
+	if err != nil {
+		o.respondError(w, r, loginError{reason: "code exchange failed", err: err})
+		return
+	}
+
+	claims, err := o.verifyIDToken(ctx, token, state.Nonce)
+	if err != nil {
+		o.respondError(w, r, loginError{reason: "invalid id_token", err: err})
+		return
+	}
+
+	sess := session{Claims: claims, Expires: time.Now().Add(o.config.SessionTTL).Unix()}
+
+	if err = o.cookies.set(w, o.config.CookieName, "/", sess, o.config.SessionTTL); err != nil {
+		o.respondError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, state.ReturnTo, http.StatusFound)
+}
+
+// logout ends the session and, if the provider supports it, the session at the provider.
+func (o *OIDC) logout(w http.ResponseWriter, r *http.Request) {
+	o.cookies.clear(w, o.config.CookieName, "/")
+
+	redirect := o.config.PostLogoutRedirectURL
+
+	if o.metadata.EndSessionEndpoint != "" {
+		params := url.Values{"client_id": {o.config.ClientID}}
+		if u, err := url.Parse(redirect); err == nil && u.IsAbs() {
+			params.Set("post_logout_redirect_uri", redirect)
+		}
+
+		redirect = o.metadata.EndSessionEndpoint + separator(o.metadata.EndSessionEndpoint) + params.Encode()
+	}
+
+	http.Redirect(w, r, redirect, http.StatusFound)
+}
+
+func (o *OIDC) verifyIDToken(ctx context.Context, token *oauth2.Token, nonce string) (jwt.MapClaims, error) {
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, errNoIDToken
+	}
+
+	claims := jwt.MapClaims{}
+
+	_, err := jwt.ParseWithClaims(rawIDToken, claims, o.keys.keyFunc(ctx),
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512"}),
+		jwt.WithIssuer(o.metadata.Issuer),
+		jwt.WithAudience(o.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt())
+	if err != nil {
+		return nil, err
+	}
+
+	if claimNonce, _ := claims["nonce"].(string); subtle.ConstantTimeCompare([]byte(claimNonce), []byte(nonce)) != 1 {
+		return nil, errNonce
+	}
+
+	return claims, nil
+}
+
+// claims returns the claims of the session of r, or nil if r has no valid session.
+func (o *OIDC) claims(r *http.Request) jwt.MapClaims {
+	var sess session
+
+	if err := o.cookies.get(r, o.config.CookieName, &sess); err != nil {
+		return nil
+	}
+
+	if time.Now().Unix() >= sess.Expires {
+		return nil
+	}
+
+	return sess.Claims
+}
+
+func (o *OIDC) stateCookieName() string {
+	return o.config.CookieName + "_state"
+}
+
+func (o *OIDC) respondError(w http.ResponseWriter, r *http.Request, err error) {
+	if o.logger != nil {
+		if cause := errors.Unwrap(err); cause != nil {
+			o.logger.Errorf("oidc: %v: %v", err, cause)
+		} else {
+			o.logger.Errorf("oidc: %v", err)
+		}
+	}
+
+	kiteHttp.NewResponder(w, r.Method).Respond(nil, err)
+}
+
+// loginError is returned to the user when the login flow cannot complete.
+type loginError struct {
+	reason string
+	err    error
+}
+
+// Error does not include the underlying error, which may contain details of the provider.
+func (e loginError) Error() string {
+	return "login failed: " + e.reason
+}
+
+func (e loginError) Unwrap() error {
+	return e.err
+}
+
+func (loginError) StatusCode() int {
+	return http.StatusUnauthorized
+}
+
+func randomString() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// localPath returns p if it is a path on this host, and "/" otherwise, so that return_to cannot be used as an
+// open redirect.
+func localPath(p string) string {
+	if !strings.HasPrefix(p, "/") || strings.HasPrefix(p, "//") || strings.Contains(p, `\`) {
+		return "/"
+	}
+
+	return p
+}
+
+func separator(endpoint string) string {
+	if strings.Contains(endpoint, "?") {
+		return "&"
+	}
+
+	return "?"
+}
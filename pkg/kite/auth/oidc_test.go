@@ -0,0 +1,296 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sllt/kite/pkg/kite/http/middleware"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+type testProvider struct {
+	*httptest.Server
+	key   *rsa.PrivateKey
+	nonce string
+}
+
+// newTestProvider starts an OpenID provider which issues an ID token for every authorization code.
+func newTestProvider(t *testing.T) *testProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	p := &testProvider{key: key}
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.URL,
+			"authorization_endpoint": p.URL + "/authorize",
+			"token_endpoint":         p.URL + "/token",
+			"jwks_uri":               p.URL + "/jwks",
+			"end_session_endpoint":   p.URL + "/logout",
+		})
+	})
+
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kid": "test",
+			"kty": "RSA",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+
+		if r.Form.Get("code") != "valid-code" || r.Form.Get("code_verifier") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"iss":   p.URL,
+			"aud":   "kite-client",
+			"sub":   "user-1",
+			"email": "user@example.com",
+			"nonce": p.nonce,
+			"iat":   time.Now().Unix(),
+			"exp":   time.Now().Add(time.Hour).Unix(),
+		})
+		token.Header["kid"] = "test"
+
+		idToken, _ := token.SignedString(key)
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     idToken,
+		})
+	})
+
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+
+	return p
+}
+
+func newTestOIDC(t *testing.T, p *testProvider) *OIDC {
+	t.Helper()
+
+	o, err := NewOIDC(context.Background(), OIDCConfig{
+		IssuerURL:    p.URL,
+		ClientID:     "kite-client",
+		ClientSecret: "secret",
+		RedirectURL:  "https://app.example.com/auth/callback",
+		CookieSecret: testSecret,
+	}, nil)
+	require.NoError(t, err)
+
+	return o
+}
+
+func claimsHandler(w http.ResponseWriter, r *http.Request) {
+	claims, _ := r.Context().Value(middleware.JWTClaim).(jwt.MapClaims)
+	_ = json.NewEncoder(w).Encode(claims)
+}
+
+func serve(h http.Handler, r *http.Request, cookies ...*http.Cookie) *http.Response {
+	for _, c := range cookies {
+		r.AddCookie(c)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	return w.Result()
+}
+
+func cookie(t *testing.T, resp *http.Response, name string) *http.Cookie {
+	t.Helper()
+
+	for _, c := range resp.Cookies() {
+		if c.Name == name {
+			return c
+		}
+	}
+
+	t.Fatalf("cookie %s not set", name)
+
+	return nil
+}
+
+func TestOIDC_LoginFlow(t *testing.T) {
+	p := newTestProvider(t)
+	h := newTestOIDC(t, p).Middleware()(http.HandlerFunc(claimsHandler))
+
+	// login redirects to the provider with PKCE and a nonce.
+	resp := serve(h, httptest.NewRequest(http.MethodGet, "/auth/login?return_to=/reports", http.NoBody))
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+
+	authURL, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, p.URL+"/authorize", authURL.Scheme+"://"+authURL.Host+authURL.Path)
+	assert.Equal(t, "S256", authURL.Query().Get("code_challenge_method"))
+	assert.Equal(t, "openid profile email", authURL.Query().Get("scope"))
+
+	stateCookie := cookie(t, resp, "kite_session_state")
+	assert.True(t, stateCookie.HttpOnly)
+	assert.True(t, stateCookie.Secure)
+	assert.Equal(t, http.SameSiteLaxMode, stateCookie.SameSite)
+
+	p.nonce = authURL.Query().Get("nonce")
+
+	// callback exchanges the code and starts the session.
+	resp = serve(h, httptest.NewRequest(http.MethodGet,
+		"/auth/callback?code=valid-code&state="+authURL.Query().Get("state"), http.NoBody), stateCookie)
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+	assert.Equal(t, "/reports", resp.Header.Get("Location"))
+
+	sessionCookie := cookie(t, resp, "kite_session")
+	assert.Equal(t, "/", sessionCookie.Path)
+	assert.NotContains(t, sessionCookie.Value, "user@example.com")
+
+	// the claims are available to the handlers.
+	resp = serve(h, httptest.NewRequest(http.MethodGet, "/reports", http.NoBody), sessionCookie)
+
+	var claims map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&claims))
+	assert.Equal(t, "user-1", claims["sub"])
+	assert.Equal(t, "user@example.com", claims["email"])
+
+	// logout clears the session and ends it at the provider.
+	resp = serve(h, httptest.NewRequest(http.MethodPost, "/auth/logout", http.NoBody), sessionCookie)
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+	assert.True(t, strings.HasPrefix(resp.Header.Get("Location"), p.URL+"/logout?client_id=kite-client"))
+	assert.Equal(t, -1, cookie(t, resp, "kite_session").MaxAge)
+}
+
+func TestOIDC_CallbackErrors(t *testing.T) {
+	p := newTestProvider(t)
+	h := newTestOIDC(t, p).Middleware()(http.HandlerFunc(claimsHandler))
+
+	login := func() (*http.Cookie, url.Values) {
+		resp := serve(h, httptest.NewRequest(http.MethodGet, "/auth/login", http.NoBody))
+		authURL, _ := url.Parse(resp.Header.Get("Location"))
+
+		return cookie(t, resp, "kite_session_state"), authURL.Query()
+	}
+
+	tests := []struct {
+		desc  string
+		query func(params url.Values) string
+		nonce func(params url.Values) string
+	}{
+		{"missing state cookie", nil, nil},
+		{"state mismatch",
+			func(url.Values) string { return "code=valid-code&state=forged" },
+			func(params url.Values) string { return params.Get("nonce") }},
+		{"provider error",
+			func(params url.Values) string { return "error=access_denied&state=" + params.Get("state") },
+			func(params url.Values) string { return params.Get("nonce") }},
+		{"invalid code",
+			func(params url.Values) string { return "code=invalid&state=" + params.Get("state") },
+			func(params url.Values) string { return params.Get("nonce") }},
+		{"nonce mismatch",
+			func(params url.Values) string { return "code=valid-code&state=" + params.Get("state") },
+			func(url.Values) string { return "replayed" }},
+	}
+
+	for i, tc := range tests {
+		stateCookie, params := login()
+
+		req := httptest.NewRequest(http.MethodGet, "/auth/callback?code=valid-code&state="+params.Get("state"), http.NoBody)
+
+		var resp *http.Response
+
+		if tc.query == nil {
+			resp = serve(h, req)
+		} else {
+			p.nonce = tc.nonce(params)
+			req = httptest.NewRequest(http.MethodGet, "/auth/callback?"+tc.query(params), http.NoBody)
+			resp = serve(h, req, stateCookie)
+		}
+
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, "TEST[%d], Failed.\n%s", i, tc.desc)
+
+		for _, c := range resp.Cookies() {
+			assert.NotEqual(t, "kite_session", c.Name, "TEST[%d], Failed.\n%s", i, tc.desc)
+		}
+	}
+}
+
+func TestOIDC_InvalidSessionCookie(t *testing.T) {
+	p := newTestProvider(t)
+	o := newTestOIDC(t, p)
+	h := o.Middleware()(http.HandlerFunc(claimsHandler))
+
+	expired, err := o.cookies.encode("kite_session", session{
+		Claims: jwt.MapClaims{"sub": "user-1"}, Expires: time.Now().Add(-time.Minute).Unix()})
+	require.NoError(t, err)
+
+	for i, value := range []string{"tampered", expired} {
+		resp := serve(h, httptest.NewRequest(http.MethodGet, "/", http.NoBody),
+			&http.Cookie{Name: "kite_session", Value: value})
+
+		var claims map[string]any
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&claims))
+		assert.Nil(t, claims, "TEST[%d], Failed.", i)
+	}
+}
+
+func TestNewOIDC_Errors(t *testing.T) {
+	p := newTestProvider(t)
+
+	tests := []struct {
+		desc   string
+		config OIDCConfig
+		err    error
+	}{
+		{"missing issuer", OIDCConfig{}, errIssuerURL},
+		{"missing client", OIDCConfig{IssuerURL: p.URL}, errClientID},
+		{"missing redirect", OIDCConfig{IssuerURL: p.URL, ClientID: "c"}, errRedirectURL},
+		{"short secret", OIDCConfig{IssuerURL: p.URL, ClientID: "c", RedirectURL: "/cb", CookieSecret: "short"}, errCookieSecret},
+		{"issuer mismatch", OIDCConfig{IssuerURL: p.URL + "/", ClientID: "c", RedirectURL: "/cb", CookieSecret: testSecret},
+			errDiscovery},
+	}
+
+	for i, tc := range tests {
+		_, err := NewOIDC(context.Background(), tc.config, nil)
+
+		assert.ErrorIs(t, err, tc.err, "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestLocalPath(t *testing.T) {
+	tests := map[string]string{
+		"":                     "/",
+		"/reports?id=1":        "/reports?id=1",
+		"https://evil.example": "/",
+		"//evil.example":       "/",
+		`/\evil.example`:       "/",
+	}
+
+	for in, expected := range tests {
+		assert.Equal(t, expected, localPath(in), in)
+	}
+}
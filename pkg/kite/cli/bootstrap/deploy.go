@@ -0,0 +1,210 @@
+package bootstrap
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+var (
+	ErrUnknownComponent = errors.New("unknown component, supported: docker, k8s, helm, postgres, mysql, redis")
+	ErrNoDeployTarget   = errors.New("the datasources need a deployment target: docker, k8s or helm")
+	ErrSQLConflict      = errors.New("postgres and mysql cannot be used together")
+	ErrGenerateFailed   = errors.New("failed to generate deployment files")
+)
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// envVar is an environment variable of the application.
+type envVar struct {
+	Key   string
+	Value string
+}
+
+// deployData is the data of the deployment templates.
+type deployData struct {
+	Name     string
+	Postgres bool
+	MySQL    bool
+	Redis    bool
+	// Datasources are the names of the datasource services, in the order of the docker-compose.yaml.
+	Datasources []string
+	Env         []envVar
+	Secrets     []envVar
+}
+
+// deployFile is a file generated for a component. Raw files are written as is.
+type deployFile struct {
+	path     string
+	template string
+	raw      bool
+}
+
+// Generate writes the deployment files of the components to the project directory:
+//
+//   - docker: a multi-stage Dockerfile, a .dockerignore and a docker-compose.yaml running the application with its
+//     datasources,
+//   - k8s: the Deployment, Service and ConfigMap manifests under deploy/k8s,
+//   - helm: a Helm chart under deploy/helm/<name>,
+//   - postgres, mysql, redis: the datasources of the application, configured through its environment.
+//
+// The probes of the manifests are wired to the health endpoints of kite. The existing files are not overwritten.
+func Generate(dir, name string, with []string) (string, error) {
+	data, targets, err := newDeployData(name, with)
+	if err != nil {
+		return "", err
+	}
+
+	var files []deployFile
+
+	if targets["docker"] {
+		files = append(files,
+			deployFile{path: "Dockerfile", template: dockerfileTemplate},
+			deployFile{path: ".dockerignore", template: dockerignoreTemplate},
+			deployFile{path: "docker-compose.yaml", template: composeTemplate})
+	}
+
+	if targets["k8s"] {
+		files = append(files,
+			deployFile{path: "deploy/k8s/deployment.yaml", template: k8sDeploymentTemplate},
+			deployFile{path: "deploy/k8s/service.yaml", template: k8sServiceTemplate},
+			deployFile{path: "deploy/k8s/config.yaml", template: k8sConfigTemplate})
+	}
+
+	if targets["helm"] {
+		chart := filepath.Join("deploy/helm", data.Name)
+
+		files = append(files,
+			deployFile{path: filepath.Join(chart, "Chart.yaml"), template: helmChartTemplate},
+			deployFile{path: filepath.Join(chart, "values.yaml"), template: helmValuesTemplate},
+			deployFile{path: filepath.Join(chart, "templates/deployment.yaml"), template: helmDeploymentTemplate, raw: true},
+			deployFile{path: filepath.Join(chart, "templates/service.yaml"), template: helmServiceTemplate, raw: true},
+			deployFile{path: filepath.Join(chart, "templates/config.yaml"), template: helmConfigTemplate, raw: true})
+	}
+
+	results := make([]string, 0, len(files))
+
+	for _, f := range files {
+		result, err := writeDeployFile(filepath.Join(dir, f.path), f, data)
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrGenerateFailed, err)
+		}
+
+		results = append(results, result)
+	}
+
+	return strings.Join(results, "\n"), nil
+}
+
+// newDeployData returns the data of the templates for the components, and the deployment targets among them.
+func newDeployData(name string, with []string) (*deployData, map[string]bool, error) {
+	data := &deployData{Name: resourceName(name)}
+	targets := make(map[string]bool)
+
+	for _, component := range with {
+		switch strings.ToLower(strings.TrimSpace(component)) {
+		case "":
+		case "docker", "k8s", "helm":
+			targets[strings.ToLower(strings.TrimSpace(component))] = true
+		case "kubernetes":
+			targets["k8s"] = true
+		case "postgres", "postgresql":
+			data.Postgres = true
+		case "mysql":
+			data.MySQL = true
+		case "redis":
+			data.Redis = true
+		default:
+			return nil, nil, fmt.Errorf("%w: %q", ErrUnknownComponent, component)
+		}
+	}
+
+	if data.Postgres && data.MySQL {
+		return nil, nil, ErrSQLConflict
+	}
+
+	if len(targets) == 0 && (data.Postgres || data.MySQL || data.Redis) {
+		return nil, nil, ErrNoDeployTarget
+	}
+
+	data.Env = []envVar{
+		{"APP_NAME", data.Name},
+		{"HTTP_PORT", "8000"},
+		{"METRICS_PORT", "2121"},
+		{"LOG_LEVEL", "INFO"},
+		{"READINESS_DRAIN_DELAY", "10"},
+	}
+
+	switch {
+	case data.Postgres:
+		data.Datasources = append(data.Datasources, "postgres")
+		data.Env = append(data.Env, envVar{"DB_DIALECT", "postgres"}, envVar{"DB_HOST", "postgres"},
+			envVar{"DB_PORT", "5432"}, envVar{"DB_USER", "postgres"}, envVar{"DB_NAME", data.Name})
+		data.Secrets = append(data.Secrets, envVar{"DB_PASSWORD", "password"})
+	case data.MySQL:
+		data.Datasources = append(data.Datasources, "mysql")
+		data.Env = append(data.Env, envVar{"DB_DIALECT", "mysql"}, envVar{"DB_HOST", "mysql"},
+			envVar{"DB_PORT", "3306"}, envVar{"DB_USER", "root"}, envVar{"DB_NAME", data.Name})
+		data.Secrets = append(data.Secrets, envVar{"DB_PASSWORD", "password"})
+	}
+
+	if data.Redis {
+		data.Datasources = append(data.Datasources, "redis")
+		data.Env = append(data.Env, envVar{"REDIS_HOST", "redis"}, envVar{"REDIS_PORT", "6379"})
+	}
+
+	return data, targets, nil
+}
+
+// resourceName returns a name valid for the docker-compose services and the Kubernetes resources, from the last
+// element of the project name, e.g. "orders" for github.com/acme/orders.
+func resourceName(projectName string) string {
+	name := invalidNameChars.ReplaceAllString(strings.ToLower(filepath.Base(projectName)), "-")
+
+	name = strings.Trim(name, "-")
+	if name == "" {
+		return "app"
+	}
+
+	return name
+}
+
+// writeDeployFile writes the file from its template, unless it already exists.
+func writeDeployFile(path string, f deployFile, data *deployData) (string, error) {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Sprintf("Skipped: %s (already exists)", f.path), nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return "", err
+	}
+
+	out, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	if f.raw {
+		if _, err := out.WriteString(f.template); err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("Created: %s", f.path), nil
+	}
+
+	tmpl, err := template.New(f.path).Parse(f.template)
+	if err != nil {
+		return "", err
+	}
+
+	if err := tmpl.Execute(out, data); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Created: %s", f.path), nil
+}
@@ -23,12 +23,18 @@ var (
 	ErrProjectExists = errors.New("project directory already exists")
 )
 
-// Create initializes a new Kite project by cloning kite-layout and replacing package names.
-func Create(projectName string) error {
+// Create initializes a new Kite project by cloning kite-layout and replacing package names. The deployment files
+// of the components in with, e.g. "docker", "k8s" and "postgres", are generated afterward, see Generate.
+func Create(projectName string, with ...string) error {
 	if projectName == "" {
 		return ErrNameEmpty
 	}
 
+	// the components are checked before anything is cloned.
+	if _, _, err := newDeployData(projectName, with); err != nil {
+		return err
+	}
+
 	// Check if directory already exists
 	if stat, _ := os.Stat(projectName); stat != nil {
 		return ErrProjectExists
@@ -62,6 +68,18 @@ func Create(projectName string) error {
 		fmt.Printf("Warning: go mod tidy failed: %v (you may need to run it manually)\n", err)
 	}
 
+	// Step 6: Generate the deployment files
+	if len(with) > 0 {
+		fmt.Println("Generating deployment files...")
+
+		result, err := Generate(projectName, projectName, with)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(result)
+	}
+
 	fmt.Printf("\nProject %s created successfully!\n\n", projectName)
 	fmt.Printf("Next steps:\n")
 	fmt.Printf("  cd %s\n", projectName)
@@ -0,0 +1,335 @@
+package bootstrap
+
+// Multi-stage Dockerfile building the server of the kite-layout project
+const dockerfileTemplate = `FROM golang:1.24-alpine AS build
+
+WORKDIR /src
+
+COPY go.mod go.sum ./
+RUN go mod download
+
+COPY . .
+RUN CGO_ENABLED=0 go build -trimpath -ldflags "-s -w" -o /out/server ./cmd/server
+
+FROM alpine:3.21
+
+RUN apk add --no-cache tzdata ca-certificates && adduser -D -u 10001 app
+
+WORKDIR /app
+COPY --from=build /out/server /app/server
+
+USER app
+
+EXPOSE 8000 2121
+
+CMD ["/app/server"]
+`
+
+const dockerignoreTemplate = `.git
+.idea
+.vscode
+*.log
+deploy
+docker-compose.yaml
+`
+
+// docker-compose.yaml running the application with its datasources
+const composeTemplate = `services:
+  {{ .Name }}:
+    build: .
+    ports:
+      - "8000:8000"
+      - "2121:2121"
+    environment:
+{{- range .Env }}
+      {{ .Key }}: "{{ .Value }}"
+{{- end }}
+{{- range .Secrets }}
+      {{ .Key }}: "{{ .Value }}"
+{{- end }}
+{{- if .Datasources }}
+    depends_on:
+{{- range .Datasources }}
+      {{ . }}:
+        condition: service_healthy
+{{- end }}
+{{- end }}
+    healthcheck:
+      test: ["CMD", "wget", "-qO-", "http://localhost:8000/.well-known/alive"]
+      interval: 10s
+      timeout: 3s
+      retries: 3
+{{- if .Postgres }}
+
+  postgres:
+    image: postgres:17-alpine
+    environment:
+      POSTGRES_DB: "{{ .Name }}"
+      POSTGRES_PASSWORD: "password"
+    ports:
+      - "5432:5432"
+    healthcheck:
+      test: ["CMD-SHELL", "pg_isready -U postgres"]
+      interval: 5s
+      timeout: 3s
+      retries: 10
+{{- end }}
+{{- if .MySQL }}
+
+  mysql:
+    image: mysql:8.4
+    environment:
+      MYSQL_DATABASE: "{{ .Name }}"
+      MYSQL_ROOT_PASSWORD: "password"
+    ports:
+      - "3306:3306"
+    healthcheck:
+      test: ["CMD", "mysqladmin", "ping", "-h", "localhost", "-ppassword"]
+      interval: 5s
+      timeout: 3s
+      retries: 10
+{{- end }}
+{{- if .Redis }}
+
+  redis:
+    image: redis:7-alpine
+    ports:
+      - "6379:6379"
+    healthcheck:
+      test: ["CMD", "redis-cli", "ping"]
+      interval: 5s
+      timeout: 3s
+      retries: 10
+{{- end }}
+`
+
+// Kubernetes ConfigMap and Secret holding the configs of the application
+const k8sConfigTemplate = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Name }}
+  labels:
+    app: {{ .Name }}
+data:
+{{- range .Env }}
+  {{ .Key }}: "{{ .Value }}"
+{{- end }}
+{{- if .Secrets }}
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: {{ .Name }}
+  labels:
+    app: {{ .Name }}
+type: Opaque
+stringData:
+{{- range .Secrets }}
+  {{ .Key }}: "{{ .Value }}"
+{{- end }}
+{{- end }}
+`
+
+// Kubernetes Deployment with the probes wired to the health endpoints of kite
+const k8sDeploymentTemplate = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Name }}
+  labels:
+    app: {{ .Name }}
+spec:
+  replicas: 2
+  selector:
+    matchLabels:
+      app: {{ .Name }}
+  template:
+    metadata:
+      labels:
+        app: {{ .Name }}
+    spec:
+      # covers READINESS_DRAIN_DELAY and SHUTDOWN_GRACE_PERIOD.
+      terminationGracePeriodSeconds: 45
+      containers:
+        - name: {{ .Name }}
+          image: {{ .Name }}:latest
+          ports:
+            - name: http
+              containerPort: 8000
+            - name: metrics
+              containerPort: 2121
+          envFrom:
+            - configMapRef:
+                name: {{ .Name }}
+{{- if .Secrets }}
+            - secretRef:
+                name: {{ .Name }}
+{{- end }}
+          readinessProbe:
+            httpGet:
+              path: /.well-known/health
+              port: http
+            periodSeconds: 2
+          livenessProbe:
+            httpGet:
+              path: /.well-known/alive
+              port: http
+            periodSeconds: 10
+          resources:
+            requests:
+              cpu: 100m
+              memory: 128Mi
+            limits:
+              memory: 256Mi
+`
+
+const k8sServiceTemplate = `apiVersion: v1
+kind: Service
+metadata:
+  name: {{ .Name }}
+  labels:
+    app: {{ .Name }}
+spec:
+  selector:
+    app: {{ .Name }}
+  ports:
+    - name: http
+      port: 80
+      targetPort: http
+    - name: metrics
+      port: 2121
+      targetPort: metrics
+`
+
+const helmChartTemplate = `apiVersion: v2
+name: {{ .Name }}
+description: Helm chart of {{ .Name }}
+type: application
+version: 0.1.0
+appVersion: "0.1.0"
+`
+
+const helmValuesTemplate = `replicaCount: 2
+
+image:
+  repository: {{ .Name }}
+  tag: latest
+  pullPolicy: IfNotPresent
+
+service:
+  port: 80
+
+# covers READINESS_DRAIN_DELAY and SHUTDOWN_GRACE_PERIOD.
+terminationGracePeriodSeconds: 45
+
+env:
+{{- range .Env }}
+  {{ .Key }}: "{{ .Value }}"
+{{- end }}
+
+secretEnv:
+{{- range .Secrets }}
+  {{ .Key }}: "{{ .Value }}"
+{{- else }} {}
+{{- end }}
+
+resources:
+  requests:
+    cpu: 100m
+    memory: 128Mi
+  limits:
+    memory: 256Mi
+`
+
+// The templates of the helm chart are written as is, they are executed by helm.
+const helmConfigTemplate = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Release.Name }}
+  labels:
+    app: {{ .Release.Name }}
+data:
+  {{- range $key, $value := .Values.env }}
+  {{ $key }}: {{ $value | quote }}
+  {{- end }}
+{{- if .Values.secretEnv }}
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: {{ .Release.Name }}
+  labels:
+    app: {{ .Release.Name }}
+type: Opaque
+stringData:
+  {{- range $key, $value := .Values.secretEnv }}
+  {{ $key }}: {{ $value | quote }}
+  {{- end }}
+{{- end }}
+`
+
+const helmDeploymentTemplate = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }}
+  labels:
+    app: {{ .Release.Name }}
+spec:
+  replicas: {{ .Values.replicaCount }}
+  selector:
+    matchLabels:
+      app: {{ .Release.Name }}
+  template:
+    metadata:
+      labels:
+        app: {{ .Release.Name }}
+      annotations:
+        checksum/config: {{ include (print $.Template.BasePath "/config.yaml") . | sha256sum }}
+    spec:
+      terminationGracePeriodSeconds: {{ .Values.terminationGracePeriodSeconds }}
+      containers:
+        - name: {{ .Chart.Name }}
+          image: "{{ .Values.image.repository }}:{{ .Values.image.tag }}"
+          imagePullPolicy: {{ .Values.image.pullPolicy }}
+          ports:
+            - name: http
+              containerPort: 8000
+            - name: metrics
+              containerPort: 2121
+          envFrom:
+            - configMapRef:
+                name: {{ .Release.Name }}
+            {{- if .Values.secretEnv }}
+            - secretRef:
+                name: {{ .Release.Name }}
+            {{- end }}
+          readinessProbe:
+            httpGet:
+              path: /.well-known/health
+              port: http
+            periodSeconds: 2
+          livenessProbe:
+            httpGet:
+              path: /.well-known/alive
+              port: http
+            periodSeconds: 10
+          resources:
+            {{- toYaml .Values.resources | nindent 12 }}
+`
+
+const helmServiceTemplate = `apiVersion: v1
+kind: Service
+metadata:
+  name: {{ .Release.Name }}
+  labels:
+    app: {{ .Release.Name }}
+spec:
+  selector:
+    app: {{ .Release.Name }}
+  ports:
+    - name: http
+      port: {{ .Values.service.port }}
+      targetPort: http
+    - name: metrics
+      port: 2121
+      targetPort: metrics
+`
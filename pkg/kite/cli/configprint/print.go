@@ -0,0 +1,41 @@
+// Package configprint implements `kite config print`, which renders the configuration report that a Kite
+// application logs at startup, from its project directory.
+package configprint
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/sllt/kite/pkg/kite/config"
+)
+
+// configsDir is the directory of the config files in a Kite project.
+const configsDir = "configs"
+
+// Print returns the configuration report of the project in dir, as JSON if asJSON is set. The configuration is read
+// from the config files of the project and the environment, the way the application reads it. The datasources are
+// the ones configured, and the ports the ones of every server.
+func Print(dir string, asJSON bool) (string, error) {
+	values, err := config.Snapshot(filepath.Join(dir, configsDir))
+	if err != nil {
+		return "", err
+	}
+
+	report := config.NewReport(values)
+
+	if asJSON {
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return "", err
+		}
+
+		return string(out), nil
+	}
+
+	var buf bytes.Buffer
+
+	report.PrettyPrint(&buf)
+
+	return buf.String(), nil
+}
@@ -0,0 +1,33 @@
+package migration
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Up runs the project in dir to apply its pending migrations selected by the datasources and tags, then exits
+// without starting the servers. The application must call app.Migrate before app.Run.
+func Up(dir string, datasources, tags []string) error {
+	cmd := exec.Command("go", "run", ".")
+	cmd.Dir = dir
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), upEnv(datasources, tags)...)
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error while running the migrations, err: %w", err)
+	}
+
+	return nil
+}
+
+// upEnv returns the configs of the application restricting it to the migrations.
+func upEnv(datasources, tags []string) []string {
+	return []string{
+		"MIGRATIONS_ONLY=true",
+		"MIGRATIONS_DATASOURCES=" + strings.Join(datasources, ","),
+		"MIGRATIONS_TAGS=" + strings.Join(tags, ","),
+	}
+}
@@ -0,0 +1,186 @@
+// Package replay implements `kite replay`, which resends the requests captured by the request capture of a Kite
+// application to a local build, to reproduce the bugs only seen in production.
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// redactedValue is the value of the headers, query parameters and body fields masked in the captured requests.
+const redactedValue = "[REDACTED]"
+
+var (
+	errInvalidHeader = errors.New("invalid header, expecting \"Name: value\"")
+	errNoRequests    = errors.New("no captured request found")
+)
+
+// Options holds the options of a replay.
+type Options struct {
+	// Target is the base URL of the application the requests are sent to, e.g. http://localhost:8000.
+	Target string
+	// Headers are set on every request, as "Name: value", e.g. the Authorization masked in the captured requests.
+	Headers []string
+	// Timeout is the timeout of each request.
+	Timeout time.Duration
+}
+
+// capturedRequest is a request captured by the request capture, as written to its file.
+type capturedRequest struct {
+	TraceID     string      `json:"trace_id"`
+	Method      string      `json:"method"`
+	URI         string      `json:"uri"`
+	Header      http.Header `json:"header"`
+	Body        string      `json:"body"`
+	BodyOmitted bool        `json:"body_omitted"`
+	Status      int         `json:"status"`
+}
+
+// Replay resends the requests captured in file, one JSON object per line, to opts.Target, in their order. It returns
+// the status of each replayed request next to the captured one. The requests whose body was too large to be captured
+// are skipped, and the masked headers are dropped unless set by opts.Headers.
+func Replay(ctx context.Context, file string, opts Options) (string, error) {
+	overrides, err := parseHeaders(opts.Headers)
+	if err != nil {
+		return "", err
+	}
+
+	requests, err := readCaptured(file)
+	if err != nil {
+		return "", err
+	}
+
+	client := &http.Client{Timeout: opts.Timeout}
+	target := strings.TrimSuffix(opts.Target, "/")
+
+	var (
+		out                           strings.Builder
+		reproduced, differed, skipped int
+	)
+
+	for _, captured := range requests {
+		if captured.BodyOmitted {
+			skipped++
+
+			fmt.Fprintf(&out, "skipped   %s %s: its body was too large to be captured\n", captured.Method, captured.URI)
+
+			continue
+		}
+
+		status, err := send(ctx, client, target, &captured, overrides)
+		if err != nil {
+			return out.String(), fmt.Errorf("replaying %s %s: %w", captured.Method, captured.URI, err)
+		}
+
+		result := "differs"
+		if status == captured.Status {
+			result = "same"
+			reproduced++
+		} else {
+			differed++
+		}
+
+		fmt.Fprintf(&out, "%-9s %d -> %d %s %s", result, captured.Status, status, captured.Method, captured.URI)
+
+		if captured.TraceID != "" {
+			fmt.Fprintf(&out, " (trace %s)", captured.TraceID)
+		}
+
+		out.WriteString("\n")
+	}
+
+	fmt.Fprintf(&out, "\nReplayed %d requests to %s: %d with the captured status, %d with another status, %d skipped",
+		reproduced+differed, target, reproduced, differed, skipped)
+
+	return out.String(), nil
+}
+
+// readCaptured reads the captured requests of file.
+func readCaptured(file string) ([]capturedRequest, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	var requests []capturedRequest
+
+	dec := json.NewDecoder(f)
+
+	for {
+		var req capturedRequest
+
+		err := dec.Decode(&req)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("reading the captured request %d of %s: %w", len(requests)+1, file, err)
+		}
+
+		requests = append(requests, req)
+	}
+
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("%w in %s", errNoRequests, file)
+	}
+
+	return requests, nil
+}
+
+// send sends a captured request to target, and returns the status of its response.
+func send(ctx context.Context, client *http.Client, target string, captured *capturedRequest,
+	overrides http.Header) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, captured.Method, target+captured.URI, strings.NewReader(captured.Body))
+	if err != nil {
+		return 0, err
+	}
+
+	for name, values := range captured.Header {
+		for _, value := range values {
+			if value != redactedValue && name != "Content-Length" {
+				req.Header.Add(name, value)
+			}
+		}
+	}
+
+	for name, values := range overrides {
+		req.Header[name] = values
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return resp.StatusCode, nil
+}
+
+// parseHeaders parses the headers given as "Name: value".
+func parseHeaders(headers []string) (http.Header, error) {
+	parsed := make(http.Header, len(headers))
+
+	for _, header := range headers {
+		name, value, ok := strings.Cut(header, ":")
+		if name = strings.TrimSpace(name); !ok || name == "" {
+			return nil, fmt.Errorf("%w: %q", errInvalidHeader, header)
+		}
+
+		parsed.Add(name, strings.TrimSpace(value))
+	}
+
+	return parsed, nil
+}
@@ -0,0 +1,306 @@
+package upgrade
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	kiteModule  = "github.com/sllt/kite"
+	kitePackage = kiteModule + "/pkg/kite"
+)
+
+var (
+	ErrNoGoMod         = errors.New("go.mod does not exist, run the command from the root of the project")
+	ErrNotKiteProject  = errors.New("the project does not depend on " + kiteModule)
+	ErrLatestFailed    = errors.New("failed to find the latest kite version")
+	ErrGoGetFailed     = errors.New("failed to update kite in go.mod")
+	ErrCodemodFailed   = errors.New("failed to apply the codemods")
+	errVersionNotFound = errors.New("version not found")
+)
+
+// codemod renames the calls of a method of kite which was renamed or replaced in a breaking change, e.g. the AddFTP of
+// app.AddFTP(fs), from old to new.
+type codemod struct {
+	description string
+	old         string
+	new         string
+}
+
+//nolint:gochecknoglobals // the known codemods, applied in order on every upgrade.
+var codemods = []codemod{
+	{description: "AddFTP is replaced by AddFileStore", old: "AddFTP", new: "AddFileStore"},
+}
+
+// manualMigrations are the usages of an API which changed in a way the codemods cannot rewrite, e.g. because the
+// arguments changed, reported for a manual migration.
+//
+//nolint:gochecknoglobals // the known manual migrations, reported on every upgrade.
+var manualMigrations = []codemod{
+	{description: "UseMongo is replaced by AddMongo, which takes the unconnected client of mongo.New and connects it", old: "UseMongo"},
+}
+
+// ProjectVersion returns the version of kite required by the go.mod of the project in dir.
+func ProjectVersion(dir string) (string, error) {
+	f, err := os.Open(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return "", ErrNoGoMod
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(scanner.Text()), "require "))
+		if len(fields) >= 2 && fields[0] == kiteModule {
+			return fields[1], nil
+		}
+	}
+
+	return "", ErrNotKiteProject
+}
+
+// LatestVersion returns the latest released version of kite, as resolved by the go command.
+func LatestVersion(dir string) (string, error) {
+	cmd := exec.Command("go", "list", "-m", "-f", "{{.Version}}", kiteModule+"@latest")
+	cmd.Dir = dir
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrLatestFailed, string(output))
+	}
+
+	v := strings.TrimSpace(string(output))
+	if v == "" {
+		return "", fmt.Errorf("%w: %v", ErrLatestFailed, errVersionNotFound)
+	}
+
+	return v, nil
+}
+
+// Upgrade updates the kite version of the project in dir to target, the latest version when empty, and applies the
+// known codemods for the breaking changes to the Go files of the project.
+func Upgrade(dir, target string) (string, error) {
+	current, err := ProjectVersion(dir)
+	if err != nil {
+		return "", err
+	}
+
+	if target == "" {
+		fmt.Println("Checking the latest kite version...")
+
+		if target, err = LatestVersion(dir); err != nil {
+			return "", err
+		}
+	}
+
+	var result strings.Builder
+
+	if current == target {
+		fmt.Fprintf(&result, "kite is already at %s\n", current)
+	} else {
+		fmt.Printf("Upgrading kite from %s to %s...\n", current, target)
+
+		if err := goCommand(dir, "get", kiteModule+"@"+target); err != nil {
+			return "", fmt.Errorf("%w: %v", ErrGoGetFailed, err)
+		}
+
+		if err := goCommand(dir, "mod", "tidy"); err != nil {
+			fmt.Printf("Warning: go mod tidy failed: %v (you may need to run it manually)\n", err)
+		}
+
+		fmt.Fprintf(&result, "Upgraded kite from %s to %s\n", current, target)
+	}
+
+	changed, manual, err := ApplyCodemods(dir)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrCodemodFailed, err)
+	}
+
+	for _, file := range changed {
+		fmt.Fprintf(&result, "Rewrote %s\n", file)
+	}
+
+	for _, usage := range manual {
+		fmt.Fprintf(&result, "Warning: migrate %s manually\n", usage)
+	}
+
+	return strings.TrimSuffix(result.String(), "\n"), nil
+}
+
+// ApplyCodemods applies the known codemods to the Go files in dir which import kite, skipping the vendor directory
+// and hidden directories, and returns the rewritten files with the codemods applied to each of them, and the usages
+// which need a manual migration with their line. Only the method calls are rewritten, keeping the comments, the strings
+// and the formatting of the files, and the files which do not parse are left unchanged.
+func ApplyCodemods(dir string) (changed, manual []string, err error) {
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if path != dir && (d.Name() == "vendor" || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if filepath.Ext(path) != ".go" {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		fset := token.NewFileSet()
+
+		file, err := parser.ParseFile(fset, path, data, parser.SkipObjectResolution)
+		if err != nil || !importsKite(file) {
+			return nil
+		}
+
+		manual = append(manual, manualUsages(fset, file)...)
+
+		newData, applied := applyCodemods(fset, file, data)
+		if len(applied) == 0 {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		changed = append(changed, fmt.Sprintf("%s (%s)", path, strings.Join(applied, ", ")))
+
+		return os.WriteFile(path, newData, info.Mode().Perm())
+	})
+
+	return changed, manual, err
+}
+
+// applyCodemods renames the method calls of the codemods in the source of file, and returns it with the descriptions of
+// the codemods applied.
+func applyCodemods(fset *token.FileSet, file *ast.File, data []byte) (newData []byte, applied []string) {
+	type rename struct {
+		offset   int
+		old, new string
+	}
+
+	var renames []rename
+
+	for _, c := range codemods {
+		calls := methodCalls(file, c.old)
+		if len(calls) == 0 {
+			continue
+		}
+
+		for _, ident := range calls {
+			renames = append(renames, rename{offset: fset.Position(ident.Pos()).Offset, old: c.old, new: c.new})
+		}
+
+		applied = append(applied, c.description)
+	}
+
+	// the renames are applied from the end of the file, so that the offsets of the next ones are unchanged.
+	sort.Slice(renames, func(i, j int) bool { return renames[i].offset > renames[j].offset })
+
+	newData = data
+
+	for _, r := range renames {
+		rewritten := make([]byte, 0, len(newData)+len(r.new)-len(r.old))
+		rewritten = append(rewritten, newData[:r.offset]...)
+		rewritten = append(rewritten, r.new...)
+		newData = append(rewritten, newData[r.offset+len(r.old):]...)
+	}
+
+	return newData, applied
+}
+
+// manualUsages returns the method calls of the manual migrations in file, e.g. "main.go:12 (UseMongo is replaced by
+// AddMongo...)".
+func manualUsages(fset *token.FileSet, file *ast.File) []string {
+	var usages []string
+
+	for _, m := range manualMigrations {
+		for _, ident := range methodCalls(file, m.old) {
+			position := fset.Position(ident.Pos())
+			usages = append(usages, fmt.Sprintf("%s:%d (%s)", position.Filename, position.Line, m.description))
+		}
+	}
+
+	return usages
+}
+
+// methodCalls returns the names of the calls of the method in file, e.g. the AddFTP of app.AddFTP(fs), in the order of
+// the file.
+func methodCalls(file *ast.File, method string) []*ast.Ident {
+	var idents []*ast.Ident
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+
+		if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == method {
+			idents = append(idents, sel.Sel)
+		}
+
+		return true
+	})
+
+	return idents
+}
+
+// importsKite reports whether file imports the kite package, whose methods the codemods rewrite.
+func importsKite(file *ast.File) bool {
+	for _, spec := range file.Imports {
+		if path, err := strconv.Unquote(spec.Path.Value); err == nil && path == kitePackage {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckVersion compares the kite version of the project in dir with the version of kite the CLI was built with,
+// the returned warning is empty when they match.
+func CheckVersion(dir, cliKiteVersion string) (string, error) {
+	project, err := ProjectVersion(dir)
+	if err != nil {
+		return "", err
+	}
+
+	if project == cliKiteVersion {
+		return "", nil
+	}
+
+	return fmt.Sprintf("Warning: the project uses kite %s but the CLI was built with kite %s, "+
+		"the generated code may not compile, run 'kite upgrade' or install the matching CLI", project, cliKiteVersion), nil
+}
+
+func goCommand(dir string, args ...string) error {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, string(output))
+	}
+
+	return nil
+}
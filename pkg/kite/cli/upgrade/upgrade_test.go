@@ -0,0 +1,152 @@
+package upgrade
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mainSource = `package main
+
+import "github.com/sllt/kite/pkg/kite"
+
+// AddFTP is kept in the comments, ".AddFTP(" in the strings.
+func main() {
+	app := kite.New()
+
+	app.AddFTP(fs)
+	app.UseMongo(db)
+	app.Logger().Info(".AddFTP(")
+
+	app.AddFTP(other)
+}
+`
+
+const rewrittenSource = `package main
+
+import "github.com/sllt/kite/pkg/kite"
+
+// AddFTP is kept in the comments, ".AddFTP(" in the strings.
+func main() {
+	app := kite.New()
+
+	app.AddFileStore(fs)
+	app.UseMongo(db)
+	app.Logger().Info(".AddFTP(")
+
+	app.AddFileStore(other)
+}
+`
+
+func writeFile(t *testing.T, path, content string, perm os.FileMode) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), perm))
+}
+
+func TestApplyCodemods(t *testing.T) {
+	dir := t.TempDir()
+
+	mainPath := filepath.Join(dir, "main.go")
+	writeFile(t, mainPath, mainSource, 0o600)
+
+	// the files which do not import kite, do not parse, or are vendored or hidden are left unchanged.
+	notKite := "package ftp\n\nfunc f(c client) { c.AddFTP(1) }\n"
+	invalid := "package main\n\nfunc main() { app.AddFTP(\n"
+
+	writeFile(t, filepath.Join(dir, "ftp", "ftp.go"), notKite, 0o644)
+	writeFile(t, filepath.Join(dir, "broken.go"), invalid, 0o644)
+	writeFile(t, filepath.Join(dir, "vendor", "lib", "lib.go"), mainSource, 0o644)
+	writeFile(t, filepath.Join(dir, ".cache", "lib.go"), mainSource, 0o644)
+
+	changed, manual, err := ApplyCodemods(dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{mainPath + " (AddFTP is replaced by AddFileStore)"}, changed)
+	require.Len(t, manual, 1)
+	assert.Contains(t, manual[0], mainPath+":10 (UseMongo is replaced by AddMongo")
+
+	data, err := os.ReadFile(mainPath)
+	require.NoError(t, err)
+	assert.Equal(t, rewrittenSource, string(data))
+
+	info, err := os.Stat(mainPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "the file mode is kept")
+
+	unchanged := map[string]string{
+		filepath.Join(dir, "ftp", "ftp.go"):           notKite,
+		filepath.Join(dir, "broken.go"):               invalid,
+		filepath.Join(dir, "vendor", "lib", "lib.go"): mainSource,
+		filepath.Join(dir, ".cache", "lib.go"):        mainSource,
+	}
+
+	for path, content := range unchanged {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, content, string(data), path)
+	}
+}
+
+func TestManualUsages(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		source string
+		usages []string
+	}{
+		{desc: "method call", source: "package main\n\nfunc main() {\n\tapp.UseMongo(db)\n}\n",
+			usages: []string{"main.go:4 (" + manualMigrations[0].description + ")"}},
+		{desc: "chained calls", source: "package main\n\nfunc main() { a.UseMongo(x); b.c.UseMongo(y) }\n",
+			usages: []string{"main.go:3 (" + manualMigrations[0].description + ")",
+				"main.go:3 (" + manualMigrations[0].description + ")"}},
+		{desc: "comment and string", source: "package main\n\n// app.UseMongo(db)\nvar s = \"app.UseMongo(db)\"\n"},
+		{desc: "function of the same name", source: "package main\n\nfunc main() { UseMongo(db) }\n"},
+	}
+
+	for i, tc := range testCases {
+		fset := token.NewFileSet()
+
+		file, err := parser.ParseFile(fset, "main.go", tc.source, parser.SkipObjectResolution)
+		require.NoError(t, err, "TEST[%d], Failed.\n%s", i, tc.desc)
+
+		assert.Equal(t, tc.usages, manualUsages(fset, file), "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestProjectVersion(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		goMod   string
+		version string
+		err     error
+	}{
+		{desc: "require block", version: "v0.3.0",
+			goMod: "module app\n\ngo 1.24\n\nrequire (\n\tgithub.com/google/uuid v1.6.0\n\tgithub.com/sllt/kite v0.3.0\n)\n"},
+		{desc: "single line", version: "v0.2.2",
+			goMod: "module app\n\ngo 1.24\n\nrequire github.com/sllt/kite v0.2.2\n"},
+		{desc: "indirect", version: "v0.1.0",
+			goMod: "module app\n\nrequire (\n\tgithub.com/sllt/kite v0.1.0 // indirect\n)\n"},
+		{desc: "missing module", err: ErrNotKiteProject,
+			goMod: "module app\n\nrequire github.com/sllt/kite-plugins v1.0.0\n"},
+		{desc: "missing go.mod", err: ErrNoGoMod},
+	}
+
+	for i, tc := range testCases {
+		dir := t.TempDir()
+
+		if tc.goMod != "" {
+			writeFile(t, filepath.Join(dir, "go.mod"), tc.goMod, 0o644)
+		}
+
+		version, err := ProjectVersion(dir)
+
+		require.ErrorIs(t, err, tc.err, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, tc.version, version, "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
@@ -0,0 +1,514 @@
+package wrap
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/scanner"
+
+	"github.com/emicklei/proto"
+)
+
+const gitRefPrefix = "git:"
+
+var (
+	ErrProtoCheckFailed   = errors.New("the proto check failed")
+	ErrReadingPreviousRef = errors.New("error reading the previous version of the proto file")
+
+	pascalCase     = regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`)
+	lowerSnakeCase = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+	upperSnakeCase = regexp.MustCompile(`^[A-Z][A-Z0-9]*(_[A-Z0-9]+)*$`)
+)
+
+// CheckProto lints the style of a proto file and, when against is set, detects the changes breaking the clients and
+// the servers generated from its previous version: removed or renumbered fields, changed types, removed methods...
+// The previous version is read from a git revision, e.g. "git:main", or from a file. It returns the issues found,
+// wrapped in ErrProtoCheckFailed, so that they are fixed before the code is generated.
+func CheckProto(protoPath, against string) (string, error) {
+	if protoPath == "" {
+		return "", ErrNoProtoFile
+	}
+
+	definition, err := parseProtoFile(protoPath)
+	if err != nil {
+		return "", err
+	}
+
+	current := newProtoSchema(definition)
+	issues := current.lint(definition)
+
+	if against != "" {
+		previous, err := readPreviousProto(protoPath, against)
+		if err != nil {
+			return "", err
+		}
+
+		if previous != nil {
+			issues = append(issues, newProtoSchema(previous).breakingChanges(current)...)
+		}
+	}
+
+	if len(issues) > 0 {
+		return "", fmt.Errorf("%w, %d issues in %s:\n  %s", ErrProtoCheckFailed, len(issues), protoPath,
+			strings.Join(issues, "\n  "))
+	}
+
+	if against == "" {
+		return fmt.Sprintf("Checked: %s, no lint issue", protoPath), nil
+	}
+
+	return fmt.Sprintf("Checked: %s, no lint issue and no breaking change against %s", protoPath, against), nil
+}
+
+// readPreviousProto parses the previous version of the proto file, from a git revision, e.g. "git:main", or from a
+// file. It returns nil when the file does not exist in the revision, as a new file breaks nothing.
+func readPreviousProto(protoPath, against string) (*proto.Proto, error) {
+	ref, fromGit := strings.CutPrefix(against, gitRefPrefix)
+	if !fromGit {
+		return parseProtoFile(against)
+	}
+
+	// the "./" path is relative to the directory of the file, instead of the root of the repository.
+	cmd := exec.Command("git", "show", ref+":./"+filepath.Base(protoPath))
+	cmd.Dir = filepath.Dir(protoPath)
+
+	var stderr strings.Builder
+
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if strings.Contains(message, "does not exist in") || strings.Contains(message, "exists on disk, but not in") {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("%w: git show %s: %s", ErrReadingPreviousRef, ref, message)
+	}
+
+	return parseProto(strings.NewReader(string(output)), against)
+}
+
+// parseProto parses a proto definition read from r, named name in the positions of its elements.
+func parseProto(r io.Reader, name string) (*proto.Proto, error) {
+	parser := proto.NewParser(r)
+	parser.Filename(name)
+
+	definition, err := parser.Parse()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFailedToParseProto, err)
+	}
+
+	return definition, nil
+}
+
+// protoSchema holds the elements of a proto file which make the contract of the generated code, keyed by their
+// qualified name, e.g. "Order.Item".
+type protoSchema struct {
+	messages map[string]*protoMessage
+	enums    map[string]*protoEnum
+	services map[string]map[string]*proto.RPC
+}
+
+type protoMessage struct {
+	position scanner.Position
+	// fields are keyed by number.
+	fields   map[int]protoField
+	reserved *protoReserved
+}
+
+type protoField struct {
+	name     string
+	typ      string
+	repeated bool
+	position scanner.Position
+}
+
+type protoEnum struct {
+	position scanner.Position
+	// values are keyed by name.
+	values   map[string]*proto.EnumField
+	reserved *protoReserved
+}
+
+// protoReserved holds the numbers reserved by a message or an enum, which cannot be reused.
+type protoReserved struct {
+	ranges []proto.Range
+}
+
+func (r *protoReserved) add(reserved *proto.Reserved) {
+	r.ranges = append(r.ranges, reserved.Ranges...)
+}
+
+// has reports whether number is reserved, which keeps it from being reused: reserving the name too is advised, not
+// required.
+func (r *protoReserved) has(number int) bool {
+	for _, rng := range r.ranges {
+		if number >= rng.From && (rng.Max || number <= rng.To) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func newProtoSchema(definition *proto.Proto) *protoSchema {
+	s := &protoSchema{
+		messages: make(map[string]*protoMessage),
+		enums:    make(map[string]*protoEnum),
+		services: make(map[string]map[string]*proto.RPC),
+	}
+
+	s.addElements("", definition.Elements)
+
+	return s
+}
+
+func (s *protoSchema) addElements(prefix string, elements []proto.Visitee) {
+	for _, element := range elements {
+		switch e := element.(type) {
+		case *proto.Message:
+			if !e.IsExtend {
+				s.addMessage(prefix+e.Name, e)
+			}
+		case *proto.Enum:
+			s.addEnum(prefix+e.Name, e)
+		case *proto.Service:
+			rpcs := make(map[string]*proto.RPC)
+
+			for _, element := range e.Elements {
+				if rpc, ok := element.(*proto.RPC); ok {
+					rpcs[rpc.Name] = rpc
+				}
+			}
+
+			s.services[e.Name] = rpcs
+		}
+	}
+}
+
+func (s *protoSchema) addMessage(name string, message *proto.Message) {
+	m := &protoMessage{position: message.Position, fields: make(map[int]protoField), reserved: &protoReserved{}}
+	s.messages[name] = m
+
+	addField := func(field *proto.Field, typ string, repeated bool) {
+		m.fields[field.Sequence] = protoField{name: field.Name, typ: typ, repeated: repeated, position: field.Position}
+	}
+
+	for _, element := range message.Elements {
+		switch e := element.(type) {
+		case *proto.NormalField:
+			addField(e.Field, e.Type, e.Repeated)
+		case *proto.MapField:
+			addField(e.Field, "map<"+e.KeyType+", "+e.Type+">", false)
+		case *proto.Oneof:
+			for _, element := range e.Elements {
+				if field, ok := element.(*proto.OneOfField); ok {
+					addField(field.Field, field.Type, false)
+				}
+			}
+		case *proto.Reserved:
+			m.reserved.add(e)
+		}
+	}
+
+	s.addElements(name+".", message.Elements)
+}
+
+func (s *protoSchema) addEnum(name string, enum *proto.Enum) {
+	e := &protoEnum{position: enum.Position, values: make(map[string]*proto.EnumField), reserved: &protoReserved{}}
+	s.enums[name] = e
+
+	for _, element := range enum.Elements {
+		switch v := element.(type) {
+		case *proto.EnumField:
+			e.values[v.Name] = v
+		case *proto.Reserved:
+			e.reserved.add(v)
+		}
+	}
+}
+
+// breakingChanges returns the changes from s to current which break the clients or the servers generated from s.
+func (s *protoSchema) breakingChanges(current *protoSchema) []string {
+	var issues []string
+
+	for _, name := range sortedKeys(s.messages) {
+		message, ok := current.messages[name]
+		if !ok {
+			issues = append(issues, fmt.Sprintf("message %s was removed, keep it until no client uses it", name))
+
+			continue
+		}
+
+		issues = append(issues, s.messages[name].breakingChanges(name, message)...)
+	}
+
+	for _, name := range sortedKeys(s.enums) {
+		enum, ok := current.enums[name]
+		if !ok {
+			issues = append(issues, fmt.Sprintf("enum %s was removed, keep it until no client uses it", name))
+
+			continue
+		}
+
+		issues = append(issues, s.enums[name].breakingChanges(name, enum)...)
+	}
+
+	for _, name := range sortedKeys(s.services) {
+		rpcs, ok := current.services[name]
+		if !ok {
+			issues = append(issues, fmt.Sprintf("service %s was removed, the generated clients can no longer call it",
+				name))
+
+			continue
+		}
+
+		for _, method := range sortedKeys(s.services[name]) {
+			issues = append(issues, rpcBreakingChanges(name, s.services[name][method], rpcs[method])...)
+		}
+	}
+
+	return issues
+}
+
+func (m *protoMessage) breakingChanges(name string, current *protoMessage) []string {
+	var issues []string
+
+	for _, number := range sortedKeys(m.fields) {
+		field := m.fields[number]
+
+		changed, ok := current.fields[number]
+		if !ok {
+			if issue := removedFieldIssue(name, number, field, current); issue != "" {
+				issues = append(issues, issue)
+			}
+
+			continue
+		}
+
+		at := positionOf(changed.position)
+
+		if changed.name != field.name {
+			issues = append(issues, fmt.Sprintf("%sfield %s.%s (%d) was renamed to %s, which breaks the JSON "+
+				"encoding and the generated code, keep the name %s", at, name, field.name, number, changed.name,
+				field.name))
+		}
+
+		if changed.typ != field.typ || changed.repeated != field.repeated {
+			issues = append(issues, fmt.Sprintf("%sfield %s.%s (%d) changed type from %s to %s, add a field with a "+
+				"new number instead", at, name, changed.name, number, fieldType(field), fieldType(changed)))
+		}
+	}
+
+	return issues
+}
+
+// removedFieldIssue describes a field which is no longer found by its number, as renumbered when a field keeps its
+// name, or as removed unless its number and name are reserved.
+func removedFieldIssue(message string, number int, field protoField, current *protoMessage) string {
+	for _, newNumber := range sortedKeys(current.fields) {
+		if changed := current.fields[newNumber]; changed.name == field.name {
+			return fmt.Sprintf("%sfield %s.%s was renumbered from %d to %d, which breaks the wire format, restore "+
+				"the number %d", positionOf(changed.position), message, field.name, number, newNumber, number)
+		}
+	}
+
+	if current.reserved.has(number) {
+		return ""
+	}
+
+	return fmt.Sprintf("%sfield %s.%s (%d) was removed, add `reserved %d;` and `reserved \"%s\";` to %s so that "+
+		"they are not reused", positionOf(current.position), message, field.name, number, number, field.name,
+		message)
+}
+
+func (e *protoEnum) breakingChanges(name string, current *protoEnum) []string {
+	var issues []string
+
+	for _, valueName := range sortedKeys(e.values) {
+		value := e.values[valueName]
+
+		changed, ok := current.values[valueName]
+
+		switch {
+		case ok && changed.Integer != value.Integer:
+			issues = append(issues, fmt.Sprintf("%senum value %s.%s was renumbered from %d to %d, restore the "+
+				"number %d", positionOf(changed.Position), name, valueName, value.Integer, changed.Integer,
+				value.Integer))
+		case !ok && !current.reserved.has(value.Integer):
+			issues = append(issues, fmt.Sprintf("%senum value %s.%s (%d) was removed, add `reserved %d;` and "+
+				"`reserved \"%s\";` to %s so that they are not reused", positionOf(current.position), name,
+				valueName, value.Integer, value.Integer, valueName, name))
+		}
+	}
+
+	return issues
+}
+
+func rpcBreakingChanges(service string, rpc, current *proto.RPC) []string {
+	if current == nil {
+		return []string{fmt.Sprintf("method %s.%s was removed, the generated clients can no longer call it",
+			service, rpc.Name)}
+	}
+
+	var issues []string
+
+	at := positionOf(current.Position)
+
+	if current.RequestType != rpc.RequestType || current.StreamsRequest != rpc.StreamsRequest {
+		issues = append(issues, fmt.Sprintf("%smethod %s.%s changed request from %s to %s, add a new method instead",
+			at, service, rpc.Name, rpcType(rpc.RequestType, rpc.StreamsRequest),
+			rpcType(current.RequestType, current.StreamsRequest)))
+	}
+
+	if current.ReturnsType != rpc.ReturnsType || current.StreamsReturns != rpc.StreamsReturns {
+		issues = append(issues, fmt.Sprintf("%smethod %s.%s changed response from %s to %s, add a new method instead",
+			at, service, rpc.Name, rpcType(rpc.ReturnsType, rpc.StreamsReturns),
+			rpcType(current.ReturnsType, current.StreamsReturns)))
+	}
+
+	return issues
+}
+
+// lint returns the style issues of the proto file: the naming conventions of the elements, the zero value of the
+// enums, and the package and go_package options the generated code relies on.
+func (s *protoSchema) lint(definition *proto.Proto) []string {
+	var (
+		issues    []string
+		pkg       bool
+		goPackage bool
+	)
+
+	proto.Walk(definition,
+		proto.WithPackage(func(*proto.Package) { pkg = true }),
+		proto.WithOption(func(opt *proto.Option) {
+			if opt.Name == "go_package" {
+				goPackage = true
+			}
+		}),
+	)
+
+	if !pkg {
+		issues = append(issues, "the file has no package, add e.g. `package orders.v1;`")
+	}
+
+	if !goPackage {
+		issues = append(issues, "the file has no go_package option, add e.g. "+
+			"`option go_package = \"github.com/acme/orders/api/v1\";`")
+	}
+
+	for _, name := range sortedKeys(s.messages) {
+		message := s.messages[name]
+
+		issues = appendNamingIssue(issues, message.position, "message", name, pascalCase, "PascalCase")
+
+		for _, number := range sortedKeys(message.fields) {
+			field := message.fields[number]
+
+			issues = appendNamingIssue(issues, field.position, "field", name+"."+field.name, lowerSnakeCase,
+				"lower_snake_case")
+		}
+	}
+
+	for _, name := range sortedKeys(s.enums) {
+		enum := s.enums[name]
+
+		issues = appendNamingIssue(issues, enum.position, "enum", name, pascalCase, "PascalCase")
+
+		zero := false
+
+		for _, valueName := range sortedKeys(enum.values) {
+			value := enum.values[valueName]
+			zero = zero || value.Integer == 0
+
+			issues = appendNamingIssue(issues, value.Position, "enum value", name+"."+valueName, upperSnakeCase,
+				"UPPER_SNAKE_CASE")
+		}
+
+		if !zero {
+			issues = append(issues, fmt.Sprintf("%senum %s has no zero value, add e.g. `%s_UNSPECIFIED = 0;`",
+				positionOf(enum.position), name, toUpperSnakeCase(lastSegment(name))))
+		}
+	}
+
+	for _, name := range sortedKeys(s.services) {
+		for _, method := range sortedKeys(s.services[name]) {
+			rpc := s.services[name][method]
+
+			issues = appendNamingIssue(issues, rpc.Position, "method", name+"."+method, pascalCase, "PascalCase")
+		}
+	}
+
+	return issues
+}
+
+// appendNamingIssue appends an issue when the last segment of the qualified name does not match the convention.
+func appendNamingIssue(issues []string, position scanner.Position, kind, name string, convention *regexp.Regexp,
+	conventionName string) []string {
+	if convention.MatchString(lastSegment(name)) {
+		return issues
+	}
+
+	return append(issues, fmt.Sprintf("%s%s %s should be %s", positionOf(position), kind, name, conventionName))
+}
+
+// positionOf formats the position of an element as a prefix of the issues, e.g. "api/orders.proto:12: ".
+func positionOf(position scanner.Position) string {
+	if position.Line == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%s:%d: ", position.Filename, position.Line)
+}
+
+func fieldType(field protoField) string {
+	if field.repeated {
+		return "repeated " + field.typ
+	}
+
+	return field.typ
+}
+
+func rpcType(typ string, streams bool) string {
+	if streams {
+		return "stream " + typ
+	}
+
+	return typ
+}
+
+func lastSegment(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// toUpperSnakeCase converts a PascalCase name, e.g. OrderStatus, to ORDER_STATUS.
+func toUpperSnakeCase(name string) string {
+	var b strings.Builder
+
+	for i, r := range name {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			b.WriteByte('_')
+		}
+
+		b.WriteRune(r)
+	}
+
+	return strings.ToUpper(b.String())
+}
+
+// checkBeforeGenerating runs CheckProto against opts.Against, if set, so that no code is generated from a proto file
+// breaking its previous version.
+func checkBeforeGenerating(protoPath string, opts GenerateOptions) error {
+	if opts.Against == "" {
+		return nil
+	}
+
+	_, err := CheckProto(protoPath, opts.Against)
+
+	return err
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"go/format"
 	"os"
 	"path"
 	"strings"
@@ -28,6 +29,7 @@ var (
 	ErrFailedToParseProto = errors.New("failed to parse proto file")
 	ErrGeneratingWrapper  = errors.New("error while generating the code using proto file")
 	ErrWritingFile        = errors.New("error writing the generated code to the file")
+	ErrReadingFile        = errors.New("error reading the existing file")
 )
 
 // ServiceMethod represents a method in a proto service.
@@ -54,23 +56,38 @@ type WrapperData struct {
 	Source   string
 }
 
+// GenerateOptions controls how generated files are written.
+type GenerateOptions struct {
+	// Force overwrites existing server files even if hand-written code outside the
+	// protected regions, or in regions that are no longer generated, would be lost.
+	Force bool
+	// Diff previews the changes as unified diffs instead of writing the files.
+	Diff bool
+	// Against is the previous version of the proto file, e.g. "git:main", which the proto file is checked against
+	// before generating the files, see CheckProto.
+	Against string
+}
+
 type FileType struct {
 	FileSuffix    string
 	CodeGenerator func(*WrapperData) string
 }
 
 // BuildGRPCKiteClient generates gRPC client wrapper code based on a proto definition.
-func BuildGRPCKiteClient(protoPath, outDir string) (string, error) {
+func BuildGRPCKiteClient(protoPath, outDir string, opts GenerateOptions) (string, error) {
 	gRPCClient := []FileType{
 		{FileSuffix: clientFileSuffix, CodeGenerator: generateKiteClient},
 		{FileSuffix: clientHealthFile, CodeGenerator: generateKiteClientHealth},
 	}
 
-	return generateWrapper(protoPath, outDir, gRPCClient...)
+	return generateWrapper(protoPath, outDir, opts, gRPCClient...)
 }
 
 // BuildGRPCKiteServer generates gRPC server code based on a proto definition.
-func BuildGRPCKiteServer(protoPath, outDir string) (string, error) {
+//
+// The generated wrappers are always overwritten. The server skeleton is merged with the existing file instead,
+// so that the code written inside its "kite:begin"/"kite:end" regions survives the regeneration.
+func BuildGRPCKiteServer(protoPath, outDir string, opts GenerateOptions) (string, error) {
 	gRPCServer := []FileType{
 		{FileSuffix: serverWrapperFileSuffix, CodeGenerator: generateKiteServerWrapper},
 		{FileSuffix: serverHealthFile, CodeGenerator: generateKiteServerHealthWrapper},
@@ -78,17 +95,21 @@ func BuildGRPCKiteServer(protoPath, outDir string) (string, error) {
 		{FileSuffix: serverFileSuffix, CodeGenerator: generateKiteServer},
 	}
 
-	return generateWrapper(protoPath, outDir, gRPCServer...)
+	return generateWrapper(protoPath, outDir, opts, gRPCServer...)
 }
 
 // generateWrapper executes the function for specified FileType to create Kite integrated
 // gRPC server/client files with the required services in proto file and
 // specified suffix for every service specified in the proto file.
-func generateWrapper(protoPath, outDir string, options ...FileType) (string, error) {
+func generateWrapper(protoPath, outDir string, opts GenerateOptions, options ...FileType) (string, error) {
 	if protoPath == "" {
 		return "", ErrNoProtoFile
 	}
 
+	if err := checkBeforeGenerating(protoPath, opts); err != nil {
+		return "", err
+	}
+
 	definition, err := parseProtoFile(protoPath)
 	if err != nil {
 		return "", err
@@ -113,7 +134,7 @@ func generateWrapper(protoPath, outDir string, options ...FileType) (string, err
 			Source:   path.Base(protoPath),
 		}
 
-		msgs, err := generateFiles(projectPath, service.Name, &wrapperData, requests, options...)
+		msgs, err := generateFiles(projectPath, service.Name, &wrapperData, requests, opts, options...)
 		if err != nil {
 			return "", err
 		}
@@ -132,19 +153,12 @@ func parseProtoFile(protoPath string) (*proto.Proto, error) {
 	}
 	defer file.Close()
 
-	parser := proto.NewParser(file)
-
-	definition, err := parser.Parse()
-	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrFailedToParseProto, err)
-	}
-
-	return definition, nil
+	return parseProto(file, protoPath)
 }
 
 // generateFiles generates files for a given service.
 func generateFiles(projectPath, serviceName string, wrapperData *WrapperData,
-	requests []string, options ...FileType) ([]string, error) {
+	requests []string, opts GenerateOptions, options ...FileType) ([]string, error) {
 	var messages []string
 
 	for _, option := range options {
@@ -159,22 +173,74 @@ func generateFiles(projectPath, serviceName string, wrapperData *WrapperData,
 
 		outputFilePath := getOutputFilePath(projectPath, serviceName, option.FileSuffix)
 
-		// Skip server skeleton file if it already exists
-		if option.FileSuffix == serverFileSuffix {
-			if _, err := os.Stat(outputFilePath); err == nil {
-				messages = append(messages, fmt.Sprintf("Skipped: %s (already exists)", outputFilePath))
-				continue
-			}
+		message, err := writeGeneratedFile(outputFilePath, generatedCode, option.FileSuffix == serverFileSuffix, opts)
+		if err != nil {
+			return nil, err
 		}
 
-		if err := os.WriteFile(outputFilePath, []byte(generatedCode), filePerm); err != nil {
-			return nil, fmt.Errorf("%w: %v", ErrWritingFile, err)
+		messages = append(messages, message)
+	}
+
+	return messages, nil
+}
+
+// writeGeneratedFile writes the generated code to a file, or previews it with opts.Diff, and describes the change.
+// When preserveRegions is set, the hand-written code of the regions of an existing file is merged into the code.
+func writeGeneratedFile(outputFilePath, generatedCode string, preserveRegions bool, opts GenerateOptions) (string, error) {
+	existing, err := os.ReadFile(outputFilePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%w: %v", ErrReadingFile, err)
+	}
+
+	// Preserve the hand-written code of an existing server skeleton
+	if preserveRegions && existing != nil {
+		var skipReason string
+
+		generatedCode, skipReason = mergeServerFile(string(existing), generatedCode, opts.Force)
+		if skipReason != "" {
+			return fmt.Sprintf("Skipped: %s (%s)", outputFilePath, skipReason), nil
 		}
+	}
 
-		messages = append(messages, fmt.Sprintf("Generated: %s", outputFilePath))
+	if opts.Diff {
+		return previewFile(outputFilePath, string(existing), generatedCode), nil
 	}
 
-	return messages, nil
+	if err := os.WriteFile(outputFilePath, []byte(generatedCode), filePerm); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrWritingFile, err)
+	}
+
+	return fmt.Sprintf("Generated: %s", outputFilePath), nil
+}
+
+// mergeServerFile merges the protected regions of an existing server skeleton into the newly generated one.
+// Unless force is set, it returns a reason to skip the file when hand-written code would be lost.
+func mergeServerFile(existing, generated string, force bool) (merged, skipReason string) {
+	regions := parseRegions(existing)
+	if len(regions) == 0 {
+		if force {
+			return generated, ""
+		}
+
+		return "", "already exists without kite:begin/kite:end regions, use --force to overwrite"
+	}
+
+	merged, orphaned := mergeRegions(generated, regions)
+	if len(orphaned) > 0 && !force {
+		return "", fmt.Sprintf("regions %s are no longer generated, move their code and use --force to overwrite",
+			strings.Join(orphaned, ", "))
+	}
+
+	return merged, ""
+}
+
+// previewFile describes the changes that generating a file would make.
+func previewFile(filePath, existing, generated string) string {
+	if existing == generated {
+		return fmt.Sprintf("Unchanged: %s", filePath)
+	}
+
+	return fmt.Sprintf("Would update: %s\n%s", filePath, unifiedDiff(filePath, existing, generated))
 }
 
 // getOutputFilePath generates the output file path based on the file suffix.
@@ -247,6 +313,23 @@ func executeTemplate(data *WrapperData, tmpl string) string {
 	return buf.String()
 }
 
+// executeRESTTemplate executes a template of the REST server generated from an OpenAPI spec, formatting the code
+// whose fields are aligned by gofmt.
+func executeRESTTemplate(data *RESTData, tmpl string) string {
+	var buf bytes.Buffer
+
+	if err := template.Must(template.New("template").Parse(tmpl)).Execute(&buf, data); err != nil {
+		return ""
+	}
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return buf.String()
+	}
+
+	return string(formatted)
+}
+
 // Template generators.
 func generateKiteServerWrapper(data *WrapperData) string {
 	return executeTemplate(data, wrapperTemplate)
@@ -0,0 +1,883 @@
+package wrap
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	typesFileSuffix  = "_types.go"
+	routesFileSuffix = "_kite.go"
+	paramsFile       = "params_kite.go"
+	componentsPrefix = "#/components/"
+)
+
+var (
+	ErrNoSpecFile        = errors.New("OpenAPI spec file path is required")
+	ErrFailedToParseSpec = errors.New("failed to parse OpenAPI spec file")
+)
+
+// openAPISpec is the subset of an OpenAPI 3 document used to generate a server.
+type openAPISpec struct {
+	Info struct {
+		Title string `json:"title"`
+	} `json:"info"`
+	Paths      map[string]openAPIPathItem `json:"paths"`
+	Components struct {
+		Schemas       map[string]*openAPISchema      `json:"schemas"`
+		Parameters    map[string]*openAPIParameter   `json:"parameters"`
+		RequestBodies map[string]*openAPIRequestBody `json:"requestBodies"`
+		Responses     map[string]*openAPIResponse    `json:"responses"`
+	} `json:"components"`
+}
+
+type openAPIPathItem struct {
+	Parameters []*openAPIParameter `json:"parameters"`
+	Get        *openAPIOperation   `json:"get"`
+	Post       *openAPIOperation   `json:"post"`
+	Put        *openAPIOperation   `json:"put"`
+	Patch      *openAPIOperation   `json:"patch"`
+	Delete     *openAPIOperation   `json:"delete"`
+	Head       *openAPIOperation   `json:"head"`
+	Options    *openAPIOperation   `json:"options"`
+}
+
+type openAPIOperation struct {
+	OperationID string                      `json:"operationId"`
+	Summary     string                      `json:"summary"`
+	Parameters  []*openAPIParameter         `json:"parameters"`
+	RequestBody *openAPIRequestBody         `json:"requestBody"`
+	Responses   map[string]*openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Ref      string         `json:"$ref"`
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required"`
+	Schema   *openAPISchema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Ref     string                      `json:"$ref"`
+	Content map[string]openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Ref     string                      `json:"$ref"`
+	Content map[string]openAPIMediaType `json:"content"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema"`
+}
+
+type openAPISchema struct {
+	Ref                  string                    `json:"$ref"`
+	Type                 openAPIType               `json:"type"`
+	Format               string                    `json:"format"`
+	Description          string                    `json:"description"`
+	Nullable             bool                      `json:"nullable"`
+	Properties           map[string]*openAPISchema `json:"properties"`
+	Required             []string                  `json:"required"`
+	AdditionalProperties json.RawMessage           `json:"additionalProperties"`
+	Items                *openAPISchema            `json:"items"`
+	Enum                 []any                     `json:"enum"`
+	MinLength            *int                      `json:"minLength"`
+	MaxLength            *int                      `json:"maxLength"`
+	MinItems             *int                      `json:"minItems"`
+	MaxItems             *int                      `json:"maxItems"`
+	Minimum              *float64                  `json:"minimum"`
+	Maximum              *float64                  `json:"maximum"`
+}
+
+// openAPIType is the type of a schema, which OpenAPI 3.1 allows to be a list, e.g. ["string", "null"].
+type openAPIType []string
+
+func (t *openAPIType) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = openAPIType{single}
+
+		return nil
+	}
+
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+// name returns the type which is not "null".
+func (t openAPIType) name() string {
+	for _, name := range t {
+		if name != "null" {
+			return name
+		}
+	}
+
+	return ""
+}
+
+func (t openAPIType) nullable() bool {
+	return slices.Contains(t, "null")
+}
+
+// RESTData is the template data of a REST server generated from an OpenAPI spec.
+type RESTData struct {
+	Package    string
+	API        string
+	Title      string
+	Source     string
+	Types      []*RESTType
+	Operations []*RESTOperation
+	UsesTime   bool
+}
+
+// RESTType is a Go type generated for a schema. Structs have Fields, other types a Definition.
+type RESTType struct {
+	Name       string
+	Doc        string
+	Fields     []RESTField
+	Definition string
+}
+
+// RESTField is a field of a generated struct.
+type RESTField struct {
+	Name string
+	Type string
+	Tag  string
+	Doc  string
+}
+
+// RESTOperation is an operation of the spec, served by a method of the generated handler.
+type RESTOperation struct {
+	Name     string
+	Method   string
+	Path     string
+	Summary  string
+	Request  string
+	Params   []RESTParam
+	Body     string
+	Response string
+}
+
+// RESTParam is a path or query parameter of an operation.
+type RESTParam struct {
+	Field    string
+	Name     string
+	In       string
+	Type     string
+	Parse    string
+	Required bool
+	Multi    bool
+}
+
+// HasRequest reports whether the operation has parameters or a body, bound to a request struct.
+func (o *RESTOperation) HasRequest() bool {
+	return len(o.Params) > 0 || o.Body != ""
+}
+
+// Results returns the results of the handler method of the operation.
+func (o *RESTOperation) Results() string {
+	if o.Response == "" {
+		return "error"
+	}
+
+	return "(" + o.Response + ", error)"
+}
+
+// Zero returns the values returned by the generated stub of the operation.
+func (o *RESTOperation) Zero() string {
+	if o.Response == "" {
+		return "nil"
+	}
+
+	if name, ok := strings.CutPrefix(o.Response, "*"); ok {
+		return "&" + name + "{}, nil"
+	}
+
+	return "nil, nil"
+}
+
+// BuildRESTKiteServer generates a REST server from an OpenAPI spec: the types of its schemas and requests, a
+// handler interface with a method per operation, the registration of its routes and a server skeleton implementing
+// the handler.
+//
+// As with BuildGRPCKiteServer, the generated types and routes are always overwritten, while the code written inside
+// the "kite:begin"/"kite:end" regions of the server skeleton survives the regeneration.
+func BuildRESTKiteServer(specPath, outDir string, opts GenerateOptions) (string, error) {
+	if specPath == "" {
+		return "", ErrNoSpecFile
+	}
+
+	content, err := os.ReadFile(specPath)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrReadingFile, err)
+	}
+
+	var spec openAPISpec
+
+	if err := json.Unmarshal(content, &spec); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrFailedToParseSpec, err)
+	}
+
+	if outDir == "" {
+		outDir = path.Dir(specPath)
+	}
+
+	data, messages := newRESTData(&spec, goPackageName(path.Base(outDir)), path.Base(specPath))
+
+	if err := os.MkdirAll(outDir, os.ModePerm); err != nil {
+		return "", fmt.Errorf("error creating output directory: %w", err)
+	}
+
+	prefix := strings.ToLower(data.API)
+	files := []struct {
+		path     string
+		template string
+		merge    bool
+	}{
+		{path.Join(outDir, prefix+typesFileSuffix), restTypesTemplate, false},
+		{path.Join(outDir, prefix+routesFileSuffix), restRoutesTemplate, false},
+		{path.Join(outDir, paramsFile), restParamsTemplate, false},
+		{path.Join(outDir, prefix+serverFileSuffix), restServerTemplate, true},
+	}
+
+	for _, file := range files {
+		generatedCode := executeRESTTemplate(data, file.template)
+		if generatedCode == "" {
+			return "", fmt.Errorf("%w: %s", ErrGeneratingWrapper, file.path)
+		}
+
+		message, err := writeGeneratedFile(file.path, generatedCode, file.merge, opts)
+		if err != nil {
+			return "", err
+		}
+
+		messages = append(messages, message)
+	}
+
+	return strings.Join(messages, "\n"), nil
+}
+
+// newRESTData builds the template data of the spec. It also returns the messages of the operations and parameters
+// which are not generated.
+func newRESTData(spec *openAPISpec, packageName, source string) (*RESTData, []string) {
+	b := &restTypeBuilder{spec: spec, declared: make(map[string]bool), structs: make(map[string]bool)}
+	data := &RESTData{Package: packageName, API: goName(spec.Info.Title), Title: spec.Info.Title, Source: source}
+
+	if data.API == "" {
+		data.API = "API"
+	}
+
+	if data.Title == "" {
+		data.Title = "the API"
+	}
+
+	// the components are declared first, so that the inline types do not take their names.
+	for _, name := range sortedKeys(spec.Components.Schemas) {
+		b.declared[goName(name)] = true
+
+		if isObject(spec.Components.Schemas[name]) {
+			b.structs[goName(name)] = true
+		}
+	}
+
+	for _, name := range sortedKeys(spec.Components.Schemas) {
+		b.declare(goName(name), spec.Components.Schemas[name])
+	}
+
+	var messages []string
+
+	for _, p := range sortedKeys(spec.Paths) {
+		item := spec.Paths[p]
+
+		for _, method := range []struct {
+			name string
+			op   *openAPIOperation
+		}{
+			{http.MethodGet, item.Get}, {http.MethodPost, item.Post}, {http.MethodPut, item.Put},
+			{http.MethodPatch, item.Patch}, {http.MethodDelete, item.Delete},
+			{http.MethodHead, item.Head}, {http.MethodOptions, item.Options},
+		} {
+			if method.op == nil {
+				continue
+			}
+
+			if method.name == http.MethodHead || method.name == http.MethodOptions {
+				messages = append(messages, fmt.Sprintf("Skipped: %s %s (method not supported)", method.name, p))
+				continue
+			}
+
+			op, skipped := b.operation(method.name, p, item.Parameters, method.op)
+
+			data.Operations = append(data.Operations, op)
+			messages = append(messages, skipped...)
+		}
+	}
+
+	data.Types, data.UsesTime = b.types, b.usesTime
+
+	return data, messages
+}
+
+// restTypeBuilder converts the schemas of a spec to Go types, declaring the types of the inline objects.
+type restTypeBuilder struct {
+	spec     *openAPISpec
+	types    []*RESTType
+	declared map[string]bool
+	structs  map[string]bool
+	usesTime bool
+}
+
+func (b *restTypeBuilder) operation(method, p string, pathParams []*openAPIParameter,
+	op *openAPIOperation) (operation *RESTOperation, skipped []string) {
+	name := goName(op.OperationID)
+	if name == "" {
+		name = operationName(method, p)
+	}
+
+	operation = &RESTOperation{Name: name, Method: method, Path: p, Summary: op.Summary}
+
+	for _, param := range mergeParameters(b.resolveParameters(pathParams), b.resolveParameters(op.Parameters)) {
+		if param.In != "path" && param.In != "query" {
+			skipped = append(skipped, fmt.Sprintf("Skipped: %s parameter %q of %s %s (only path and query parameters "+
+				"are bound)", param.In, param.Name, method, p))
+
+			continue
+		}
+
+		operation.Params = append(operation.Params, b.param(param))
+	}
+
+	if body := b.resolveRequestBody(op.RequestBody); body != nil {
+		if schema := jsonSchema(body.Content); schema != nil {
+			operation.Body = b.goType(schema, name+"Body")
+		}
+	}
+
+	operation.Response = b.response(name, op.Responses)
+
+	if operation.HasRequest() {
+		operation.Request = b.declareRequest(operation)
+	}
+
+	return operation, skipped
+}
+
+func (b *restTypeBuilder) param(param *openAPIParameter) RESTParam {
+	p := RESTParam{Field: goName(param.Name), Name: param.Name, In: param.In, Required: param.Required || param.In == "path"}
+
+	schema := b.resolveSchema(param.Schema)
+	if schema != nil && schema.Type.name() == "array" && param.In == "query" {
+		p.Multi = true
+		schema = b.resolveSchema(schema.Items)
+	}
+
+	p.Type, p.Parse = paramType(schema)
+	if p.Type == "time.Time" {
+		b.usesTime = true
+	}
+
+	if p.Multi {
+		p.Type = "[]" + p.Type
+	}
+
+	return p
+}
+
+// response returns the Go type of the JSON body of the first successful response, it is empty when the operation
+// responds without content. The bodies which are not objects, arrays or maps are returned as any, so that the stubs
+// can return nil.
+func (b *restTypeBuilder) response(operation string, responses map[string]*openAPIResponse) string {
+	for _, code := range sortedKeys(responses) {
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+
+		res := b.resolveResponse(responses[code])
+		if res == nil {
+			continue
+		}
+
+		schema := jsonSchema(res.Content)
+		if schema == nil {
+			return ""
+		}
+
+		typ := b.goType(schema, operation+"Response")
+		resolved := b.resolveSchema(schema)
+
+		switch {
+		case b.structs[typ]:
+			return "*" + typ
+		case resolved.Type.name() == "array", len(resolved.Properties) == 0 && strings.HasPrefix(typ, "map["),
+			len(resolved.Properties) == 0 && resolved.AdditionalProperties != nil && typ != "any":
+			return typ
+		default:
+			return "any"
+		}
+	}
+
+	return ""
+}
+
+func (b *restTypeBuilder) declareRequest(op *RESTOperation) string {
+	t := &RESTType{Name: b.uniqueName(op.Name + "Request"), Doc: fmt.Sprintf("holds the parameters and the body of %s %s.",
+		op.Method, op.Path)}
+
+	for _, param := range op.Params {
+		t.Fields = append(t.Fields, RESTField{Name: param.Field, Type: param.Type,
+			Doc: fmt.Sprintf("%s parameter %q", param.In, param.Name)})
+	}
+
+	if op.Body != "" {
+		t.Fields = append(t.Fields, RESTField{Name: "Body", Type: op.Body})
+	}
+
+	b.types = append(b.types, t)
+
+	return t.Name
+}
+
+// declare declares the Go type name of a component or an inline object.
+func (b *restTypeBuilder) declare(name string, schema *openAPISchema) {
+	schema = b.resolveSchema(schema)
+
+	t := &RESTType{Name: name, Doc: schema.Description}
+	b.types = append(b.types, t)
+
+	if !isObject(schema) {
+		t.Definition = b.goType(schema, name+"Item")
+
+		return
+	}
+
+	for _, property := range sortedKeys(schema.Properties) {
+		t.Fields = append(t.Fields, b.field(name, property, schema.Properties[property],
+			slices.Contains(schema.Required, property)))
+	}
+}
+
+func (b *restTypeBuilder) field(structName, property string, schema *openAPISchema, required bool) RESTField {
+	field := RESTField{Name: goName(property)}
+	if field.Name == "" {
+		field.Name = "Field"
+	}
+
+	resolved := b.resolveSchema(schema)
+	nullable := resolved.Nullable || resolved.Type.nullable()
+	field.Type = b.goType(schema, structName+field.Name)
+
+	if b.structs[field.Type] && !required || isScalar(field.Type) && nullable {
+		field.Type = "*" + field.Type
+	}
+
+	jsonTag := property
+	if !required {
+		jsonTag += ",omitempty"
+	}
+
+	field.Tag = fmt.Sprintf("json:%q", jsonTag)
+
+	// the nullable fields are required to be present, and may be null.
+	if rules := b.bindingRules(resolved, field.Type, required && !nullable); rules != "" {
+		field.Tag += fmt.Sprintf(" binding:%q", rules)
+	}
+
+	if resolved.Description != "" {
+		field.Doc = resolved.Description
+	}
+
+	return field
+}
+
+// bindingRules returns the validation rules of a field, checked when the request is bound.
+func (b *restTypeBuilder) bindingRules(schema *openAPISchema, typ string, required bool) string {
+	var rules []string
+
+	switch schema.Type.name() {
+	case "string":
+		rules = appendLengthRules(rules, schema.MinLength, schema.MaxLength)
+		rules = appendEnumRule(rules, schema.Enum)
+
+		switch schema.Format {
+		case "email":
+			rules = append(rules, "email")
+		case "uuid":
+			rules = append(rules, "uuid")
+		case "uri", "url":
+			rules = append(rules, "url")
+		case "ipv4":
+			rules = append(rules, "ipv4")
+		case "ipv6":
+			rules = append(rules, "ipv6")
+		}
+	case "integer", "number":
+		if schema.Minimum != nil {
+			rules = append(rules, "gte="+strconv.FormatFloat(*schema.Minimum, 'f', -1, 64))
+		}
+
+		if schema.Maximum != nil {
+			rules = append(rules, "lte="+strconv.FormatFloat(*schema.Maximum, 'f', -1, 64))
+		}
+
+		rules = appendEnumRule(rules, schema.Enum)
+	case "array":
+		rules = appendLengthRules(rules, schema.MinItems, schema.MaxItems)
+
+		if b.structs[strings.TrimPrefix(typ, "[]")] {
+			rules = append(rules, "dive")
+		}
+	}
+
+	// the zero numbers and booleans are valid values, which "required" would reject.
+	switch {
+	case required && !isNumberOrBool(typ):
+		rules = append([]string{"required"}, rules...)
+	case !required && len(rules) > 0:
+		rules = append([]string{"omitempty"}, rules...)
+	}
+
+	return strings.Join(rules, ",")
+}
+
+// goType returns the Go type of a schema, declaring the inline objects as name.
+func (b *restTypeBuilder) goType(schema *openAPISchema, name string) string {
+	if schema == nil {
+		return "any"
+	}
+
+	if schema.Ref != "" {
+		if ref, ok := strings.CutPrefix(schema.Ref, componentsPrefix+"schemas/"); ok {
+			return goName(ref)
+		}
+
+		return "any"
+	}
+
+	switch schema.Type.name() {
+	case "string":
+		if schema.Format == "date-time" {
+			b.usesTime = true
+
+			return "time.Time"
+		}
+
+		return "string"
+	case "integer":
+		if schema.Format == "int32" {
+			return "int32"
+		}
+
+		return "int64"
+	case "number":
+		if schema.Format == "float" {
+			return "float32"
+		}
+
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]" + b.goType(schema.Items, name+"Item")
+	}
+
+	if len(schema.Properties) > 0 {
+		name = b.uniqueName(name)
+		b.declared[name] = true
+		b.structs[name] = true
+		b.declare(name, schema)
+
+		return name
+	}
+
+	var additional openAPISchema
+	if json.Unmarshal(schema.AdditionalProperties, &additional) == nil {
+		return "map[string]" + b.goType(&additional, name+"Value")
+	}
+
+	if schema.Type.name() == "object" {
+		return "map[string]any"
+	}
+
+	return "any"
+}
+
+func (b *restTypeBuilder) uniqueName(name string) string {
+	unique := name
+
+	for i := 2; b.declared[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+
+	b.declared[unique] = true
+
+	return unique
+}
+
+func (b *restTypeBuilder) resolveSchema(schema *openAPISchema) *openAPISchema {
+	if schema == nil {
+		return &openAPISchema{}
+	}
+
+	if ref, ok := strings.CutPrefix(schema.Ref, componentsPrefix+"schemas/"); ok {
+		if resolved, ok := b.spec.Components.Schemas[ref]; ok && resolved != schema {
+			return b.resolveSchema(resolved)
+		}
+	}
+
+	return schema
+}
+
+func (b *restTypeBuilder) resolveParameters(params []*openAPIParameter) []*openAPIParameter {
+	resolved := make([]*openAPIParameter, 0, len(params))
+
+	for _, param := range params {
+		if ref, ok := strings.CutPrefix(param.Ref, componentsPrefix+"parameters/"); ok {
+			param = b.spec.Components.Parameters[ref]
+		}
+
+		if param != nil && param.Name != "" {
+			resolved = append(resolved, param)
+		}
+	}
+
+	return resolved
+}
+
+func (b *restTypeBuilder) resolveRequestBody(body *openAPIRequestBody) *openAPIRequestBody {
+	if body == nil {
+		return nil
+	}
+
+	if ref, ok := strings.CutPrefix(body.Ref, componentsPrefix+"requestBodies/"); ok {
+		return b.spec.Components.RequestBodies[ref]
+	}
+
+	return body
+}
+
+func (b *restTypeBuilder) resolveResponse(res *openAPIResponse) *openAPIResponse {
+	if res == nil {
+		return nil
+	}
+
+	if ref, ok := strings.CutPrefix(res.Ref, componentsPrefix+"responses/"); ok {
+		return b.spec.Components.Responses[ref]
+	}
+
+	return res
+}
+
+// mergeParameters returns the parameters of a path and of its operation, which override the ones of the path with
+// the same name and location.
+func mergeParameters(pathParams, opParams []*openAPIParameter) []*openAPIParameter {
+	params := slices.Clone(opParams)
+
+	for _, param := range pathParams {
+		if !slices.ContainsFunc(opParams, func(p *openAPIParameter) bool { return p.Name == param.Name && p.In == param.In }) {
+			params = append(params, param)
+		}
+	}
+
+	return params
+}
+
+// jsonSchema returns the schema of the JSON content, or of the first content when there is no JSON one.
+func jsonSchema(content map[string]openAPIMediaType) *openAPISchema {
+	for _, mediaType := range sortedKeys(content) {
+		if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+			return content[mediaType].Schema
+		}
+	}
+
+	for _, mediaType := range sortedKeys(content) {
+		return content[mediaType].Schema
+	}
+
+	return nil
+}
+
+// paramType returns the Go type of a parameter and the function parsing it.
+func paramType(schema *openAPISchema) (typ, parse string) {
+	switch schema.Type.name() {
+	case "integer":
+		if schema.Format == "int32" {
+			return "int32", "parseInt32"
+		}
+
+		return "int64", "parseInt64"
+	case "number":
+		if schema.Format == "float" {
+			return "float32", "parseFloat32"
+		}
+
+		return "float64", "parseFloat64"
+	case "boolean":
+		return "bool", "parseBool"
+	case "string":
+		if schema.Format == "date-time" {
+			return "time.Time", "parseTime"
+		}
+	}
+
+	return "string", "parseString"
+}
+
+func appendLengthRules(rules []string, minimum, maximum *int) []string {
+	if minimum != nil {
+		rules = append(rules, "min="+strconv.Itoa(*minimum))
+	}
+
+	if maximum != nil {
+		rules = append(rules, "max="+strconv.Itoa(*maximum))
+	}
+
+	return rules
+}
+
+// appendEnumRule adds a "oneof" rule for the values of an enum, unless one of them contains a space.
+func appendEnumRule(rules []string, enum []any) []string {
+	values := make([]string, 0, len(enum))
+
+	for _, v := range enum {
+		value := fmt.Sprint(v)
+		if v == nil || value == "" || strings.ContainsAny(value, " ,'") {
+			return rules
+		}
+
+		values = append(values, value)
+	}
+
+	if len(values) == 0 {
+		return rules
+	}
+
+	return append(rules, "oneof="+strings.Join(values, " "))
+}
+
+func isObject(schema *openAPISchema) bool {
+	return schema != nil && schema.Ref == "" && len(schema.Properties) > 0
+}
+
+func isScalar(typ string) bool {
+	return typ == "string" || typ == "time.Time" || isNumberOrBool(typ)
+}
+
+func isNumberOrBool(typ string) bool {
+	switch typ {
+	case "int32", "int64", "float32", "float64", "bool":
+		return true
+	}
+
+	return false
+}
+
+// operationName names the operations without operationId after their method and path, e.g. GetPetsByPetID for
+// GET /pets/{petId}.
+func operationName(method, p string) string {
+	name := goName(strings.ToLower(method))
+
+	for _, segment := range strings.Split(p, "/") {
+		if param, ok := strings.CutPrefix(segment, "{"); ok {
+			name += "By" + goName(strings.TrimSuffix(param, "}"))
+		} else {
+			name += goName(segment)
+		}
+	}
+
+	return name
+}
+
+// commonInitialisms are written in upper case in the Go names, as golint expects.
+var commonInitialisms = map[string]bool{
+	"API": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true, "SQL": true,
+	"TLS": true, "URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// goName converts a name of the spec to an exported Go identifier, e.g. PetID for pet_id or petId.
+func goName(name string) string {
+	var (
+		words []string
+		word  []rune
+	)
+
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = nil
+		}
+	}
+
+	runes := []rune(name)
+
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+			continue
+		case unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) ||
+			unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1])):
+			flush()
+		}
+
+		word = append(word, r)
+	}
+
+	flush()
+
+	var b strings.Builder
+
+	for _, w := range words {
+		if upper := strings.ToUpper(w); commonInitialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+
+		r := []rune(w)
+		b.WriteString(strings.ToUpper(string(r[0])) + string(r[1:]))
+	}
+
+	result := b.String()
+	if result != "" && unicode.IsDigit([]rune(result)[0]) {
+		result = "N" + result
+	}
+
+	return result
+}
+
+// goPackageName converts the name of the output directory to a Go package name.
+func goPackageName(dir string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+
+		return -1
+	}, dir)
+
+	if name == "" || unicode.IsDigit([]rune(name)[0]) {
+		return "api"
+	}
+
+	return name
+}
+
+func sortedKeys[K cmp.Ordered, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
@@ -0,0 +1,254 @@
+package wrap
+
+const (
+	restTypesTemplate = `// Code generated by kite-cli. DO NOT EDIT.
+// versions:
+// 	kite-cli v0.1.0
+// 	kite v0.1.0
+// 	source: {{ .Source }}
+
+package {{ .Package }}
+{{- if .UsesTime }}
+
+import "time"
+{{- end }}
+{{ range .Types }}
+{{- if .Doc }}
+// {{ .Name }} {{ .Doc }}
+{{- end }}
+{{- if .Fields }}
+type {{ .Name }} struct {
+{{- range .Fields }}
+	{{- if .Doc }}
+	// {{ .Doc }}
+	{{- end }}
+	{{ .Name }} {{ .Type }}{{ if .Tag }} ` + "`{{ .Tag }}`" + `{{ end }}
+{{- end }}
+}
+{{- else if .Definition }}
+type {{ .Name }} {{ .Definition }}
+{{- else }}
+type {{ .Name }} struct{}
+{{- end }}
+{{ end }}`
+
+	restRoutesTemplate = `// Code generated by kite-cli. DO NOT EDIT.
+// versions:
+// 	kite-cli v0.1.0
+// 	kite v0.1.0
+// 	source: {{ .Source }}
+
+package {{ .Package }}
+
+import (
+	"github.com/sllt/kite/pkg/kite"
+)
+
+// {{ .API }}Handler serves the operations of {{ .Title }}.
+type {{ .API }}Handler interface {
+{{- range .Operations }}
+	{{- if .Summary }}
+	// {{ .Name }}: {{ .Summary }}
+	{{- end }}
+	{{ .Name }}(ctx *kite.Context{{ if .HasRequest }}, req *{{ .Request }}{{ end }}) {{ .Results }}
+{{- end }}
+}
+
+// Register{{ .API }}Routes registers the routes of the operations of {{ .Title }}, served by handler. The path and
+// query parameters are parsed, and the body is bound and validated, before calling the handler.
+func Register{{ .API }}Routes(app *kite.App, handler {{ .API }}Handler) {
+{{- range .Operations }}
+	app.{{ .Method }}("{{ .Path }}", func(ctx *kite.Context) (any, error) {
+	{{- if .HasRequest }}
+		var req {{ .Request }}
+	{{- if .Params }}
+
+		params := &paramBinder{ctx: ctx}
+		{{- range .Params }}
+		req.{{ .Field }} = {{ if .Multi }}bindParams{{ else }}bindParam{{ end }}(params, "{{ .In }}", "{{ .Name }}", {{ .Required }}, {{ .Parse }})
+		{{- end }}
+
+		if err := params.err(); err != nil {
+			return nil, err
+		}
+	{{- end }}
+	{{- if .Body }}
+
+		if err := ctx.Bind(&req.Body); err != nil {
+			return nil, err
+		}
+	{{- end }}
+
+	{{- end }}
+	{{- if .Response }}
+
+		return handler.{{ .Name }}(ctx{{ if .HasRequest }}, &req{{ end }})
+	{{- else }}
+
+		return nil, handler.{{ .Name }}(ctx{{ if .HasRequest }}, &req{{ end }})
+	{{- end }}
+	})
+{{- end }}
+}
+`
+
+	restParamsTemplate = `// Code generated by kite-cli. DO NOT EDIT.
+// versions:
+// 	kite-cli v0.1.0
+// 	kite v0.1.0
+
+package {{ .Package }}
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/sllt/kite/pkg/kite"
+	kiteHTTP "github.com/sllt/kite/pkg/kite/http"
+)
+
+// paramBinder reads the path and query parameters of a request, collecting the missing and invalid ones.
+type paramBinder struct {
+	ctx     *kite.Context
+	missing []string
+	invalid []string
+}
+
+// err returns the error of the missing parameters, or else of the invalid ones, which respond with 400 Bad Request.
+func (b *paramBinder) err() error {
+	if len(b.missing) > 0 {
+		return kiteHTTP.ErrorMissingParam{Params: b.missing}
+	}
+
+	if len(b.invalid) > 0 {
+		return kiteHTTP.ErrorInvalidParam{Params: b.invalid}
+	}
+
+	return nil
+}
+
+// bindParam returns the value of the parameter name, from the path or the query as set by in.
+func bindParam[T any](b *paramBinder, in, name string, required bool, parse func(string) (T, error)) T {
+	var zero T
+
+	raw := b.ctx.Param(name)
+	if in == "path" {
+		raw = b.ctx.PathParam(name)
+	}
+
+	if raw == "" {
+		if required {
+			b.missing = append(b.missing, name)
+		}
+
+		return zero
+	}
+
+	value, err := parse(raw)
+	if err != nil {
+		b.invalid = append(b.invalid, name)
+
+		return zero
+	}
+
+	return value
+}
+
+// bindParams returns the values of the query parameter name, repeated or separated by commas.
+func bindParams[T any](b *paramBinder, _, name string, required bool, parse func(string) (T, error)) []T {
+	raw := b.ctx.Params(name)
+	if len(raw) == 0 {
+		if required {
+			b.missing = append(b.missing, name)
+		}
+
+		return nil
+	}
+
+	values := make([]T, 0, len(raw))
+
+	for _, r := range raw {
+		value, err := parse(r)
+		if err != nil {
+			b.invalid = append(b.invalid, name)
+
+			return nil
+		}
+
+		values = append(values, value)
+	}
+
+	return values
+}
+
+func parseString(s string) (string, error) {
+	return s, nil
+}
+
+func parseInt32(s string) (int32, error) {
+	v, err := strconv.ParseInt(s, 10, 32)
+
+	return int32(v), err
+}
+
+func parseInt64(s string) (int64, error) {
+	return strconv.ParseInt(s, 10, 64)
+}
+
+func parseFloat32(s string) (float32, error) {
+	v, err := strconv.ParseFloat(s, 32)
+
+	return float32(v), err
+}
+
+func parseFloat64(s string) (float64, error) {
+	return strconv.ParseFloat(s, 64)
+}
+
+func parseBool(s string) (bool, error) {
+	return strconv.ParseBool(s)
+}
+
+func parseTime(s string) (time.Time, error) {
+	return time.Parse(time.RFC3339, s)
+}
+`
+
+	restServerTemplate = `// versions:
+// 	kite-cli v0.1.0
+// 	kite v0.1.0
+// 	source: {{ .Source }}
+//
+// Code between "kite:begin" and "kite:end" markers is preserved when this file is regenerated
+// with "kite generate server". Changes outside of these regions are overwritten.
+
+package {{ .Package }}
+
+import (
+	"github.com/sllt/kite/pkg/kite"
+	// kite:begin imports
+	// kite:end imports
+)
+
+// Register the routes in your app using the following code in your main.go:
+//
+// {{ .Package }}.Register{{ .API }}Routes(app, &{{ .Package }}.{{ .API }}Server{})
+//
+// {{ .API }}Server implements {{ .API }}Handler.
+// Customize the struct with required dependencies and fields as needed.
+
+type {{ .API }}Server struct {
+	// kite:begin fields
+	// kite:end fields
+}
+{{ range .Operations }}
+func (s *{{ $.API }}Server) {{ .Name }}(ctx *kite.Context{{ if .HasRequest }}, req *{{ .Request }}{{ end }}) {{ .Results }} {
+	// kite:begin {{ .Name }}
+	return {{ .Zero }}
+	// kite:end {{ .Name }}
+}
+{{ end }}
+// kite:begin custom
+// kite:end custom
+`
+)
@@ -0,0 +1,106 @@
+package wrap
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+const (
+	regionBegin = "// kite:begin "
+	regionEnd   = "// kite:end "
+)
+
+// parseRegions extracts the content of the protected regions of a file, keyed by region name.
+// A region starts with a "// kite:begin <name>" line and ends with the matching "// kite:end <name>" line.
+// Unterminated regions are ignored.
+func parseRegions(content string) map[string]string {
+	regions := make(map[string]string)
+	lines := strings.Split(content, "\n")
+
+	for i := 0; i < len(lines); i++ {
+		name, ok := strings.CutPrefix(strings.TrimSpace(lines[i]), regionBegin)
+		if !ok {
+			continue
+		}
+
+		name = strings.TrimSpace(name)
+
+		for j := i + 1; j < len(lines); j++ {
+			if strings.TrimSpace(lines[j]) == regionEnd+name {
+				regions[name] = strings.Join(lines[i+1:j], "\n")
+				i = j
+
+				break
+			}
+		}
+	}
+
+	return regions
+}
+
+// mergeRegions replaces the content of the protected regions in the generated code with the content
+// preserved from the existing file. It returns the merged code and the names of the preserved regions
+// which no longer exist in the generated code, e.g. because the RPC was removed from the proto file.
+func mergeRegions(generated string, preserved map[string]string) (merged string, orphaned []string) {
+	lines := strings.Split(generated, "\n")
+	out := make([]string, 0, len(lines))
+	used := make(map[string]bool, len(preserved))
+
+	for i := 0; i < len(lines); i++ {
+		out = append(out, lines[i])
+
+		name, ok := strings.CutPrefix(strings.TrimSpace(lines[i]), regionBegin)
+		if !ok {
+			continue
+		}
+
+		name = strings.TrimSpace(name)
+
+		content, ok := preserved[name]
+		if !ok {
+			continue
+		}
+
+		end := i + 1
+		for end < len(lines) && strings.TrimSpace(lines[end]) != regionEnd+name {
+			end++
+		}
+
+		if end == len(lines) {
+			continue
+		}
+
+		if content != "" {
+			out = append(out, content)
+		}
+
+		out = append(out, lines[end])
+		used[name] = true
+		i = end
+	}
+
+	for name := range preserved {
+		if !used[name] {
+			orphaned = append(orphaned, name)
+		}
+	}
+
+	slices.Sort(orphaned)
+
+	return strings.Join(out, "\n"), orphaned
+}
+
+// unifiedDiff returns the changes between the current and the generated content of a file.
+func unifiedDiff(filePath, current, generated string) string {
+	diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(current),
+		B:        difflib.SplitLines(generated),
+		FromFile: "a/" + filePath,
+		ToFile:   "b/" + filePath,
+		Context:  3,
+	})
+
+	return diff
+}
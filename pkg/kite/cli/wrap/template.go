@@ -31,17 +31,13 @@ import (
 
 	"github.com/sllt/kite/pkg/kite"
 	"github.com/sllt/kite/pkg/kite/infra"
-	{{- if $hasStream }}
 	kiteGRPC "github.com/sllt/kite/pkg/kite/grpc"
-	{{- end }}
 	"google.golang.org/grpc"
 
 	{{- if $hasUnary }}
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	{{- end }}
-
-	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 // New{{ .Service }}KiteServer creates a new instance of {{ .Service }}KiteServer
@@ -116,6 +112,7 @@ func (s *instrumentedStream) RecvMsg(m interface{}) error {
 // Client-side streaming specific wrapper
 type clientStreamWrapper{{ .Name }} struct {
 	*instrumentedStream
+	req *{{ .Request }}Wrapper
 }
 
 func (w *clientStreamWrapper{{ .Name }}) SendAndClose(m *{{ .Response }}) error {
@@ -139,6 +136,14 @@ func (w *clientStreamWrapper{{ .Name }}) Recv() (*{{ .Request }}, error) {
 
 	var req {{ .Request }}
 	err := w.ServerStream.RecvMsg(&req)
+	if err == nil {
+		// the invalid messages are rejected before they reach the handler.
+		err = kiteGRPC.Validate(&req)
+	}
+
+	if err == nil {
+		w.req.{{ .Request }} = &req
+	}
 
 	logger := kiteGRPC.NewgRPCLogger()
 	logger.DocumentRPCLog(w.ctx, w.ctx.Logger, w.ctx.Metrics(), start, err,
@@ -172,6 +177,7 @@ func (w *serverStreamWrapper{{ .Name }}) Send(m *{{ .Response }}) error {
 // Bidirectional streaming wrapper
 type bidiStreamWrapper{{ .Name }} struct {
 	*instrumentedStream
+	req *{{ .Request }}Wrapper
 }
 
 func (w *bidiStreamWrapper{{ .Name }}) Send(m *{{ .Response }}) error {
@@ -195,6 +201,14 @@ func (w *bidiStreamWrapper{{ .Name }}) Recv() (*{{ .Request }}, error) {
 
 	var req {{ .Request }}
 	err := w.ServerStream.RecvMsg(&req)
+	if err == nil {
+		// the invalid messages are rejected before they reach the handler.
+		err = kiteGRPC.Validate(&req)
+	}
+
+	if err == nil {
+		w.req.{{ .Request }} = &req
+	}
 
 	logger := kiteGRPC.NewgRPCLogger()
 	logger.DocumentRPCLog(w.ctx, w.ctx.Logger, w.ctx.Metrics(), start, err,
@@ -225,6 +239,10 @@ func (w *bidiStreamWrapper{{ .Name }}) CloseSend() error {
 {{- if not .StreamsRequest }}
 // Server-side streaming handler for {{ .Name }}
 func (h *{{ $.Service }}ServerWrapper) {{ .Name }}(req *{{ .Request }}, stream {{ $.Service }}_{{ .Name }}Server) error {
+	if err := kiteGRPC.Validate(req); err != nil {
+		return err
+	}
+
 	ctx := stream.Context()
 	gctx := h.getKiteContext(ctx, &{{ .Request }}Wrapper{ctx: ctx, {{ .Request }}: req})
 
@@ -241,7 +259,8 @@ func (h *{{ $.Service }}ServerWrapper) {{ .Name }}(req *{{ .Request }}, stream {
 // Bidirectional streaming handler for {{ .Name }}
 func (h *{{ $.Service }}ServerWrapper) {{ .Name }}(stream {{ $.Service }}_{{ .Name }}Server) error {
 	ctx := stream.Context()
-	gctx := h.getKiteContext(ctx, nil)
+	req := &{{ .Request }}Wrapper{ctx: ctx, {{ .Request }}: &{{ .Request }}{}}
+	gctx := h.getKiteContext(ctx, req)
 
 	is := &instrumentedStream{
 		ServerStream: stream,
@@ -249,7 +268,7 @@ func (h *{{ $.Service }}ServerWrapper) {{ .Name }}(stream {{ $.Service }}_{{ .Na
 		method:     "/{{ $.Service }}/{{ .Name }}",
 	}
 
-	wrappedStream := &bidiStreamWrapper{{ .Name }}{instrumentedStream: is}
+	wrappedStream := &bidiStreamWrapper{{ .Name }}{instrumentedStream: is, req: req}
 	return h.server.{{ .Name }}(gctx, wrappedStream)
 }
 {{- end }}
@@ -257,7 +276,8 @@ func (h *{{ $.Service }}ServerWrapper) {{ .Name }}(stream {{ $.Service }}_{{ .Na
 // Client-side streaming handler for {{ .Name }}
 func (h *{{ $.Service }}ServerWrapper) {{ .Name }}(stream {{ $.Service }}_{{ .Name }}Server) error {
 	ctx := stream.Context()
-	gctx := h.getKiteContext(ctx, nil)
+	req := &{{ .Request }}Wrapper{ctx: ctx, {{ .Request }}: &{{ .Request }}{}}
+	gctx := h.getKiteContext(ctx, req)
 
 	is := &instrumentedStream{
 		ServerStream: stream,
@@ -265,12 +285,16 @@ func (h *{{ $.Service }}ServerWrapper) {{ .Name }}(stream {{ $.Service }}_{{ .Na
 		method:     "/{{ $.Service }}/{{ .Name }}",
 	}
 
-	wrappedStream := &clientStreamWrapper{{ .Name }}{instrumentedStream: is}
+	wrappedStream := &clientStreamWrapper{{ .Name }}{instrumentedStream: is, req: req}
 	return h.server.{{ .Name }}(gctx, wrappedStream)
 }
 {{- else }}
 // Unary method handler for {{ .Name }}
 func (h *{{ $.Service }}ServerWrapper) {{ .Name }}(ctx context.Context, req *{{ .Request }}) (*{{ .Response }}, error) {
+	if err := kiteGRPC.Validate(req); err != nil {
+		return nil, err
+	}
+
 	gctx := h.getKiteContext(ctx, &{{ .Request }}Wrapper{ctx: ctx, {{ .Request }}: req})
 
 	res, err := h.server.{{ .Name }}(gctx)
@@ -301,7 +325,7 @@ func Register{{ .Service }}ServerWithKite(app *kite.App, srv {{ .Service }}Serve
 
 		Register{{ .Service }}Server(s, wrapper)
 
-		wrapper.Server.SetServingStatus("Hello", healthpb.HealthCheckResponse_SERVING)
+		app.MonitorGRPCHealth(wrapper.Server, "{{ .Service }}")
 	})
 }
 
@@ -326,8 +350,8 @@ package {{ .Package }}
 
 import (
 	"context"
-	"fmt"
-	"reflect"
+
+	kiteGRPC "github.com/sllt/kite/pkg/kite/grpc"
 )
 
 // Request Wrappers
@@ -349,27 +373,10 @@ func (h *{{ $request }}Wrapper) PathParam(s string) string {
 	return ""
 }
 
+// Bind copies the request message into p, see kiteGRPC.Bind for how oneof, map and enum fields are bound.
+// For streaming requests, it binds the message most recently received on the stream.
 func (h *{{ $request }}Wrapper) Bind(p interface{}) error {
-	ptr := reflect.ValueOf(p)
-	if ptr.Kind() != reflect.Ptr {
-		return fmt.Errorf("expected a pointer, got %T", p)
-	}
-
-	hValue := reflect.ValueOf(h.{{ $request }}).Elem()
-	ptrValue := ptr.Elem()
-
-	for i := 0; i < hValue.NumField(); i++ {
-		field := hValue.Type().Field(i)
-		if field.Name == "state" || field.Name == "sizeCache" || field.Name == "unknownFields" {
-			continue
-		}
-
-		if field.IsExported() {
-			ptrValue.Field(i).Set(hValue.Field(i))
-		}
-	}
-
-	return nil
+	return kiteGRPC.Bind(h.{{ $request }}, p)
 }
 
 func (h *{{ $request }}Wrapper) HostName() string {
@@ -385,10 +392,17 @@ func (h *{{ $request }}Wrapper) Params(s string) []string {
 // 	kite-cli v0.1.0
 // 	kite v0.1.0
 // 	source: {{ .Source }}
+//
+// Code between "kite:begin" and "kite:end" markers is preserved when this file is regenerated
+// with "kite wrap grpc server". Changes outside of these regions are overwritten.
 
 package {{ .Package }}
 
-import "github.com/sllt/kite/pkg/kite"
+import (
+	"github.com/sllt/kite/pkg/kite"
+	// kite:begin imports
+	// kite:end imports
+)
 
 // Register the gRPC service in your app using the following code in your main.go:
 //
@@ -399,25 +413,36 @@ import "github.com/sllt/kite/pkg/kite"
 
 type {{ $.Service }}KiteServer struct {
  health *healthServer
+	// kite:begin fields
+	// kite:end fields
 }
 
 {{- range .Methods }}
 {{- if .StreamsRequest }}
 func (s *{{ $.Service }}KiteServer) {{ .Name }}(ctx *kite.Context, stream {{ $.Service }}_{{ .Name }}Server) error {
+	// kite:begin {{ .Name }}
 	// Implementation here
 	return nil
+	// kite:end {{ .Name }}
 }
 {{- else if .StreamsResponse }}
 func (s *{{ $.Service }}KiteServer) {{ .Name }}(ctx *kite.Context, stream {{ $.Service }}_{{ .Name }}Server) error {
+	// kite:begin {{ .Name }}
 	// Implementation here
 	return nil
+	// kite:end {{ .Name }}
 }
 {{- else }}
 func (s *{{ $.Service }}KiteServer) {{ .Name }}(ctx *kite.Context) (any, error) {
+	// kite:begin {{ .Name }}
 	return &{{ .Response }}{}, nil
+	// kite:end {{ .Name }}
 }
 {{- end }}
 {{- end }}
+
+// kite:begin custom
+// kite:end custom
 `
 
 	clientTemplate = `// Code generated by kite-cli. DO NOT EDIT.
@@ -426,20 +451,46 @@ func (s *{{ $.Service }}KiteServer) {{ .Name }}(ctx *kite.Context) (any, error)
 // 	kite v0.1.0
 // 	source: {{ .Source }}
 
+{{- $hasServerStream := false }}
+{{- $hasClientStream := false }}
+{{- range .Methods }}
+    {{- if and .StreamsResponse (not .StreamsRequest) }}
+        {{- $hasServerStream = true }}
+    {{- else if and .StreamsRequest (not .StreamsResponse) }}
+        {{- $hasClientStream = true }}
+    {{- end }}
+{{- end }}
+
 package {{ .Package }}
 
 import (
+	"context"
+	{{- if or $hasServerStream $hasClientStream }}
+	"iter"
+	{{- end }}
+
 	"github.com/sllt/kite/pkg/kite"
+	"github.com/sllt/kite/pkg/kite/infra/grpcpool"
 	"github.com/sllt/kite/pkg/kite/metrics"
 	"google.golang.org/grpc"
+
+	{{- if $hasClientStream }}
+	kiteErrors "github.com/sllt/kite/pkg/kite/errors"
+	{{- end }}
+	{{- if or $hasServerStream $hasClientStream }}
+	kiteGRPC "github.com/sllt/kite/pkg/kite/grpc"
+	{{- end }}
 )
 
 type {{ .Service }}KiteClient interface {
 {{- range .Methods }}
 {{- if and .StreamsResponse (not .StreamsRequest) }}
 	{{ .Name }}(ctx *kite.Context, req *{{ .Request }}, opts ...grpc.CallOption) (grpc.ServerStreamingClient[{{ .Response }}], error)
+	{{ .Name }}Stream(ctx *kite.Context, req *{{ .Request }}, opts ...grpc.CallOption) iter.Seq2[*{{ .Response }}, error]
+	{{ .Name }}CollectAll(ctx *kite.Context, req *{{ .Request }}, opts ...grpc.CallOption) ([]*{{ .Response }}, error)
 {{- else if and .StreamsRequest (not .StreamsResponse) }}
 	{{ .Name }}(ctx *kite.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[{{ .Request }}, {{ .Response }}], error)
+	{{ .Name }}SendAll(ctx *kite.Context, reqs iter.Seq[*{{ .Request }}], opts ...grpc.CallOption) (*{{ .Response }}, error)
 {{- else if and .StreamsRequest .StreamsResponse }}
 	{{ .Name }}(ctx *kite.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[{{ .Request }}, {{ .Response }}], error)
 {{- else }}
@@ -451,6 +502,7 @@ type {{ .Service }}KiteClient interface {
 
 type {{ .Service }}ClientWrapper struct {
 	client {{ .Service }}Client
+	target string
 	HealthClient
 }
 
@@ -463,25 +515,36 @@ func New{{ .Service }}KiteClient(host string, metrics metrics.Manager, dialOptio
 		}, err
 	}
 
-	metricsOnce.Do(func() {
-		metrics.NewHistogram("app_gRPC-Client_stats", "Response time of gRPC client in milliseconds.", gRPCBuckets...)
-	})
+	registerClientMetrics(metrics)
 
 	res := New{{ .Service }}Client(conn)
 	healthClient := NewHealthClient(conn)
 
 	return &{{ .Service }}ClientWrapper{
 		client: res,
+		target: conn.Target(),
 		HealthClient: healthClient,
 	}, nil
 }
 
+// New{{ .Service }}KiteClientFromPool returns a client spreading its calls over the connections of pool, which is
+// shared by the clients of the container, see Container.GRPCClientPool.
+func New{{ .Service }}KiteClientFromPool(pool *grpcpool.Pool, metrics metrics.Manager) {{ .Service }}KiteClient {
+	registerClientMetrics(metrics)
+
+	return &{{ .Service }}ClientWrapper{
+		client: New{{ .Service }}Client(pool),
+		target: pool.Target(),
+		HealthClient: newHealthClient(pool, pool.Target()),
+	}
+}
+
 {{ range .Methods }}
 {{- if and .StreamsResponse (not .StreamsRequest) }}
 func (h *{{ $.Service }}ClientWrapper) {{ .Name }}(ctx *kite.Context, req *{{ .Request }},
 	opts ...grpc.CallOption) (grpc.ServerStreamingClient[{{ .Response }}], error) {
-	result, err := invokeRPC(ctx, "/{{ $.Service }}/{{ .Name }}", func() (interface{}, error) {
-		return h.client.{{ .Name }}(ctx.Context, req, opts...)
+	result, err := invokeRPC(ctx, h.target, "/{{ $.Service }}/{{ .Name }}", "", func(callCtx context.Context) (interface{}, error) {
+		return h.client.{{ .Name }}(callCtx, req, opts...)
 	}, "app_gRPC-Stream_stats")
 
 	if err != nil {
@@ -489,11 +552,30 @@ func (h *{{ $.Service }}ClientWrapper) {{ .Name }}(ctx *kite.Context, req *{{ .R
 	}
 	return result.(grpc.ServerStreamingClient[{{ .Response }}]), nil
 }
+
+// {{ .Name }}Stream returns an iterator over the responses of {{ .Name }}. The stream is bound to ctx, so that its
+// deadline applies, and is closed when ctx is cancelled or the loop exits. Errors are converted to kite errors.
+func (h *{{ $.Service }}ClientWrapper) {{ .Name }}Stream(ctx *kite.Context, req *{{ .Request }},
+	opts ...grpc.CallOption) iter.Seq2[*{{ .Response }}, error] {
+	return kiteGRPC.Stream(ctx, func(streamCtx context.Context) (kiteGRPC.Receiver[{{ .Response }}], error) {
+		stream, err := h.{{ .Name }}(withStreamContext(ctx, streamCtx), req, opts...)
+		return stream, err
+	})
+}
+
+// {{ .Name }}CollectAll returns every response of {{ .Name }}, along with the first error, see {{ .Name }}Stream.
+func (h *{{ $.Service }}ClientWrapper) {{ .Name }}CollectAll(ctx *kite.Context, req *{{ .Request }},
+	opts ...grpc.CallOption) ([]*{{ .Response }}, error) {
+	return kiteGRPC.CollectAll(ctx, func(streamCtx context.Context) (kiteGRPC.Receiver[{{ .Response }}], error) {
+		stream, err := h.{{ .Name }}(withStreamContext(ctx, streamCtx), req, opts...)
+		return stream, err
+	})
+}
 {{- else if and .StreamsRequest (not .StreamsResponse) }}
 func (h *{{ $.Service }}ClientWrapper) {{ .Name }}(ctx *kite.Context,
 	opts ...grpc.CallOption) (grpc.ClientStreamingClient[{{ .Request }}, {{ .Response }}], error) {
-	result, err := invokeRPC(ctx, "/{{ $.Service }}/{{ .Name }}", func() (interface{}, error) {
-		return h.client.{{ .Name }}(ctx.Context, opts...)
+	result, err := invokeRPC(ctx, h.target, "/{{ $.Service }}/{{ .Name }}", "", func(callCtx context.Context) (interface{}, error) {
+		return h.client.{{ .Name }}(callCtx, opts...)
 	}, "app_gRPC-Stream_stats")
 
 	if err != nil {
@@ -501,11 +583,23 @@ func (h *{{ $.Service }}ClientWrapper) {{ .Name }}(ctx *kite.Context,
 	}
 	return result.(grpc.ClientStreamingClient[{{ .Request }}, {{ .Response }}]), nil
 }
+
+// {{ .Name }}SendAll sends every request of reqs on {{ .Name }} and returns the response of the server. The call is
+// bound to ctx, so that its deadline applies. Errors are converted to kite errors.
+func (h *{{ $.Service }}ClientWrapper) {{ .Name }}SendAll(ctx *kite.Context, reqs iter.Seq[*{{ .Request }}],
+	opts ...grpc.CallOption) (*{{ .Response }}, error) {
+	stream, err := h.{{ .Name }}(ctx, opts...)
+	if err != nil {
+		return nil, kiteErrors.FromGRPC(err)
+	}
+
+	return kiteGRPC.SendAll(stream, reqs)
+}
 {{- else if and .StreamsRequest .StreamsResponse }}
 func (h *{{ $.Service }}ClientWrapper) {{ .Name }}(ctx *kite.Context,
 	opts ...grpc.CallOption) (grpc.BidiStreamingClient[{{ .Request }}, {{ .Response }}], error) {
-	result, err := invokeRPC(ctx, "/{{ $.Service }}/{{ .Name }}", func() (interface{}, error) {
-		return h.client.{{ .Name }}(ctx.Context, opts...)
+	result, err := invokeRPC(ctx, h.target, "/{{ $.Service }}/{{ .Name }}", "", func(callCtx context.Context) (interface{}, error) {
+		return h.client.{{ .Name }}(callCtx, opts...)
 	}, "app_gRPC-Stream_stats")
 
 	if err != nil {
@@ -516,8 +610,8 @@ func (h *{{ $.Service }}ClientWrapper) {{ .Name }}(ctx *kite.Context,
 {{- else }}
 func (h *{{ $.Service }}ClientWrapper) {{ .Name }}(ctx *kite.Context, req *{{ .Request }},
 	opts ...grpc.CallOption) (*{{ .Response }}, error) {
-	result, err := invokeRPC(ctx, "/{{ $.Service }}/{{ .Name }}", func() (interface{}, error) {
-		return h.client.{{ .Name }}(ctx.Context, req, opts...)
+	result, err := invokeRPC(ctx, h.target, "/{{ $.Service }}/{{ .Name }}", "", func(callCtx context.Context) (interface{}, error) {
+		return h.client.{{ .Name }}(callCtx, req, opts...)
 	}, "app_gRPC-Client_stats")
 
 	if err != nil {
@@ -575,7 +669,7 @@ func registerServerWithKite(app *kite.App, srv any, registerFunc func(grpc.Servi
 		app.Metrics().NewHistogram("app_gRPC-Stream_stats", "Duration of gRPC stream in milliseconds.", gRPCBuckets...)
 
 		healthpb.RegisterHealthServer(s, h.Server)
-		h.Server.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+		app.MonitorGRPCHealth(h.Server, "")
 		healthServerRegistered = true
 	}
 
@@ -648,15 +742,16 @@ var clientHealthTemplate = `// Code generated by kite-cli. DO NOT EDIT.
 package {{ .Package }}
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
 
 	"github.com/sllt/kite/pkg/kite"
+	"github.com/sllt/kite/pkg/kite/metrics"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/health/grpc_health_v1"
-	"google.golang.org/grpc/metadata"
 
 	kiteGRPC "github.com/sllt/kite/pkg/kite/grpc"
 )
@@ -664,6 +759,8 @@ import (
 var (
 	metricsOnce sync.Once
 	gRPCBuckets = []float64{0.005, 0.01, .05, .075, .1, .125, .15, .2, .3, .5, .75, 1, 2, 3, 4, 5, 7.5, 10}
+
+	clientDurationBuckets = []float64{0.5, 1, 2.5, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
 )
 
 type HealthClient interface {
@@ -674,14 +771,30 @@ type HealthClient interface {
 
 type HealthClientWrapper struct {
 	client grpc_health_v1.HealthClient
+	target string
 }
 
 func NewHealthClient(conn *grpc.ClientConn) HealthClient {
+	return newHealthClient(conn, conn.Target())
+}
+
+func newHealthClient(conn grpc.ClientConnInterface, target string) HealthClient {
 	return &HealthClientWrapper{
 		client: grpc_health_v1.NewHealthClient(conn),
+		target: target,
 	}
 }
 
+// registerClientMetrics registers the metrics of the gRPC clients, once for all the clients of the package.
+func registerClientMetrics(metrics metrics.Manager) {
+	metricsOnce.Do(func() {
+		metrics.NewHistogram("app_gRPC-Client_stats", "Response time of gRPC client in milliseconds.", gRPCBuckets...)
+		metrics.NewHistogram("grpc_client_request_duration", "Response time of gRPC client calls in milliseconds.",
+			clientDurationBuckets...)
+		metrics.NewCounter("grpc_client_errors_total", "Total gRPC client calls which returned an error.")
+	})
+}
+
 func createGRPCConn(host string, serviceName string, dialOptions ...grpc.DialOption) (*grpc.ClientConn, error) {
 	serviceConfig := ` + "`{\"loadBalancingPolicy\": \"round_robin\"}`" + `
 
@@ -700,28 +813,42 @@ func createGRPCConn(host string, serviceName string, dialOptions ...grpc.DialOpt
 	return conn, nil
 }
 
-func invokeRPC(ctx *kite.Context, rpcName string, rpcFunc func() (interface{}, error), metricName string) (interface{}, error) {
-	span := ctx.Trace("gRPC-srv-call: " + rpcName)
-	defer span.End()
+// withStreamContext returns a copy of ctx carrying streamCtx, so that the streams opened with it are closed when
+// streamCtx is cancelled.
+func withStreamContext(ctx *kite.Context, streamCtx context.Context) *kite.Context {
+	c := *ctx
+	c.Context = streamCtx
 
-	traceID := span.SpanContext().TraceID().String()
-	spanID := span.SpanContext().SpanID().String()
-	md := metadata.Pairs("x-kite-traceid", traceID, "x-kite-spanid", spanID)
+	return &c
+}
 
-	ctx.Context = metadata.NewOutgoingContext(ctx.Context, md)
+// invokeRPC calls rpcFunc with a context derived from ctx, so that its deadline and cancellation are propagated to
+// the server, and records the client span, metrics and log of the call. logName replaces method in the log when set.
+func invokeRPC(ctx *kite.Context, target, method, logName string, rpcFunc func(context.Context) (interface{}, error),
+	metricName string) (interface{}, error) {
+	callCtx, span := kiteGRPC.StartClientSpan(ctx.Context, target, method)
 	transactionStartTime := time.Now()
 
-	res, err := rpcFunc()
+	res, err := rpcFunc(callCtx)
+
+	kiteGRPC.EndClientSpan(span, err)
+	kiteGRPC.RecordClientMetrics(callCtx, ctx.Metrics(), method, time.Since(transactionStartTime), err)
+
+	if logName == "" {
+		logName = method
+	}
+
 	logger := kiteGRPC.NewgRPCLogger()
-	logger.DocumentRPCLog(ctx.Context, ctx.Logger, ctx.Metrics(), transactionStartTime, err, rpcName, metricName)
+	logger.DocumentRPCLog(callCtx, ctx.Logger, ctx.Metrics(), transactionStartTime, err, logName, metricName)
 
 	return res, err
 }
 
 func (h *HealthClientWrapper) Check(ctx *kite.Context, in *grpc_health_v1.HealthCheckRequest,
 	opts ...grpc.CallOption) (*grpc_health_v1.HealthCheckResponse, error) {
-	result, err := invokeRPC(ctx, fmt.Sprintf("/grpc.health.v1.Health/Check	Service: %q", in.Service), func() (interface{}, error) {
-		return h.client.Check(ctx, in, opts...)
+	result, err := invokeRPC(ctx, h.target, "/grpc.health.v1.Health/Check",
+		fmt.Sprintf("/grpc.health.v1.Health/Check	Service: %q", in.Service), func(callCtx context.Context) (interface{}, error) {
+		return h.client.Check(callCtx, in, opts...)
 	}, "app_gRPC-Client_stats")
 
 	if err != nil {
@@ -732,8 +859,9 @@ func (h *HealthClientWrapper) Check(ctx *kite.Context, in *grpc_health_v1.Health
 
 func (h *HealthClientWrapper) Watch(ctx *kite.Context, in *grpc_health_v1.HealthCheckRequest,
 	opts ...grpc.CallOption) (grpc.ServerStreamingClient[grpc_health_v1.HealthCheckResponse], error) {
-	result, err := invokeRPC(ctx, fmt.Sprintf("/grpc.health.v1.Health/Watch	Service: %q", in.Service), func() (interface{}, error) {
-		return h.client.Watch(ctx, in, opts...)
+	result, err := invokeRPC(ctx, h.target, "/grpc.health.v1.Health/Watch",
+		fmt.Sprintf("/grpc.health.v1.Health/Watch	Service: %q", in.Service), func(callCtx context.Context) (interface{}, error) {
+		return h.client.Watch(callCtx, in, opts...)
 	}, "app_gRPC-Stream_stats")
 
 	if err != nil {
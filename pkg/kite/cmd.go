@@ -7,7 +7,9 @@ import (
 
 	cmd2 "github.com/sllt/kite/pkg/kite/cmd"
 	"github.com/sllt/kite/pkg/kite/cmd/terminal"
+	"github.com/sllt/kite/pkg/kite/config"
 	"github.com/sllt/kite/pkg/kite/infra"
+	"github.com/sllt/kite/pkg/kite/logging"
 )
 
 type cmd struct {
@@ -34,6 +36,64 @@ func (e ErrCommandNotFound) Error() string {
 	return fmt.Sprintf("'%s' is not a valid command.", e.Command)
 }
 
+// logFlags are the global flags of command line applications which configure the logger.
+type logFlags struct {
+	level    logging.Level
+	levelSet bool
+	format   string
+}
+
+// parseLogFlags parses -v/--verbose, -q/--quiet and --log-format=text|json from the arguments of the command.
+func parseLogFlags(args []string) logFlags {
+	flags := logFlags{level: logging.WARN}
+
+	for _, a := range args {
+		name, value, _ := strings.Cut(strings.TrimLeft(a, "-"), "=")
+		if name == "" || a[0] != '-' {
+			continue
+		}
+
+		switch name {
+		case "v", "verbose":
+			flags.level, flags.levelSet = logging.DEBUG, true
+		case "q", "quiet":
+			flags.level, flags.levelSet = logging.ERROR, true
+		case "log-format":
+			flags.format = value
+		}
+	}
+
+	return flags
+}
+
+// newCMDLogger creates the logger of a command line application. Logs are written to CMD_LOGS_FILE when set, and to
+// stderr otherwise where only the warnings and errors are shown by default. -v logs everything, -q only the errors.
+func newCMDLogger(cfg config.Config, args []string) logging.Logger {
+	flags := parseLogFlags(args)
+
+	if path := cfg.Get("CMD_LOGS_FILE"); path != "" {
+		l := logging.NewFileLogger(path)
+		if flags.levelSet {
+			l.ChangeLevel(flags.level)
+		}
+
+		return l
+	}
+
+	format := flags.format
+	if format != "" && format != logging.FormatText && format != logging.FormatJSON {
+		format = ""
+	}
+
+	l := logging.NewWriterLogger(os.Stderr, flags.level, format)
+
+	if format != flags.format {
+		l.Errorf("invalid --log-format %q, use %q or %q", flags.format, logging.FormatText, logging.FormatJSON)
+	}
+
+	return l
+}
+
 func (cmd *cmd) Run(c *infra.Container) {
 	args := os.Args[1:] // First one is command itself
 	subCommand, showHelp, firstArg := parseArgs(args)
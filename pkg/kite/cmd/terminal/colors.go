@@ -19,10 +19,20 @@ const (
 	BrightWhite
 )
 
+// SetColor sets the foreground color of the output, unless colors are disabled.
 func (o *Out) SetColor(colorCode int) {
+	if o.noColor {
+		return
+	}
+
 	o.Printf(csi+"38;5;%d"+"m", colorCode)
 }
 
+// ResetColor resets the foreground color of the output, unless colors are disabled.
 func (o *Out) ResetColor() {
+	if o.noColor {
+		return
+	}
+
 	o.Print(csi + "0m")
 }
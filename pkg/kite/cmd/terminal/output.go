@@ -44,10 +44,12 @@ type Output interface {
 	getSize() (int, int, error)
 }
 
-// terminal stores the UNIX file descriptor and isTerminal check for the tty.
+// terminal stores the UNIX file descriptor and isTerminal check for the tty, noColor disables the colors which
+// are not shown when the output is not a tty or when NO_COLOR is set.
 type terminal struct {
 	fd         uintptr
 	isTerminal bool
+	noColor    bool
 }
 
 // Out manages the cli outputs that is user facing with many functionalities
@@ -60,6 +62,7 @@ type Out struct {
 func New() *Out {
 	o := &Out{out: os.Stdout}
 	o.fd, o.isTerminal = getTerminalInfo(o.out)
+	o.noColor = !o.isTerminal || os.Getenv("NO_COLOR") != ""
 
 	return o
 }
@@ -124,6 +127,10 @@ const (
 
 // Reset the terminal to its default style, removing any active styles.
 func (o *Out) Reset() {
+	if o.noColor {
+		return
+	}
+
 	fmt.Fprint(o.out, csi+"0"+"m")
 }
 
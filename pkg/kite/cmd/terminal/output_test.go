@@ -18,6 +18,26 @@ func TestNewOutput(t *testing.T) {
 
 	// for tests, the os.Stdout do not directly outputs to the terminal.
 	assert.False(t, o.isTerminal)
+	assert.True(t, o.noColor, "colors are disabled when not writing to a terminal")
+}
+
+func TestNoColor(t *testing.T) {
+	o := tempOutput(t)
+	o.noColor = true
+
+	o.SetColor(Red)
+	o.Print("error")
+	o.ResetColor()
+	o.Reset()
+
+	validate(t, o, "error")
+
+	o = tempOutput(t)
+
+	o.SetColor(Red)
+	o.ResetColor()
+
+	validate(t, o, "\x1b[38;5;1m\x1b[0m")
 }
 
 func tempOutput(t *testing.T) *Out {
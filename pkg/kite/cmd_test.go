@@ -351,3 +351,51 @@ func Test_Run_handler_help(t *testing.T) {
 	// check that only help for the hello subcommand is printed
 	assert.Equal(t, "this a helper string for hello sub command\n", out)
 }
+
+func TestParseLogFlags(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		args     []string
+		expected logFlags
+	}{
+		{"no flags", []string{"migrate", "-name=users"}, logFlags{level: logging.WARN}},
+		{"verbose", []string{"migrate", "-v"}, logFlags{level: logging.DEBUG, levelSet: true}},
+		{"quiet", []string{"migrate", "--quiet"}, logFlags{level: logging.ERROR, levelSet: true}},
+		{"log format", []string{"migrate", "--log-format=json", "--verbose"},
+			logFlags{level: logging.DEBUG, levelSet: true, format: "json"}},
+		{"empty and positional arguments", []string{"", "-", "v", "quiet"}, logFlags{level: logging.WARN}},
+	}
+
+	for i, tc := range testCases {
+		assert.Equal(t, tc.expected, parseLogFlags(tc.args), "TEST[%d] Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestNewCMDLogger(t *testing.T) {
+	logs := testutil.StderrOutputForFunc(func() {
+		l := newCMDLogger(config.NewMockConfig(nil), []string{"migrate", "--log-format=json"})
+
+		l.Info("hidden by default")
+		l.Warn("warning shown")
+	})
+
+	assert.NotContains(t, logs, "hidden by default")
+	assert.Contains(t, logs, `"message":"warning shown"`)
+
+	logs = testutil.StderrOutputForFunc(func() {
+		l := newCMDLogger(config.NewMockConfig(nil), []string{"migrate", "-v", "--log-format=xml"})
+
+		l.Debug("debug shown")
+	})
+
+	assert.Contains(t, logs, `invalid --log-format \"xml\"`)
+	assert.Contains(t, logs, "debug shown")
+
+	logs = testutil.StderrOutputForFunc(func() {
+		l := newCMDLogger(config.NewMockConfig(nil), []string{"migrate", "-q"})
+
+		l.Warn("warning hidden")
+	})
+
+	assert.NotContains(t, logs, "warning hidden")
+}
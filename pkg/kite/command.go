@@ -0,0 +1,111 @@
+package kite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	cmd2 "github.com/sllt/kite/pkg/kite/cmd"
+	"github.com/sllt/kite/pkg/kite/cmd/terminal"
+)
+
+// runCommandArg is the first argument of the binary which runs a command registered with App.Command.
+const runCommandArg = "run"
+
+var errCommandPanic = errors.New("command panicked")
+
+// Command registers a one-off command of the application, run with `<binary> run <name> [flags]` instead of serving:
+//
+//	app.Command("backfill-users", func(ctx *kite.Context) (any, error) {
+//		since := ctx.Param("since") // ./myapp run backfill-users --since 2024-01-01
+//		...
+//	}, kite.AddDescription("Backfills the users created since --since"))
+//
+// The handler runs with the container of the application, once its datasources are connected, as a command of a
+// command line application: the flags are its params, and its result is written to stdout. The application then shuts
+// down instead of starting its servers, and exits with the status 1 when the handler fails. The OnStart hooks are not
+// run. `<binary> run` lists the commands.
+//
+// Commands replace the throwaway main.go files of the operational scripts, such as backfills and data fixes, which
+// would have to wire the datasources again.
+func (a *App) Command(name string, handler Handler, options ...Options) {
+	if a.commands == nil {
+		a.commands = &cmd{out: terminal.New()}
+	}
+
+	a.commands.addRoute(name, handler, options...)
+}
+
+// runCommand runs the command named by args, the arguments of the binary, and reports whether they are the arguments
+// of a command.
+func (a *App) runCommand(ctx context.Context, args []string) (bool, error) {
+	if a.commands == nil || len(args) == 0 || args[0] != runCommandArg {
+		return false, nil
+	}
+
+	args = args[1:]
+	subCommand, showHelp, _ := parseArgs(args)
+	name, _, _ := strings.Cut(strings.TrimSpace(subCommand), " ")
+
+	if name == "" {
+		a.commands.printHelp()
+
+		return true, nil
+	}
+
+	r := a.commands.command(name)
+	if r == nil || r.handler == nil {
+		a.commands.printHelp()
+
+		return true, ErrCommandNotFound{Command: name}
+	}
+
+	if showHelp {
+		a.commands.out.Println(r.help)
+
+		return true, nil
+	}
+
+	c := newCMDContext(&cmd2.Responder{}, cmd2.NewRequest(args), a.container, a.commands.out)
+	c.Context = ctx
+
+	start := time.Now()
+
+	data, err := runCommandHandler(r.handler, c)
+	c.responder.Respond(data, err)
+
+	if err != nil {
+		a.Logger().Errorf("command %s failed after %v: %v", name, time.Since(start), err)
+
+		return true, err
+	}
+
+	a.Logger().Infof("command %s completed in %v", name, time.Since(start))
+
+	return true, nil
+}
+
+// command returns the command registered with name, or nil.
+func (cmd *cmd) command(name string) *route {
+	for i := range cmd.routes {
+		if cmd.routes[i].pattern == name {
+			return &cmd.routes[i]
+		}
+	}
+
+	return nil
+}
+
+// runCommandHandler runs the handler of a command, returning its panic as an error so that the application still
+// shuts down.
+func runCommandHandler(handler Handler, c *Context) (data any, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("%w: %v", errCommandPanic, p)
+		}
+	}()
+
+	return handler(c)
+}
@@ -0,0 +1,82 @@
+package kite
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sllt/kite/pkg/kite/config"
+	"github.com/sllt/kite/pkg/kite/infra"
+	"github.com/sllt/kite/pkg/kite/logging"
+	"github.com/sllt/kite/pkg/kite/testutil"
+)
+
+var errBackfill = errors.New("backfill failed")
+
+func newCommandApp(t *testing.T) *App {
+	t.Helper()
+
+	app := &App{container: infra.NewContainer(config.NewMockConfig(nil))}
+	app.container.Logger = logging.NewMockLogger(logging.ERROR)
+
+	app.Command("backfill-users", func(c *Context) (any, error) {
+		if c.Param("fail") != "" {
+			return nil, errBackfill
+		}
+
+		return "backfilled users since " + c.Param("since"), nil
+	}, AddDescription("Backfills the users"), AddHelp("--since <date>"))
+
+	app.Command("backfill", func(*Context) (any, error) { panic("boom") })
+
+	return app
+}
+
+func TestApp_Command(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		args   []string
+		ran    bool
+		err    error
+		output string
+	}{
+		{desc: "command", args: []string{"run", "backfill-users", "--since", "2024-01-01"}, ran: true,
+			output: "backfilled users since 2024-01-01"},
+		{desc: "command failure", args: []string{"run", "backfill-users", "--fail"}, ran: true, err: errBackfill},
+		{desc: "command panic", args: []string{"run", "backfill"}, ran: true, err: errCommandPanic},
+		{desc: "unknown command", args: []string{"run", "cleanup"}, ran: true,
+			err: ErrCommandNotFound{Command: "cleanup"}, output: "backfill-users"},
+		{desc: "list", args: []string{"run"}, ran: true, output: "Backfills the users"},
+		{desc: "help", args: []string{"run", "backfill-users", "-h"}, ran: true, output: "--since <date>"},
+		{desc: "serve", args: []string{}},
+		{desc: "other arguments", args: []string{"-v"}},
+	}
+
+	for i, tc := range testCases {
+		app := newCommandApp(t)
+
+		var (
+			ran bool
+			err error
+		)
+
+		output := testutil.StdoutOutputForFunc(func() {
+			ran, err = app.runCommand(t.Context(), tc.args)
+		})
+
+		assert.Equal(t, tc.ran, ran, "TEST[%d], Failed.\n%s", i, tc.desc)
+		require.ErrorIs(t, err, tc.err, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Contains(t, output, tc.output, "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestApp_Command_NoCommands(t *testing.T) {
+	app := &App{container: infra.NewContainer(config.NewMockConfig(nil))}
+
+	ran, err := app.runCommand(t.Context(), []string{"run", "backfill-users"})
+
+	assert.False(t, ran, "the arguments of the applications without commands are not handled")
+	require.NoError(t, err)
+}
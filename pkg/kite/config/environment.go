@@ -0,0 +1,78 @@
+package config
+
+import "strings"
+
+// Environment is the environment an application runs in, read from APP_ENV. It selects the defaults, or presets, of
+// the configurations whose value usually differs between the development and the production of an application.
+type Environment string
+
+const (
+	// EnvDevelopment pretty prints the logs, reloads the templates on every render and responds with the stack trace
+	// of the panics.
+	EnvDevelopment Environment = "development"
+	// EnvStaging has the presets of EnvProduction, except that the error messages are not hidden, so that the failures
+	// can be diagnosed by the testers.
+	EnvStaging Environment = "staging"
+	// EnvProduction writes the logs as JSON, caches the templates, hides the messages of the internal errors and sets
+	// the security headers.
+	EnvProduction Environment = "production"
+)
+
+// presets are the defaults of the configurations in each environment.
+var presets = map[Environment]map[string]string{
+	EnvDevelopment: {
+		"HTTP_ERROR_MODE":          "verbose",
+		"LOG_FORMAT":               "text",
+		"SECURITY_HEADERS_ENABLED": "false",
+		"TEMPLATE_RELOAD":          "true",
+	},
+	EnvStaging: {
+		"LOG_FORMAT":               "json",
+		"SECURITY_HEADERS_ENABLED": "true",
+		"TEMPLATE_RELOAD":          "false",
+	},
+	EnvProduction: {
+		"HTTP_ERROR_MODE":          "strict",
+		"LOG_FORMAT":               "json",
+		"SECURITY_HEADERS_ENABLED": "true",
+		"TEMPLATE_RELOAD":          "false",
+	},
+}
+
+// ParseEnvironment returns the environment named value, ignoring case: "dev", "development" and "local" are
+// EnvDevelopment, "stage" and "staging" are EnvStaging, "prod" and "production" are EnvProduction. The other values,
+// e.g. "test", are returned as is and have no presets.
+func ParseEnvironment(value string) Environment {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "dev", "development", "local":
+		return EnvDevelopment
+	case "stage", "staging":
+		return EnvStaging
+	case "prod", "production":
+		return EnvProduction
+	default:
+		return Environment(value)
+	}
+}
+
+// GetEnvironment returns the environment of the application configured with APP_ENV.
+func GetEnvironment(c Config) Environment {
+	return ParseEnvironment(c.Get("APP_ENV"))
+}
+
+// Preset returns the default of the configuration key in the environment, and whether the environment has one.
+func (e Environment) Preset(key string) (string, bool) {
+	value, ok := presets[e][key]
+
+	return value, ok
+}
+
+// GetOrPreset returns the value of the configuration key, which overrides the preset of the environment of the
+// application, then the preset, or defaultValue when the environment has none.
+func GetOrPreset(c Config, key, defaultValue string) string {
+	if value, ok := GetEnvironment(c).Preset(key); ok {
+		defaultValue = value
+	}
+
+	return c.GetOrDefault(key, defaultValue)
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEnvironment(t *testing.T) {
+	testCases := []struct {
+		value string
+		env   Environment
+	}{
+		{value: "dev", env: EnvDevelopment},
+		{value: "Local", env: EnvDevelopment},
+		{value: "stage", env: EnvStaging},
+		{value: " PROD ", env: EnvProduction},
+		{value: "production", env: EnvProduction},
+		{value: "test", env: "test"},
+		{value: "", env: ""},
+	}
+
+	for i, tc := range testCases {
+		assert.Equal(t, tc.env, ParseEnvironment(tc.value), "TEST[%d], Failed.\n%s", i, tc.value)
+	}
+}
+
+func TestGetOrPreset(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		configs  map[string]string
+		expected string
+	}{
+		{desc: "preset of the environment", configs: map[string]string{"APP_ENV": "prod"}, expected: "json"},
+		{desc: "overridden preset", configs: map[string]string{"APP_ENV": "prod", "LOG_FORMAT": "text"},
+			expected: "text"},
+		{desc: "environment without presets", configs: map[string]string{"APP_ENV": "test"}, expected: "default"},
+		{desc: "no environment", configs: map[string]string{}, expected: "default"},
+	}
+
+	for i, tc := range testCases {
+		assert.Equal(t, tc.expected, GetOrPreset(NewMockConfig(tc.configs), "LOG_FORMAT", "default"),
+			"TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
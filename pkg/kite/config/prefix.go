@@ -0,0 +1,22 @@
+package config
+
+type prefixedConfig struct {
+	Config
+
+	prefix string
+}
+
+// WithPrefix returns a view of conf which prepends prefix to every key, e.g. Get("DB_HOST") of
+// WithPrefix(conf, "ANALYTICS_") reads ANALYTICS_DB_HOST. It is used to configure several instances
+// of the same datasource from a single configuration.
+func WithPrefix(conf Config, prefix string) Config {
+	return &prefixedConfig{Config: conf, prefix: prefix}
+}
+
+func (p *prefixedConfig) Get(key string) string {
+	return p.Config.Get(p.prefix + key)
+}
+
+func (p *prefixedConfig) GetOrDefault(key, defaultValue string) string {
+	return p.Config.GetOrDefault(p.prefix+key, defaultValue)
+}
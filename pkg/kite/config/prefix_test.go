@@ -0,0 +1,15 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WithPrefix(t *testing.T) {
+	cfg := WithPrefix(NewMockConfig(map[string]string{"DB_HOST": "orders", "ANALYTICS_DB_HOST": "analytics"}), "ANALYTICS_")
+
+	assert.Equal(t, "analytics", cfg.Get("DB_HOST"))
+	assert.Empty(t, cfg.Get("DB_NAME"))
+	assert.Equal(t, "3306", cfg.GetOrDefault("DB_PORT", "3306"))
+}
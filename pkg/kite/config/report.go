@@ -0,0 +1,250 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// MaskedValue replaces the values of the secrets in a Report.
+const MaskedValue = "[REDACTED]"
+
+var (
+	// reportedPrefixes are the prefixes of the environment variables read by Kite, which are reported along with the
+	// keys defined in the config files.
+	reportedPrefixes = []string{
+		"APP_", "CERT_FILE", "CMD_LOGS_FILE", "DB_", "GOOGLE_", "GOROUTINE_DUMP_DIR", "GRPC_", "HTTP_", "KAFKA_",
+		"KEY_FILE", "KITE_", "LOG_", "METRICS_", "MQTT_", "PUBSUB_", "REDIS_", "REMOTE_LOG_", "REQUEST_TIMEOUT",
+		"SECRETS_", "SECURITY_HEADERS_", "SHUTDOWN_", "SLOW_QUERY_", "SUPABASE_", "TEMPLATE_RELOAD", "TRACE",
+		"TRUSTED_PROXIES", "VAULT_",
+	}
+
+	// secretNames are the parts of the keys whose values are masked, compared ignoring case and underscores.
+	// Headers are masked as they usually carry credentials, e.g. TRACER_HEADERS.
+	secretNames = []string{
+		"password", "passwd", "secret", "token", "apikey", "authkey", "privatekey", "credential", "headers",
+	}
+
+	// featureToggles are the boolean configurations of Kite with their default value.
+	featureToggles = map[string]bool{
+		"GRPC_ENABLE_REFLECTION": false,
+		"HTTP_METHOD_OVERRIDE":   false,
+		"KITE_TELEMETRY":         true,
+		"LOG_CALLER":             true,
+		"LOG_DISABLE_PROBES":     false,
+		"LOG_REDACT":             true,
+		"METRICS_DEBUG_ENABLED":  false,
+		"REDIS_TLS_ENABLED":      false,
+	}
+
+	// listeningPorts are the ports of the servers of Kite with their default value.
+	listeningPorts = map[string]int{
+		"HTTP_PORT":    8000,
+		"GRPC_PORT":    9000,
+		"METRICS_PORT": 2121,
+	}
+)
+
+// Report is the effective configuration of an application, with its secrets masked. It is logged when the
+// application starts and printed by `kite config print`, to tell which configuration is actually running.
+type Report struct {
+	// Config holds the keys defined in the config files and the environment variables read by Kite.
+	Config map[string]string `json:"config"`
+	// Datasources lists the datasources, e.g. "sql", "redis" or "pubsub:kafka".
+	Datasources []string `json:"datasources"`
+	// Features holds the feature toggles of Kite, including the ones left to their default.
+	Features map[string]bool `json:"features"`
+	// Ports holds the ports of the servers, keyed by their configuration, e.g. HTTP_PORT.
+	Ports map[string]int `json:"ports"`
+}
+
+// NewReport builds the report of the configuration values. Its datasources are the ones configured in values, and
+// its ports the ones of every server.
+func NewReport(values map[string]string) *Report {
+	r := &Report{
+		Config:   make(map[string]string, len(values)),
+		Features: make(map[string]bool, len(featureToggles)),
+		Ports:    make(map[string]int, len(listeningPorts)),
+	}
+
+	for key, value := range values {
+		r.Config[key] = MaskValue(key, value)
+	}
+
+	for key, enabled := range featureToggles {
+		if v, err := strconv.ParseBool(values[key]); err == nil {
+			enabled = v
+		}
+
+		r.Features[key] = enabled
+	}
+
+	// the security headers are enabled by the presets of staging and production.
+	securityHeaders, _ := ParseEnvironment(values["APP_ENV"]).Preset("SECURITY_HEADERS_ENABLED")
+	r.Features["SECURITY_HEADERS_ENABLED"] = securityHeaders == "true"
+
+	if v, err := strconv.ParseBool(values["SECURITY_HEADERS_ENABLED"]); err == nil {
+		r.Features["SECURITY_HEADERS_ENABLED"] = v
+	}
+
+	for key, port := range listeningPorts {
+		if v, err := strconv.Atoi(values[key]); err == nil && v >= 0 {
+			port = v
+		}
+
+		r.Ports[key] = port
+	}
+
+	if values["DB_DIALECT"] != "" {
+		r.Datasources = append(r.Datasources, "sql")
+	}
+
+	if values["REDIS_HOST"] != "" {
+		r.Datasources = append(r.Datasources, "redis")
+	}
+
+	if backend := values["PUBSUB_BACKEND"]; backend != "" {
+		r.Datasources = append(r.Datasources, "pubsub:"+strings.ToLower(backend))
+	}
+
+	return r
+}
+
+// MaskValue returns the value of key as reported: the values of secrets, e.g. DB_PASSWORD or API_KEY, are replaced
+// by MaskedValue, and so are the passwords of URLs. Empty values are kept, as they tell that a secret is not set.
+func MaskValue(key, value string) string {
+	if value == "" {
+		return value
+	}
+
+	name := strings.ToLower(strings.ReplaceAll(key, "_", ""))
+
+	for _, secret := range secretNames {
+		if strings.Contains(name, secret) {
+			return MaskedValue
+		}
+	}
+
+	if u, err := url.Parse(value); err == nil && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			return strings.Replace(u.Redacted(), ":xxxxx@", ":"+MaskedValue+"@", 1)
+		}
+	}
+
+	return value
+}
+
+// PrettyPrint writes the report as sorted "KEY=value" lines, grouped in sections.
+func (r *Report) PrettyPrint(writer io.Writer) {
+	fmt.Fprintf(writer, "\u001B[38;5;8mconfiguration\u001B[0m\n")
+
+	for _, key := range sortedKeys(r.Config) {
+		fmt.Fprintf(writer, "  %s=%s\n", key, r.Config[key])
+	}
+
+	fmt.Fprintf(writer, "\u001B[38;5;8mdatasources\u001B[0m\n")
+
+	for _, ds := range r.Datasources {
+		fmt.Fprintf(writer, "  %s\n", ds)
+	}
+
+	fmt.Fprintf(writer, "\u001B[38;5;8mfeatures\u001B[0m\n")
+
+	for _, key := range sortedKeys(r.Features) {
+		fmt.Fprintf(writer, "  %s=%t\n", key, r.Features[key])
+	}
+
+	fmt.Fprintf(writer, "\u001B[38;5;8mports\u001B[0m\n")
+
+	for _, key := range sortedKeys(r.Ports) {
+		port := strconv.Itoa(r.Ports[key])
+		if r.Ports[key] == 0 {
+			port = "disabled"
+		}
+
+		fmt.Fprintf(writer, "  %s=%s\n", key, port)
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
+
+// Snapshot returns the effective configuration of an application whose config files are in folder, as loaded by
+// NewEnvFile, without modifying the environment: the keys of .env, overridden by the ones of .<APP_ENV>.env, or of
+// .local.env, and by the environment variables. The environment variables read by Kite are included even if no
+// config file defines them.
+func Snapshot(folder string) (map[string]string, error) {
+	values, err := readEnvFile(folder + defaultFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	overrideFile := folder + defaultOverrideFileName
+
+	env := os.Getenv("APP_ENV")
+	if env == "" {
+		env = values["APP_ENV"]
+	}
+
+	if env != "" {
+		overrideFile = fmt.Sprintf("%s/.%s.env", folder, env)
+	}
+
+	overrides, err := readEnvFile(overrideFile)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range overrides {
+		values[key] = value
+	}
+
+	for _, envVar := range os.Environ() {
+		key, value, found := strings.Cut(envVar, "=")
+		if !found {
+			continue
+		}
+
+		if _, defined := values[key]; defined || isReportedKey(key) {
+			values[key] = value
+		}
+	}
+
+	return values, nil
+}
+
+// readEnvFile reads the values of an env file, which may not exist.
+func readEnvFile(path string) (map[string]string, error) {
+	values, err := godotenv.Read(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return make(map[string]string), nil
+	}
+
+	return values, err
+}
+
+func isReportedKey(key string) bool {
+	for _, prefix := range reportedPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"context"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// secretTimeout bounds the time taken to fetch a secret referenced by a config.
+const secretTimeout = 10 * time.Second
+
+// secretReference matches the references to secrets in the values of the configs, e.g. ${secret:db_password}.
+var secretReference = regexp.MustCompile(`\$\{secret:([^}]+)\}`)
+
+// Secrets provides the secrets referenced in the values of the configs.
+type Secrets interface {
+	// Get returns the current value of the secret.
+	Get(ctx context.Context, name string) (string, error)
+	// Watch calls onChange with the new value of the secret each time it rotates, until ctx is done.
+	Watch(ctx context.Context, name string, onChange func(value string))
+}
+
+type secretLogger interface {
+	Errorf(format string, a ...any)
+	Infof(format string, a ...any)
+}
+
+// SecretConfig is a Config whose values may reference secrets as ${secret:name}, e.g. DB_PASSWORD=${secret:db_password}.
+// The references are resolved each time the value is read, which is when the datasources connect, so that they always
+// use the current value of the secrets. The secrets are watched for rotations until Close is called.
+type SecretConfig struct {
+	Config
+
+	secrets Secrets
+	logger  secretLogger
+
+	// watching is done once Close is called, which stops watching the secrets.
+	watching context.Context
+	stop     context.CancelFunc
+
+	mu sync.Mutex
+	// watched holds the config keys referencing each secret which is watched.
+	watched map[string]map[string]struct{}
+	// values holds the last value of each secret, used when it cannot be fetched.
+	values    map[string]string
+	listeners []func(key string)
+}
+
+// WithSecrets returns a view of conf which resolves the references to secrets with secrets.
+func WithSecrets(conf Config, secrets Secrets, logger secretLogger) *SecretConfig {
+	watching, stop := context.WithCancel(context.Background())
+
+	return &SecretConfig{
+		Config:   conf,
+		secrets:  secrets,
+		logger:   logger,
+		watching: watching,
+		stop:     stop,
+		watched:  make(map[string]map[string]struct{}),
+		values:   make(map[string]string),
+	}
+}
+
+func (s *SecretConfig) Get(key string) string {
+	return s.resolve(key, s.Config.Get(key))
+}
+
+func (s *SecretConfig) GetOrDefault(key, defaultValue string) string {
+	return s.resolve(key, s.Config.GetOrDefault(key, defaultValue))
+}
+
+// OnRotate registers fn to be called with the key of a config when a secret it references rotates, e.g. to
+// reconnect the datasource using it. Only the configs which have been read are known to reference a secret.
+func (s *SecretConfig) OnRotate(fn func(key string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.listeners = append(s.listeners, fn)
+}
+
+// Close stops watching the secrets for rotations.
+func (s *SecretConfig) Close() error {
+	s.stop()
+
+	return nil
+}
+
+// resolve replaces the references to secrets in value. A secret which cannot be fetched is replaced by its last
+// value, or by an empty value if it was never fetched.
+func (s *SecretConfig) resolve(key, value string) string {
+	if !secretReference.MatchString(value) {
+		return value
+	}
+
+	return secretReference.ReplaceAllStringFunc(value, func(reference string) string {
+		name := secretReference.FindStringSubmatch(reference)[1]
+
+		ctx, cancel := context.WithTimeout(context.Background(), secretTimeout)
+		defer cancel()
+
+		secret, err := s.secrets.Get(ctx, name)
+		if err != nil {
+			return s.lastValue(name, key, err)
+		}
+
+		s.mu.Lock()
+		s.values[name] = secret
+		s.mu.Unlock()
+
+		s.watch(name, key)
+
+		return secret
+	})
+}
+
+// lastValue logs the failure to fetch the secret referenced by key, and returns its last value, empty if it was
+// never fetched.
+func (s *SecretConfig) lastValue(name, key string, err error) string {
+	s.mu.Lock()
+	last, ok := s.values[name]
+	s.mu.Unlock()
+
+	if !ok {
+		s.logger.Errorf("could not resolve the secret %q of %s: %v", name, key, err)
+
+		return ""
+	}
+
+	s.logger.Errorf("could not resolve the secret %q of %s, using its last value: %v", name, key, err)
+
+	return last
+}
+
+// watch starts watching the secret the first time it is referenced, and records that key references it.
+func (s *SecretConfig) watch(name, key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys, ok := s.watched[name]
+	if !ok {
+		keys = make(map[string]struct{})
+		s.watched[name] = keys
+
+		go s.secrets.Watch(s.watching, name, func(value string) { s.rotated(name, value) })
+	}
+
+	keys[key] = struct{}{}
+}
+
+// rotated records the new value of the secret, and notifies the listeners of each config referencing it.
+func (s *SecretConfig) rotated(name, value string) {
+	s.mu.Lock()
+
+	s.values[name] = value
+
+	keys := make([]string, 0, len(s.watched[name]))
+	for key := range s.watched[name] {
+		keys = append(keys, key)
+	}
+
+	listeners := append([]func(string){}, s.listeners...)
+
+	s.mu.Unlock()
+
+	s.logger.Infof("secret %q rotated", name)
+
+	for _, key := range keys {
+		for _, fn := range listeners {
+			fn(key)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const awsService = "secretsmanager"
+
+// AWSConfig configures the AWS Secrets Manager provider.
+type AWSConfig struct {
+	// Region of the secrets, e.g. eu-west-1.
+	Region string
+	// AccessKeyID, SecretAccessKey and SessionToken are the credentials signing the requests. SessionToken is only
+	// set for temporary credentials.
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Endpoint overrides the endpoint of the region, e.g. for LocalStack.
+	Endpoint string
+	// Interval between the checks for rotated secrets.
+	Interval time.Duration
+	// Client sends the requests, http.DefaultClient by default.
+	Client *http.Client
+}
+
+// AWSProvider reads the secrets from AWS Secrets Manager.
+type AWSProvider struct {
+	poller
+
+	config AWSConfig
+	now    func() time.Time
+}
+
+// AWS returns a provider reading the secret "orders-db" from the secret string of the orders-db secret, and
+// "orders-db#password" from the password field of this string when it is a JSON object.
+func AWS(config AWSConfig) (*AWSProvider, error) {
+	if config.Region == "" || config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, fmt.Errorf("%w: AWS_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required",
+			errMissingConfig)
+	}
+
+	if config.Endpoint == "" {
+		config.Endpoint = fmt.Sprintf("https://%s.%s.amazonaws.com", awsService, config.Region)
+	}
+
+	if config.Interval <= 0 {
+		config.Interval = defaultRefreshInterval
+	}
+
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+
+	p := &AWSProvider{config: config, now: time.Now}
+	p.poller = poller{get: p.Get, interval: config.Interval}
+
+	return p, nil
+}
+
+func (p *AWSProvider) Get(ctx context.Context, name string) (string, error) {
+	id, field := splitField(name)
+
+	body, err := json.Marshal(map[string]string{"SecretId": id})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(p.config.Endpoint, "/")+"/",
+		bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	p.sign(req, body)
+
+	var secret struct {
+		SecretString string `json:"SecretString"`
+	}
+
+	if err = doJSON(p.config.Client, req, &secret); err != nil {
+		return "", fmt.Errorf("reading %q from aws secrets manager: %w", id, err)
+	}
+
+	return jsonField(secret.SecretString, field)
+}
+
+// sign adds the AWS Signature Version 4 of the request, see
+// https://docs.aws.amazon.com/IAM/latest/UserGuide/reference_sigv-create-signed-request.html.
+func (p *AWSProvider) sign(req *http.Request, body []byte) {
+	now := p.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	if p.config.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", p.config.SessionToken)
+	}
+
+	headers := []string{"content-type", "host", "x-amz-date"}
+	if p.config.SessionToken != "" {
+		headers = append(headers, "x-amz-security-token")
+	}
+
+	headers = append(headers, "x-amz-target")
+
+	var canonicalHeaders strings.Builder
+
+	for _, h := range headers {
+		value := req.Header.Get(h)
+		if h == "host" {
+			value = req.URL.Host
+		}
+
+		canonicalHeaders.WriteString(h + ":" + strings.TrimSpace(value) + "\n")
+	}
+
+	signedHeaders := strings.Join(headers, ";")
+	canonicalRequest := strings.Join([]string{
+		req.Method, "/", "", canonicalHeaders.String(), signedHeaders, hashHex(body),
+	}, "\n")
+
+	scope := strings.Join([]string{date, p.config.Region, awsService, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hashHex([]byte(canonicalRequest))}, "\n")
+
+	key := []byte("AWS4" + p.config.SecretAccessKey)
+	for _, part := range []string{date, p.config.Region, awsService, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		p.config.AccessKeyID, scope, signedHeaders, hex.EncodeToString(hmacSHA256(key, stringToSign))))
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
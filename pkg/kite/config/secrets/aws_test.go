@@ -0,0 +1,74 @@
+package secrets
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAWS_Get(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secretsmanager.GetSecretValue", r.Header.Get("X-Amz-Target"))
+		assert.Equal(t, "20261016T120000Z", r.Header.Get("X-Amz-Date"))
+		assert.Equal(t, "session", r.Header.Get("X-Amz-Security-Token"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"),
+			"AWS4-HMAC-SHA256 Credential=AKID/20261016/eu-west-1/secretsmanager/aws4_request, "+
+				"SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-amz-target, Signature="))
+
+		var input struct{ SecretId string }
+
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&input))
+
+		if input.SecretId != "orders-db" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"ResourceNotFoundException"}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"Name":"orders-db","SecretString":"{\"username\":\"orders\",\"password\":\"s3cr3t\"}"}`))
+	}))
+	defer server.Close()
+
+	provider, err := AWS(AWSConfig{Region: "eu-west-1", AccessKeyID: "AKID", SecretAccessKey: "key",
+		SessionToken: "session", Endpoint: server.URL})
+	require.NoError(t, err)
+
+	provider.now = func() time.Time { return time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC) }
+
+	value, err := provider.Get(t.Context(), "orders-db#password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", value)
+
+	value, err = provider.Get(t.Context(), "orders-db")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"username":"orders","password":"s3cr3t"}`, value)
+
+	_, err = provider.Get(t.Context(), "billing-db")
+	require.ErrorIs(t, err, errUnexpectedStatus)
+}
+
+func TestAWS_Sign(t *testing.T) {
+	provider, err := AWS(AWSConfig{Region: "us-east-1", AccessKeyID: "AKID", SecretAccessKey: "key"})
+	require.NoError(t, err)
+
+	provider.now = func() time.Time { return time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC) }
+
+	sign := func(body string) string {
+		req := httptest.NewRequest(http.MethodPost, "https://secretsmanager.us-east-1.amazonaws.com/", http.NoBody)
+		req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+		req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+		provider.sign(req, []byte(body))
+
+		return req.Header.Get("Authorization")
+	}
+
+	assert.Equal(t, sign(`{"SecretId":"a"}`), sign(`{"SecretId":"a"}`), "signatures must be deterministic")
+	assert.NotEqual(t, sign(`{"SecretId":"a"}`), sign(`{"SecretId":"b"}`), "signatures must cover the body")
+}
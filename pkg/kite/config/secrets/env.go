@@ -0,0 +1,67 @@
+package secrets
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// EnvProvider reads the secrets from the environment variables.
+type EnvProvider struct{}
+
+// Env returns a provider reading the secret db_password from the DB_PASSWORD environment variable, or
+// db_password if it is set.
+func Env() *EnvProvider {
+	return &EnvProvider{}
+}
+
+func (*EnvProvider) Get(_ context.Context, name string) (string, error) {
+	if value, ok := os.LookupEnv(name); ok {
+		return value, nil
+	}
+
+	if value, ok := os.LookupEnv(strings.ToUpper(name)); ok {
+		return value, nil
+	}
+
+	return "", errNotFound
+}
+
+// Watch returns immediately, the environment of a running process does not change.
+func (*EnvProvider) Watch(context.Context, string, func(string)) {}
+
+// FileProvider reads the secrets from the files of a directory, e.g. the secrets mounted by Kubernetes or Docker.
+type FileProvider struct {
+	poller
+
+	dir string
+}
+
+// Files returns a provider reading the secret db_password from the file dir/db_password, checked for rotation
+// every interval.
+func Files(dir string, interval time.Duration) *FileProvider {
+	p := &FileProvider{dir: dir}
+	p.poller = poller{get: p.Get, interval: interval}
+
+	return p
+}
+
+// Get returns the content of the file, without its trailing newline.
+func (p *FileProvider) Get(_ context.Context, name string) (string, error) {
+	if !filepath.IsLocal(name) {
+		return "", errInvalidSecretName
+	}
+
+	content, err := os.ReadFile(filepath.Join(p.dir, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", errNotFound
+		}
+
+		return "", err
+	}
+
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
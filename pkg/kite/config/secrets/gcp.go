@@ -0,0 +1,105 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	defaultGCPEndpoint = "https://secretmanager.googleapis.com"
+	gcpScope           = "https://www.googleapis.com/auth/cloud-platform"
+)
+
+// GCPConfig configures the Google Cloud Secret Manager provider.
+type GCPConfig struct {
+	// ProjectID of the secrets.
+	ProjectID string
+	// TokenSource authenticates the requests, the Application Default Credentials by default.
+	TokenSource oauth2.TokenSource
+	// Endpoint overrides the endpoint of Secret Manager.
+	Endpoint string
+	// Interval between the checks for rotated secrets.
+	Interval time.Duration
+}
+
+// GCPProvider reads the secrets from Google Cloud Secret Manager.
+type GCPProvider struct {
+	poller
+
+	config GCPConfig
+	client *http.Client
+}
+
+// GCP returns a provider reading the secret "orders-db" from the latest version of the orders-db secret,
+// "orders-db@3" from its version 3 and "orders-db#password" from the password field of the secret when it is a
+// JSON object.
+func GCP(ctx context.Context, config GCPConfig) (*GCPProvider, error) {
+	if config.ProjectID == "" {
+		return nil, fmt.Errorf("%w: GOOGLE_PROJECT_ID is required", errMissingConfig)
+	}
+
+	if config.TokenSource == nil {
+		credentials, err := google.FindDefaultCredentials(ctx, gcpScope)
+		if err != nil {
+			return nil, err
+		}
+
+		config.TokenSource = credentials.TokenSource
+	}
+
+	if config.Endpoint == "" {
+		config.Endpoint = defaultGCPEndpoint
+	}
+
+	if config.Interval <= 0 {
+		config.Interval = defaultRefreshInterval
+	}
+
+	p := &GCPProvider{config: config, client: oauth2.NewClient(ctx, config.TokenSource)}
+	p.poller = poller{get: p.Get, interval: config.Interval}
+
+	return p, nil
+}
+
+func (p *GCPProvider) Get(ctx context.Context, name string) (string, error) {
+	secret, field := splitField(name)
+
+	secret, version, ok := strings.Cut(secret, "@")
+	if !ok {
+		version = "latest"
+	}
+
+	endpoint := fmt.Sprintf("%s/v1/projects/%s/secrets/%s/versions/%s:access",
+		strings.TrimSuffix(p.config.Endpoint, "/"), url.PathEscape(p.config.ProjectID), url.PathEscape(secret),
+		url.PathEscape(version))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, http.NoBody)
+	if err != nil {
+		return "", err
+	}
+
+	var response struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+
+	if err = doJSON(p.client, req, &response); err != nil {
+		return "", fmt.Errorf("reading %q from gcp secret manager: %w", secret, err)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(response.Payload.Data)
+	if err != nil {
+		return "", err
+	}
+
+	return jsonField(string(data), field)
+}
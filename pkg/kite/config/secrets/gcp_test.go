@@ -0,0 +1,51 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestGCP_Get(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer t0k3n", r.Header.Get("Authorization"))
+
+		var data string
+
+		switch r.URL.Path {
+		case "/v1/projects/shop/secrets/orders-db/versions/latest:access":
+			data = `{"password":"s3cr3t"}`
+		case "/v1/projects/shop/secrets/orders-db/versions/3:access":
+			data = `{"password":"old"}`
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"name":"orders-db","payload":{"data":"` + base64.StdEncoding.EncodeToString([]byte(data)) + `"}}`))
+	}))
+	defer server.Close()
+
+	provider, err := GCP(t.Context(), GCPConfig{
+		ProjectID:   "shop",
+		TokenSource: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "t0k3n"}),
+		Endpoint:    server.URL,
+	})
+	require.NoError(t, err)
+
+	value, err := provider.Get(t.Context(), "orders-db#password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", value)
+
+	value, err = provider.Get(t.Context(), "orders-db@3#password")
+	require.NoError(t, err)
+	assert.Equal(t, "old", value)
+
+	_, err = provider.Get(t.Context(), "billing-db")
+	require.ErrorIs(t, err, errNotFound)
+}
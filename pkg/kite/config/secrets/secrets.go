@@ -0,0 +1,156 @@
+// Package secrets provides the secrets referenced in the configs as ${secret:name}, read from the environment,
+// mounted files, HashiCorp Vault, AWS Secrets Manager or Google Cloud Secret Manager.
+//
+// The provider is selected with SECRETS_PROVIDER, see New. Rotated secrets are detected by polling them every
+// SECRETS_REFRESH_INTERVAL, except for the environment which does not change.
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sllt/kite/pkg/kite/config"
+)
+
+const (
+	defaultRefreshInterval = time.Minute
+	defaultSecretsDir      = "/run/secrets"
+	maxSecretSize          = 1 << 20
+)
+
+var (
+	errNotFound          = errors.New("secret not found")
+	errFieldNotFound     = errors.New("field not found in the secret")
+	errUnknownProvider   = errors.New("unknown secrets provider")
+	errMissingConfig     = errors.New("missing config")
+	errUnexpectedStatus  = errors.New("unexpected status")
+	errInvalidSecretName = errors.New("invalid secret name")
+)
+
+// New returns the provider selected by SECRETS_PROVIDER: env, the default, file, vault, aws or gcp.
+func New(conf config.Config) (config.Secrets, error) {
+	interval, err := time.ParseDuration(conf.GetOrDefault("SECRETS_REFRESH_INTERVAL", defaultRefreshInterval.String()))
+	if err != nil || interval <= 0 {
+		interval = defaultRefreshInterval
+	}
+
+	switch provider := strings.ToLower(conf.GetOrDefault("SECRETS_PROVIDER", "env")); provider {
+	case "env":
+		return Env(), nil
+	case "file":
+		return Files(conf.GetOrDefault("SECRETS_DIR", defaultSecretsDir), interval), nil
+	case "vault":
+		return Vault(VaultConfig{
+			Address:  conf.Get("VAULT_ADDR"),
+			Token:    conf.Get("VAULT_TOKEN"),
+			Mount:    conf.Get("VAULT_MOUNT"),
+			Interval: interval,
+		})
+	case "aws":
+		return AWS(AWSConfig{
+			Region:          conf.Get("AWS_REGION"),
+			AccessKeyID:     conf.Get("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: conf.Get("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    conf.Get("AWS_SESSION_TOKEN"),
+			Interval:        interval,
+		})
+	case "gcp":
+		return GCP(context.Background(), GCPConfig{ProjectID: conf.Get("GOOGLE_PROJECT_ID"), Interval: interval})
+	default:
+		return nil, fmt.Errorf("%w: %q", errUnknownProvider, provider)
+	}
+}
+
+// poller implements Watch for the providers which are polled for rotated secrets.
+type poller struct {
+	get      func(ctx context.Context, name string) (string, error)
+	interval time.Duration
+}
+
+// Watch fetches the secret every interval and calls onChange when its value differs from the previous one. Failed
+// fetches are ignored, so that a provider which is briefly unavailable does not rotate the secret.
+func (p poller) Watch(ctx context.Context, name string, onChange func(value string)) {
+	last, _ := p.get(ctx, name)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			value, err := p.get(ctx, name)
+			if err != nil || value == last {
+				continue
+			}
+
+			last = value
+
+			onChange(value)
+		}
+	}
+}
+
+// splitField splits the name of a secret holding several values, e.g. "orders-db#password", into the name of the
+// secret and the field.
+func splitField(name string) (secret, field string) {
+	secret, field, _ = strings.Cut(name, "#")
+
+	return secret, field
+}
+
+// jsonField returns the field of the secret, which is a JSON object, or the secret itself when field is empty.
+func jsonField(secret, field string) (string, error) {
+	if field == "" {
+		return secret, nil
+	}
+
+	var fields map[string]any
+
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+		return "", fmt.Errorf("%w: %q is not a JSON object", errFieldNotFound, field)
+	}
+
+	return stringField(fields, field)
+}
+
+func stringField(fields map[string]any, field string) (string, error) {
+	switch value := fields[field].(type) {
+	case nil:
+		return "", fmt.Errorf("%w: %q", errFieldNotFound, field)
+	case string:
+		return value, nil
+	default:
+		return fmt.Sprint(value), nil
+	}
+}
+
+// doJSON sends req and decodes the JSON response into v.
+func doJSON(client *http.Client, req *http.Request, v any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSecretSize))
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return errNotFound
+	case resp.StatusCode >= http.StatusBadRequest:
+		return fmt.Errorf("%w %d: %s", errUnexpectedStatus, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return json.Unmarshal(body, v)
+}
@@ -0,0 +1,115 @@
+package secrets
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sllt/kite/pkg/kite/config"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		configs  map[string]string
+		provider any
+		err      error
+	}{
+		{configs: map[string]string{}, provider: &EnvProvider{}},
+		{configs: map[string]string{"SECRETS_PROVIDER": "FILE"}, provider: &FileProvider{}},
+		{configs: map[string]string{"SECRETS_PROVIDER": "vault", "VAULT_ADDR": "http://vault:8200", "VAULT_TOKEN": "t"},
+			provider: &VaultProvider{}},
+		{configs: map[string]string{"SECRETS_PROVIDER": "vault"}, err: errMissingConfig},
+		{configs: map[string]string{"SECRETS_PROVIDER": "aws", "AWS_REGION": "eu-west-1", "AWS_ACCESS_KEY_ID": "id",
+			"AWS_SECRET_ACCESS_KEY": "key"}, provider: &AWSProvider{}},
+		{configs: map[string]string{"SECRETS_PROVIDER": "aws"}, err: errMissingConfig},
+		{configs: map[string]string{"SECRETS_PROVIDER": "gcp"}, err: errMissingConfig},
+		{configs: map[string]string{"SECRETS_PROVIDER": "keychain"}, err: errUnknownProvider},
+	}
+
+	for i, tc := range testCases {
+		provider, err := New(config.NewMockConfig(tc.configs))
+
+		require.ErrorIs(t, err, tc.err, "TEST[%d], Failed.\n", i)
+
+		if tc.err == nil {
+			assert.IsType(t, tc.provider, provider, "TEST[%d], Failed.\n", i)
+		}
+	}
+}
+
+func TestEnv(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "s3cr3t")
+	t.Setenv("redis_password", "r3d1s")
+
+	value, err := Env().Get(t.Context(), "db_password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", value)
+
+	value, err = Env().Get(t.Context(), "redis_password")
+	require.NoError(t, err)
+	assert.Equal(t, "r3d1s", value)
+
+	_, err = Env().Get(t.Context(), "missing_secret")
+	assert.ErrorIs(t, err, errNotFound)
+}
+
+func TestFiles(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db_password"), []byte("s3cr3t\n"), 0o600))
+
+	provider := Files(dir, 10*time.Millisecond)
+
+	value, err := provider.Get(t.Context(), "db_password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", value)
+
+	_, err = provider.Get(t.Context(), "missing")
+	require.ErrorIs(t, err, errNotFound)
+
+	_, err = provider.Get(t.Context(), "../etc/passwd")
+	require.ErrorIs(t, err, errInvalidSecretName)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	rotated := make(chan string, 1)
+
+	go provider.Watch(ctx, "db_password", func(value string) { rotated <- value })
+
+	time.Sleep(30 * time.Millisecond)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db_password"), []byte("n3w\n"), 0o600))
+
+	select {
+	case value = <-rotated:
+		assert.Equal(t, "n3w", value)
+	case <-time.After(time.Second):
+		t.Fatal("the rotation was not detected")
+	}
+}
+
+func TestJSONField(t *testing.T) {
+	testCases := []struct {
+		secret string
+		field  string
+		value  string
+		err    error
+	}{
+		{secret: "s3cr3t", value: "s3cr3t"},
+		{secret: `{"username":"orders","password":"s3cr3t","port":5432}`, field: "password", value: "s3cr3t"},
+		{secret: `{"username":"orders","password":"s3cr3t","port":5432}`, field: "port", value: "5432"},
+		{secret: `{"username":"orders"}`, field: "password", err: errFieldNotFound},
+		{secret: "s3cr3t", field: "password", err: errFieldNotFound},
+	}
+
+	for i, tc := range testCases {
+		value, err := jsonField(tc.secret, tc.field)
+
+		require.ErrorIs(t, err, tc.err, "TEST[%d], Failed.\n", i)
+		assert.Equal(t, tc.value, value, "TEST[%d], Failed.\n", i)
+	}
+}
@@ -0,0 +1,86 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const defaultVaultMount = "secret"
+
+// VaultConfig configures the HashiCorp Vault provider.
+type VaultConfig struct {
+	// Address of the Vault server, e.g. https://vault.internal:8200.
+	Address string
+	// Token authenticating the requests.
+	Token string
+	// Mount is the path of the KV version 2 secrets engine, "secret" by default.
+	Mount string
+	// Interval between the checks for rotated secrets.
+	Interval time.Duration
+	// Client sends the requests, http.DefaultClient by default.
+	Client *http.Client
+}
+
+// VaultProvider reads the secrets from the KV version 2 secrets engine of HashiCorp Vault.
+type VaultProvider struct {
+	poller
+
+	config VaultConfig
+}
+
+// Vault returns a provider reading the secret "orders/db#password" from the password field of the secret at the
+// path orders/db. The field defaults to "value".
+func Vault(config VaultConfig) (*VaultProvider, error) {
+	if config.Address == "" || config.Token == "" {
+		return nil, fmt.Errorf("%w: VAULT_ADDR and VAULT_TOKEN are required", errMissingConfig)
+	}
+
+	if config.Mount == "" {
+		config.Mount = defaultVaultMount
+	}
+
+	if config.Interval <= 0 {
+		config.Interval = defaultRefreshInterval
+	}
+
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+
+	p := &VaultProvider{config: config}
+	p.poller = poller{get: p.Get, interval: config.Interval}
+
+	return p, nil
+}
+
+func (p *VaultProvider) Get(ctx context.Context, name string) (string, error) {
+	path, field := splitField(name)
+	if field == "" {
+		field = "value"
+	}
+
+	url := fmt.Sprintf("%s/v1/%s/data/%s", strings.TrimSuffix(p.config.Address, "/"),
+		strings.Trim(p.config.Mount, "/"), strings.TrimPrefix(path, "/"))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("X-Vault-Token", p.config.Token)
+
+	var secret struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+
+	if err = doJSON(p.config.Client, req, &secret); err != nil {
+		return "", fmt.Errorf("reading %q from vault: %w", path, err)
+	}
+
+	return stringField(secret.Data.Data, field)
+}
@@ -0,0 +1,50 @@
+package secrets
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVault_Get(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "t0k3n" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/kv/data/orders/db":
+			_, _ = w.Write([]byte(`{"data":{"data":{"password":"s3cr3t","value":"default"},"metadata":{"version":2}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	provider, err := Vault(VaultConfig{Address: server.URL, Token: "t0k3n", Mount: "kv"})
+	require.NoError(t, err)
+
+	value, err := provider.Get(t.Context(), "orders/db#password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", value)
+
+	value, err = provider.Get(t.Context(), "orders/db")
+	require.NoError(t, err)
+	assert.Equal(t, "default", value)
+
+	_, err = provider.Get(t.Context(), "orders/db#user")
+	require.ErrorIs(t, err, errFieldNotFound)
+
+	_, err = provider.Get(t.Context(), "billing/db")
+	require.ErrorIs(t, err, errNotFound)
+
+	provider, err = Vault(VaultConfig{Address: server.URL, Token: "wrong", Mount: "kv"})
+	require.NoError(t, err)
+
+	_, err = provider.Get(t.Context(), "orders/db")
+	require.ErrorIs(t, err, errUnexpectedStatus)
+}
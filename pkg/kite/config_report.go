@@ -0,0 +1,58 @@
+package kite
+
+import (
+	"os"
+	"strings"
+
+	"github.com/sllt/kite/pkg/kite/config"
+)
+
+// configReport returns the report of the effective configuration of the application, with its secrets masked, the
+// datasources of its container and the ports of the servers it starts. The ports of the servers which are not
+// started are 0.
+func (a *App) configReport() *config.Report {
+	var location string
+
+	if _, err := os.Stat(configLocation); err == nil {
+		location = configLocation
+	}
+
+	values, err := config.Snapshot(location)
+	if err != nil {
+		a.Logger().Errorf("failed to read the configuration for the report: %v", err)
+
+		values = make(map[string]string)
+	}
+
+	report := config.NewReport(values)
+
+	report.Datasources = a.container.Datasources()
+
+	for i, name := range report.Datasources {
+		if backend := a.Config.Get("PUBSUB_BACKEND"); name == "pubsub" && backend != "" {
+			report.Datasources[i] = "pubsub:" + strings.ToLower(backend)
+		}
+	}
+
+	report.Ports["HTTP_PORT"] = 0
+	report.Ports["GRPC_PORT"] = 0
+	report.Ports["METRICS_PORT"] = 0
+
+	if a.httpRegistered && a.httpServer != nil {
+		report.Ports["HTTP_PORT"] = a.httpServer.port
+	}
+
+	if a.grpcRegistered && a.grpcServer != nil {
+		report.Ports["GRPC_PORT"] = a.grpcServer.port
+	}
+
+	if a.metricServer != nil {
+		report.Ports["METRICS_PORT"] = a.metricServer.port
+	}
+
+	if a.adminServer != nil {
+		report.Ports["HTTP_ADMIN_PORT"] = a.adminServer.port
+	}
+
+	return report
+}
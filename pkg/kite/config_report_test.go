@@ -0,0 +1,35 @@
+package kite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sllt/kite/pkg/kite/config"
+	"github.com/sllt/kite/pkg/kite/infra"
+)
+
+func TestApp_ConfigReport(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "hunter2")
+	t.Setenv("PUBSUB_BACKEND", "kafka")
+
+	c, _ := infra.NewMockContainer(t)
+
+	app := &App{
+		Config:         config.NewMockConfig(map[string]string{"PUBSUB_BACKEND": "kafka"}),
+		container:      c,
+		httpServer:     &httpServer{port: 8080},
+		httpRegistered: true,
+		grpcServer:     &grpcServer{port: 9090},
+		metricServer:   newMetricServer(2122),
+	}
+
+	report := app.configReport()
+
+	assert.Equal(t, config.MaskedValue, report.Config["DB_PASSWORD"])
+	assert.Contains(t, report.Datasources, "sql")
+	assert.Contains(t, report.Datasources, "pubsub:kafka")
+	assert.NotContains(t, report.Datasources, "pubsub")
+	assert.Equal(t, map[string]int{"HTTP_PORT": 8080, "GRPC_PORT": 0, "METRICS_PORT": 2122}, report.Ports,
+		"the ports of the servers which are not started must be 0")
+}
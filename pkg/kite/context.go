@@ -3,6 +3,7 @@ package kite
 import (
 	"context"
 	"fmt"
+	"net/http"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/gorilla/websocket"
@@ -10,8 +11,9 @@ import (
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/sllt/kite/pkg/kite/cmd/terminal"
-	"github.com/sllt/kite/pkg/kite/infra"
 	"github.com/sllt/kite/pkg/kite/http/middleware"
+	"github.com/sllt/kite/pkg/kite/i18n"
+	"github.com/sllt/kite/pkg/kite/infra"
 	"github.com/sllt/kite/pkg/kite/logging"
 )
 
@@ -150,6 +152,90 @@ func (a *authInfo) GetAPIKey() string {
 	return a.apiKey
 }
 
+// Session returns the session of the request, see middleware.Sessions. It returns nil when sessions are not
+// enabled, in which case the methods of the session do nothing.
+//
+//	ctx.Session().Set("user_id", user.ID)
+//	ctx.Session().Rotate() // on login, to prevent session fixation
+func (c *Context) Session() *middleware.Session {
+	return middleware.SessionFromContext(c.Request.Context())
+}
+
+// T returns the message of key in the locale of the request, formatted with args as in fmt.Sprintf, see
+// middleware.Locale. It returns the formatted key when the locale middleware is not enabled.
+//
+//	ctx.T("order.created", order.ID)
+func (c *Context) T(key string, args ...any) string {
+	return i18n.FromContext(c.Context).T(key, args...)
+}
+
+// Locale returns the locale resolved for the request by middleware.Locale, or an empty string.
+func (c *Context) Locale() string {
+	return i18n.LocaleFromContext(c.Context)
+}
+
+// AddLogField adds a custom field to the access log of the HTTP request, e.g. the id of the order it created.
+func (c *Context) AddLogField(key string, value any) {
+	middleware.AddAccessLogField(c.Context, key, value)
+}
+
+// ClientIP returns the IP of the client of an HTTP request. The X-Forwarded-For, X-Real-IP and Forwarded headers are
+// only used when the request was received from one of the TRUSTED_PROXIES, otherwise it is the IP of the peer.
+// It is empty for the requests which are not HTTP requests, e.g. pubsub messages.
+func (c *Context) ClientIP() string {
+	if r, ok := c.Request.(interface{ ClientIP() string }); ok {
+		return r.ClientIP()
+	}
+
+	return ""
+}
+
+// responseHeaderSetter is implemented by the responders of HTTP requests.
+type responseHeaderSetter interface {
+	SetHeader(key, value string)
+	AddHeader(key, value string)
+	SetCookie(cookie *http.Cookie)
+}
+
+// SetHeader sets the header of the response, replacing its values. The headers are sent with any response returned
+// by the handler, including the errors, files, templates and redirects. It does nothing outside HTTP requests.
+func (c *Context) SetHeader(key, value string) {
+	if r, ok := c.responder.(responseHeaderSetter); ok {
+		r.SetHeader(key, value)
+	}
+}
+
+// AddHeader adds a value to the header of the response, keeping the values already set, e.g. by the middlewares.
+func (c *Context) AddHeader(key, value string) {
+	if r, ok := c.responder.(responseHeaderSetter); ok {
+		r.AddHeader(key, value)
+	}
+}
+
+// SetCookie adds the cookie to the response. The cookie is always HttpOnly, and its path and SameSite default to "/"
+// and Lax: a cookie which scripts must read can be sent with AddHeader("Set-Cookie", cookie.String()).
+//
+//	ctx.SetCookie(&http.Cookie{Name: "theme", Value: "dark", MaxAge: 3600, Secure: true})
+func (c *Context) SetCookie(cookie *http.Cookie) {
+	r, ok := c.responder.(responseHeaderSetter)
+	if !ok || cookie == nil {
+		return
+	}
+
+	withDefaults := *cookie
+	withDefaults.HttpOnly = true
+
+	if withDefaults.Path == "" {
+		withDefaults.Path = "/"
+	}
+
+	if withDefaults.SameSite == http.SameSiteDefaultMode {
+		withDefaults.SameSite = http.SameSiteLaxMode
+	}
+
+	r.SetCookie(&withDefaults)
+}
+
 // func (c *Context) reset(w Responder, r Request) {
 //	c.Request = r
 //	c.responder = w
@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"sync"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -19,9 +20,11 @@ import (
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 
 	"github.com/sllt/kite/pkg/kite/config"
-	"github.com/sllt/kite/pkg/kite/infra"
+	"github.com/sllt/kite/pkg/kite/datasource/pubsub"
 	kiteHTTP "github.com/sllt/kite/pkg/kite/http"
 	"github.com/sllt/kite/pkg/kite/http/middleware"
+	"github.com/sllt/kite/pkg/kite/i18n"
+	"github.com/sllt/kite/pkg/kite/infra"
 	"github.com/sllt/kite/pkg/kite/logging"
 	"github.com/sllt/kite/pkg/kite/testutil"
 	"github.com/sllt/kite/pkg/kite/version"
@@ -362,3 +365,117 @@ func TestContext_GetCorrelationID(t *testing.T) {
 		assert.Equal(t, expected, correlationID, "Expected empty TraceID when no span present")
 	})
 }
+
+func TestContext_Session(t *testing.T) {
+	mockContainer, _ := infra.NewMockContainer(t)
+
+	var sess *middleware.Session
+
+	h := middleware.Sessions(middleware.SessionConfig{})(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		c := &Context{Context: r.Context(), Request: kiteHTTP.NewRequest(r), Container: mockContainer}
+
+		c.Session().Set("user", "alice")
+		sess = c.Session()
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	require.NotNil(t, sess)
+	assert.Equal(t, "alice", sess.Get("user"))
+	assert.NotEmpty(t, sess.ID())
+	assert.Len(t, rec.Result().Cookies(), 1)
+}
+
+func TestContext_Session_Disabled(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	c := &Context{Context: req.Context(), Request: kiteHTTP.NewRequest(req)}
+
+	assert.Nil(t, c.Session())
+}
+
+func TestContext_T(t *testing.T) {
+	bundle, err := i18n.NewBundle(fstest.MapFS{
+		"locales/en.json": {Data: []byte(`{"order": {"created": "Order %s created"}}`)},
+		"locales/fr.json": {Data: []byte(`{"order": {"created": "Commande %s créée"}}`)},
+	}, "locales", "en")
+	require.NoError(t, err)
+
+	var message, locale string
+
+	h := middleware.Locale(middleware.LocaleConfig{Bundle: bundle})(http.HandlerFunc(func(_ http.ResponseWriter,
+		r *http.Request) {
+		c := &Context{Context: r.Context(), Request: kiteHTTP.NewRequest(r)}
+
+		message, locale = c.T("order.created", "o-1"), c.Locale()
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	req.Header.Set("Accept-Language", "fr-FR,fr;q=0.9")
+
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "Commande o-1 créée", message)
+	assert.Equal(t, "fr", locale)
+}
+
+func TestContext_T_Disabled(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	c := &Context{Context: req.Context(), Request: kiteHTTP.NewRequest(req)}
+
+	assert.Equal(t, "order.created", c.T("order.created"))
+	assert.Empty(t, c.Locale())
+}
+
+func TestContext_ClientIP(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	req.RemoteAddr = "203.0.113.7:4000"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+
+	c := &Context{Context: req.Context(), Request: kiteHTTP.NewRequest(req)}
+
+	assert.Equal(t, "203.0.113.7", c.ClientIP(), "forwarding headers are ignored without trusted proxies")
+
+	req = req.WithContext(kiteHTTP.WithClientIP(req.Context(), "198.51.100.1"))
+	c = &Context{Context: req.Context(), Request: kiteHTTP.NewRequest(req)}
+
+	assert.Equal(t, "198.51.100.1", c.ClientIP())
+
+	c = &Context{Context: t.Context(), Request: pubsub.NewMessage(t.Context())}
+
+	assert.Empty(t, c.ClientIP())
+}
+
+func TestContext_ResponseHeaders(t *testing.T) {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+
+	c := &Context{Context: req.Context(), Request: kiteHTTP.NewRequest(req),
+		responder: kiteHTTP.NewResponder(w, http.MethodGet)}
+
+	w.Header().Set("Vary", "Origin")
+
+	c.SetHeader("X-Request-Source", "api")
+	c.AddHeader("Vary", "Accept-Language")
+	c.SetCookie(&http.Cookie{Name: "theme", Value: "dark", MaxAge: 3600})
+	c.SetCookie(&http.Cookie{Name: "csrf", Value: "t", Path: "/app", SameSite: http.SameSiteStrictMode})
+
+	c.responder.Respond("ok", nil)
+
+	assert.Equal(t, "api", w.Header().Get("X-Request-Source"))
+	assert.Equal(t, []string{"Origin", "Accept-Language"}, w.Header().Values("Vary"))
+	assert.Equal(t, []string{
+		"theme=dark; Path=/; Max-Age=3600; HttpOnly; SameSite=Lax",
+		"csrf=t; Path=/app; HttpOnly; SameSite=Strict",
+	}, w.Header().Values("Set-Cookie"))
+}
+
+func TestContext_ResponseHeaders_NotHTTP(t *testing.T) {
+	c := &Context{Context: t.Context(), Request: pubsub.NewMessage(t.Context())}
+
+	assert.NotPanics(t, func() {
+		c.SetHeader("X-Custom", "value")
+		c.AddHeader("X-Custom", "value")
+		c.SetCookie(&http.Cookie{Name: "theme", Value: "dark"})
+	})
+}
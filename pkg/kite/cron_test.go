@@ -205,6 +205,8 @@ func TestCronTab_AddJob(t *testing.T) {
 	}
 
 	c := NewCron(nil)
+	// the job must not run once the test is over, as the cron has no container.
+	defer c.ticker.Stop()
 
 	for _, tc := range testCases {
 		err := c.AddJob(tc.schedule, "test-job", fn)
@@ -5,7 +5,6 @@ import (
 	"context"
 	"database/sql"
 	"database/sql/driver"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -248,7 +247,7 @@ func Test_CreateHandler(t *testing.T) {
 			mockErr:       nil,
 			expectedQuery: "",
 			expectedResp:  nil,
-			expectedErr:   &json.UnmarshalTypeError{Value: "string", Offset: 9, Struct: "userEntity", Field: "id"},
+			expectedErr:   kiteHTTP.ErrorInvalidJSON{Field: "id", Expected: "number", Offset: 9, Reason: "expected number, got string"},
 		},
 	}
 
@@ -503,7 +502,7 @@ func Test_UpdateHandler(t *testing.T) {
 				reqBody:      []byte(`{"id":"2"}`),
 				mockErr:      nil,
 				expectedResp: nil,
-				expectedErr:  &json.UnmarshalTypeError{Value: "string", Offset: 9, Struct: "user", Field: "id"},
+				expectedErr:  kiteHTTP.ErrorInvalidJSON{Field: "id", Expected: "number", Offset: 9, Reason: "expected number, got string"},
 			},
 			{
 				desc:         "error From DB",
@@ -24,6 +24,12 @@ type Config struct {
 	Port     int
 	Username string
 	Password string
+	// PreparedStatementCacheSize is the number of prepared statements kept by the session, 1000 by default. Writers
+	// executing more distinct statements should raise it, so that their statements are not prepared again.
+	PreparedStatementCacheSize int
+	// MaxBatchStatements limits the number of statements of the batches, unlimited by default. It is also the size of
+	// the batches of WriteBatchWithCtx.
+	MaxBatchStatements int
 }
 
 type cassandra struct {
@@ -38,6 +44,8 @@ type Client struct {
 
 	cassandra *cassandra
 
+	statements *statementCache
+
 	logger  Logger
 	metrics Metrics
 	tracer  trace.Tracer
@@ -56,7 +64,7 @@ var errStatusDown = errors.New("status down")
 func New(conf Config) *Client {
 	cass := &cassandra{clusterConfig: newClusterConfig(&conf)}
 
-	return &Client{config: &conf, cassandra: cass}
+	return &Client{config: &conf, cassandra: cass, statements: newStatementCache(conf.PreparedStatementCacheSize)}
 }
 
 // Connect establishes a connection to Cassandra and registers metrics using the provided configuration when the client was Created.
@@ -73,11 +81,27 @@ func (c *Client) Connect() {
 	cassandraBucktes := []float64{.05, .075, .1, .125, .15, .2, .3, .5, .75, 1, 2, 3, 4, 5, 7.5, 10}
 	c.metrics.NewHistogram("app_cassandra_stats", "Response time of CASSANDRA queries in microseconds.", cassandraBucktes...)
 
+	if r, ok := c.metrics.(counterRegisterer); ok {
+		r.NewCounter(metricPreparedStatements, "Number of CASSANDRA statements reusing a prepared statement or prepared.")
+	}
+
 	c.logger.Logf("connected to '%s' keyspace at host '%s' and port '%d'", c.config.Keyspace, c.config.Hosts, c.config.Port)
 
 	c.cassandra.session = sess
 }
 
+// Close closes the session of the Cassandra client, e.g. before connecting it again.
+func (c *Client) Close() error {
+	if c.cassandra.session == nil {
+		return nil
+	}
+
+	c.cassandra.session.close()
+	c.cassandra.session = nil
+
+	return nil
+}
+
 // UseLogger sets the logger for the Cassandra client which asserts the Logger interface.
 func (c *Client) UseLogger(logger any) {
 	if l, ok := logger.(Logger); ok {
@@ -120,10 +144,12 @@ func (c *Client) NewBatch(name string, batchType int) error {
 // QueryWithCtx executes a CQL query in the Cassandra database and returns the result.
 //
 //nolint:exhaustive // We just want to take care of slice and struct in this case.
-func (c *Client) QueryWithCtx(ctx context.Context, dest any, stmt string, values ...any) error {
+func (c *Client) QueryWithCtx(ctx context.Context, dest any, stmt string, values ...any) (err error) {
 	span := c.addTrace(ctx, "query", stmt)
 
-	defer c.sendOperationStats(&QueryLog{Operation: "QueryWithCtx", Query: stmt, Keyspace: c.config.Keyspace}, time.Now(), "query", span)
+	c.recordPrepare(ctx, span, stmt)
+
+	defer c.sendOperationStats(&QueryLog{Operation: "QueryWithCtx", Query: stmt, Keyspace: c.config.Keyspace}, time.Now(), "query", span, &err)
 
 	rvo := reflect.ValueOf(dest)
 	if rvo.Kind() != reflect.Ptr {
@@ -169,10 +195,12 @@ func (c *Client) QueryWithCtx(ctx context.Context, dest any, stmt string, values
 	return nil
 }
 
-func (c *Client) ExecWithCtx(ctx context.Context, stmt string, values ...any) error {
+func (c *Client) ExecWithCtx(ctx context.Context, stmt string, values ...any) (err error) {
 	span := c.addTrace(ctx, "exec", stmt)
 
-	defer c.sendOperationStats(&QueryLog{Operation: "ExecWithCtx", Query: stmt, Keyspace: c.config.Keyspace}, time.Now(), "exec", span)
+	c.recordPrepare(ctx, span, stmt)
+
+	defer c.sendOperationStats(&QueryLog{Operation: "ExecWithCtx", Query: stmt, Keyspace: c.config.Keyspace}, time.Now(), "exec", span, &err)
 
 	return c.cassandra.session.query(stmt, values...).exec()
 }
@@ -180,15 +208,12 @@ func (c *Client) ExecWithCtx(ctx context.Context, stmt string, values ...any) er
 // ExecCASWithCtx executes a CQL query in the Cassandra database and returns the true if the query is applied.
 //
 //nolint:exhaustive // We just want to take care of slice and struct in this case.
-func (c *Client) ExecCASWithCtx(ctx context.Context, dest any, stmt string, values ...any) (bool, error) {
-	var (
-		applied bool
-		err     error
-	)
-
+func (c *Client) ExecCASWithCtx(ctx context.Context, dest any, stmt string, values ...any) (applied bool, err error) {
 	span := c.addTrace(ctx, "exec-cas", stmt)
 
-	defer c.sendOperationStats(&QueryLog{Operation: "ExecCASWithCtx", Query: stmt, Keyspace: c.config.Keyspace}, time.Now(), "exec-cas", span)
+	c.recordPrepare(ctx, span, stmt)
+
+	defer c.sendOperationStats(&QueryLog{Operation: "ExecCASWithCtx", Query: stmt, Keyspace: c.config.Keyspace}, time.Now(), "exec-cas", span, &err)
 
 	rvo := reflect.ValueOf(dest)
 	if rvo.Kind() != reflect.Ptr {
@@ -331,7 +356,9 @@ func (*Client) getColumnsFromColumnsInfo(columns []gocql.ColumnInfo) []string {
 	return cols
 }
 
-func (c *Client) sendOperationStats(ql *QueryLog, startTime time.Time, method string, span trace.Span) {
+// sendOperationStats logs the operation and records its metrics, counting it as failed when the error err points to,
+// the one returned by the operation, is not nil.
+func (c *Client) sendOperationStats(ql *QueryLog, startTime time.Time, method string, span trace.Span, err *error) {
 	duration := time.Since(startTime).Microseconds()
 
 	ql.Duration = duration
@@ -347,6 +374,18 @@ func (c *Client) sendOperationStats(ql *QueryLog, startTime time.Time, method st
 	c.metrics.RecordHistogram(context.Background(), "app_cassandra_stats", float64(duration), "hostname", c.config.Hosts,
 		"keyspace", c.config.Keyspace)
 
+	if m, ok := c.metrics.(counterMetrics); ok {
+		labels := []string{"datasource", "cassandra", "database", c.config.Keyspace, "operation", ql.Operation}
+
+		m.IncrementCounter(context.Background(), metricOperations, labels...)
+
+		if err != nil && *err != nil {
+			m.IncrementCounter(context.Background(), metricErrors, labels...)
+		}
+
+		c.metrics.RecordHistogram(context.Background(), metricDuration, float64(duration)/1000, labels...)
+	}
+
 	c.cassandra.query = nil
 }
 
@@ -3,8 +3,14 @@ package cassandra
 import (
 	"context"
 	"time"
+
+	"github.com/gocql/gocql"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+// defaultBatchStatements is the size of the batches of WriteBatchWithCtx when neither the call nor the config set it.
+const defaultBatchStatements = 50
+
 func (c *Client) BatchQuery(name, stmt string, values ...any) error {
 	return c.BatchQueryWithCtx(context.Background(), name, stmt, values...)
 }
@@ -17,33 +23,39 @@ func (c *Client) ExecuteBatchCAS(name string, dest ...any) (bool, error) {
 	return c.ExecuteBatchCASWithCtx(context.Background(), name, dest)
 }
 
-func (c *Client) BatchQueryWithCtx(ctx context.Context, name, stmt string, values ...any) error {
+func (c *Client) BatchQueryWithCtx(ctx context.Context, name, stmt string, values ...any) (err error) {
 	span := c.addTrace(ctx, "batch-query", stmt)
 
 	defer c.sendOperationStats(&QueryLog{
 		Operation: "BatchQueryWithCtx",
 		Query:     stmt,
 		Keyspace:  c.config.Keyspace,
-	}, time.Now(), "batch-query", span)
+	}, time.Now(), "batch-query", span, &err)
 
 	b, ok := c.cassandra.batches[name]
 	if !ok {
 		return errBatchNotInitialized
 	}
 
+	if c.config.MaxBatchStatements > 0 && b.size() >= c.config.MaxBatchStatements {
+		return errBatchFull{limit: c.config.MaxBatchStatements}
+	}
+
+	c.recordPrepare(ctx, span, stmt)
+
 	b.Query(stmt, values...)
 
 	return nil
 }
 
-func (c *Client) ExecuteBatchWithCtx(ctx context.Context, name string) error {
+func (c *Client) ExecuteBatchWithCtx(ctx context.Context, name string) (err error) {
 	span := c.addTrace(ctx, "execute-batch", "batch")
 
 	defer c.sendOperationStats(&QueryLog{
 		Operation: "ExecuteBatchWithCtx",
 		Query:     "batch",
 		Keyspace:  c.config.Keyspace,
-	}, time.Now(), "execute-batch", span)
+	}, time.Now(), "execute-batch", span, &err)
 
 	b, ok := c.cassandra.batches[name]
 	if !ok {
@@ -53,14 +65,14 @@ func (c *Client) ExecuteBatchWithCtx(ctx context.Context, name string) error {
 	return c.cassandra.session.executeBatch(b)
 }
 
-func (c *Client) ExecuteBatchCASWithCtx(ctx context.Context, name string, dest ...any) (bool, error) {
+func (c *Client) ExecuteBatchCASWithCtx(ctx context.Context, name string, dest ...any) (applied bool, err error) {
 	span := c.addTrace(ctx, "execute-batch-cas", "batch")
 
 	defer c.sendOperationStats(&QueryLog{
 		Operation: "ExecuteBatchCASWithCtx",
 		Query:     "batch",
 		Keyspace:  c.config.Keyspace,
-	}, time.Now(), "execute-batch-cas", span)
+	}, time.Now(), "execute-batch-cas", span, &err)
 
 	b, ok := c.cassandra.batches[name]
 	if !ok {
@@ -69,3 +81,57 @@ func (c *Client) ExecuteBatchCASWithCtx(ctx context.Context, name string, dest .
 
 	return c.cassandra.session.executeBatchCAS(b, dest...)
 }
+
+// WriteBatchWithCtx executes the statement once for each of the rows of values, in batches of the batch type holding at
+// most size statements, or MaxBatchStatements of the config when size is not positive. The batches are executed in
+// order and the first failure is returned, so that only the rows of the failed batch and the following ones are not
+// written; each LoggedBatch is atomic, but not the whole write.
+func (c *Client) WriteBatchWithCtx(ctx context.Context, batchType int, stmt string, rows [][]any, size int) error {
+	switch batchType {
+	case LoggedBatch, UnloggedBatch, CounterBatch:
+	default:
+		return errUnsupportedBatchType
+	}
+
+	if size <= 0 {
+		size = c.config.MaxBatchStatements
+	}
+
+	if size <= 0 {
+		size = defaultBatchStatements
+	}
+
+	for start := 0; start < len(rows); start += size {
+		chunk := rows[start:min(start+size, len(rows))]
+
+		if err := c.writeBatch(ctx, batchType, stmt, chunk); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Client) writeBatch(ctx context.Context, batchType int, stmt string, rows [][]any) (err error) {
+	span := c.addTrace(ctx, "write-batch", stmt)
+
+	if span != nil {
+		span.SetAttributes(attribute.Int("cassandra.batch.size", len(rows)))
+	}
+
+	defer c.sendOperationStats(&QueryLog{
+		Operation: "WriteBatchWithCtx",
+		Query:     stmt,
+		Keyspace:  c.config.Keyspace,
+	}, time.Now(), "write-batch", span, &err)
+
+	c.recordPrepare(ctx, span, stmt)
+
+	b := c.cassandra.session.newBatch(gocql.BatchType(batchType))
+
+	for _, values := range rows {
+		b.Query(stmt, values...)
+	}
+
+	return c.cassandra.session.executeBatch(b)
+}
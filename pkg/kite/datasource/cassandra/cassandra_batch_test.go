@@ -5,6 +5,7 @@ import (
 
 	"github.com/gocql/gocql"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
 
@@ -136,3 +137,83 @@ func Test_ExecuteBatchCAS(t *testing.T) {
 		assert.Equalf(t, applied, tc.expErr == nil, "TEST[%d], Failed.\n%s", i, tc.desc)
 	}
 }
+
+func Test_BatchQueryLimit(t *testing.T) {
+	client, mockDeps := initTest(t)
+
+	client.config.MaxBatchStatements = 2
+
+	const stmt = "INSERT INTO users (id, name) VALUES(?, ?)"
+
+	mockDeps.mockBatch.EXPECT().size().Return(1)
+	mockDeps.mockBatch.EXPECT().Query(stmt, 1, "Test")
+
+	err := client.BatchQuery(mockBatchName, stmt, 1, "Test")
+	require.NoError(t, err)
+
+	mockDeps.mockBatch.EXPECT().size().Return(2)
+
+	err = client.BatchQuery(mockBatchName, stmt, 2, "Test")
+	require.EqualError(t, err, "batch already holds the maximum of 2 statements")
+}
+
+func Test_WriteBatchWithCtx(t *testing.T) {
+	const stmt = "INSERT INTO users (id, name) VALUES(?, ?)"
+
+	rows := [][]any{{1, "a"}, {2, "b"}, {3, "c"}, {4, "d"}, {5, "e"}}
+
+	testCases := []struct {
+		desc       string
+		size       int
+		configSize int
+		batches    []int
+	}{
+		{"size of the call", 2, 0, []int{2, 2, 1}},
+		{"size of the config", 0, 3, []int{3, 2}},
+		{"default size", 0, 0, []int{5}},
+	}
+
+	for i, tc := range testCases {
+		client, mockDeps := initTest(t)
+		client.config.MaxBatchStatements = tc.configSize
+
+		for _, n := range tc.batches {
+			mockDeps.mockSession.EXPECT().newBatch(gocql.UnloggedBatch).Return(mockDeps.mockBatch)
+			mockDeps.mockBatch.EXPECT().Query(stmt, gomock.Any()).Times(n)
+			mockDeps.mockSession.EXPECT().executeBatch(mockDeps.mockBatch).Return(nil)
+		}
+
+		err := client.WriteBatchWithCtx(t.Context(), UnloggedBatch, stmt, rows, tc.size)
+
+		assert.NoErrorf(t, err, "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func Test_WriteBatchWithCtxErrors(t *testing.T) {
+	client, mockDeps := initTest(t)
+
+	const stmt = "INSERT INTO users (id, name) VALUES(?, ?)"
+
+	rows := [][]any{{1, "a"}, {2, "b"}, {3, "c"}}
+
+	err := client.WriteBatchWithCtx(t.Context(), 5, stmt, rows, 1)
+	require.ErrorIs(t, err, errUnsupportedBatchType)
+
+	// the write stops at the first failed batch.
+	mockDeps.mockSession.EXPECT().newBatch(gocql.LoggedBatch).Return(mockDeps.mockBatch).Times(2)
+	mockDeps.mockBatch.EXPECT().Query(stmt, gomock.Any()).Times(2)
+	mockDeps.mockSession.EXPECT().executeBatch(mockDeps.mockBatch).Return(nil)
+	mockDeps.mockSession.EXPECT().executeBatch(mockDeps.mockBatch).Return(errMock)
+
+	err = client.WriteBatchWithCtx(t.Context(), LoggedBatch, stmt, rows, 1)
+	require.ErrorIs(t, err, errMock)
+}
+
+func Test_cassandraBatch_size(t *testing.T) {
+	c := &cassandraBatch{batch: &gocql.Batch{}}
+
+	c.Query("test query")
+	c.Query("test query")
+
+	assert.Equal(t, 2, c.size())
+}
@@ -3,6 +3,7 @@ package cassandra
 import (
 	"context"
 	"errors"
+	"slices"
 	"testing"
 
 	"github.com/gocql/gocql"
@@ -202,6 +203,50 @@ func Test_Exec(t *testing.T) {
 	}
 }
 
+// counters records the counters incremented by a Client, as the kite metrics manager which implements counterMetrics.
+type counters struct {
+	*MockMetrics
+
+	incremented []string
+}
+
+func (c *counters) IncrementCounter(_ context.Context, name string, _ ...string) {
+	c.incremented = append(c.incremented, name)
+}
+
+func Test_Exec_DatasourceMetrics(t *testing.T) {
+	const query = "INSERT INTO users (id, name) VALUES(1, 'Test')"
+
+	client, mockDeps := initTest(t)
+
+	ctrl := gomock.NewController(t)
+	metrics := &counters{MockMetrics: NewMockMetrics(ctrl)}
+	metrics.EXPECT().RecordHistogram(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	client.UseMetrics(metrics)
+
+	testCases := []struct {
+		desc   string
+		err    error
+		failed bool
+	}{
+		{"success case", nil, false},
+		{"failure case", errMock, true},
+	}
+
+	for i, tc := range testCases {
+		metrics.incremented = nil
+
+		mockDeps.mockSession.EXPECT().query(query, nil).Return(mockDeps.mockQuery).Times(1)
+		mockDeps.mockQuery.EXPECT().exec().Return(tc.err).Times(1)
+
+		err := client.Exec(query)
+
+		assert.Equalf(t, tc.err, err, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Containsf(t, metrics.incremented, metricOperations, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equalf(t, tc.failed, slices.Contains(metrics.incremented, metricErrors), "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
 func Test_ExecCAS(t *testing.T) {
 	const query = "INSERT INTO users (id, name) VALUES(1, 'Test') IF NOT EXISTS"
 
@@ -342,6 +387,16 @@ func Test_HealthCheck(t *testing.T) {
 	}
 }
 
+func Test_Close(t *testing.T) {
+	client, mockDeps := initTest(t)
+
+	mockDeps.mockSession.EXPECT().close().Times(1)
+
+	require.NoError(t, client.Close())
+	assert.Nil(t, client.cassandra.session)
+	require.NoError(t, client.Close(), "closing a client which is not connected")
+}
+
 func Test_CreateSession_Error(t *testing.T) {
 	c := newClusterConfig(&Config{})
 
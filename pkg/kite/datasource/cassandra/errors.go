@@ -27,3 +27,11 @@ type errUnexpectedSlice struct {
 func (d errUnexpectedSlice) Error() string {
 	return fmt.Sprintf("a slice of %v was not expected.", d.target)
 }
+
+type errBatchFull struct {
+	limit int
+}
+
+func (d errBatchFull) Error() string {
+	return fmt.Sprintf("batch already holds the maximum of %d statements", d.limit)
+}
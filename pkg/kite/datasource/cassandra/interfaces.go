@@ -20,6 +20,7 @@ type session interface {
 	newBatch(batchtype gocql.BatchType) batch
 	executeBatch(batch batch) error
 	executeBatchCAS(b batch, dest ...any) (bool, error)
+	close()
 }
 
 // query defines methods for interacting with a Cassandra query.
@@ -33,6 +34,7 @@ type query interface {
 // batch defines methods for interacting with a Cassandra batch.
 type batch interface {
 	Query(stmt string, args ...any)
+	size() int
 	getBatch() *gocql.Batch
 }
 
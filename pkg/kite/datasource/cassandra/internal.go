@@ -76,6 +76,10 @@ func newClusterConfig(config *Config) clusterConfig {
 	c.clusterConfig.Port = config.Port
 	c.clusterConfig.Authenticator = gocql.PasswordAuthenticator{Username: config.Username, Password: config.Password}
 
+	if config.PreparedStatementCacheSize > 0 {
+		c.clusterConfig.MaxPreparedStmts = config.PreparedStatementCacheSize
+	}
+
 	return &c
 }
 
@@ -128,6 +132,12 @@ func (c *cassandraSession) executeBatchCAS(b batch, dest ...any) (bool, error) {
 	return applied, err
 }
 
+// close closes the Cassandra session.
+// This method wraps the `Close` method of the underlying `session` object.
+func (c *cassandraSession) close() {
+	c.session.Close()
+}
+
 // cassandraBatch implements batch interface.
 type cassandraBatch struct {
 	batch *gocql.Batch
@@ -139,6 +149,12 @@ func (c *cassandraBatch) Query(stmt string, args ...any) {
 	c.batch.Query(stmt, args...)
 }
 
+// size returns the number of statements of the batch.
+// This method wraps the `Size` method of underlying `batch` object.
+func (c *cassandraBatch) size() int {
+	return c.batch.Size()
+}
+
 // getBatch returns the underlying `gocql.Batch`.
 func (c *cassandraBatch) getBatch() *gocql.Batch {
 	return c.batch
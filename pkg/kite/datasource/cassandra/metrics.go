@@ -7,3 +7,23 @@ type Metrics interface {
 
 	RecordHistogram(ctx context.Context, name string, value float64, labels ...string)
 }
+
+// Standard datasource metrics of kite, see the datasource package of kite. They are recorded when the metrics
+// also implement counterMetrics, as the kite metrics manager does.
+const (
+	metricOperations = "app_datasource_operations_total"
+	metricErrors     = "app_datasource_errors_total"
+	metricDuration   = "app_datasource_duration"
+)
+
+type counterMetrics interface {
+	IncrementCounter(ctx context.Context, name string, labels ...string)
+}
+
+// metricPreparedStatements counts the executed statements which reused a prepared statement, with the "hit" result,
+// or were prepared, with the "miss" result. It is registered when the metrics also implement counterRegisterer.
+const metricPreparedStatements = "app_cassandra_prepared_statements_total"
+
+type counterRegisterer interface {
+	NewCounter(name, desc string)
+}
@@ -76,6 +76,18 @@ func (m *Mocksession) EXPECT() *MocksessionMockRecorder {
 	return m.recorder
 }
 
+// close mocks base method.
+func (m *Mocksession) close() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "close")
+}
+
+// close indicates an expected call of close.
+func (mr *MocksessionMockRecorder) close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "close", reflect.TypeOf((*Mocksession)(nil).close))
+}
+
 // executeBatch mocks base method.
 func (m *Mocksession) executeBatch(batch batch) error {
 	m.ctrl.T.Helper()
@@ -282,6 +294,20 @@ func (mr *MockbatchMockRecorder) getBatch() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "getBatch", reflect.TypeOf((*Mockbatch)(nil).getBatch))
 }
 
+// size mocks base method.
+func (m *Mockbatch) size() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "size")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// size indicates an expected call of size.
+func (mr *MockbatchMockRecorder) size() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "size", reflect.TypeOf((*Mockbatch)(nil).size))
+}
+
 // Mockiterator is a mock of iterator interface.
 type Mockiterator struct {
 	ctrl     *gomock.Controller
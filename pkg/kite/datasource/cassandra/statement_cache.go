@@ -0,0 +1,105 @@
+package cassandra
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"unicode"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// defaultPreparedStatementCacheSize is the number of prepared statements kept by gocql by default.
+const defaultPreparedStatementCacheSize = 1000
+
+// statementCache tracks the statements prepared by the session, keyed by query. gocql prepares the SELECT, INSERT,
+// UPDATE, DELETE and BATCH statements on their first execution and keeps them in a LRU cache of the same size, so that
+// the statements seen by the cache are executed without being prepared again.
+type statementCache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	items map[string]*list.Element
+}
+
+func newStatementCache(size int) *statementCache {
+	if size <= 0 {
+		size = defaultPreparedStatementCacheSize
+	}
+
+	return &statementCache{size: size, order: list.New(), items: make(map[string]*list.Element)}
+}
+
+// seen records the statement as prepared and reports whether it already was, evicting the least recently used
+// statement when the cache is full.
+func (s *statementCache) seen(stmt string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.items[stmt]; ok {
+		s.order.MoveToFront(e)
+
+		return true
+	}
+
+	s.items[stmt] = s.order.PushFront(stmt)
+
+	if s.order.Len() > s.size {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.items, oldest.Value.(string))
+	}
+
+	return false
+}
+
+// recordPrepare records whether the statement reuses a prepared statement, as the "cassandra.prepared" attribute of
+// the span and in the app_cassandra_prepared_statements_total metric. The statements which are not prepared, e.g. the
+// schema changes, are not recorded.
+func (c *Client) recordPrepare(ctx context.Context, span trace.Span, stmt string) {
+	if c.statements == nil || !shouldPrepare(stmt) {
+		return
+	}
+
+	result := "miss"
+	hit := c.statements.seen(stmt)
+
+	if hit {
+		result = "hit"
+	}
+
+	if span != nil {
+		span.SetAttributes(attribute.Bool("cassandra.prepared", hit))
+	}
+
+	if m, ok := c.metrics.(counterMetrics); ok {
+		m.IncrementCounter(ctx, metricPreparedStatements, "keyspace", c.config.Keyspace, "result", result)
+	}
+}
+
+// shouldPrepare reports whether gocql prepares the statement before executing it.
+func shouldPrepare(stmt string) bool {
+	stmt = strings.TrimLeftFunc(strings.TrimRightFunc(stmt, func(r rune) bool {
+		return unicode.IsSpace(r) || r == ';'
+	}), unicode.IsSpace)
+
+	var stmtType string
+	if n := strings.IndexFunc(stmt, unicode.IsSpace); n >= 0 {
+		stmtType = strings.ToLower(stmt[:n])
+	}
+
+	if stmtType == "begin" {
+		if n := strings.LastIndexFunc(stmt, unicode.IsSpace); n >= 0 {
+			stmtType = strings.ToLower(stmt[n+1:])
+		}
+	}
+
+	switch stmtType {
+	case "select", "insert", "update", "delete", "batch":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,83 @@
+package cassandra
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type counterRecorder struct {
+	Metrics
+
+	counts map[string]int
+}
+
+func (c *counterRecorder) IncrementCounter(_ context.Context, name string, labels ...string) {
+	if name != metricPreparedStatements {
+		return
+	}
+
+	c.counts[labels[len(labels)-1]]++
+}
+
+func (*counterRecorder) RecordHistogram(context.Context, string, float64, ...string) {}
+
+func Test_statementCache(t *testing.T) {
+	cache := newStatementCache(2)
+
+	assert.False(t, cache.seen("SELECT a FROM t"))
+	assert.True(t, cache.seen("SELECT a FROM t"))
+	assert.False(t, cache.seen("SELECT b FROM t"))
+
+	// the least recently used statement is evicted.
+	assert.False(t, cache.seen("SELECT c FROM t"))
+	assert.True(t, cache.seen("SELECT b FROM t"))
+	assert.False(t, cache.seen("SELECT a FROM t"))
+}
+
+func Test_newStatementCacheDefaultSize(t *testing.T) {
+	assert.Equal(t, defaultPreparedStatementCacheSize, newStatementCache(0).size)
+	assert.Equal(t, 10, newStatementCache(10).size)
+}
+
+func Test_shouldPrepare(t *testing.T) {
+	testCases := []struct {
+		stmt     string
+		expected bool
+	}{
+		{"SELECT * FROM users", true},
+		{"  insert INTO users (id) VALUES(?);", true},
+		{"UPDATE users SET name=? WHERE id=?", true},
+		{"DELETE FROM users WHERE id=?", true},
+		{"BEGIN BATCH INSERT INTO users (id) VALUES(1) APPLY BATCH", true},
+		{"BEGIN UNLOGGED BATCH", true},
+		{"CREATE TABLE users (id int PRIMARY KEY)", false},
+		{"TRUNCATE users", false},
+		{"SELECT", false},
+	}
+
+	for i, tc := range testCases {
+		assert.Equalf(t, tc.expected, shouldPrepare(tc.stmt), "TEST[%d], Failed.\n%s", i, tc.stmt)
+	}
+}
+
+func Test_recordPrepare(t *testing.T) {
+	client, mockDeps := initTest(t)
+
+	recorder := &counterRecorder{Metrics: client.metrics, counts: make(map[string]int)}
+	client.metrics = recorder
+
+	const stmt = "UPDATE users SET name=? WHERE id=?"
+
+	mockDeps.mockSession.EXPECT().query(stmt, "John", 1).Return(mockDeps.mockQuery).Times(2)
+	mockDeps.mockSession.EXPECT().query("TRUNCATE users").Return(mockDeps.mockQuery)
+	mockDeps.mockQuery.EXPECT().exec().Return(nil).Times(3)
+
+	require.NoError(t, client.ExecWithCtx(t.Context(), stmt, "John", 1))
+	require.NoError(t, client.ExecWithCtx(t.Context(), stmt, "John", 1))
+	require.NoError(t, client.ExecWithCtx(t.Context(), "TRUNCATE users"))
+
+	assert.Equal(t, map[string]int{"miss": 1, "hit": 1}, recorder.counts)
+}
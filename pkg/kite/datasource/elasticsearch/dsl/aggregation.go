@@ -0,0 +1,111 @@
+package dsl
+
+// Aggregation is an aggregation of a search.
+type Aggregation interface {
+	// Source returns the JSON object of the aggregation.
+	Source() map[string]any
+}
+
+// BucketAggregation is an aggregation grouping the documents in buckets, which can hold sub-aggregations.
+type BucketAggregation struct {
+	kind string
+	body map[string]any
+	subs map[string]Aggregation
+}
+
+// TermsAgg groups the documents by the values of field, the 10 most frequent by default, see Size.
+func TermsAgg(field string) *BucketAggregation {
+	return newBucketAggregation("terms", map[string]any{"field": field})
+}
+
+// DateHistogram groups the documents by the calendar interval of the date field, e.g. "day" or "month".
+func DateHistogram(field, interval string) *BucketAggregation {
+	return newBucketAggregation("date_histogram", map[string]any{"field": field, "calendar_interval": interval})
+}
+
+// FilterAgg groups the documents matching the query in a single bucket.
+func FilterAgg(query Query) *BucketAggregation {
+	return &BucketAggregation{kind: "filter", body: query.Source()}
+}
+
+func newBucketAggregation(kind string, body map[string]any) *BucketAggregation {
+	return &BucketAggregation{kind: kind, body: body}
+}
+
+// Size sets the number of buckets of a terms aggregation.
+func (a *BucketAggregation) Size(size int) *BucketAggregation {
+	return a.Set("size", size)
+}
+
+// Set sets a parameter of the aggregation without a helper, e.g. "min_doc_count" or "time_zone".
+func (a *BucketAggregation) Set(key string, value any) *BucketAggregation {
+	a.body[key] = value
+
+	return a
+}
+
+// Sub adds an aggregation computed for each bucket, e.g. the average price of each category.
+func (a *BucketAggregation) Sub(name string, agg Aggregation) *BucketAggregation {
+	if a.subs == nil {
+		a.subs = make(map[string]Aggregation)
+	}
+
+	a.subs[name] = agg
+
+	return a
+}
+
+// Source implements the Aggregation interface.
+func (a *BucketAggregation) Source() map[string]any {
+	source := map[string]any{a.kind: a.body}
+
+	if len(a.subs) > 0 {
+		source["aggs"] = aggregationsSource(a.subs)
+	}
+
+	return source
+}
+
+type metricAggregation struct {
+	kind  string
+	field string
+}
+
+func (a metricAggregation) Source() map[string]any {
+	return map[string]any{a.kind: map[string]any{"field": a.field}}
+}
+
+// Avg computes the average of the numeric field.
+func Avg(field string) Aggregation {
+	return metricAggregation{kind: "avg", field: field}
+}
+
+// Sum computes the sum of the numeric field.
+func Sum(field string) Aggregation {
+	return metricAggregation{kind: "sum", field: field}
+}
+
+// Min computes the minimum of the field.
+func Min(field string) Aggregation {
+	return metricAggregation{kind: "min", field: field}
+}
+
+// Max computes the maximum of the field.
+func Max(field string) Aggregation {
+	return metricAggregation{kind: "max", field: field}
+}
+
+// Cardinality approximates the number of distinct values of the field.
+func Cardinality(field string) Aggregation {
+	return metricAggregation{kind: "cardinality", field: field}
+}
+
+func aggregationsSource(aggs map[string]Aggregation) map[string]any {
+	source := make(map[string]any, len(aggs))
+
+	for name, agg := range aggs {
+		source[name] = agg.Source()
+	}
+
+	return source
+}
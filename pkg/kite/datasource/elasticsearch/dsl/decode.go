@@ -0,0 +1,143 @@
+package dsl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	errInvalidDestination = errors.New("destination must be a non-nil pointer to a slice")
+	errInvalidResponse    = errors.New("invalid search response")
+	errAggregationMissing = errors.New("aggregation not found in the search response")
+)
+
+/*
+DecodeHits decodes the sources of the hits of a search result into dest, a pointer to a slice of structs or of pointers
+to structs, using their json tags. The _id and the _score of the hits are added to the sources unless they have a field
+of the same name, so that they are decoded into the fields tagged json:"_id" and json:"_score":
+
+	type Article struct {
+		ID    string  `json:"_id"`
+		Score float64 `json:"_score"`
+		Title string  `json:"title"`
+	}
+
+	var articles []Article
+	err := dsl.DecodeHits(result, &articles)
+
+A result without hits decodes into an empty slice.
+*/
+func DecodeHits(result map[string]any, dest any) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return errInvalidDestination
+	}
+
+	hits, err := hitsOf(result)
+	if err != nil {
+		return err
+	}
+
+	sources := make([]map[string]any, 0, len(hits))
+
+	for i, h := range hits {
+		hit, ok := h.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%w: hit %d is not an object", errInvalidResponse, i)
+		}
+
+		source, _ := hit["_source"].(map[string]any)
+
+		merged := make(map[string]any, len(source)+2)
+		for k, v := range source {
+			merged[k] = v
+		}
+
+		for _, meta := range []string{"_id", "_score"} {
+			if _, ok := merged[meta]; !ok && hit[meta] != nil {
+				merged[meta] = hit[meta]
+			}
+		}
+
+		sources = append(sources, merged)
+	}
+
+	return remarshal(sources, dest)
+}
+
+// Total returns the number of documents matching the search of the result, which is a lower bound when the search
+// does not track the total hits beyond 10,000 documents.
+func Total(result map[string]any) (int64, error) {
+	outer, ok := result["hits"].(map[string]any)
+	if !ok {
+		return 0, fmt.Errorf("%w: missing hits", errInvalidResponse)
+	}
+
+	switch total := outer["total"].(type) {
+	case map[string]any:
+		value, ok := total["value"].(float64)
+		if !ok {
+			return 0, fmt.Errorf("%w: invalid total", errInvalidResponse)
+		}
+
+		return int64(value), nil
+	case float64:
+		return int64(total), nil
+	default:
+		return 0, fmt.Errorf("%w: invalid total", errInvalidResponse)
+	}
+}
+
+/*
+DecodeAggregation decodes the result of the aggregation name of a search result into dest, using its json tags, e.g.
+the buckets of a terms aggregation:
+
+	var authors struct {
+		Buckets []struct {
+			Key      string `json:"key"`
+			DocCount int64  `json:"doc_count"`
+		} `json:"buckets"`
+	}
+
+	err := dsl.DecodeAggregation(result, "authors", &authors)
+*/
+func DecodeAggregation(result map[string]any, name string, dest any) error {
+	aggs, _ := result["aggregations"].(map[string]any)
+
+	agg, ok := aggs[name]
+	if !ok {
+		return fmt.Errorf("%w: %q", errAggregationMissing, name)
+	}
+
+	return remarshal(agg, dest)
+}
+
+func hitsOf(result map[string]any) ([]any, error) {
+	outer, ok := result["hits"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: missing hits", errInvalidResponse)
+	}
+
+	if outer["hits"] == nil {
+		return nil, nil
+	}
+
+	hits, ok := outer["hits"].([]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: hits is not an array", errInvalidResponse)
+	}
+
+	return hits, nil
+}
+
+// remarshal decodes the JSON value v, as decoded into a map by the datasource, into dest.
+func remarshal(v, dest any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, dest)
+}
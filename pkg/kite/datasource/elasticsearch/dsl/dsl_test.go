@@ -0,0 +1,230 @@
+package dsl
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueries_Source(t *testing.T) {
+	var unset *RangeQuery
+
+	tests := []struct {
+		desc     string
+		query    Query
+		expected string
+	}{
+		{"match all", MatchAll(), `{"match_all":{}}`},
+		{"term", Term("status", "published"), `{"term":{"status":"published"}}`},
+		{"terms", Terms("tags", "go", "search"), `{"terms":{"tags":["go","search"]}}`},
+		{"terms without values", Terms("tags"), `{"terms":{"tags":[]}}`},
+		{"match", Match("title", "kite"), `{"match":{"title":"kite"}}`},
+		{"match phrase", MatchPhrase("title", "query dsl"), `{"match_phrase":{"title":"query dsl"}}`},
+		{"multi match", MultiMatch("kite", "title^2", "body"),
+			`{"multi_match":{"fields":["title^2","body"],"query":"kite"}}`},
+		{"exists", Exists("author"), `{"exists":{"field":"author"}}`},
+		{"range", Range("age").Gte(18).Lt(65), `{"range":{"age":{"gte":18,"lt":65}}}`},
+		{"date range", Range("at").Gt("now-1d/d").Lte("now").Format("strict_date_optional_time").TimeZone("+01:00"),
+			`{"range":{"at":{"format":"strict_date_optional_time","gt":"now-1d/d","lte":"now","time_zone":"+01:00"}}}`},
+		{"raw", Raw{"prefix": map[string]any{"name": "ki"}}, `{"prefix":{"name":"ki"}}`},
+		{"empty bool", Bool(), `{"bool":{}}`},
+		{"bool", Bool().
+			Must(Match("title", "kite")).
+			Should(Term("tags", "go"), Term("tags", "search")).
+			Filter(Term("status", "published"), nil, unset).
+			MustNot(Exists("deleted_at")).
+			MinimumShouldMatch(1),
+			`{"bool":{"filter":[{"term":{"status":"published"}}],"minimum_should_match":1,` +
+				`"must":[{"match":{"title":"kite"}}],"must_not":[{"exists":{"field":"deleted_at"}}],` +
+				`"should":[{"term":{"tags":"go"}},{"term":{"tags":"search"}}]}}`},
+		{"nested bool", Bool().Filter(Bool().Should(Term("a", 1), Term("b", 2))),
+			`{"bool":{"filter":[{"bool":{"should":[{"term":{"a":1}},{"term":{"b":2}}]}}]}}`},
+	}
+
+	for i, tc := range tests {
+		assert.JSONEq(t, tc.expected, toJSON(t, tc.query.Source()), "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestAggregations_Source(t *testing.T) {
+	tests := []struct {
+		desc     string
+		agg      Aggregation
+		expected string
+	}{
+		{"avg", Avg("price"), `{"avg":{"field":"price"}}`},
+		{"sum", Sum("price"), `{"sum":{"field":"price"}}`},
+		{"min", Min("price"), `{"min":{"field":"price"}}`},
+		{"max", Max("price"), `{"max":{"field":"price"}}`},
+		{"cardinality", Cardinality("user"), `{"cardinality":{"field":"user"}}`},
+		{"terms", TermsAgg("category").Size(5), `{"terms":{"field":"category","size":5}}`},
+		{"date histogram", DateHistogram("at", "day").Set("time_zone", "Europe/Paris"),
+			`{"date_histogram":{"calendar_interval":"day","field":"at","time_zone":"Europe/Paris"}}`},
+		{"filter", FilterAgg(Term("status", "paid")), `{"filter":{"term":{"status":"paid"}}}`},
+		{"sub aggregations", TermsAgg("category").Sub("avg_price", Avg("price")).Sub("max_price", Max("price")),
+			`{"terms":{"field":"category"},"aggs":{"avg_price":{"avg":{"field":"price"}},` +
+				`"max_price":{"max":{"field":"price"}}}}`},
+	}
+
+	for i, tc := range tests {
+		assert.JSONEq(t, tc.expected, toJSON(t, tc.agg.Source()), "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestSearch_Source(t *testing.T) {
+	search := NewSearch().
+		Query(Bool().Must(Match("title", "kite")).Filter(Range("published_at").Gte("now-30d/d"))).
+		Sort("published_at", Desc).
+		Sort("_score", Asc).
+		From(20).
+		Size(10).
+		Fields("title", "author").
+		Aggregation("authors", TermsAgg("author").Size(3))
+
+	expected := `{
+		"query": {"bool": {"must": [{"match": {"title": "kite"}}],
+			"filter": [{"range": {"published_at": {"gte": "now-30d/d"}}}]}},
+		"sort": [{"published_at": {"order": "desc"}}, {"_score": {"order": "asc"}}],
+		"from": 20,
+		"size": 10,
+		"_source": ["title", "author"],
+		"aggs": {"authors": {"terms": {"field": "author", "size": 3}}}
+	}`
+
+	assert.JSONEq(t, expected, toJSON(t, search.Source()))
+}
+
+func TestSearch_SourceEmpty(t *testing.T) {
+	assert.Empty(t, NewSearch().Source())
+	assert.Equal(t, map[string]any{"size": 0}, NewSearch().Size(0).Source())
+}
+
+type article struct {
+	ID     string  `json:"_id"`
+	Score  float64 `json:"_score"`
+	Title  string  `json:"title"`
+	Author string  `json:"author"`
+}
+
+func TestDecodeHits(t *testing.T) {
+	result := searchResult(t, `{
+		"hits": {
+			"total": {"value": 2, "relation": "eq"},
+			"hits": [
+				{"_id": "1", "_score": 1.5, "_source": {"title": "Kite", "author": "ana"}},
+				{"_id": "2", "_score": 0.5, "_source": {"title": "Search", "author": "bo", "_id": "custom"}}
+			]
+		}
+	}`)
+
+	var articles []article
+
+	require.NoError(t, DecodeHits(result, &articles))
+	assert.Equal(t, []article{
+		{ID: "1", Score: 1.5, Title: "Kite", Author: "ana"},
+		{ID: "custom", Score: 0.5, Title: "Search", Author: "bo"},
+	}, articles)
+
+	var pointers []*article
+
+	require.NoError(t, DecodeHits(result, &pointers))
+	require.Len(t, pointers, 2)
+	assert.Equal(t, "Kite", pointers[0].Title)
+
+	total, err := Total(result)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+}
+
+func TestDecodeHits_NoHits(t *testing.T) {
+	articles := []article{{Title: "stale"}}
+
+	require.NoError(t, DecodeHits(searchResult(t, `{"hits": {"total": {"value": 0}, "hits": []}}`), &articles))
+	assert.Empty(t, articles)
+}
+
+func TestDecodeHits_Errors(t *testing.T) {
+	valid := searchResult(t, `{"hits": {"hits": []}}`)
+
+	var articles []article
+
+	tests := []struct {
+		desc   string
+		result map[string]any
+		dest   any
+		err    error
+	}{
+		{"nil destination", valid, nil, errInvalidDestination},
+		{"not a pointer", valid, articles, errInvalidDestination},
+		{"not a slice", valid, &article{}, errInvalidDestination},
+		{"missing hits", map[string]any{}, &articles, errInvalidResponse},
+		{"hits not an array", searchResult(t, `{"hits": {"hits": {}}}`), &articles, errInvalidResponse},
+		{"hit not an object", searchResult(t, `{"hits": {"hits": [1]}}`), &articles, errInvalidResponse},
+	}
+
+	for i, tc := range tests {
+		err := DecodeHits(tc.result, tc.dest)
+		assert.ErrorIs(t, err, tc.err, "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestTotal_Errors(t *testing.T) {
+	_, err := Total(map[string]any{})
+	require.ErrorIs(t, err, errInvalidResponse)
+
+	_, err = Total(searchResult(t, `{"hits": {"total": "many"}}`))
+	require.ErrorIs(t, err, errInvalidResponse)
+}
+
+func TestDecodeAggregation(t *testing.T) {
+	result := searchResult(t, `{
+		"hits": {"hits": []},
+		"aggregations": {
+			"authors": {"buckets": [{"key": "ana", "doc_count": 3}, {"key": "bo", "doc_count": 1}]},
+			"avg_price": {"value": 12.5}
+		}
+	}`)
+
+	var authors struct {
+		Buckets []struct {
+			Key      string `json:"key"`
+			DocCount int64  `json:"doc_count"`
+		} `json:"buckets"`
+	}
+
+	require.NoError(t, DecodeAggregation(result, "authors", &authors))
+	require.Len(t, authors.Buckets, 2)
+	assert.Equal(t, "ana", authors.Buckets[0].Key)
+	assert.Equal(t, int64(3), authors.Buckets[0].DocCount)
+
+	var avg struct {
+		Value float64 `json:"value"`
+	}
+
+	require.NoError(t, DecodeAggregation(result, "avg_price", &avg))
+	assert.InDelta(t, 12.5, avg.Value, 0)
+
+	err := DecodeAggregation(result, "missing", &avg)
+	require.ErrorIs(t, err, errAggregationMissing)
+}
+
+func searchResult(t *testing.T, body string) map[string]any {
+	t.Helper()
+
+	var result map[string]any
+
+	require.NoError(t, json.Unmarshal([]byte(body), &result))
+
+	return result
+}
+
+func toJSON(t *testing.T, v any) string {
+	t.Helper()
+
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	return string(data)
+}
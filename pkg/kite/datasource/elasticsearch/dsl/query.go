@@ -0,0 +1,240 @@
+// Package dsl builds the bodies of Elasticsearch searches, and decodes the hits of their results into structs.
+//
+// The queries, aggregations and searches are built with typed helpers instead of nested maps, and their Source is the
+// map[string]any accepted by the Search method of the Elasticsearch datasource:
+//
+//	search := dsl.NewSearch().
+//		Query(dsl.Bool().
+//			Must(dsl.Match("title", input)).
+//			Filter(dsl.Term("status", "published"), dsl.Range("published_at").Gte("now-30d/d"))).
+//		Sort("published_at", dsl.Desc).
+//		Size(20).
+//		Aggregation("authors", dsl.TermsAgg("author").Size(10))
+//
+//	result, err := ctx.Elasticsearch.Search(ctx, []string{"articles"}, search.Source())
+//
+//	var articles []Article
+//	err = dsl.DecodeHits(result, &articles)
+package dsl
+
+// Query is a query of the query DSL.
+type Query interface {
+	// Source returns the JSON object of the query.
+	Source() map[string]any
+}
+
+// Raw is a query, or an aggregation, given as its JSON object, for the clauses without a helper.
+type Raw map[string]any
+
+// Source implements the Query and Aggregation interfaces.
+func (r Raw) Source() map[string]any {
+	return r
+}
+
+type leafQuery struct {
+	kind string
+	body any
+}
+
+func (q leafQuery) Source() map[string]any {
+	return map[string]any{q.kind: q.body}
+}
+
+// MatchAll matches all the documents.
+func MatchAll() Query {
+	return leafQuery{kind: "match_all", body: map[string]any{}}
+}
+
+// Term matches the documents whose field is exactly value, e.g. of a keyword field.
+func Term(field string, value any) Query {
+	return leafQuery{kind: "term", body: map[string]any{field: value}}
+}
+
+// Terms matches the documents whose field is exactly one of the values.
+func Terms(field string, values ...any) Query {
+	if values == nil {
+		values = []any{}
+	}
+
+	return leafQuery{kind: "terms", body: map[string]any{field: values}}
+}
+
+// Match matches the documents whose analyzed text field matches the text, e.g. the input of a search box.
+func Match(field string, text any) Query {
+	return leafQuery{kind: "match", body: map[string]any{field: text}}
+}
+
+// MatchPhrase matches the documents whose text field contains the words of the text in order.
+func MatchPhrase(field, text string) Query {
+	return leafQuery{kind: "match_phrase", body: map[string]any{field: text}}
+}
+
+// MultiMatch matches the text on several fields, which can be boosted, e.g. "title^2".
+func MultiMatch(text string, fields ...string) Query {
+	return leafQuery{kind: "multi_match", body: map[string]any{"query": text, "fields": fields}}
+}
+
+// Exists matches the documents with a value for the field.
+func Exists(field string) Query {
+	return leafQuery{kind: "exists", body: map[string]any{"field": field}}
+}
+
+// RangeQuery matches the documents whose field is in a range, see Range.
+type RangeQuery struct {
+	field  string
+	bounds map[string]any
+}
+
+// Range starts a range query on field. The bounds are set with Gt, Gte, Lt and Lte, and can be dates or date math,
+// e.g. "now-1d/d".
+func Range(field string) *RangeQuery {
+	return &RangeQuery{field: field, bounds: make(map[string]any)}
+}
+
+// Gt sets the exclusive lower bound of the range.
+func (q *RangeQuery) Gt(value any) *RangeQuery {
+	return q.set("gt", value)
+}
+
+// Gte sets the inclusive lower bound of the range.
+func (q *RangeQuery) Gte(value any) *RangeQuery {
+	return q.set("gte", value)
+}
+
+// Lt sets the exclusive upper bound of the range.
+func (q *RangeQuery) Lt(value any) *RangeQuery {
+	return q.set("lt", value)
+}
+
+// Lte sets the inclusive upper bound of the range.
+func (q *RangeQuery) Lte(value any) *RangeQuery {
+	return q.set("lte", value)
+}
+
+// Format sets the format of the date bounds, e.g. "yyyy-MM-dd".
+func (q *RangeQuery) Format(format string) *RangeQuery {
+	return q.set("format", format)
+}
+
+// TimeZone sets the time zone of the date bounds, e.g. "Europe/Paris" or "+01:00".
+func (q *RangeQuery) TimeZone(zone string) *RangeQuery {
+	return q.set("time_zone", zone)
+}
+
+func (q *RangeQuery) set(key string, value any) *RangeQuery {
+	q.bounds[key] = value
+
+	return q
+}
+
+// Source implements the Query interface.
+func (q *RangeQuery) Source() map[string]any {
+	return map[string]any{"range": map[string]any{q.field: q.bounds}}
+}
+
+// BoolQuery combines queries, see Bool.
+type BoolQuery struct {
+	must               []Query
+	should             []Query
+	filter             []Query
+	mustNot            []Query
+	minimumShouldMatch any
+}
+
+// Bool starts a bool query. The documents must match the Must and Filter queries, and none of the MustNot queries;
+// the Filter and MustNot queries do not score the documents. A bool query without queries matches all the documents.
+func Bool() *BoolQuery {
+	return &BoolQuery{}
+}
+
+// Must adds queries the documents must match, contributing to their score. The nil queries are skipped, so that
+// optional criteria can be added inline.
+func (q *BoolQuery) Must(queries ...Query) *BoolQuery {
+	q.must = appendQueries(q.must, queries)
+
+	return q
+}
+
+// Should adds queries the documents should match. Without Must or Filter queries, the documents must match at least
+// one of them, see MinimumShouldMatch.
+func (q *BoolQuery) Should(queries ...Query) *BoolQuery {
+	q.should = appendQueries(q.should, queries)
+
+	return q
+}
+
+// Filter adds queries the documents must match, without scoring them.
+func (q *BoolQuery) Filter(queries ...Query) *BoolQuery {
+	q.filter = appendQueries(q.filter, queries)
+
+	return q
+}
+
+// MustNot adds queries the documents must not match.
+func (q *BoolQuery) MustNot(queries ...Query) *BoolQuery {
+	q.mustNot = appendQueries(q.mustNot, queries)
+
+	return q
+}
+
+// MinimumShouldMatch sets the number, e.g. 2, or the percentage, e.g. "75%", of Should queries to match.
+func (q *BoolQuery) MinimumShouldMatch(value any) *BoolQuery {
+	q.minimumShouldMatch = value
+
+	return q
+}
+
+// Source implements the Query interface.
+func (q *BoolQuery) Source() map[string]any {
+	body := make(map[string]any)
+
+	setClauses(body, "must", q.must)
+	setClauses(body, "should", q.should)
+	setClauses(body, "filter", q.filter)
+	setClauses(body, "must_not", q.mustNot)
+
+	if q.minimumShouldMatch != nil {
+		body["minimum_should_match"] = q.minimumShouldMatch
+	}
+
+	return map[string]any{"bool": body}
+}
+
+func appendQueries(dst, queries []Query) []Query {
+	for _, query := range queries {
+		if !isNil(query) {
+			dst = append(dst, query)
+		}
+	}
+
+	return dst
+}
+
+func setClauses(body map[string]any, occur string, queries []Query) {
+	if len(queries) == 0 {
+		return
+	}
+
+	clauses := make([]any, 0, len(queries))
+	for _, query := range queries {
+		clauses = append(clauses, query.Source())
+	}
+
+	body[occur] = clauses
+}
+
+// isNil reports whether the query is nil, including a typed nil pointer, e.g. an unset *RangeQuery.
+func isNil(query Query) bool {
+	switch q := query.(type) {
+	case nil:
+		return true
+	case *BoolQuery:
+		return q == nil
+	case *RangeQuery:
+		return q == nil
+	case Raw:
+		return q == nil
+	default:
+		return false
+	}
+}
@@ -0,0 +1,101 @@
+package dsl
+
+// Order is the order of a sort of a search.
+type Order string
+
+const (
+	Asc  Order = "asc"
+	Desc Order = "desc"
+)
+
+// Search is the body of a search, see NewSearch.
+type Search struct {
+	query  Query
+	sort   []any
+	from   *int
+	size   *int
+	source []string
+	aggs   map[string]Aggregation
+}
+
+// NewSearch starts the body of a search. Without a query, the search matches all the documents.
+func NewSearch() *Search {
+	return &Search{}
+}
+
+// Query sets the query of the search.
+func (s *Search) Query(query Query) *Search {
+	s.query = query
+
+	return s
+}
+
+// Sort adds a sort of the hits, after the previous ones.
+func (s *Search) Sort(field string, order Order) *Search {
+	s.sort = append(s.sort, map[string]any{field: map[string]any{"order": string(order)}})
+
+	return s
+}
+
+// From sets the offset of the first hit, to paginate the hits.
+func (s *Search) From(from int) *Search {
+	s.from = &from
+
+	return s
+}
+
+// Size sets the number of hits, 10 by default. A size of 0 only returns the total and the aggregations.
+func (s *Search) Size(size int) *Search {
+	s.size = &size
+
+	return s
+}
+
+// Fields restricts the fields of the sources of the hits.
+func (s *Search) Fields(fields ...string) *Search {
+	s.source = append(s.source, fields...)
+
+	return s
+}
+
+// Aggregation adds an aggregation of the matching documents, whose result is read with DecodeAggregation.
+func (s *Search) Aggregation(name string, agg Aggregation) *Search {
+	if s.aggs == nil {
+		s.aggs = make(map[string]Aggregation)
+	}
+
+	s.aggs[name] = agg
+
+	return s
+}
+
+// Source returns the body of the search, as accepted by the Search method of the Elasticsearch datasource.
+func (s *Search) Source() map[string]any {
+	body := make(map[string]any)
+
+	if !isNil(s.query) {
+		body["query"] = s.query.Source()
+	}
+
+	if len(s.sort) > 0 {
+		body["sort"] = s.sort
+	}
+
+	if s.from != nil {
+		body["from"] = *s.from
+	}
+
+	if s.size != nil {
+		body["size"] = *s.size
+	}
+
+	if len(s.source) > 0 {
+		body["_source"] = s.source
+	}
+
+	if len(s.aggs) > 0 {
+		body["aggs"] = aggregationsSource(s.aggs)
+	}
+
+	return body
+}
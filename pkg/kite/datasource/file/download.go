@@ -0,0 +1,236 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5" //nolint:gosec // MD5 is the checksum of the objects stored by S3, it is not used for security.
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"time"
+)
+
+const (
+	defaultDownloadPartSize    = 8 << 20 // 8 MiB
+	defaultDownloadConcurrency = 4
+)
+
+var (
+	// ErrChecksumMismatch is returned when the checksum of a downloaded object differs from the one of the stored
+	// object.
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+
+	errUnsupportedChecksum = errors.New("unsupported checksum algorithm")
+)
+
+// ChecksumAlgorithm is the algorithm of the checksum of a stored object.
+type ChecksumAlgorithm string
+
+const (
+	// ChecksumMD5 is the MD5 digest of the object, e.g. the ETag of the objects uploaded to S3 in a single part.
+	ChecksumMD5 ChecksumAlgorithm = "MD5"
+	// ChecksumCRC32C is the CRC32 checksum of the object with the Castagnoli polynomial, computed by GCS.
+	ChecksumCRC32C ChecksumAlgorithm = "CRC32C"
+)
+
+// Checksum is the checksum of a stored object, verified by the downloads.
+type Checksum struct {
+	Algorithm ChecksumAlgorithm
+	Sum       []byte
+}
+
+func (c *Checksum) newHash() (hash.Hash, error) {
+	switch c.Algorithm {
+	case ChecksumMD5:
+		return md5.New(), nil //nolint:gosec // see the import.
+	case ChecksumCRC32C:
+		return crc32.New(crc32.MakeTable(crc32.Castagnoli)), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", errUnsupportedChecksum, c.Algorithm)
+	}
+}
+
+// DownloadConfig holds the configuration of the parallel downloads of objects.
+type DownloadConfig struct {
+	// PartSize is the size of the ranges fetched concurrently. Defaults to 8 MiB.
+	PartSize int64
+	// Concurrency is the number of ranges fetched at the same time. The download buffers up to Concurrency parts,
+	// so it holds at most PartSize * Concurrency bytes in memory. Defaults to 4.
+	Concurrency int
+	// SkipChecksum disables the verification of the checksum of the object, when it has one.
+	SkipChecksum bool
+}
+
+func (c DownloadConfig) withDefaults() DownloadConfig {
+	if c.PartSize <= 0 {
+		c.PartSize = defaultDownloadPartSize
+	}
+
+	if c.Concurrency <= 0 {
+		c.Concurrency = defaultDownloadConcurrency
+	}
+
+	return c
+}
+
+// Downloader is implemented by the file systems which can download an object with parallel ranged reads, which is
+// much faster than streaming large objects with Open.
+type Downloader interface {
+	// Download writes the content of the object name to w and returns the number of bytes written.
+	Download(ctx context.Context, name string, w io.Writer, config DownloadConfig) (int64, error)
+}
+
+// RangeReader reads length bytes of an object from offset.
+type RangeReader func(ctx context.Context, offset, length int64) (io.ReadCloser, error)
+
+// rangePart is the content, or the failure, of a fetched range.
+type rangePart struct {
+	data []byte
+	err  error
+}
+
+// DownloadRanges downloads an object of size bytes to w, fetching config.Concurrency ranges of config.PartSize bytes
+// at the same time with readRange, and writing them in order.
+//
+// When checksum is not nil the content is verified against it, and ErrChecksumMismatch is returned if it differs.
+// As the content is verified once it is written, w should be discarded when the download fails, e.g. by writing it
+// to a temporary file which is renamed on success.
+func DownloadRanges(ctx context.Context, w io.Writer, size int64, checksum *Checksum, config DownloadConfig,
+	readRange RangeReader) (int64, error) {
+	config = config.withDefaults()
+
+	var h hash.Hash
+
+	if checksum != nil && !config.SkipChecksum {
+		var err error
+
+		if h, err = checksum.newHash(); err != nil {
+			return 0, err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	parts := make([]chan rangePart, (size+config.PartSize-1)/config.PartSize)
+	for i := range parts {
+		// buffered so that the fetches never block, even when the download has failed.
+		parts[i] = make(chan rangePart, 1)
+	}
+
+	// a slot is taken for each part fetched and released once it is written, bounding the buffered parts.
+	slots := make(chan struct{}, config.Concurrency)
+
+	go func() {
+		for i := range parts {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+
+			go fetchRange(ctx, parts[i], int64(i)*config.PartSize, min(config.PartSize, size-int64(i)*config.PartSize),
+				readRange)
+		}
+	}()
+
+	var written int64
+
+	for i := range parts {
+		var part rangePart
+
+		select {
+		case part = <-parts[i]:
+		case <-ctx.Done():
+			return written, ctx.Err()
+		}
+
+		<-slots
+
+		if part.err != nil {
+			return written, fmt.Errorf("reading range at offset %d: %w", int64(i)*config.PartSize, part.err)
+		}
+
+		n, err := w.Write(part.data)
+		written += int64(n)
+
+		if err != nil {
+			return written, err
+		}
+
+		if h != nil {
+			h.Write(part.data)
+		}
+	}
+
+	if h != nil && len(checksum.Sum) > 0 && !bytes.Equal(h.Sum(nil), checksum.Sum) {
+		return written, fmt.Errorf("%w: %s is %s, expected %s", ErrChecksumMismatch, checksum.Algorithm,
+			hex.EncodeToString(h.Sum(nil)), hex.EncodeToString(checksum.Sum))
+	}
+
+	return written, nil
+}
+
+func fetchRange(ctx context.Context, result chan<- rangePart, offset, length int64, readRange RangeReader) {
+	reader, err := readRange(ctx, offset, length)
+	if err != nil {
+		result <- rangePart{err: err}
+
+		return
+	}
+
+	defer reader.Close()
+
+	data := make([]byte, length)
+
+	if _, err = io.ReadFull(reader, data); err != nil {
+		result <- rangePart{err: err}
+
+		return
+	}
+
+	result <- rangePart{data: data}
+}
+
+// Download writes the content of the object name to w, fetching its ranges in parallel, and verifies its checksum
+// when the provider reports one, see DownloadRanges.
+//
+// The ranges are read concurrently, which the cloud storages support; an FTP connection serves a single transfer at a
+// time, so the downloads from FTP servers must use a Concurrency of 1.
+func (c *CommonFileSystem) Download(ctx context.Context, name string, w io.Writer, config DownloadConfig) (int64, error) {
+	var msg string
+
+	st := StatusError
+	startTime := time.Now()
+
+	defer c.Observe(OpDownload, startTime, &st, &msg)
+
+	if c.Provider == nil {
+		return 0, errProviderNil
+	}
+
+	info, err := c.Provider.StatObject(ctx, name)
+	if err != nil {
+		msg = fmt.Sprintf("failed to stat %q: %v", name, err)
+
+		return 0, err
+	}
+
+	n, err := DownloadRanges(ctx, w, info.Size, info.Checksum, config,
+		func(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+			return c.Provider.NewRangeReader(ctx, name, offset, length)
+		})
+	if err != nil {
+		msg = fmt.Sprintf("failed to download %q: %v", name, err)
+
+		return n, err
+	}
+
+	st = StatusSuccess
+	msg = fmt.Sprintf("downloaded %q (%d bytes)", name, n)
+
+	return n, nil
+}
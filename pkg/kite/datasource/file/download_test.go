@@ -0,0 +1,138 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5" //nolint:gosec // the checksum of the test objects.
+	"errors"
+	"hash/crc32"
+	"io"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+var errRangeFailed = errors.New("range failed")
+
+// rangesOf reads the ranges of content, recording the maximum number of concurrent reads.
+func rangesOf(content []byte, inFlight, maxInFlight *atomic.Int64) RangeReader {
+	return func(_ context.Context, offset, length int64) (io.ReadCloser, error) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+
+		for {
+			if m := maxInFlight.Load(); n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+
+		return io.NopCloser(bytes.NewReader(content[offset : offset+length])), nil
+	}
+}
+
+func TestDownloadRanges(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+	md5Sum := md5.Sum(content) //nolint:gosec // see the import.
+	crc := crc32.Checksum(content, crc32.MakeTable(crc32.Castagnoli))
+
+	testCases := []struct {
+		desc     string
+		content  []byte
+		checksum *Checksum
+		config   DownloadConfig
+		err      error
+	}{
+		{desc: "parts", content: content, config: DownloadConfig{PartSize: 1000, Concurrency: 3}},
+		{desc: "last part shorter", content: content, config: DownloadConfig{PartSize: 3000, Concurrency: 2}},
+		{desc: "single part", content: content, config: DownloadConfig{}},
+		{desc: "empty object", content: []byte{}, config: DownloadConfig{PartSize: 10}},
+		{desc: "md5", content: content, checksum: &Checksum{Algorithm: ChecksumMD5, Sum: md5Sum[:]},
+			config: DownloadConfig{PartSize: 999}},
+		{desc: "crc32c", content: content, checksum: &Checksum{Algorithm: ChecksumCRC32C,
+			Sum: []byte{byte(crc >> 24), byte(crc >> 16), byte(crc >> 8), byte(crc)}}, config: DownloadConfig{PartSize: 999}},
+		{desc: "mismatch", content: content, checksum: &Checksum{Algorithm: ChecksumMD5, Sum: []byte("wrong")},
+			config: DownloadConfig{PartSize: 999}, err: ErrChecksumMismatch},
+		{desc: "checksum skipped", content: content, checksum: &Checksum{Algorithm: ChecksumMD5, Sum: []byte("wrong")},
+			config: DownloadConfig{PartSize: 999, SkipChecksum: true}},
+	}
+
+	for i, tc := range testCases {
+		var inFlight, maxInFlight atomic.Int64
+
+		var out bytes.Buffer
+
+		n, err := DownloadRanges(t.Context(), &out, int64(len(tc.content)), tc.checksum, tc.config,
+			rangesOf(tc.content, &inFlight, &maxInFlight))
+
+		require.ErrorIs(t, err, tc.err, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, int64(len(tc.content)), n, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, string(tc.content), out.String(), "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.LessOrEqual(t, maxInFlight.Load(), int64(tc.config.withDefaults().Concurrency),
+			"TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestDownloadRanges_Errors(t *testing.T) {
+	content := bytes.Repeat([]byte("a"), 100)
+
+	testCases := []struct {
+		desc      string
+		checksum  *Checksum
+		readRange RangeReader
+		written   int64
+		err       error
+	}{
+		{desc: "range error", written: 20, err: errRangeFailed,
+			readRange: func(_ context.Context, offset, length int64) (io.ReadCloser, error) {
+				if offset == 20 {
+					return nil, errRangeFailed
+				}
+
+				return io.NopCloser(bytes.NewReader(content[offset : offset+length])), nil
+			}},
+		{desc: "short range", err: io.ErrUnexpectedEOF,
+			readRange: func(context.Context, int64, int64) (io.ReadCloser, error) {
+				return io.NopCloser(bytes.NewReader(content[:5])), nil
+			}},
+		{desc: "unsupported checksum", checksum: &Checksum{Algorithm: "SHA1"}, err: errUnsupportedChecksum},
+	}
+
+	for i, tc := range testCases {
+		n, err := DownloadRanges(t.Context(), io.Discard, int64(len(content)), tc.checksum,
+			DownloadConfig{PartSize: 10, Concurrency: 2}, tc.readRange)
+
+		require.ErrorIs(t, err, tc.err, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, tc.written, n, "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestCommonFileSystem_Download(t *testing.T) {
+	ctrl, mockProvider, fs := setupCommonFS(t)
+	defer ctrl.Finish()
+
+	content := []byte("hello, parallel world")
+	sum := md5.Sum(content) //nolint:gosec // see the import.
+
+	mockProvider.EXPECT().StatObject(gomock.Any(), "file.bin").Return(&ObjectInfo{Name: "file.bin",
+		Size: int64(len(content)), Checksum: &Checksum{Algorithm: ChecksumMD5, Sum: sum[:]}}, nil)
+	mockProvider.EXPECT().NewRangeReader(gomock.Any(), "file.bin", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, offset, length int64) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(content[offset : offset+length])), nil
+		}).Times(3)
+
+	var out bytes.Buffer
+
+	n, err := fs.Download(t.Context(), "file.bin", &out, DownloadConfig{PartSize: 8})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), n)
+	assert.Equal(t, content, out.Bytes())
+
+	mockProvider.EXPECT().StatObject(gomock.Any(), "missing.bin").Return(nil, errTest)
+
+	_, err = fs.Download(t.Context(), "missing.bin", &out, DownloadConfig{})
+	require.ErrorIs(t, err, errTest)
+}
@@ -2,6 +2,7 @@ package gcs
 
 import (
 	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
@@ -177,13 +178,20 @@ func (s *storageAdapter) StatObject(ctx context.Context, name string) (*file.Obj
 		return nil, fmt.Errorf("%w for %q: %w", errFailedToGetObjectAttrs, name, err)
 	}
 
-	return &file.ObjectInfo{
+	info := &file.ObjectInfo{
 		Name:         attrs.Name,
 		Size:         attrs.Size,
 		ContentType:  attrs.ContentType,
 		LastModified: attrs.Updated,
 		IsDir:        attrs.ContentType == contentTypeDirectory,
-	}, nil
+	}
+
+	// GCS computes the CRC32C of every object, it is only missing from the metadata of emulated buckets.
+	if attrs.CRC32C != 0 {
+		info.Checksum = &file.Checksum{Algorithm: file.ChecksumCRC32C, Sum: binary.BigEndian.AppendUint32(nil, attrs.CRC32C)}
+	}
+
+	return info, nil
 }
 
 // DeleteObject deletes the object with the given name.
@@ -362,7 +362,8 @@ func TestStorageAdapter_StatObject_Success(t *testing.T) {
 				"size":"123",
 				"contentType":"text/plain",
 				"updated":"2020-01-01T00:00:00.000Z",
-				"generation":"42"
+				"generation":"42",
+				"crc32c":"yZRlqg=="
 			}`))
 
 			return
@@ -390,6 +391,7 @@ func TestStorageAdapter_StatObject_Success(t *testing.T) {
 	assert.Equal(t, int64(123), info.Size)
 	assert.Equal(t, "text/plain", info.ContentType)
 	assert.False(t, info.IsDir)
+	assert.Equal(t, &file.Checksum{Algorithm: file.ChecksumCRC32C, Sum: []byte{0xc9, 0x94, 0x65, 0xaa}}, info.Checksum)
 }
 
 func TestStorageAdapter_DeleteObject_Success(t *testing.T) {
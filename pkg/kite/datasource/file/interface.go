@@ -67,6 +67,8 @@ type ObjectInfo struct {
 	ContentType  string
 	LastModified time.Time
 	IsDir        bool
+	// Checksum is the checksum of the content of the object, when the provider reports one.
+	Checksum *Checksum
 }
 
 // FileSystem : Any simulated or real filesystem should implement this interface.
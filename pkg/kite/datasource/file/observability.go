@@ -33,6 +33,7 @@ const (
 	OpWriteAt   = "WRITE_AT"
 	OpSeek      = "SEEK"
 	OpClose     = "CLOSE"
+	OpDownload  = "DOWNLOAD"
 )
 
 // StorageMetrics interface that all storage providers should use.
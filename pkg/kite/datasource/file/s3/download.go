@@ -0,0 +1,83 @@
+package s3
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	file "github.com/sllt/kite/pkg/kite/datasource/file"
+)
+
+// Download writes the content of the object name to w, fetching config.Concurrency ranges of config.PartSize bytes
+// in parallel, which is much faster than streaming a large object with Open. See file.DownloadRanges.
+//
+// The ranges are fetched with the ETag of the object as a precondition, so that the download fails instead of mixing
+// two versions if the object is overwritten meanwhile. The content is verified against the ETag when it is the MD5
+// digest of the object, i.e. for the objects uploaded in a single part without SSE-KMS or SSE-C encryption.
+func (f *FileSystem) Download(ctx context.Context, name string, w io.Writer, config file.DownloadConfig) (int64, error) {
+	var msg string
+
+	st := statusErr
+
+	defer f.sendOperationStats(&FileLog{
+		Operation: "DOWNLOAD",
+		Location:  getLocation(f.config.BucketName),
+		Status:    &st,
+		Message:   &msg,
+	}, time.Now())
+
+	head, err := f.conn.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(f.config.BucketName),
+		Key:    aws.String(name),
+	})
+	if err != nil {
+		f.logger.Errorf("failed to retrieve the metadata of %q: %v", name, err)
+		return 0, err
+	}
+
+	n, err := file.DownloadRanges(ctx, w, aws.ToInt64(head.ContentLength), etagChecksum(head), config,
+		func(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+			res, err := f.conn.GetObject(ctx, &s3.GetObjectInput{
+				Bucket:  aws.String(f.config.BucketName),
+				Key:     aws.String(name),
+				Range:   aws.String(fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)),
+				IfMatch: head.ETag,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			return res.Body, nil
+		})
+	if err != nil {
+		f.logger.Errorf("failed to download %q: %v", name, err)
+		return n, err
+	}
+
+	st = statusSuccess
+	msg = fmt.Sprintf("File with path %q downloaded successfully (%d bytes)", name, n)
+
+	return n, nil
+}
+
+// etagChecksum returns the MD5 digest of the object held by its ETag, or nil when the ETag is not one: the ETags of
+// the multipart uploads end with the number of parts, e.g. "-3", and the ones of the encrypted objects are opaque.
+func etagChecksum(head *s3.HeadObjectOutput) *file.Checksum {
+	if head.ServerSideEncryption == types.ServerSideEncryptionAwsKms ||
+		head.ServerSideEncryption == types.ServerSideEncryptionAwsKmsDsse || head.SSECustomerAlgorithm != nil {
+		return nil
+	}
+
+	sum, err := hex.DecodeString(strings.Trim(aws.ToString(head.ETag), `"`))
+	if err != nil || len(sum) != 16 {
+		return nil
+	}
+
+	return &file.Checksum{Algorithm: file.ChecksumMD5, Sum: sum}
+}
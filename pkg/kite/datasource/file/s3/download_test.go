@@ -0,0 +1,106 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5" //nolint:gosec // the ETag of the test objects.
+	"encoding/hex"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	file "github.com/sllt/kite/pkg/kite/datasource/file"
+)
+
+func Test_Download(t *testing.T) {
+	content := []byte("hello, parallel world")
+	sum := md5.Sum(content) //nolint:gosec // see the import.
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+
+	testCases := []struct {
+		desc string
+		etag string
+		err  error
+	}{
+		{desc: "etag verified", etag: etag},
+		{desc: "multipart etag", etag: `"d41d8cd98f00b204e9800998ecf8427e-3"`},
+		{desc: "etag mismatch", etag: `"d41d8cd98f00b204e9800998ecf8427e"`, err: file.ErrChecksumMismatch},
+	}
+
+	for i, tc := range testCases {
+		ctrl := gomock.NewController(t)
+		mocks := setupTestMocks(ctrl)
+		fs := setupTestFileSystem(mocks, nil)
+
+		mocks.mockLogger.EXPECT().Debug(gomock.Any()).AnyTimes()
+		mocks.mockLogger.EXPECT().Errorf(gomock.Any(), gomock.Any()).AnyTimes()
+
+		mocks.mockS3.EXPECT().HeadObject(gomock.Any(), &s3.HeadObjectInput{
+			Bucket: aws.String("test-bucket"), Key: aws.String("data.bin"),
+		}).Return(&s3.HeadObjectOutput{ContentLength: aws.Int64(int64(len(content))), ETag: aws.String(tc.etag)}, nil)
+
+		for offset := 0; offset < len(content); offset += 8 {
+			end := min(offset+8, len(content))
+
+			mocks.mockS3.EXPECT().GetObject(gomock.Any(), &s3.GetObjectInput{
+				Bucket:  aws.String("test-bucket"),
+				Key:     aws.String("data.bin"),
+				Range:   aws.String(fmt.Sprintf("bytes=%d-%d", offset, end-1)),
+				IfMatch: aws.String(tc.etag),
+			}).Return(&s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(content[offset:end]))}, nil)
+		}
+
+		var out bytes.Buffer
+
+		n, err := fs.Download(t.Context(), "data.bin", &out, file.DownloadConfig{PartSize: 8, Concurrency: 2})
+
+		require.ErrorIs(t, err, tc.err, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, int64(len(content)), n, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, content, out.Bytes(), "TEST[%d], Failed.\n%s", i, tc.desc)
+
+		ctrl.Finish()
+	}
+}
+
+func Test_Download_HeadError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mocks := setupTestMocks(ctrl)
+	fs := setupTestFileSystem(mocks, nil)
+
+	mocks.mockLogger.EXPECT().Debug(gomock.Any()).AnyTimes()
+	mocks.mockLogger.EXPECT().Errorf(gomock.Any(), gomock.Any())
+	mocks.mockS3.EXPECT().HeadObject(gomock.Any(), gomock.Any()).Return(nil, errMock)
+
+	_, err := fs.Download(context.Background(), "data.bin", io.Discard, file.DownloadConfig{})
+
+	require.ErrorIs(t, err, errMock)
+}
+
+func Test_etagChecksum(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		head     *s3.HeadObjectOutput
+		checksum *file.Checksum
+	}{
+		{desc: "single part", head: &s3.HeadObjectOutput{ETag: aws.String(`"00112233445566778899aabbccddeeff"`)},
+			checksum: &file.Checksum{Algorithm: file.ChecksumMD5, Sum: []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66,
+				0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}}},
+		{desc: "multipart", head: &s3.HeadObjectOutput{ETag: aws.String(`"00112233445566778899aabbccddeeff-2"`)}},
+		{desc: "kms", head: &s3.HeadObjectOutput{ETag: aws.String(`"00112233445566778899aabbccddeeff"`),
+			ServerSideEncryption: types.ServerSideEncryptionAwsKms}},
+		{desc: "missing", head: &s3.HeadObjectOutput{}},
+	}
+
+	for i, tc := range testCases {
+		assert.Equal(t, tc.checksum, etagChecksum(tc.head), "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
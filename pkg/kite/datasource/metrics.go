@@ -0,0 +1,72 @@
+package datasource
+
+import (
+	"context"
+	"time"
+)
+
+// Standard metrics recorded by every datasource, so that dashboards and alerts work the same across stores.
+// They are all labeled with the datasource, e.g. "sql" or "redis", the database and the operation.
+const (
+	// MetricOperations counts the operations run against a datasource.
+	MetricOperations = "app_datasource_operations_total"
+	// MetricErrors counts the operations which failed.
+	MetricErrors = "app_datasource_errors_total"
+	// MetricDuration is the histogram of the response time of the operations in milliseconds.
+	MetricDuration = "app_datasource_duration"
+)
+
+// Metrics is the subset of the metrics manager used to record the standard datasource metrics.
+type Metrics interface {
+	IncrementCounter(ctx context.Context, name string, labels ...string)
+	RecordHistogram(ctx context.Context, name string, value float64, labels ...string)
+}
+
+// MetricsRegisterer is the subset of the metrics manager used to register the standard datasource metrics.
+type MetricsRegisterer interface {
+	NewCounter(name, desc string)
+	NewHistogram(name, desc string, buckets ...float64)
+}
+
+// Operation identifies an operation run against a datasource.
+type Operation struct {
+	// Datasource is the type of the store, e.g. "sql", "redis" or "mongo".
+	Datasource string
+	// Database is the database, keyspace or index the operation ran against.
+	Database string
+	// Name is the operation, e.g. "SELECT" for SQL or the command name for Redis.
+	Name string
+}
+
+// RegisterMetrics registers the standard datasource metrics.
+func RegisterMetrics(m MetricsRegisterer) {
+	m.NewCounter(MetricOperations, "Number of operations run against datasources.")
+	m.NewCounter(MetricErrors, "Number of failed operations run against datasources.")
+	m.NewHistogram(MetricDuration, "Response time of datasource operations in milliseconds.", DurationBuckets()...)
+}
+
+// DurationBuckets returns the buckets of the MetricDuration histogram in milliseconds.
+func DurationBuckets() []float64 {
+	return []float64{
+		.05, .075, .1, .125, .15, .2, .3, .5, .75, 1, 2, 3, 5, 7.5, 10, // 0-10ms: fast operations
+		25, 50, 100, 250, 500, 1000, 5000, 10000, 30000, // 10ms-30s: slower operations
+	}
+}
+
+// RecordOperation records the standard metrics of an operation which took duration and failed with err, if not nil.
+// Errors which only report a missing result, like sql.ErrNoRows, should be passed as nil.
+func RecordOperation(ctx context.Context, m Metrics, op Operation, duration time.Duration, err error) {
+	if m == nil {
+		return
+	}
+
+	labels := []string{"datasource", op.Datasource, "database", op.Database, "operation", op.Name}
+
+	m.IncrementCounter(ctx, MetricOperations, labels...)
+
+	if err != nil {
+		m.IncrementCounter(ctx, MetricErrors, labels...)
+	}
+
+	m.RecordHistogram(ctx, MetricDuration, float64(duration.Microseconds())/1000, labels...)
+}
@@ -0,0 +1,74 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errTimeout = errors.New("timeout")
+
+// recordedMetrics records the metrics for assertions.
+type recordedMetrics struct {
+	counters   map[string][]string
+	histograms map[string]float64
+	buckets    map[string][]float64
+}
+
+func newRecordedMetrics() *recordedMetrics {
+	return &recordedMetrics{
+		counters:   make(map[string][]string),
+		histograms: make(map[string]float64),
+		buckets:    make(map[string][]float64),
+	}
+}
+
+func (m *recordedMetrics) NewCounter(name, _ string) {
+	m.counters[name] = nil
+}
+
+func (m *recordedMetrics) NewHistogram(name, _ string, buckets ...float64) {
+	m.buckets[name] = buckets
+}
+
+func (m *recordedMetrics) IncrementCounter(_ context.Context, name string, labels ...string) {
+	m.counters[name] = labels
+}
+
+func (m *recordedMetrics) RecordHistogram(_ context.Context, name string, value float64, _ ...string) {
+	m.histograms[name] = value
+}
+
+func TestRegisterMetrics(t *testing.T) {
+	m := newRecordedMetrics()
+
+	RegisterMetrics(m)
+
+	assert.Contains(t, m.counters, MetricOperations)
+	assert.Contains(t, m.counters, MetricErrors)
+	assert.Equal(t, DurationBuckets(), m.buckets[MetricDuration])
+}
+
+func TestRecordOperation(t *testing.T) {
+	op := Operation{Datasource: "sql", Database: "orders", Name: "SELECT"}
+	labels := []string{"datasource", "sql", "database", "orders", "operation", "SELECT"}
+
+	m := newRecordedMetrics()
+
+	RecordOperation(context.Background(), m, op, 1500*time.Microsecond, nil)
+
+	assert.Equal(t, labels, m.counters[MetricOperations])
+	assert.NotContains(t, m.counters, MetricErrors)
+	assert.InDelta(t, 1.5, m.histograms[MetricDuration], 1e-9)
+
+	RecordOperation(context.Background(), m, op, time.Millisecond, errTimeout)
+
+	assert.Equal(t, labels, m.counters[MetricErrors])
+
+	assert.NotPanics(t, func() {
+		RecordOperation(context.Background(), nil, op, time.Millisecond, errTimeout)
+	})
+}
@@ -115,6 +115,9 @@ func (c *Client) Connect() {
 
 	if err = m.Ping(ctx, nil); err != nil {
 		c.logger.Errorf("could not connect to MongoDB at %v due to err: %v", host, err)
+
+		_ = m.Disconnect(ctx)
+
 		return
 	}
 
@@ -126,6 +129,18 @@ func (c *Client) Connect() {
 	c.logger.Logf("connected to MongoDB at %v to database %v", host, c.config.Database)
 }
 
+// Close disconnects the client from MongoDB, e.g. before connecting it again.
+func (c *Client) Close() error {
+	if c.Database == nil {
+		return nil
+	}
+
+	err := c.Database.Client().Disconnect(context.Background())
+	c.Database = nil
+
+	return err
+}
+
 func generateMongoURI(config *Config) (uri, host string, err error) {
 	if config.URI != "" {
 		host, err = getDBHost(config.URI)
@@ -186,7 +201,7 @@ func (c *Client) InsertOne(ctx context.Context, collection string, document any)
 	result, err := c.Database.Collection(collection).InsertOne(tracerCtx, document)
 
 	defer c.sendOperationStats(&QueryLog{Query: "insertOne", Collection: collection, Filter: document}, time.Now(),
-		"insert", span)
+		"insert", span, err)
 
 	return result, err
 }
@@ -196,13 +211,14 @@ func (c *Client) InsertMany(ctx context.Context, collection string, documents []
 	tracerCtx, span := c.addTrace(ctx, "insertMany", collection)
 
 	res, err := c.Database.Collection(collection).InsertMany(tracerCtx, documents)
+
+	defer c.sendOperationStats(&QueryLog{Query: "insertMany", Collection: collection, Filter: documents}, time.Now(),
+		"insertMany", span, err)
+
 	if err != nil {
 		return nil, err
 	}
 
-	defer c.sendOperationStats(&QueryLog{Query: "insertMany", Collection: collection, Filter: documents}, time.Now(),
-		"insertMany", span)
-
 	return res.InsertedIDs, nil
 }
 
@@ -211,20 +227,16 @@ func (c *Client) Find(ctx context.Context, collection string, filter, results an
 	tracerCtx, span := c.addTrace(ctx, "find", collection)
 
 	cur, err := c.Database.Collection(collection).Find(tracerCtx, filter)
-	if err != nil {
-		return err
-	}
-
-	defer cur.Close(ctx)
+	if err == nil {
+		defer cur.Close(ctx)
 
-	if err := cur.All(ctx, results); err != nil {
-		return err
+		err = cur.All(ctx, results)
 	}
 
 	defer c.sendOperationStats(&QueryLog{Query: "find", Collection: collection, Filter: filter}, time.Now(), "find",
-		span)
+		span, err)
 
-	return nil
+	return err
 }
 
 // FindOne retrieves a single document from the specified collection based on the provided filter and binds response to result.
@@ -232,13 +244,14 @@ func (c *Client) FindOne(ctx context.Context, collection string, filter, result
 	tracerCtx, span := c.addTrace(ctx, "findOne", collection)
 
 	b, err := c.Database.Collection(collection).FindOne(tracerCtx, filter).Raw()
+
+	defer c.sendOperationStats(&QueryLog{Query: "findOne", Collection: collection, Filter: filter}, time.Now(),
+		"findOne", span, err)
+
 	if err != nil {
 		return err
 	}
 
-	defer c.sendOperationStats(&QueryLog{Query: "findOne", Collection: collection, Filter: filter}, time.Now(),
-		"findOne", span)
-
 	return bson.Unmarshal(b, result)
 }
 
@@ -249,7 +262,7 @@ func (c *Client) UpdateByID(ctx context.Context, collection string, id, update a
 	res, err := c.Database.Collection(collection).UpdateByID(tracerCtx, id, update)
 
 	defer c.sendOperationStats(&QueryLog{Query: "updateByID", Collection: collection, ID: id, Update: update}, time.Now(),
-		"updateByID", span)
+		"updateByID", span, err)
 
 	return res.ModifiedCount, err
 }
@@ -261,7 +274,7 @@ func (c *Client) UpdateOne(ctx context.Context, collection string, filter, updat
 	_, err := c.Database.Collection(collection).UpdateOne(tracerCtx, filter, update)
 
 	defer c.sendOperationStats(&QueryLog{Query: "updateOne", Collection: collection, Filter: filter, Update: update},
-		time.Now(), "updateOne", span)
+		time.Now(), "updateOne", span, err)
 
 	return err
 }
@@ -273,7 +286,7 @@ func (c *Client) UpdateMany(ctx context.Context, collection string, filter, upda
 	res, err := c.Database.Collection(collection).UpdateMany(tracerCtx, filter, update)
 
 	defer c.sendOperationStats(&QueryLog{Query: "updateMany", Collection: collection, Filter: filter, Update: update}, time.Now(),
-		"updateMany", span)
+		"updateMany", span, err)
 
 	return res.ModifiedCount, err
 }
@@ -285,7 +298,7 @@ func (c *Client) CountDocuments(ctx context.Context, collection string, filter a
 	result, err := c.Database.Collection(collection).CountDocuments(tracerCtx, filter)
 
 	defer c.sendOperationStats(&QueryLog{Query: "countDocuments", Collection: collection, Filter: filter}, time.Now(),
-		"countDocuments", span)
+		"countDocuments", span, err)
 
 	return result, err
 }
@@ -295,13 +308,14 @@ func (c *Client) DeleteOne(ctx context.Context, collection string, filter any) (
 	tracerCtx, span := c.addTrace(ctx, "deleteOne", collection)
 
 	res, err := c.Database.Collection(collection).DeleteOne(tracerCtx, filter)
+
+	defer c.sendOperationStats(&QueryLog{Query: "deleteOne", Collection: collection, Filter: filter}, time.Now(),
+		"deleteOne", span, err)
+
 	if err != nil {
 		return 0, err
 	}
 
-	defer c.sendOperationStats(&QueryLog{Query: "deleteOne", Collection: collection, Filter: filter}, time.Now(),
-		"deleteOne", span)
-
 	return res.DeletedCount, nil
 }
 
@@ -310,13 +324,14 @@ func (c *Client) DeleteMany(ctx context.Context, collection string, filter any)
 	tracerCtx, span := c.addTrace(ctx, "deleteMany", collection)
 
 	res, err := c.Database.Collection(collection).DeleteMany(tracerCtx, filter)
+
+	defer c.sendOperationStats(&QueryLog{Query: "deleteMany", Collection: collection, Filter: filter}, time.Now(),
+		"deleteMany", span, err)
+
 	if err != nil {
 		return 0, err
 	}
 
-	defer c.sendOperationStats(&QueryLog{Query: "deleteMany", Collection: collection, Filter: filter}, time.Now(),
-		"deleteMany", span)
-
 	return res.DeletedCount, nil
 }
 
@@ -326,7 +341,7 @@ func (c *Client) Drop(ctx context.Context, collection string) error {
 
 	err := c.Database.Collection(collection).Drop(tracerCtx)
 
-	defer c.sendOperationStats(&QueryLog{Query: "drop", Collection: collection}, time.Now(), "drop", span)
+	defer c.sendOperationStats(&QueryLog{Query: "drop", Collection: collection}, time.Now(), "drop", span, err)
 
 	return err
 }
@@ -338,12 +353,50 @@ func (c *Client) CreateCollection(ctx context.Context, name string) error {
 	err := c.Database.CreateCollection(tracerCtx, name)
 
 	defer c.sendOperationStats(&QueryLog{Query: "createCollection", Collection: name}, time.Now(), "createCollection",
-		span)
+		span, err)
 
 	return err
 }
 
-func (c *Client) sendOperationStats(ql *QueryLog, startTime time.Time, method string, span trace.Span) {
+// Aggregate runs the aggregation pipeline, a *Pipeline or any pipeline accepted by the driver, on the specified
+// collection and binds the resulting documents to results.
+func (c *Client) Aggregate(ctx context.Context, collection string, pipeline, results any) error {
+	cur, err := c.aggregate(ctx, collection, pipeline)
+	if err != nil {
+		return err
+	}
+
+	defer cur.Close(ctx)
+
+	return cur.All(ctx, results)
+}
+
+// AggregateCursor runs the aggregation pipeline on the specified collection and returns the cursor of the resulting
+// documents, to decode them one by one or with DecodeAll. The cursor must be closed.
+func (c *Client) AggregateCursor(ctx context.Context, collection string, pipeline any) (*mongo.Cursor, error) {
+	return c.aggregate(ctx, collection, pipeline)
+}
+
+func (c *Client) aggregate(ctx context.Context, collection string, pipeline any) (*mongo.Cursor, error) {
+	tracerCtx, span := c.addTrace(ctx, "aggregate", collection)
+
+	stages, count := pipelineStages(pipeline)
+
+	if span != nil {
+		span.SetAttributes(attribute.Int("mongo.pipeline.stages", count))
+	}
+
+	cur, err := c.Database.Collection(collection).Aggregate(tracerCtx, stages)
+
+	defer c.sendOperationStats(&QueryLog{Query: "aggregate", Collection: collection, Filter: stages}, time.Now(),
+		"aggregate", span, err)
+
+	return cur, err
+}
+
+// sendOperationStats logs the operation and records its metrics, counting it as failed when err is not nil.
+// mongo.ErrNoDocuments only reports that no document matched the filter, it is not counted as a failure.
+func (c *Client) sendOperationStats(ql *QueryLog, startTime time.Time, method string, span trace.Span, err error) {
 	duration := time.Since(startTime).Microseconds()
 
 	ql.Duration = duration
@@ -353,6 +406,18 @@ func (c *Client) sendOperationStats(ql *QueryLog, startTime time.Time, method st
 	c.metrics.RecordHistogram(context.Background(), "app_mongo_stats", float64(duration), "hostname", c.uri,
 		"database", c.database, "type", ql.Query)
 
+	if m, ok := c.metrics.(counterMetrics); ok {
+		labels := []string{"datasource", "mongo", "database", c.database, "operation", ql.Query}
+
+		m.IncrementCounter(context.Background(), metricOperations, labels...)
+
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			m.IncrementCounter(context.Background(), metricErrors, labels...)
+		}
+
+		c.metrics.RecordHistogram(context.Background(), metricDuration, float64(duration)/1000, labels...)
+	}
+
 	if span != nil {
 		defer span.End()
 
@@ -374,6 +439,12 @@ func (c *Client) HealthCheck(ctx context.Context) (any, error) {
 	h.Details["host"] = c.uri
 	h.Details["database"] = c.database
 
+	if c.Database == nil {
+		h.Status = "DOWN"
+
+		return &h, errStatusDown
+	}
+
 	err := c.Database.Client().Ping(ctx, readpref.Primary())
 	if err != nil {
 		h.Status = "DOWN"
@@ -387,9 +458,10 @@ func (c *Client) HealthCheck(ctx context.Context) (any, error) {
 }
 
 func (c *Client) StartSession() (any, error) {
-	defer c.sendOperationStats(&QueryLog{Query: "startSession"}, time.Now(), "", nil)
-
 	s, err := c.Client().StartSession()
+
+	defer c.sendOperationStats(&QueryLog{Query: "startSession"}, time.Now(), "", nil, err)
+
 	ses := &session{s}
 
 	return ses, err
@@ -2,7 +2,9 @@ package mongo
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"testing"
 	"time"
 
@@ -215,9 +217,9 @@ func Test_InsertCommands(t *testing.T) {
 	cl := Client{metrics: metrics, tracer: otel.GetTracerProvider().Tracer("kite-mongo")}
 
 	metrics.EXPECT().RecordHistogram(context.Background(), "app_mongo_stats", gomock.Any(), "hostname",
-		gomock.Any(), "database", gomock.Any(), "type", gomock.Any()).Times(3)
+		gomock.Any(), "database", gomock.Any(), "type", gomock.Any()).Times(4)
 
-	logger.EXPECT().Debug(gomock.Any()).Times(3)
+	logger.EXPECT().Debug(gomock.Any()).Times(4)
 
 	cl.logger = logger
 
@@ -320,9 +322,9 @@ func Test_FindMultipleCommands(t *testing.T) {
 	cl := Client{metrics: metrics, tracer: otel.GetTracerProvider().Tracer("kite-mongo")}
 
 	metrics.EXPECT().RecordHistogram(context.Background(), "app_mongo_stats", gomock.Any(), "hostname",
-		gomock.Any(), "database", gomock.Any(), "type", gomock.Any())
+		gomock.Any(), "database", gomock.Any(), "type", gomock.Any()).Times(3)
 
-	logger.EXPECT().Debug(gomock.Any())
+	logger.EXPECT().Debug(gomock.Any()).Times(3)
 
 	cl.logger = logger
 
@@ -394,9 +396,9 @@ func Test_FindOneCommands(t *testing.T) {
 	cl := Client{metrics: metrics, tracer: otel.GetTracerProvider().Tracer("kite-mongo")}
 
 	metrics.EXPECT().RecordHistogram(context.Background(), "app_mongo_stats", gomock.Any(), "hostname",
-		gomock.Any(), "database", gomock.Any(), "type", gomock.Any())
+		gomock.Any(), "database", gomock.Any(), "type", gomock.Any()).Times(2)
 
-	logger.EXPECT().Debug(gomock.Any())
+	logger.EXPECT().Debug(gomock.Any()).Times(2)
 
 	cl.logger = logger
 
@@ -553,9 +555,9 @@ func Test_DeleteCommands(t *testing.T) {
 	cl := Client{metrics: metrics, tracer: otel.GetTracerProvider().Tracer("kite-mongo")}
 
 	metrics.EXPECT().RecordHistogram(context.Background(), "app_mongo_stats", gomock.Any(), "hostname",
-		gomock.Any(), "database", gomock.Any(), "type", gomock.Any()).Times(2)
+		gomock.Any(), "database", gomock.Any(), "type", gomock.Any()).Times(4)
 
-	logger.EXPECT().Debug(gomock.Any()).Times(2)
+	logger.EXPECT().Debug(gomock.Any()).Times(4)
 
 	cl.logger = logger
 
@@ -733,3 +735,55 @@ func Test_HealthCheck(t *testing.T) {
 		assert.Contains(t, fmt.Sprint(resp), "DOWN")
 	})
 }
+
+func Test_HealthCheck_NotConnected(t *testing.T) {
+	cl := Client{}
+
+	resp, err := cl.HealthCheck(context.Background())
+
+	require.ErrorIs(t, err, errStatusDown)
+	assert.Contains(t, fmt.Sprint(resp), "DOWN")
+	require.NoError(t, cl.Close(), "closing a client which is not connected")
+}
+
+// counters records the counters incremented by a Client, as the kite metrics manager which implements counterMetrics.
+type counters struct {
+	*MockMetrics
+
+	incremented []string
+}
+
+func (c *counters) IncrementCounter(_ context.Context, name string, _ ...string) {
+	c.incremented = append(c.incremented, name)
+}
+
+func Test_SendOperationStats_Errors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	metrics := &counters{MockMetrics: NewMockMetrics(ctrl)}
+	metrics.EXPECT().RecordHistogram(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	logger := NewMockLogger(ctrl)
+	logger.EXPECT().Debug(gomock.Any()).AnyTimes()
+
+	cl := Client{metrics: metrics, logger: logger, database: "test"}
+
+	testCases := []struct {
+		desc   string
+		err    error
+		failed bool
+	}{
+		{"success", nil, false},
+		{"failure", errors.New("connection reset"), true},
+		{"no document found", mongo.ErrNoDocuments, false},
+	}
+
+	for i, tc := range testCases {
+		metrics.incremented = nil
+
+		cl.sendOperationStats(&QueryLog{Query: "findOne", Collection: "users"}, time.Now(), "findOne", nil, tc.err)
+
+		assert.Containsf(t, metrics.incremented, metricOperations, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equalf(t, tc.failed, slices.Contains(metrics.incremented, metricErrors), "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
@@ -0,0 +1,235 @@
+package mongo
+
+import (
+	"context"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Pipeline is an aggregation pipeline, built stage by stage, see NewPipeline.
+type Pipeline struct {
+	stages mongo.Pipeline
+}
+
+/*
+NewPipeline starts an aggregation pipeline, which is passed to Aggregate. The filters and the projections are Go values
+marshaled with their bson tags, e.g. a struct, a bson.M or a bson.D:
+
+	pipeline := mongo.NewPipeline().
+		Match(bson.M{"status": "paid"}).
+		Group("$customer_id", mongo.Sum("total", "$amount"), mongo.Count("orders")).
+		Sort(mongo.Desc("total")).
+		Limit(10)
+
+	var top []struct {
+		CustomerID string  `bson:"_id"`
+		Total      float64 `bson:"total"`
+		Orders     int     `bson:"orders"`
+	}
+
+	err := ctx.Mongo.Aggregate(ctx, "orders", pipeline, &top)
+*/
+func NewPipeline() *Pipeline {
+	return &Pipeline{}
+}
+
+// Stage adds a stage without a helper, e.g. Stage("$sample", bson.M{"size": 5}).
+func (p *Pipeline) Stage(operator string, value any) *Pipeline {
+	p.stages = append(p.stages, bson.D{{Key: operator, Value: value}})
+
+	return p
+}
+
+// Match filters the documents with filter, a query as accepted by Find.
+func (p *Pipeline) Match(filter any) *Pipeline {
+	return p.Stage("$match", filter)
+}
+
+// Group groups the documents by the id expression, e.g. "$customer_id" or nil for a single group, and computes the
+// accumulators for each group. The id of each group is its _id field.
+func (p *Pipeline) Group(id any, accumulators ...Accumulator) *Pipeline {
+	group := bson.D{{Key: "_id", Value: id}}
+
+	for _, acc := range accumulators {
+		group = append(group, bson.E{Key: acc.Field, Value: bson.D{{Key: acc.Operator, Value: acc.Expression}}})
+	}
+
+	return p.Stage("$group", group)
+}
+
+// Sort sorts the documents by the fields, in order.
+func (p *Pipeline) Sort(fields ...SortField) *Pipeline {
+	sort := make(bson.D, 0, len(fields))
+
+	for _, f := range fields {
+		sort = append(sort, bson.E{Key: f.Field, Value: f.Order})
+	}
+
+	return p.Stage("$sort", sort)
+}
+
+// Lookup joins the documents of the collection from whose foreignField equals the localField of each document, as the
+// array field as.
+func (p *Pipeline) Lookup(from, localField, foreignField, as string) *Pipeline {
+	return p.Stage("$lookup", bson.D{
+		{Key: "from", Value: from},
+		{Key: "localField", Value: localField},
+		{Key: "foreignField", Value: foreignField},
+		{Key: "as", Value: as},
+	})
+}
+
+// Unwind outputs a document for each element of the array field at path, e.g. "$items".
+func (p *Pipeline) Unwind(path string) *Pipeline {
+	return p.Stage("$unwind", path)
+}
+
+// Project reshapes the documents with the projection, e.g. bson.M{"name": 1, "total": "$amount"}.
+func (p *Pipeline) Project(projection any) *Pipeline {
+	return p.Stage("$project", projection)
+}
+
+// Skip skips the first n documents.
+func (p *Pipeline) Skip(n int64) *Pipeline {
+	return p.Stage("$skip", n)
+}
+
+// Limit limits the number of documents to n.
+func (p *Pipeline) Limit(n int64) *Pipeline {
+	return p.Stage("$limit", n)
+}
+
+// Stages returns the stages of the pipeline.
+func (p *Pipeline) Stages() mongo.Pipeline {
+	return p.stages
+}
+
+// Len returns the number of stages of the pipeline.
+func (p *Pipeline) Len() int {
+	return len(p.stages)
+}
+
+// Accumulator computes a field of the groups of a Group stage.
+type Accumulator struct {
+	Field      string
+	Operator   string
+	Expression any
+}
+
+// Sum sums the expression, e.g. "$amount", over the documents of the group.
+func Sum(field string, expression any) Accumulator {
+	return Accumulator{Field: field, Operator: "$sum", Expression: expression}
+}
+
+// Count counts the documents of the group.
+func Count(field string) Accumulator {
+	return Sum(field, 1)
+}
+
+// Avg averages the expression over the documents of the group.
+func Avg(field string, expression any) Accumulator {
+	return Accumulator{Field: field, Operator: "$avg", Expression: expression}
+}
+
+// Min returns the minimum of the expression over the documents of the group.
+func Min(field string, expression any) Accumulator {
+	return Accumulator{Field: field, Operator: "$min", Expression: expression}
+}
+
+// Max returns the maximum of the expression over the documents of the group.
+func Max(field string, expression any) Accumulator {
+	return Accumulator{Field: field, Operator: "$max", Expression: expression}
+}
+
+// First returns the expression for the first document of the group, in the order of the previous Sort stage.
+func First(field string, expression any) Accumulator {
+	return Accumulator{Field: field, Operator: "$first", Expression: expression}
+}
+
+// Push collects the expression of the documents of the group into an array.
+func Push(field string, expression any) Accumulator {
+	return Accumulator{Field: field, Operator: "$push", Expression: expression}
+}
+
+// SortField is a field of a Sort stage, see Asc and Desc.
+type SortField struct {
+	Field string
+	Order int
+}
+
+// Asc sorts by field in ascending order.
+func Asc(field string) SortField {
+	return SortField{Field: field, Order: 1}
+}
+
+// Desc sorts by field in descending order.
+func Desc(field string) SortField {
+	return SortField{Field: field, Order: -1}
+}
+
+// Cursor is the cursor of a query, as returned by AggregateCursor.
+type Cursor interface {
+	Next(ctx context.Context) bool
+	Decode(val any) error
+	Err() error
+	Close(ctx context.Context) error
+}
+
+// DecodeAll decodes the remaining documents of the cursor into values of type T, using their bson tags, and closes the
+// cursor. A cursor without documents decodes into an empty slice.
+func DecodeAll[T any](ctx context.Context, cur Cursor) ([]T, error) {
+	defer cur.Close(ctx)
+
+	results := make([]T, 0)
+
+	for cur.Next(ctx) {
+		var v T
+		if err := cur.Decode(&v); err != nil {
+			return nil, err
+		}
+
+		results = append(results, v)
+	}
+
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// DecodeOne decodes the next document of the cursor into a value of type T, and closes the cursor. It returns
+// mongo.ErrNoDocuments when the cursor has no documents.
+func DecodeOne[T any](ctx context.Context, cur Cursor) (T, error) {
+	defer cur.Close(ctx)
+
+	var v T
+
+	if !cur.Next(ctx) {
+		if err := cur.Err(); err != nil {
+			return v, err
+		}
+
+		return v, mongo.ErrNoDocuments
+	}
+
+	err := cur.Decode(&v)
+
+	return v, err
+}
+
+// pipelineStages returns the pipeline passed to Aggregate and its number of stages. The pipelines not built with
+// NewPipeline, e.g. a mongo.Pipeline or a []bson.M, are passed to the driver as is.
+func pipelineStages(pipeline any) (stages any, count int) {
+	if p, ok := pipeline.(*Pipeline); ok {
+		return p.Stages(), p.Len()
+	}
+
+	if v := reflect.ValueOf(pipeline); v.Kind() == reflect.Slice {
+		return pipeline, v.Len()
+	}
+
+	return pipeline, 0
+}
@@ -0,0 +1,260 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/mock/gomock"
+)
+
+var errCursor = errors.New("cursor failed")
+
+func TestPipeline_Stages(t *testing.T) {
+	type statusFilter struct {
+		Status string `bson:"status"`
+	}
+
+	pipeline := NewPipeline().
+		Match(statusFilter{Status: "paid"}).
+		Lookup("customers", "customer_id", "_id", "customer").
+		Unwind("$customer").
+		Group("$customer.country", Sum("total", "$amount"), Count("orders"), Avg("avg", "$amount"),
+			Min("min", "$amount"), Max("max", "$amount"), First("first", "$created_at"), Push("ids", "$_id")).
+		Sort(Desc("total"), Asc("_id")).
+		Project(bson.M{"total": 1}).
+		Skip(5).
+		Limit(10).
+		Stage("$sample", bson.M{"size": 2})
+
+	expected := mongo.Pipeline{
+		{{Key: "$match", Value: statusFilter{Status: "paid"}}},
+		{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: "customers"},
+			{Key: "localField", Value: "customer_id"},
+			{Key: "foreignField", Value: "_id"},
+			{Key: "as", Value: "customer"},
+		}}},
+		{{Key: "$unwind", Value: "$customer"}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$customer.country"},
+			{Key: "total", Value: bson.D{{Key: "$sum", Value: "$amount"}}},
+			{Key: "orders", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "avg", Value: bson.D{{Key: "$avg", Value: "$amount"}}},
+			{Key: "min", Value: bson.D{{Key: "$min", Value: "$amount"}}},
+			{Key: "max", Value: bson.D{{Key: "$max", Value: "$amount"}}},
+			{Key: "first", Value: bson.D{{Key: "$first", Value: "$created_at"}}},
+			{Key: "ids", Value: bson.D{{Key: "$push", Value: "$_id"}}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "total", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$project", Value: bson.M{"total": 1}}},
+		{{Key: "$skip", Value: int64(5)}},
+		{{Key: "$limit", Value: int64(10)}},
+		{{Key: "$sample", Value: bson.M{"size": 2}}},
+	}
+
+	assert.Equal(t, expected, pipeline.Stages())
+	assert.Equal(t, 9, pipeline.Len())
+}
+
+func TestPipelineStages(t *testing.T) {
+	built := NewPipeline().Match(bson.M{}).Limit(1)
+	raw := []bson.M{{"$match": bson.M{}}}
+
+	tests := []struct {
+		desc     string
+		pipeline any
+		stages   any
+		count    int
+	}{
+		{"built", built, built.Stages(), 2},
+		{"driver pipeline", mongo.Pipeline{{{Key: "$limit", Value: 1}}}, mongo.Pipeline{{{Key: "$limit", Value: 1}}}, 1},
+		{"slice of maps", raw, raw, 1},
+		{"not a slice", bson.M{}, bson.M{}, 0},
+	}
+
+	for i, tc := range tests {
+		stages, count := pipelineStages(tc.pipeline)
+
+		assert.Equal(t, tc.stages, stages, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, tc.count, count, "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+type order struct {
+	ID    string  `bson:"_id"`
+	Total float64 `bson:"total"`
+}
+
+type fakeCursor struct {
+	docs      []bson.D
+	pos       int
+	decodeErr error
+	err       error
+	closed    bool
+}
+
+func (c *fakeCursor) Next(context.Context) bool {
+	if c.pos >= len(c.docs) {
+		return false
+	}
+
+	c.pos++
+
+	return true
+}
+
+func (c *fakeCursor) Decode(val any) error {
+	if c.decodeErr != nil {
+		return c.decodeErr
+	}
+
+	b, err := bson.Marshal(c.docs[c.pos-1])
+	if err != nil {
+		return err
+	}
+
+	return bson.Unmarshal(b, val)
+}
+
+func (c *fakeCursor) Err() error {
+	return c.err
+}
+
+func (c *fakeCursor) Close(context.Context) error {
+	c.closed = true
+
+	return nil
+}
+
+func TestDecodeAll(t *testing.T) {
+	cur := &fakeCursor{docs: []bson.D{
+		{{Key: "_id", Value: "a"}, {Key: "total", Value: 12.5}},
+		{{Key: "_id", Value: "b"}, {Key: "total", Value: 3.0}},
+	}}
+
+	orders, err := DecodeAll[order](context.Background(), cur)
+
+	require.NoError(t, err)
+	assert.Equal(t, []order{{ID: "a", Total: 12.5}, {ID: "b", Total: 3}}, orders)
+	assert.True(t, cur.closed)
+
+	pointers, err := DecodeAll[*order](context.Background(), &fakeCursor{docs: cur.docs})
+
+	require.NoError(t, err)
+	require.Len(t, pointers, 2)
+	assert.Equal(t, "b", pointers[1].ID)
+}
+
+func TestDecodeAll_Empty(t *testing.T) {
+	orders, err := DecodeAll[order](context.Background(), &fakeCursor{})
+
+	require.NoError(t, err)
+	assert.NotNil(t, orders)
+	assert.Empty(t, orders)
+}
+
+func TestDecodeAll_Errors(t *testing.T) {
+	docs := []bson.D{{{Key: "_id", Value: "a"}}}
+
+	tests := []struct {
+		desc   string
+		cursor *fakeCursor
+	}{
+		{"decode error", &fakeCursor{docs: docs, decodeErr: errCursor}},
+		{"cursor error", &fakeCursor{docs: docs, err: errCursor}},
+	}
+
+	for i, tc := range tests {
+		orders, err := DecodeAll[order](context.Background(), tc.cursor)
+
+		require.ErrorIs(t, err, errCursor, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Nil(t, orders, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.True(t, tc.cursor.closed, "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestDecodeOne(t *testing.T) {
+	cur := &fakeCursor{docs: []bson.D{{{Key: "_id", Value: "a"}, {Key: "total", Value: 1.5}}}}
+
+	o, err := DecodeOne[order](context.Background(), cur)
+
+	require.NoError(t, err)
+	assert.Equal(t, order{ID: "a", Total: 1.5}, o)
+	assert.True(t, cur.closed)
+
+	_, err = DecodeOne[order](context.Background(), &fakeCursor{})
+	require.ErrorIs(t, err, mongo.ErrNoDocuments)
+
+	_, err = DecodeOne[order](context.Background(), &fakeCursor{err: errCursor})
+	require.ErrorIs(t, err, errCursor)
+}
+
+func Test_AggregateCommands(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	metrics := NewMockMetrics(ctrl)
+	logger := NewMockLogger(ctrl)
+
+	cl := Client{metrics: metrics, logger: logger, tracer: otel.GetTracerProvider().Tracer("kite-mongo")}
+
+	metrics.EXPECT().RecordHistogram(context.Background(), "app_mongo_stats", gomock.Any(), "hostname",
+		gomock.Any(), "database", gomock.Any(), "type", "aggregate").AnyTimes()
+
+	logger.EXPECT().Debug(gomock.Any()).AnyTimes()
+
+	pipeline := NewPipeline().Group("$customer_id", Sum("total", "$amount"))
+
+	mt.Run("AggregateSuccess", func(mt *mtest.T) {
+		cl.Database = mt.DB
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.orders", mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: "a"}, {Key: "total", Value: 12.5}},
+			bson.D{{Key: "_id", Value: "b"}, {Key: "total", Value: 3.0}},
+		))
+
+		var orders []order
+
+		err := cl.Aggregate(context.Background(), mt.Coll.Name(), pipeline, &orders)
+
+		require.NoError(t, err)
+		assert.Equal(t, []order{{ID: "a", Total: 12.5}, {ID: "b", Total: 3}}, orders)
+	})
+
+	mt.Run("AggregateCursor", func(mt *mtest.T) {
+		cl.Database = mt.DB
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.orders", mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: "a"}, {Key: "total", Value: 12.5}},
+		))
+
+		cur, err := cl.AggregateCursor(context.Background(), mt.Coll.Name(), pipeline)
+		require.NoError(t, err)
+
+		orders, err := DecodeAll[order](context.Background(), cur)
+
+		require.NoError(t, err)
+		assert.Equal(t, []order{{ID: "a", Total: 12.5}}, orders)
+	})
+
+	mt.Run("AggregateError", func(mt *mtest.T) {
+		cl.Database = mt.DB
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 2, Message: "bad pipeline"}))
+
+		var orders []order
+
+		err := cl.Aggregate(context.Background(), mt.Coll.Name(), pipeline, &orders)
+
+		require.ErrorContains(t, err, "bad pipeline")
+	})
+}
@@ -5,6 +5,7 @@ package kafka
 import (
 	"context"
 	"errors"
+	"strconv"
 	"sync"
 	"time"
 
@@ -238,10 +239,24 @@ func (k *kafkaClient) Subscribe(ctx context.Context, topic string) (*pubsub.Mess
 	})
 
 	k.metrics.IncrementCounter(ctx, "app_pubsub_subscribe_success_count", "topic", topic, "consumer_group", k.config.ConsumerGroupID)
+	k.setConsumerLag(topic, &msg)
 
 	return m, err
 }
 
+// setConsumerLag reports the number of messages of the partition after msg, which the consumer group has yet to read.
+func (k *kafkaClient) setConsumerLag(topic string, msg *kafka.Message) {
+	g, ok := k.metrics.(gaugeMetrics)
+	if !ok || msg.HighWaterMark == 0 {
+		return
+	}
+
+	lag := max(msg.HighWaterMark-msg.Offset-1, 0)
+
+	g.SetGauge("app_pubsub_consumer_lag", float64(lag), "topic", topic, "consumer_group", k.config.ConsumerGroupID,
+		"partition", strconv.Itoa(msg.Partition))
+}
+
 func (k *kafkaClient) Close() (err error) {
 	for _, r := range k.reader {
 		err = errors.Join(err, r.Close())
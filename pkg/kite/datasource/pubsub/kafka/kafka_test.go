@@ -352,6 +352,57 @@ func TestKafkaClient_SubscribeSuccess(t *testing.T) {
 	assert.Contains(t, logs, "test")
 }
 
+type gaugeRecorder struct {
+	*MockMetrics
+
+	gauges map[string]float64
+	labels []string
+}
+
+func (g *gaugeRecorder) SetGauge(name string, value float64, labels ...string) {
+	g.gauges[name] = value
+	g.labels = labels
+}
+
+func TestKafkaClient_SubscribeConsumerLag(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockReader := NewMockReader(ctrl)
+	mockConnection := NewMockConnection(ctrl)
+	metrics := &gaugeRecorder{MockMetrics: NewMockMetrics(ctrl), gauges: make(map[string]float64)}
+
+	k := &kafkaClient{
+		dialer: &kafka.Dialer{},
+		reader: map[string]Reader{
+			"test": mockReader,
+		},
+		conn: &multiConn{
+			conns: []Connection{
+				mockConnection,
+			},
+		},
+		logger: logging.NewMockLogger(logging.ERROR),
+		config: Config{
+			ConsumerGroupID: "consumer",
+			Brokers:         []string{"kafkabroker"},
+			OffSet:          -1,
+		},
+		mu:      &sync.RWMutex{},
+		metrics: metrics,
+	}
+
+	mockConnection.EXPECT().Controller().Return(kafka.Broker{}, nil)
+	mockReader.EXPECT().FetchMessage(gomock.Any()).
+		Return(kafka.Message{Value: []byte(`hello`), Topic: "test", Partition: 2, Offset: 40, HighWaterMark: 50}, nil)
+	metrics.EXPECT().IncrementCounter(gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+
+	_, err := k.Subscribe(t.Context(), "test")
+
+	require.NoError(t, err)
+	assert.InDelta(t, 9, metrics.gauges["app_pubsub_consumer_lag"], 0)
+	assert.Equal(t, []string{"topic", "test", "consumer_group", "consumer", "partition", "2"}, metrics.labels)
+}
+
 func TestKafkaClient_Subscribe_ErrConsumerGroupID(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
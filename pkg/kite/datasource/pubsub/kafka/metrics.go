@@ -5,3 +5,8 @@ import "context"
 type Metrics interface {
 	IncrementCounter(ctx context.Context, name string, labels ...string)
 }
+
+// gaugeMetrics is implemented by the metrics of the container, it is used to report the consumer lag.
+type gaugeMetrics interface {
+	SetGauge(name string, value float64, labels ...string)
+}
@@ -3,6 +3,8 @@ package pubsub
 import (
 	"fmt"
 	"io"
+
+	"github.com/sllt/kite/pkg/kite/logging"
 )
 
 type Log struct {
@@ -19,3 +21,11 @@ func (l *Log) PrettyPrint(writer io.Writer) {
 	fmt.Fprintf(writer, "\u001B[38;5;8m%-32s \u001B[38;5;24m%-6s\u001B[0m %8d\u001B[38;5;8mµs\u001B[0m %-4s %s \u001b[38;5;101m%s\u001b[0m\n",
 		l.CorrelationID, l.PubSubBackend, l.Time, l.Mode, l.Topic, l.MessageValue)
 }
+
+// Redact implements logging.Redactable, masking the fields of JSON payloads and the sensitive values of others.
+func (l *Log) Redact(r *logging.Redactor) any {
+	c := *l
+	c.MessageValue = r.Payload(l.MessageValue)
+
+	return &c
+}
@@ -190,7 +190,7 @@ func (m *MQTT) Unsubscribe(topic string) error {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	token := m.Client.Unsubscribe(topic)
+	token := m.Client.Unsubscribe(m.config.subscriptionTopic(topic))
 	token.Wait()
 
 	if token.Error() != nil {
@@ -266,7 +266,7 @@ func createReconnectHandler(mu *sync.RWMutex, config *Config, subs map[string]su
 		defer mu.RUnlock()
 
 		for topic, sub := range subs {
-			token := client.Subscribe(topic, config.QoS, sub.handler)
+			token := client.Subscribe(config.subscriptionTopic(topic), config.QoS, sub.handler)
 			if token.Wait() && token.Error() != nil {
 				logger.Debugf("failed to resubscribe to topic %s: %v", topic, token.Error())
 			} else {
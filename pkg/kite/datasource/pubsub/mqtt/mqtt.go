@@ -47,6 +47,10 @@ type MQTT struct {
 	mu            *sync.RWMutex
 }
 
+// Config holds the settings of the MQTT client. When SharedGroup is set, Subscribe joins the shared subscription
+// "$share/<SharedGroup>/<topic>", so that each message is delivered to only one subscriber of the group. The client
+// only speaks MQTT 3.1.1, MQTT 5 is not supported: shared subscriptions require a broker accepting them from MQTT 3.1.1
+// clients, e.g. EMQX, HiveMQ or Mosquitto 2.
 type Config struct {
 	Protocol         string
 	Hostname         string
@@ -59,6 +63,17 @@ type Config struct {
 	RetrieveRetained bool
 	KeepAlive        time.Duration
 	CloseTimeout     time.Duration
+	SharedGroup      string
+}
+
+// subscriptionTopic returns the topic filter Subscribe uses for topic, prefixed with the shared subscription group
+// if one is configured.
+func (c *Config) subscriptionTopic(topic string) string {
+	if c.SharedGroup == "" {
+		return topic
+	}
+
+	return "$share/" + c.SharedGroup + "/" + topic
 }
 
 type subscription struct {
@@ -110,7 +125,7 @@ func (m *MQTT) Subscribe(ctx context.Context, topic string) (*pubsub.Message, er
 	if !ok {
 		subs.msgs = make(chan *pubsub.Message, messageBuffer)
 		subs.handler = m.createMqttHandler(ctx, topic, subs.msgs)
-		token := m.Client.Subscribe(topic, m.config.QoS, subs.handler)
+		token := m.Client.Subscribe(m.config.subscriptionTopic(topic), m.config.QoS, subs.handler)
 
 		if token.Wait() && token.Error() != nil {
 			m.mu.Unlock()
@@ -250,6 +250,39 @@ func TestMQTT_SubscribeSuccess(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestMQTT_SharedSubscription(t *testing.T) {
+	conf := *mockConfigs
+	conf.SharedGroup = "workers"
+
+	ctrl, client, mockClient, _, mockToken := getMockMQTT(t, &conf)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	mockClient.EXPECT().IsConnected().Return(true)
+	mockClient.EXPECT().Subscribe("$share/workers/test/topic", conf.QoS, gomock.Any()).Return(mockToken)
+	mockClient.EXPECT().Unsubscribe("$share/workers/test/topic").Return(mockToken)
+	mockToken.EXPECT().Wait().Return(true).Times(2)
+	mockToken.EXPECT().Error().Return(nil).Times(2)
+
+	m, err := client.Subscribe(ctx, "test/topic")
+	require.NoError(t, err)
+	assert.Nil(t, m)
+
+	// the subscription is tracked by the plain topic, which is also what Unsubscribe takes
+	_, ok := client.subscriptions["test/topic"]
+	assert.True(t, ok)
+
+	require.NoError(t, client.Unsubscribe("test/topic"))
+	assert.Empty(t, client.subscriptions)
+}
+
+func TestConfig_subscriptionTopic(t *testing.T) {
+	assert.Equal(t, "test/topic", (&Config{}).subscriptionTopic("test/topic"))
+	assert.Equal(t, "$share/g1/test/topic", (&Config{SharedGroup: "g1"}).subscriptionTopic("test/topic"))
+}
+
 func TestMQTT_SubscribeFailure(t *testing.T) {
 	ctrl, client, mockClient, _, mockToken := getMockMQTT(t, mockConfigs)
 	defer ctrl.Finish()
@@ -28,6 +28,7 @@ const (
 	defaultQueryTimeout  = 30 * time.Second
 	defaultMaxBytes      = 100 * 1024 * 1024
 	defaultAckWait       = 30 * time.Second
+	defaultStreamTimeout = 10 * time.Second
 )
 
 // Client represents a Client for NATS jStream operations.
@@ -27,11 +27,35 @@ func (c *Client) establishConnection() error {
 	c.streamManager = newStreamManager(js, c.logger)
 	c.subManager = newSubscriptionManager(batchSize)
 
+	if c.Config.AutoCreateStream {
+		if err := c.provisionStream(); err != nil {
+			c.connManager.Close(context.Background())
+
+			return err
+		}
+	}
+
 	c.logger.Logf("connected to NATS server '%s'", c.Config.Server)
 
 	return nil
 }
 
+// provisionStream creates the stream of the config, or updates it if it already exists.
+func (c *Client) provisionStream() error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultStreamTimeout)
+	defer cancel()
+
+	jsCfg := jetStreamConfig(&c.Config.Stream)
+
+	if _, err := c.streamManager.CreateOrUpdateStream(ctx, &jsCfg); err != nil {
+		return err
+	}
+
+	c.logger.Debugf("provisioned stream %s for subjects %v", jsCfg.Name, jsCfg.Subjects)
+
+	return nil
+}
+
 func (c *Client) retryConnect() {
 	for {
 		c.logger.Debugf("connecting to NATS server at %v", c.Config.Server)
@@ -65,6 +89,7 @@ func (c *Client) createOrUpdateConsumer(
 		AckPolicy:     jetstream.AckExplicitPolicy,
 		FilterSubject: subject,
 		MaxDeliver:    c.Config.Stream.MaxDeliver,
+		BackOff:       c.Config.Stream.BackOff,
 		DeliverPolicy: jetstream.DeliverNewPolicy,
 	})
 	if err != nil {
@@ -124,7 +149,7 @@ func (c *Client) handleMessage(ctx context.Context, msg jetstream.Msg, handler m
 
 	c.logger.Errorf("Error handling message: %v", err)
 
-	if nakErr := msg.Nak(); nakErr != nil {
+	if nakErr := nak(msg, c.Config.Stream.BackOff); nakErr != nil {
 		c.logger.Debugf("Error sending NAK for message: %v", nakErr)
 
 		return nakErr
@@ -133,6 +158,20 @@ func (c *Client) handleMessage(ctx context.Context, msg jetstream.Msg, handler m
 	return err
 }
 
+// nak negatively acknowledges msg, it is redelivered after the backoff delay of its delivery attempt when set.
+func nak(msg jetstream.Msg, backOff []time.Duration) error {
+	if len(backOff) == 0 {
+		return msg.Nak()
+	}
+
+	attempt := 0
+	if md, err := msg.Metadata(); err == nil && md.NumDelivered > 0 {
+		attempt = int(md.NumDelivered) - 1
+	}
+
+	return msg.NakWithDelay(backOff[min(attempt, len(backOff)-1)])
+}
+
 // parseQueryArgs parses the query arguments.
 func parseQueryArgs(args ...any) (timeout time.Duration, limit int) {
 	// Default values
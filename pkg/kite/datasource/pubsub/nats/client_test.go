@@ -415,6 +415,60 @@ func TestClient_ValidateAndPrepare(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestClient_ValidateAndPrepare_BackOff(t *testing.T) {
+	logger := logging.NewMockLogger(logging.DEBUG)
+	cfg := &Config{
+		Server: "nats://localhost:4222",
+		Stream: StreamConfig{
+			Stream:     "test-stream",
+			Subjects:   []string{"test-subject"},
+			MaxDeliver: 2,
+			BackOff:    []time.Duration{time.Second, 5 * time.Second},
+		},
+		Consumer: "test-consumer",
+	}
+
+	require.ErrorIs(t, validateAndPrepare(cfg, logger), errInvalidBackOff)
+
+	cfg.Stream.MaxDeliver = 3
+	require.NoError(t, validateAndPrepare(cfg, logger))
+
+	// MaxDeliver is unlimited when not set.
+	cfg.Stream.MaxDeliver = 0
+	assert.NoError(t, validateAndPrepare(cfg, logger))
+}
+
+func Test_nak(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	backOff := []time.Duration{time.Second, 5 * time.Second}
+
+	tests := []struct {
+		desc         string
+		numDelivered uint64
+		delay        time.Duration
+	}{
+		{"first delivery", 1, time.Second},
+		{"second delivery", 2, 5 * time.Second},
+		{"deliveries after the last delay", 5, 5 * time.Second},
+	}
+
+	for i, tc := range tests {
+		msg := NewMockMsg(ctrl)
+		msg.EXPECT().Metadata().Return(&jetstream.MsgMetadata{NumDelivered: tc.numDelivered}, nil)
+		msg.EXPECT().NakWithDelay(tc.delay).Return(nil)
+
+		assert.NoError(t, nak(msg, backOff), "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+
+	// Without backoff the message is redelivered immediately.
+	msg := NewMockMsg(ctrl)
+	msg.EXPECT().Nak().Return(nil)
+
+	assert.NoError(t, nak(msg, nil))
+}
+
 func TestClient_ValidateAndPrepareError(t *testing.T) {
 	client := &Client{
 		Config: &Config{},
@@ -995,6 +1049,50 @@ func TestClient_establishConnection(t *testing.T) {
 	}
 }
 
+func TestClient_establishConnection_AutoCreateStream(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockNATSConnector := NewMockNATSConnector(ctrl)
+	mockJSCreator := NewMockJetStreamCreator(ctrl)
+	mockConn := NewMockConnInterface(ctrl)
+	mockJS := NewMockJetStream(ctrl)
+
+	client := &Client{
+		Config: &Config{
+			Server: "nats://localhost:4222",
+			Stream: StreamConfig{
+				Stream:   "orders",
+				Subjects: []string{"orders.*"},
+				Storage:  "file",
+				MaxAge:   time.Hour,
+			},
+			Consumer:         "test_consumer",
+			AutoCreateStream: true,
+		},
+		logger:           logging.NewMockLogger(logging.DEBUG),
+		natsConnector:    mockNATSConnector,
+		jetStreamCreator: mockJSCreator,
+	}
+
+	expectedCfg := jetstream.StreamConfig{
+		Name:     "orders",
+		Subjects: []string{"orders.*"},
+		Storage:  jetstream.FileStorage,
+		MaxAge:   time.Hour,
+	}
+
+	mockNATSConnector.EXPECT().Connect(client.Config.Server, gomock.Any()).Return(mockConn, nil).Times(2)
+	mockJSCreator.EXPECT().New(mockConn).Return(mockJS, nil).Times(2)
+
+	mockJS.EXPECT().CreateOrUpdateStream(gomock.Any(), expectedCfg).Return(nil, nil)
+	require.NoError(t, client.establishConnection())
+
+	mockJS.EXPECT().CreateOrUpdateStream(gomock.Any(), expectedCfg).Return(nil, errCreateOrUpdateStream)
+	mockConn.EXPECT().Close()
+	assert.ErrorIs(t, client.establishConnection(), errCreateOrUpdateStream)
+}
+
 func TestClient_Query_Success(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
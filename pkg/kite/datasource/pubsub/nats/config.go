@@ -16,13 +16,19 @@ type Config struct {
 	Consumer    string
 	MaxWait     time.Duration
 	MaxPullWait int
+	// AutoCreateStream creates the stream with the Stream config when connecting, or updates it if it already exists.
+	AutoCreateStream bool
 }
 
 // StreamConfig holds stream settings for NATS jStream.
+//
+// BackOff are the delays before a message which was not acknowledged is redelivered, the last one is used for the
+// following deliveries. MaxDeliver must be greater than the number of delays when set.
 type StreamConfig struct {
 	Stream     string
 	Subjects   []string
 	MaxDeliver int
+	BackOff    []time.Duration
 	MaxWait    time.Duration
 	MaxBytes   int64
 	Storage    string
@@ -59,5 +65,9 @@ func validateConfigs(conf *Config) error {
 		return errConsumerNotProvided
 	}
 
+	if len(conf.Stream.BackOff) > 0 && conf.Stream.MaxDeliver > 0 && conf.Stream.MaxDeliver <= len(conf.Stream.BackOff) {
+		return errInvalidBackOff
+	}
+
 	return nil
 }
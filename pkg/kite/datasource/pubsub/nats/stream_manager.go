@@ -25,6 +25,22 @@ func newStreamManager(js jetstream.JetStream, logger pubsub.Logger) *StreamManag
 
 // CreateStream creates a new jStream stream.
 func (sm *StreamManager) CreateStream(ctx context.Context, cfg *StreamConfig) error {
+	_, err := sm.js.CreateStream(ctx, jetStreamConfig(cfg))
+	if err != nil {
+		if strings.Contains(err.Error(), "stream name already in use") {
+			return nil
+		}
+
+		sm.logger.Errorf("failed to create stream: %v", err)
+
+		return err
+	}
+
+	return nil
+}
+
+// jetStreamConfig converts cfg to the config of a jStream stream.
+func jetStreamConfig(cfg *StreamConfig) jetstream.StreamConfig {
 	jsCfg := jetstream.StreamConfig{
 		Name:     cfg.Stream,
 		Subjects: cfg.Subjects,
@@ -51,18 +67,7 @@ func (sm *StreamManager) CreateStream(ctx context.Context, cfg *StreamConfig) er
 		}
 	}
 
-	_, err := sm.js.CreateStream(ctx, jsCfg)
-	if err != nil {
-		if strings.Contains(err.Error(), "stream name already in use") {
-			return nil
-		}
-
-		sm.logger.Errorf("failed to create stream: %v", err)
-
-		return err
-	}
-
-	return nil
+	return jsCfg
 }
 
 // DeleteStream deletes a jStream stream.
@@ -113,6 +113,7 @@ func (*SubscriptionManager) createOrUpdateConsumer(
 		AckPolicy:     jetstream.AckExplicitPolicy,
 		FilterSubject: topic,
 		MaxDeliver:    cfg.Stream.MaxDeliver,
+		BackOff:       cfg.Stream.BackOff,
 		DeliverPolicy: jetstream.DeliverNewPolicy,
 		AckWait:       defaultAckWait,
 	})
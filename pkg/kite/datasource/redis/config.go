@@ -23,6 +23,7 @@ const (
 	defaultPubSubBufferSize   = 100
 	defaultPubSubQueryLimit   = 10
 	defaultPubSubQueryTimeout = 5 * time.Second
+	defaultStreamsClaimIdle   = 5 * time.Minute
 )
 
 // getRedisConfig builds the Redis Config struct from the provided [Config].
@@ -33,6 +34,8 @@ const (
 //	REDIS_TLS_CERT:    PEM-encoded client certificate (string or file path)
 //	REDIS_TLS_KEY:     PEM-encoded client private key (string or file path)
 //
+// The topology of the servers is configured with REDIS_MODE, see parseTopology.
+//
 // If TLS is enabled, the function sets up the [tls.Config] for the [Redis] client.
 func getRedisConfig(c config.Config, logger datasource.Logger) *Config {
 	var redisConfig = &Config{}
@@ -55,6 +58,8 @@ func getRedisConfig(c config.Config, logger datasource.Logger) *Config {
 
 	redisConfig.DB = db
 
+	parseTopology(c, redisConfig, logger)
+
 	options := new(redis.Options)
 	options.Addr = fmt.Sprintf("%s:%d", redisConfig.HostName, redisConfig.Port)
 	options.Username = redisConfig.Username
@@ -162,6 +167,7 @@ func configStreams(c config.Config, redisConfig *Config) {
 		ConsumerGroup: c.Get("REDIS_STREAMS_CONSUMER_GROUP"),
 		ConsumerName:  c.Get("REDIS_STREAMS_CONSUMER_NAME"),
 		PELRatio:      0.7, // Default: 70% PEL, 30% new messages
+		ClaimMinIdle:  defaultStreamsClaimIdle,
 	}
 
 	streamsConfig.Block = 1 * time.Second // default - reduced from 5s for better responsiveness
@@ -187,6 +193,13 @@ func configStreams(c config.Config, redisConfig *Config) {
 		}
 	}
 
+	// Parse the idle time after which pending messages of other consumers are claimed, 0 disables claiming
+	if claimStr := c.Get("REDIS_STREAMS_CLAIM_MIN_IDLE"); claimStr != "" {
+		if claim, err := time.ParseDuration(claimStr); err == nil && claim >= 0 {
+			streamsConfig.ClaimMinIdle = claim
+		}
+	}
+
 	redisConfig.PubSubStreamsConfig = streamsConfig
 }
 
@@ -133,6 +133,33 @@ func TestGetRedisConfig_PubSubStreams_Defaults(t *testing.T) {
 	assert.Equal(t, int64(0), conf.PubSubStreamsConfig.MaxLen)
 	assert.Equal(t, 1*time.Second, conf.PubSubStreamsConfig.Block)     // Default block (reduced from 5s for better responsiveness)
 	assert.InEpsilon(t, 0.7, conf.PubSubStreamsConfig.PELRatio, 0.001) // Default PEL ratio
+	assert.Equal(t, 5*time.Minute, conf.PubSubStreamsConfig.ClaimMinIdle)
+}
+
+func TestGetRedisConfig_PubSubStreams_ClaimMinIdle(t *testing.T) {
+	tests := []struct {
+		desc     string
+		value    string
+		expected time.Duration
+	}{
+		{"custom idle time", "30s", 30 * time.Second},
+		{"claiming disabled", "0", 0},
+		{"invalid value falls back to default", "invalid", 5 * time.Minute},
+		{"negative value falls back to default", "-1s", 5 * time.Minute},
+	}
+
+	for i, tc := range tests {
+		conf := testGetRedisConfig(t, map[string]string{
+			"PUBSUB_BACKEND":               "REDIS",
+			"REDIS_HOST":                   "localhost",
+			"REDIS_PUBSUB_MODE":            "streams",
+			"REDIS_STREAMS_CONSUMER_GROUP": "mygroup",
+			"REDIS_STREAMS_CLAIM_MIN_IDLE": tc.value,
+		})
+
+		require.NotNil(t, conf.PubSubStreamsConfig)
+		assert.Equal(t, tc.expected, conf.PubSubStreamsConfig.ClaimMinIdle, "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
 }
 
 func TestGetRedisConfig_PubSubStreams_InvalidValues(t *testing.T) {
@@ -6,6 +6,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/sllt/kite/pkg/kite/datasource"
@@ -23,17 +24,22 @@ func (r *Redis) HealthCheck() datasource.Health {
 
 	h.Details["host"] = r.config.HostName + ":" + strconv.Itoa(r.config.Port)
 
+	if r.config.Mode != "" && r.config.Mode != modeStandalone {
+		h.Details["mode"] = r.config.Mode
+		h.Details["addrs"] = r.config.Addrs
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
 	defer cancel()
 
-	if r.Client == nil {
+	if r.UniversalClient == nil {
 		h.Status = datasource.StatusDown
 		h.Details["error"] = "redis not connected"
 
 		return h
 	}
 
-	info, err := r.InfoMap(ctx, "Stats").Result()
+	info, err := r.Info(ctx, "Stats").Result()
 	if err != nil {
 		h.Status = datasource.StatusDown
 		h.Details["error"] = err.Error()
@@ -42,11 +48,30 @@ func (r *Redis) HealthCheck() datasource.Health {
 	}
 
 	h.Status = datasource.StatusUp
-	h.Details["stats"] = info["Stats"]
+	h.Details["stats"] = parseInfo(info)
 
 	return h
 }
 
+// parseInfo parses the fields of a section of the INFO reply, one "name:value" per line. In cluster mode, the reply
+// is the one of a single node.
+func parseInfo(info string) map[string]string {
+	fields := make(map[string]string)
+
+	for _, line := range strings.Split(info, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if name, value, ok := strings.Cut(line, ":"); ok {
+			fields[name] = value
+		}
+	}
+
+	return fields
+}
+
 // Health returns the health status of the Redis PubSub connection.
 func (ps *PubSub) Health() datasource.Health {
 	res := datasource.Health{
@@ -2,9 +2,11 @@ package redis
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -64,8 +66,9 @@ func (ql *QueryLog) String() string {
 }
 
 // logQuery logs the Redis query information.
-func (r *redisHook) sendOperationStats(start time.Time, query string, args ...any) {
-	duration := time.Since(start).Microseconds()
+func (r *redisHook) sendOperationStats(start time.Time, query string, err error, args ...any) {
+	elapsed := time.Since(start)
+	duration := elapsed.Microseconds()
 
 	r.logger.Debug(&QueryLog{
 		Query:    query,
@@ -75,6 +78,15 @@ func (r *redisHook) sendOperationStats(start time.Time, query string, args ...an
 
 	r.metrics.RecordHistogram(context.Background(), "app_redis_stats",
 		float64(duration), "hostname", r.config.HostName, "type", query)
+
+	// a missing key is a result, not a failure.
+	if errors.Is(err, redis.Nil) {
+		err = nil
+	}
+
+	datasource.RecordOperation(context.Background(), r.metrics, datasource.Operation{
+		Datasource: "redis", Database: strconv.Itoa(r.config.DB), Name: query,
+	}, elapsed, err)
 }
 
 // DialHook implements the redis.DialHook interface.
@@ -87,7 +99,7 @@ func (r *redisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
 	return func(ctx context.Context, cmd redis.Cmder) error {
 		start := time.Now()
 		err := next(ctx, cmd)
-		r.sendOperationStats(start, cmd.Name(), cmd.Args()...)
+		r.sendOperationStats(start, cmd.Name(), err, cmd.Args()...)
 
 		return err
 	}
@@ -98,7 +110,7 @@ func (r *redisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.Pr
 	return func(ctx context.Context, cmds []redis.Cmder) error {
 		start := time.Now()
 		err := next(ctx, cmds)
-		r.sendOperationStats(start, "pipeline", cmds[:len(cmds)-1])
+		r.sendOperationStats(start, "pipeline", err, cmds[:len(cmds)-1])
 
 		return err
 	}
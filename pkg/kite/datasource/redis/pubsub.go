@@ -475,6 +475,12 @@ func (ps *PubSub) consumeStreamMessages(ctx context.Context, topic, group, consu
 		ps.readPendingMessages(ctx, topic, group, consumer, pelCount)
 	}
 
+	// Claim the messages left pending by other consumers, e.g. which crashed, after our own PEL was read as the
+	// claimed messages are added to it
+	if ps.shouldClaim(topic) {
+		ps.claimPendingMessages(ctx, topic, group, consumer, calculatePELCount(ps.getAvailableCapacity(topic), ratio))
+	}
+
 	// Re-check capacity and fill remaining with new messages
 	// This ensures remaining capacity is always used, regardless of ratio
 	available = ps.getAvailableCapacity(topic)
@@ -547,6 +553,88 @@ func (ps *PubSub) readPendingMessages(ctx context.Context, topic, group, consume
 	return true
 }
 
+// shouldClaim reports whether the pending messages of other consumers should be claimed, claiming is done at most
+// once per ClaimMinIdle for each topic as younger pending messages cannot be claimed anyway, unless the previous claim
+// stopped before the end of the pending messages.
+func (ps *PubSub) shouldClaim(topic string) bool {
+	if ps.config.PubSubStreamsConfig == nil || ps.config.PubSubStreamsConfig.ClaimMinIdle <= 0 {
+		return false
+	}
+
+	ps.mu.RLock()
+	last := ps.lastClaim[topic]
+	_, resume := ps.claimCursor[topic]
+	ps.mu.RUnlock()
+
+	return resume || time.Since(last) >= ps.config.PubSubStreamsConfig.ClaimMinIdle
+}
+
+// claimPendingMessages claims with XAUTOCLAIM the messages of the group which were delivered to a consumer but not
+// acknowledged for longer than ClaimMinIdle, and processes them. The pending messages are claimed page by page,
+// following the cursor returned by XAUTOCLAIM until it is "0-0", as long as the messages can be processed: the claim
+// is resumed from the cursor by the next read otherwise. Returns true if messages were processed.
+func (ps *PubSub) claimPendingMessages(ctx context.Context, topic, group, consumer string, count int64) bool {
+	ps.mu.Lock()
+	start, resume := ps.claimCursor[topic]
+
+	if !resume {
+		start = "0-0"
+		ps.lastClaim[topic] = time.Now()
+	}
+	ps.mu.Unlock()
+
+	claimed := 0
+
+	for count > 0 && ctx.Err() == nil {
+		msgs, cursor, err := ps.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   topic,
+			Group:    group,
+			Consumer: consumer,
+			MinIdle:  ps.config.PubSubStreamsConfig.ClaimMinIdle,
+			Start:    start,
+			Count:    count,
+		}).Result()
+		if err != nil {
+			if !errors.Is(err, redis.Nil) {
+				ps.logger.Debugf("error claiming pending messages for stream '%s': %v", topic, err)
+			}
+
+			// the claim starts over from the first pending message on the next ClaimMinIdle.
+			cursor = "0-0"
+		}
+
+		if len(msgs) > 0 {
+			claimed += len(msgs)
+
+			ps.processStreamMessages(ctx, topic, []redis.XStream{{Stream: topic, Messages: msgs}}, group)
+		}
+
+		ps.mu.Lock()
+		if cursor == "0-0" {
+			delete(ps.claimCursor, topic)
+		} else {
+			ps.claimCursor[topic] = cursor
+		}
+		ps.mu.Unlock()
+
+		if cursor == "0-0" {
+			break
+		}
+
+		start = cursor
+		count = int64(ps.getAvailableCapacity(topic))
+	}
+
+	if claimed == 0 {
+		return false
+	}
+
+	ps.logger.Infof("claimed %d pending messages of consumer group '%s' on stream '%s'", claimed, group, topic)
+	ps.metrics.IncrementCounter(ctx, "app_pubsub_redis_claimed_total", "topic", topic, "consumer_group", group)
+
+	return true
+}
+
 // markPendingRead marks pending messages as read.
 func (ps *PubSub) markPendingRead(topic string) {
 	ps.mu.Lock()
@@ -899,6 +987,8 @@ func (ps *PubSub) cleanupSubscription(topic string) {
 	delete(ps.subStarted, topic)
 	delete(ps.chanClosed, topic)
 	delete(ps.pendingRead, topic)
+	delete(ps.lastClaim, topic)
+	delete(ps.claimCursor, topic)
 	ps.mu.Unlock()
 }
 
@@ -931,6 +1021,8 @@ func (ps *PubSub) cleanupStreamConsumers(topic string) {
 	delete(ps.subStarted, topic)
 	delete(ps.chanClosed, topic)
 	delete(ps.pendingRead, topic)
+	delete(ps.lastClaim, topic)
+	delete(ps.claimCursor, topic)
 }
 
 // Query retrieves messages from a Redis channel or stream.
@@ -3,6 +3,7 @@ package redis
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -174,9 +175,9 @@ func setupMockTest(t *testing.T, conf map[string]string) (*testRedisClient, redi
 	redisConfig := getRedisConfig(config.NewMockConfig(conf), mockLogger)
 
 	r := &Redis{
-		Client: db,
-		config: redisConfig,
-		logger: mockLogger,
+		UniversalClient: db,
+		config:          redisConfig,
+		logger:          mockLogger,
 	}
 	ps := newPubSub(db, redisConfig, mockLogger, mockMetrics)
 
@@ -1296,6 +1297,151 @@ func TestPubSub_ConsumeStreamMessages_RatioBasedMixing(t *testing.T) {
 	psClient.consumeStreamMessages(ctx, topic, group, "test-consumer", 1*time.Second)
 }
 
+func TestPubSub_ConsumeStreamMessages_ClaimsIdlePendingMessages(t *testing.T) {
+	t.Parallel()
+
+	client, s := setupTest(t, map[string]string{
+		"REDIS_PUBSUB_MODE":            "streams",
+		"REDIS_STREAMS_CONSUMER_GROUP": "test-group",
+		"REDIS_STREAMS_CONSUMER_NAME":  "test-consumer",
+		"REDIS_STREAMS_CLAIM_MIN_IDLE": "1ms",
+		"REDIS_STREAMS_BLOCK_TIMEOUT":  "10ms",
+	})
+	defer s.Close()
+	defer client.Close()
+
+	ctx := context.Background()
+	topic := "claim-topic"
+	group := "test-group"
+
+	require.NoError(t, client.PubSub.CreateTopic(ctx, topic))
+	require.NoError(t, client.PubSub.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: topic,
+		Values: map[string]any{"payload": "orphaned"},
+	}).Err())
+
+	// The message is delivered to a consumer which crashes before acknowledging it.
+	require.NoError(t, client.PubSub.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    group,
+		Consumer: "crashed-consumer",
+		Streams:  []string{topic, ">"},
+		Count:    1,
+	}).Err())
+
+	time.Sleep(10 * time.Millisecond)
+
+	subCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	msg, err := client.PubSub.Subscribe(subCtx, topic)
+	require.NoError(t, err)
+	require.NotNil(t, msg)
+	assert.Equal(t, "orphaned", string(msg.Value))
+
+	pending, err := client.PubSub.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: topic,
+		Group:  group,
+		Start:  "-",
+		End:    "+",
+		Count:  10,
+	}).Result()
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "test-consumer", pending[0].Consumer)
+
+	msg.Commit()
+
+	pending, err = client.PubSub.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: topic,
+		Group:  group,
+		Start:  "-",
+		End:    "+",
+		Count:  10,
+	}).Result()
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestPubSub_ClaimPendingMessages_FollowsCursor(t *testing.T) {
+	t.Parallel()
+
+	client, s := setupTest(t, map[string]string{
+		"REDIS_PUBSUB_MODE":            "streams",
+		"REDIS_STREAMS_CONSUMER_GROUP": "test-group",
+		"REDIS_STREAMS_CONSUMER_NAME":  "test-consumer",
+		"REDIS_STREAMS_CLAIM_MIN_IDLE": "1ms",
+	})
+	defer s.Close()
+	defer client.Close()
+
+	ctx := context.Background()
+	ps := client.PubSub
+	topic := "claim-cursor-topic"
+	group := "test-group"
+
+	require.NoError(t, ps.CreateTopic(ctx, topic))
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, ps.client.XAdd(ctx, &redis.XAddArgs{
+			Stream: topic,
+			Values: map[string]any{"payload": fmt.Sprintf("orphaned-%d", i)},
+		}).Err())
+	}
+
+	// The messages are delivered to a consumer which crashes before acknowledging them.
+	require.NoError(t, ps.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    group,
+		Consumer: "crashed-consumer",
+		Streams:  []string{topic, ">"},
+		Count:    3,
+	}).Err())
+
+	time.Sleep(10 * time.Millisecond)
+
+	msgChan := make(chan *pubsub.Message, 10)
+
+	ps.mu.Lock()
+	ps.receiveChan[topic] = msgChan
+	ps.chanClosed[topic] = false
+	ps.mu.Unlock()
+
+	// a single message is claimed per page, the following pages are claimed with the cursor of XAUTOCLAIM.
+	assert.True(t, ps.claimPendingMessages(ctx, topic, group, "test-consumer", 1))
+
+	require.Len(t, msgChan, 3)
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, fmt.Sprintf("orphaned-%d", i), string((<-msgChan).Value))
+	}
+
+	assert.Empty(t, ps.claimCursor, "the cursor is dropped once every pending message is claimed")
+	assert.False(t, ps.shouldClaim(topic))
+}
+
+func TestPubSub_ShouldClaim(t *testing.T) {
+	ps := &PubSub{
+		config:      &Config{PubSubStreamsConfig: &StreamsConfig{ClaimMinIdle: time.Minute}},
+		lastClaim:   make(map[string]time.Time),
+		claimCursor: make(map[string]string),
+	}
+
+	assert.True(t, ps.shouldClaim("topic"), "pending messages are claimed on the first read")
+
+	ps.lastClaim["topic"] = time.Now()
+	assert.False(t, ps.shouldClaim("topic"), "pending messages are claimed at most once per ClaimMinIdle")
+
+	ps.lastClaim["topic"] = time.Now().Add(-2 * time.Minute)
+	assert.True(t, ps.shouldClaim("topic"))
+
+	ps.lastClaim["topic"] = time.Now()
+	ps.claimCursor["topic"] = "5-0"
+	assert.True(t, ps.shouldClaim("topic"), "a claim stopped before the end of the pending messages is resumed")
+	delete(ps.claimCursor, "topic")
+
+	ps.config.PubSubStreamsConfig.ClaimMinIdle = 0
+	assert.False(t, ps.shouldClaim("topic"), "claiming is disabled when ClaimMinIdle is 0")
+}
+
 func TestPubSub_ConsumeStreamMessages_Ratio_0_0_FillsWithNew(t *testing.T) {
 	t.Parallel()
 
@@ -1998,7 +2144,7 @@ func TestPubSub_UnsubscribeFromRedis_Error(t *testing.T) {
 	topic := "test-topic"
 	ctx := context.Background()
 
-	redisPubSub := client.Redis.Client.Subscribe(ctx, topic)
+	redisPubSub := client.Redis.Subscribe(ctx, topic)
 
 	client.PubSub.mu.Lock()
 	client.PubSub.subPubSub[topic] = redisPubSub
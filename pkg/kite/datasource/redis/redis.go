@@ -6,6 +6,7 @@ import (
 	"errors"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	otel "github.com/redis/go-redis/extra/redisotel/v9"
@@ -39,6 +40,9 @@ var (
 	errPubSubChannelFailedForTopic    = errors.New("failed to get channel from PubSub for topic")
 	errConsumerGroupNotConfigured     = errors.New("consumer group not configured for stream")
 	errFailedToEnsureConsumerGroup    = errors.New("failed to ensure consumer group for stream")
+
+	errMasterNameNotProvided = errors.New("REDIS_MASTER_NAME must be provided in sentinel mode")
+	errPubSubClusterMode     = errors.New("redis pubsub does not support the cluster mode")
 )
 
 type Config struct {
@@ -50,6 +54,13 @@ type Config struct {
 	Options  *redis.Options
 	TLS      *tls.Config
 
+	// Topology configuration
+	Mode             string   // "standalone", "cluster" or "sentinel" (default: standalone)
+	Addrs            []string // Seed nodes of the cluster, or sentinels
+	MasterName       string   // Master monitored by the sentinels
+	SentinelUsername string
+	SentinelPassword string
+
 	// PubSub configuration
 	PubSubMode          string // "pubsub" or "streams"
 	PubSubStreamsConfig *StreamsConfig
@@ -78,12 +89,21 @@ type StreamsConfig struct {
 	// Default: 0.7 (70% PEL, 30% new messages)
 	// 0.0 = only new messages, 1.0 = only PEL messages
 	PELRatio float64
+
+	// ClaimMinIdle is the idle time after which the pending messages of the other consumers of the group, e.g. of a
+	// consumer which crashed before acknowledging them, are claimed with XAUTOCLAIM (optional)
+	// Default: 5m, 0 disables claiming
+	ClaimMinIdle time.Duration
 }
 
+// Redis is a client of a standalone Redis server, of a Redis cluster or of the master elected by Redis sentinels,
+// depending on REDIS_MODE.
 type Redis struct {
-	*redis.Client
+	redis.UniversalClient
 	logger datasource.Logger
 	config *Config
+	// credentials are the user and password of the connections opened from now on, see UpdateCredentials.
+	credentials atomic.Pointer[[2]string]
 }
 
 // PubSub handles Redis PubSub operations.
@@ -108,7 +128,9 @@ type PubSub struct {
 	chanClosed      map[string]bool
 	closeOnce       map[string]*sync.Once // Ensure channels are closed only once
 	streamConsumers map[string]*streamConsumer
-	pendingRead     map[string]bool // Track if pending messages have been read for streams
+	pendingRead     map[string]bool      // Track if pending messages have been read for streams
+	lastClaim       map[string]time.Time // Track when pending messages were last claimed for streams
+	claimCursor     map[string]string    // Track where a claim of pending messages stopped, to resume it
 	mu              sync.RWMutex
 	ctx             context.Context
 	cancel          context.CancelFunc
@@ -124,6 +146,7 @@ type streamConsumer struct {
 
 // NewClient returns a [Redis] client if connection is successful based on [Config].
 // Supports both plain and TLS connections. TLS is configured via REDIS_TLS_ENABLED and related environment variables.
+// The client connects to a standalone server, a cluster or the master elected by sentinels, as set by REDIS_MODE.
 // In case of error, it returns an error as second parameter.
 func NewClient(c config.Config, logger datasource.Logger, metrics Metrics) *Redis {
 	redisConfig := getRedisConfig(c, logger)
@@ -133,8 +156,23 @@ func NewClient(c config.Config, logger datasource.Logger, metrics Metrics) *Redi
 		return nil
 	}
 
-	rc := redis.NewClient(redisConfig.Options)
+	if err := validateTopology(redisConfig); err != nil {
+		logger.Errorf("could not connect to redis, error: %s", err)
+
+		return nil
+	}
+
+	r := &Redis{
+		config: redisConfig,
+		logger: logger,
+	}
+
+	r.credentials.Store(&[2]string{redisConfig.Username, redisConfig.Password})
+	redisConfig.Options.CredentialsProvider = r.currentCredentials
+
+	rc := newUniversalClient(redisConfig, logger)
 	rc.AddHook(&redisHook{config: redisConfig, logger: logger, metrics: metrics})
+	instrumentNodes(rc, metrics)
 
 	ctx, cancel := context.WithTimeout(context.TODO(), redisPingTimeout)
 	defer cancel()
@@ -144,24 +182,53 @@ func NewClient(c config.Config, logger datasource.Logger, metrics Metrics) *Redi
 			logger.Errorf("could not add tracing instrumentation, error: %s", err)
 		}
 
-		logger.Infof("connected to redis at %s:%d on database %d", redisConfig.HostName, redisConfig.Port, redisConfig.DB)
+		logger.Infof("connected to redis at %s on database %d", redisConfig.address(), redisConfig.DB)
 	} else {
-		logger.Errorf("could not connect to redis at '%s:%d' , error: %s", redisConfig.HostName, redisConfig.Port, err)
+		logger.Errorf("could not connect to redis at '%s' , error: %s", redisConfig.address(), err)
 
 		go retryConnect(rc, logger)
 	}
 
-	r := &Redis{
-		Client: rc,
-		config: redisConfig,
-		logger: logger,
-	}
+	r.UniversalClient = rc
 
 	return r
 }
 
+// UpdateCredentials sets the user and password of the connections opened from now on, e.g. when they are rotated.
+// The open connections stay authenticated.
+func (r *Redis) UpdateCredentials(user, password string) error {
+	if r == nil {
+		return errClientNotConnected
+	}
+
+	r.credentials.Store(&[2]string{user, password})
+
+	r.logger.Infof("updated the credentials of redis at %s:%d", r.config.HostName, r.config.Port)
+
+	return nil
+}
+
+// Client returns the client of a standalone server, or of the master elected by the sentinels, e.g. for the methods
+// of *redis.Client such as Options and Conn, which the clients of a cluster do not have. It returns nil in cluster
+// mode.
+func (r *Redis) Client() *redis.Client {
+	if r == nil {
+		return nil
+	}
+
+	client, _ := r.UniversalClient.(*redis.Client)
+
+	return client
+}
+
+func (r *Redis) currentCredentials() (user, password string) {
+	credentials := r.credentials.Load()
+
+	return credentials[0], credentials[1]
+}
+
 // retryConnect handles the retry mechanism for connecting to Redis.
-func retryConnect(client *redis.Client, logger datasource.Logger) {
+func retryConnect(client redis.UniversalClient, logger datasource.Logger) {
 	for {
 		time.Sleep(defaultRetryTimeout)
 
@@ -186,8 +253,8 @@ func retryConnect(client *redis.Client, logger datasource.Logger) {
 
 // Close shuts down the Redis client, ensuring the current dataset is saved before exiting.
 func (r *Redis) Close() error {
-	if r.Client != nil {
-		return r.Client.Close()
+	if r.UniversalClient != nil {
+		return r.UniversalClient.Close()
 	}
 
 	return nil
@@ -212,7 +279,18 @@ func NewPubSub(conf config.Config, logger datasource.Logger, metrics Metrics) pu
 	// to avoid collisions with the main Redis database (typically 0).
 	setPubSubDB(conf, redisConfig)
 
-	rc := redis.NewClient(redisConfig.Options)
+	err := validateTopology(redisConfig)
+	if redisConfig.Mode == modeCluster {
+		err = errPubSubClusterMode
+	}
+
+	if err != nil {
+		logger.Errorf("could not connect to redis, error: %s", err)
+
+		return nil
+	}
+
+	rc := newPubSubClient(redisConfig)
 	rc.AddHook(&redisHook{config: redisConfig, logger: logger, metrics: metrics})
 
 	ctx, cancel := context.WithTimeout(context.TODO(), redisPingTimeout)
@@ -223,9 +301,9 @@ func NewPubSub(conf config.Config, logger datasource.Logger, metrics Metrics) pu
 			logger.Errorf("could not add tracing instrumentation, error: %s", err)
 		}
 
-		logger.Infof("connected to redis at %s:%d on database %d", redisConfig.HostName, redisConfig.Port, redisConfig.DB)
+		logger.Infof("connected to redis at %s on database %d", redisConfig.address(), redisConfig.DB)
 	} else {
-		logger.Errorf("could not connect to redis at '%s:%d' , error: %s", redisConfig.HostName, redisConfig.Port, err)
+		logger.Errorf("could not connect to redis at '%s' , error: %s", redisConfig.address(), err)
 
 		go retryConnect(rc, logger)
 	}
@@ -274,6 +352,8 @@ func newPubSub(client *redis.Client, redisCfg *Config, logger datasource.Logger,
 		closeOnce:       make(map[string]*sync.Once),
 		streamConsumers: make(map[string]*streamConsumer),
 		pendingRead:     make(map[string]bool),
+		lastClaim:       make(map[string]time.Time),
+		claimCursor:     make(map[string]string),
 	}
 
 	ps.ctx, ps.cancel = context.WithCancel(context.Background())
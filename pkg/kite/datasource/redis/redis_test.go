@@ -11,10 +11,21 @@ import (
 	"go.uber.org/mock/gomock"
 
 	"github.com/sllt/kite/pkg/kite/config"
+	"github.com/sllt/kite/pkg/kite/datasource"
 	"github.com/sllt/kite/pkg/kite/logging"
 	"github.com/sllt/kite/pkg/kite/testutil"
 )
 
+// expectDatasourceMetrics allows the standard datasource metrics recorded for every command.
+func expectDatasourceMetrics(m *MockMetrics) {
+	m.EXPECT().IncrementCounter(gomock.Any(), datasource.MetricOperations, "datasource", "redis",
+		"database", gomock.Any(), "operation", gomock.Any()).AnyTimes()
+	m.EXPECT().IncrementCounter(gomock.Any(), datasource.MetricErrors, "datasource", "redis",
+		"database", gomock.Any(), "operation", gomock.Any()).AnyTimes()
+	m.EXPECT().RecordHistogram(gomock.Any(), datasource.MetricDuration, gomock.Any(), "datasource", "redis",
+		"database", gomock.Any(), "operation", gomock.Any()).AnyTimes()
+}
+
 func Test_NewClient_HostNameMissing(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -39,9 +50,10 @@ func Test_NewClient_InvalidPort(t *testing.T) {
 	mockMetrics.EXPECT().RecordHistogram(
 		gomock.Any(), "app_redis_stats", gomock.Any(), "hostname", gomock.Any(), "type", gomock.Any(),
 	).AnyTimes()
+	expectDatasourceMetrics(mockMetrics)
 
 	client := NewClient(mockConfig, mockLogger, mockMetrics)
-	assert.NotNil(t, client.Client, "Test_NewClient_InvalidPort Failed! Expected redis client not to be nil")
+	assert.NotNil(t, client.UniversalClient, "Test_NewClient_InvalidPort Failed! Expected redis client not to be nil")
 }
 
 func TestRedis_QueryLogging(t *testing.T) {
@@ -57,6 +69,7 @@ func TestRedis_QueryLogging(t *testing.T) {
 	mockMetric := NewMockMetrics(ctrl)
 	mockMetric.EXPECT().RecordHistogram(gomock.Any(), "app_redis_stats", gomock.Any(),
 		"hostname", gomock.Any(), "type", gomock.Any()).AnyTimes()
+	expectDatasourceMetrics(mockMetric)
 
 	result := testutil.StdoutOutputForFunc(func() {
 		mockLogger := logging.NewMockLogger(logging.DEBUG)
@@ -94,6 +107,7 @@ func TestRedis_PipelineQueryLogging(t *testing.T) {
 	mockMetric := NewMockMetrics(ctrl)
 	mockMetric.EXPECT().RecordHistogram(gomock.Any(), "app_redis_stats", gomock.Any(),
 		"hostname", gomock.Any(), "type", gomock.Any()).AnyTimes()
+	expectDatasourceMetrics(mockMetric)
 
 	// Execute Redis pipeline
 	result := testutil.StdoutOutputForFunc(func() {
@@ -143,6 +157,7 @@ func TestRedis_Close(t *testing.T) {
 	mockMetric := NewMockMetrics(ctrl)
 	mockMetric.EXPECT().RecordHistogram(gomock.Any(), "app_redis_stats", gomock.Any(), "hostname",
 		gomock.Any(), "type", gomock.Any()).AnyTimes()
+	expectDatasourceMetrics(mockMetric)
 
 	mockLogger := logging.NewMockLogger(logging.DEBUG)
 	client := NewClient(config.NewMockConfig(map[string]string{
@@ -203,3 +218,38 @@ func getMockKey() string {
 
 	return mockKey
 }
+
+func TestRedis_UpdateCredentials(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s, err := miniredis.Run()
+	require.NoError(t, err)
+
+	defer s.Close()
+
+	s.RequireAuth("s3cr3t")
+
+	mockMetric := NewMockMetrics(ctrl)
+	mockMetric.EXPECT().RecordHistogram(gomock.Any(), "app_redis_stats", gomock.Any(),
+		"hostname", gomock.Any(), "type", gomock.Any()).AnyTimes()
+	expectDatasourceMetrics(mockMetric)
+
+	client := NewClient(config.NewMockConfig(map[string]string{
+		"REDIS_HOST":     s.Host(),
+		"REDIS_PORT":     s.Port(),
+		"REDIS_PASSWORD": "s3cr3t",
+	}), logging.NewMockLogger(logging.DEBUG), mockMetric)
+
+	require.NoError(t, client.Ping(t.Context()).Err())
+
+	require.NoError(t, client.UpdateCredentials("", "n3w"))
+
+	user, password := client.Options().CredentialsProvider()
+	assert.Empty(t, user)
+	assert.Equal(t, "n3w", password, "the connections opened from now on must use the new password")
+
+	var notConnected *Redis
+
+	assert.ErrorIs(t, notConnected.UpdateCredentials("", "n3w"), errClientNotConnected)
+}
@@ -0,0 +1,93 @@
+package redis
+
+import (
+	"context"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// clusterSlots is the number of hash slots the keys of a Redis cluster are sharded in.
+const clusterSlots = 16384
+
+// KeySlot returns the hash slot of key in a Redis cluster. When the key contains a non-empty hash tag, e.g. the
+// "user:42" of "{user:42}:profile", only the hash tag is hashed, so that related keys share a slot.
+func KeySlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+
+	return int(crc16(key) % clusterSlots)
+}
+
+// GroupKeysBySlot groups keys by hash slot, in the order the slots first appear in keys. The keys of a group can be
+// passed to the same multi-key command, e.g. MGET or DEL, which a cluster rejects when their keys are in different
+// slots.
+func GroupKeysBySlot(keys []string) [][]string {
+	var groups [][]string
+
+	index := make(map[int]int)
+
+	for _, key := range keys {
+		slot := KeySlot(key)
+
+		i, ok := index[slot]
+		if !ok {
+			i = len(groups)
+			index[slot] = i
+			groups = append(groups, nil)
+		}
+
+		groups[i] = append(groups[i], key)
+	}
+
+	return groups
+}
+
+// PipelinedBySlot sends in one pipeline the commands queued by fn for each group of keys sharing a hash slot, see
+// GroupKeysBySlot. In cluster mode, the pipeline sends the commands of each slot to the node owning it, in parallel;
+// with the other topologies, fn is called once with all the keys.
+//
+//	cmds, err := redis.PipelinedBySlot(ctx, keys, func(pipe goredis.Pipeliner, keys []string) error {
+//		pipe.MGet(ctx, keys...)
+//
+//		return nil
+//	})
+func (r *Redis) PipelinedBySlot(ctx context.Context, keys []string,
+	fn func(pipe redis.Pipeliner, keys []string) error) ([]redis.Cmder, error) {
+	groups := [][]string{keys}
+	if r.config.Mode == modeCluster {
+		groups = GroupKeysBySlot(keys)
+	}
+
+	return r.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, group := range groups {
+			if err := fn(pipe, group); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// crc16 returns the CRC16-XMODEM checksum of key, the hash of the keys of a Redis cluster.
+func crc16(key string) uint16 {
+	var crc uint16
+
+	for i := 0; i < len(key); i++ {
+		crc ^= uint16(key[i]) << 8
+
+		for range 8 {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return crc
+}
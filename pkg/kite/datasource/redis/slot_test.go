@@ -0,0 +1,105 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/sllt/kite/pkg/kite/config"
+	"github.com/sllt/kite/pkg/kite/logging"
+)
+
+func TestKeySlot(t *testing.T) {
+	testCases := []struct {
+		key  string
+		slot int
+	}{
+		{key: "123456789", slot: 12739},
+		{key: "foo", slot: 12182},
+		{key: "bar", slot: 5061},
+		{key: "{foo}:profile", slot: 12182},
+		{key: "user:{foo}", slot: 12182},
+		{key: "{foo}{bar}", slot: 12182},
+		{key: "{}foo", slot: 9500},
+		{key: "foo{}{bar}", slot: 8363},
+		{key: "foo{{bar}}zap", slot: 4015},
+		{key: "foo{", slot: 7673},
+		{key: "", slot: 0},
+	}
+
+	for i, tc := range testCases {
+		assert.Equal(t, tc.slot, KeySlot(tc.key), "TEST[%d], Failed.\n%s", i, tc.key)
+	}
+}
+
+func TestGroupKeysBySlot(t *testing.T) {
+	groups := GroupKeysBySlot([]string{"{user:1}:name", "{user:2}:name", "{user:1}:email", "{user:2}:email"})
+
+	assert.Equal(t, [][]string{{"{user:1}:name", "{user:1}:email"}, {"{user:2}:name", "{user:2}:email"}}, groups)
+	assert.Empty(t, GroupKeysBySlot(nil))
+}
+
+func TestRedis_PipelinedBySlot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	s, err := miniredis.Run()
+	require.NoError(t, err)
+
+	defer s.Close()
+
+	mockMetric := NewMockMetrics(ctrl)
+	mockMetric.EXPECT().RecordHistogram(gomock.Any(), "app_redis_stats", gomock.Any(),
+		"hostname", gomock.Any(), "type", gomock.Any()).AnyTimes()
+	expectDatasourceMetrics(mockMetric)
+
+	client := NewClient(config.NewMockConfig(map[string]string{
+		"REDIS_HOST": s.Host(),
+		"REDIS_PORT": s.Port(),
+	}), logging.NewMockLogger(logging.ERROR), mockMetric)
+
+	defer client.Close()
+
+	keys := []string{"{a}1", "{b}1", "{a}2"}
+	for _, key := range keys {
+		require.NoError(t, s.Set(key, key))
+	}
+
+	testCases := []struct {
+		desc   string
+		mode   string
+		groups [][]string
+	}{
+		{desc: "standalone", mode: modeStandalone, groups: [][]string{keys}},
+		{desc: "cluster", mode: modeCluster, groups: [][]string{{"{a}1", "{a}2"}, {"{b}1"}}},
+	}
+
+	for i, tc := range testCases {
+		client.config.Mode = tc.mode
+
+		var groups [][]string
+
+		cmds, err := client.PipelinedBySlot(t.Context(), keys, func(pipe redis.Pipeliner, keys []string) error {
+			groups = append(groups, keys)
+			pipe.MGet(t.Context(), keys...)
+
+			return nil
+		})
+
+		require.NoError(t, err, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, tc.groups, groups, "TEST[%d], Failed.\n%s", i, tc.desc)
+		require.Len(t, cmds, len(tc.groups), "TEST[%d], Failed.\n%s", i, tc.desc)
+
+		for j, cmd := range cmds {
+			values := make([]any, len(tc.groups[j]))
+			for k, key := range tc.groups[j] {
+				values[k] = key
+			}
+
+			assert.Equal(t, values, cmd.(*redis.SliceCmd).Val(), "TEST[%d], Failed.\n%s", i, tc.desc)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/sllt/kite/pkg/kite/config"
+	"github.com/sllt/kite/pkg/kite/datasource"
+)
+
+const (
+	// Topologies of the Redis servers, set by REDIS_MODE.
+	modeStandalone = "standalone"
+	modeCluster    = "cluster"
+	modeSentinel   = "sentinel"
+
+	nodeStatsMetric = "app_redis_node_stats"
+)
+
+// parseTopology parses the topology of the Redis servers:
+//
+//	REDIS_MODE:              "standalone" (default), "cluster" or "sentinel"
+//	REDIS_ADDRS:             comma-separated host:port of the seed nodes of the cluster, or of the sentinels
+//	REDIS_MASTER_NAME:       name of the master monitored by the sentinels
+//	REDIS_SENTINEL_USER:     username of the sentinels (optional)
+//	REDIS_SENTINEL_PASSWORD: password of the sentinels (optional)
+//
+// REDIS_ADDRS defaults to REDIS_HOST:REDIS_PORT, and REDIS_HOST to the host of the first address.
+func parseTopology(c config.Config, redisConfig *Config, logger datasource.Logger) {
+	mode := strings.ToLower(c.GetOrDefault("REDIS_MODE", modeStandalone))
+
+	switch mode {
+	case modeStandalone, modeCluster, modeSentinel:
+	default:
+		logger.Errorf("invalid REDIS_MODE %q, use %q, %q or %q, connecting to a standalone server", mode,
+			modeStandalone, modeCluster, modeSentinel)
+
+		mode = modeStandalone
+	}
+
+	redisConfig.Mode = mode
+
+	if mode == modeStandalone {
+		return
+	}
+
+	for _, addr := range strings.Split(c.Get("REDIS_ADDRS"), ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			redisConfig.Addrs = append(redisConfig.Addrs, addr)
+		}
+	}
+
+	if len(redisConfig.Addrs) == 0 && redisConfig.HostName != "" {
+		redisConfig.Addrs = []string{net.JoinHostPort(redisConfig.HostName, strconv.Itoa(redisConfig.Port))}
+	}
+
+	if redisConfig.HostName == "" && len(redisConfig.Addrs) > 0 {
+		host, port, err := net.SplitHostPort(redisConfig.Addrs[0])
+		if err != nil {
+			host = redisConfig.Addrs[0]
+		}
+
+		redisConfig.HostName = host
+
+		if p, err := strconv.Atoi(port); err == nil {
+			redisConfig.Port = p
+		}
+	}
+
+	redisConfig.MasterName = c.Get("REDIS_MASTER_NAME")
+	redisConfig.SentinelUsername = c.Get("REDIS_SENTINEL_USER")
+	redisConfig.SentinelPassword = c.Get("REDIS_SENTINEL_PASSWORD")
+}
+
+// validateTopology returns the error which prevents connecting to the servers of the configured topology.
+func validateTopology(c *Config) error {
+	if c.Mode == modeSentinel && c.MasterName == "" {
+		return errMasterNameNotProvided
+	}
+
+	return nil
+}
+
+// newUniversalClient returns the client of the configured topology: a cluster client routing the commands to the node
+// owning the slot of their keys, a failover client following the master elected by the sentinels, or a client of a
+// standalone server.
+func newUniversalClient(c *Config, logger datasource.Logger) redis.UniversalClient {
+	switch c.Mode {
+	case modeCluster:
+		if c.DB != 0 {
+			logger.Warnf("REDIS_DB is ignored in cluster mode, a Redis cluster only has the database 0")
+		}
+
+		return redis.NewClusterClient(clusterOptions(c))
+	case modeSentinel:
+		return redis.NewFailoverClient(failoverOptions(c))
+	default:
+		return redis.NewClient(c.Options)
+	}
+}
+
+// newPubSubClient returns the client of the PubSub, which follows the master elected by the sentinels in sentinel
+// mode. The PubSub does not support the cluster mode.
+func newPubSubClient(c *Config) *redis.Client {
+	if c.Mode == modeSentinel {
+		return redis.NewFailoverClient(failoverOptions(c))
+	}
+
+	return redis.NewClient(c.Options)
+}
+
+// clusterOptions returns the options of a cluster client, with the connection pool, timeouts and retries of
+// c.Options, which apply to the connections to each node.
+func clusterOptions(c *Config) *redis.ClusterOptions {
+	o := c.Options
+
+	return &redis.ClusterOptions{
+		Addrs:                 c.Addrs,
+		ClientName:            o.ClientName,
+		Protocol:              o.Protocol,
+		Username:              c.Username,
+		Password:              c.Password,
+		CredentialsProvider:   o.CredentialsProvider,
+		MaxRetries:            o.MaxRetries,
+		MinRetryBackoff:       o.MinRetryBackoff,
+		MaxRetryBackoff:       o.MaxRetryBackoff,
+		DialTimeout:           o.DialTimeout,
+		ReadTimeout:           o.ReadTimeout,
+		WriteTimeout:          o.WriteTimeout,
+		ContextTimeoutEnabled: o.ContextTimeoutEnabled,
+		PoolFIFO:              o.PoolFIFO,
+		PoolSize:              o.PoolSize,
+		PoolTimeout:           o.PoolTimeout,
+		MinIdleConns:          o.MinIdleConns,
+		MaxIdleConns:          o.MaxIdleConns,
+		MaxActiveConns:        o.MaxActiveConns,
+		ConnMaxIdleTime:       o.ConnMaxIdleTime,
+		ConnMaxLifetime:       o.ConnMaxLifetime,
+		TLSConfig:             c.TLS,
+	}
+}
+
+// failoverOptions returns the options of a client of the master elected by the sentinels, with the connection pool,
+// timeouts and retries of c.Options.
+func failoverOptions(c *Config) *redis.FailoverOptions {
+	o := c.Options
+
+	return &redis.FailoverOptions{
+		MasterName:            c.MasterName,
+		SentinelAddrs:         c.Addrs,
+		SentinelUsername:      c.SentinelUsername,
+		SentinelPassword:      c.SentinelPassword,
+		ClientName:            o.ClientName,
+		Protocol:              o.Protocol,
+		Username:              c.Username,
+		Password:              c.Password,
+		CredentialsProvider:   o.CredentialsProvider,
+		DB:                    c.DB,
+		MaxRetries:            o.MaxRetries,
+		MinRetryBackoff:       o.MinRetryBackoff,
+		MaxRetryBackoff:       o.MaxRetryBackoff,
+		DialTimeout:           o.DialTimeout,
+		ReadTimeout:           o.ReadTimeout,
+		WriteTimeout:          o.WriteTimeout,
+		ContextTimeoutEnabled: o.ContextTimeoutEnabled,
+		PoolFIFO:              o.PoolFIFO,
+		PoolSize:              o.PoolSize,
+		PoolTimeout:           o.PoolTimeout,
+		MinIdleConns:          o.MinIdleConns,
+		MaxIdleConns:          o.MaxIdleConns,
+		MaxActiveConns:        o.MaxActiveConns,
+		ConnMaxIdleTime:       o.ConnMaxIdleTime,
+		ConnMaxLifetime:       o.ConnMaxLifetime,
+		TLSConfig:             c.TLS,
+	}
+}
+
+// address describes the servers of the configured topology in the logs.
+func (c *Config) address() string {
+	switch c.Mode {
+	case modeCluster:
+		return "cluster " + strings.Join(c.Addrs, ",")
+	case modeSentinel:
+		return fmt.Sprintf("master %s of sentinels %s", c.MasterName, strings.Join(c.Addrs, ","))
+	default:
+		return fmt.Sprintf("%s:%d", c.HostName, c.Port)
+	}
+}
+
+// instrumentNodes records the response time of the commands sent to each node of a cluster, which shows how the load
+// is sharded between the nodes and which of them is slow.
+func instrumentNodes(client redis.UniversalClient, metrics Metrics) {
+	cluster, ok := client.(*redis.ClusterClient)
+	if !ok || metrics == nil {
+		return
+	}
+
+	cluster.OnNewNode(func(node *redis.Client) {
+		node.AddHook(&nodeHook{node: node.Options().Addr, metrics: metrics})
+	})
+}
+
+// nodeHook records the response time of the commands sent to a node of a cluster.
+type nodeHook struct {
+	node    string
+	metrics Metrics
+}
+
+func (h *nodeHook) record(ctx context.Context, start time.Time, command string) {
+	h.metrics.RecordHistogram(ctx, nodeStatsMetric, float64(time.Since(start).Microseconds())/1000,
+		"node", h.node, "type", command)
+}
+
+// DialHook implements the redis.DialHook interface.
+func (*nodeHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+// ProcessHook implements the redis.ProcessHook interface.
+func (h *nodeHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		h.record(ctx, start, cmd.Name())
+
+		return err
+	}
+}
+
+// ProcessPipelineHook implements the redis.ProcessPipelineHook interface.
+func (h *nodeHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		h.record(ctx, start, "pipeline")
+
+		return err
+	}
+}
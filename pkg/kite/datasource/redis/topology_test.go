@@ -0,0 +1,176 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/sllt/kite/pkg/kite/config"
+	"github.com/sllt/kite/pkg/kite/logging"
+)
+
+func TestGetRedisConfig_Topology(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		configs  map[string]string
+		mode     string
+		addrs    []string
+		hostName string
+		port     int
+		address  string
+	}{
+		{
+			desc:     "standalone by default",
+			configs:  map[string]string{"REDIS_HOST": "localhost", "REDIS_ADDRS": "node-1:7000"},
+			mode:     modeStandalone,
+			hostName: "localhost",
+			port:     defaultRedisPort,
+			address:  "localhost:6379",
+		},
+		{
+			desc:     "invalid mode",
+			configs:  map[string]string{"REDIS_HOST": "localhost", "REDIS_MODE": "ring"},
+			mode:     modeStandalone,
+			hostName: "localhost",
+			port:     defaultRedisPort,
+			address:  "localhost:6379",
+		},
+		{
+			desc:     "cluster seed nodes",
+			configs:  map[string]string{"REDIS_MODE": "Cluster", "REDIS_ADDRS": "node-1:7000, node-2:7001,"},
+			mode:     modeCluster,
+			addrs:    []string{"node-1:7000", "node-2:7001"},
+			hostName: "node-1",
+			port:     7000,
+			address:  "cluster node-1:7000,node-2:7001",
+		},
+		{
+			desc:     "cluster on the host",
+			configs:  map[string]string{"REDIS_MODE": "cluster", "REDIS_HOST": "localhost", "REDIS_PORT": "7000"},
+			mode:     modeCluster,
+			addrs:    []string{"localhost:7000"},
+			hostName: "localhost",
+			port:     7000,
+			address:  "cluster localhost:7000",
+		},
+		{
+			desc: "sentinels",
+			configs: map[string]string{"REDIS_MODE": "sentinel", "REDIS_ADDRS": "sentinel-1:26379,sentinel-2:26379",
+				"REDIS_MASTER_NAME": "cache"},
+			mode:     modeSentinel,
+			addrs:    []string{"sentinel-1:26379", "sentinel-2:26379"},
+			hostName: "sentinel-1",
+			port:     26379,
+			address:  "master cache of sentinels sentinel-1:26379,sentinel-2:26379",
+		},
+	}
+
+	for i, tc := range testCases {
+		conf := testGetRedisConfig(t, tc.configs)
+
+		assert.Equal(t, tc.mode, conf.Mode, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, tc.addrs, conf.Addrs, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, tc.hostName, conf.HostName, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, tc.port, conf.Port, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, tc.address, conf.address(), "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestGetRedisConfig_SentinelCredentials(t *testing.T) {
+	conf := testGetRedisConfig(t, map[string]string{
+		"REDIS_MODE":              "sentinel",
+		"REDIS_ADDRS":             "sentinel-1:26379",
+		"REDIS_MASTER_NAME":       "cache",
+		"REDIS_PASSWORD":          "master-password",
+		"REDIS_SENTINEL_USER":     "sentinel",
+		"REDIS_SENTINEL_PASSWORD": "sentinel-password",
+	})
+
+	opts := failoverOptions(conf)
+
+	assert.Equal(t, "cache", opts.MasterName)
+	assert.Equal(t, []string{"sentinel-1:26379"}, opts.SentinelAddrs)
+	assert.Equal(t, "sentinel", opts.SentinelUsername)
+	assert.Equal(t, "sentinel-password", opts.SentinelPassword)
+	assert.Equal(t, "master-password", opts.Password)
+}
+
+func TestClusterOptions_Pool(t *testing.T) {
+	conf := testGetRedisConfig(t, map[string]string{"REDIS_MODE": "cluster", "REDIS_ADDRS": "node-1:7000"})
+	conf.Options.PoolSize = 50
+	conf.Options.MinIdleConns = 5
+	conf.Options.DialTimeout = 2 * time.Second
+	conf.Options.ReadTimeout = time.Second
+	conf.Options.MaxRetries = 1
+
+	cluster := clusterOptions(conf)
+	failover := failoverOptions(conf)
+
+	assert.Equal(t, []any{50, 5, 2 * time.Second, time.Second, 1}, []any{cluster.PoolSize, cluster.MinIdleConns,
+		cluster.DialTimeout, cluster.ReadTimeout, cluster.MaxRetries})
+	assert.Equal(t, []any{50, 5, 2 * time.Second, time.Second, 1}, []any{failover.PoolSize, failover.MinIdleConns,
+		failover.DialTimeout, failover.ReadTimeout, failover.MaxRetries})
+}
+
+func TestNewClient_Topology(t *testing.T) {
+	mockLogger := logging.NewMockLogger(logging.ERROR)
+
+	client := NewClient(config.NewMockConfig(map[string]string{
+		"REDIS_MODE": "sentinel", "REDIS_ADDRS": "sentinel-1:26379",
+	}), mockLogger, nil)
+	assert.Nil(t, client, "a sentinel client needs the master name")
+
+	ps := NewPubSub(config.NewMockConfig(map[string]string{
+		"REDIS_MODE": "cluster", "REDIS_ADDRS": "node-1:7000", "PUBSUB_BACKEND": "REDIS",
+	}), mockLogger, nil)
+	assert.Nil(t, ps, "the pubsub does not support the cluster mode")
+
+	cluster := newUniversalClient(testGetRedisConfig(t, map[string]string{
+		"REDIS_MODE": "cluster", "REDIS_ADDRS": "node-1:7000",
+	}), mockLogger)
+
+	defer cluster.Close()
+
+	assert.IsType(t, &redis.ClusterClient{}, cluster)
+	assert.Nil(t, (&Redis{UniversalClient: cluster}).Client(), "a cluster has no standalone client")
+
+	standalone := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+
+	defer standalone.Close()
+
+	assert.Same(t, standalone, (&Redis{UniversalClient: standalone}).Client())
+}
+
+func TestNodeHook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockMetric := NewMockMetrics(ctrl)
+
+	hook := &nodeHook{node: "node-1:7000", metrics: mockMetric}
+
+	mockMetric.EXPECT().RecordHistogram(gomock.Any(), nodeStatsMetric, gomock.Any(),
+		"node", "node-1:7000", "type", "get")
+	mockMetric.EXPECT().RecordHistogram(gomock.Any(), nodeStatsMetric, gomock.Any(),
+		"node", "node-1:7000", "type", "pipeline")
+
+	cmd := redis.NewStringCmd(t.Context(), "get", "key")
+
+	err := hook.ProcessHook(func(_ context.Context, _ redis.Cmder) error { return nil })(t.Context(), cmd)
+	require.NoError(t, err)
+
+	err = hook.ProcessPipelineHook(func(_ context.Context, _ []redis.Cmder) error {
+		return errMockPing
+	})(t.Context(), []redis.Cmder{cmd})
+	require.ErrorIs(t, err, errMockPing)
+}
+
+func TestParseInfo(t *testing.T) {
+	info := "# Stats\r\ntotal_connections_received:3\r\ninstantaneous_ops_per_sec:12\r\n\r\n"
+
+	assert.Equal(t, map[string]string{"total_connections_received": "3", "instantaneous_ops_per_sec": "12"},
+		parseInfo(info))
+}
@@ -0,0 +1,56 @@
+package datasource
+
+import (
+	"context"
+	"time"
+)
+
+// RetryPolicy retries the connection of a datasource which is not healthy after connecting, e.g. because the
+// database is still starting.
+type RetryPolicy struct {
+	// MaxAttempts is the number of connection attempts, including the first one. Values below 1 mean 1.
+	MaxAttempts int
+	// InitialBackoff is the wait before the second attempt, doubled after each attempt up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// FailFast stops the application when the datasource is still down after the last attempt, instead of starting
+	// without it.
+	FailFast bool
+}
+
+// Connect calls connect until healthy reports no error, at most MaxAttempts times. Before each new attempt, it closes
+// the client of the previous one with closeClient, or, when closeClient is nil, connects only once and checks the health
+// again on the next attempts, relying on the driver to reconnect. It returns the error of the last health check, or the
+// error of ctx when it is done while waiting for the next attempt.
+func (p RetryPolicy) Connect(ctx context.Context, connect func(), healthy func(context.Context) error,
+	closeClient func() error) error {
+	backoff := p.InitialBackoff
+
+	for attempt := 1; ; attempt++ {
+		switch {
+		case attempt == 1:
+			connect()
+		case closeClient != nil:
+			// The previous client is down, so an error closing it does not prevent the next one from connecting.
+			_ = closeClient()
+
+			connect()
+		}
+
+		err := healthy(ctx)
+		if err == nil || attempt >= p.MaxAttempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+			backoff = p.MaxBackoff
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errNotReady = errors.New("database is starting")
+
+func TestRetryPolicy_Connect(t *testing.T) {
+	testCases := []struct {
+		policy      RetryPolicy
+		readyAfter  int
+		connections int
+		err         error
+	}{
+		{policy: RetryPolicy{}, readyAfter: 1, connections: 1},
+		{policy: RetryPolicy{}, readyAfter: 2, connections: 1, err: errNotReady},
+		{policy: RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}, readyAfter: 3, connections: 3},
+		{policy: RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+			readyAfter: 5, connections: 3, err: errNotReady},
+	}
+
+	for i, tc := range testCases {
+		connections, closed := 0, 0
+
+		err := tc.policy.Connect(t.Context(), func() { connections++ }, func(context.Context) error {
+			if connections < tc.readyAfter {
+				return errNotReady
+			}
+
+			return nil
+		}, func() error {
+			closed++
+
+			return nil
+		})
+
+		assert.ErrorIs(t, err, tc.err, "TEST[%d], Failed.\n", i)
+		assert.Equal(t, tc.connections, connections, "TEST[%d], Failed.\n", i)
+		assert.Equal(t, tc.connections-1, closed, "TEST[%d], Failed.\n", i)
+	}
+}
+
+func TestRetryPolicy_Connect_NotClosable(t *testing.T) {
+	connections, checks := 0, 0
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+
+	err := policy.Connect(t.Context(), func() { connections++ }, func(context.Context) error {
+		checks++
+
+		if checks < 3 {
+			return errNotReady
+		}
+
+		return nil
+	}, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, connections)
+	assert.Equal(t, 3, checks)
+}
+
+func TestRetryPolicy_Connect_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	connections := 0
+	policy := RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour}
+
+	err := policy.Connect(ctx, func() { connections++ }, func(context.Context) error { return errNotReady }, nil)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, connections)
+}
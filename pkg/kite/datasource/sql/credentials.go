@@ -0,0 +1,75 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync/atomic"
+)
+
+var errCredentialsNotUpdatable = errors.New("the credentials of the connection cannot be updated")
+
+// connector opens the connections of the pool with the current connection string, so that the connections opened
+// after the credentials are updated use the new ones.
+type connector struct {
+	driver driver.Driver
+	dsn    atomic.Pointer[string]
+}
+
+func newConnector(driverName, dsn string) (*connector, error) {
+	// sql.Open only looks the driver up, it does not open any connection.
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &connector{driver: db.Driver()}
+	c.dsn.Store(&dsn)
+
+	return c, db.Close()
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	dsn := *c.dsn.Load()
+
+	if d, ok := c.driver.(driver.DriverContext); ok {
+		dc, err := d.OpenConnector(dsn)
+		if err != nil {
+			return nil, err
+		}
+
+		return dc.Connect(ctx)
+	}
+
+	return c.driver.Open(dsn)
+}
+
+func (c *connector) Driver() driver.Driver {
+	return c.driver
+}
+
+// UpdateCredentials sets the user and password of the connections opened from now on, e.g. when they are rotated.
+// The idle connections are closed, the connections in use keep their session.
+func (d *DB) UpdateCredentials(user, password string) error {
+	if d == nil || d.connector == nil || d.config == nil {
+		return errCredentialsNotUpdatable
+	}
+
+	dbConfig := *d.config
+	dbConfig.User, dbConfig.Password = user, password
+
+	dsn, err := getDBConnectionString(&dbConfig)
+	if err != nil {
+		return err
+	}
+
+	d.connector.dsn.Store(&dsn)
+
+	d.DB.SetMaxIdleConns(0)
+	d.DB.SetMaxIdleConns(d.config.MaxIdleConn)
+
+	d.logger.Infof("updated the credentials of the %s database", d.config.Dialect)
+
+	return nil
+}
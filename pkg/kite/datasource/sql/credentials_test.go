@@ -0,0 +1,76 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sllt/kite/pkg/kite/logging"
+)
+
+var errNotImplemented = errors.New("not implemented")
+
+// recordingDriver records the connection strings of the connections it opens.
+type recordingDriver struct {
+	mu   sync.Mutex
+	dsns []string
+}
+
+func (d *recordingDriver) Open(dsn string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.dsns = append(d.dsns, dsn)
+
+	return recordingConn{}, nil
+}
+
+func (d *recordingDriver) last() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.dsns[len(d.dsns)-1]
+}
+
+type recordingConn struct{}
+
+func (recordingConn) Prepare(string) (driver.Stmt, error) { return nil, errNotImplemented }
+func (recordingConn) Close() error                        { return nil }
+func (recordingConn) Begin() (driver.Tx, error)           { return nil, errNotImplemented }
+
+func TestDB_UpdateCredentials(t *testing.T) {
+	drv := &recordingDriver{}
+	sql.Register("recording-credentials", drv)
+
+	dbConfig := &DBConfig{Dialect: dialectPostgres, HostName: "localhost", Port: "5432", User: "orders",
+		Password: "s3cr3t", Database: "orders", SSLMode: "disable", MaxIdleConn: 2}
+
+	dsn, err := getDBConnectionString(dbConfig)
+	require.NoError(t, err)
+
+	conn, err := newConnector("recording-credentials", dsn)
+	require.NoError(t, err)
+
+	db := &DB{DB: sql.OpenDB(conn), connector: conn, config: dbConfig, logger: logging.NewMockLogger(logging.DEBUG)}
+	defer db.Close()
+
+	require.NoError(t, db.PingContext(context.Background()))
+	assert.Contains(t, drv.last(), "user=orders password=s3cr3t")
+
+	require.NoError(t, db.UpdateCredentials("orders_v2", "n3w"))
+
+	require.NoError(t, db.PingContext(context.Background()))
+	assert.Contains(t, drv.last(), "user=orders_v2 password=n3w", "the idle connections must be replaced")
+	assert.Equal(t, "s3cr3t", db.config.Password, "the config of the DB must not be changed")
+
+	var notConnected *DB
+
+	assert.ErrorIs(t, notConnected.UpdateCredentials("orders", "n3w"), errCredentialsNotUpdatable)
+	assert.ErrorIs(t, (&DB{}).UpdateCredentials("orders", "n3w"), errCredentialsNotUpdatable)
+}
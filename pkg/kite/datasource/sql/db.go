@@ -12,9 +12,13 @@ import (
 	"reflect"
 	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/sllt/kite/pkg/kite/datasource"
+	"github.com/sllt/kite/pkg/kite/datasource/sql/qb"
+	"github.com/sllt/kite/pkg/kite/logging"
 )
 
 // DB is a wrapper around sql.DB which provides some more features.
@@ -24,6 +28,17 @@ type DB struct {
 	logger  datasource.Logger
 	config  *DBConfig
 	metrics Metrics
+	// slowQueries explains the queries slower than SLOW_QUERY_THRESHOLD, it is nil when it is not set.
+	slowQueries *slowQueryExplainer
+	// fingerprints records the metrics per query fingerprint, it is nil when they are disabled.
+	fingerprints *fingerprintMetrics
+	// connector opens the connections, it is nil when the DB was not opened by NewSQL.
+	connector *connector
+	// done is closed by Close to stop the goroutines of the connection, it is nil when the DB was not opened by NewSQL.
+	done      chan struct{}
+	closeOnce sync.Once
+	// logFormatter is set by SetLogFormatter, it replaces the LogFormatter of the config.
+	logFormatter atomic.Pointer[LogFormatter]
 }
 
 type Log struct {
@@ -38,11 +53,60 @@ var (
 	errSelectUnsupported    = errors.New("unsupported select destination type")
 )
 
+// LogFormatter rewrites the query and arguments of a Log before it is written, e.g. to interpolate
+// or hide the arguments. Queries are logged in placeholder form with their arguments by default.
+type LogFormatter func(query string, args []any) (string, []any)
+
+// InterpolatedLogFormatter logs queries with the arguments interpolated into the query, after passing them
+// through redact. A nil redact hides all the arguments except numbers, booleans, times and NULLs.
+func InterpolatedLogFormatter(redact qb.RedactFunc) LogFormatter {
+	if redact == nil {
+		redact = qb.RedactStrings
+	}
+
+	return func(query string, args []any) (string, []any) {
+		return qb.InterpolateRedacted(query, args, redact), nil
+	}
+}
+
+// SanitizedLogFormatter logs queries without their arguments and with the literals written into the query
+// replaced by placeholders.
+func SanitizedLogFormatter(query string, _ []any) (string, []any) {
+	return qb.Sanitize(query), nil
+}
+
+func logFormatterFromConfig(format string) LogFormatter {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "interpolated":
+		return InterpolatedLogFormatter(nil)
+	case "sanitized":
+		return SanitizedLogFormatter
+	default:
+		return nil
+	}
+}
+
 func (l *Log) PrettyPrint(writer io.Writer) {
 	fmt.Fprintf(writer, "\u001B[38;5;8m%-32s \u001B[38;5;24m%-6s\u001B[0m %8d\u001B[38;5;8mµs\u001B[0m %s\n",
 		l.Type, "SQL", l.Duration, clean(l.Query))
 }
 
+// Redact implements logging.Redactable, masking the arguments and the literals of the query.
+func (l *Log) Redact(r *logging.Redactor) any {
+	c := *l
+	c.Query = r.String(l.Query)
+
+	if l.Args != nil {
+		c.Args = make([]any, len(l.Args))
+
+		for i, arg := range l.Args {
+			c.Args[i] = r.Field("", arg)
+		}
+	}
+
+	return &c
+}
+
 func clean(query string) string {
 	query = regexp.MustCompile(`\s+`).ReplaceAllString(query, " ")
 	query = strings.TrimSpace(query)
@@ -50,27 +114,64 @@ func clean(query string) string {
 	return query
 }
 
-func sendStats(logger datasource.Logger, metrics Metrics, config *DBConfig, start time.Time, queryType, query string, args ...any) {
-	duration := time.Since(start).Milliseconds()
+// currentLogFormatter returns the formatter set by SetLogFormatter, or else the LogFormatter of the config.
+func currentLogFormatter(set *atomic.Pointer[LogFormatter], config *DBConfig) LogFormatter {
+	if set != nil {
+		if formatter := set.Load(); formatter != nil {
+			return *formatter
+		}
+	}
+
+	if config != nil {
+		return config.LogFormatter
+	}
+
+	return nil
+}
+
+func sendStats(logger datasource.Logger, metrics Metrics, config *DBConfig, formatter LogFormatter, start time.Time,
+	queryType, query string, err error, args ...any) {
+	elapsed := time.Since(start)
+	duration := elapsed.Milliseconds()
 
 	if logger != nil {
+		logQuery, logArgs := query, args
+		if formatter != nil {
+			logQuery, logArgs = formatter(query, args)
+		}
+
 		logger.Debug(&Log{
 			Type:     queryType,
-			Query:    query,
+			Query:    logQuery,
 			Duration: duration,
-			Args:     args,
+			Args:     logArgs,
 		})
 	}
 
 	// This contains the fix for the nil pointer dereference
 	if metrics != nil {
-		metrics.RecordHistogram(context.Background(), "app_sql_stats", float64(duration), "hostname", config.HostName,
-			"database", config.Database, "type", getOperationType(query))
+		labels := append([]string{"hostname", config.HostName, "database", config.Database,
+			"type", getOperationType(query)}, config.metricLabels()...)
+
+		metrics.RecordHistogram(context.Background(), "app_sql_stats", float64(duration), labels...)
+
+		if errors.Is(err, sql.ErrNoRows) {
+			err = nil
+		}
+
+		if m, ok := metrics.(datasource.Metrics); ok {
+			datasource.RecordOperation(context.Background(), m, datasource.Operation{
+				Datasource: "sql", Database: config.Database, Name: getOperationType(query),
+			}, elapsed, err)
+		}
 	}
 }
 
-func (d *DB) sendOperationStats(start time.Time, queryType, query string, args ...any) {
-	sendStats(d.logger, d.metrics, d.config, start, queryType, query, args...)
+func (d *DB) sendOperationStats(start time.Time, queryType, query string, err error, args ...any) {
+	sendStats(d.logger, d.metrics, d.config, currentLogFormatter(&d.logFormatter, d.config), start, queryType, query,
+		err, args...)
+	d.slowQueries.observe(start, query, args)
+	d.fingerprints.record(start, query)
 }
 
 func getOperationType(query string) string {
@@ -81,13 +182,25 @@ func getOperationType(query string) string {
 }
 
 func (d *DB) Query(query string, args ...any) (*sql.Rows, error) {
-	defer d.sendOperationStats(time.Now(), "Query", query, args...)
-	return d.DB.QueryContext(context.Background(), query, args...)
+	start := time.Now()
+	rows, err := d.DB.QueryContext(context.Background(), query, args...)
+	d.sendOperationStats(start, "Query", query, err, args...)
+
+	return rows, err
 }
 
 func (d *DB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
-	defer d.sendOperationStats(time.Now(), "QueryContext", query, args...)
-	return d.DB.QueryContext(ctx, query, args...)
+	start := time.Now()
+	rows, err := d.DB.QueryContext(ctx, query, args...)
+	d.sendOperationStats(start, "QueryContext", query, err, args...)
+
+	return rows, err
+}
+
+// SetLogFormatter sets the formatter of the query logs of the database and its transactions.
+// A nil formatter logs queries in placeholder form with their arguments. It is safe to call while queries run.
+func (d *DB) SetLogFormatter(formatter LogFormatter) {
+	d.logFormatter.Store(&formatter)
 }
 
 func (d *DB) Dialect() string {
@@ -95,28 +208,43 @@ func (d *DB) Dialect() string {
 }
 
 func (d *DB) QueryRow(query string, args ...any) *sql.Row {
-	defer d.sendOperationStats(time.Now(), "QueryRow", query, args...)
-	return d.DB.QueryRowContext(context.Background(), query, args...)
+	start := time.Now()
+	row := d.DB.QueryRowContext(context.Background(), query, args...)
+	d.sendOperationStats(start, "QueryRow", query, row.Err(), args...)
+
+	return row
 }
 
 func (d *DB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
-	defer d.sendOperationStats(time.Now(), "QueryRowContext", query, args...)
-	return d.DB.QueryRowContext(ctx, query, args...)
+	start := time.Now()
+	row := d.DB.QueryRowContext(ctx, query, args...)
+	d.sendOperationStats(start, "QueryRowContext", query, row.Err(), args...)
+
+	return row
 }
 
 func (d *DB) Exec(query string, args ...any) (sql.Result, error) {
-	defer d.sendOperationStats(time.Now(), "Exec", query, args...)
-	return d.DB.ExecContext(context.Background(), query, args...)
+	start := time.Now()
+	res, err := d.DB.ExecContext(context.Background(), query, args...)
+	d.sendOperationStats(start, "Exec", query, err, args...)
+
+	return res, err
 }
 
 func (d *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
-	defer d.sendOperationStats(time.Now(), "ExecContext", query, args...)
-	return d.DB.ExecContext(ctx, query, args...)
+	start := time.Now()
+	res, err := d.DB.ExecContext(ctx, query, args...)
+	d.sendOperationStats(start, "ExecContext", query, err, args...)
+
+	return res, err
 }
 
 func (d *DB) Prepare(query string) (*sql.Stmt, error) {
-	defer d.sendOperationStats(time.Now(), "Prepare", query)
-	return d.DB.PrepareContext(context.Background(), query)
+	start := time.Now()
+	stmt, err := d.DB.PrepareContext(context.Background(), query)
+	d.sendOperationStats(start, "Prepare", query, err)
+
+	return stmt, err
 }
 
 func (d *DB) Begin() (*Tx, error) {
@@ -125,10 +253,15 @@ func (d *DB) Begin() (*Tx, error) {
 		return nil, err
 	}
 
-	return &Tx{Tx: tx, config: d.config, logger: d.logger, metrics: d.metrics}, nil
+	return &Tx{Tx: tx, db: d.DB, config: d.config, logFormatter: &d.logFormatter, logger: d.logger, metrics: d.metrics,
+		slowQueries: d.slowQueries, fingerprints: d.fingerprints}, nil
 }
 
 func (d *DB) Close() error {
+	if d.done != nil {
+		d.closeOnce.Do(func() { close(d.done) })
+	}
+
 	if d.DB != nil {
 		return d.DB.Close()
 	}
@@ -138,58 +271,96 @@ func (d *DB) Close() error {
 
 type Tx struct {
 	*sql.Tx
-	config  *DBConfig
-	logger  datasource.Logger
-	metrics Metrics
-}
-
-func (t *Tx) sendOperationStats(start time.Time, queryType, query string, args ...any) {
-	sendStats(t.logger, t.metrics, t.config, start, queryType, query, args...)
+	// db is the database of the transaction, see WithTx.
+	db     *sql.DB
+	config *DBConfig
+	// logFormatter is the formatter set on the database of the transaction by SetLogFormatter.
+	logFormatter *atomic.Pointer[LogFormatter]
+	logger       datasource.Logger
+	metrics      Metrics
+	slowQueries  *slowQueryExplainer
+	fingerprints *fingerprintMetrics
+	// savepoints is the number of the nested WithTransaction calls running in the transaction.
+	savepoints atomic.Int64
+}
+
+func (t *Tx) sendOperationStats(start time.Time, queryType, query string, err error, args ...any) {
+	sendStats(t.logger, t.metrics, t.config, currentLogFormatter(t.logFormatter, t.config), start, queryType, query,
+		err, args...)
+	t.slowQueries.observe(start, query, args)
+	t.fingerprints.record(start, query)
 }
 
 func (t *Tx) Query(query string, args ...any) (*sql.Rows, error) {
-	defer t.sendOperationStats(time.Now(), "TxQuery", query, args...)
-	return t.Tx.QueryContext(context.Background(), query, args...)
+	start := time.Now()
+	rows, err := t.Tx.QueryContext(context.Background(), query, args...)
+	t.sendOperationStats(start, "TxQuery", query, err, args...)
+
+	return rows, err
 }
 
 func (t *Tx) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
-	defer t.sendOperationStats(time.Now(), "TxQueryContext", query, args...)
-	return t.Tx.QueryContext(ctx, query, args...)
+	start := time.Now()
+	rows, err := t.Tx.QueryContext(ctx, query, args...)
+	t.sendOperationStats(start, "TxQueryContext", query, err, args...)
+
+	return rows, err
 }
 
 func (t *Tx) QueryRow(query string, args ...any) *sql.Row {
-	defer t.sendOperationStats(time.Now(), "TxQueryRow", query, args...)
-	return t.Tx.QueryRowContext(context.Background(), query, args...)
+	start := time.Now()
+	row := t.Tx.QueryRowContext(context.Background(), query, args...)
+	t.sendOperationStats(start, "TxQueryRow", query, row.Err(), args...)
+
+	return row
 }
 
 func (t *Tx) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
-	defer t.sendOperationStats(time.Now(), "TxQueryRowContext", query, args...)
-	return t.Tx.QueryRowContext(ctx, query, args...)
+	start := time.Now()
+	row := t.Tx.QueryRowContext(ctx, query, args...)
+	t.sendOperationStats(start, "TxQueryRowContext", query, row.Err(), args...)
+
+	return row
 }
 
 func (t *Tx) Exec(query string, args ...any) (sql.Result, error) {
-	defer t.sendOperationStats(time.Now(), "TxExec", query, args...)
-	return t.Tx.ExecContext(context.Background(), query, args...)
+	start := time.Now()
+	res, err := t.Tx.ExecContext(context.Background(), query, args...)
+	t.sendOperationStats(start, "TxExec", query, err, args...)
+
+	return res, err
 }
 
 func (t *Tx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
-	defer t.sendOperationStats(time.Now(), "TxExecContext", query, args...)
-	return t.Tx.ExecContext(ctx, query, args...)
+	start := time.Now()
+	res, err := t.Tx.ExecContext(ctx, query, args...)
+	t.sendOperationStats(start, "TxExecContext", query, err, args...)
+
+	return res, err
 }
 
 func (t *Tx) Prepare(query string) (*sql.Stmt, error) {
-	defer t.sendOperationStats(time.Now(), "TxPrepare", query)
-	return t.Tx.PrepareContext(context.Background(), query)
+	start := time.Now()
+	stmt, err := t.Tx.PrepareContext(context.Background(), query)
+	t.sendOperationStats(start, "TxPrepare", query, err)
+
+	return stmt, err
 }
 
 func (t *Tx) Commit() error {
-	defer t.sendOperationStats(time.Now(), "TxCommit", "COMMIT")
-	return t.Tx.Commit()
+	start := time.Now()
+	err := t.Tx.Commit()
+	t.sendOperationStats(start, "TxCommit", "COMMIT", err)
+
+	return err
 }
 
 func (t *Tx) Rollback() error {
-	defer t.sendOperationStats(time.Now(), "TxRollback", "ROLLBACK")
-	return t.Tx.Rollback()
+	start := time.Now()
+	err := t.Tx.Rollback()
+	t.sendOperationStats(start, "TxRollback", "ROLLBACK", err)
+
+	return err
 }
 
 // Select runs a query with args and binds the result of the query to data.
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
+	"sync"
 	"testing"
 	"time"
 
@@ -31,7 +32,7 @@ func getDB(t *testing.T, logLevel logging.Level) (*DB, sqlmock.Sqlmock) {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 
-	db := &DB{mockDB, logging.NewMockLogger(logLevel), nil, nil}
+	db := &DB{DB: mockDB, logger: logging.NewMockLogger(logLevel)}
 	db.config = &DBConfig{}
 
 	return db, mock
@@ -1119,6 +1120,94 @@ func TestTx_RollbackError(t *testing.T) {
 	assert.Contains(t, out, "TxRollback ROLLBACK")
 }
 
+func TestSendStats_LogFormatter(t *testing.T) {
+	query := "SELECT * FROM users WHERE email=? AND age=?"
+
+	testCases := []struct {
+		desc      string
+		formatter LogFormatter
+		contains  []string
+		excludes  []string
+	}{
+		{"placeholder form by default", nil, []string{"email=? AND age=?", "a@b.c"}, nil},
+		{"interpolated with redaction", logFormatterFromConfig("interpolated"),
+			[]string{"email='[REDACTED]' AND age=30"}, []string{"a@b.c", `"args"`}},
+		{"sanitized", logFormatterFromConfig("sanitized"), []string{"email=? AND age=?"}, []string{"a@b.c", `"args"`}},
+	}
+
+	for i, tc := range testCases {
+		out := testutil.StdoutOutputForFunc(func() {
+			sendStats(logging.NewMockLogger(logging.DEBUG), nil, &DBConfig{}, tc.formatter,
+				time.Now(), "Query", query, nil, "a@b.c", 30)
+		})
+
+		for _, s := range tc.contains {
+			assert.Contains(t, out, s, "TEST[%d], Failed.\n%s", i, tc.desc)
+		}
+
+		for _, s := range tc.excludes {
+			assert.NotContains(t, out, s, "TEST[%d], Failed.\n%s", i, tc.desc)
+		}
+	}
+}
+
+func TestDB_SetLogFormatter(t *testing.T) {
+	db := &DB{config: &DBConfig{}}
+	tx := &Tx{config: db.config, logFormatter: &db.logFormatter}
+
+	query := "SELECT * FROM users WHERE email=?"
+
+	// logs returns the logs of f, the logger writes to the stdout of the moment it is created.
+	logs := func(f func()) string {
+		return testutil.StdoutOutputForFunc(func() {
+			db.logger = logging.NewMockLogger(logging.DEBUG)
+			tx.logger = db.logger
+
+			f()
+		})
+	}
+
+	logs(func() {
+		var wg sync.WaitGroup
+
+		// the formatter is set while the database and its transaction log queries.
+		for range 4 {
+			wg.Add(3)
+
+			go func() {
+				defer wg.Done()
+
+				db.SetLogFormatter(SanitizedLogFormatter)
+			}()
+
+			go func() {
+				defer wg.Done()
+
+				db.sendOperationStats(time.Now(), "Query", query, nil, "a@b.c")
+			}()
+
+			go func() {
+				defer wg.Done()
+
+				tx.sendOperationStats(time.Now(), "TxQuery", query, nil, "a@b.c")
+			}()
+		}
+
+		wg.Wait()
+	})
+
+	out := logs(func() { tx.sendOperationStats(time.Now(), "TxQuery", query, nil, "a@b.c") })
+
+	assert.Contains(t, out, "email=?")
+	assert.NotContains(t, out, "a@b.c", "the transaction uses the formatter of its database")
+
+	db.SetLogFormatter(nil)
+
+	out = logs(func() { db.sendOperationStats(time.Now(), "Query", query, nil, "a@b.c") })
+
+	assert.Contains(t, out, "a@b.c", "a nil formatter logs the arguments")
+}
+
 func TestPrettyPrint(t *testing.T) {
 	b := make([]byte, 0)
 	w := bytes.NewBuffer(b)
@@ -1246,7 +1335,7 @@ func TestDB_sendOperationStats_RecordsMilliseconds(t *testing.T) {
 		"hostname", "host", "database", "db", "type", "SELECT",
 	)
 
-	db.sendOperationStats(start, "SELECT", "SELECT * FROM users")
+	db.sendOperationStats(start, "SELECT", "SELECT * FROM users", nil)
 
 	duration := time.Since(start).Milliseconds()
 	assert.Equal(t, int64(1500), duration)
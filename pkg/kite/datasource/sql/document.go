@@ -0,0 +1,183 @@
+package sql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sllt/kite/pkg/kite/datasource/sql/qb"
+)
+
+var (
+	errDocumentStoreDialect = errors.New("document store requires the postgres dialect")
+	errDocumentEmptyID      = errors.New("document id cannot be empty")
+)
+
+// DocumentStore stores JSON documents in a Postgres table, keyed by id, with a jsonb data column.
+// It gives a document-store style API on top of an existing Postgres database: documents are
+// written with Put, read with Get and filtered with JSONPath predicates with Query.
+//
+// The table is created with DocumentTableQuery, usually from a migration, and its data column
+// can be indexed with GINIndexQuery so that the filters of Query use the index.
+//
+// Operations run inside the transaction carried by the context, if any (see WithTx).
+type DocumentStore struct {
+	db      Executor
+	builder *qb.Builder
+	table   string
+}
+
+// DocumentDB is the database a DocumentStore runs on. It is implemented by DB, so that ctx.SQL can be used.
+type DocumentDB interface {
+	Executor
+	Dialect() string
+}
+
+// NewDocumentStore returns a DocumentStore for table. db must use the postgres dialect.
+func NewDocumentStore(db DocumentDB, table string) (*DocumentStore, error) {
+	b, err := qb.FromDB(db)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.Dialect() != qb.DialectPostgres {
+		return nil, fmt.Errorf("%w, got %q", errDocumentStoreDialect, db.Dialect())
+	}
+
+	return &DocumentStore{db: db, builder: b, table: table}, nil
+}
+
+// DocumentTableQuery returns the statement creating the table of a DocumentStore, if it does not exist.
+func DocumentTableQuery(table string) string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+    id TEXT PRIMARY KEY,
+    data JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);`, table)
+}
+
+// GINIndexQuery returns the statement creating a GIN index on the jsonb column of table, if it does not exist.
+// With pathOps the index uses the jsonb_path_ops operator class, which is smaller and faster but only
+// supports the @>, @? and @@ operators used by qb.JsonbContains and qb.JsonbPathMatch.
+func GINIndexQuery(table, column string, pathOps bool) string {
+	opClass := ""
+	if pathOps {
+		opClass = " jsonb_path_ops"
+	}
+
+	return fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s_%s_gin ON %s USING GIN (%s%s);`, table, column, table, column, opClass)
+}
+
+// Put inserts the document with the given id, or replaces it if it exists. doc is marshaled to JSON.
+func (s *DocumentStore) Put(ctx context.Context, id string, doc any) error {
+	if id == "" {
+		return errDocumentEmptyID
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+
+	query, args, err := s.builder.BuildUpsert(s.table,
+		[]map[string]any{{"id": id, "data": string(data), "created_at": now, "updated_at": now}},
+		[]string{"id"},
+		map[string]any{"data": string(data), "updated_at": now})
+	if err != nil {
+		return err
+	}
+
+	_, err = ExecutorFromContext(ctx, s.db).ExecContext(ctx, query, args...)
+
+	return err
+}
+
+// Get unmarshals the document with the given id into dest. It returns sql.ErrNoRows if the document does not exist.
+func (s *DocumentStore) Get(ctx context.Context, id string, dest any) error {
+	query, args, err := s.builder.BuildSelect(s.table, map[string]any{"id": id}, []string{"data"})
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+
+	if err = ExecutorFromContext(ctx, s.db).QueryRowContext(ctx, query, args...).Scan(&data); err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, dest)
+}
+
+// Query unmarshals the documents matching the JSONPath predicate filter, eg `$.status == "active"`, into dest,
+// which must be a pointer to a slice. An empty filter matches all the documents.
+//
+// where takes the keys of the where map of qb, eg "_orderby" and "_limit", to refine the query:
+//
+//	var orders []Order
+//	err := store.Query(ctx, &orders, `$.total > 100`, map[string]any{"_orderby": "updated_at desc", "_limit": []uint{0, 20}})
+func (s *DocumentStore) Query(ctx context.Context, dest any, filter string, where map[string]any) error {
+	conditions := make(map[string]any, len(where)+1)
+	for k, v := range where {
+		conditions[k] = v
+	}
+
+	if filter != "" {
+		conditions["_custom_jsonpath"] = qb.JsonbPathMatch("data", filter)
+	}
+
+	query, args, err := s.builder.BuildSelect(s.table, conditions, []string{"data"})
+	if err != nil {
+		return err
+	}
+
+	rows, err := ExecutorFromContext(ctx, s.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	// the documents are gathered into a JSON array, so that dest can be a slice of any type.
+	var buf bytes.Buffer
+
+	buf.WriteByte('[')
+
+	for i := 0; rows.Next(); i++ {
+		var data []byte
+
+		if err = rows.Scan(&data); err != nil {
+			return err
+		}
+
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		buf.Write(data)
+	}
+
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	buf.WriteByte(']')
+
+	return json.Unmarshal(buf.Bytes(), dest)
+}
+
+// Delete deletes the document with the given id. Deleting a document which does not exist is not an error.
+func (s *DocumentStore) Delete(ctx context.Context, id string) error {
+	query, args, err := s.builder.BuildDelete(s.table, map[string]any{"id": id})
+	if err != nil {
+		return err
+	}
+
+	_, err = ExecutorFromContext(ctx, s.db).ExecContext(ctx, query, args...)
+
+	return err
+}
@@ -0,0 +1,120 @@
+package sql
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sllt/kite/pkg/kite/logging"
+)
+
+type testDocument struct {
+	Name  string   `json:"name"`
+	Total int      `json:"total"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+func getDocumentStore(t *testing.T) (*DocumentStore, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock := getDB(t, logging.INFO)
+	db.config.Dialect = dialectPostgres
+
+	t.Cleanup(func() { db.DB.Close() })
+
+	store, err := NewDocumentStore(db, "orders")
+	require.NoError(t, err)
+
+	return store, mock
+}
+
+func TestNewDocumentStore_RequiresPostgres(t *testing.T) {
+	db, _ := getDB(t, logging.INFO)
+	defer db.DB.Close()
+
+	db.config.Dialect = "mysql"
+
+	store, err := NewDocumentStore(db, "orders")
+
+	assert.Nil(t, store)
+	require.ErrorIs(t, err, errDocumentStoreDialect)
+}
+
+func TestDocumentStore_Put(t *testing.T) {
+	store, mock := getDocumentStore(t)
+
+	mock.ExpectExec("INSERT INTO orders (created_at,data,id,updated_at) VALUES ($1,$2,$3,$4) "+
+		"ON CONFLICT (id) DO UPDATE SET data=$5,updated_at=$6").
+		WithArgs(sqlmock.AnyArg(), `{"name":"book","total":12}`, "o-1", sqlmock.AnyArg(),
+			`{"name":"book","total":12}`, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := store.Put(t.Context(), "o-1", testDocument{Name: "book", Total: 12})
+	require.NoError(t, err)
+
+	require.ErrorIs(t, store.Put(t.Context(), "", testDocument{}), errDocumentEmptyID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentStore_Get(t *testing.T) {
+	store, mock := getDocumentStore(t)
+
+	mock.ExpectQuery("SELECT data FROM orders WHERE (id=$1)").WithArgs("o-1").
+		WillReturnRows(sqlmock.NewRows([]string{"data"}).AddRow([]byte(`{"name":"book","total":12}`)))
+	mock.ExpectQuery("SELECT data FROM orders WHERE (id=$1)").WithArgs("o-2").
+		WillReturnRows(sqlmock.NewRows([]string{"data"}))
+
+	var doc testDocument
+
+	require.NoError(t, store.Get(t.Context(), "o-1", &doc))
+	assert.Equal(t, testDocument{Name: "book", Total: 12}, doc)
+
+	require.ErrorIs(t, store.Get(t.Context(), "o-2", &doc), sql.ErrNoRows)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentStore_Query(t *testing.T) {
+	store, mock := getDocumentStore(t)
+
+	mock.ExpectQuery("SELECT data FROM orders WHERE (data @@ $1::jsonpath) ORDER BY updated_at DESC LIMIT $2 OFFSET $3").
+		WithArgs("$.total > 10", 20, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"data"}).
+			AddRow([]byte(`{"name":"book","total":12}`)).
+			AddRow([]byte(`{"name":"pen","total":15,"tags":["new"]}`)))
+
+	var docs []testDocument
+
+	err := store.Query(t.Context(), &docs, "$.total > 10", map[string]any{"_orderby": "updated_at desc", "_limit": []uint{0, 20}})
+	require.NoError(t, err)
+	assert.Equal(t, []testDocument{{Name: "book", Total: 12}, {Name: "pen", Total: 15, Tags: []string{"new"}}}, docs)
+
+	mock.ExpectQuery("SELECT data FROM orders").
+		WillReturnRows(sqlmock.NewRows([]string{"data"}))
+
+	docs = nil
+
+	require.NoError(t, store.Query(t.Context(), &docs, "", nil))
+	assert.Empty(t, docs)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentStore_Delete(t *testing.T) {
+	store, mock := getDocumentStore(t)
+
+	mock.ExpectExec("DELETE FROM orders WHERE (id=$1)").WithArgs("o-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, store.Delete(t.Context(), "o-1"))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentStore_DDL(t *testing.T) {
+	assert.Contains(t, DocumentTableQuery("orders"), "CREATE TABLE IF NOT EXISTS orders (")
+	assert.Equal(t, "CREATE INDEX IF NOT EXISTS orders_data_gin ON orders USING GIN (data);",
+		GINIndexQuery("orders", "data", false))
+	assert.Equal(t, "CREATE INDEX IF NOT EXISTS orders_data_gin ON orders USING GIN (data jsonb_path_ops);",
+		GINIndexQuery("orders", "data", true))
+}
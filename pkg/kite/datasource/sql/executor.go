@@ -5,11 +5,105 @@ import (
 	"database/sql"
 )
 
-// Executor captures the query operations shared by DB and Tx.
-// It is useful for transaction-aware repositories that can run against either.
-type Executor interface {
+// Execer runs statements which do not return rows. It is implemented by DB and Tx.
+type Execer interface {
 	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Queryer runs queries which return rows. It is implemented by DB and Tx.
+type Queryer interface {
 	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
 	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Selector runs queries and binds their result. It is implemented by DB and Tx.
+type Selector interface {
 	Select(ctx context.Context, data any, query string, args ...any) error
 }
+
+// Executor captures the query operations shared by DB and Tx.
+// It is useful for transaction-aware repositories that can run against either.
+type Executor interface {
+	Execer
+	Queryer
+	Selector
+}
+
+var (
+	_ Executor = (*DB)(nil)
+	_ Executor = (*Tx)(nil)
+)
+
+// txContextKey is the key of the transactions in a context, by database. The key without database is the one of the
+// last transaction, see FromContext.
+type txContextKey struct {
+	db *sql.DB
+}
+
+// WithTx returns a copy of ctx which carries tx, so that the repositories of its database called with it run inside
+// the transaction. The transactions of the other databases carried by ctx are kept, see ExecutorFromContext.
+func WithTx(ctx context.Context, tx *Tx) context.Context {
+	ctx = context.WithValue(ctx, txContextKey{}, tx)
+
+	if tx != nil && tx.db != nil {
+		ctx = context.WithValue(ctx, txContextKey{db: tx.db}, tx)
+	}
+
+	return ctx
+}
+
+// FromContext returns the last transaction carried by ctx, if any, whichever its database.
+func FromContext(ctx context.Context) (*Tx, bool) {
+	tx, ok := ctx.Value(txContextKey{}).(*Tx)
+
+	return tx, ok && tx != nil
+}
+
+// ExecutorFromContext returns the transaction of the database of db carried by ctx, or db if ctx does not carry one,
+// so that a repository of another database, e.g. ctx.NamedSQL("analytics"), does not run in the transaction of the
+// primary one. When the database of db is unknown, e.g. for the resolver of AddDBResolver, the last transaction of ctx
+// is returned.
+//
+// Repositories use it to run inside the transaction started by the caller, if any:
+//
+//	func (r *UserRepo) Rename(ctx context.Context, id int, name string) error {
+//		_, err := sql.ExecutorFromContext(ctx, r.db).ExecContext(ctx, "UPDATE users SET name=? WHERE id=?", name, id)
+//		return err
+//	}
+//
+//	// in the service layer
+//	tx, _ := db.Begin()
+//	ctx = sql.WithTx(ctx, tx)
+//	err := repo.Rename(ctx, 1, "kite")
+func ExecutorFromContext(ctx context.Context, db Executor) Executor {
+	if tx, ok := transactionOf(ctx, db); ok {
+		return tx
+	}
+
+	return db
+}
+
+// transactionOf returns the transaction of the database of db carried by ctx, or the last one when the database of db
+// is unknown.
+func transactionOf(ctx context.Context, db any) (*Tx, bool) {
+	var database *sql.DB
+
+	switch d := db.(type) {
+	case *DB:
+		if d != nil {
+			database = d.DB
+		}
+	case *Tx:
+		if d != nil {
+			database = d.db
+		}
+	}
+
+	if database == nil {
+		return FromContext(ctx)
+	}
+
+	tx, ok := ctx.Value(txContextKey{db: database}).(*Tx)
+
+	return tx, ok && tx != nil
+}
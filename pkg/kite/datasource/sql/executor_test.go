@@ -0,0 +1,121 @@
+package sql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sllt/kite/pkg/kite/logging"
+)
+
+func renameUser(ctx context.Context, db Executor, name string, id int) error {
+	_, err := ExecutorFromContext(ctx, db).ExecContext(ctx, "UPDATE users SET name=? WHERE id=?", name, id)
+
+	return err
+}
+
+func TestExecutorFromContext_WithoutTx(t *testing.T) {
+	db, mock := getDB(t, logging.DEBUG)
+	defer db.DB.Close()
+
+	_, ok := FromContext(t.Context())
+	assert.False(t, ok)
+	assert.Same(t, db, ExecutorFromContext(t.Context(), db))
+
+	mock.ExpectExec("UPDATE users SET name=? WHERE id=?").WithArgs("kite", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, renameUser(t.Context(), db, "kite", 1))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExecutorFromContext_WithTx(t *testing.T) {
+	db, mock := getDB(t, logging.DEBUG)
+	defer db.DB.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users SET name=? WHERE id=?").WithArgs("kite", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectRollback()
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+
+	ctx := WithTx(t.Context(), tx)
+
+	fromCtx, ok := FromContext(ctx)
+	require.True(t, ok)
+	assert.Same(t, tx, fromCtx)
+	assert.Same(t, tx, ExecutorFromContext(ctx, db))
+
+	require.NoError(t, renameUser(ctx, db, "kite", 1))
+	require.NoError(t, tx.Rollback())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExecutorFromContext_TwoDBs(t *testing.T) {
+	primary, primaryMock := getDB(t, logging.DEBUG)
+	defer primary.DB.Close()
+
+	analytics, analyticsMock := getDB(t, logging.DEBUG)
+	defer analytics.DB.Close()
+
+	primaryMock.ExpectBegin()
+	primaryMock.ExpectExec("UPDATE users SET name=? WHERE id=?").WithArgs("kite", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	primaryMock.ExpectCommit()
+
+	// the statement of the analytics DB does not run in the transaction of the primary one.
+	analyticsMock.ExpectExec("UPDATE users SET name=? WHERE id=?").WithArgs("kite", 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	tx, err := primary.Begin()
+	require.NoError(t, err)
+
+	ctx := WithTx(t.Context(), tx)
+
+	assert.Same(t, tx, ExecutorFromContext(ctx, primary))
+	assert.Same(t, analytics, ExecutorFromContext(ctx, analytics))
+
+	require.NoError(t, renameUser(ctx, primary, "kite", 1))
+	require.NoError(t, renameUser(ctx, analytics, "kite", 2))
+	require.NoError(t, tx.Commit())
+
+	require.NoError(t, primaryMock.ExpectationsWereMet())
+	require.NoError(t, analyticsMock.ExpectationsWereMet())
+}
+
+func TestExecutorFromContext_TransactionPerDB(t *testing.T) {
+	primary, primaryMock := getDB(t, logging.DEBUG)
+	defer primary.DB.Close()
+
+	analytics, analyticsMock := getDB(t, logging.DEBUG)
+	defer analytics.DB.Close()
+
+	primaryMock.ExpectBegin()
+	analyticsMock.ExpectBegin()
+
+	primaryTx, err := primary.Begin()
+	require.NoError(t, err)
+
+	analyticsTx, err := analytics.Begin()
+	require.NoError(t, err)
+
+	ctx := WithTx(WithTx(t.Context(), primaryTx), analyticsTx)
+
+	assert.Same(t, primaryTx, ExecutorFromContext(ctx, primary))
+	assert.Same(t, analyticsTx, ExecutorFromContext(ctx, analytics))
+	assert.Same(t, analyticsTx, ExecutorFromContext(ctx, struct{ Executor }{primary}),
+		"the last transaction for an unknown database")
+
+	last, ok := FromContext(ctx)
+	require.True(t, ok)
+	assert.Same(t, analyticsTx, last)
+}
+
+func TestFromContext_NilTx(t *testing.T) {
+	_, ok := FromContext(WithTx(t.Context(), nil))
+
+	assert.False(t, ok)
+}
@@ -0,0 +1,120 @@
+package sql
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sllt/kite/pkg/kite/config"
+	"github.com/sllt/kite/pkg/kite/datasource"
+	"github.com/sllt/kite/pkg/kite/datasource/sql/qb"
+)
+
+const (
+	// MetricFingerprintQueries counts the queries of each fingerprint.
+	MetricFingerprintQueries = "app_sql_fingerprint_queries_total"
+	// MetricFingerprintDuration is the histogram of the response time of the queries of each fingerprint in
+	// milliseconds.
+	MetricFingerprintDuration = "app_sql_fingerprint_duration"
+
+	// OtherFingerprint labels the queries whose fingerprint exceeds DB_METRICS_MAX_FINGERPRINTS.
+	OtherFingerprint = "other"
+
+	defaultMaxFingerprints = 200
+	// maxCachedQueries bounds the queries whose fingerprint is cached, so that queries with literals do not grow
+	// the cache forever.
+	maxCachedQueries = 4096
+)
+
+// fingerprintMetrics records the metrics of the queries per fingerprint, see qb.Fingerprint. The number of
+// fingerprints is capped, the queries of the fingerprints seen after the cap is reached are recorded as
+// OtherFingerprint.
+type fingerprintMetrics struct {
+	metrics Metrics
+	logger  datasource.Logger
+	labels  []string
+	max     int
+
+	mu           sync.Mutex
+	fingerprints map[string]struct{}
+	cache        map[string]string
+}
+
+// newFingerprintMetrics returns the per-fingerprint metrics of the database, capped to DB_METRICS_MAX_FINGERPRINTS
+// fingerprints. It returns nil when the cap is 0 or there are no metrics.
+func newFingerprintMetrics(configs config.Config, dbConfig *DBConfig, metrics Metrics,
+	logger datasource.Logger) *fingerprintMetrics {
+	if metrics == nil {
+		return nil
+	}
+
+	maxFingerprints := defaultMaxFingerprints
+
+	if value := configs.Get("DB_METRICS_MAX_FINGERPRINTS"); value != "" {
+		v, err := strconv.Atoi(value)
+		if err != nil || v < 0 {
+			logger.Warnf("invalid DB_METRICS_MAX_FINGERPRINTS %q, using %d", value, defaultMaxFingerprints)
+		} else {
+			maxFingerprints = v
+		}
+	}
+
+	if maxFingerprints == 0 {
+		return nil
+	}
+
+	return &fingerprintMetrics{
+		metrics:      metrics,
+		logger:       logger,
+		labels:       append([]string{"database", dbConfig.Database}, dbConfig.metricLabels()...),
+		max:          maxFingerprints,
+		fingerprints: make(map[string]struct{}),
+		cache:        make(map[string]string),
+	}
+}
+
+// record records the metrics of the query started at start.
+func (f *fingerprintMetrics) record(start time.Time, query string) {
+	if f == nil {
+		return
+	}
+
+	duration := float64(time.Since(start).Microseconds()) / 1000
+	labels := append([]string{"fingerprint", f.label(query)}, f.labels...)
+
+	f.metrics.RecordHistogram(context.Background(), MetricFingerprintDuration, duration, labels...)
+
+	if m, ok := f.metrics.(datasource.Metrics); ok {
+		m.IncrementCounter(context.Background(), MetricFingerprintQueries, labels...)
+	}
+}
+
+// label returns the fingerprint label of query.
+func (f *fingerprintMetrics) label(query string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if label, ok := f.cache[query]; ok {
+		return label
+	}
+
+	label := qb.Fingerprint(query)
+
+	if _, ok := f.fingerprints[label]; !ok {
+		if len(f.fingerprints) >= f.max {
+			label = OtherFingerprint
+		} else {
+			f.fingerprints[label] = struct{}{}
+
+			// the logs map the fingerprints of the metrics to their queries.
+			f.logger.Debugf("SQL query fingerprint %s: %s", label, qb.Normalize(query))
+		}
+	}
+
+	if len(f.cache) < maxCachedQueries {
+		f.cache[query] = label
+	}
+
+	return label
+}
@@ -0,0 +1,99 @@
+package sql
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sllt/kite/pkg/kite/config"
+	"github.com/sllt/kite/pkg/kite/datasource/sql/qb"
+	"github.com/sllt/kite/pkg/kite/logging"
+)
+
+// recordingMetrics records the fingerprint labels of the metrics.
+type recordingMetrics struct {
+	mu         sync.Mutex
+	histograms map[string]int
+	counters   map[string]int
+}
+
+func newRecordingMetrics() *recordingMetrics {
+	return &recordingMetrics{histograms: make(map[string]int), counters: make(map[string]int)}
+}
+
+func (m *recordingMetrics) RecordHistogram(_ context.Context, name string, _ float64, labels ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if name == MetricFingerprintDuration {
+		m.histograms[fingerprintLabel(labels)]++
+	}
+}
+
+func (m *recordingMetrics) IncrementCounter(_ context.Context, name string, labels ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if name == MetricFingerprintQueries {
+		m.counters[fingerprintLabel(labels)]++
+	}
+}
+
+func (*recordingMetrics) SetGauge(string, float64, ...string) {}
+
+func fingerprintLabel(labels []string) string {
+	for i := 0; i+1 < len(labels); i += 2 {
+		if labels[i] == "fingerprint" {
+			return labels[i+1]
+		}
+	}
+
+	return ""
+}
+
+func TestFingerprintMetrics_Record(t *testing.T) {
+	metrics := newRecordingMetrics()
+	f := newFingerprintMetrics(config.NewMockConfig(map[string]string{"DB_METRICS_MAX_FINGERPRINTS": "2"}),
+		&DBConfig{Database: "shop", Name: "orders"}, metrics, logging.NewMockLogger(logging.ERROR))
+	require.NotNil(t, f)
+
+	assert.Equal(t, []string{"database", "shop", "name", "orders"}, f.labels)
+
+	for _, query := range []string{
+		"SELECT * FROM users WHERE id = 1",
+		"SELECT * FROM users WHERE id = 2",
+		"SELECT * FROM orders WHERE id IN (?, ?)",
+		"DELETE FROM sessions WHERE id = ?",
+		"UPDATE users SET name = ? WHERE id = ?",
+	} {
+		f.record(time.Now(), query)
+	}
+
+	users := qb.Fingerprint("SELECT * FROM users WHERE id = ?")
+	orders := qb.Fingerprint("SELECT * FROM orders WHERE id IN (?)")
+
+	expected := map[string]int{users: 2, orders: 1, OtherFingerprint: 2}
+	assert.Equal(t, expected, metrics.histograms)
+	assert.Equal(t, expected, metrics.counters)
+}
+
+func TestFingerprintMetrics_Disabled(t *testing.T) {
+	logger := logging.NewMockLogger(logging.FATAL)
+
+	assert.Nil(t, newFingerprintMetrics(config.NewMockConfig(nil), &DBConfig{}, nil, logger))
+	assert.Nil(t, newFingerprintMetrics(config.NewMockConfig(map[string]string{"DB_METRICS_MAX_FINGERPRINTS": "0"}),
+		&DBConfig{}, newRecordingMetrics(), logger))
+
+	f := newFingerprintMetrics(config.NewMockConfig(map[string]string{"DB_METRICS_MAX_FINGERPRINTS": "many"}),
+		&DBConfig{}, newRecordingMetrics(), logger)
+	require.NotNil(t, f)
+	assert.Equal(t, defaultMaxFingerprints, f.max)
+
+	// a nil registry records nothing.
+	var disabled *fingerprintMetrics
+	disabled.record(time.Now(), "SELECT 1")
+}
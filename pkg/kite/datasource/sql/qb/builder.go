@@ -37,6 +37,7 @@ var (
 		"_having":   struct{}{},
 		"_limit":    struct{}{},
 		"_lockMode": struct{}{},
+		"_select":   struct{}{},
 	}
 )
 
@@ -77,17 +78,21 @@ func BuildSelect(table string, where map[string]interface{}, selectField []strin
 // BuildSelect work as its name says.
 // supported operators including: =,in,>,>=,<,<=,<>,!=.
 // key without operator will be regarded as =.
-// special key begin with _: _orderby,_groupby,_limit,_having.
+// special key begin with _: _orderby,_groupby,_limit,_having,_select.
 // the value of _limit supports int/uint/int64/uint64 and integer slices with one or two elements (ie: []uint{0, 100}).
 // the value of _having must be a map just like where but only support =,in,>,>=,<,<=,<>,!=
+// the value of _select must be a Comparable or []Comparable (ie: a Case expression) appended to the select fields.
 // for more examples,see README.md or open a issue.
 func (b Builder) BuildSelect(table string, where map[string]interface{}, selectField []string) (cond string, vals []interface{}, err error) {
+	table = b.tableName(table)
+
 	var orderBy string
 	var limit *eleLimit
 	var groupBy string
 	var having map[string]interface{}
 	var lockMode string
 	var lockClause string
+	var selectExprs []Comparable
 
 	if where == nil {
 		where = map[string]interface{}{}
@@ -133,12 +138,18 @@ func (b Builder) BuildSelect(table string, where map[string]interface{}, selectF
 			return
 		}
 	}
-	conditions, err := getWhereConditions(where, defaultIgnoreKeys)
+	if val, ok := where["_select"]; ok {
+		selectExprs, err = parseSelectExprs(val)
+		if err != nil {
+			return
+		}
+	}
+	conditions, err := b.whereConditions(where)
 	if nil != err {
 		return
 	}
 	if having != nil {
-		havingCondition, err1 := getWhereConditions(having, defaultIgnoreKeys)
+		havingCondition, err1 := b.whereConditions(having)
 		if nil != err1 {
 			err = err1
 			return
@@ -147,7 +158,7 @@ func (b Builder) BuildSelect(table string, where map[string]interface{}, selectF
 		conditions = append(conditions, havingCondition...)
 	}
 
-	return b.buildSelect(table, selectField, groupBy, orderBy, lockClause, limit, conditions...)
+	return b.buildSelect(table, selectField, selectExprs, groupBy, orderBy, lockClause, limit, conditions...)
 }
 
 func copyWhere(src map[string]interface{}) (target map[string]interface{}) {
@@ -207,11 +218,13 @@ func BuildUpdate(table string, where map[string]interface{}, update map[string]i
 
 // BuildUpdate work as its name says.
 func (b Builder) BuildUpdate(table string, where map[string]interface{}, update map[string]interface{}) (string, []interface{}, error) {
+	table = b.tableName(table)
+
 	limit, err := getLimit(where)
 	if err != nil {
 		return "", nil, err
 	}
-	conditions, err := getWhereConditions(where, defaultIgnoreKeys)
+	conditions, err := b.whereConditions(where)
 	if nil != err {
 		return "", nil, err
 	}
@@ -226,11 +239,13 @@ func BuildDelete(table string, where map[string]interface{}) (string, []interfac
 
 // BuildDelete work as its name says.
 func (b Builder) BuildDelete(table string, where map[string]interface{}) (string, []interface{}, error) {
+	table = b.tableName(table)
+
 	limit, err := getLimit(where)
 	if err != nil {
 		return "", nil, err
 	}
-	conditions, err := getWhereConditions(where, defaultIgnoreKeys)
+	conditions, err := b.whereConditions(where)
 	if nil != err {
 		return "", nil, err
 	}
@@ -245,6 +260,8 @@ func BuildInsert(table string, data []map[string]interface{}) (string, []interfa
 
 // BuildInsert work as its name says.
 func (b Builder) BuildInsert(table string, data []map[string]interface{}) (string, []interface{}, error) {
+	table = b.tableName(table)
+
 	return b.buildInsert(table, data, commonInsert)
 }
 
@@ -255,6 +272,8 @@ func BuildInsertIgnore(table string, data []map[string]interface{}) (string, []i
 
 // BuildInsertIgnore work as its name says.
 func (b Builder) BuildInsertIgnore(table string, data []map[string]interface{}) (string, []interface{}, error) {
+	table = b.tableName(table)
+
 	return b.buildInsert(table, data, ignoreInsert)
 }
 
@@ -265,6 +284,8 @@ func BuildReplaceInsert(table string, data []map[string]interface{}) (string, []
 
 // BuildReplaceInsert work as its name says.
 func (b Builder) BuildReplaceInsert(table string, data []map[string]interface{}) (string, []interface{}, error) {
+	table = b.tableName(table)
+
 	return b.buildInsert(table, data, replaceInsert)
 }
 
@@ -276,6 +297,8 @@ func BuildInsertOnDuplicate(table string, data []map[string]interface{}, update
 
 // BuildInsertOnDuplicate builds an INSERT ... ON DUPLICATE KEY UPDATE clause.
 func (b Builder) BuildInsertOnDuplicate(table string, data []map[string]interface{}, update map[string]interface{}) (string, []interface{}, error) {
+	table = b.tableName(table)
+
 	return b.buildInsertOnDuplicate(table, data, update)
 }
 
@@ -0,0 +1,238 @@
+package qb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	errBulkUpdateEmptyRows   = errors.New("[builder] bulk update rows cannot be empty")
+	errBulkUpdateEmptyKeys   = errors.New("[builder] bulk update key columns cannot be empty")
+	errBulkUpdateNoColumns   = errors.New("[builder] bulk update rows must contain at least one column besides the key columns")
+	errBulkUpdateRowNotMatch = errors.New("[builder] bulk update rows must contain the same columns")
+)
+
+// BuildBulkUpdate builds a single statement which updates every row with its own values using MySQL dialect
+// for backward-compatible defaults.
+//
+// Each row must contain the key columns, which identify the row to update, and the same set of columns to update.
+func BuildBulkUpdate(table string, rows []map[string]interface{}, keyCols []string) (string, []interface{}, error) {
+	return defaultBuilder.BuildBulkUpdate(table, rows, keyCols)
+}
+
+// BuildBulkUpdateWithDialect builds a bulk update query for the provided dialect.
+func BuildBulkUpdateWithDialect(dialect, table string, rows []map[string]interface{}, keyCols []string) (string, []interface{}, error) {
+	b, err := New(dialect)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return b.BuildBulkUpdate(table, rows, keyCols)
+}
+
+// BuildBulkUpdate builds a bulk update query for the current builder dialect.
+//
+// For PostgreSQL the rows are joined with UPDATE ... FROM (VALUES ...). The values are typed after the columns
+// of the table, so table must be the name of the table and not a view or an expression.
+// For MySQL and SQLite every column is set with a CASE WHEN expression on the key columns.
+func (b Builder) BuildBulkUpdate(table string, rows []map[string]interface{}, keyCols []string) (string, []interface{}, error) {
+	table = b.tableName(table)
+
+	columns, err := resolveBulkUpdateColumns(rows, keyCols)
+	if err != nil {
+		return "", nil, err
+	}
+
+	switch b.dialect {
+	case DialectPostgres:
+		cond, vals := buildBulkUpdateFromValues(table, rows, keyCols, columns)
+		return b.finalizeQuery(cond, vals)
+	case DialectMySQL, DialectSQLite:
+		cond, vals := buildBulkUpdateCase(table, rows, keyCols, columns)
+		return b.finalizeQuery(cond, vals)
+	default:
+		return "", nil, fmt.Errorf("%w: %q", errUnsupportedDialect, b.dialect)
+	}
+}
+
+// resolveBulkUpdateColumns validates the rows and returns the sorted columns to update.
+func resolveBulkUpdateColumns(rows []map[string]interface{}, keyCols []string) ([]string, error) {
+	if len(rows) == 0 {
+		return nil, errBulkUpdateEmptyRows
+	}
+
+	if len(keyCols) == 0 {
+		return nil, errBulkUpdateEmptyKeys
+	}
+
+	keys := make(map[string]struct{}, len(keyCols))
+
+	for _, k := range keyCols {
+		if strings.TrimSpace(k) == "" {
+			return nil, errBulkUpdateEmptyKeys
+		}
+
+		keys[k] = struct{}{}
+	}
+
+	columns := make([]string, 0, len(rows[0]))
+
+	for col := range rows[0] {
+		if _, ok := keys[col]; !ok {
+			columns = append(columns, col)
+		}
+	}
+
+	if len(columns) == 0 {
+		return nil, errBulkUpdateNoColumns
+	}
+
+	defaultSortAlgorithm(columns)
+
+	for _, row := range rows {
+		if len(row) != len(columns)+len(keyCols) {
+			return nil, errBulkUpdateRowNotMatch
+		}
+
+		for _, k := range keyCols {
+			if _, ok := row[k]; !ok {
+				return nil, errBulkUpdateRowNotMatch
+			}
+		}
+
+		for _, col := range columns {
+			if _, ok := row[col]; !ok {
+				return nil, errBulkUpdateRowNotMatch
+			}
+		}
+	}
+
+	return columns, nil
+}
+
+// buildBulkUpdateFromValues builds
+//
+//	UPDATE t SET a=v.a FROM (SELECT (NULL::t).id,(NULL::t).a WHERE false UNION ALL VALUES (?,?),(?,?)) AS v WHERE t.id=v.id
+//
+// The empty typed SELECT gives the placeholders of VALUES the types of the table columns, otherwise
+// PostgreSQL resolves them as text and the comparison with the key columns fails.
+func buildBulkUpdateFromValues(table string, rows []map[string]interface{}, keyCols, columns []string) (string, []interface{}) {
+	all := make([]string, 0, len(keyCols)+len(columns))
+	all = append(all, keyCols...)
+	all = append(all, columns...)
+
+	typed := make([]string, len(all))
+	for i, col := range all {
+		typed[i] = fmt.Sprintf("(NULL::%s).%s", table, quoteField(col))
+	}
+
+	vals := make([]interface{}, 0, len(rows)*len(all))
+	tuples := make([]string, len(rows))
+
+	for i, row := range rows {
+		var sb strings.Builder
+
+		sb.WriteByte('(')
+
+		for j, col := range all {
+			if j > 0 {
+				sb.WriteByte(',')
+			}
+
+			vals = appendCaseValue(&sb, vals, row[col])
+		}
+
+		sb.WriteByte(')')
+		tuples[i] = sb.String()
+	}
+
+	sets := make([]string, len(columns))
+	for i, col := range columns {
+		sets[i] = fmt.Sprintf("%s=v.%s", quoteField(col), quoteField(col))
+	}
+
+	joins := make([]string, len(keyCols))
+	for i, k := range keyCols {
+		joins[i] = fmt.Sprintf("%s.%s=v.%s", table, quoteField(k), quoteField(k))
+	}
+
+	cond := fmt.Sprintf("UPDATE %s SET %s FROM (SELECT %s WHERE false UNION ALL VALUES %s) AS v WHERE %s",
+		quoteField(table), strings.Join(sets, ","), strings.Join(typed, ","), strings.Join(tuples, ","),
+		strings.Join(joins, " AND "))
+
+	return cond, vals
+}
+
+// buildBulkUpdateCase builds
+//
+//	UPDATE t SET a=CASE WHEN id=? THEN ? WHEN id=? THEN ? ELSE a END WHERE id IN (?,?)
+func buildBulkUpdateCase(table string, rows []map[string]interface{}, keyCols, columns []string) (string, []interface{}) {
+	// match is the condition identifying a row, matchVals are its values.
+	match := make([]string, len(keyCols))
+	for i, k := range keyCols {
+		match[i] = assembleExpression(k, "=")
+	}
+
+	rowMatch := strings.Join(match, " AND ")
+	if len(keyCols) > 1 {
+		rowMatch = "(" + rowMatch + ")"
+	}
+
+	matchVals := func(row map[string]interface{}) []interface{} {
+		v := make([]interface{}, len(keyCols))
+		for i, k := range keyCols {
+			v[i] = row[k]
+		}
+
+		return v
+	}
+
+	var vals []interface{}
+
+	sets := make([]string, len(columns))
+
+	for i, col := range columns {
+		var sb strings.Builder
+
+		sb.WriteString(quoteField(col))
+		sb.WriteString("=CASE")
+
+		for _, row := range rows {
+			sb.WriteString(" WHEN ")
+			sb.WriteString(rowMatch)
+			sb.WriteString(" THEN ")
+			vals = append(vals, matchVals(row)...)
+			vals = appendCaseValue(&sb, vals, row[col])
+		}
+
+		sb.WriteString(" ELSE ")
+		sb.WriteString(quoteField(col))
+		sb.WriteString(" END")
+		sets[i] = sb.String()
+	}
+
+	var where string
+
+	if len(keyCols) == 1 {
+		keyVals := make([]interface{}, len(rows))
+		for i, row := range rows {
+			keyVals[i] = row[keyCols[0]]
+		}
+
+		where = buildIn(keyCols[0], keyVals)
+		vals = append(vals, keyVals...)
+	} else {
+		ors := make([]string, len(rows))
+		for i, row := range rows {
+			ors[i] = rowMatch
+			vals = append(vals, matchVals(row)...)
+		}
+
+		where = strings.Join(ors, " OR ")
+	}
+
+	cond := fmt.Sprintf("UPDATE %s SET %s WHERE %s", quoteField(table), strings.Join(sets, ","), where)
+
+	return cond, vals
+}
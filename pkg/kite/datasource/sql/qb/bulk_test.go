@@ -0,0 +1,70 @@
+package qb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func bulkUsers() []map[string]interface{} {
+	return []map[string]interface{}{
+		{"id": 1, "name": "a", "age": 10},
+		{"id": 2, "name": "b", "age": 20},
+	}
+}
+
+func TestBuildBulkUpdate_MySQL(t *testing.T) {
+	cond, vals, err := BuildBulkUpdate("users", bulkUsers(), []string{"id"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "UPDATE users SET age=CASE WHEN id=? THEN ? WHEN id=? THEN ? ELSE age END,"+
+		"name=CASE WHEN id=? THEN ? WHEN id=? THEN ? ELSE name END WHERE id IN (?,?)", cond)
+	assert.Equal(t, []interface{}{1, 10, 2, 20, 1, "a", 2, "b", 1, 2}, vals)
+}
+
+func TestBuildBulkUpdate_Postgres(t *testing.T) {
+	cond, vals, err := BuildBulkUpdateWithDialect("postgres", "users", bulkUsers(), []string{"id"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "UPDATE users SET age=v.age,name=v.name FROM (SELECT (NULL::users).id,(NULL::users).age,(NULL::users).name "+
+		"WHERE false UNION ALL VALUES ($1,$2,$3),($4,$5,$6)) AS v WHERE users.id=v.id", cond)
+	assert.Equal(t, []interface{}{1, 10, "a", 2, 20, "b"}, vals)
+}
+
+func TestBuildBulkUpdate_CompositeKey(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"tenant": 1, "id": 1, "qty": 5},
+		{"tenant": 1, "id": 2, "qty": Raw("qty+1")},
+	}
+
+	cond, vals, err := BuildBulkUpdateWithDialect("sqlite", "stock", rows, []string{"tenant", "id"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "UPDATE stock SET qty=CASE WHEN (tenant=? AND id=?) THEN ? WHEN (tenant=? AND id=?) THEN qty+1 ELSE qty END "+
+		"WHERE (tenant=? AND id=?) OR (tenant=? AND id=?)", cond)
+	assert.Equal(t, []interface{}{1, 1, 5, 1, 2, 1, 1, 1, 2}, vals)
+}
+
+func TestBuildBulkUpdate_Errors(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		rows    []map[string]interface{}
+		keyCols []string
+		err     error
+	}{
+		{desc: "no rows", keyCols: []string{"id"}, err: errBulkUpdateEmptyRows},
+		{desc: "no key columns", rows: bulkUsers(), err: errBulkUpdateEmptyKeys},
+		{desc: "only key columns", rows: []map[string]interface{}{{"id": 1}}, keyCols: []string{"id"}, err: errBulkUpdateNoColumns},
+		{desc: "missing key", rows: []map[string]interface{}{{"id": 1, "name": "a"}, {"uid": 2, "name": "b"}},
+			keyCols: []string{"id"}, err: errBulkUpdateRowNotMatch},
+		{desc: "different columns", rows: []map[string]interface{}{{"id": 1, "name": "a"}, {"id": 2, "name": "b", "age": 3}},
+			keyCols: []string{"id"}, err: errBulkUpdateRowNotMatch},
+	}
+
+	for i, tc := range testCases {
+		_, _, err := BuildBulkUpdate("users", tc.rows, tc.keyCols)
+
+		require.ErrorIs(t, err, tc.err, "TEST[%d] %s", i, tc.desc)
+	}
+}
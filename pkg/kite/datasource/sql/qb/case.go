@@ -0,0 +1,176 @@
+package qb
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	errCaseWithoutWhen     = errors.New("[builder] CASE expression must contain at least one WHEN clause")
+	errCaseEmptyCondition  = errors.New("[builder] the condition of a WHEN clause cannot be empty")
+	errSelectExprValueType = errors.New(`[builder] the value of "_select" must be of Comparable or []Comparable type`)
+)
+
+type caseWhen struct {
+	cond Comparable
+	then interface{}
+}
+
+// CaseExpr is a parameterized CASE WHEN expression built with Case.
+type CaseExpr struct {
+	whens   []caseWhen
+	elseVal interface{}
+	hasElse bool
+	alias   string
+}
+
+// Case starts a CASE WHEN expression. The result values are passed as placeholders unless they are Raw.
+//
+// It can be used as a select expression with the "_select" key of the where map:
+//
+//	where := map[string]interface{}{
+//		"_select": qb.Case().
+//			When(qb.Cond(map[string]interface{}{"score >=": 90}), "gold").
+//			When(qb.Cond(map[string]interface{}{"score >=": 60}), "silver").
+//			Else("bronze").
+//			As("tier"),
+//	}
+//	// SELECT id,CASE WHEN score>=? THEN ? WHEN score>=? THEN ? ELSE ? END AS tier FROM users
+//
+// or as the value of a column in an update map:
+//
+//	update := map[string]interface{}{
+//		"status": qb.Case().When(qb.Cond(map[string]interface{}{"retries >": 3}), "failed").Else(qb.Raw("status")),
+//	}
+//	// UPDATE jobs SET status=CASE WHEN retries>? THEN ? ELSE status END
+func Case() *CaseExpr {
+	return &CaseExpr{}
+}
+
+// When adds a WHEN clause which results in then if cond is true.
+func (c *CaseExpr) When(cond Comparable, then interface{}) *CaseExpr {
+	c.whens = append(c.whens, caseWhen{cond: cond, then: then})
+	return c
+}
+
+// Else sets the result of the expression if none of the WHEN conditions is true. Without it the result is NULL.
+func (c *CaseExpr) Else(val interface{}) *CaseExpr {
+	c.elseVal = val
+	c.hasElse = true
+
+	return c
+}
+
+// As sets the alias of the expression when it is used as a select expression. It is ignored in update maps.
+func (c *CaseExpr) As(alias string) *CaseExpr {
+	c.alias = alias
+	return c
+}
+
+// Build implements the Comparable interface.
+func (c *CaseExpr) Build() ([]string, []interface{}) {
+	expr, vals := c.expression()
+	if expr == "" {
+		return nil, nil
+	}
+
+	if c.alias != "" {
+		expr += " AS " + quoteField(c.alias)
+	}
+
+	return []string{expr}, vals
+}
+
+func (c *CaseExpr) expression() (string, []interface{}) {
+	if len(c.whens) == 0 {
+		return "", nil
+	}
+
+	var (
+		sb   strings.Builder
+		vals []interface{}
+	)
+
+	sb.WriteString("CASE")
+
+	for _, w := range c.whens {
+		if w.cond == nil {
+			return "", nil
+		}
+
+		cond, condVals := And(w.cond).Build()
+		if len(cond) == 0 {
+			return "", nil
+		}
+
+		sb.WriteString(" WHEN ")
+		sb.WriteString(cond[0])
+		sb.WriteString(" THEN ")
+		vals = append(vals, condVals...)
+		vals = appendCaseValue(&sb, vals, w.then)
+	}
+
+	if c.hasElse {
+		sb.WriteString(" ELSE ")
+		vals = appendCaseValue(&sb, vals, c.elseVal)
+	}
+
+	sb.WriteString(" END")
+
+	return sb.String(), vals
+}
+
+func appendCaseValue(sb *strings.Builder, vals []interface{}, v interface{}) []interface{} {
+	if raw, ok := v.(Raw); ok {
+		sb.WriteString(string(raw))
+		return vals
+	}
+
+	sb.WriteByte('?')
+
+	return append(vals, v)
+}
+
+func (c *CaseExpr) buildError() error {
+	if len(c.whens) == 0 {
+		return errCaseWithoutWhen
+	}
+
+	for _, w := range c.whens {
+		if w.cond == nil {
+			return errCaseEmptyCondition
+		}
+
+		if err := comparableBuildErr(w.cond); err != nil {
+			return err
+		}
+
+		if cond, _ := w.cond.Build(); len(cond) == 0 {
+			return errCaseEmptyCondition
+		}
+	}
+
+	return nil
+}
+
+// parseSelectExprs resolves the value of the "_select" key into the extra select expressions.
+func parseSelectExprs(value interface{}) ([]Comparable, error) {
+	var exprs []Comparable
+
+	switch v := value.(type) {
+	case Comparable:
+		exprs = []Comparable{v}
+	case []Comparable:
+		exprs = v
+	default:
+		return nil, errSelectExprValueType
+	}
+
+	for _, expr := range exprs {
+		if err := comparableBuildErr(expr); err != nil {
+			return nil, err
+		}
+	}
+
+	return exprs, nil
+}
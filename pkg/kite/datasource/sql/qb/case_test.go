@@ -0,0 +1,72 @@
+package qb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tierCase() *CaseExpr {
+	return Case().
+		When(Cond(map[string]interface{}{"score >=": 90}), "gold").
+		When(Cond(map[string]interface{}{"score >=": 60}), "silver").
+		Else("bronze").
+		As("tier")
+}
+
+func TestBuildSelect_CaseExpression(t *testing.T) {
+	where := map[string]interface{}{
+		"_select": tierCase(),
+		"active":  1,
+	}
+
+	cond, vals, err := BuildSelect("users", where, []string{"id"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT id,CASE WHEN score>=? THEN ? WHEN score>=? THEN ? ELSE ? END AS tier FROM users WHERE (active=?)", cond)
+	assert.Equal(t, []interface{}{90, "gold", 60, "silver", "bronze", 1}, vals)
+}
+
+func TestBuildSelect_CaseExpressionWithDialect(t *testing.T) {
+	where := map[string]interface{}{
+		"_select": []Comparable{tierCase()},
+		"active":  1,
+	}
+
+	cond, vals, err := BuildSelectWithDialect("postgres", "users", where, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT CASE WHEN score>=$1 THEN $2 WHEN score>=$3 THEN $4 ELSE $5 END AS tier FROM users WHERE (active=$6)", cond)
+	assert.Equal(t, []interface{}{90, "gold", 60, "silver", "bronze", 1}, vals)
+}
+
+func TestBuildUpdate_CaseExpression(t *testing.T) {
+	update := map[string]interface{}{
+		"status":     Case().When(Cond(map[string]interface{}{"retries >": 3}), "failed").Else(Raw("status")),
+		"updated_by": "cron",
+	}
+
+	cond, vals, err := BuildUpdateWithDialect("postgres", "jobs", map[string]interface{}{"id": 7}, update)
+
+	require.NoError(t, err)
+	assert.Equal(t, "UPDATE jobs SET status=CASE WHEN retries>$1 THEN $2 ELSE status END,updated_by=$3 WHERE (id=$4)", cond)
+	assert.Equal(t, []interface{}{3, "failed", "cron", 7}, vals)
+}
+
+func TestCaseExpression_Errors(t *testing.T) {
+	_, _, err := BuildUpdate("jobs", map[string]interface{}{"id": 7}, map[string]interface{}{"status": Case().Else("x")})
+	require.ErrorIs(t, err, errCaseWithoutWhen)
+
+	_, _, err = BuildUpdate("jobs", map[string]interface{}{"id": 7},
+		map[string]interface{}{"status": Case().When(Cond(nil), "x")})
+	require.ErrorIs(t, err, errCaseEmptyCondition)
+
+	_, _, err = BuildSelect("users", map[string]interface{}{
+		"_select": Case().When(Cond(map[string]interface{}{"score unknown": 1}), "x"),
+	}, nil)
+	require.ErrorIs(t, err, ErrUnsupportedOperator)
+
+	_, _, err = BuildSelect("users", map[string]interface{}{"_select": "CASE WHEN 1 THEN 2 END"}, nil)
+	require.ErrorIs(t, err, errSelectExprValueType)
+}
@@ -0,0 +1,157 @@
+package qb
+
+import "strings"
+
+// DefaultInChunkSize is the maximum number of values of an IN list of the builders which do not set their own
+// with WithInChunkSize. It is the limit of Oracle, the lowest of the common databases.
+const DefaultInChunkSize = 1000
+
+// WithInChunkSize returns a copy of the builder which splits the "in" and "not in" conditions of more than size
+// values into groups of size values: `id IN (...) OR id IN (...)` and `id NOT IN (...) AND id NOT IN (...)`.
+// A size lower than 0 disables the splitting, 0 restores DefaultInChunkSize.
+func (b Builder) WithInChunkSize(size int) *Builder {
+	b.inChunkSize = size
+
+	return &b
+}
+
+func (b Builder) chunkSize() int {
+	if b.inChunkSize == 0 {
+		return DefaultInChunkSize
+	}
+
+	return b.inChunkSize
+}
+
+// whereConditions works like getWhereConditions and splits the IN lists longer than the chunk size of the builder.
+func (b Builder) whereConditions(where map[string]interface{}) ([]Comparable, error) {
+	conditions, err := getWhereConditions(where, defaultIgnoreKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	size := b.chunkSize()
+	if size < 0 {
+		return conditions, nil
+	}
+
+	for i, c := range conditions {
+		conditions[i] = chunkIn(c, size)
+	}
+
+	return conditions, nil
+}
+
+// chunkIn splits the IN lists of c, and of the conditions nested in c, which are longer than size.
+func chunkIn(c Comparable, size int) Comparable {
+	switch v := c.(type) {
+	case In:
+		return chunkInMap(v, size, false)
+	case NotIn:
+		return chunkInMap(v, size, true)
+	case NestWhere:
+		return NestWhere(chunkInSlice(v, size))
+	case OrWhere:
+		return OrWhere(chunkInSlice(v, size))
+	case conditionGroup:
+		v.conditions = chunkInSlice(v.conditions, size)
+		return v
+	default:
+		return c
+	}
+}
+
+func chunkInSlice(conditions []Comparable, size int) []Comparable {
+	chunked := make([]Comparable, len(conditions))
+	for i, c := range conditions {
+		chunked[i] = chunkIn(c, size)
+	}
+
+	return chunked
+}
+
+func chunkInMap(m map[string][]interface{}, size int, not bool) Comparable {
+	split := false
+
+	for _, vals := range m {
+		if len(vals) > size {
+			split = true
+			break
+		}
+	}
+
+	if !split {
+		if not {
+			return NotIn(m)
+		}
+
+		return In(m)
+	}
+
+	fields := make([]string, 0, len(m))
+	for field := range m {
+		fields = append(fields, field)
+	}
+
+	defaultSortAlgorithm(fields)
+
+	// the fields are kept in the order of In.Build.
+	conditions := make(inChunkList, 0, len(fields))
+	for _, field := range fields {
+		conditions = append(conditions, inChunks{field: field, vals: m[field], size: size, not: not})
+	}
+
+	return conditions
+}
+
+// inChunkList holds the conditions of the fields of an In or NotIn, built like In.Build.
+type inChunkList []inChunks
+
+// Build implements the Comparable interface.
+func (l inChunkList) Build() ([]string, []interface{}) {
+	var (
+		cond []string
+		vals []interface{}
+	)
+
+	for _, c := range l {
+		cs, vs := c.Build()
+		cond = append(cond, cs...)
+		vals = append(vals, vs...)
+	}
+
+	return cond, vals
+}
+
+// inChunks is an IN or NOT IN condition whose values are split into groups of size values.
+type inChunks struct {
+	field string
+	vals  []interface{}
+	size  int
+	not   bool
+}
+
+// Build implements the Comparable interface.
+func (c inChunks) Build() ([]string, []interface{}) {
+	if len(c.vals) <= c.size {
+		if c.not {
+			return []string{buildNotIn(c.field, c.vals)}, c.vals
+		}
+
+		return []string{buildIn(c.field, c.vals)}, c.vals
+	}
+
+	connector, build := " OR ", buildIn
+	if c.not {
+		connector, build = " AND ", buildNotIn
+	}
+
+	parts := make([]string, 0, (len(c.vals)+c.size-1)/c.size)
+
+	for start := 0; start < len(c.vals); start += c.size {
+		end := min(start+c.size, len(c.vals))
+		parts = append(parts, build(c.field, c.vals[start:end]))
+	}
+
+	return []string{"(" + strings.Join(parts, connector) + ")"}, c.vals
+}
@@ -0,0 +1,80 @@
+package qb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func values(n int) []interface{} {
+	vals := make([]interface{}, n)
+	for i := range vals {
+		vals[i] = i
+	}
+
+	return vals
+}
+
+func TestBuildSelect_ChunksInLists(t *testing.T) {
+	b, err := New("postgres")
+	require.NoError(t, err)
+
+	cond, vals, err := b.WithInChunkSize(2).BuildSelect("users", map[string]interface{}{
+		"id in":        values(5),
+		"status in":    []interface{}{"active"},
+		"role not in":  []interface{}{"a", "b", "c"},
+		"country":      "DE",
+		"_orderby":     "id asc",
+		"_limit":       []uint{0, 10},
+		"tenant_id !=": "x",
+	}, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE (country=$1 AND "+
+		"(id IN ($2,$3) OR id IN ($4,$5) OR id IN ($6)) AND status IN ($7) AND "+
+		"tenant_id!=$8 AND "+
+		"(role NOT IN ($9,$10) AND role NOT IN ($11))) ORDER BY id ASC LIMIT $12 OFFSET $13", cond)
+	assert.Equal(t, []interface{}{"DE", 0, 1, 2, 3, 4, "active", "x", "a", "b", "c", 10, 0}, vals)
+}
+
+func TestBuildSelect_ChunksNestedInLists(t *testing.T) {
+	cond, vals, err := defaultBuilder.WithInChunkSize(2).BuildSelect("users", map[string]interface{}{
+		"_or": []map[string]interface{}{
+			{"id in": values(3)},
+			{"name": "kite"},
+		},
+		"_custom_": And(Cond(map[string]interface{}{"group_id in": values(3)})),
+	}, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE ((group_id IN (?,?) OR group_id IN (?)) AND "+
+		"(((id IN (?,?) OR id IN (?))) OR (name=?)))", cond)
+	assert.Equal(t, []interface{}{0, 1, 2, 0, 1, 2, "kite"}, vals)
+}
+
+func TestBuildDelete_ChunksInLists(t *testing.T) {
+	cond, vals, err := defaultBuilder.WithInChunkSize(2).BuildDelete("users", map[string]interface{}{
+		"id in": values(3),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "DELETE FROM users WHERE ((id IN (?,?) OR id IN (?)))", cond)
+	assert.Equal(t, values(3), vals)
+}
+
+func TestBuildSelect_InChunkSize(t *testing.T) {
+	where := map[string]interface{}{"id in": values(DefaultInChunkSize + 1)}
+
+	cond, _, err := BuildSelect("users", where, nil)
+	require.NoError(t, err)
+	assert.Contains(t, cond, ") OR id IN (?)")
+
+	cond, _, err = defaultBuilder.WithInChunkSize(-1).BuildSelect("users", where, nil)
+	require.NoError(t, err)
+	assert.NotContains(t, cond, " OR ")
+
+	cond, _, err = defaultBuilder.WithInChunkSize(-1).WithInChunkSize(0).BuildSelect("users", where, nil)
+	require.NoError(t, err)
+	assert.Contains(t, cond, ") OR id IN (?)")
+}
@@ -0,0 +1,88 @@
+package qb
+
+import "strings"
+
+// conditionGroup joins its conditions with AND or OR. Groups can be nested to any depth.
+type conditionGroup struct {
+	connector  string
+	conditions []Comparable
+}
+
+// And joins the conditions with AND.
+//
+// Conditions built with And, Or and Cond can be nested to any depth and used as the value of a "_custom_" key:
+//
+//	where := map[string]interface{}{
+//		"_custom_": qb.And(
+//			qb.Cond(map[string]interface{}{"status": "active"}),
+//			qb.Or(
+//				qb.Cond(map[string]interface{}{"age >": 18}),
+//				qb.And(
+//					qb.Cond(map[string]interface{}{"guardian": qb.IsNotNull}),
+//					qb.Cond(map[string]interface{}{"country in": []interface{}{"DE", "FR"}}),
+//				),
+//			),
+//		),
+//		"_orderby": "id desc",
+//	}
+//	// SELECT * FROM users WHERE ((status=? AND (age>? OR (guardian IS NOT NULL AND country IN (?,?))))) ORDER BY id DESC
+func And(conditions ...Comparable) Comparable {
+	return conditionGroup{connector: "AND", conditions: conditions}
+}
+
+// Or joins the conditions with OR. See And for an example.
+func Or(conditions ...Comparable) Comparable {
+	return conditionGroup{connector: "OR", conditions: conditions}
+}
+
+// Cond converts a where map into a condition. The map supports the same keys and operators as the where map
+// of BuildSelect, except for the special keys like "_orderby" or "_limit" which are ignored.
+func Cond(where map[string]interface{}) Comparable {
+	conditions, err := getWhereConditions(where, defaultIgnoreKeys)
+	if err != nil {
+		return errorComparable{err: err}
+	}
+
+	return conditionGroup{connector: "AND", conditions: conditions}
+}
+
+// Build implements the Comparable interface.
+func (g conditionGroup) Build() ([]string, []interface{}) {
+	var (
+		parts []string
+		vals  []interface{}
+	)
+
+	for _, c := range g.conditions {
+		cond, v := c.Build()
+
+		for _, part := range cond {
+			if part != "" {
+				parts = append(parts, part)
+			}
+		}
+
+		vals = append(vals, v...)
+	}
+
+	switch len(parts) {
+	case 0:
+		return nil, nil
+	case 1:
+		return parts, vals
+	default:
+		return []string{"(" + strings.Join(parts, " "+g.connector+" ") + ")"}, vals
+	}
+}
+
+// buildError reports the first error of the nested conditions, so that an invalid condition
+// fails the whole query instead of being dropped.
+func (g conditionGroup) buildError() error {
+	for _, c := range g.conditions {
+		if err := comparableBuildErr(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
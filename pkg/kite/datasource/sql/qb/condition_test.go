@@ -0,0 +1,66 @@
+package qb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildSelect_NestedConditions(t *testing.T) {
+	where := map[string]interface{}{
+		"_custom_": And(
+			Cond(map[string]interface{}{"status": "active"}),
+			Or(
+				Cond(map[string]interface{}{"age >": 18}),
+				And(
+					Cond(map[string]interface{}{"guardian": IsNotNull}),
+					Cond(map[string]interface{}{"country in": []interface{}{"DE", "FR"}}),
+				),
+			),
+		),
+		"_orderby": "id desc",
+	}
+
+	cond, vals, err := BuildSelect("users", where, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE ((status=? AND (age>? OR (guardian IS NOT NULL AND country IN (?,?))))) ORDER BY id DESC", cond)
+	assert.Equal(t, []interface{}{"active", 18, "DE", "FR"}, vals)
+}
+
+func TestBuildSelect_NestedConditionsWithDialect(t *testing.T) {
+	where := map[string]interface{}{
+		"tenant": 1,
+		"_custom_": Or(
+			Cond(map[string]interface{}{"a": 1, "b": 2}),
+			Cond(map[string]interface{}{"c <": 3}),
+		),
+	}
+
+	cond, vals, err := BuildSelectWithDialect("postgres", "t", where, []string{"id"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM t WHERE (((a=$1 AND b=$2) OR c<$3) AND tenant=$4)", cond)
+	assert.Equal(t, []interface{}{1, 2, 3, 1}, vals)
+}
+
+func TestCond_InvalidConditionFailsQuery(t *testing.T) {
+	where := map[string]interface{}{
+		"_custom_": And(
+			Cond(map[string]interface{}{"a": 1}),
+			Or(Cond(map[string]interface{}{"b unknown": 2})),
+		),
+	}
+
+	_, _, err := BuildSelect("t", where, nil)
+
+	require.ErrorIs(t, err, ErrUnsupportedOperator)
+}
+
+func TestConditionGroup_Empty(t *testing.T) {
+	cond, vals := And(Or(), Cond(nil)).Build()
+
+	assert.Nil(t, cond)
+	assert.Nil(t, vals)
+}
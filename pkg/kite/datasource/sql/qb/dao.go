@@ -475,6 +475,15 @@ func resolveUpdate(update map[string]interface{}) (sets string, vals []interface
 			sb.WriteString(fmt.Sprintf("%s=%s,", k, v))
 			continue
 		}
+		if expr, ok := v.(*CaseExpr); ok {
+			if err = expr.buildError(); err != nil {
+				return "", nil, err
+			}
+			caseSQL, caseVals := expr.expression()
+			sb.WriteString(fmt.Sprintf("%s=%s,", quoteField(k), caseSQL))
+			vals = append(vals, caseVals...)
+			continue
+		}
 		if strings.HasPrefix(k, "_custom_") {
 			if custom, ok := v.(Comparable); ok {
 				if err = comparableBuildErr(custom); err != nil {
@@ -614,7 +623,7 @@ func buildSelect(table string, ufields []string, groupBy, orderBy, lockMode stri
 		}
 	}
 
-	return defaultBuilder.buildSelect(table, ufields, groupBy, orderBy, lockClause, limit, conditions...)
+	return defaultBuilder.buildSelect(table, ufields, nil, groupBy, orderBy, lockClause, limit, conditions...)
 }
 
 func splitCondition(conditions []Comparable) ([]Comparable, []Comparable) {
@@ -632,12 +641,23 @@ func splitCondition(conditions []Comparable) ([]Comparable, []Comparable) {
 	return conditions, nil
 }
 
-func (b Builder) buildSelect(table string, ufields []string, groupBy, orderBy, lockClause string, limit *eleLimit, conditions ...Comparable) (string, []interface{}, error) {
+func (b Builder) buildSelect(table string, ufields []string, selectExprs []Comparable, groupBy, orderBy, lockClause string, limit *eleLimit, conditions ...Comparable) (string, []interface{}, error) {
 	fields := "*"
-	if len(ufields) > 0 {
-		for i := range ufields {
-			ufields[i] = quoteField(ufields[i])
+	for i := range ufields {
+		ufields[i] = quoteField(ufields[i])
+	}
+	var vals []interface{}
+	if len(selectExprs) > 0 {
+		selected := make([]string, 0, len(ufields)+len(selectExprs))
+		selected = append(selected, ufields...)
+		for _, expr := range selectExprs {
+			exprFields, exprVals := expr.Build()
+			selected = append(selected, exprFields...)
+			vals = append(vals, exprVals...)
 		}
+		ufields = selected
+	}
+	if len(ufields) > 0 {
 		fields = strings.Join(ufields, ",")
 	}
 	bd := strings.Builder{}
@@ -646,10 +666,11 @@ func (b Builder) buildSelect(table string, ufields []string, groupBy, orderBy, l
 	bd.WriteString(" FROM ")
 	bd.WriteString(table)
 	where, having := splitCondition(conditions)
-	whereString, vals := whereConnector("AND", where...)
+	whereString, whereVals := whereConnector("AND", where...)
 	if "" != whereString {
 		bd.WriteString(" WHERE ")
 		bd.WriteString(whereString)
+		vals = append(vals, whereVals...)
 	}
 	if "" != groupBy {
 		bd.WriteString(" GROUP BY ")
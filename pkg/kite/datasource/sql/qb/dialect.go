@@ -25,7 +25,10 @@ var (
 
 // Builder builds SQL for a specific dialect.
 type Builder struct {
-	dialect Dialect
+	dialect     Dialect
+	inChunkSize int
+	tablePrefix string
+	schema      string
 }
 
 // DialectProvider describes a type that can expose SQL dialect.
@@ -59,6 +62,11 @@ func FromDB(db DialectProvider) (*Builder, error) {
 	return New(db.Dialect())
 }
 
+// Dialect returns the SQL dialect of the builder.
+func (b Builder) Dialect() Dialect {
+	return b.dialect
+}
+
 // BuildSelectWithDialect builds a SELECT query for the given dialect.
 func BuildSelectWithDialect(dialect, table string, where map[string]interface{}, selectField []string) (string, []interface{}, error) {
 	b, err := New(dialect)
@@ -139,6 +147,17 @@ func NamedQueryWithDialect(dialect, sql string, data map[string]interface{}) (st
 	return b.NamedQuery(sql, data)
 }
 
+// RebindWithDialect rewrites the "?" placeholders of the query into the ones of the given dialect, e.g. "$1" for
+// postgres, supabase and cockroachdb. The query is returned unchanged for the dialects which qb does not support.
+func RebindWithDialect(dialect, query string) string {
+	b, err := New(dialect)
+	if err != nil {
+		return query
+	}
+
+	return b.rebindQuery(query)
+}
+
 func normalizeDialect(dialect string) (Dialect, error) {
 	switch strings.ToLower(strings.TrimSpace(dialect)) {
 	case "", string(DialectMySQL), "mariadb":
@@ -179,9 +198,12 @@ func (b Builder) rebindQuery(query string) string {
 }
 
 func (b Builder) finalizeQuery(query string, vals []interface{}) (string, []interface{}, error) {
-	return b.rebindQuery(query), vals, nil
+	return b.rebindQuery(b.rewriteLikeEscape(query)), b.convertTimeValues(vals), nil
 }
 
+// lockClause returns the locking clause of the "_lockMode" of a SELECT query: "share", "exclusive", or the exclusive
+// locks which do not wait for the rows locked by other transactions, "skip_locked" to skip them, e.g. to pick the jobs
+// of a queue, and "nowait" to fail right away. MySQL supports "skip_locked" and "nowait" from version 8.0.
 func (b Builder) lockClause(lockMode string) (string, error) {
 	switch b.dialect {
 	case DialectMySQL:
@@ -190,8 +212,6 @@ func (b Builder) lockClause(lockMode string) (string, error) {
 			return " LOCK IN SHARE MODE", nil
 		case "exclusive":
 			return " FOR UPDATE", nil
-		default:
-			return "", errNotAllowedLockMode
 		}
 	case DialectPostgres:
 		switch lockMode {
@@ -199,14 +219,21 @@ func (b Builder) lockClause(lockMode string) (string, error) {
 			return " FOR SHARE", nil
 		case "exclusive":
 			return " FOR UPDATE", nil
-		default:
-			return "", errNotAllowedLockMode
 		}
 	case DialectSQLite:
-		return "", errNotAllowedLockMode
+		return "", fmt.Errorf("%w: %q, sqlite does not support row locks", errNotAllowedLockMode, lockMode)
 	default:
 		return "", fmt.Errorf("%w: %q", errUnsupportedDialect, b.dialect)
 	}
+
+	switch lockMode {
+	case "skip_locked":
+		return " FOR UPDATE SKIP LOCKED", nil
+	case "nowait":
+		return " FOR UPDATE NOWAIT", nil
+	default:
+		return "", fmt.Errorf("%w: %q", errNotAllowedLockMode, lockMode)
+	}
 }
 
 func (b Builder) limitIdentifier() string {
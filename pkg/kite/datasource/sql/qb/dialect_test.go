@@ -83,6 +83,36 @@ func TestBuildSelectWithDialect_SQLiteLockModeRejected(t *testing.T) {
 	assert.ErrorIs(t, err, errNotAllowedLockMode)
 }
 
+func TestBuildSelectWithDialect_LockModes(t *testing.T) {
+	testCases := []struct {
+		dialect  string
+		lockMode string
+		query    string
+		err      error
+	}{
+		{dialect: "mysql", lockMode: "skip_locked",
+			query: "SELECT id FROM jobs WHERE (status=?) LIMIT ?,? FOR UPDATE SKIP LOCKED"},
+		{dialect: "mysql", lockMode: "nowait", query: "SELECT id FROM jobs WHERE (status=?) LIMIT ?,? FOR UPDATE NOWAIT"},
+		{dialect: "postgres", lockMode: "skip_locked",
+			query: "SELECT id FROM jobs WHERE (status=$1) LIMIT $2 OFFSET $3 FOR UPDATE SKIP LOCKED"},
+		{dialect: "postgres", lockMode: " nowait ",
+			query: "SELECT id FROM jobs WHERE (status=$1) LIMIT $2 OFFSET $3 FOR UPDATE NOWAIT"},
+		{dialect: "postgres", lockMode: "skip_locked; DROP TABLE jobs", err: errNotAllowedLockMode},
+		{dialect: "sqlite", lockMode: "skip_locked", err: errNotAllowedLockMode},
+	}
+
+	for i, tc := range testCases {
+		query, _, err := BuildSelectWithDialect(tc.dialect, "jobs", map[string]interface{}{
+			"status":    "pending",
+			"_limit":    uint(10),
+			"_lockMode": tc.lockMode,
+		}, []string{"id"})
+
+		require.ErrorIs(t, err, tc.err, "TEST[%d], Failed.\n%s", i, tc.dialect)
+		assert.Equal(t, tc.query, query, "TEST[%d], Failed.\n%s", i, tc.dialect)
+	}
+}
+
 func TestBuildUpdateWithDialect_PostgresLimit(t *testing.T) {
 	cond, vals, err := BuildUpdateWithDialect("postgres", "users", map[string]interface{}{
 		"id >":   100,
@@ -129,6 +159,26 @@ func TestBuildReplaceInsertWithDialect_PostgresUnsupported(t *testing.T) {
 	assert.ErrorIs(t, err, errFeatureUnsupportedDialect)
 }
 
+func TestRebindWithDialect(t *testing.T) {
+	const query = "SELECT * FROM users WHERE id = ? AND status = ?"
+
+	testCases := []struct {
+		dialect  string
+		expected string
+	}{
+		{dialect: "postgres", expected: "SELECT * FROM users WHERE id = $1 AND status = $2"},
+		{dialect: "supabase", expected: "SELECT * FROM users WHERE id = $1 AND status = $2"},
+		{dialect: "cockroachdb", expected: "SELECT * FROM users WHERE id = $1 AND status = $2"},
+		{dialect: "mysql", expected: query},
+		{dialect: "sqlite", expected: query},
+		{dialect: "clickhouse", expected: query},
+	}
+
+	for i, tc := range testCases {
+		assert.Equal(t, tc.expected, RebindWithDialect(tc.dialect, query), "TEST[%d], Failed.\n%s", i, tc.dialect)
+	}
+}
+
 func TestNamedQueryWithDialect_Postgres(t *testing.T) {
 	cond, vals, err := NamedQueryWithDialect("postgres", "SELECT * FROM users WHERE id={{id}} AND status IN {{statuses}}", map[string]interface{}{
 		"id":       7,
@@ -5,6 +5,28 @@
 // Use New(...) or *WithDialect helpers to generate SQL for sqlite and postgres.
 // You can also use FromDB(...) with a datasource that exposes Dialect().
 //
+// Nested AND/OR conditions can be composed with And, Or and Cond and passed as the value of a "_custom_" key.
+//
+// Case builds parameterized CASE WHEN expressions for the "_select" key of the where map and for update maps.
+//
+// Contains, HasPrefix and HasSuffix build LIKE conditions which escape the wildcards of user input.
+//
+// The "in" and "not in" conditions of more than DefaultInChunkSize values are split into OR-ed, respectively AND-ed,
+// groups, see Builder.WithInChunkSize.
+//
+// The "_lockMode" key locks the selected rows with "share", "exclusive", "skip_locked" or "nowait", e.g. to pick the
+// jobs of a queue with SELECT ... FOR UPDATE SKIP LOCKED.
+//
+// Builder.WithTablePrefix and Builder.WithSchema prefix and qualify the table names of the queries, quoted for the
+// dialect.
+//
+// TimeBetween filters a field on a half-open time range, and Builder.DayBucket and Builder.MonthBucket group the rows by
+// day or month of a time zone, with the expressions of the dialect.
+//
+// The qbtest package asserts the queries generated for each dialect in tests.
+//
+// BuildBulkUpdate updates many rows with different values in a single statement.
+//
 // JSON helper functions (JsonContains/JsonSet/JsonArrayAppend/JsonArrayInsert/JsonRemove)
-// generate MySQL JSON function syntax.
+// generate MySQL JSON function syntax, JsonbPathMatch and JsonbContains generate PostgreSQL jsonb operators.
 package qb
@@ -0,0 +1,39 @@
+package qb
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+)
+
+var (
+	numberedPlaceholder = regexp.MustCompile(`\$\d+`)
+	whitespaces         = regexp.MustCompile(`\s+`)
+	placeholderList     = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)*\s*\)`)
+	placeholderTuples   = regexp.MustCompile(`\(\?\)(?:\s*,\s*\(\?\))+`)
+)
+
+// Normalize returns the logical form of query, shared by the queries which only differ by their data: its
+// literals and placeholders are replaced by ?, the lists of values, like the ones of IN or VALUES, are reduced to a
+// single (?) and its whitespaces are collapsed.
+//
+//	Normalize("SELECT * FROM users WHERE id IN ($1, $2, $3) AND status = 'active'")
+//	// SELECT * FROM users WHERE id IN (?) AND status = ?
+func Normalize(query string) string {
+	query = Sanitize(query)
+	query = numberedPlaceholder.ReplaceAllString(query, "?")
+	query = whitespaces.ReplaceAllString(strings.TrimSpace(query), " ")
+	query = placeholderList.ReplaceAllString(query, "(?)")
+
+	return placeholderTuples.ReplaceAllString(query, "(?)")
+}
+
+// Fingerprint returns a short hash identifying the logical query of query, see Normalize. The case of the query is
+// ignored.
+func Fingerprint(query string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(strings.ToLower(Normalize(query))))
+
+	return fmt.Sprintf("%016x", h.Sum64())
+}
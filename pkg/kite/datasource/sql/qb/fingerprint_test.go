@@ -0,0 +1,55 @@
+package qb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{
+			name:     "literals",
+			query:    "SELECT * FROM users WHERE name = 'alice' AND age > 30",
+			expected: "SELECT * FROM users WHERE name = ? AND age > ?",
+		},
+		{
+			name:     "numbered placeholders and IN list",
+			query:    "SELECT * FROM users WHERE id IN ($1, $2,$3) AND status=$4",
+			expected: "SELECT * FROM users WHERE id IN (?) AND status=?",
+		},
+		{
+			name:     "VALUES tuples",
+			query:    "INSERT INTO t (a,b) VALUES (?,?), (?,?),(?,?)",
+			expected: "INSERT INTO t (a,b) VALUES (?)",
+		},
+		{
+			name:     "whitespaces",
+			query:    "\n  SELECT id\n\tFROM t  WHERE id = ?  ",
+			expected: "SELECT id FROM t WHERE id = ?",
+		},
+		{
+			name:     "identifiers with digits",
+			query:    "SELECT col1 FROM t2 WHERE `x 3` = 3",
+			expected: "SELECT col1 FROM t2 WHERE `x 3` = ?",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Normalize(tc.query))
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	fp := Fingerprint("SELECT * FROM users WHERE id IN (?, ?) AND name = 'alice'")
+
+	assert.Len(t, fp, 16)
+	assert.Equal(t, fp, Fingerprint("select *\nfrom users where id in ($1,$2,$3) and name = 'bob'"))
+	assert.NotEqual(t, fp, Fingerprint("SELECT * FROM users WHERE id IN (?) AND email = ?"))
+}
@@ -0,0 +1,219 @@
+package qb
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Redacted is the literal shown instead of a redacted argument.
+const Redacted = Raw("'[REDACTED]'")
+
+// RedactFunc returns the value shown for the argument at index in an interpolated query.
+// Returning a Raw writes it to the query as is.
+type RedactFunc func(index int, arg interface{}) interface{}
+
+// RedactNone shows every argument.
+func RedactNone(_ int, arg interface{}) interface{} {
+	return arg
+}
+
+// RedactAll hides every argument.
+func RedactAll(int, interface{}) interface{} {
+	return Redacted
+}
+
+// RedactStrings shows numbers, booleans, times and NULLs, and hides every other argument, like strings
+// and bytes which usually hold personal data.
+func RedactStrings(_ int, arg interface{}) interface{} {
+	if v, ok := arg.(driver.Valuer); ok {
+		if value, err := v.Value(); err == nil {
+			arg = value
+		}
+	}
+
+	switch arg.(type) {
+	case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, time.Time:
+		return arg
+	default:
+		return Redacted
+	}
+}
+
+// Interpolate replaces the ? and $n placeholders of query with the SQL literals of args.
+//
+// It is meant for debugging and logging only: the result must never be executed, as the literals are
+// not escaped for every dialect. Use InterpolateRedacted to hide sensitive values.
+func Interpolate(query string, args []interface{}) string {
+	return InterpolateRedacted(query, args, RedactNone)
+}
+
+// InterpolateRedacted works like Interpolate but passes every argument through redact first.
+func InterpolateRedacted(query string, args []interface{}, redact RedactFunc) string {
+	if len(args) == 0 {
+		return query
+	}
+
+	if redact == nil {
+		redact = RedactNone
+	}
+
+	var (
+		sb   strings.Builder
+		next int
+	)
+
+	sb.Grow(len(query) + len(args)*8)
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := quotedEnd(query, i)
+			sb.WriteString(query[i:end])
+			i = end - 1
+		case c == '?':
+			if next >= len(args) {
+				sb.WriteByte(c)
+				continue
+			}
+
+			sb.WriteString(sqlLiteral(redact(next, args[next])))
+			next++
+		case c == '$' && i+1 < len(query) && isDigit(query[i+1]):
+			end := i + 1
+			for end < len(query) && isDigit(query[end]) {
+				end++
+			}
+
+			n, _ := strconv.Atoi(query[i+1 : end])
+			if n < 1 || n > len(args) {
+				sb.WriteString(query[i:end])
+			} else {
+				sb.WriteString(sqlLiteral(redact(n-1, args[n-1])))
+			}
+
+			i = end - 1
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return sb.String()
+}
+
+// Sanitize replaces the string and number literals written into query with ? placeholders, so that
+// a query built with Raw values can be shared without its data.
+func Sanitize(query string) string {
+	var sb strings.Builder
+
+	sb.Grow(len(query))
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+
+		switch {
+		case c == '\'':
+			sb.WriteByte('?')
+			i = quotedEnd(query, i) - 1
+		case c == '"' || c == '`':
+			end := quotedEnd(query, i)
+			sb.WriteString(query[i:end])
+			i = end - 1
+		case isDigit(c) && (i == 0 || !isIdentifierByte(query[i-1])):
+			end := i
+			for end < len(query) && (isDigit(query[end]) || query[end] == '.') {
+				end++
+			}
+
+			sb.WriteByte('?')
+			i = end - 1
+		case isIdentifierByte(c) || c == '$':
+			// copy identifiers and $n placeholders as a whole, so that their digits are kept.
+			end := i + 1
+			for end < len(query) && isIdentifierByte(query[end]) {
+				end++
+			}
+
+			sb.WriteString(query[i:end])
+			i = end - 1
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return sb.String()
+}
+
+// quotedEnd returns the index after the closing quote of the quoted string starting at start.
+// Quotes are escaped by doubling them, and backslash escapes are skipped in single quoted strings.
+func quotedEnd(query string, start int) int {
+	quote := query[start]
+
+	for i := start + 1; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			if quote == '\'' {
+				i++
+			}
+		case quote:
+			if i+1 < len(query) && query[i+1] == quote {
+				i++
+				continue
+			}
+
+			return i + 1
+		}
+	}
+
+	return len(query)
+}
+
+func sqlLiteral(arg interface{}) string {
+	if v, ok := arg.(driver.Valuer); ok {
+		value, err := v.Value()
+		if err != nil {
+			return "?"
+		}
+
+		arg = value
+	}
+
+	switch v := arg.(type) {
+	case nil:
+		return "NULL"
+	case Raw:
+		return string(v)
+	case string:
+		return quoteLiteral(v)
+	case []byte:
+		return quoteLiteral(string(v))
+	case bool:
+		if v {
+			return "TRUE"
+		}
+
+		return "FALSE"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(v)
+	case time.Time:
+		return quoteLiteral(v.Format("2006-01-02 15:04:05.999999"))
+	default:
+		return quoteLiteral(fmt.Sprint(v))
+	}
+}
+
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentifierByte(c byte) bool {
+	return c == '_' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
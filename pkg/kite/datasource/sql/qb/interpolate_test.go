@@ -0,0 +1,73 @@
+package qb
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpolate(t *testing.T) {
+	created := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+
+	testCases := []struct {
+		desc  string
+		query string
+		args  []interface{}
+		out   string
+	}{
+		{"question marks", "SELECT * FROM t WHERE a=? AND b IN (?,?) AND c=?", []interface{}{"o'hara", 1, 2.5, nil},
+			"SELECT * FROM t WHERE a='o''hara' AND b IN (1,2.5) AND c=NULL"},
+		{"dollar placeholders", "UPDATE t SET a=$2 WHERE id=$1 AND ok=$3", []interface{}{7, created, true},
+			"UPDATE t SET a='2024-05-06 07:08:09' WHERE id=7 AND ok=TRUE"},
+		{"placeholders in literals are kept", "SELECT '?', \"$1\" FROM t WHERE a=?", []interface{}{1},
+			"SELECT '?', \"$1\" FROM t WHERE a=1"},
+		{"missing args", "SELECT * FROM t WHERE a=? AND b=? AND c=$5", []interface{}{1}, "SELECT * FROM t WHERE a=1 AND b=? AND c=$5"},
+		{"valuer", "SELECT ?", []interface{}{sql.NullString{String: "x", Valid: true}}, "SELECT 'x'"},
+	}
+
+	for i, tc := range testCases {
+		assert.Equal(t, tc.out, Interpolate(tc.query, tc.args), "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestInterpolateRedacted(t *testing.T) {
+	query := "INSERT INTO users (email,age,verified,token,deleted_at) VALUES (?,?,?,?,?)"
+	args := []interface{}{"a@b.c", 30, true, []byte("secret"), sql.NullTime{}}
+
+	assert.Equal(t, "INSERT INTO users (email,age,verified,token,deleted_at) VALUES ('[REDACTED]',30,TRUE,'[REDACTED]',NULL)",
+		InterpolateRedacted(query, args, RedactStrings))
+	assert.Equal(t, "INSERT INTO users (email,age,verified,token,deleted_at) VALUES "+
+		"('[REDACTED]','[REDACTED]','[REDACTED]','[REDACTED]','[REDACTED]')",
+		InterpolateRedacted(query, args, RedactAll))
+
+	redactEmail := func(index int, arg interface{}) interface{} {
+		if index == 0 {
+			return Redacted
+		}
+
+		return arg
+	}
+
+	assert.Equal(t, "SELECT * FROM users WHERE email='[REDACTED]' AND name='bob'",
+		InterpolateRedacted("SELECT * FROM users WHERE email=$1 AND name=$2", []interface{}{"a@b.c", "bob"}, redactEmail))
+}
+
+func TestSanitize(t *testing.T) {
+	testCases := []struct {
+		query string
+		out   string
+	}{
+		{"SELECT * FROM t1 WHERE name='o''hara' AND age>30 AND score=1.5 LIMIT 10",
+			"SELECT * FROM t1 WHERE name=? AND age>? AND score=? LIMIT ?"},
+		{`SELECT "col2", ` + "`col3`" + ` FROM t WHERE a=$1 AND b='x\'y'`,
+			`SELECT "col2", ` + "`col3`" + ` FROM t WHERE a=$1 AND b=?`},
+		{"UPDATE t SET status=CASE WHEN retries>3 THEN 'failed' ELSE status END WHERE id IN (1,2)",
+			"UPDATE t SET status=CASE WHEN retries>? THEN ? ELSE status END WHERE id IN (?,?)"},
+	}
+
+	for i, tc := range testCases {
+		assert.Equal(t, tc.out, Sanitize(tc.query), "TEST[%d], Failed.\n", i)
+	}
+}
@@ -0,0 +1,50 @@
+package qb
+
+import "strings"
+
+// likeEscapeClause is the ESCAPE clause of the conditions built by Contains, HasPrefix and HasSuffix. It is kept
+// for PostgreSQL and SQLite, and removed for MySQL whose LIKE already escapes with a backslash and where '\' is not
+// a valid string literal.
+const likeEscapeClause = ` ESCAPE '\'`
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Contains checks whether field contains s. The wildcards % and _ of s match themselves, so that user input
+// cannot widen the search, eg to a full scan with "%".
+//
+// usage where := map[string]interface{}{"_custom_name": qb.Contains("name", input)}
+func Contains(field, s string) Comparable {
+	return likeCondition(field, "%"+escapeLike(s)+"%")
+}
+
+// HasPrefix checks whether field starts with s, escaping its wildcards like Contains. Unlike Contains, it can use
+// an index on field.
+func HasPrefix(field, s string) Comparable {
+	return likeCondition(field, escapeLike(s)+"%")
+}
+
+// HasSuffix checks whether field ends with s, escaping its wildcards like Contains.
+func HasSuffix(field, s string) Comparable {
+	return likeCondition(field, "%"+escapeLike(s))
+}
+
+func likeCondition(field, pattern string) Comparable {
+	return rawSql{
+		sqlCond: field + " LIKE ?" + likeEscapeClause,
+		values:  []interface{}{pattern},
+	}
+}
+
+// escapeLike escapes the wildcards of s and the backslash used to escape them.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// rewriteLikeEscape adapts the ESCAPE clauses of query to the dialect of the builder.
+func (b Builder) rewriteLikeEscape(query string) string {
+	if b.dialect != DialectMySQL || !strings.Contains(query, likeEscapeClause) {
+		return query
+	}
+
+	return strings.ReplaceAll(query, likeEscapeClause, "")
+}
@@ -0,0 +1,62 @@
+package qb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLikeHelpers_EscapeWildcards(t *testing.T) {
+	tests := []struct {
+		name     string
+		cond     Comparable
+		expected string
+	}{
+		{name: "contains", cond: Contains("name", "50%_off"), expected: `%50\%\_off%`},
+		{name: "prefix", cond: HasPrefix("name", `C:\dir`), expected: `C:\\dir%`},
+		{name: "suffix", cond: HasSuffix("email", "@example.com"), expected: "%@example.com"},
+		{name: "wildcard only", cond: Contains("name", "%"), expected: `%\%%`},
+		{name: "empty", cond: HasPrefix("name", ""), expected: "%"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, vals := tc.cond.Build()
+			assert.Equal(t, []interface{}{tc.expected}, vals)
+		})
+	}
+}
+
+func TestLikeHelpers_EscapeClausePerDialect(t *testing.T) {
+	tests := []struct {
+		dialect  string
+		expected string
+	}{
+		{dialect: "mysql", expected: "SELECT * FROM users WHERE ((status=? AND name LIKE ?))"},
+		{dialect: "postgres", expected: `SELECT * FROM users WHERE ((status=$1 AND name LIKE $2 ESCAPE '\'))`},
+		{dialect: "sqlite", expected: `SELECT * FROM users WHERE ((status=? AND name LIKE ? ESCAPE '\'))`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.dialect, func(t *testing.T) {
+			cond, vals, err := BuildSelectWithDialect(tc.dialect, "users", map[string]interface{}{
+				"_custom_": And(Cond(map[string]interface{}{"status": "active"}), Contains("name", "a_b")),
+			}, nil)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, cond)
+			assert.Equal(t, []interface{}{"active", `%a\_b%`}, vals)
+		})
+	}
+}
+
+func TestLikeHelpers_Update(t *testing.T) {
+	cond, vals, err := BuildUpdateWithDialect("sqlite", "users", map[string]interface{}{
+		"_custom_": HasSuffix("email", "@old.example"),
+	}, map[string]interface{}{"active": false})
+
+	require.NoError(t, err)
+	assert.Equal(t, `UPDATE users SET active=? WHERE (email LIKE ? ESCAPE '\')`, cond)
+	assert.Equal(t, []interface{}{false, "%@old.example"}, vals)
+}
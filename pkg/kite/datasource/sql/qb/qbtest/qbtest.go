@@ -0,0 +1,90 @@
+// Package qbtest provides helpers to test the queries generated with qb for each dialect.
+package qbtest
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sllt/kite/pkg/kite/datasource/sql/qb"
+)
+
+// Dialects are the dialects supported by qb.
+var Dialects = []qb.Dialect{qb.DialectMySQL, qb.DialectPostgres, qb.DialectSQLite}
+
+// BuilderFunc builds a query with the builder of a dialect.
+type BuilderFunc func(b *qb.Builder) (string, []interface{}, error)
+
+// Expected is the query a BuilderFunc is expected to build for a dialect.
+type Expected struct {
+	SQL  string
+	Args []interface{}
+	// Err is the error expected instead of the query, matched with errors.Is.
+	Err error
+}
+
+// AssertSQL runs fn with the builder of each dialect of expected, in a subtest named after the dialect, and asserts the
+// query and its arguments:
+//
+//	qbtest.AssertSQL(t, func(b *qb.Builder) (string, []interface{}, error) {
+//		return b.BuildSelect("users", map[string]interface{}{"status": "active", "_limit": []uint{20, 10}}, nil)
+//	}, map[qb.Dialect]qbtest.Expected{
+//		qb.DialectMySQL:    {SQL: "SELECT * FROM users WHERE (status=?) LIMIT ?,?", Args: []interface{}{"active", 20, 10}},
+//		qb.DialectPostgres: {SQL: "SELECT * FROM users WHERE (status=$1) LIMIT $2 OFFSET $3", Args: []interface{}{"active", 10, 20}},
+//	})
+//
+// The dialects missing from expected are not run, use Same to expect the same query for all of them.
+func AssertSQL(t *testing.T, fn BuilderFunc, expected map[qb.Dialect]Expected) {
+	t.Helper()
+
+	require.NotEmpty(t, expected, "no expected query")
+
+	dialects := make([]string, 0, len(expected))
+	for d := range expected {
+		dialects = append(dialects, string(d))
+	}
+
+	sort.Strings(dialects)
+
+	for _, d := range dialects {
+		exp := expected[qb.Dialect(d)]
+
+		t.Run(d, func(t *testing.T) {
+			t.Helper()
+
+			b, err := qb.New(d)
+			require.NoError(t, err)
+
+			query, args, err := fn(b)
+
+			if exp.Err != nil {
+				require.ErrorIs(t, err, exp.Err, "unexpected error of dialect %s", d)
+
+				return
+			}
+
+			require.NoError(t, err, "unexpected error of dialect %s", d)
+			assert.Equal(t, exp.SQL, query, "unexpected query of dialect %s", d)
+
+			// no arguments match both a nil and an empty slice.
+			if len(exp.Args) == 0 && len(args) == 0 {
+				return
+			}
+
+			assert.Equal(t, exp.Args, args, "unexpected arguments of dialect %s", d)
+		})
+	}
+}
+
+// Same expects the query for all the dialects, e.g. for the queries without placeholders or dialect specific syntax.
+func Same(expected Expected) map[qb.Dialect]Expected {
+	m := make(map[qb.Dialect]Expected, len(Dialects))
+
+	for _, d := range Dialects {
+		m[d] = expected
+	}
+
+	return m
+}
@@ -0,0 +1,29 @@
+package qbtest
+
+import (
+	"testing"
+
+	"github.com/sllt/kite/pkg/kite/datasource/sql/qb"
+)
+
+func TestAssertSQL(t *testing.T) {
+	AssertSQL(t, func(b *qb.Builder) (string, []interface{}, error) {
+		return b.BuildSelect("users", map[string]interface{}{"status": "active", "_limit": []uint{20, 10}}, nil)
+	}, map[qb.Dialect]Expected{
+		qb.DialectMySQL:    {SQL: "SELECT * FROM users WHERE (status=?) LIMIT ?,?", Args: []interface{}{"active", 20, 10}},
+		qb.DialectPostgres: {SQL: "SELECT * FROM users WHERE (status=$1) LIMIT $2 OFFSET $3", Args: []interface{}{"active", 10, 20}},
+		qb.DialectSQLite:   {SQL: "SELECT * FROM users WHERE (status=?) LIMIT ? OFFSET ?", Args: []interface{}{"active", 10, 20}},
+	})
+}
+
+func TestAssertSQL_Error(t *testing.T) {
+	AssertSQL(t, func(b *qb.Builder) (string, []interface{}, error) {
+		return b.BuildSelect("users", map[string]interface{}{"age ~": 18}, nil)
+	}, Same(Expected{Err: qb.ErrUnsupportedOperator}))
+}
+
+func TestAssertSQL_Same(t *testing.T) {
+	AssertSQL(t, func(b *qb.Builder) (string, []interface{}, error) {
+		return b.BuildSelect("users", nil, []string{"id"})
+	}, Same(Expected{SQL: "SELECT id FROM users"}))
+}
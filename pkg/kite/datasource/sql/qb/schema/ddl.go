@@ -0,0 +1,252 @@
+package schema
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/sllt/kite/pkg/kite/datasource/sql/qb"
+)
+
+var errCompositeAutoIncrement = errors.New("[schema] sqlite supports auto_increment only on a single primary key column")
+
+var (
+	nullStringType  = reflect.TypeOf(sql.NullString{})
+	nullInt64Type   = reflect.TypeOf(sql.NullInt64{})
+	nullInt32Type   = reflect.TypeOf(sql.NullInt32{})
+	nullBoolType    = reflect.TypeOf(sql.NullBool{})
+	nullFloat64Type = reflect.TypeOf(sql.NullFloat64{})
+	nullTimeType    = reflect.TypeOf(sql.NullTime{})
+)
+
+// Generator generates DDL statements for a specific dialect.
+type Generator struct {
+	dialect qb.Dialect
+}
+
+// New returns a Generator for the provided dialect. It accepts the same values as qb.New.
+func New(dialect string) (*Generator, error) {
+	b, err := qb.New(dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Generator{dialect: b.Dialect()}, nil
+}
+
+// CreateTable returns the CREATE TABLE IF NOT EXISTS statement of the model.
+// The indexes of the model are created with CreateIndexes.
+func (g *Generator) CreateTable(model any) (string, error) {
+	table, err := Parse(model)
+	if err != nil {
+		return "", err
+	}
+
+	var primaryKeys []string
+
+	for _, c := range table.Columns {
+		if c.PrimaryKey {
+			primaryKeys = append(primaryKeys, g.quote(c.Name))
+		}
+	}
+
+	// sqlite only allows AUTOINCREMENT on an INTEGER PRIMARY KEY column definition.
+	inlinePrimaryKey := false
+
+	if g.dialect == qb.DialectSQLite {
+		for _, c := range table.Columns {
+			if !c.AutoIncrement {
+				continue
+			}
+
+			if !c.PrimaryKey || len(primaryKeys) != 1 {
+				return "", errCompositeAutoIncrement
+			}
+
+			inlinePrimaryKey = true
+		}
+	}
+
+	defs := make([]string, 0, len(table.Columns)+1)
+
+	for _, c := range table.Columns {
+		def, err := g.columnDefinition(c)
+		if err != nil {
+			return "", err
+		}
+
+		defs = append(defs, def)
+	}
+
+	if len(primaryKeys) > 0 && !inlinePrimaryKey {
+		defs = append(defs, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(primaryKeys, ", ")))
+	}
+
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", g.quote(table.Name), strings.Join(defs, ", ")), nil
+}
+
+// CreateIndexes returns a CREATE INDEX statement for every index of the model.
+func (g *Generator) CreateIndexes(model any) ([]string, error) {
+	table, err := Parse(model)
+	if err != nil {
+		return nil, err
+	}
+
+	stmts := make([]string, 0, len(table.Indexes))
+
+	for _, idx := range table.Indexes {
+		columns := make([]string, len(idx.Columns))
+		for i, c := range idx.Columns {
+			columns[i] = g.quote(c)
+		}
+
+		create := "CREATE INDEX"
+		if idx.Unique {
+			create = "CREATE UNIQUE INDEX"
+		}
+
+		// MySQL does not support IF NOT EXISTS for indexes.
+		if g.dialect != qb.DialectMySQL {
+			create += " IF NOT EXISTS"
+		}
+
+		stmts = append(stmts, fmt.Sprintf("%s %s ON %s (%s)",
+			create, g.quote(idx.Name), g.quote(table.Name), strings.Join(columns, ", ")))
+	}
+
+	return stmts, nil
+}
+
+// AddColumn returns the ALTER TABLE ... ADD COLUMN statement of a column of the model.
+func (g *Generator) AddColumn(model any, column string) (string, error) {
+	table, err := Parse(model)
+	if err != nil {
+		return "", err
+	}
+
+	c, err := table.Column(column)
+	if err != nil {
+		return "", err
+	}
+
+	def, err := g.columnDefinition(c)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", g.quote(table.Name), def), nil
+}
+
+func (g *Generator) columnDefinition(c Column) (string, error) {
+	colType := c.Type
+	if colType == "" {
+		var err error
+
+		colType, err = g.columnType(c)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	parts := []string{g.quote(c.Name), colType}
+
+	if c.AutoIncrement {
+		switch g.dialect {
+		case qb.DialectMySQL:
+			parts = append(parts, "NOT NULL", "AUTO_INCREMENT")
+		case qb.DialectPostgres:
+			parts = append(parts, "GENERATED BY DEFAULT AS IDENTITY")
+		case qb.DialectSQLite:
+			parts = append(parts, "PRIMARY KEY AUTOINCREMENT")
+		}
+
+		return strings.Join(parts, " "), nil
+	}
+
+	if c.NotNull {
+		parts = append(parts, "NOT NULL")
+	}
+
+	if c.Default != "" {
+		parts = append(parts, "DEFAULT "+c.Default)
+	}
+
+	return strings.Join(parts, " "), nil
+}
+
+func (g *Generator) columnType(c Column) (string, error) {
+	t := c.goType
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	mysql, postgres, sqlite := "", "", ""
+
+	switch {
+	case t == timeType || t == nullTimeType:
+		mysql, postgres, sqlite = "DATETIME", "TIMESTAMP", "DATETIME"
+	case t == nullStringType:
+		t = reflect.TypeOf("")
+	case t == nullInt64Type:
+		t = reflect.TypeOf(int64(0))
+	case t == nullInt32Type:
+		t = reflect.TypeOf(int32(0))
+	case t == nullBoolType:
+		t = reflect.TypeOf(false)
+	case t == nullFloat64Type:
+		t = reflect.TypeOf(float64(0))
+	}
+
+	if mysql == "" {
+		switch t.Kind() {
+		case reflect.Bool:
+			mysql, postgres, sqlite = "BOOLEAN", "BOOLEAN", "INTEGER"
+		case reflect.Int8, reflect.Int16, reflect.Uint8:
+			mysql, postgres, sqlite = "SMALLINT", "SMALLINT", "INTEGER"
+		case reflect.Int32, reflect.Uint16:
+			mysql, postgres, sqlite = "INT", "INTEGER", "INTEGER"
+		case reflect.Int, reflect.Int64, reflect.Uint32, reflect.Uint, reflect.Uint64:
+			mysql, postgres, sqlite = "BIGINT", "BIGINT", "INTEGER"
+		case reflect.Float32:
+			mysql, postgres, sqlite = "FLOAT", "REAL", "REAL"
+		case reflect.Float64:
+			mysql, postgres, sqlite = "DOUBLE", "DOUBLE PRECISION", "REAL"
+		case reflect.String:
+			size := c.size
+			if size == 0 {
+				size = 255
+			}
+
+			varchar := fmt.Sprintf("VARCHAR(%d)", size)
+			mysql, postgres, sqlite = varchar, varchar, "TEXT"
+		case reflect.Slice:
+			if t.Elem().Kind() == reflect.Uint8 {
+				mysql, postgres, sqlite = "BLOB", "BYTEA", "BLOB"
+			}
+		default:
+		}
+	}
+
+	if mysql == "" {
+		return "", fmt.Errorf("%w for column %s of type %s, use the type option", errUnknownType, c.Name, c.goType)
+	}
+
+	switch g.dialect {
+	case qb.DialectPostgres:
+		return postgres, nil
+	case qb.DialectSQLite:
+		return sqlite, nil
+	default:
+		return mysql, nil
+	}
+}
+
+func (g *Generator) quote(identifier string) string {
+	if g.dialect == qb.DialectMySQL {
+		return "`" + identifier + "`"
+	}
+
+	return `"` + identifier + `"`
+}
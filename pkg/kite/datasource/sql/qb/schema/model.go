@@ -0,0 +1,231 @@
+// Package schema generates dialect-specific DDL statements (CREATE TABLE, ALTER TABLE ... ADD COLUMN and
+// CREATE INDEX) from Go struct definitions, so that migrations can share a single definition of a table
+// across mysql, postgres and sqlite.
+//
+// Columns are named after the "db" tag of a field, or the snake case of the field name. The "schema" tag
+// holds semicolon separated options:
+//
+//	type User struct {
+//		ID        int64     `db:"id" schema:"primary_key;auto_increment"`
+//		Email     string    `db:"email" schema:"type:varchar(320);not_null;unique"`
+//		Status    string    `schema:"size:16;not_null;default:'active';index:idx_users_status_created"`
+//		CreatedAt time.Time `schema:"not_null;default:CURRENT_TIMESTAMP;index:idx_users_status_created"`
+//		Note      *string   `schema:"-"`
+//	}
+//
+// Supported options:
+//   - type:<sql type> overrides the column type derived from the Go type
+//   - size:<n> sets the length of string columns, 255 by default
+//   - not_null, null set the nullability of the column; columns are nullable by default
+//   - default:<expr> sets the default value, written to the statement as is
+//   - primary_key, auto_increment
+//   - index, index:<name> adds the column to an index; columns sharing an index name form a composite index
+//   - unique, unique_index:<name> adds the column to a unique index
+//   - "-" skips the field
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	errInvalidModel = errors.New("[schema] model must be a struct or a pointer to a struct")
+	errInvalidTag   = errors.New("[schema] invalid schema tag")
+	errUnknownType  = errors.New("[schema] cannot derive column type")
+	errNoColumns    = errors.New("[schema] model has no columns")
+	errNoColumn     = errors.New("[schema] column does not exist in model")
+
+	matchFirstCap = regexp.MustCompile("(.)([A-Z][a-z]+)")
+	matchAllCap   = regexp.MustCompile("([a-z0-9])([A-Z])")
+
+	timeType = reflect.TypeOf(time.Time{})
+)
+
+// TableNamer can be implemented by a model to override the table name, which is the snake case of the
+// struct name by default.
+type TableNamer interface {
+	TableName() string
+}
+
+// Table is the definition of a table parsed from a model.
+type Table struct {
+	Name    string
+	Columns []Column
+	Indexes []Index
+}
+
+// Column is the definition of a column parsed from a struct field.
+type Column struct {
+	Name          string
+	Type          string
+	NotNull       bool
+	Default       string
+	PrimaryKey    bool
+	AutoIncrement bool
+
+	goType reflect.Type
+	size   int
+}
+
+// Index is the definition of an index. Its columns are in the order of the struct fields.
+type Index struct {
+	Name    string
+	Columns []string
+	Unique  bool
+}
+
+// Parse parses the table definition of a model.
+func Parse(model any) (*Table, error) {
+	t := reflect.TypeOf(model)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w, got %T", errInvalidModel, model)
+	}
+
+	table := &Table{Name: toSnakeCase(t.Name())}
+	if n, ok := model.(TableNamer); ok {
+		table.Name = n.TableName()
+	}
+
+	indexes := make(map[string]*Index)
+
+	var indexOrder []string
+
+	addToIndex := func(name, column string, unique bool) {
+		idx, ok := indexes[name]
+		if !ok {
+			idx = &Index{Name: name, Unique: unique}
+			indexes[name] = idx
+			indexOrder = append(indexOrder, name)
+		}
+
+		idx.Columns = append(idx.Columns, column)
+		idx.Unique = idx.Unique || unique
+	}
+
+	if err := parseFields(t, table, addToIndex); err != nil {
+		return nil, err
+	}
+
+	if len(table.Columns) == 0 {
+		return nil, fmt.Errorf("%w: %s", errNoColumns, t.Name())
+	}
+
+	for _, name := range indexOrder {
+		table.Indexes = append(table.Indexes, *indexes[name])
+	}
+
+	return table, nil
+}
+
+// Column returns the column with the given name.
+func (t *Table) Column(name string) (Column, error) {
+	for _, c := range t.Columns {
+		if c.Name == name {
+			return c, nil
+		}
+	}
+
+	return Column{}, fmt.Errorf("%w: %s.%s", errNoColumn, t.Name, name)
+}
+
+func parseFields(t reflect.Type, table *Table, addToIndex func(name, column string, unique bool)) error {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && f.Type != timeType && f.Tag.Get("schema") == "" {
+			if err := parseFields(f.Type, table, addToIndex); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if !f.IsExported() || f.Tag.Get("db") == "-" || f.Tag.Get("schema") == "-" {
+			continue
+		}
+
+		col := Column{Name: f.Tag.Get("db"), goType: f.Type}
+		if col.Name == "" {
+			col.Name = toSnakeCase(f.Name)
+		}
+
+		if err := parseTag(f.Tag.Get("schema"), table.Name, &col, addToIndex); err != nil {
+			return fmt.Errorf("%w on field %s: %w", errInvalidTag, f.Name, err)
+		}
+
+		table.Columns = append(table.Columns, col)
+	}
+
+	return nil
+}
+
+func parseTag(tag, tableName string, col *Column, addToIndex func(name, column string, unique bool)) error {
+	if tag == "" {
+		return nil
+	}
+
+	for _, option := range strings.Split(tag, ";") {
+		key, value, _ := strings.Cut(strings.TrimSpace(option), ":")
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "":
+		case "type":
+			col.Type = value
+		case "size":
+			size, err := strconv.Atoi(value)
+			if err != nil || size <= 0 {
+				return fmt.Errorf("invalid size %q", value)
+			}
+
+			col.size = size
+		case "not_null":
+			col.NotNull = true
+		case "null":
+			col.NotNull = false
+		case "default":
+			col.Default = value
+		case "primary_key":
+			col.PrimaryKey = true
+			col.NotNull = true
+		case "auto_increment":
+			col.AutoIncrement = true
+		case "index", "unique_index", "unique":
+			unique := key != "index"
+
+			name := value
+			if name == "" {
+				prefix := "idx_"
+				if unique {
+					prefix = "uidx_"
+				}
+
+				name = prefix + tableName + "_" + col.Name
+			}
+
+			addToIndex(name, col.Name, unique)
+		default:
+			return fmt.Errorf("unknown option %q", key)
+		}
+	}
+
+	return nil
+}
+
+func toSnakeCase(str string) string {
+	snake := matchFirstCap.ReplaceAllString(str, "${1}_${2}")
+	snake = matchAllCap.ReplaceAllString(snake, "${1}_${2}")
+
+	return strings.ToLower(snake)
+}
@@ -0,0 +1,125 @@
+package schema
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type timestamps struct {
+	CreatedAt time.Time `schema:"not_null;default:CURRENT_TIMESTAMP;index:idx_user_account_status_created"`
+}
+
+type UserAccount struct {
+	ID     int64          `db:"id" schema:"primary_key;auto_increment"`
+	Email  string         `db:"email" schema:"type:varchar(320);not_null;unique"`
+	Status string         `schema:"size:16;not_null;default:'active';index:idx_user_account_status_created"`
+	Score  float64        `schema:"default:0"`
+	Bio    sql.NullString `schema:"size:1024"`
+	Note   *string        `schema:"-"`
+	secret string
+
+	timestamps
+}
+
+type tenantRole struct {
+	TenantID int64  `schema:"primary_key"`
+	UserID   int64  `schema:"primary_key;index"`
+	Role     string `schema:"not_null"`
+}
+
+func (tenantRole) TableName() string { return "tenant_roles" }
+
+func TestGenerator_CreateTable(t *testing.T) {
+	testCases := []struct {
+		dialect string
+		model   any
+		stmt    string
+	}{
+		{"mysql", UserAccount{}, "CREATE TABLE IF NOT EXISTS `user_account` (`id` BIGINT NOT NULL AUTO_INCREMENT, " +
+			"`email` varchar(320) NOT NULL, `status` VARCHAR(16) NOT NULL DEFAULT 'active', `score` DOUBLE DEFAULT 0, " +
+			"`bio` VARCHAR(1024), `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY (`id`))"},
+		{"postgres", &UserAccount{}, `CREATE TABLE IF NOT EXISTS "user_account" ("id" BIGINT GENERATED BY DEFAULT AS IDENTITY, ` +
+			`"email" varchar(320) NOT NULL, "status" VARCHAR(16) NOT NULL DEFAULT 'active', "score" DOUBLE PRECISION DEFAULT 0, ` +
+			`"bio" VARCHAR(1024), "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY ("id"))`},
+		{"sqlite", UserAccount{}, `CREATE TABLE IF NOT EXISTS "user_account" ("id" INTEGER PRIMARY KEY AUTOINCREMENT, ` +
+			`"email" varchar(320) NOT NULL, "status" TEXT NOT NULL DEFAULT 'active', "score" REAL DEFAULT 0, ` +
+			`"bio" TEXT, "created_at" DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP)`},
+		{"sqlite", tenantRole{}, `CREATE TABLE IF NOT EXISTS "tenant_roles" ("tenant_id" INTEGER NOT NULL, ` +
+			`"user_id" INTEGER NOT NULL, "role" TEXT NOT NULL, PRIMARY KEY ("tenant_id", "user_id"))`},
+	}
+
+	for i, tc := range testCases {
+		g, err := New(tc.dialect)
+		require.NoError(t, err)
+
+		stmt, err := g.CreateTable(tc.model)
+
+		require.NoError(t, err, "TEST[%d], Failed.\n", i)
+		assert.Equal(t, tc.stmt, stmt, "TEST[%d], Failed.\n", i)
+	}
+}
+
+func TestGenerator_CreateIndexes(t *testing.T) {
+	mysql, err := New("mysql")
+	require.NoError(t, err)
+
+	stmts, err := mysql.CreateIndexes(UserAccount{})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"CREATE UNIQUE INDEX `uidx_user_account_email` ON `user_account` (`email`)",
+		"CREATE INDEX `idx_user_account_status_created` ON `user_account` (`status`, `created_at`)",
+	}, stmts)
+
+	postgres, err := New("postgresql")
+	require.NoError(t, err)
+
+	stmts, err = postgres.CreateIndexes(tenantRole{})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{`CREATE INDEX IF NOT EXISTS "idx_tenant_roles_user_id" ON "tenant_roles" ("user_id")`}, stmts)
+}
+
+func TestGenerator_AddColumn(t *testing.T) {
+	g, err := New("postgres")
+	require.NoError(t, err)
+
+	stmt, err := g.AddColumn(UserAccount{}, "status")
+
+	require.NoError(t, err)
+	assert.Equal(t, `ALTER TABLE "user_account" ADD COLUMN "status" VARCHAR(16) NOT NULL DEFAULT 'active'`, stmt)
+
+	_, err = g.AddColumn(UserAccount{}, "note")
+	require.ErrorIs(t, err, errNoColumn)
+}
+
+func TestGenerator_Errors(t *testing.T) {
+	g, err := New("sqlite")
+	require.NoError(t, err)
+
+	_, err = g.CreateTable(struct {
+		ID   int64 `schema:"primary_key;auto_increment"`
+		Kind int64 `schema:"primary_key"`
+	}{})
+	require.ErrorIs(t, err, errCompositeAutoIncrement)
+
+	_, err = g.CreateTable(struct {
+		ID int64 `schema:"primary"`
+	}{})
+	require.ErrorIs(t, err, errInvalidTag)
+
+	_, err = g.CreateTable(struct {
+		Tags map[string]string
+	}{})
+	require.ErrorIs(t, err, errUnknownType)
+
+	_, err = g.CreateTable("users")
+	require.ErrorIs(t, err, errInvalidModel)
+
+	_, err = New("oracle")
+	require.Error(t, err)
+}
@@ -0,0 +1,105 @@
+package qb
+
+import (
+	"strings"
+	"unicode"
+)
+
+// WithTablePrefix returns a copy of the builder which prefixes the table names of the queries, e.g. "app_" builds
+// the queries of "users" on "app_users". The table names are then quoted for the dialect.
+func (b Builder) WithTablePrefix(prefix string) *Builder {
+	b.tablePrefix = prefix
+
+	return &b
+}
+
+// WithSchema returns a copy of the builder which qualifies the table names of the queries with schema, e.g.
+// "analytics"."users" for PostgreSQL. The table names already qualified with a schema keep it, and the table names
+// are then quoted for the dialect. An empty schema restores the unqualified table names.
+func (b Builder) WithSchema(schema string) *Builder {
+	b.schema = schema
+
+	return &b
+}
+
+// tableName returns table with the prefix and the schema of the builder, quoted for the dialect, followed by its
+// alias if any, e.g. "users u". It returns table unchanged when the builder has neither a prefix nor a schema.
+func (b Builder) tableName(table string) string {
+	if b.tablePrefix == "" && b.schema == "" {
+		return table
+	}
+
+	table = strings.TrimSpace(table)
+
+	name, alias := table, ""
+	if i := strings.IndexFunc(table, unicode.IsSpace); i >= 0 {
+		name, alias = table[:i], table[i:]
+	}
+
+	parts := splitQualifiedName(name)
+
+	qualifiers := parts[:len(parts)-1]
+	if len(qualifiers) == 0 && b.schema != "" {
+		qualifiers = []string{b.schema}
+	}
+
+	quoted := make([]string, 0, len(qualifiers)+1)
+	for _, q := range qualifiers {
+		quoted = append(quoted, b.quoteIdentifier(q))
+	}
+
+	quoted = append(quoted, b.quoteIdentifier(b.tablePrefix+parts[len(parts)-1]))
+
+	return strings.Join(quoted, ".") + alias
+}
+
+// quoteIdentifier quotes name with the backticks of MySQL or the double quotes of PostgreSQL and SQLite, doubling
+// the quotes within the name.
+func (b Builder) quoteIdentifier(name string) string {
+	quote := `"`
+	if b.dialect == DialectMySQL {
+		quote = "`"
+	}
+
+	return quote + strings.ReplaceAll(name, quote, quote+quote) + quote
+}
+
+// splitQualifiedName splits a table name such as `analytics.users` or "analytics"."users" into its unquoted parts.
+// The dots within quotes do not split the name.
+func splitQualifiedName(name string) []string {
+	var (
+		parts []string
+		part  strings.Builder
+		quote rune
+	)
+
+	runes := []rune(name)
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case quote != 0 && r == quote:
+			// a doubled quote is a quote of the name.
+			if i+1 < len(runes) && runes[i+1] == quote {
+				part.WriteRune(r)
+				i++
+
+				continue
+			}
+
+			quote = 0
+		case quote != 0:
+			part.WriteRune(r)
+		case r == '`' || r == '"':
+			quote = r
+		case r == '.':
+			parts = append(parts, part.String())
+			part.Reset()
+		default:
+			part.WriteRune(r)
+		}
+	}
+
+	return append(parts, part.String())
+}
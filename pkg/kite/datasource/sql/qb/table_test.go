@@ -0,0 +1,91 @@
+package qb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder_tableName(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		dialect  string
+		prefix   string
+		schema   string
+		table    string
+		expected string
+	}{
+		{desc: "no option", dialect: "postgres", table: "users", expected: "users"},
+		{desc: "mysql prefix", dialect: "mysql", prefix: "app_", table: "users", expected: "`app_users`"},
+		{desc: "postgres schema", dialect: "postgres", schema: "analytics", table: "users",
+			expected: `"analytics"."users"`},
+		{desc: "sqlite prefix and schema", dialect: "sqlite", prefix: "app_", schema: "main", table: "users",
+			expected: `"main"."app_users"`},
+		{desc: "alias", dialect: "mysql", prefix: "app_", schema: "shop", table: "users AS u",
+			expected: "`shop`.`app_users` AS u"},
+		{desc: "qualified table keeps its schema", dialect: "postgres", prefix: "app_", schema: "analytics",
+			table: "public.users", expected: `"public"."app_users"`},
+		{desc: "quoted table", dialect: "postgres", prefix: "app_", table: `"order.items"`,
+			expected: `"app_order.items"`},
+		{desc: "embedded quotes", dialect: "mysql", schema: "a`b", table: "users", expected: "`a``b`.`users`"},
+	}
+
+	for i, tc := range testCases {
+		b, err := New(tc.dialect)
+		require.NoError(t, err, "TEST[%d], Failed.\n%s", i, tc.desc)
+
+		assert.Equal(t, tc.expected, b.WithTablePrefix(tc.prefix).WithSchema(tc.schema).tableName(tc.table),
+			"TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestBuilder_WithTablePrefix_Queries(t *testing.T) {
+	b, err := New("postgres")
+	require.NoError(t, err)
+
+	b = b.WithTablePrefix("app_").WithSchema("analytics")
+	row := []map[string]interface{}{{"id": 1, "name": "kite"}}
+
+	testCases := []struct {
+		desc     string
+		build    func() (string, []interface{}, error)
+		expected string
+	}{
+		{desc: "select", build: func() (string, []interface{}, error) {
+			return b.BuildSelect("users", map[string]interface{}{"id": 1}, nil)
+		}, expected: `SELECT * FROM "analytics"."app_users" WHERE (id=$1)`},
+		{desc: "update", build: func() (string, []interface{}, error) {
+			return b.BuildUpdate("users", map[string]interface{}{"id": 1}, map[string]interface{}{"name": "kite"})
+		}, expected: `UPDATE "analytics"."app_users" SET name=$1 WHERE (id=$2)`},
+		{desc: "delete", build: func() (string, []interface{}, error) {
+			return b.BuildDelete("users", map[string]interface{}{"id": 1})
+		}, expected: `DELETE FROM "analytics"."app_users" WHERE (id=$1)`},
+		{desc: "insert", build: func() (string, []interface{}, error) {
+			return b.BuildInsert("users", row)
+		}, expected: `INSERT INTO "analytics"."app_users" (id,name) VALUES ($1,$2)`},
+		{desc: "upsert", build: func() (string, []interface{}, error) {
+			return b.BuildUpsert("users", row, []string{"id"}, map[string]interface{}{"name": "kite"})
+		}, expected: `INSERT INTO "analytics"."app_users" (id,name) VALUES ($1,$2) ON CONFLICT (id) DO UPDATE SET name=$3`},
+	}
+
+	for i, tc := range testCases {
+		cond, _, err := tc.build()
+
+		require.NoError(t, err, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, tc.expected, cond, "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestBuilder_WithTablePrefix_BulkUpdate(t *testing.T) {
+	b, err := New("postgres")
+	require.NoError(t, err)
+
+	cond, _, err := b.WithTablePrefix("app_").BuildBulkUpdate("users",
+		[]map[string]interface{}{{"id": 1, "name": "kite"}}, []string{"id"})
+
+	require.NoError(t, err)
+	assert.Contains(t, cond, `UPDATE "app_users" SET`)
+	assert.Contains(t, cond, `(NULL::"app_users").name`)
+	assert.Contains(t, cond, `"app_users".id=v.id`)
+}
@@ -0,0 +1,167 @@
+package qb
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// zonedTimeLayout is the layout of the time parameters of the dialects storing times without time zone.
+const zonedTimeLayout = "2006-01-02 15:04:05.999999"
+
+// zonedTime is a time parameter converted to the time zone of the stored times when the query is finalized, as the
+// drivers of the dialects do not agree on how they pass a time.Time.
+type zonedTime struct {
+	t   time.Time
+	loc *time.Location
+}
+
+// TimeBetween checks whether field is in the half-open range [from, to), so that consecutive ranges, e.g. of days, do
+// not count the values on their bounds twice as BETWEEN does. loc is the time zone of the times stored in field,
+// UTC when nil; from and to can be in any time zone, they are converted to loc:
+//
+//	paris, _ := time.LoadLocation("Europe/Paris")
+//	day := time.Date(2024, 3, 10, 0, 0, 0, 0, paris)
+//
+//	where := map[string]interface{}{"_custom_day": qb.TimeBetween("created_at", day, day.AddDate(0, 0, 1), time.UTC)}
+//	// SELECT * FROM orders WHERE (created_at>=? AND created_at<?) with '2024-03-09 23:00:00', '2024-03-10 23:00:00'
+//
+// The parameters are passed as the wall clock time in loc for MySQL and SQLite, whose DATETIME and text columns have no
+// time zone, and as a time.Time in loc for PostgreSQL, which compares it as an instant to a timestamptz column and by
+// its wall clock to a timestamp column.
+func TimeBetween(field string, from, to time.Time, loc *time.Location) Comparable {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	return rawSql{
+		sqlCond: "(" + field + ">=? AND " + field + "<?)",
+		values:  []interface{}{zonedTime{t: from, loc: loc}, zonedTime{t: to, loc: loc}},
+	}
+}
+
+// convertTimeValues converts the parameters of TimeBetween for the dialect of the builder. The values are copied
+// before they are converted, as they may be shared with the conditions.
+func (b Builder) convertTimeValues(vals []interface{}) []interface{} {
+	copied := false
+
+	for i, v := range vals {
+		zt, ok := v.(zonedTime)
+		if !ok {
+			continue
+		}
+
+		if !copied {
+			vals = append([]interface{}(nil), vals...)
+			copied = true
+		}
+
+		if b.dialect == DialectPostgres {
+			vals[i] = zt.t.In(zt.loc)
+		} else {
+			vals[i] = zt.t.In(zt.loc).Format(zonedTimeLayout)
+		}
+	}
+
+	return vals
+}
+
+// DayBucket returns the expression of the day of field in loc, to group or order the rows by day, e.g. with the
+// "_groupby" key of the where map or as a select field:
+//
+//	b, _ := qb.New("postgres")
+//	day, err := b.DayBucket("created_at", paris)
+//	// date_trunc('day', created_at AT TIME ZONE 'Europe/Paris')
+//
+// The times of field are assumed to be stored in UTC, and are converted to loc unless it is nil or UTC:
+//   - MySQL uses CONVERT_TZ, which needs the time zone tables of the server for the named locations; the locations
+//     with a fixed offset, e.g. from time.FixedZone, are converted by their offset.
+//   - PostgreSQL uses AT TIME ZONE, for a timestamptz column.
+//   - SQLite only supports the locations with a fixed offset.
+func (b Builder) DayBucket(field string, loc *time.Location) (Raw, error) {
+	return b.timeBucket("day", field, loc)
+}
+
+// MonthBucket returns the expression of the first day of the month of field in loc. See DayBucket.
+func (b Builder) MonthBucket(field string, loc *time.Location) (Raw, error) {
+	return b.timeBucket("month", field, loc)
+}
+
+func (b Builder) timeBucket(unit, field string, loc *time.Location) (Raw, error) {
+	offset, fixed := fixedOffset(loc)
+	convert := loc != nil && loc != time.UTC && !(fixed && offset == 0)
+
+	switch b.dialect {
+	case DialectMySQL:
+		expr := field
+		if convert {
+			zone := loc.String()
+			if fixed {
+				zone = formatOffset(offset)
+			}
+
+			expr = "CONVERT_TZ(" + field + ",'+00:00'," + quoteLiteral(zone) + ")"
+		}
+
+		if unit == "month" {
+			return Raw("DATE_FORMAT(" + expr + ",'%Y-%m-01')"), nil
+		}
+
+		return Raw("DATE(" + expr + ")"), nil
+	case DialectPostgres:
+		zone := "'UTC'"
+
+		switch {
+		case convert && fixed:
+			zone = "INTERVAL " + quoteLiteral(formatOffset(offset))
+		case convert:
+			zone = quoteLiteral(loc.String())
+		}
+
+		return Raw("date_trunc('" + unit + "', " + field + " AT TIME ZONE " + zone + ")"), nil
+	case DialectSQLite:
+		args := []string{field}
+
+		if convert {
+			if !fixed {
+				return "", b.unsupportedFeature("time zone " + loc.String())
+			}
+
+			args = append(args, fmt.Sprintf("'%+d minutes'", offset/60))
+		}
+
+		if unit == "month" {
+			args = append(args, "'start of month'")
+		}
+
+		return Raw("date(" + strings.Join(args, ",") + ")"), nil
+	default:
+		return "", fmt.Errorf("%w: %q", errUnsupportedDialect, b.dialect)
+	}
+}
+
+// fixedOffset returns the offset in seconds of loc and whether it is the same all year, i.e. loc has no daylight
+// saving time.
+func fixedOffset(loc *time.Location) (int, bool) {
+	if loc == nil {
+		return 0, true
+	}
+
+	year := time.Now().Year()
+
+	_, winter := time.Date(year, time.January, 1, 0, 0, 0, 0, loc).Zone()
+	_, summer := time.Date(year, time.July, 1, 0, 0, 0, 0, loc).Zone()
+
+	return winter, winter == summer
+}
+
+// formatOffset formats an offset in seconds as ±hh:mm.
+func formatOffset(offset int) string {
+	sign := '+'
+	if offset < 0 {
+		sign = '-'
+		offset = -offset
+	}
+
+	return fmt.Sprintf("%c%02d:%02d", sign, offset/3600, offset%3600/60)
+}
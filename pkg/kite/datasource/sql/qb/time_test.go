@@ -0,0 +1,131 @@
+package qb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeBetween_ParametersPerDialect(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+
+	day := time.Date(2024, 3, 10, 0, 0, 0, 0, paris)
+
+	tests := []struct {
+		dialect  string
+		expected string
+		from, to interface{}
+	}{
+		{dialect: "mysql", expected: "SELECT * FROM orders WHERE ((created_at>=? AND created_at<?) AND status=?)",
+			from: "2024-03-09 23:00:00", to: "2024-03-10 23:00:00"},
+		{dialect: "postgres", expected: "SELECT * FROM orders WHERE ((created_at>=$1 AND created_at<$2) AND status=$3)",
+			from: day.In(time.UTC), to: day.AddDate(0, 0, 1).In(time.UTC)},
+		{dialect: "sqlite", expected: "SELECT * FROM orders WHERE ((created_at>=? AND created_at<?) AND status=?)",
+			from: "2024-03-09 23:00:00", to: "2024-03-10 23:00:00"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.dialect, func(t *testing.T) {
+			cond := TimeBetween("created_at", day, day.AddDate(0, 0, 1), nil)
+
+			query, vals, err := BuildSelectWithDialect(tc.dialect, "orders", map[string]interface{}{
+				"_custom_day": cond,
+				"status":      "paid",
+			}, nil)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, query)
+			assert.Equal(t, []interface{}{tc.from, tc.to, "paid"}, vals)
+
+			// the parameters of the condition are not converted in place.
+			_, condVals := cond.Build()
+			assert.IsType(t, zonedTime{}, condVals[0])
+		})
+	}
+}
+
+func TestTimeBetween_StoredInLocation(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	_, vals, err := BuildDeleteWithDialect("mysql", "events", map[string]interface{}{
+		"_custom_": TimeBetween("at", from, from.Add(90*time.Minute), tokyo),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"2024-01-01 09:00:00", "2024-01-01 10:30:00"}, vals)
+}
+
+func TestBuilder_TimeBuckets(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+
+	india := time.FixedZone("IST", 5*3600+1800)
+	west := time.FixedZone("", -3*3600)
+
+	tests := []struct {
+		dialect string
+		loc     *time.Location
+		day     Raw
+		month   Raw
+	}{
+		{"mysql", nil, "DATE(created_at)", "DATE_FORMAT(created_at,'%Y-%m-01')"},
+		{"mysql", time.UTC, "DATE(created_at)", "DATE_FORMAT(created_at,'%Y-%m-01')"},
+		{"mysql", paris, "DATE(CONVERT_TZ(created_at,'+00:00','Europe/Paris'))",
+			"DATE_FORMAT(CONVERT_TZ(created_at,'+00:00','Europe/Paris'),'%Y-%m-01')"},
+		{"mysql", india, "DATE(CONVERT_TZ(created_at,'+00:00','+05:30'))",
+			"DATE_FORMAT(CONVERT_TZ(created_at,'+00:00','+05:30'),'%Y-%m-01')"},
+		{"postgres", nil, "date_trunc('day', created_at AT TIME ZONE 'UTC')",
+			"date_trunc('month', created_at AT TIME ZONE 'UTC')"},
+		{"postgres", paris, "date_trunc('day', created_at AT TIME ZONE 'Europe/Paris')",
+			"date_trunc('month', created_at AT TIME ZONE 'Europe/Paris')"},
+		{"postgres", west, "date_trunc('day', created_at AT TIME ZONE INTERVAL '-03:00')",
+			"date_trunc('month', created_at AT TIME ZONE INTERVAL '-03:00')"},
+		{"sqlite", nil, "date(created_at)", "date(created_at,'start of month')"},
+		{"sqlite", india, "date(created_at,'+330 minutes')", "date(created_at,'+330 minutes','start of month')"},
+	}
+
+	for i, tc := range tests {
+		b, err := New(tc.dialect)
+		require.NoError(t, err)
+
+		day, err := b.DayBucket("created_at", tc.loc)
+		require.NoError(t, err, "TEST[%d], Failed.\n%s", i, tc.dialect)
+		assert.Equal(t, tc.day, day, "TEST[%d], Failed.\n%s", i, tc.dialect)
+
+		month, err := b.MonthBucket("created_at", tc.loc)
+		require.NoError(t, err, "TEST[%d], Failed.\n%s", i, tc.dialect)
+		assert.Equal(t, tc.month, month, "TEST[%d], Failed.\n%s", i, tc.dialect)
+	}
+}
+
+func TestBuilder_TimeBucketGroupBy(t *testing.T) {
+	b, err := New("postgres")
+	require.NoError(t, err)
+
+	day, err := b.DayBucket("created_at", nil)
+	require.NoError(t, err)
+
+	query, _, err := b.BuildSelect("orders", map[string]interface{}{"_groupby": day},
+		[]string{string(day) + " AS day", "count(*)"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT date_trunc('day', created_at AT TIME ZONE 'UTC') AS day,count(*) FROM orders "+
+		"GROUP BY date_trunc('day', created_at AT TIME ZONE 'UTC')", query)
+}
+
+func TestBuilder_TimeBucketUnsupportedZone(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+
+	b, err := New("sqlite")
+	require.NoError(t, err)
+
+	_, err = b.MonthBucket("created_at", paris)
+	require.ErrorIs(t, err, errFeatureUnsupportedDialect)
+}
@@ -25,6 +25,8 @@ func BuildUpsertWithDialect(dialect, table string, data []map[string]interface{}
 
 // BuildUpsert builds an upsert query for the current builder dialect.
 func (b Builder) BuildUpsert(table string, data []map[string]interface{}, conflictColumns []string, update map[string]interface{}) (string, []interface{}, error) {
+	table = b.tableName(table)
+
 	if len(update) == 0 {
 		switch b.dialect {
 		case DialectMySQL:
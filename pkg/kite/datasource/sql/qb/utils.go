@@ -3,6 +3,7 @@ package qb
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
@@ -354,6 +355,40 @@ func JsonRemove(field string, path ...string) Comparable {
 	}
 }
 
+// JsonbPathMatch checks whether the jsonb field matches the JSONPath predicate jsonPath, eg `$.status == "active"`.
+//
+// PostgreSQL only. The @@ operator can use a GIN index on field.
+//
+// usage where := map[string]interface{}{"_custom_xxx": builder.JsonbPathMatch("data", `$.age > 18`)}
+func JsonbPathMatch(field, jsonPath string) Comparable {
+	jsonPath = strings.TrimSpace(jsonPath)
+	if jsonPath == "" {
+		return errorComparable{err: errInvalidJSONPathValue}
+	}
+
+	return rawSql{
+		sqlCond: field + " @@ ?::jsonpath",
+		values:  []interface{}{jsonPath},
+	}
+}
+
+// JsonbContains checks whether the jsonb field contains jsonLike, which is marshaled to JSON.
+//
+// PostgreSQL only. The @> operator can use a GIN index on field.
+//
+// usage where := map[string]interface{}{"_custom_xxx": builder.JsonbContains("data", map[string]any{"tags": []string{"new"}})}
+func JsonbContains(field string, jsonLike interface{}) Comparable {
+	b, err := json.Marshal(jsonLike)
+	if err != nil {
+		return errorComparable{err: fmt.Errorf("%w: %w", errUnsupportedJSONType, err)}
+	}
+
+	return rawSql{
+		sqlCond: field + " @> ?::jsonb",
+		values:  []interface{}{string(b)},
+	}
+}
+
 // jsonUpdateCall build args then call fn
 func jsonUpdateCall(fn string, field string, pathAndValuePair ...interface{}) Comparable {
 	if len(pathAndValuePair) == 0 || len(pathAndValuePair)%2 != 0 {
@@ -690,6 +690,31 @@ func TestJsonContains_UnsupportedTypeReturnsError(t *testing.T) {
 	assert.ErrorIs(t, err, errUnsupportedJSONType)
 }
 
+func TestJsonbPathMatch(t *testing.T) {
+	b, err := New("postgres")
+	require.NoError(t, err)
+
+	cond, vals, err := b.BuildSelect("docs", map[string]interface{}{
+		"_custom_0": JsonbPathMatch("data", ` $.age > 18 `),
+		"_limit":    []uint{0, 10},
+	}, []string{"data"})
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT data FROM docs WHERE (data @@ $1::jsonpath) LIMIT $2 OFFSET $3", cond)
+	assert.Equal(t, []interface{}{"$.age > 18", 10, 0}, vals)
+
+	_, _, err = b.BuildSelect("docs", map[string]interface{}{"_custom_0": JsonbPathMatch("data", " ")}, nil)
+	assert.ErrorIs(t, err, errInvalidJSONPathValue)
+}
+
+func TestJsonbContains(t *testing.T) {
+	cond, vals := JsonbContains("data", map[string]interface{}{"tags": []string{"new"}}).Build()
+	assert.Equal(t, []string{"data @> ?::jsonb"}, cond)
+	assert.Equal(t, []interface{}{`{"tags":["new"]}`}, vals)
+
+	_, _, err := BuildSelect("docs", map[string]interface{}{"_custom_0": JsonbContains("data", make(chan int))}, nil)
+	assert.ErrorIs(t, err, errUnsupportedJSONType)
+}
+
 func TestJsonSet_InvalidPathReturnsError(t *testing.T) {
 	_, _, err := BuildUpdate("xx", map[string]interface{}{"id": 1}, map[string]interface{}{
 		"_custom_0": JsonSet("my_json", 1, "v"),
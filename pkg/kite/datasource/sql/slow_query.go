@@ -0,0 +1,243 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sllt/kite/pkg/kite/config"
+	"github.com/sllt/kite/pkg/kite/datasource"
+	"github.com/sllt/kite/pkg/kite/datasource/sql/qb"
+	"github.com/sllt/kite/pkg/kite/logging"
+)
+
+const (
+	defaultExplainInterval = time.Minute
+	explainTimeout         = 5 * time.Second
+	// maxExplainedFingerprints bounds the fingerprints remembered for the sampling.
+	maxExplainedFingerprints = 1000
+)
+
+// planStringLiteral matches the string literals of plans, e.g. the arguments PostgreSQL writes into its filters.
+var planStringLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+
+// SlowQueryLog is logged at WARN with the plan of a query slower than SLOW_QUERY_THRESHOLD.
+type SlowQueryLog struct {
+	// Fingerprint identifies the query regardless of its arguments and literals.
+	Fingerprint string `json:"fingerprint"`
+	// Query is the query with its literals replaced by placeholders.
+	Query    string `json:"query"`
+	Duration int64  `json:"duration"`
+	Plan     string `json:"plan"`
+}
+
+func (l *SlowQueryLog) PrettyPrint(writer io.Writer) {
+	fmt.Fprintf(writer, "\u001B[38;5;8m%-32s \u001B[38;5;220m%-6s\u001B[0m %8d\u001B[38;5;8mms\u001B[0m %s\n%s\n",
+		l.Fingerprint, "SLOW", l.Duration, clean(l.Query), l.Plan)
+}
+
+// Redact implements logging.Redactable, masking the literals of the query and of the plan.
+func (l *SlowQueryLog) Redact(r *logging.Redactor) any {
+	c := *l
+	c.Query = r.String(l.Query)
+	c.Plan = r.String(l.Plan)
+
+	return &c
+}
+
+// slowQueryExplainer logs the plans of the queries slower than its threshold. The plans are read asynchronously,
+// one at a time, and at most once per interval for each query fingerprint, so that a burst of slow queries does not
+// load the database further.
+type slowQueryExplainer struct {
+	db        *sql.DB
+	dialect   string
+	logger    datasource.Logger
+	threshold time.Duration
+	interval  time.Duration
+
+	running atomic.Bool
+
+	mu        sync.Mutex
+	explained map[string]time.Time
+}
+
+// newSlowQueryExplainer returns the explainer configured by SLOW_QUERY_THRESHOLD, e.g. "500ms", and
+// SLOW_QUERY_EXPLAIN_INTERVAL. It returns nil when the threshold is not set.
+func newSlowQueryExplainer(configs config.Config, dialect string, logger datasource.Logger) *slowQueryExplainer {
+	value := configs.Get("SLOW_QUERY_THRESHOLD")
+	if value == "" {
+		return nil
+	}
+
+	threshold, err := time.ParseDuration(value)
+	if err != nil || threshold <= 0 {
+		logger.Errorf("invalid SLOW_QUERY_THRESHOLD %q, slow queries are not explained", value)
+
+		return nil
+	}
+
+	interval := defaultExplainInterval
+
+	if value := configs.Get("SLOW_QUERY_EXPLAIN_INTERVAL"); value != "" {
+		interval, err = time.ParseDuration(value)
+		if err != nil || interval < 0 {
+			logger.Warnf("invalid SLOW_QUERY_EXPLAIN_INTERVAL %q, using %v", value, defaultExplainInterval)
+
+			interval = defaultExplainInterval
+		}
+	}
+
+	return &slowQueryExplainer{
+		dialect:   dialect,
+		logger:    logger,
+		threshold: threshold,
+		interval:  interval,
+		explained: make(map[string]time.Time),
+	}
+}
+
+// observe explains the query in the background when it took longer than the threshold.
+func (e *slowQueryExplainer) observe(start time.Time, query string, args []any) {
+	if e == nil || e.db == nil {
+		return
+	}
+
+	elapsed := time.Since(start)
+	if elapsed < e.threshold {
+		return
+	}
+
+	explainQuery, ok := explainStatement(e.dialect, query)
+	if !ok {
+		return
+	}
+
+	sanitized := qb.Sanitize(query)
+	fingerprint := qb.Fingerprint(query)
+
+	if !e.sample(fingerprint, time.Now()) {
+		return
+	}
+
+	go func() {
+		defer e.running.Store(false)
+
+		plan, err := e.explain(explainQuery, args)
+		if err != nil {
+			e.logger.Debugf("could not explain slow query %s: %v", fingerprint, err)
+
+			return
+		}
+
+		e.logger.Warn(&SlowQueryLog{
+			Fingerprint: fingerprint,
+			Query:       sanitized,
+			Duration:    elapsed.Milliseconds(),
+			Plan:        plan,
+		})
+	}()
+}
+
+// sample reports whether the query with fingerprint must be explained now. It marks the explainer as running when
+// it returns true.
+func (e *slowQueryExplainer) sample(fingerprint string, now time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if last, ok := e.explained[fingerprint]; ok && now.Sub(last) < e.interval {
+		return false
+	}
+
+	if !e.running.CompareAndSwap(false, true) {
+		return false
+	}
+
+	if len(e.explained) >= maxExplainedFingerprints {
+		for fp, last := range e.explained {
+			if now.Sub(last) >= e.interval {
+				delete(e.explained, fp)
+			}
+		}
+	}
+
+	e.explained[fingerprint] = now
+
+	return true
+}
+
+func (e *slowQueryExplainer) explain(query string, args []any) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), explainTimeout)
+	defer cancel()
+
+	rows, err := e.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return "", err
+	}
+
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+
+	var lines []string
+
+	for rows.Next() {
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+
+		if err := rows.Scan(pointers...); err != nil {
+			return "", err
+		}
+
+		fields := make([]string, len(values))
+
+		for i, v := range values {
+			if b, ok := v.([]byte); ok {
+				v = string(b)
+			}
+
+			fields[i] = fmt.Sprint(v)
+		}
+
+		lines = append(lines, strings.Join(fields, " | "))
+	}
+
+	// the arguments of the query are not logged, nor are they in its plan.
+	return planStringLiteral.ReplaceAllString(strings.Join(lines, "\n"), "?"), rows.Err()
+}
+
+// explainStatement returns the statement reading the plan of query in dialect. Only the queries reading or
+// modifying rows are explained, the EXPLAIN statements do not run them.
+func explainStatement(dialect, query string) (string, bool) {
+	words := strings.Fields(query)
+	if len(words) == 0 {
+		return "", false
+	}
+
+	switch strings.ToUpper(words[0]) {
+	case "SELECT", "INSERT", "UPDATE", "DELETE", "WITH", "REPLACE":
+	default:
+		return "", false
+	}
+
+	switch dialect {
+	case sqlite:
+		return "EXPLAIN QUERY PLAN " + query, true
+	case dialectMysql, dialectPostgres, supabaseDialect, cockroachDB:
+		return "EXPLAIN " + query, true
+	default:
+		return "", false
+	}
+}
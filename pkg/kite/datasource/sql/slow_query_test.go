@@ -0,0 +1,130 @@
+package sql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sllt/kite/pkg/kite/config"
+	"github.com/sllt/kite/pkg/kite/datasource/sql/qb"
+	"github.com/sllt/kite/pkg/kite/logging"
+)
+
+// warnLogger sends the WARN logs to a channel, as the slow queries are explained asynchronously.
+type warnLogger struct {
+	logging.Logger
+	warns chan any
+}
+
+func (l *warnLogger) Warn(args ...any) {
+	l.warns <- args[0]
+}
+
+func newTestExplainer(t *testing.T, dialect string) (*slowQueryExplainer, sqlmock.Sqlmock, *warnLogger) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+
+	t.Cleanup(func() { db.Close() })
+
+	logger := &warnLogger{Logger: logging.NewMockLogger(logging.ERROR), warns: make(chan any, 1)}
+
+	e := newSlowQueryExplainer(config.NewMockConfig(map[string]string{"SLOW_QUERY_THRESHOLD": "100ms"}), dialect, logger)
+	require.NotNil(t, e)
+
+	e.db = db
+
+	return e, mock, logger
+}
+
+func TestSlowQueryExplainer_LogsPlan(t *testing.T) {
+	e, mock, logger := newTestExplainer(t, "postgres")
+
+	mock.ExpectQuery("EXPLAIN SELECT * FROM users WHERE email = $1").WithArgs("alice@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"QUERY PLAN"}).
+			AddRow("Seq Scan on users  (cost=0.00..35.50 rows=10 width=36)").
+			AddRow("  Filter: (email = 'alice@example.com'::text)"))
+
+	e.observe(time.Now().Add(-time.Second), "SELECT * FROM users WHERE email = $1", []any{"alice@example.com"})
+
+	select {
+	case log := <-logger.warns:
+		slow, ok := log.(*SlowQueryLog)
+		require.True(t, ok)
+
+		assert.Equal(t, "SELECT * FROM users WHERE email = $1", slow.Query)
+		assert.Equal(t, qb.Fingerprint("select * from users where email = ?"), slow.Fingerprint)
+		assert.GreaterOrEqual(t, slow.Duration, int64(1000))
+		assert.Equal(t, "Seq Scan on users  (cost=0.00..35.50 rows=10 width=36)\n  Filter: (email = ?::text)", slow.Plan)
+	case <-time.After(time.Second):
+		t.Fatal("the slow query was not logged")
+	}
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSlowQueryExplainer_SkipsFastQueries(t *testing.T) {
+	e, mock, _ := newTestExplainer(t, "mysql")
+
+	e.observe(time.Now(), "SELECT 1", nil)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSlowQueryExplainer_Sample(t *testing.T) {
+	e, _, _ := newTestExplainer(t, "mysql")
+	now := time.Now()
+
+	assert.True(t, e.sample("a", now))
+	assert.False(t, e.sample("b", now), "a plan is already being read")
+
+	e.running.Store(false)
+
+	assert.False(t, e.sample("a", now.Add(time.Second)), "a was explained less than an interval ago")
+	assert.True(t, e.sample("b", now))
+
+	e.running.Store(false)
+
+	assert.True(t, e.sample("a", now.Add(defaultExplainInterval)))
+}
+
+func TestNewSlowQueryExplainer_Config(t *testing.T) {
+	logger := logging.NewMockLogger(logging.FATAL)
+
+	assert.Nil(t, newSlowQueryExplainer(config.NewMockConfig(nil), "mysql", logger))
+	assert.Nil(t, newSlowQueryExplainer(config.NewMockConfig(map[string]string{"SLOW_QUERY_THRESHOLD": "fast"}), "mysql", logger))
+
+	e := newSlowQueryExplainer(config.NewMockConfig(map[string]string{
+		"SLOW_QUERY_THRESHOLD":        "2s",
+		"SLOW_QUERY_EXPLAIN_INTERVAL": "10m",
+	}), "mysql", logger)
+
+	require.NotNil(t, e)
+	assert.Equal(t, 2*time.Second, e.threshold)
+	assert.Equal(t, 10*time.Minute, e.interval)
+}
+
+func TestExplainStatement(t *testing.T) {
+	tests := []struct {
+		dialect  string
+		query    string
+		expected string
+	}{
+		{dialect: "mysql", query: "SELECT * FROM t", expected: "EXPLAIN SELECT * FROM t"},
+		{dialect: "cockroachdb", query: "\n  update t set a = 1", expected: "EXPLAIN \n  update t set a = 1"},
+		{dialect: "sqlite", query: "DELETE FROM t", expected: "EXPLAIN QUERY PLAN DELETE FROM t"},
+		{dialect: "postgres", query: "CREATE TABLE t (id int)"},
+		{dialect: "postgres", query: "  "},
+	}
+
+	for _, tc := range tests {
+		statement, ok := explainStatement(tc.dialect, tc.query)
+
+		assert.Equal(t, tc.expected != "", ok, tc.query)
+		assert.Equal(t, tc.expected, statement, tc.query)
+	}
+}
@@ -36,16 +36,24 @@ var (
 
 // DBConfig has those members which are necessary variables while connecting to database.
 type DBConfig struct {
-	Dialect     string
-	HostName    string
-	User        string
-	Password    string
-	Port        string
-	Database    string
+	// Name identifies a connection added with NewNamedSQL. It is empty for the default connection.
+	Name string
+	// Tenant identifies a connection opened for a tenant with NewTenantSQL. It is empty for the other connections.
+	Tenant   string
+	Dialect  string
+	HostName string
+	User     string
+	Password string
+	Port     string
+	Database string
+	// Schema sets the search_path of the postgres, supabase and cockroachdb connections, see DB_SCHEMA.
+	Schema      string
 	SSLMode     string
 	MaxIdleConn int
 	MaxOpenConn int
 	Charset     string
+	// LogFormatter rewrites the queries before they are logged, see DB_LOG_QUERY_FORMAT.
+	LogFormatter LogFormatter
 }
 
 func setupSupabaseDefaults(dbConfig *DBConfig, configs config.Config, logger datasource.Logger) {
@@ -72,8 +80,29 @@ func setupSupabaseDefaults(dbConfig *DBConfig, configs config.Config, logger dat
 }
 
 func NewSQL(configs config.Config, logger datasource.Logger, metrics Metrics) *DB {
+	return newSQL(getDBConfig(configs), configs, logger, metrics)
+}
+
+// NewNamedSQL creates a connection which is identified by name, for applications using more than one database.
+// It reads the same DB_* configs as NewSQL, and adds a "name" label to the metrics of the connection.
+func NewNamedSQL(name string, configs config.Config, logger datasource.Logger, metrics Metrics) *DB {
+	dbConfig := getDBConfig(configs)
+	dbConfig.Name = name
+
+	return newSQL(dbConfig, configs, logger, metrics)
+}
+
+// NewTenantSQL creates a connection for the tenant of multi-tenant applications, with configs being the view of the
+// tenant returned by tenant.Config, so that each tenant can have its own database (DB_NAME) or schema (DB_SCHEMA).
+// It adds a "tenant" label to the metrics of the connection.
+func NewTenantSQL(tenant string, configs config.Config, logger datasource.Logger, metrics Metrics) *DB {
 	dbConfig := getDBConfig(configs)
+	dbConfig.Tenant = tenant
+
+	return newSQL(dbConfig, configs, logger, metrics)
+}
 
+func newSQL(dbConfig *DBConfig, configs config.Config, logger datasource.Logger, metrics Metrics) *DB {
 	if dbConfig.Dialect == supabaseDialect {
 		setupSupabaseDefaults(dbConfig, configs, logger)
 	}
@@ -114,17 +143,24 @@ func NewSQL(configs config.Config, logger datasource.Logger, metrics Metrics) *D
 		return nil
 	}
 
-	database := &DB{config: dbConfig, logger: logger, metrics: metrics}
+	database := &DB{config: dbConfig, logger: logger, metrics: metrics, done: make(chan struct{}),
+		fingerprints: newFingerprintMetrics(configs, dbConfig, metrics, logger)}
 
 	printConnectionSuccessLog("connecting", database.config, logger)
 
-	database.DB, err = sql.Open(otelRegisteredDialect, dbConnectionString)
+	database.connector, err = newConnector(otelRegisteredDialect, dbConnectionString)
 	if err != nil {
 		printConnectionFailureLog("open connection with", database.config, database.logger, err)
 
 		return database
 	}
 
+	database.DB = sql.OpenDB(database.connector)
+
+	if database.slowQueries = newSlowQueryExplainer(configs, dbConfig.Dialect, logger); database.slowQueries != nil {
+		database.slowQueries.db = database.DB
+	}
+
 	// We are not setting idle connection timeout because we are checking for connection
 	// every 10 seconds which would need a connection, moreover if connection expires it is
 	// automatically closed by the database/sql package.
@@ -137,7 +173,7 @@ func NewSQL(configs config.Config, logger datasource.Logger, metrics Metrics) *D
 
 	go retryConnection(database)
 
-	go pushDBMetrics(database.DB, metrics)
+	go pushDBMetrics(database.DB, metrics, database.done, dbConfig.metricLabels()...)
 
 	return database
 }
@@ -184,11 +220,15 @@ func retryConnection(database *DB) {
 
 				printConnectionFailureLog("connect", database.config, database.logger, err)
 
-				time.Sleep(connRetryFrequencyInSeconds * time.Second)
+				if !sleep(database.done, connRetryFrequencyInSeconds*time.Second) {
+					return
+				}
 			}
 		}
 
-		time.Sleep(connRetryFrequencyInSeconds * time.Second)
+		if !sleep(database.done, connRetryFrequencyInSeconds*time.Second) {
+			return
+		}
 	}
 }
 
@@ -220,11 +260,13 @@ func getDBConfig(configs config.Config) *DBConfig {
 		Password:    configs.Get("DB_PASSWORD"),
 		Port:        configs.GetOrDefault("DB_PORT", strconv.Itoa(defaultDBPort)),
 		Database:    configs.Get("DB_NAME"),
+		Schema:      configs.Get("DB_SCHEMA"),
 		MaxOpenConn: maxOpenConn,
 		MaxIdleConn: maxIdleConn,
 		// Supported for postgres, supabase, cockroachdb, and mysql
-		SSLMode: configs.GetOrDefault("DB_SSL_MODE", "disable"),
-		Charset: configs.Get("DB_CHARSET"),
+		SSLMode:      configs.GetOrDefault("DB_SSL_MODE", "disable"),
+		Charset:      configs.Get("DB_CHARSET"),
+		LogFormatter: logFormatterFromConfig(configs.Get("DB_LOG_QUERY_FORMAT")),
 	}
 }
 
@@ -250,8 +292,14 @@ func getDBConnectionString(dbConfig *DBConfig) (string, error) {
 
 		return connStr, nil
 	case dialectPostgres, supabaseDialect, cockroachDB:
-		return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-			dbConfig.HostName, dbConfig.Port, dbConfig.User, dbConfig.Password, dbConfig.Database, dbConfig.SSLMode), nil
+		connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+			dbConfig.HostName, dbConfig.Port, dbConfig.User, dbConfig.Password, dbConfig.Database, dbConfig.SSLMode)
+
+		if dbConfig.Schema != "" {
+			connStr = fmt.Sprintf("%s search_path=%s", connStr, dbConfig.Schema)
+		}
+
+		return connStr, nil
 	case sqlite:
 		s := strings.TrimSuffix(dbConfig.Database, ".db")
 
@@ -261,21 +309,55 @@ func getDBConnectionString(dbConfig *DBConfig) (string, error) {
 	}
 }
 
-func pushDBMetrics(db *sql.DB, metrics Metrics) {
+func pushDBMetrics(db *sql.DB, metrics Metrics, done <-chan struct{}, labels ...string) {
 	const frequency = 10
 
 	for {
 		if db != nil {
 			stats := db.Stats()
 
-			metrics.SetGauge("app_sql_open_connections", float64(stats.OpenConnections))
-			metrics.SetGauge("app_sql_inUse_connections", float64(stats.InUse))
+			metrics.SetGauge("app_sql_open_connections", float64(stats.OpenConnections), labels...)
+			metrics.SetGauge("app_sql_inUse_connections", float64(stats.InUse), labels...)
 
-			time.Sleep(frequency * time.Second)
+			if !sleep(done, frequency*time.Second) {
+				return
+			}
 		}
 	}
 }
 
+// sleep waits for d, and reports false when done is closed before, i.e. when the connection is closed.
+func sleep(done <-chan struct{}, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// metricLabels returns the labels identifying a named or a tenant connection in the metrics.
+func (c *DBConfig) metricLabels() []string {
+	if c == nil {
+		return nil
+	}
+
+	var labels []string
+
+	if c.Name != "" {
+		labels = append(labels, "name", c.Name)
+	}
+
+	if c.Tenant != "" {
+		labels = append(labels, "tenant", c.Tenant)
+	}
+
+	return labels
+}
+
 func printConnectionSuccessLog(status string, dbconfig *DBConfig, logger datasource.Logger) {
 	logFunc := logger.Infof
 	if status != "connected" {
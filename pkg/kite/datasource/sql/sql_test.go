@@ -260,6 +260,20 @@ func TestSQL_getDBConnectionString(t *testing.T) {
 			},
 			expOut: "host=host port=26257 user=user password=password dbname=test sslmode=require",
 		},
+		{
+			desc: "postgresql dialect with schema",
+			configs: &DBConfig{
+				Dialect:  "postgres",
+				HostName: "host",
+				User:     "user",
+				Password: "password",
+				Port:     "5432",
+				Database: "test",
+				Schema:   "tenant_acme",
+				SSLMode:  "disable",
+			},
+			expOut: "host=host port=5432 user=user password=password dbname=test sslmode=disable search_path=tenant_acme",
+		},
 		{
 			desc:    "unsupported dialect",
 			configs: &DBConfig{Dialect: "mssql"},
@@ -278,6 +292,56 @@ func TestSQL_getDBConnectionString(t *testing.T) {
 	}
 }
 
+func TestDBConfig_metricLabels(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		config   *DBConfig
+		expected []string
+	}{
+		{"default connection", &DBConfig{}, nil},
+		{"named connection", &DBConfig{Name: "orders"}, []string{"name", "orders"}},
+		{"tenant connection", &DBConfig{Tenant: "acme"}, []string{"tenant", "acme"}},
+		{"named tenant connection", &DBConfig{Name: "orders", Tenant: "acme"}, []string{"name", "orders", "tenant", "acme"}},
+	}
+
+	for i, tc := range testCases {
+		assert.Equal(t, tc.expected, tc.config.metricLabels(), "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestNewTenantSQL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockMetrics := NewMockMetrics(ctrl)
+	mockMetrics.EXPECT().SetGauge(gomock.Any(), gomock.Any(), "tenant", "acme").AnyTimes()
+
+	mockConfig := config.NewMockConfig(map[string]string{
+		"DB_DIALECT": "postgres",
+		"DB_HOST":    "127.0.0.1",
+		"DB_PORT":    "3201",
+		"DB_NAME":    "shop",
+		"DB_SCHEMA":  "tenant_acme",
+	})
+
+	db := NewTenantSQL("acme", mockConfig, logging.NewMockLogger(logging.ERROR), mockMetrics)
+	require.NotNil(t, db)
+
+	assert.Equal(t, "acme", db.config.Tenant)
+	assert.Equal(t, "tenant_acme", db.config.Schema)
+	require.NoError(t, db.Close())
+	require.NoError(t, db.Close())
+}
+
+func Test_sleep(t *testing.T) {
+	done := make(chan struct{})
+
+	assert.True(t, sleep(done, time.Millisecond))
+
+	close(done)
+
+	assert.False(t, sleep(done, time.Hour))
+}
+
 func Test_NewSQLMock(t *testing.T) {
 	db, mock, mockMetric := NewSQLMocks(t)
 
@@ -0,0 +1,119 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+var (
+	errInvalidSavepoint = errors.New("invalid savepoint name")
+
+	savepointPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// Beginner starts transactions. It is implemented by DB, by the resolver of the read replicas and by the SQL
+// datasource of the container.
+type Beginner interface {
+	Begin() (*Tx, error)
+}
+
+// Savepoint marks the current state of the transaction, to roll back to it with RollbackTo without aborting the
+// transaction. name must be a plain identifier: letters, digits and underscores.
+func (t *Tx) Savepoint(name string) error {
+	return t.savepointStatement(context.Background(), "SAVEPOINT ", name)
+}
+
+// RollbackTo rolls back the changes made since the savepoint name, which remains set.
+func (t *Tx) RollbackTo(name string) error {
+	return t.savepointStatement(context.Background(), "ROLLBACK TO SAVEPOINT ", name)
+}
+
+// ReleaseSavepoint removes the savepoint name, keeping the changes made since it.
+func (t *Tx) ReleaseSavepoint(name string) error {
+	return t.savepointStatement(context.Background(), "RELEASE SAVEPOINT ", name)
+}
+
+func (t *Tx) savepointStatement(ctx context.Context, statement, name string) error {
+	if !savepointPattern.MatchString(name) {
+		return fmt.Errorf("%w: %q", errInvalidSavepoint, name)
+	}
+
+	_, err := t.ExecContext(ctx, statement+name)
+
+	return err
+}
+
+// WithTransaction runs fn in a transaction, committed when fn returns nil and rolled back when it returns an error or
+// panics. The context passed to fn carries the transaction, see ExecutorFromContext.
+//
+// When ctx already carries a transaction of db, fn runs in it within a savepoint instead of a new transaction: an error
+// of fn rolls back the changes of fn only, and the outer transaction is committed by its own WithTransaction. Service
+// methods can then compose repository methods which start their own transactions, without opening a second
+// connection, which would deadlock with the first one on the locked rows, nor committing twice.
+//
+//	err := sql.WithTransaction(ctx, db, func(ctx context.Context) error {
+//		if err := orders.Create(ctx, order); err != nil { // may call WithTransaction too
+//			return err
+//		}
+//
+//		return stock.Reserve(ctx, order.Items)
+//	})
+func WithTransaction(ctx context.Context, db Beginner, fn func(ctx context.Context) error) (err error) {
+	if tx, ok := transactionOf(ctx, db); ok {
+		return tx.withSavepoint(ctx, fn)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+
+			panic(p)
+		}
+	}()
+
+	if err = fn(WithTx(ctx, tx)); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return errors.Join(err, rollbackErr)
+		}
+
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// withSavepoint runs fn within a new savepoint of the transaction, named after the nesting depth.
+func (t *Tx) withSavepoint(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	name := "kite_savepoint_" + strconv.FormatInt(t.savepoints.Add(1), 10)
+	defer t.savepoints.Add(-1)
+
+	if err = t.savepointStatement(ctx, "SAVEPOINT ", name); err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = t.savepointStatement(ctx, "ROLLBACK TO SAVEPOINT ", name)
+
+			panic(p)
+		}
+	}()
+
+	if err = fn(ctx); err != nil {
+		if rollbackErr := t.savepointStatement(ctx, "ROLLBACK TO SAVEPOINT ", name); rollbackErr != nil {
+			return errors.Join(err, rollbackErr)
+		}
+
+		return err
+	}
+
+	return t.savepointStatement(ctx, "RELEASE SAVEPOINT ", name)
+}
@@ -0,0 +1,158 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sllt/kite/pkg/kite/logging"
+)
+
+var errOutOfStock = errors.New("out of stock")
+
+func TestTx_Savepoint(t *testing.T) {
+	db, mock := getDB(t, logging.DEBUG)
+	defer db.DB.Close()
+
+	tx := getTransaction(db, mock)
+
+	mock.ExpectExec("SAVEPOINT before_items").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT before_items").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("RELEASE SAVEPOINT before_items").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	require.NoError(t, tx.Savepoint("before_items"))
+	require.NoError(t, tx.RollbackTo("before_items"))
+	require.NoError(t, tx.ReleaseSavepoint("before_items"))
+
+	require.ErrorIs(t, tx.Savepoint("x; DROP TABLE users"), errInvalidSavepoint)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithTransaction(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		err    error
+		expect func(mock sqlmock.Sqlmock)
+	}{
+		{desc: "commit", expect: func(mock sqlmock.Sqlmock) { mock.ExpectCommit() }},
+		{desc: "rollback", err: errOutOfStock, expect: func(mock sqlmock.Sqlmock) { mock.ExpectRollback() }},
+	}
+
+	for i, tc := range testCases {
+		db, mock := getDB(t, logging.DEBUG)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE users SET name=? WHERE id=?").WithArgs("kite", 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		tc.expect(mock)
+
+		err := WithTransaction(t.Context(), db, func(ctx context.Context) error {
+			if err := renameUser(ctx, db, "kite", 1); err != nil {
+				return err
+			}
+
+			return tc.err
+		})
+
+		require.ErrorIs(t, err, tc.err, "TEST[%d], Failed.\n%s", i, tc.desc)
+		require.NoError(t, mock.ExpectationsWereMet(), "TEST[%d], Failed.\n%s", i, tc.desc)
+
+		db.DB.Close()
+	}
+}
+
+func TestWithTransaction_Nested(t *testing.T) {
+	db, mock := getDB(t, logging.DEBUG)
+	defer db.DB.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT kite_savepoint_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE users SET name=? WHERE id=?").WithArgs("kite", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("RELEASE SAVEPOINT kite_savepoint_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SAVEPOINT kite_savepoint_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SAVEPOINT kite_savepoint_2").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT kite_savepoint_2").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT kite_savepoint_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err := WithTransaction(t.Context(), db, func(ctx context.Context) error {
+		err := WithTransaction(ctx, db, func(ctx context.Context) error {
+			return renameUser(ctx, db, "kite", 1)
+		})
+		require.NoError(t, err)
+
+		// the failure of the nested calls rolls back to their savepoints and does not abort the transaction.
+		err = WithTransaction(ctx, db, func(ctx context.Context) error {
+			return WithTransaction(ctx, db, func(context.Context) error { return errOutOfStock })
+		})
+		require.ErrorIs(t, err, errOutOfStock)
+
+		return nil
+	})
+
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithTransaction_TwoDBs(t *testing.T) {
+	primary, primaryMock := getDB(t, logging.DEBUG)
+	defer primary.DB.Close()
+
+	analytics, analyticsMock := getDB(t, logging.DEBUG)
+	defer analytics.DB.Close()
+
+	primaryMock.ExpectBegin()
+	primaryMock.ExpectCommit()
+
+	// the transaction of the analytics DB is a transaction of its own, not a savepoint of the primary one.
+	analyticsMock.ExpectBegin()
+	analyticsMock.ExpectExec("UPDATE users SET name=? WHERE id=?").WithArgs("kite", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	analyticsMock.ExpectCommit()
+
+	err := WithTransaction(t.Context(), primary, func(ctx context.Context) error {
+		return WithTransaction(ctx, analytics, func(ctx context.Context) error {
+			return renameUser(ctx, analytics, "kite", 1)
+		})
+	})
+
+	require.NoError(t, err)
+	require.NoError(t, primaryMock.ExpectationsWereMet())
+	require.NoError(t, analyticsMock.ExpectationsWereMet())
+}
+
+func TestWithTransaction_Panic(t *testing.T) {
+	db, mock := getDB(t, logging.DEBUG)
+	defer db.DB.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	assert.PanicsWithValue(t, "boom", func() {
+		_ = WithTransaction(t.Context(), db, func(context.Context) error { panic("boom") })
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithTransaction_BeginError(t *testing.T) {
+	db, mock := getDB(t, logging.DEBUG)
+	defer db.DB.Close()
+
+	mock.ExpectBegin().WillReturnError(errTx)
+
+	called := false
+
+	err := WithTransaction(t.Context(), db, func(context.Context) error {
+		called = true
+
+		return nil
+	})
+
+	require.ErrorIs(t, err, errTx)
+	assert.False(t, called)
+}
@@ -0,0 +1,87 @@
+package kite
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"strings"
+	"time"
+
+	"github.com/sllt/kite/pkg/kite/http/middleware"
+	"github.com/sllt/kite/pkg/kite/metrics"
+)
+
+// debugEnabled reports whether the diagnostics are enabled with METRICS_DEBUG_ENABLED: the pprof, expvar and runtime
+// stats endpoints of the metrics server, and the goroutine dumps on SIGUSR1.
+func (a *App) debugEnabled() bool {
+	return a.Config.GetOrDefault("METRICS_DEBUG_ENABLED", "false") == "true"
+}
+
+// newDebugHandler returns the handler of the diagnostics endpoints, protected by basic authentication with
+// METRICS_DEBUG_USERNAME and METRICS_DEBUG_PASSWORD, or else by the comma-separated METRICS_DEBUG_API_KEYS. It returns
+// nil when no credentials are configured, as the endpoints must not be served without authentication.
+func (a *App) newDebugHandler() http.Handler {
+	username := a.Config.Get("METRICS_DEBUG_USERNAME")
+	password := a.Config.Get("METRICS_DEBUG_PASSWORD")
+
+	var apiKeys []string
+
+	for _, key := range strings.Split(a.Config.Get("METRICS_DEBUG_API_KEYS"), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			apiKeys = append(apiKeys, key)
+		}
+	}
+
+	switch {
+	case username != "" && password != "":
+		return middleware.BasicAuthMiddleware(middleware.BasicAuthProvider{
+			Users: map[string]string{username: password},
+		})(metrics.DebugHandler())
+	case len(apiKeys) > 0:
+		return middleware.APIKeyAuthMiddleware(middleware.APIKeyAuthProvider{}, apiKeys...)(metrics.DebugHandler())
+	default:
+		a.container.Logger.Errorf("debug endpoints are not served, set METRICS_DEBUG_USERNAME and " +
+			"METRICS_DEBUG_PASSWORD, or METRICS_DEBUG_API_KEYS to protect them")
+
+		return nil
+	}
+}
+
+// dumpGoroutines writes the stacks of all the goroutines to a new file of GOROUTINE_DUMP_DIR, which defaults to the
+// temporary directory, and returns its path.
+func (a *App) dumpGoroutines() (string, error) {
+	dir := a.Config.GetOrDefault("GOROUTINE_DUMP_DIR", os.TempDir())
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("goroutines-%d-%s.txt", os.Getpid(),
+		time.Now().UTC().Format("20060102T150405.000000000")))
+
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+
+	err = pprof.Lookup("goroutine").WriteTo(f, 2)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	return path, err
+}
+
+// logGoroutineDump captures a goroutine dump with dumpGoroutines and logs where it was written.
+func (a *App) logGoroutineDump() {
+	path, err := a.dumpGoroutines()
+	if err != nil {
+		a.container.Logger.Errorf("failed to dump goroutines: %v", err)
+
+		return
+	}
+
+	a.container.Logger.Infof("goroutine dump written to %s", path)
+}
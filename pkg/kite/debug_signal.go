@@ -0,0 +1,34 @@
+//go:build !windows
+
+package kite
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// startGoroutineDumpHandler captures a goroutine dump on each SIGUSR1 until ctx is done, when the diagnostics are
+// enabled. It lets operators inspect a stuck application with `kill -USR1 <pid>`, even if its ports are unreachable.
+func (a *App) startGoroutineDumpHandler(ctx context.Context) {
+	if !a.debugEnabled() {
+		return
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+
+	go func() {
+		defer signal.Stop(signals)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signals:
+				a.logGoroutineDump()
+			}
+		}
+	}()
+}
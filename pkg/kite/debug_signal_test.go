@@ -0,0 +1,29 @@
+//go:build !windows
+
+package kite
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApp_GoroutineDumpOnSIGUSR1(t *testing.T) {
+	dir := t.TempDir()
+	app := newDebugApp(map[string]string{"METRICS_DEBUG_ENABLED": "true", "GOROUTINE_DUMP_DIR": dir})
+
+	app.startGoroutineDumpHandler(t.Context())
+
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+
+	assert.Eventually(t, func() bool {
+		dumps, _ := filepath.Glob(filepath.Join(dir, "goroutines-*.txt"))
+
+		return len(dumps) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+}
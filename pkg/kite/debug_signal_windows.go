@@ -0,0 +1,6 @@
+package kite
+
+import "context"
+
+// startGoroutineDumpHandler is a no-op, as there is no SIGUSR1 on Windows.
+func (*App) startGoroutineDumpHandler(context.Context) {}
@@ -0,0 +1,88 @@
+package kite
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sllt/kite/pkg/kite/config"
+	"github.com/sllt/kite/pkg/kite/infra"
+	"github.com/sllt/kite/pkg/kite/testutil"
+)
+
+func newDebugApp(configs map[string]string) *App {
+	cfg := config.NewMockConfig(configs)
+
+	return &App{Config: cfg, container: infra.NewContainer(cfg)}
+}
+
+func debugStatus(t *testing.T, handler http.Handler, setAuth func(*http.Request)) int {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/runtime", http.NoBody)
+	if setAuth != nil {
+		setAuth(req)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	return rec.Code
+}
+
+func TestApp_DebugEnabled(t *testing.T) {
+	assert.False(t, newDebugApp(nil).debugEnabled())
+	assert.True(t, newDebugApp(map[string]string{"METRICS_DEBUG_ENABLED": "true"}).debugEnabled())
+}
+
+func TestApp_NewDebugHandler_BasicAuth(t *testing.T) {
+	handler := newDebugApp(map[string]string{
+		"METRICS_DEBUG_USERNAME": "admin",
+		"METRICS_DEBUG_PASSWORD": "secret",
+	}).newDebugHandler()
+	require.NotNil(t, handler)
+
+	assert.Equal(t, http.StatusUnauthorized, debugStatus(t, handler, nil))
+	assert.Equal(t, http.StatusUnauthorized, debugStatus(t, handler, func(r *http.Request) {
+		r.SetBasicAuth("admin", "wrong")
+	}))
+	assert.Equal(t, http.StatusOK, debugStatus(t, handler, func(r *http.Request) {
+		r.SetBasicAuth("admin", "secret")
+	}))
+}
+
+func TestApp_NewDebugHandler_APIKeys(t *testing.T) {
+	handler := newDebugApp(map[string]string{"METRICS_DEBUG_API_KEYS": "key-1, key-2"}).newDebugHandler()
+	require.NotNil(t, handler)
+
+	assert.Equal(t, http.StatusUnauthorized, debugStatus(t, handler, nil))
+	assert.Equal(t, http.StatusOK, debugStatus(t, handler, func(r *http.Request) {
+		r.Header.Set("X-Api-Key", "key-2")
+	}))
+}
+
+func TestApp_NewDebugHandler_WithoutCredentials(t *testing.T) {
+	logs := testutil.StderrOutputForFunc(func() {
+		assert.Nil(t, newDebugApp(nil).newDebugHandler())
+	})
+
+	assert.Contains(t, logs, "debug endpoints are not served")
+}
+
+func TestApp_DumpGoroutines(t *testing.T) {
+	dir := t.TempDir()
+
+	path, err := newDebugApp(map[string]string{"GOROUTINE_DUMP_DIR": dir}).dumpGoroutines()
+	require.NoError(t, err)
+
+	assert.Equal(t, dir, filepath.Dir(path))
+
+	dump, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(dump), "TestApp_DumpGoroutines")
+}
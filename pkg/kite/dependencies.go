@@ -0,0 +1,290 @@
+package kite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sllt/kite/pkg/kite/datasource"
+	"github.com/sllt/kite/pkg/kite/startup"
+)
+
+const (
+	defaultConnectBackoff     = time.Second
+	defaultConnectMaxBackoff  = 30 * time.Second
+	defaultConnectTimeout     = time.Minute
+	defaultDependenciesWait   = time.Minute
+	dependenciesCheckInterval = time.Second
+)
+
+var errDatasourceDown = errors.New("datasource is down")
+
+// connectDatasource connects the datasource added with an Add* method, retrying with the policy read from the configs
+// when it is not healthy afterward. A required datasource which is still down stops the application. The datasources
+// connect concurrently when the application runs if DATASOURCE_CONNECT_PARALLEL is true, see runStartup.
+func (a *App) connectDatasource(name string, provider interface{ Connect() }) {
+	if a.parallelConnect() {
+		if a.startupDatasources == nil {
+			a.startupDatasources = make(map[string]bool)
+		}
+
+		a.startupDatasources[name] = true
+
+		a.startupSteps.Add(startup.Step{
+			Name:    name,
+			Timeout: a.connectTimeout(name),
+			Run: func(ctx context.Context) error {
+				return a.connect(ctx, name, provider)
+			},
+		})
+
+		return
+	}
+
+	a.connectFailed(name, a.connect(context.Background(), name, provider))
+}
+
+// connect connects the provider with the retry policy of the datasource, returning its error once it is still down. A
+// provider which can be closed is closed before connecting it again, the others are only checked again.
+func (a *App) connect(ctx context.Context, name string, provider interface{ Connect() }) error {
+	policy := a.connectPolicy(name)
+
+	healthy := healthCheckOf(provider)
+	if healthy == nil || (policy.MaxAttempts <= 1 && !policy.FailFast) {
+		provider.Connect()
+
+		return nil
+	}
+
+	var closeClient func() error
+	if closer, ok := provider.(io.Closer); ok {
+		closeClient = closer.Close
+	}
+
+	return policy.Connect(ctx, provider.Connect, healthy, closeClient)
+}
+
+// connectFailed stops the application if the datasource which failed to connect is required, and logs the error
+// otherwise.
+func (a *App) connectFailed(name string, err error) {
+	if err == nil {
+		return
+	}
+
+	policy := a.connectPolicy(name)
+
+	if policy.FailFast {
+		a.Logger().Fatalf("required datasource %s is down after %d connection attempts: %v", name,
+			max(policy.MaxAttempts, 1), err)
+
+		return
+	}
+
+	a.Logger().Errorf("datasource %s is down after %d connection attempts, starting without it: %v", name,
+		max(policy.MaxAttempts, 1), err)
+}
+
+// parallelConnect reports whether the datasources connect concurrently when the application runs, set with
+// DATASOURCE_CONNECT_PARALLEL.
+func (a *App) parallelConnect() bool {
+	parallel, err := strconv.ParseBool(a.Config.GetOrDefault("DATASOURCE_CONNECT_PARALLEL", "false"))
+
+	return err == nil && parallel
+}
+
+// connectTimeout returns the timeout of the concurrent connection of the datasource, read from
+// DATASOURCE_CONNECT_TIMEOUTS, e.g. "cassandra=2m,mongo=10s", or else from DATASOURCE_CONNECT_TIMEOUT.
+func (a *App) connectTimeout(name string) time.Duration {
+	for _, entry := range a.configList("DATASOURCE_CONNECT_TIMEOUTS") {
+		datasourceName, value, ok := strings.Cut(entry, "=")
+		if !ok || !strings.EqualFold(strings.TrimSpace(datasourceName), name) {
+			continue
+		}
+
+		if timeout, err := time.ParseDuration(strings.TrimSpace(value)); err == nil && timeout > 0 {
+			return timeout
+		}
+	}
+
+	timeout, err := time.ParseDuration(a.Config.GetOrDefault("DATASOURCE_CONNECT_TIMEOUT",
+		defaultConnectTimeout.String()))
+	if err != nil || timeout <= 0 {
+		return defaultConnectTimeout
+	}
+
+	return timeout
+}
+
+// AddStartupStep adds a step run before the application serves, concurrently with the other steps and with the
+// connections of the datasources when DATASOURCE_CONNECT_PARALLEL is true. The step starts once the steps and the
+// datasources named in dependsOn are up, e.g. to warm up a cache once "redis" is connected, and the application does
+// not start if it fails.
+func (a *App) AddStartupStep(name string, step func(ctx context.Context) error, dependsOn ...string) {
+	a.startupSteps.Add(startup.Step{Name: name, DependsOn: dependsOn, Run: step})
+}
+
+// runStartup runs the startup steps added since it last ran, and logs their report. It stops the application if a
+// required datasource is down, and returns the errors of the steps added with AddStartupStep.
+func (a *App) runStartup(ctx context.Context) error {
+	report := a.startupSteps.Run(ctx)
+	if report == nil {
+		return a.startupErr
+	}
+
+	a.Logger().Info(report.String())
+
+	for _, result := range report.Results {
+		if result.Err == nil {
+			continue
+		}
+
+		if a.startupDatasources[result.Name] {
+			a.connectFailed(result.Name, result.Err)
+
+			continue
+		}
+
+		a.startupErr = errors.Join(a.startupErr, fmt.Errorf("startup step %s failed: %w", result.Name, result.Err))
+	}
+
+	return a.startupErr
+}
+
+// connectPolicy reads the connection retry policy of the datasource from DATASOURCE_CONNECT_ATTEMPTS,
+// DATASOURCE_CONNECT_BACKOFF, DATASOURCE_CONNECT_MAX_BACKOFF and DATASOURCE_REQUIRED.
+func (a *App) connectPolicy(name string) datasource.RetryPolicy {
+	policy := datasource.RetryPolicy{
+		MaxAttempts:    1,
+		InitialBackoff: defaultConnectBackoff,
+		MaxBackoff:     defaultConnectMaxBackoff,
+		FailFast:       a.requiredDatasource(name),
+	}
+
+	if attempts, err := strconv.Atoi(a.Config.Get("DATASOURCE_CONNECT_ATTEMPTS")); err == nil && attempts > 0 {
+		policy.MaxAttempts = attempts
+	}
+
+	if backoff, err := time.ParseDuration(a.Config.Get("DATASOURCE_CONNECT_BACKOFF")); err == nil && backoff > 0 {
+		policy.InitialBackoff = backoff
+	}
+
+	maxBackoff, err := time.ParseDuration(a.Config.Get("DATASOURCE_CONNECT_MAX_BACKOFF"))
+	if err == nil && maxBackoff > 0 {
+		policy.MaxBackoff = maxBackoff
+	}
+
+	return policy
+}
+
+// requiredDatasources returns the datasources listed in DATASOURCE_REQUIRED, named as in the health check, e.g.
+// "sql,redis,mongo".
+func (a *App) requiredDatasources() []string {
+	return a.configList("DATASOURCE_REQUIRED")
+}
+
+// configList returns the comma-separated values of the config, ignoring the empty ones.
+func (a *App) configList(key string) []string {
+	var values []string
+
+	for _, value := range strings.Split(a.Config.Get(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+func (a *App) requiredDatasource(name string) bool {
+	return slices.ContainsFunc(a.requiredDatasources(), func(required string) bool {
+		return strings.EqualFold(required, name)
+	})
+}
+
+// healthCheckOf returns the health check of the provider, or nil if it has none.
+func healthCheckOf(provider any) func(context.Context) error {
+	switch p := provider.(type) {
+	case interface {
+		HealthCheck(ctx context.Context) (any, error)
+	}:
+		return func(ctx context.Context) error {
+			_, err := p.HealthCheck(ctx)
+
+			return err
+		}
+	case interface{ Health() datasource.Health }:
+		return func(context.Context) error {
+			if p.Health().Status == datasource.StatusDown {
+				return errDatasourceDown
+			}
+
+			return nil
+		}
+	default:
+		return nil
+	}
+}
+
+// waitForDependencies delays serving until the required datasources, or all of them if none is required, are up
+// when WAIT_FOR_DEPENDENCIES is true. It fails once WAIT_FOR_DEPENDENCIES_TIMEOUT has elapsed.
+func (a *App) waitForDependencies(ctx context.Context) error {
+	if wait, err := strconv.ParseBool(a.Config.GetOrDefault("WAIT_FOR_DEPENDENCIES", "false")); err != nil || !wait {
+		return nil
+	}
+
+	timeout, err := time.ParseDuration(a.Config.GetOrDefault("WAIT_FOR_DEPENDENCIES_TIMEOUT",
+		defaultDependenciesWait.String()))
+	if err != nil || timeout <= 0 {
+		timeout = defaultDependenciesWait
+	}
+
+	names := a.requiredDatasources()
+	if len(names) == 0 {
+		names = a.container.Datasources()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(dependenciesCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		down := pendingDependencies(a.container.DependenciesUp(ctx), names)
+		if len(down) == 0 {
+			return nil
+		}
+
+		a.Logger().Infof("waiting for the dependencies %s to be up", strings.Join(down, ", "))
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("dependencies %s are still down: %w", strings.Join(down, ", "), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// pendingDependencies returns the names which are not up yet, compared ignoring case. Unlike downDependencies, the
+// datasources which are not configured are pending, as a required datasource may be added later.
+func pendingDependencies(up map[string]bool, names []string) []string {
+	upNames := make(map[string]bool, len(up))
+	for name, isUp := range up {
+		upNames[strings.ToLower(name)] = isUp
+	}
+
+	var down []string
+
+	for _, name := range names {
+		if !upNames[strings.ToLower(name)] {
+			down = append(down, name)
+		}
+	}
+
+	return down
+}
@@ -0,0 +1,188 @@
+package kite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/sllt/kite/pkg/kite/config"
+	"github.com/sllt/kite/pkg/kite/infra"
+	"github.com/sllt/kite/pkg/kite/logging"
+)
+
+var errMongoStarting = errors.New("mongo is starting")
+
+func newDependenciesApp(configs map[string]string) *App {
+	return &App{
+		Config:    config.NewMockConfig(configs),
+		container: &infra.Container{Logger: logging.NewMockLogger(logging.DEBUG)},
+	}
+}
+
+func TestApp_connectDatasource_Retries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	app := newDependenciesApp(map[string]string{"DATASOURCE_CONNECT_ATTEMPTS": "3", "DATASOURCE_CONNECT_BACKOFF": "1ms"})
+
+	mock := infra.NewMockMongoProvider(ctrl)
+
+	gomock.InOrder(
+		mock.EXPECT().Connect(),
+		mock.EXPECT().HealthCheck(gomock.Any()).Return(nil, errMongoStarting),
+		mock.EXPECT().HealthCheck(gomock.Any()).Return("UP", nil),
+	)
+
+	app.connectDatasource("mongo", mock)
+}
+
+// closableMongo is a mongo provider which can be closed.
+type closableMongo struct {
+	*infra.MockMongoProvider
+
+	closed int
+}
+
+func (m *closableMongo) Close() error {
+	m.closed++
+
+	return nil
+}
+
+func TestApp_connectDatasource_ClosesBeforeRetrying(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	app := newDependenciesApp(map[string]string{"DATASOURCE_CONNECT_ATTEMPTS": "3", "DATASOURCE_CONNECT_BACKOFF": "1ms"})
+
+	mock := &closableMongo{MockMongoProvider: infra.NewMockMongoProvider(ctrl)}
+
+	gomock.InOrder(
+		mock.EXPECT().Connect(),
+		mock.EXPECT().HealthCheck(gomock.Any()).Return(nil, errMongoStarting),
+		mock.EXPECT().Connect(),
+		mock.EXPECT().HealthCheck(gomock.Any()).Return("UP", nil),
+	)
+
+	app.connectDatasource("mongo", mock)
+
+	assert.Equal(t, 1, mock.closed)
+}
+
+func TestApp_connectDatasource_NoRetryByDefault(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	app := newDependenciesApp(map[string]string{})
+
+	mock := infra.NewMockMongoProvider(ctrl)
+	mock.EXPECT().Connect()
+
+	app.connectDatasource("mongo", mock)
+}
+
+func TestApp_connectDatasource_Parallel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	app := newDependenciesApp(map[string]string{"DATASOURCE_CONNECT_PARALLEL": "true"})
+
+	mock := infra.NewMockMongoProvider(ctrl)
+
+	app.connectDatasource("mongo", mock)
+
+	var cacheWarmed bool
+
+	app.AddStartupStep("cache", func(context.Context) error {
+		cacheWarmed = true
+
+		return nil
+	}, "mongo")
+
+	mock.EXPECT().Connect()
+
+	require.NoError(t, app.runStartup(t.Context()))
+	assert.True(t, cacheWarmed)
+	assert.Zero(t, app.startupSteps.Len())
+}
+
+func TestApp_runStartup_StepFailure(t *testing.T) {
+	app := newDependenciesApp(map[string]string{})
+
+	app.AddStartupStep("cache", func(context.Context) error { return errMongoStarting })
+
+	err := app.runStartup(t.Context())
+
+	require.ErrorIs(t, err, errMongoStarting)
+	assert.Contains(t, err.Error(), "cache")
+	require.ErrorIs(t, app.runStartup(t.Context()), errMongoStarting, "the failure stops the next runs")
+}
+
+func TestApp_connectTimeout(t *testing.T) {
+	app := newDependenciesApp(map[string]string{
+		"DATASOURCE_CONNECT_TIMEOUT":  "20s",
+		"DATASOURCE_CONNECT_TIMEOUTS": "cassandra=2m, Mongo=5s,redis=invalid",
+	})
+
+	assert.Equal(t, 2*time.Minute, app.connectTimeout("cassandra"))
+	assert.Equal(t, 5*time.Second, app.connectTimeout("mongo"))
+	assert.Equal(t, 20*time.Second, app.connectTimeout("redis"))
+	assert.Equal(t, defaultConnectTimeout, newDependenciesApp(map[string]string{}).connectTimeout("sql"))
+}
+
+func TestApp_connectPolicy(t *testing.T) {
+	app := newDependenciesApp(map[string]string{
+		"DATASOURCE_CONNECT_ATTEMPTS":    "5",
+		"DATASOURCE_CONNECT_BACKOFF":     "2s",
+		"DATASOURCE_CONNECT_MAX_BACKOFF": "invalid",
+		"DATASOURCE_REQUIRED":            "sql, Mongo",
+	})
+
+	policy := app.connectPolicy("mongo")
+
+	assert.Equal(t, 5, policy.MaxAttempts)
+	assert.Equal(t, "2s", policy.InitialBackoff.String())
+	assert.Equal(t, defaultConnectMaxBackoff, policy.MaxBackoff)
+	assert.True(t, policy.FailFast)
+	assert.False(t, app.connectPolicy("redis").FailFast)
+}
+
+func TestApp_waitForDependencies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mock := infra.NewMockMongoProvider(ctrl)
+	mock.EXPECT().HealthCheck(gomock.Any()).Return(nil, errMongoStarting)
+	mock.EXPECT().HealthCheck(gomock.Any()).Return("UP", nil)
+
+	app := newDependenciesApp(map[string]string{"WAIT_FOR_DEPENDENCIES": "true", "DATASOURCE_REQUIRED": "mongo"})
+	app.container.Mongo = mock
+
+	require.NoError(t, app.waitForDependencies(t.Context()))
+}
+
+func TestApp_waitForDependencies_Timeout(t *testing.T) {
+	app := newDependenciesApp(map[string]string{
+		"WAIT_FOR_DEPENDENCIES":         "true",
+		"WAIT_FOR_DEPENDENCIES_TIMEOUT": "10ms",
+		"DATASOURCE_REQUIRED":           "mongo",
+	})
+
+	err := app.waitForDependencies(t.Context())
+
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "mongo")
+}
+
+func TestApp_waitForDependencies_Disabled(t *testing.T) {
+	app := newDependenciesApp(map[string]string{"DATASOURCE_REQUIRED": "mongo"})
+
+	assert.NoError(t, app.waitForDependencies(t.Context()))
+}
+
+func TestPendingDependencies(t *testing.T) {
+	up := map[string]bool{"Mongo": true, "sql": false}
+
+	assert.Equal(t, []string{"sql", "redis"}, pendingDependencies(up, []string{"mongo", "sql", "redis"}))
+	assert.Empty(t, pendingDependencies(up, []string{"MONGO"}))
+}
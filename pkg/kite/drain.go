@@ -0,0 +1,99 @@
+package kite
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/sllt/kite/pkg/kite/config"
+	"github.com/sllt/kite/pkg/kite/logging"
+)
+
+// errDraining is returned by the health endpoint while the application drains before shutting down.
+type errDraining struct{}
+
+func (errDraining) Error() string {
+	return "application is shutting down"
+}
+
+func (errDraining) StatusCode() int {
+	return http.StatusServiceUnavailable
+}
+
+// LogLevel is INFO as the failing readiness probes are expected during the drain.
+func (errDraining) LogLevel() logging.Level {
+	return logging.INFO
+}
+
+// getReadinessDrainDelay reads READINESS_DRAIN_DELAY, in seconds or as a duration, e.g. "10" or "10s". An empty or
+// invalid value disables the drain.
+func getReadinessDrainDelay(logger logging.Logger, cfg config.Config) time.Duration {
+	value := cfg.Get("READINESS_DRAIN_DELAY")
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	delay, err := time.ParseDuration(value)
+	if err != nil || delay < 0 {
+		logger.Errorf("invalid READINESS_DRAIN_DELAY %q, shutting down without draining", value)
+
+		return 0
+	}
+
+	return delay
+}
+
+// drain fails the readiness of the application, its health endpoint and the gRPC health status of its services,
+// while it keeps serving for the delay, so that the load balancers stop routing requests to it before the servers
+// shut down. It returns early when ctx is done, e.g. on a second signal.
+func (a *App) drain(ctx context.Context, delay time.Duration) {
+	if delay <= 0 {
+		return
+	}
+
+	a.draining.Store(true)
+
+	if a.grpcHealth != nil {
+		a.grpcHealth.drain()
+	}
+
+	a.Logger().Infof("Failing readiness and serving for %v before shutting down", delay)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		a.Logger().Info("Drain interrupted, shutting down now")
+	case <-timer.C:
+	}
+}
+
+// readinessHandler reports the health of the application, and fails with 503 Service Unavailable while it drains.
+func (a *App) readinessHandler(c *Context) (any, error) {
+	if a.draining.Load() {
+		return nil, errDraining{}
+	}
+
+	return healthHandler(c)
+}
+
+// drain sets every service NOT_SERVING for good, the health of the dependencies no longer matters.
+func (m *grpcHealthMonitor) drain() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.draining = true
+
+	for _, name := range m.serviceNames() {
+		m.services[name].serving = false
+		m.server.SetServingStatus(name, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+}
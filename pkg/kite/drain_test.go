@@ -0,0 +1,101 @@
+package kite
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/sllt/kite/pkg/kite/config"
+	kiteHTTP "github.com/sllt/kite/pkg/kite/http"
+	"github.com/sllt/kite/pkg/kite/infra"
+	"github.com/sllt/kite/pkg/kite/logging"
+)
+
+func TestGetReadinessDrainDelay(t *testing.T) {
+	testCases := []struct {
+		value string
+		delay time.Duration
+	}{
+		{"", 0},
+		{"10", 10 * time.Second},
+		{"1500ms", 1500 * time.Millisecond},
+		{"-5", 0},
+		{"invalid", 0},
+	}
+
+	for i, tc := range testCases {
+		cfg := config.NewMockConfig(map[string]string{"READINESS_DRAIN_DELAY": tc.value})
+
+		delay := getReadinessDrainDelay(logging.NewMockLogger(logging.FATAL), cfg)
+
+		assert.Equal(t, tc.delay, delay, "TEST[%d], Failed.\n%s", i, tc.value)
+	}
+}
+
+func TestApp_Drain(t *testing.T) {
+	app := &App{
+		container: infra.NewContainer(config.NewMockConfig(nil)),
+		Config:    config.NewMockConfig(nil),
+	}
+
+	server := &fakeGRPCHealthServer{statuses: make(map[string]healthpb.HealthCheckResponse_ServingStatus)}
+	app.MonitorGRPCHealth(server, "Orders")
+
+	start := time.Now()
+	app.drain(t.Context(), 50*time.Millisecond)
+
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond, "the application should keep serving")
+	assert.True(t, app.draining.Load())
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, server.statuses["Orders"])
+
+	// the health of the dependencies no longer changes the serving status.
+	app.grpcHealth.update(map[string]bool{})
+
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, server.statuses["Orders"])
+}
+
+func TestApp_Drain_Interrupted(t *testing.T) {
+	app := &App{container: infra.NewContainer(config.NewMockConfig(nil))}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	start := time.Now()
+	app.drain(ctx, time.Minute)
+
+	assert.Less(t, time.Since(start), time.Second)
+	assert.True(t, app.draining.Load())
+}
+
+func TestApp_Drain_Disabled(t *testing.T) {
+	app := &App{container: infra.NewContainer(config.NewMockConfig(nil))}
+
+	app.drain(t.Context(), 0)
+
+	assert.False(t, app.draining.Load())
+}
+
+func TestApp_ReadinessHandler(t *testing.T) {
+	app := &App{container: infra.NewContainer(config.NewMockConfig(nil))}
+
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, "/.well-known/health", http.NoBody)
+	ctx := newContext(nil, kiteHTTP.NewRequest(req), app.container)
+
+	resp, err := app.readinessHandler(ctx)
+
+	require.NoError(t, err)
+	assert.NotNil(t, resp)
+
+	app.draining.Store(true)
+
+	resp, err = app.readinessHandler(ctx)
+
+	require.ErrorIs(t, err, errDraining{})
+	assert.Nil(t, resp)
+	assert.Equal(t, http.StatusServiceUnavailable, errDraining{}.StatusCode())
+}
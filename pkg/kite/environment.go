@@ -0,0 +1,54 @@
+package kite
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/sllt/kite/pkg/kite/config"
+	kiteHTTP "github.com/sllt/kite/pkg/kite/http"
+	"github.com/sllt/kite/pkg/kite/http/response"
+)
+
+// Environment returns the environment of the application, configured with APP_ENV, e.g. config.EnvProduction. It
+// selects the presets of the configurations, see config.Environment.
+func (a *App) Environment() config.Environment {
+	return config.GetEnvironment(a.Config)
+}
+
+// IsDev reports whether the application runs in development, with APP_ENV set to "dev", "development" or "local".
+func (a *App) IsDev() bool {
+	return a.Environment() == config.EnvDevelopment
+}
+
+// IsProduction reports whether the application runs in production, with APP_ENV set to "prod" or "production".
+func (a *App) IsProduction() bool {
+	return a.Environment() == config.EnvProduction
+}
+
+// httpErrorMode returns the level of detail of the errors responded by the handlers, set by HTTP_ERROR_MODE and
+// preset by the environment.
+func (a *App) httpErrorMode() kiteHTTP.ErrorMode {
+	switch mode := kiteHTTP.ErrorMode(strings.ToLower(config.GetOrPreset(a.Config, "HTTP_ERROR_MODE", ""))); mode {
+	case kiteHTTP.ErrorModeDefault, kiteHTTP.ErrorModeVerbose, kiteHTTP.ErrorModeStrict:
+		return mode
+	default:
+		a.container.Errorf("invalid value %q for HTTP_ERROR_MODE, use %q or %q", mode, kiteHTTP.ErrorModeVerbose,
+			kiteHTTP.ErrorModeStrict)
+
+		return kiteHTTP.ErrorModeDefault
+	}
+}
+
+// setTemplateReload sets whether the templates are parsed on every render from TEMPLATE_RELOAD, preset by the
+// environment.
+func (a *App) setTemplateReload() {
+	reload, err := strconv.ParseBool(config.GetOrPreset(a.Config, "TEMPLATE_RELOAD", "true"))
+	if err != nil {
+		a.container.Errorf("invalid value %q for TEMPLATE_RELOAD, the templates are reloaded",
+			a.Config.Get("TEMPLATE_RELOAD"))
+
+		reload = true
+	}
+
+	response.SetTemplateReload(reload)
+}
@@ -0,0 +1,329 @@
+// Package errors provides the standard error taxonomy of kite applications.
+//
+// Errors are created with a constructor per kind, optionally carrying a business code and metadata:
+//
+//	var ErrUserNotFound = errors.NotFound("user not found").WithCode(10404)
+//
+//	func (s *Service) Get(ctx *kite.Context) (any, error) {
+//		user, err := s.repo.Find(ctx, id)
+//		if err != nil {
+//			return nil, errors.Wrap(err, errors.KindInternal, "failed to load user")
+//		}
+//		if user == nil {
+//			return nil, ErrUserNotFound.WithMeta("id", id)
+//		}
+//		...
+//	}
+//
+// The kind of an error sets both the HTTP status code of the response and the gRPC status code, so that HTTP and
+// gRPC handlers report the same failure the same way. Error returns the message only, the wrapped error is not
+// sent to the client but can be inspected with errors.Unwrap, errors.Is and errors.As.
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	kiteHTTP "github.com/sllt/kite/pkg/kite/http"
+	"github.com/sllt/kite/pkg/kite/logging"
+)
+
+// Kind classifies an error.
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindInvalid
+	KindNotFound
+	KindConflict
+	KindUnauthorized
+	KindForbidden
+	KindUnavailable
+	KindTooManyRequests
+)
+
+// kindInfo describes how a kind is reported over HTTP, over gRPC and in the logs.
+type kindInfo struct {
+	name       string
+	httpStatus int
+	grpcCode   codes.Code
+	logLevel   logging.Level
+}
+
+var kinds = map[Kind]kindInfo{
+	KindInternal:        {"internal", http.StatusInternalServerError, codes.Internal, logging.ERROR},
+	KindInvalid:         {"invalid", http.StatusBadRequest, codes.InvalidArgument, logging.INFO},
+	KindNotFound:        {"not_found", http.StatusNotFound, codes.NotFound, logging.INFO},
+	KindConflict:        {"conflict", http.StatusConflict, codes.AlreadyExists, logging.WARN},
+	KindUnauthorized:    {"unauthorized", http.StatusUnauthorized, codes.Unauthenticated, logging.WARN},
+	KindForbidden:       {"forbidden", http.StatusForbidden, codes.PermissionDenied, logging.WARN},
+	KindUnavailable:     {"unavailable", http.StatusServiceUnavailable, codes.Unavailable, logging.ERROR},
+	KindTooManyRequests: {"too_many_requests", http.StatusTooManyRequests, codes.ResourceExhausted, logging.WARN},
+}
+
+func (k Kind) info() kindInfo {
+	if info, ok := kinds[k]; ok {
+		return info
+	}
+
+	return kinds[KindInternal]
+}
+
+// String returns the name of the kind, e.g. "not_found".
+func (k Kind) String() string {
+	return k.info().name
+}
+
+// Error is an error of a given kind. Its methods return copies, so that errors declared as package variables
+// can be used as templates.
+type Error struct {
+	kind    Kind
+	message string
+	code    int
+	meta    map[string]any
+	cause   error
+	// retryAfter is sent in the Retry-After header of HTTP responses.
+	retryAfter time.Duration
+}
+
+// New returns an error of the given kind.
+func New(kind Kind, message string) *Error {
+	return &Error{kind: kind, message: message}
+}
+
+// Newf returns an error of the given kind with a formatted message.
+func Newf(kind Kind, format string, args ...any) *Error {
+	return New(kind, fmt.Sprintf(format, args...))
+}
+
+// Wrap returns an error of the given kind caused by err. err must not be nil.
+func Wrap(err error, kind Kind, message string) *Error {
+	return &Error{kind: kind, message: message, cause: err}
+}
+
+// Internal returns an error for unexpected failures, reported as 500 Internal Server Error.
+func Internal(message string) *Error {
+	return New(KindInternal, message)
+}
+
+// Invalid returns an error for invalid input, reported as 400 Bad Request.
+func Invalid(message string) *Error {
+	return New(KindInvalid, message)
+}
+
+// NotFound returns an error for missing entities, reported as 404 Not Found.
+func NotFound(message string) *Error {
+	return New(KindNotFound, message)
+}
+
+// Conflict returns an error for conflicting writes, e.g. duplicates, reported as 409 Conflict.
+func Conflict(message string) *Error {
+	return New(KindConflict, message)
+}
+
+// Unauthorized returns an error for unauthenticated requests, reported as 401 Unauthorized.
+func Unauthorized(message string) *Error {
+	return New(KindUnauthorized, message)
+}
+
+// Forbidden returns an error for requests the caller is not allowed to make, reported as 403 Forbidden.
+func Forbidden(message string) *Error {
+	return New(KindForbidden, message)
+}
+
+// Unavailable returns an error for failing dependencies, reported as 503 Service Unavailable.
+func Unavailable(message string) *Error {
+	return New(KindUnavailable, message)
+}
+
+// TooManyRequests returns an error for callers exceeding their rate limit or quota, reported as 429 Too Many
+// Requests. It is usually returned with a delay, see WithRetryAfter.
+func TooManyRequests(message string) *Error {
+	return New(KindTooManyRequests, message)
+}
+
+// WithCode returns a copy of the error with the business code sent in the "code" field of HTTP responses.
+func (e *Error) WithCode(code int) *Error {
+	c := e.clone()
+	c.code = code
+
+	return c
+}
+
+// WithMeta returns a copy of the error with key set in its metadata, sent in the "meta" field of HTTP responses.
+func (e *Error) WithMeta(key string, value any) *Error {
+	c := e.clone()
+	c.meta[key] = value
+
+	return c
+}
+
+// WithRetryAfter returns a copy of the error telling the clients to retry after d, e.g. of an Unavailable or a
+// TooManyRequests error. It is sent in the Retry-After header of HTTP responses, rounded up to the second, and in an
+// errdetails.RetryInfo of gRPC statuses.
+func (e *Error) WithRetryAfter(d time.Duration) *Error {
+	c := e.clone()
+	c.retryAfter = d
+
+	return c
+}
+
+// Wrap returns a copy of the error caused by err.
+func (e *Error) Wrap(err error) *Error {
+	c := e.clone()
+	c.cause = err
+
+	return c
+}
+
+func (e *Error) clone() *Error {
+	c := *e
+	c.meta = maps.Clone(e.meta)
+
+	if c.meta == nil {
+		c.meta = make(map[string]any)
+	}
+
+	return &c
+}
+
+// Error returns the message of the error, without its cause.
+func (e *Error) Error() string {
+	return e.message
+}
+
+// Unwrap returns the cause of the error.
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is reports whether target is an *Error with the same kind, code and message, so that the copies of an error
+// declared as a package variable match it.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+
+	return e.kind == t.kind && e.code == t.code && e.message == t.message
+}
+
+// Kind returns the kind of the error.
+func (e *Error) Kind() Kind {
+	return e.kind
+}
+
+// Meta returns the metadata of the error.
+func (e *Error) Meta() map[string]any {
+	return maps.Clone(e.meta)
+}
+
+// RetryAfter returns the delay after which the request can be retried, 0 if none was set.
+func (e *Error) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+// StatusCode returns the HTTP status code of the kind of the error.
+func (e *Error) StatusCode() int {
+	return e.kind.info().httpStatus
+}
+
+// Code returns the business code of the error, or its HTTP status code if it has none.
+func (e *Error) Code() int {
+	if e.code != 0 {
+		return e.code
+	}
+
+	return e.StatusCode()
+}
+
+// LogLevel returns the level at which the error is logged: client errors are not logged as errors.
+func (e *Error) LogLevel() logging.Level {
+	return e.kind.info().logLevel
+}
+
+// Response returns the metadata of the error, sent in the "meta" field of HTTP responses.
+func (e *Error) Response() map[string]any {
+	if len(e.meta) == 0 {
+		return nil
+	}
+
+	return e.Meta()
+}
+
+// GRPCStatus returns the gRPC status of the error. It is used by the gRPC server, and status.FromError,
+// to convert the errors returned by the handlers.
+func (e *Error) GRPCStatus() *status.Status {
+	return status.New(e.kind.info().grpcCode, e.message)
+}
+
+// KindOf returns the kind of the first *Error in the chain of err, and KindInternal if there is none.
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.kind
+	}
+
+	return KindInternal
+}
+
+// grpcKinds maps the gRPC codes without a kind of their own to the closest kind.
+var grpcKinds = map[codes.Code]Kind{
+	codes.FailedPrecondition: KindInvalid,
+	codes.OutOfRange:         KindInvalid,
+	codes.Aborted:            KindConflict,
+	codes.DeadlineExceeded:   KindUnavailable,
+}
+
+// FromGRPC converts an error returned by a gRPC client into an *Error of the kind matching its status code, with
+// the status message as message and err as cause, so that it is reported by the calling handler like any other
+// kite error. Context errors are converted like the matching gRPC status. It returns nil for a nil error, and err
+// unchanged if it already is an *Error.
+func FromGRPC(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var e *Error
+	if errors.As(err, &e) {
+		return err
+	}
+
+	// context errors, e.g. of a cancelled stream, are not gRPC statuses yet.
+	st, ok := status.FromError(err)
+	if !ok {
+		st = status.FromContextError(err)
+	}
+
+	kind, ok := grpcKinds[st.Code()]
+	if !ok {
+		kind = KindInternal
+
+		for k, info := range kinds {
+			if info.grpcCode == st.Code() {
+				kind = k
+				break
+			}
+		}
+	}
+
+	return Wrap(err, kind, st.Message())
+}
+
+// validate the errors satisfy the interfaces used by the HTTP responder, the logger and the gRPC server.
+var (
+	_ kiteHTTP.StatusCodeResponder = (*Error)(nil)
+	_ kiteHTTP.CodeResponder       = (*Error)(nil)
+	_ kiteHTTP.ResponseMarshaller  = (*Error)(nil)
+	_ kiteHTTP.RetryAfterResponder = (*Error)(nil)
+	_ logging.LogLevelResponder    = (*Error)(nil)
+	_ interface {
+		GRPCStatus() *status.Status
+	} = (*Error)(nil)
+)
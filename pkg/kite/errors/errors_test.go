@@ -0,0 +1,143 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	kiteHTTP "github.com/sllt/kite/pkg/kite/http"
+	"github.com/sllt/kite/pkg/kite/logging"
+)
+
+var errDB = errors.New("connection refused")
+
+func TestKinds(t *testing.T) {
+	tests := []struct {
+		err        *Error
+		kind       string
+		httpStatus int
+		grpcCode   codes.Code
+		logLevel   logging.Level
+	}{
+		{Internal("boom"), "internal", http.StatusInternalServerError, codes.Internal, logging.ERROR},
+		{Invalid("bad id"), "invalid", http.StatusBadRequest, codes.InvalidArgument, logging.INFO},
+		{NotFound("no user"), "not_found", http.StatusNotFound, codes.NotFound, logging.INFO},
+		{Conflict("duplicate"), "conflict", http.StatusConflict, codes.AlreadyExists, logging.WARN},
+		{Unauthorized("login"), "unauthorized", http.StatusUnauthorized, codes.Unauthenticated, logging.WARN},
+		{Forbidden("denied"), "forbidden", http.StatusForbidden, codes.PermissionDenied, logging.WARN},
+		{Unavailable("down"), "unavailable", http.StatusServiceUnavailable, codes.Unavailable, logging.ERROR},
+		{TooManyRequests("slow down"), "too_many_requests", http.StatusTooManyRequests, codes.ResourceExhausted,
+			logging.WARN},
+		{New(Kind(42), "unknown"), "internal", http.StatusInternalServerError, codes.Internal, logging.ERROR},
+	}
+
+	for i, tc := range tests {
+		assert.Equal(t, tc.kind, tc.err.Kind().String(), "TEST[%d], Failed.\n", i)
+		assert.Equal(t, tc.httpStatus, tc.err.StatusCode(), "TEST[%d], Failed.\n", i)
+		assert.Equal(t, tc.httpStatus, tc.err.Code(), "TEST[%d], Failed.\n", i)
+		assert.Equal(t, tc.logLevel, logging.GetLogLevelForError(tc.err), "TEST[%d], Failed.\n", i)
+
+		st, ok := status.FromError(tc.err)
+		assert.True(t, ok, "TEST[%d], Failed.\n", i)
+		assert.Equal(t, tc.grpcCode, st.Code(), "TEST[%d], Failed.\n", i)
+		assert.Equal(t, tc.err.Error(), st.Message(), "TEST[%d], Failed.\n", i)
+		assert.Equal(t, tc.grpcCode, status.Code(fmt.Errorf("handler: %w", tc.err)), "TEST[%d], Failed.\n", i)
+	}
+}
+
+func TestError_Templates(t *testing.T) {
+	errUserNotFound := NotFound("user not found").WithCode(10404)
+
+	err := errUserNotFound.WithMeta("id", 7)
+
+	assert.ErrorIs(t, err, errUserNotFound)
+	assert.NotErrorIs(t, err, NotFound("user not found"))
+	assert.Equal(t, 10404, err.Code())
+	assert.Equal(t, map[string]any{"id": 7}, err.Meta())
+	assert.Empty(t, errUserNotFound.Meta(), "the template must not be modified")
+}
+
+func TestError_Wrap(t *testing.T) {
+	err := Wrap(errDB, KindUnavailable, "failed to load user")
+
+	assert.Equal(t, "failed to load user", err.Error())
+	assert.ErrorIs(t, err, errDB)
+	assert.Equal(t, KindUnavailable, KindOf(fmt.Errorf("service: %w", err)))
+	assert.Equal(t, KindInternal, KindOf(errDB))
+
+	errLoad := Internal("failed to load user")
+	wrapped := errLoad.Wrap(errDB)
+
+	assert.ErrorIs(t, wrapped, errLoad)
+	assert.ErrorIs(t, wrapped, errDB)
+	assert.NoError(t, errLoad.Unwrap())
+}
+
+func TestError_HTTPResponse(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	kiteHTTP.NewResponder(w, http.MethodGet).Respond(nil,
+		NotFound("user not found").WithCode(10404).WithMeta("id", "7"))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"code":10404,"data":null,"message":"user not found","meta":{"id":"7"}}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+
+	kiteHTTP.NewResponder(w, http.MethodGet).Respond(nil, Wrap(errDB, KindInternal, "failed to load user"))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.JSONEq(t, `{"code":500,"data":null,"message":"failed to load user"}`, w.Body.String())
+	assert.Empty(t, w.Header().Get("Retry-After"))
+}
+
+func TestError_RetryAfter(t *testing.T) {
+	errQuota := TooManyRequests("quota exceeded")
+
+	err := errQuota.WithRetryAfter(1500 * time.Millisecond)
+
+	assert.Equal(t, 1500*time.Millisecond, err.RetryAfter())
+	assert.Zero(t, errQuota.RetryAfter(), "the template must not be modified")
+	assert.ErrorIs(t, err, errQuota)
+
+	w := httptest.NewRecorder()
+
+	kiteHTTP.NewResponder(w, http.MethodGet).Respond(nil, err)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+}
+
+func TestFromGRPC(t *testing.T) {
+	tests := []struct {
+		err  error
+		kind Kind
+	}{
+		{status.Error(codes.NotFound, "no user"), KindNotFound},
+		{status.Error(codes.PermissionDenied, "denied"), KindForbidden},
+		{status.Error(codes.DeadlineExceeded, "deadline exceeded"), KindUnavailable},
+		{status.Error(codes.FailedPrecondition, "not ready"), KindInvalid},
+		{status.Error(codes.ResourceExhausted, "quota exceeded"), KindTooManyRequests},
+		{status.Error(codes.Unknown, "boom"), KindInternal},
+	}
+
+	for i, tc := range tests {
+		err := FromGRPC(tc.err)
+
+		assert.Equal(t, tc.kind, KindOf(err), "TEST[%d], Failed.\n", i)
+		assert.Equal(t, status.Convert(tc.err).Message(), err.Error(), "TEST[%d], Failed.\n", i)
+		assert.ErrorIs(t, err, tc.err, "TEST[%d], Failed.\n", i)
+	}
+
+	errNotFound := NotFound("no user")
+
+	assert.Same(t, errNotFound, FromGRPC(errNotFound))
+	assert.NoError(t, FromGRPC(nil))
+}
@@ -0,0 +1,200 @@
+package kite
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sllt/kite/pkg/kite/datasource/pubsub"
+	kiteHTTP "github.com/sllt/kite/pkg/kite/http"
+	"github.com/sllt/kite/pkg/kite/infra/events"
+)
+
+// eventOriginField is the field added to the events forwarded by ForwardEvents, holding the origin of the
+// application instance which forwarded them, so that ReceiveEvents skips the events the instance forwarded itself.
+const eventOriginField = "kite_origin"
+
+var (
+	errNilEvent                = errors.New("event cannot be nil")
+	errPublisherNotInitialized = errors.New("publisher not initialized in the container")
+)
+
+// receivedEventKey marks the context of the events received by ReceiveEvents, which ForwardEvents does not forward
+// again, so that the replicas of an application forwarding and receiving the same topic do not loop.
+type receivedEventKey struct{}
+
+// Events returns the in-process event bus of the application, on which its modules publish typed events and
+// subscribe to them:
+//
+//	app.Events().Subscribe(func(ctx context.Context, e UserCreated) error {
+//		return sendWelcomeMail(ctx, e.Email)
+//	}, events.Async())
+//
+// The asynchronous handlers still running are waited for on shutdown, before the datasources are closed.
+func (a *App) Events() *events.Bus {
+	return a.container.Events()
+}
+
+// WaitForEvent parks the request until an event is delivered on topic of the event bus of the application and
+// returns its payload. Events are delivered by other requests with ctx.Events().Notify, or ctx.Events().Publish
+// for the events named topic (see events.Name), or from a pubsub topic bridged with App.BridgeEvents.
+//
+// When the timeout elapses first, it returns http.ErrorNoEvent, which is answered with 204 No Content, so that
+// long-poll endpoints are written as:
+//
+//	app.GET("/orders/updates", func(ctx *kite.Context) (any, error) {
+//		return ctx.WaitForEvent("orders", 30*time.Second)
+//	})
+//
+// The timeout must be shorter than REQUEST_TIMEOUT, if set, for the request not to time out first.
+func (c *Context) WaitForEvent(topic string, timeout time.Duration) (any, error) {
+	e, err := c.Events().Wait(c.Context, topic, timeout)
+	if errors.Is(err, events.ErrTimeout) {
+		return nil, kiteHTTP.ErrorNoEvent{}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return e.Payload, nil
+}
+
+// BridgeEvents subscribes to the pubsub topic and delivers its messages on the event bus of the application, under
+// the same topic, so that the requests waiting with ctx.WaitForEvent on every instance of the application get them.
+// The payload of the events is the value of the message, as a json.RawMessage if it is valid JSON, else as a string.
+//
+// It returns the subscription, nil if the subscriber is not initialized in the container.
+func (a *App) BridgeEvents(topic string) *Subscription {
+	return a.Subscribe(topic, func(ctx *Context) error {
+		msg, ok := ctx.Request.(*pubsub.Message)
+		if !ok {
+			return nil
+		}
+
+		ctx.Events().Notify(topic, eventPayload(msg.Value))
+
+		return nil
+	})
+}
+
+// ForwardEvents publishes the events of the type of event, published on the event bus of the application, on the
+// pubsub topic, marshaled to JSON, so that the other services get them, e.g. with App.ReceiveEvents. The events
+// are forwarded asynchronously, the failures are logged.
+//
+// The events marshaled to JSON objects get a "kite_origin" field identifying the instance of the application, so
+// that it skips them if it receives topic too. The events received with App.ReceiveEvents are not forwarded again.
+func (a *App) ForwardEvents(event any, topic string) error {
+	if event == nil {
+		return errNilEvent
+	}
+
+	publisher := a.container.GetPublisher()
+	if publisher == nil {
+		return errPublisherNotInitialized
+	}
+
+	eventType := reflect.TypeOf(event)
+	origin := a.eventOriginID()
+
+	_, err := a.container.Events().Subscribe(func(ctx context.Context, e any) error {
+		if reflect.TypeOf(e) != eventType || ctx.Value(receivedEventKey{}) != nil {
+			return nil
+		}
+
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+
+		return publisher.Publish(ctx, topic, withEventOrigin(data, origin))
+	}, events.Async())
+
+	return err
+}
+
+// ReceiveEvents subscribes to the pubsub topic and publishes its messages, bound to the type of event, on the event
+// bus of the application, e.g. the events forwarded by another service with App.ForwardEvents. A message is not
+// committed when it cannot be bound or when a synchronous handler of the event fails, so that it is redelivered.
+//
+// The events forwarded to topic by the instance itself are skipped, as they were already dispatched on its bus.
+//
+// It returns the subscription, nil if event is nil or the subscriber is not initialized in the container.
+func (a *App) ReceiveEvents(topic string, event any) *Subscription {
+	if event == nil {
+		a.container.Logger.Errorf("invalid subscription to events of topic %s: %v", topic, errNilEvent)
+
+		return nil
+	}
+
+	eventType := reflect.TypeOf(event)
+	origin := a.eventOriginID()
+
+	return a.Subscribe(topic, func(ctx *Context) error {
+		if msg, ok := ctx.Request.(*pubsub.Message); ok && eventOrigin(msg.Value) == origin {
+			return nil
+		}
+
+		e := reflect.New(eventType)
+
+		if err := ctx.Bind(e.Interface()); err != nil {
+			return err
+		}
+
+		return ctx.Events().Publish(context.WithValue(ctx, receivedEventKey{}, true), e.Elem().Interface())
+	})
+}
+
+// eventOriginID returns the origin identifying the instance of the application in the events it forwards.
+func (a *App) eventOriginID() string {
+	a.eventOriginOnce.Do(func() {
+		a.eventOrigin = uuid.NewString()
+	})
+
+	return a.eventOrigin
+}
+
+// withEventOrigin adds the origin field to the event marshaled in data, if it is a JSON object.
+func withEventOrigin(data []byte, origin string) []byte {
+	if len(data) < 2 || data[0] != '{' {
+		return data
+	}
+
+	field, _ := json.Marshal(map[string]string{eventOriginField: origin})
+
+	if string(data) == "{}" {
+		return field
+	}
+
+	// field is {"kite_origin":"..."}, the fields of the event follow it.
+	out := make([]byte, 0, len(field)+len(data))
+	out = append(out, field[:len(field)-1]...)
+	out = append(out, ',')
+
+	return append(out, data[1:]...)
+}
+
+// eventOrigin returns the origin of a forwarded event, empty if it was not forwarded by ForwardEvents.
+func eventOrigin(value []byte) string {
+	var forwarded struct {
+		Origin string `json:"kite_origin"`
+	}
+
+	if json.Unmarshal(value, &forwarded) != nil {
+		return ""
+	}
+
+	return forwarded.Origin
+}
+
+func eventPayload(value []byte) any {
+	if json.Valid(value) {
+		return json.RawMessage(value)
+	}
+
+	return string(value)
+}
@@ -0,0 +1,221 @@
+package kite
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sllt/kite/pkg/kite/datasource/pubsub"
+	kiteHTTP "github.com/sllt/kite/pkg/kite/http"
+	"github.com/sllt/kite/pkg/kite/infra"
+	"github.com/sllt/kite/pkg/kite/logging"
+)
+
+func TestContext_WaitForEvent(t *testing.T) {
+	ctx := &Context{Context: t.Context(), Container: &infra.Container{Logger: logging.NewMockLogger(logging.ERROR)}}
+
+	go func() {
+		for ctx.Events().Waiting("orders") == 0 {
+			time.Sleep(time.Millisecond)
+		}
+
+		ctx.Events().Notify("orders", "order-1")
+	}()
+
+	payload, err := ctx.WaitForEvent("orders", 5*time.Second)
+
+	require.NoError(t, err)
+	assert.Equal(t, "order-1", payload)
+}
+
+func TestContext_WaitForEvent_Timeout(t *testing.T) {
+	ctx := &Context{Context: t.Context(), Container: &infra.Container{Logger: logging.NewMockLogger(logging.ERROR)}}
+
+	payload, err := ctx.WaitForEvent("orders", 10*time.Millisecond)
+
+	assert.Nil(t, payload)
+	require.ErrorIs(t, err, kiteHTTP.ErrorNoEvent{})
+}
+
+func TestApp_BridgeEvents(t *testing.T) {
+	container := &infra.Container{Logger: logging.NewMockLogger(logging.ERROR), PubSub: mockSubscriber{}}
+	app := &App{container: container, subscriptionManager: newSubscriptionManager(nil)}
+
+	sub := app.BridgeEvents("orders")
+	require.NotNil(t, sub)
+
+	testCases := []struct {
+		value   []byte
+		payload any
+	}{
+		{[]byte(`{"id":"order-1"}`), json.RawMessage(`{"id":"order-1"}`)},
+		{[]byte("order-2"), "order-2"},
+	}
+
+	for i, tc := range testCases {
+		received := make(chan any, 1)
+
+		go func() {
+			payload, _ := (&Context{Context: t.Context(), Container: container}).WaitForEvent("orders", 5*time.Second)
+			received <- payload
+		}()
+
+		for container.Events().Waiting("orders") == 0 {
+			time.Sleep(time.Millisecond)
+		}
+
+		msg := pubsub.NewMessage(t.Context())
+		msg.Value = tc.value
+
+		require.NoError(t, sub.handler(&Context{Context: t.Context(), Request: msg, Container: container}), "TEST[%d]", i)
+		assert.Equal(t, tc.payload, <-received, "TEST[%d]", i)
+	}
+}
+
+type userCreated struct {
+	ID string `json:"id"`
+}
+
+type publishedMessage struct {
+	topic string
+	value string
+}
+
+// recordingPublisher records the messages published on it.
+type recordingPublisher struct {
+	mockSubscriber
+
+	published chan publishedMessage
+}
+
+func (p recordingPublisher) Publish(_ context.Context, topic string, message []byte) error {
+	p.published <- publishedMessage{topic: topic, value: string(message)}
+
+	return nil
+}
+
+func TestApp_Events(t *testing.T) {
+	container := &infra.Container{Logger: logging.NewMockLogger(logging.ERROR)}
+	app := &App{container: container}
+
+	received := make(chan userCreated, 1)
+
+	_, err := app.Events().Subscribe(func(e userCreated) { received <- e })
+	require.NoError(t, err)
+
+	ctx := &Context{Context: t.Context(), Container: container}
+
+	require.NoError(t, ctx.Events().Publish(ctx, userCreated{ID: "u-1"}))
+	assert.Equal(t, userCreated{ID: "u-1"}, <-received)
+}
+
+func TestApp_ForwardEvents(t *testing.T) {
+	publisher := recordingPublisher{published: make(chan publishedMessage, 1)}
+	app := &App{container: &infra.Container{Logger: logging.NewMockLogger(logging.ERROR), PubSub: publisher}}
+
+	require.NoError(t, app.ForwardEvents(userCreated{}, "users"))
+	require.ErrorIs(t, app.ForwardEvents(nil, "users"), errNilEvent)
+
+	require.NoError(t, app.Events().Publish(t.Context(), "not forwarded"))
+	require.NoError(t, app.Events().Publish(t.Context(), userCreated{ID: "u-1"}))
+
+	assert.Equal(t, publishedMessage{topic: "users", value: `{"kite_origin":"` + app.eventOriginID() + `","id":"u-1"}`},
+		<-publisher.published)
+
+	// the events received from pubsub are not forwarded again.
+	received := context.WithValue(t.Context(), receivedEventKey{}, true)
+	require.NoError(t, app.Events().Publish(received, userCreated{ID: "u-2"}))
+
+	require.NoError(t, app.Events().Close(t.Context()))
+	assert.Empty(t, publisher.published)
+}
+
+func TestWithEventOrigin(t *testing.T) {
+	tests := []struct {
+		desc     string
+		data     string
+		expected string
+	}{
+		{"object", `{"id":"u-1"}`, `{"kite_origin":"o-1","id":"u-1"}`},
+		{"empty object", `{}`, `{"kite_origin":"o-1"}`},
+		{"not an object", `"u-1"`, `"u-1"`},
+	}
+
+	for i, tc := range tests {
+		data := withEventOrigin([]byte(tc.data), "o-1")
+
+		assert.Equal(t, tc.expected, string(data), "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, tc.expected != tc.data, eventOrigin(data) == "o-1", "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestApp_ForwardEvents_NoPublisher(t *testing.T) {
+	app := &App{container: &infra.Container{Logger: logging.NewMockLogger(logging.ERROR)}}
+
+	require.ErrorIs(t, app.ForwardEvents(userCreated{}, "users"), errPublisherNotInitialized)
+}
+
+func TestApp_ReceiveEvents(t *testing.T) {
+	container := &infra.Container{Logger: logging.NewMockLogger(logging.ERROR), PubSub: mockSubscriber{}}
+	app := &App{container: container, subscriptionManager: newSubscriptionManager(nil)}
+
+	var (
+		mu       sync.Mutex
+		received []userCreated
+	)
+
+	_, err := app.Events().Subscribe(func(e userCreated) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		received = append(received, e)
+	})
+	require.NoError(t, err)
+
+	sub := app.ReceiveEvents("users", userCreated{})
+	require.NotNil(t, sub)
+	assert.Nil(t, app.ReceiveEvents("users", nil))
+
+	msg := pubsub.NewMessage(t.Context())
+	msg.Value = []byte(`{"id":"u-1"}`)
+
+	require.NoError(t, sub.handler(&Context{Context: t.Context(), Request: msg, Container: container}))
+
+	// the events forwarded by the instance itself are skipped, the ones of other instances are dispatched.
+	msg.Value = []byte(`{"kite_origin":"` + app.eventOriginID() + `","id":"u-2"}`)
+
+	require.NoError(t, sub.handler(&Context{Context: t.Context(), Request: msg, Container: container}))
+
+	msg.Value = []byte(`{"kite_origin":"another-instance","id":"u-3"}`)
+
+	require.NoError(t, sub.handler(&Context{Context: t.Context(), Request: msg, Container: container}))
+
+	msg.Value = []byte(`not json`)
+
+	require.Error(t, sub.handler(&Context{Context: t.Context(), Request: msg, Container: container}))
+	assert.Equal(t, []userCreated{{ID: "u-1"}, {ID: "u-3"}}, received)
+}
+
+func TestApp_ForwardAndReceiveEvents_NoLoop(t *testing.T) {
+	publisher := recordingPublisher{published: make(chan publishedMessage, 1)}
+	container := &infra.Container{Logger: logging.NewMockLogger(logging.ERROR), PubSub: publisher}
+	app := &App{container: container, subscriptionManager: newSubscriptionManager(nil)}
+
+	require.NoError(t, app.ForwardEvents(userCreated{}, "users"))
+
+	sub := app.ReceiveEvents("users", userCreated{})
+	require.NotNil(t, sub)
+
+	// an event forwarded by another replica of the application is dispatched, but not forwarded back.
+	msg := pubsub.NewMessage(t.Context())
+	msg.Value = []byte(`{"kite_origin":"another-replica","id":"u-1"}`)
+
+	require.NoError(t, sub.handler(&Context{Context: t.Context(), Request: msg, Container: container}))
+	require.NoError(t, app.Events().Close(t.Context()))
+	assert.Empty(t, publisher.published)
+}
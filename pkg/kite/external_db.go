@@ -3,8 +3,10 @@ package kite
 import (
 	"go.opentelemetry.io/otel"
 
-	"github.com/sllt/kite/pkg/kite/infra"
+	"github.com/sllt/kite/pkg/kite/config"
 	"github.com/sllt/kite/pkg/kite/datasource/file"
+	"github.com/sllt/kite/pkg/kite/datasource/sql"
+	"github.com/sllt/kite/pkg/kite/infra"
 )
 
 // AddMongo sets the Mongo datasource in the app's infra.
@@ -16,7 +18,7 @@ func (a *App) AddMongo(db infra.MongoProvider) {
 
 	db.UseTracer(tracer)
 
-	db.Connect()
+	a.connectDatasource("mongo", db)
 
 	a.container.Mongo = db
 }
@@ -27,7 +29,7 @@ func (a *App) AddFTP(fs file.FileSystemProvider) {
 	fs.UseLogger(a.Logger())
 	fs.UseMetrics(a.Metrics())
 
-	fs.Connect()
+	a.connectDatasource("file", fs)
 
 	a.container.File = fs
 }
@@ -37,7 +39,7 @@ func (a *App) AddPubSub(pubsub infra.PubSubProvider) {
 	pubsub.UseLogger(a.Logger())
 	pubsub.UseMetrics(a.Metrics())
 
-	pubsub.Connect()
+	a.connectDatasource("pubsub", pubsub)
 
 	a.container.PubSub = pubsub
 }
@@ -47,7 +49,7 @@ func (a *App) AddFileStore(fs file.FileSystemProvider) {
 	fs.UseLogger(a.Logger())
 	fs.UseMetrics(a.Metrics())
 
-	fs.Connect()
+	a.connectDatasource("file", fs)
 
 	a.container.File = fs
 }
@@ -62,7 +64,7 @@ func (a *App) AddClickhouse(db infra.ClickhouseProvider) {
 
 	db.UseTracer(tracer)
 
-	db.Connect()
+	a.connectDatasource("clickHouse", db)
 
 	a.container.Clickhouse = db
 }
@@ -77,7 +79,7 @@ func (a *App) AddOracle(db infra.OracleProvider) {
 
 	db.UseTracer(tracer)
 
-	db.Connect()
+	a.connectDatasource("oracle", db)
 
 	a.container.Oracle = db
 }
@@ -97,7 +99,7 @@ func (a *App) AddCassandra(db infra.CassandraProvider) {
 
 	db.UseTracer(tracer)
 
-	db.Connect()
+	a.connectDatasource("cassandra", db)
 
 	a.container.Cassandra = db
 }
@@ -111,7 +113,7 @@ func (a *App) AddKVStore(db infra.KVStoreProvider) {
 
 	db.UseTracer(tracer)
 
-	db.Connect()
+	a.connectDatasource("kv-store", db)
 
 	a.container.KVStore = db
 }
@@ -125,7 +127,7 @@ func (a *App) AddSolr(db infra.SolrProvider) {
 
 	db.UseTracer(tracer)
 
-	db.Connect()
+	a.connectDatasource("solr", db)
 
 	a.container.Solr = db
 }
@@ -140,7 +142,7 @@ func (a *App) AddDgraph(db infra.DgraphProvider) {
 
 	db.UseTracer(tracer)
 
-	db.Connect()
+	a.connectDatasource("dgraph", db)
 
 	a.container.DGraph = db
 }
@@ -155,7 +157,7 @@ func (a *App) AddOpenTSDB(db infra.OpenTSDBProvider) {
 
 	db.UseTracer(tracer)
 
-	db.Connect()
+	a.connectDatasource("opentsdb", db)
 
 	a.container.OpenTSDB = db
 }
@@ -168,7 +170,7 @@ func (a *App) AddScyllaDB(db infra.ScyllaDBProvider) {
 
 	tracer := otel.GetTracerProvider().Tracer("kite-scylladb")
 	db.UseTracer(tracer)
-	db.Connect()
+	a.connectDatasource("scylladb", db)
 	a.container.ScyllaDB = db
 }
 
@@ -183,7 +185,7 @@ func (a *App) AddArangoDB(db infra.ArangoDBProvider) {
 	db.UseTracer(tracer)
 
 	// Connect to ArangoDB
-	db.Connect()
+	a.connectDatasource("arangodb", db)
 
 	// Add the ArangoDB provider to the container
 	a.container.ArangoDB = db
@@ -195,7 +197,7 @@ func (a *App) AddSurrealDB(db infra.SurrealBDProvider) {
 
 	tracer := otel.GetTracerProvider().Tracer("kite-surrealdb")
 	db.UseTracer(tracer)
-	db.Connect()
+	a.connectDatasource("surrealdb", db)
 	a.container.SurrealDB = db
 }
 
@@ -205,7 +207,7 @@ func (a *App) AddElasticsearch(db infra.ElasticsearchProvider) {
 
 	tracer := otel.GetTracerProvider().Tracer("kite-elasticsearch")
 	db.UseTracer(tracer)
-	db.Connect()
+	a.connectDatasource("elasticsearch", db)
 
 	a.container.Elasticsearch = db
 }
@@ -216,7 +218,7 @@ func (a *App) AddCouchbase(db infra.CouchbaseProvider) {
 
 	tracer := otel.GetTracerProvider().Tracer("kite-couchbase")
 	db.UseTracer(tracer)
-	db.Connect()
+	a.connectDatasource("couchbase", db)
 
 	a.container.Couchbase = db
 }
@@ -235,7 +237,7 @@ func (a *App) AddDBResolver(resolver infra.DBResolverProvider) {
 	tracer := otel.GetTracerProvider().Tracer("kite-dbresolver")
 	resolver.UseTracer(tracer)
 
-	resolver.Connect()
+	a.connectDatasource("sql", resolver)
 
 	// Replace the SQL connection with the resolver
 	a.container.SQL = resolver.GetResolver()
@@ -243,13 +245,34 @@ func (a *App) AddDBResolver(resolver infra.DBResolverProvider) {
 	a.Logger().Logf("DB Resolver initialized successfully")
 }
 
+// AddSQL adds an SQL connection identified by name, for applications using more than one database.
+// The connection has its own pool, is configured with the same DB_* keys as the default connection and
+// is available in handlers as ctx.NamedSQL(name). config.WithPrefix can be used to read its configs
+// from the app configs:
+//
+//	app.AddSQL("analytics", config.WithPrefix(app.Config, "ANALYTICS_")) // ANALYTICS_DB_HOST, ANALYTICS_DB_NAME...
+func (a *App) AddSQL(name string, conf config.Config) {
+	if name == "" {
+		a.Logger().Errorf("SQL connection name must not be empty")
+		return
+	}
+
+	db := sql.NewNamedSQL(name, conf, a.Logger(), a.Metrics())
+	if db == nil {
+		a.Logger().Errorf("SQL connection %q is not configured, DB_DIALECT is empty", name)
+		return
+	}
+
+	a.container.AddNamedSQL(name, db)
+}
+
 func (a *App) AddInfluxDB(db infra.InfluxDBProvider) {
 	db.UseLogger(a.Logger())
 	db.UseMetrics(a.Metrics())
 
 	tracer := otel.GetTracerProvider().Tracer("kite-influxdb")
 	db.UseTracer(tracer)
-	db.Connect()
+	a.connectDatasource("influx", db)
 
 	a.container.InfluxDB = db
 }
@@ -1,15 +1,20 @@
 package kite
 
 import (
+	"path/filepath"
 	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel"
 	"go.uber.org/mock/gomock"
 
-	"github.com/sllt/kite/pkg/kite/infra"
+	"github.com/sllt/kite/pkg/kite/config"
 	"github.com/sllt/kite/pkg/kite/datasource/file"
+	"github.com/sllt/kite/pkg/kite/infra"
+	"github.com/sllt/kite/pkg/kite/logging"
+	"github.com/sllt/kite/pkg/kite/migration"
 	"github.com/sllt/kite/pkg/kite/testutil"
 )
 
@@ -250,3 +255,52 @@ func TestApp_AddArangoDB(t *testing.T) {
 		assert.Equal(t, mock, app.container.ArangoDB)
 	})
 }
+
+func TestApp_AddSQL(t *testing.T) {
+	testutil.NewServerConfigs(t)
+
+	app := New()
+
+	app.AddSQL("analytics", config.NewMockConfig(map[string]string{
+		"DB_DIALECT": "sqlite",
+		"DB_NAME":    filepath.Join(t.TempDir(), "analytics.db"),
+	}))
+
+	db := app.container.NamedSQL("analytics")
+	require.NotNil(t, db)
+	assert.Equal(t, "sqlite", db.Dialect())
+
+	app.MigrateSQL("analytics", map[int64]migration.Migrate{
+		1: {UP: func(d migration.Datasource) error {
+			_, err := d.SQL.Exec("CREATE TABLE events (id INTEGER PRIMARY KEY)")
+			return err
+		}},
+	})
+
+	var version int64
+
+	require.NoError(t, db.QueryRow("SELECT MAX(version) FROM kite_migrations").Scan(&version))
+	assert.Equal(t, int64(1), version)
+
+	_, err := db.Exec("INSERT INTO events (id) VALUES (1)")
+	require.NoError(t, err)
+
+	require.NoError(t, db.Close())
+}
+
+func TestApp_AddSQL_NotConfigured(t *testing.T) {
+	testutil.NewServerConfigs(t)
+
+	app := New()
+
+	logs := testutil.StderrOutputForFunc(func() {
+		app.container.Logger = logging.NewMockLogger(logging.ERROR)
+
+		app.AddSQL("analytics", config.NewMockConfig(nil))
+		app.AddSQL("", config.NewMockConfig(nil))
+	})
+
+	assert.Nil(t, app.container.NamedSQL("analytics"))
+	assert.Contains(t, logs, `SQL connection "analytics" is not configured`)
+	assert.Contains(t, logs, "SQL connection name must not be empty")
+}
@@ -8,7 +8,6 @@ import (
 	"github.com/sllt/kite/pkg/kite/cmd/terminal"
 	"github.com/sllt/kite/pkg/kite/infra"
 	"github.com/sllt/kite/pkg/kite/http/middleware"
-	"github.com/sllt/kite/pkg/kite/logging"
 )
 
 // New creates an HTTP Server Application and returns that App.
@@ -31,6 +30,11 @@ func New() *App {
 	app.httpServer.keyFile = app.Config.GetOrDefault("KEY_FILE", "")
 	app.httpServer.staticFiles = make(map[string]string)
 
+	activated := activatedListeners(app.container.Logger)
+	app.httpServer.bind = newServerListener(app.Config, "HTTP", activated[activatedHTTP], app.container.Logger)
+
+	app.initAdminServer()
+
 	// Note: Default routes (health, alive, favicon, swagger) are registered in httpServerSetup()
 	// only when HTTP server actually starts. This prevents gRPC-only apps from starting HTTP server.
 
@@ -45,6 +49,8 @@ func New() *App {
 	// Continue without gRPC server rather than failing the entire app
 	if err != nil {
 		app.container.Logger.Errorf("failed to create gRPC server: %v", err)
+	} else {
+		app.grpcServer.bind = newServerListener(app.Config, "GRPC", activated[activatedGRPC], app.container.Logger)
 	}
 
 	app.subscriptionManager = newSubscriptionManager(app.container)
@@ -65,7 +71,7 @@ func NewCMD() *App {
 	app := &App{}
 	app.readConfig(true)
 	app.container = infra.NewContainer(nil)
-	app.container.Logger = logging.NewFileLogger(app.Config.Get("CMD_LOGS_FILE"))
+	app.container.Logger = newCMDLogger(app.Config, os.Args[1:])
 
 	app.cmd = &cmd{
 		out: terminal.New(),
@@ -97,4 +103,8 @@ func (a *App) initMetricsServer() {
 	}
 
 	a.metricServer = newMetricServer(port)
+
+	if a.debugEnabled() {
+		a.metricServer.debug = a.newDebugHandler()
+	}
 }
@@ -4,18 +4,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"net"
 	"reflect"
-	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	grpc_recovery "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/recovery"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 
 	"github.com/sllt/kite/pkg/kite/config"
-	"github.com/sllt/kite/pkg/kite/infra"
 	kite_grpc "github.com/sllt/kite/pkg/kite/grpc"
+	"github.com/sllt/kite/pkg/kite/infra"
+	"github.com/sllt/kite/pkg/kite/logging"
 )
 
 type pendingService struct {
@@ -29,9 +30,16 @@ type grpcServer struct {
 	streamInterceptors []grpc.StreamServerInterceptor
 	options            []grpc.ServerOption
 	port               int
+	bind               serverListener
 	config             config.Config
 	serverCreated      bool
 	pendingServices    []pendingService
+	logger             logging.Logger
+	// drainTimeout bounds how long Shutdown waits for in-flight RPCs before closing them.
+	// Zero means waiting until the shutdown context is done.
+	drainTimeout time.Duration
+	// inFlight counts the unary calls and streams currently being handled.
+	inFlight atomic.Int64
 }
 
 var (
@@ -115,22 +123,72 @@ func newGRPCServer(c *infra.Container, port int, cfg config.Config) (*grpcServer
 
 	registerGRPCMetrics(c)
 
-	middleware := make([]grpc.UnaryServerInterceptor, 0)
-	middleware = append(middleware,
+	g := &grpcServer{
+		port:         port,
+		config:       cfg,
+		logger:       c.Logger,
+		drainTimeout: getGRPCDrainTimeout(c.Logger, cfg),
+	}
+
+	g.interceptors = append(g.interceptors,
+		g.unaryInFlightInterceptor,
 		grpc_recovery.UnaryServerInterceptor(),
-		kite_grpc.ObservabilityInterceptor(c.Logger, c.Metrics()))
+		kite_grpc.ObservabilityInterceptor(c.Logger, c.Metrics()),
+		kite_grpc.DeadlineBudgetInterceptor(getDeadlineOverhead(cfg), c.Logger),
+		kite_grpc.ErrorInterceptor())
 
-	streamMiddleware := make([]grpc.StreamServerInterceptor, 0)
-	streamMiddleware = append(streamMiddleware,
+	g.streamInterceptors = append(g.streamInterceptors,
+		g.streamInFlightInterceptor,
 		grpc_recovery.StreamServerInterceptor(),
-		kite_grpc.StreamObservabilityInterceptor(c.Logger, c.Metrics()))
-
-	return &grpcServer{
-		port:               port,
-		interceptors:       middleware,
-		streamInterceptors: streamMiddleware,
-		config:             cfg,
-	}, nil
+		kite_grpc.StreamObservabilityInterceptor(c.Logger, c.Metrics()),
+		kite_grpc.StreamErrorInterceptor())
+
+	return g, nil
+}
+
+// getGRPCDrainTimeout reads GRPC_DRAIN_TIMEOUT. An empty or invalid value disables the drain timeout,
+// in which case the shutdown grace period alone bounds the drain.
+func getGRPCDrainTimeout(logger logging.Logger, cfg config.Config) time.Duration {
+	value := cfg.Get("GRPC_DRAIN_TIMEOUT")
+	if value == "" {
+		return 0
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		logger.Errorf("invalid GRPC_DRAIN_TIMEOUT %q, waiting for the shutdown grace period instead", value)
+
+		return 0
+	}
+
+	return timeout
+}
+
+// getDeadlineOverhead reads REQUEST_DEADLINE_OVERHEAD, the time subtracted from the deadline of the callers to leave
+// time for the responses to travel back. An empty or invalid value disables it.
+func getDeadlineOverhead(cfg config.Config) time.Duration {
+	overhead, err := time.ParseDuration(cfg.Get("REQUEST_DEADLINE_OVERHEAD"))
+	if err != nil || overhead < 0 {
+		return 0
+	}
+
+	return overhead
+}
+
+func (g *grpcServer) unaryInFlightInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (any, error) {
+	g.inFlight.Add(1)
+	defer g.inFlight.Add(-1)
+
+	return handler(ctx, req)
+}
+
+func (g *grpcServer) streamInFlightInterceptor(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+	g.inFlight.Add(1)
+	defer g.inFlight.Add(-1)
+
+	return handler(srv, ss)
 }
 
 // registerGRPCMetrics registers essential gRPC metrics.
@@ -138,6 +196,18 @@ func registerGRPCMetrics(c *infra.Container) {
 	c.Metrics().NewGauge("grpc_server_status", "gRPC server status (1=running, 0=stopped)")
 	c.Metrics().NewCounter("grpc_server_errors_total", "Total gRPC server errors")
 	c.Metrics().NewCounter("grpc_services_registered_total", "Total gRPC services registered")
+
+	durationBuckets := []float64{0.5, 1, 2.5, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+	sizeBuckets := []float64{64, 256, 1024, 4096, 16384, 65536, 262144, 1048576, 4194304}
+
+	c.Metrics().NewCounter("grpc_server_requests_total", "Total gRPC requests handled, by service, method, type and status code")
+	c.Metrics().NewHistogram("grpc_server_request_duration_ms", "Response time of gRPC requests in milliseconds", durationBuckets...)
+	c.Metrics().NewHistogram("grpc_server_msg_received_bytes", "Size of gRPC messages received in bytes", sizeBuckets...)
+	c.Metrics().NewHistogram("grpc_server_msg_sent_bytes", "Size of gRPC messages sent in bytes", sizeBuckets...)
+	c.Metrics().NewUpDownCounter("grpc_server_active_streams", "Number of gRPC streams currently open")
+	c.Metrics().NewHistogram("grpc_server_stream_send_lag", "Time the messages of the gRPC streams wait to be sent in milliseconds", durationBuckets...)
+	c.Metrics().NewCounter("grpc_server_stream_slow_consumers_total", "Number of gRPC streams whose consumer lags behind")
+	c.Metrics().NewCounter("grpc_server_streams_cut_total", "Number of gRPC streams cut because their consumer lags too far behind")
 }
 
 func (g *grpcServer) createServer() error {
@@ -189,7 +259,7 @@ func (g *grpcServer) Run(c *infra.Container) {
 		g.pendingServices = nil
 	}
 
-	if !isPortAvailable(g.port) {
+	if g.bind.usesPort() && !isPortAvailable(g.port) {
 		c.Logger.Fatalf("gRPC port %d is blocked or unreachable", g.port)
 		c.Metrics().IncrementCounter(context.Background(), "grpc_server_errors_total")
 		c.Metrics().SetGauge("grpc_server_status", 0)
@@ -197,11 +267,11 @@ func (g *grpcServer) Run(c *infra.Container) {
 		return
 	}
 
-	addr := ":" + strconv.Itoa(g.port)
+	addr := g.bind.address(g.port)
 
 	c.Logger.Infof("starting gRPC server at %s", addr)
 
-	listener, err := (&net.ListenConfig{}).Listen(context.Background(), "tcp", addr)
+	listener, err := g.bind.listen(g.port)
 	if err != nil {
 		c.Logger.Errorf("error in starting gRPC server at %s: %s", addr, err)
 		c.Metrics().IncrementCounter(context.Background(), "grpc_server_errors_total")
@@ -225,7 +295,21 @@ func (g *grpcServer) Run(c *infra.Container) {
 	c.Metrics().SetGauge("grpc_server_status", 0)
 }
 
+// Shutdown drains the gRPC server. GracefulStop sends GOAWAY to every connection, so that clients stop
+// opening new streams, and waits for the in-flight RPCs to finish. Once the drain timeout or the context
+// deadline is reached, the remaining RPCs are cancelled and the connections are closed.
 func (g *grpcServer) Shutdown(ctx context.Context) error {
+	if g.drainTimeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, g.drainTimeout)
+		defer cancel()
+	}
+
+	if g.server != nil && g.logger != nil {
+		g.logger.Infof("draining gRPC server with %d in-flight RPCs", g.inFlight.Load())
+	}
+
 	return ShutdownWithContext(ctx, func(_ context.Context) error {
 		if g.server != nil {
 			g.server.GracefulStop()
@@ -233,8 +317,18 @@ func (g *grpcServer) Shutdown(ctx context.Context) error {
 
 		return nil
 	}, func() error {
+		if g.server == nil {
+			return nil
+		}
+
+		cut := g.inFlight.Load()
+
 		g.server.Stop()
 
+		if g.logger != nil {
+			g.logger.Warnf("gRPC server drain did not complete in time, force closed %d in-flight RPCs", cut)
+		}
+
 		return nil
 	})
 }
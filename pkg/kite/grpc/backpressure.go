@@ -0,0 +1,294 @@
+package grpc
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Backpressure metrics of the server streams. They are registered by the kite package when the gRPC server is created.
+const (
+	streamSendLagMetric       = "grpc_server_stream_send_lag"
+	streamSlowConsumersMetric = "grpc_server_stream_slow_consumers_total"
+	streamsCutMetric          = "grpc_server_streams_cut_total"
+
+	defaultStreamQueueSize       = 64
+	defaultStreamSlowConsumerLag = time.Second
+	defaultStreamMaxLag          = 10 * time.Second
+)
+
+var (
+	// ErrSlowConsumer is returned by StreamSender once the stream is cut because its consumer lags too far behind.
+	// It is a RESOURCE_EXHAUSTED status, which the handler returns as is to end the stream.
+	ErrSlowConsumer = status.Error(codes.ResourceExhausted, "stream cut: the consumer is too slow")
+
+	errStreamSenderClosed = errors.New("stream sender is closed")
+)
+
+// BackpressureConfig holds the configuration of a StreamSender.
+type BackpressureConfig struct {
+	// QueueSize is the number of messages queued for sending, Send blocks while the queue is full. Defaults to 64.
+	QueueSize int
+	// SlowConsumerLag is the lag above which the consumer of the stream is reported slow, once per stream.
+	// Defaults to 1 second.
+	SlowConsumerLag time.Duration
+	// MaxLag is the lag above which the stream is cut. Defaults to 10 seconds.
+	MaxLag time.Duration
+}
+
+func (c BackpressureConfig) withDefaults() BackpressureConfig {
+	if c.QueueSize <= 0 {
+		c.QueueSize = defaultStreamQueueSize
+	}
+
+	if c.SlowConsumerLag <= 0 {
+		c.SlowConsumerLag = defaultStreamSlowConsumerLag
+	}
+
+	if c.MaxLag <= 0 {
+		c.MaxLag = defaultStreamMaxLag
+	}
+
+	return c
+}
+
+// StreamSender sends the messages of a server stream from a bounded queue, protecting the server from the consumers
+// which do not keep up: the messages of a slow consumer pile up in memory, or block the handler, as long as the
+// stream is open.
+//
+// The lag of the stream is the time the oldest message not yet sent has been waiting. The consumers lagging more than
+// SlowConsumerLag are logged and counted by grpc_server_stream_slow_consumers_total, and the streams lagging more than
+// MaxLag are cut: Send and Close return ErrSlowConsumer, and grpc_server_streams_cut_total is incremented. The lag of
+// every message is recorded by the grpc_server_stream_send_lag histogram, in milliseconds.
+//
+//	func (s *Server) Watch(ctx *kite.Context, stream pb.Prices_WatchServer) error {
+//		sender := kiteGRPC.NewStreamSender(stream, kiteGRPC.BackpressureConfig{MaxLag: 5 * time.Second},
+//			ctx.Logger, ctx.Metrics())
+//
+//		for price := range s.prices(ctx) {
+//			if err := sender.Send(price); err != nil {
+//				return err
+//			}
+//		}
+//
+//		return sender.Close()
+//	}
+//
+// As the methods of a grpc.ServerStream, the methods of a StreamSender must not be called concurrently.
+type StreamSender struct {
+	stream  grpc.ServerStream
+	config  BackpressureConfig
+	logger  Logger
+	metrics Metrics
+	labels  methodLabels
+
+	queue  chan queuedMessage
+	closed bool
+	// drained is closed once the queued messages are sent, or dropped when the stream ended.
+	drained chan struct{}
+	// ended is closed when the stream is cut or fails, err holding the reason.
+	ended   chan struct{}
+	endOnce sync.Once
+	err     error
+
+	// sendingSince is the time, in Unix nanoseconds, the message being sent was queued at, 0 when none is.
+	sendingSince atomic.Int64
+	slow         atomic.Bool
+}
+
+type queuedMessage struct {
+	msg      any
+	queuedAt time.Time
+}
+
+// NewStreamSender returns a StreamSender sending messages on stream, the stream of a server-streaming or of a
+// bidirectional RPC. Close must be called before the handler returns, as the messages cannot be sent once it has.
+func NewStreamSender(stream grpc.ServerStream, config BackpressureConfig, logger Logger, metrics Metrics) *StreamSender {
+	config = config.withDefaults()
+	method, _ := grpc.MethodFromServerStream(stream)
+
+	s := &StreamSender{
+		stream:  stream,
+		config:  config,
+		logger:  logger,
+		metrics: metrics,
+		labels:  newMethodLabels(method, rpcTypeServerStream, method != ""),
+		queue:   make(chan queuedMessage, config.QueueSize),
+		drained: make(chan struct{}),
+		ended:   make(chan struct{}),
+	}
+
+	go s.drain()
+
+	return s
+}
+
+// Send queues msg for sending, blocking while the queue is full. It returns the error which ended the stream, e.g.
+// ErrSlowConsumer, in which case msg is not sent.
+func (s *StreamSender) Send(msg any) error {
+	if s.closed {
+		return errStreamSenderClosed
+	}
+
+	if err := s.Err(); err != nil {
+		return err
+	}
+
+	lag := s.Lag()
+	if lag > s.config.MaxLag {
+		return s.cut(lag)
+	}
+
+	s.checkSlow(lag)
+
+	// the queue is full for as long as the message being sent is not, so the stream is cut once it lags MaxLag.
+	timer := time.NewTimer(s.config.MaxLag - lag)
+	defer timer.Stop()
+
+	select {
+	case s.queue <- queuedMessage{msg: msg, queuedAt: time.Now()}:
+		return nil
+	case <-s.ended:
+		return s.Err()
+	case <-timer.C:
+		return s.cut(s.Lag())
+	}
+}
+
+// Close waits until the queued messages are sent, and returns the error which ended the stream, if any. The stream
+// is cut when its consumer lags more than MaxLag meanwhile.
+func (s *StreamSender) Close() error {
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+
+	for {
+		lag := s.Lag()
+		if lag > s.config.MaxLag {
+			return s.cut(lag)
+		}
+
+		timer := time.NewTimer(s.config.MaxLag - lag)
+
+		select {
+		case <-s.drained:
+			timer.Stop()
+
+			return s.Err()
+		case <-s.ended:
+			timer.Stop()
+
+			return s.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Lag returns the time the oldest message not yet sent has been waiting.
+func (s *StreamSender) Lag() time.Duration {
+	since := s.sendingSince.Load()
+	if since == 0 {
+		return 0
+	}
+
+	return time.Since(time.Unix(0, since))
+}
+
+// Err returns the error which ended the stream, or nil while it is open.
+func (s *StreamSender) Err() error {
+	select {
+	case <-s.ended:
+		return s.err
+	default:
+		return nil
+	}
+}
+
+// drain sends the queued messages until the queue is closed. Once the stream has ended, the messages are dropped.
+func (s *StreamSender) drain() {
+	defer close(s.drained)
+
+	for m := range s.queue {
+		if s.Err() != nil {
+			continue
+		}
+
+		s.sendingSince.Store(m.queuedAt.UnixNano())
+		err := s.stream.SendMsg(m.msg)
+		lag := time.Since(m.queuedAt)
+		s.sendingSince.Store(0)
+
+		if err != nil {
+			s.end(err)
+
+			continue
+		}
+
+		s.recordLag(lag)
+	}
+}
+
+// end ends the stream with err, and reports whether it was still open.
+func (s *StreamSender) end(err error) bool {
+	ended := false
+
+	s.endOnce.Do(func() {
+		s.err = err
+		close(s.ended)
+
+		ended = true
+	})
+
+	return ended
+}
+
+// cut ends the stream of a consumer lagging lag behind.
+func (s *StreamSender) cut(lag time.Duration) error {
+	if s.end(ErrSlowConsumer) {
+		if s.logger != nil {
+			s.logger.Errorf("cutting stream %s/%s, its consumer lags %v behind", s.labels.service, s.labels.method,
+				lag.Round(time.Millisecond))
+		}
+
+		if s.metrics != nil {
+			s.metrics.IncrementCounter(s.stream.Context(), streamsCutMetric,
+				"service", s.labels.service, "method", s.labels.method)
+		}
+	}
+
+	return s.Err()
+}
+
+// recordLag records the lag of a sent message.
+func (s *StreamSender) recordLag(lag time.Duration) {
+	if s.metrics != nil {
+		s.metrics.RecordHistogram(s.stream.Context(), streamSendLagMetric, float64(lag.Microseconds())/1000,
+			"service", s.labels.service, "method", s.labels.method)
+	}
+
+	s.checkSlow(lag)
+}
+
+// checkSlow reports the consumer slow, once, when the stream lags more than SlowConsumerLag.
+func (s *StreamSender) checkSlow(lag time.Duration) {
+	if lag <= s.config.SlowConsumerLag || !s.slow.CompareAndSwap(false, true) {
+		return
+	}
+
+	if s.logger != nil {
+		s.logger.Info(fmt.Sprintf("slow consumer of stream %s/%s, its messages wait %v to be sent", s.labels.service,
+			s.labels.method, lag.Round(time.Millisecond)))
+	}
+
+	if s.metrics != nil {
+		s.metrics.IncrementCounter(s.stream.Context(), streamSlowConsumersMetric,
+			"service", s.labels.service, "method", s.labels.method)
+	}
+}
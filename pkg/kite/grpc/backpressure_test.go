@@ -0,0 +1,223 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+var errStreamBroken = errors.New("stream broken")
+
+// gatedServerStream is a server stream whose sends wait for the gate to be opened.
+type gatedServerStream struct {
+	grpc.ServerStream
+
+	ctx  context.Context
+	gate chan struct{}
+	err  error
+
+	mu   sync.Mutex
+	sent []any
+}
+
+func newGatedServerStream(t *testing.T) *gatedServerStream {
+	t.Helper()
+
+	ctx := grpc.NewContextWithServerTransportStream(t.Context(), transportStream{method: "/prices.Prices/Watch"})
+
+	return &gatedServerStream{ctx: ctx, gate: make(chan struct{})}
+}
+
+func (s *gatedServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *gatedServerStream) SendMsg(m any) error {
+	select {
+	case <-s.gate:
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	}
+
+	if s.err != nil {
+		return s.err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sent = append(s.sent, m)
+
+	return nil
+}
+
+func (s *gatedServerStream) messages() []any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sent
+}
+
+type transportStream struct {
+	grpc.ServerTransportStream
+
+	method string
+}
+
+func (t transportStream) Method() string {
+	return t.method
+}
+
+// streamMetrics records the counters and the histograms of the stream senders.
+type streamMetrics struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (m *streamMetrics) IncrementCounter(_ context.Context, name string, labels ...string) {
+	m.record(name, labels)
+}
+
+func (*streamMetrics) DeltaUpDownCounter(context.Context, string, float64, ...string) {}
+
+func (m *streamMetrics) RecordHistogram(_ context.Context, name string, _ float64, labels ...string) {
+	m.record(name, labels)
+}
+
+func (m *streamMetrics) record(name string, labels []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.counts == nil {
+		m.counts = make(map[string]int)
+	}
+
+	m.counts[fmt.Sprint(name, labels)]++
+}
+
+func (m *streamMetrics) count(name string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.counts[fmt.Sprint(name, []string{"service", "prices.Prices", "method", "Watch"})]
+}
+
+func TestStreamSender_Send(t *testing.T) {
+	stream := newGatedServerStream(t)
+	metrics := &streamMetrics{}
+
+	close(stream.gate)
+
+	sender := NewStreamSender(stream, BackpressureConfig{QueueSize: 2}, nil, metrics)
+
+	for i := range 5 {
+		require.NoError(t, sender.Send(i))
+	}
+
+	require.NoError(t, sender.Close())
+
+	assert.Equal(t, []any{0, 1, 2, 3, 4}, stream.messages(), "the messages are sent in order")
+	assert.Equal(t, 5, metrics.count(streamSendLagMetric))
+	assert.Equal(t, 0, metrics.count(streamSlowConsumersMetric))
+	assert.ErrorIs(t, sender.Send(5), errStreamSenderClosed)
+}
+
+func TestStreamSender_SlowConsumer(t *testing.T) {
+	stream := newGatedServerStream(t)
+	metrics := &streamMetrics{}
+
+	sender := NewStreamSender(stream, BackpressureConfig{QueueSize: 1, SlowConsumerLag: 10 * time.Millisecond,
+		MaxLag: time.Second}, nil, metrics)
+
+	require.NoError(t, sender.Send(0))
+
+	time.Sleep(30 * time.Millisecond)
+	close(stream.gate)
+
+	require.NoError(t, sender.Send(1))
+	require.NoError(t, sender.Close())
+
+	assert.Equal(t, 1, metrics.count(streamSlowConsumersMetric), "a slow consumer is reported once")
+	assert.Equal(t, 0, metrics.count(streamsCutMetric))
+}
+
+func TestStreamSender_Cut(t *testing.T) {
+	testCases := []struct {
+		desc string
+		// send sends the messages of a stream whose consumer is stuck.
+		send func(s *StreamSender) error
+	}{
+		{desc: "full queue", send: func(s *StreamSender) error {
+			for i := range 10 {
+				if err := s.Send(i); err != nil {
+					return err
+				}
+			}
+
+			return s.Close()
+		}},
+		{desc: "lagging send", send: func(s *StreamSender) error {
+			_ = s.Send(0)
+
+			time.Sleep(60 * time.Millisecond)
+
+			return s.Send(1)
+		}},
+		{desc: "close", send: func(s *StreamSender) error {
+			_ = s.Send(0)
+
+			return s.Close()
+		}},
+	}
+
+	for i, tc := range testCases {
+		stream := newGatedServerStream(t)
+		metrics := &streamMetrics{}
+
+		sender := NewStreamSender(stream, BackpressureConfig{QueueSize: 2, MaxLag: 50 * time.Millisecond}, nil, metrics)
+
+		start := time.Now()
+		err := tc.send(sender)
+
+		require.ErrorIs(t, err, ErrSlowConsumer, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Less(t, time.Since(start), time.Second, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, 1, metrics.count(streamsCutMetric), "TEST[%d], Failed.\n%s", i, tc.desc)
+		require.ErrorIs(t, sender.Err(), ErrSlowConsumer, "TEST[%d], Failed.\n%s", i, tc.desc)
+
+		close(stream.gate)
+	}
+}
+
+func TestStreamSender_StreamFailure(t *testing.T) {
+	stream := newGatedServerStream(t)
+	stream.err = errStreamBroken
+
+	close(stream.gate)
+
+	sender := NewStreamSender(stream, BackpressureConfig{}, nil, nil)
+
+	require.NoError(t, sender.Send(0))
+	require.ErrorIs(t, sender.Close(), errStreamBroken)
+	assert.Empty(t, stream.messages())
+}
+
+func TestStreamSender_Metadata(t *testing.T) {
+	stream := newGatedServerStream(t)
+	stream.ctx = metadata.NewIncomingContext(stream.ctx, metadata.Pairs("k", "v"))
+
+	close(stream.gate)
+
+	sender := NewStreamSender(stream, BackpressureConfig{}, nil, nil)
+
+	assert.Equal(t, "prices.Prices", sender.labels.service)
+	assert.Equal(t, "Watch", sender.labels.method)
+	require.NoError(t, sender.Close())
+}
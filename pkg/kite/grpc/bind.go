@@ -0,0 +1,151 @@
+package grpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var errBindTarget = errors.New("bind target must be a non-nil pointer")
+
+// wellKnownTypes are encoded with their protojson representation, e.g. RFC 3339 strings for timestamps.
+var wellKnownTypes = map[protoreflect.FullName]bool{
+	"google.protobuf.Any":         true,
+	"google.protobuf.Duration":    true,
+	"google.protobuf.Empty":       true,
+	"google.protobuf.FieldMask":   true,
+	"google.protobuf.Struct":      true,
+	"google.protobuf.Value":       true,
+	"google.protobuf.ListValue":   true,
+	"google.protobuf.Timestamp":   true,
+	"google.protobuf.BoolValue":   true,
+	"google.protobuf.BytesValue":  true,
+	"google.protobuf.DoubleValue": true,
+	"google.protobuf.FloatValue":  true,
+	"google.protobuf.Int32Value":  true,
+	"google.protobuf.Int64Value":  true,
+	"google.protobuf.StringValue": true,
+	"google.protobuf.UInt32Value": true,
+	"google.protobuf.UInt64Value": true,
+}
+
+// Bind copies a protobuf request message into target. It is used by the request wrappers generated by
+// `kite wrap grpc server` to implement Context.Bind.
+//
+// If target is a message of the same type, it receives a deep copy of msg. Otherwise, the populated fields of
+// msg are bound by their JSON names: the set field of a oneof is bound like a regular field, maps are bound as
+// maps keyed by the string form of their keys, enums are bound as numbers and well-known types use their
+// protojson representation.
+func Bind(msg proto.Message, target any) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("%w, got %T", errBindTarget, target)
+	}
+
+	if msg == nil {
+		return nil
+	}
+
+	if t, ok := target.(proto.Message); ok &&
+		t.ProtoReflect().Descriptor().FullName() == msg.ProtoReflect().Descriptor().FullName() {
+		proto.Reset(t)
+		proto.Merge(t, msg)
+
+		return nil
+	}
+
+	fields, err := messageToMap(msg.ProtoReflect())
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, target)
+}
+
+func messageToMap(m protoreflect.Message) (map[string]any, error) {
+	fields := make(map[string]any)
+
+	var err error
+
+	// Range only visits populated fields, so only the field which is set is visited for a oneof.
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		var value any
+
+		switch {
+		case fd.IsMap():
+			value, err = mapToMap(fd, v.Map())
+		case fd.IsList():
+			value, err = listToSlice(fd, v.List())
+		default:
+			value, err = singularValue(fd, v)
+		}
+
+		if err != nil {
+			return false
+		}
+
+		fields[fd.JSONName()] = value
+
+		return true
+	})
+
+	return fields, err
+}
+
+func mapToMap(fd protoreflect.FieldDescriptor, m protoreflect.Map) (map[string]any, error) {
+	out := make(map[string]any, m.Len())
+
+	var err error
+
+	m.Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+		out[k.String()], err = singularValue(fd.MapValue(), v)
+
+		return err == nil
+	})
+
+	return out, err
+}
+
+func listToSlice(fd protoreflect.FieldDescriptor, l protoreflect.List) ([]any, error) {
+	out := make([]any, l.Len())
+
+	for i := range l.Len() {
+		v, err := singularValue(fd, l.Get(i))
+		if err != nil {
+			return nil, err
+		}
+
+		out[i] = v
+	}
+
+	return out, nil
+}
+
+func singularValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) (any, error) {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		msg := v.Message()
+
+		if wellKnownTypes[msg.Descriptor().FullName()] {
+			data, err := protojson.Marshal(msg.Interface())
+
+			return json.RawMessage(data), err
+		}
+
+		return messageToMap(msg)
+	case protoreflect.EnumKind:
+		return int32(v.Enum()), nil
+	default:
+		return v.Interface(), nil
+	}
+}
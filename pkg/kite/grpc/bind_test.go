@@ -0,0 +1,149 @@
+package grpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// newOrderDescriptor builds the descriptor of the following message:
+//
+//	message Order {
+//	  string id = 1;
+//	  map<string, int64> quantities = 2;
+//	  oneof contact {
+//	    string email = 3;
+//	    Address address = 4;
+//	  }
+//	  repeated string tags = 5;
+//	}
+//
+//	message Address { string city = 1; }
+func newOrderDescriptor(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	repeated := descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	str := descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()
+	msg := descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("order.proto"),
+		Package: proto.String("test"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Order"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("id"), JsonName: proto.String("id"), Number: proto.Int32(1), Label: optional, Type: str},
+					{Name: proto.String("quantities"), JsonName: proto.String("quantities"), Number: proto.Int32(2),
+						Label: repeated, Type: msg, TypeName: proto.String(".test.Order.QuantitiesEntry")},
+					{Name: proto.String("email"), JsonName: proto.String("email"), Number: proto.Int32(3), Label: optional,
+						Type: str, OneofIndex: proto.Int32(0)},
+					{Name: proto.String("address"), JsonName: proto.String("address"), Number: proto.Int32(4), Label: optional,
+						Type: msg, TypeName: proto.String(".test.Address"), OneofIndex: proto.Int32(0)},
+					{Name: proto.String("tags"), JsonName: proto.String("tags"), Number: proto.Int32(5), Label: repeated, Type: str},
+				},
+				NestedType: []*descriptorpb.DescriptorProto{{
+					Name: proto.String("QuantitiesEntry"),
+					Field: []*descriptorpb.FieldDescriptorProto{
+						{Name: proto.String("key"), JsonName: proto.String("key"), Number: proto.Int32(1), Label: optional, Type: str},
+						{Name: proto.String("value"), JsonName: proto.String("value"), Number: proto.Int32(2), Label: optional,
+							Type: descriptorpb.FieldDescriptorProto_TYPE_INT64.Enum()},
+					},
+					Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+				}},
+				OneofDecl: []*descriptorpb.OneofDescriptorProto{{Name: proto.String("contact")}},
+			},
+			{
+				Name: proto.String("Address"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("city"), JsonName: proto.String("city"), Number: proto.Int32(1), Label: optional, Type: str},
+				},
+			},
+		},
+	}
+
+	fd, err := protodesc.NewFile(file, nil)
+	require.NoError(t, err)
+
+	return fd.Messages().ByName("Order")
+}
+
+func TestBind_OneofAndMapFields(t *testing.T) {
+	desc := newOrderDescriptor(t)
+	order := dynamicpb.NewMessage(desc)
+	fields := desc.Fields()
+
+	order.Set(fields.ByName("id"), protoreflect.ValueOfString("order-1"))
+
+	quantities := order.Mutable(fields.ByName("quantities")).Map()
+	quantities.Set(protoreflect.ValueOfString("apple").MapKey(), protoreflect.ValueOfInt64(1<<40))
+
+	address := dynamicpb.NewMessage(fields.ByName("address").Message())
+	address.Set(address.Descriptor().Fields().ByName("city"), protoreflect.ValueOfString("Berlin"))
+	order.Set(fields.ByName("address"), protoreflect.ValueOfMessage(address))
+
+	tags := order.Mutable(fields.ByName("tags")).List()
+	tags.Append(protoreflect.ValueOfString("express"))
+
+	var target struct {
+		ID         string           `json:"id"`
+		Quantities map[string]int64 `json:"quantities"`
+		Email      string           `json:"email"`
+		Address    *struct {
+			City string `json:"city"`
+		} `json:"address"`
+		Tags []string `json:"tags"`
+	}
+
+	require.NoError(t, Bind(order, &target))
+
+	assert.Equal(t, "order-1", target.ID)
+	assert.Equal(t, map[string]int64{"apple": 1 << 40}, target.Quantities)
+	assert.Empty(t, target.Email)
+	require.NotNil(t, target.Address)
+	assert.Equal(t, "Berlin", target.Address.City)
+	assert.Equal(t, []string{"express"}, target.Tags)
+}
+
+func TestBind_SameMessageType(t *testing.T) {
+	req := &grpc_health_v1.HealthCheckRequest{Service: "orders"}
+
+	var target grpc_health_v1.HealthCheckRequest
+
+	require.NoError(t, Bind(req, &target))
+
+	assert.Equal(t, "orders", target.Service)
+
+	// The target is a copy, so later changes to the request are not reflected.
+	req.Service = "payments"
+	assert.Equal(t, "orders", target.Service)
+}
+
+func TestBind_EnumAsNumber(t *testing.T) {
+	resp := &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_NOT_SERVING}
+
+	var target struct {
+		Status int32 `json:"status"`
+	}
+
+	require.NoError(t, Bind(resp, &target))
+
+	assert.Equal(t, int32(grpc_health_v1.HealthCheckResponse_NOT_SERVING), target.Status)
+}
+
+func TestBind_InvalidTarget(t *testing.T) {
+	var target struct{}
+
+	err := Bind(&grpc_health_v1.HealthCheckRequest{}, target)
+
+	require.ErrorIs(t, err, errBindTarget)
+}
@@ -0,0 +1,70 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelCodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Per-method gRPC client metrics. They are registered by the clients generated with "kite wrap grpc client".
+const (
+	clientDurationMetric = "grpc_client_request_duration"
+	clientErrorsMetric   = "grpc_client_errors_total"
+)
+
+// StartClientSpan starts the client span of a call to fullMethod, e.g. "/Service/Method", on target. The returned
+// context carries the span, the trace metadata read by kite gRPC servers and the deadline of ctx, which gRPC
+// propagates to the server.
+func StartClientSpan(ctx context.Context, target, fullMethod string) (context.Context, trace.Span) {
+	l := newMethodLabels(fullMethod, "", true)
+
+	ctx, span := otel.GetTracerProvider().Tracer("kite-gRPC-client").Start(ctx, fullMethod,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.service", l.service),
+			attribute.String("rpc.method", l.method),
+			attribute.String("server.address", target),
+		))
+
+	sc := span.SpanContext()
+	ctx = metadata.AppendToOutgoingContext(ctx, "x-kite-traceid", sc.TraceID().String(), "x-kite-spanid", sc.SpanID().String())
+
+	return ctx, span
+}
+
+// EndClientSpan records the status code of the call on span, and the error if it failed, then ends it.
+func EndClientSpan(span trace.Span, err error) {
+	span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(status.Code(err))))
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelCodes.Error, status.Convert(err).Message())
+	}
+
+	span.End()
+}
+
+// RecordClientMetrics records the latency of a call to fullMethod made by a generated client, and counts it as an
+// error if it failed.
+func RecordClientMetrics(ctx context.Context, metrics Metrics, fullMethod string, duration time.Duration, err error) {
+	if metrics == nil {
+		return
+	}
+
+	l := newMethodLabels(fullMethod, "", true)
+	code := status.Code(err).String()
+	durationMs := float64(duration.Milliseconds()) + float64(duration.Nanoseconds()%nanosecondsPerMillisecond)/nanosecondsPerMillisecond
+
+	metrics.RecordHistogram(ctx, clientDurationMetric, durationMs, "service", l.service, "method", l.method, "code", code)
+
+	if err != nil {
+		metrics.IncrementCounter(ctx, clientErrorsMetric, "service", l.service, "method", l.method, "code", code)
+	}
+}
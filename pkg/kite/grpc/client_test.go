@@ -0,0 +1,80 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelCodes "go.opentelemetry.io/otel/codes"
+	sdkTrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestStartClientSpan(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdkTrace.NewTracerProvider(sdkTrace.WithSyncer(exporter)))
+
+	deadline := time.Now().Add(time.Minute)
+
+	parent, cancel := context.WithDeadline(t.Context(), deadline)
+	defer cancel()
+
+	ctx, span := StartClientSpan(parent, "localhost:9000", "/Hello/SayHello")
+
+	md, ok := metadata.FromOutgoingContext(ctx)
+	require.True(t, ok)
+	assert.Equal(t, []string{span.SpanContext().TraceID().String()}, md.Get("x-kite-traceid"))
+	assert.Equal(t, []string{span.SpanContext().SpanID().String()}, md.Get("x-kite-spanid"))
+
+	d, ok := ctx.Deadline()
+	require.True(t, ok)
+	assert.Equal(t, deadline, d)
+
+	EndClientSpan(span, status.Error(codes.Unavailable, "connection refused"))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+
+	s := spans[0]
+	assert.Equal(t, "/Hello/SayHello", s.Name)
+	assert.Equal(t, trace.SpanKindClient, s.SpanKind)
+	assert.Equal(t, otelCodes.Error, s.Status.Code)
+	assert.Equal(t, "connection refused", s.Status.Description)
+	assert.Subset(t, s.Attributes, []attribute.KeyValue{
+		attribute.String("rpc.system", "grpc"),
+		attribute.String("rpc.service", "Hello"),
+		attribute.String("rpc.method", "SayHello"),
+		attribute.String("server.address", "localhost:9000"),
+		attribute.Int("rpc.grpc.status_code", int(codes.Unavailable)),
+	})
+}
+
+func TestRecordClientMetrics(t *testing.T) {
+	_, mockMetrics, ctrl := createMocks(t)
+	defer ctrl.Finish()
+
+	mockMetrics.EXPECT().RecordHistogram(gomock.Any(), clientDurationMetric, 1.5,
+		"service", "Hello", "method", "SayHello", "code", "OK").Times(1)
+
+	RecordClientMetrics(t.Context(), mockMetrics, "/Hello/SayHello", 1500*time.Microsecond, nil)
+
+	mockMetrics.EXPECT().RecordHistogram(gomock.Any(), clientDurationMetric, 2.0,
+		"service", "Hello", "method", "SayHello", "code", "DeadlineExceeded").Times(1)
+	mockMetrics.EXPECT().IncrementCounter(gomock.Any(), clientErrorsMetric,
+		"service", "Hello", "method", "SayHello", "code", "DeadlineExceeded").Times(1)
+
+	RecordClientMetrics(t.Context(), mockMetrics, "/Hello/SayHello", 2*time.Millisecond,
+		status.Error(codes.DeadlineExceeded, "deadline exceeded"))
+
+	// A nil Metrics is ignored.
+	RecordClientMetrics(t.Context(), nil, "/Hello/SayHello", time.Millisecond, nil)
+}
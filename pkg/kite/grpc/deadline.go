@@ -0,0 +1,46 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type deadlineLogger interface {
+	Warnf(format string, args ...any)
+}
+
+// DeadlineBudgetInterceptor subtracts overhead from the deadline of the incoming calls, sent by the clients in the
+// grpc-timeout header, to leave time for the response to travel back. The context of the handler carries the rest,
+// so that it also bounds the calls made by the handler.
+//
+// Calls with no budget left are doomed to miss their deadline: they are logged and fail with DeadlineExceeded
+// without calling the handler.
+func DeadlineBudgetInterceptor(overhead time.Duration, logger deadlineLogger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= overhead {
+			logger.Warnf("%s is doomed to miss its deadline: the caller waits %v, less than the overhead of %v",
+				info.FullMethod, remaining.Round(time.Millisecond), overhead)
+
+			return nil, status.Error(codes.DeadlineExceeded, "not enough time left to handle the call")
+		}
+
+		if overhead == 0 {
+			return handler(ctx, req)
+		}
+
+		ctx, cancel := context.WithDeadline(ctx, deadline.Add(-overhead))
+		defer cancel()
+
+		return handler(ctx, req)
+	}
+}
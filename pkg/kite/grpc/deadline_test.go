@@ -0,0 +1,70 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type deadlineMockLogger struct {
+	logs []string
+}
+
+func (l *deadlineMockLogger) Warnf(format string, args ...any) {
+	l.logs = append(l.logs, fmt.Sprintf(format, args...))
+}
+
+func TestDeadlineBudgetInterceptor(t *testing.T) {
+	logger := &deadlineMockLogger{}
+	interceptor := DeadlineBudgetInterceptor(100*time.Millisecond, logger)
+	info := &grpc.UnaryServerInfo{FullMethod: "/Hello/SayHello"}
+
+	var remaining time.Duration
+
+	handler := func(ctx context.Context, _ any) (any, error) {
+		deadline, ok := ctx.Deadline()
+		require.True(t, ok)
+
+		remaining = time.Until(deadline)
+
+		return "ok", nil
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+	defer cancel()
+
+	res, err := interceptor(ctx, nil, info, handler)
+
+	require.NoError(t, err)
+	assert.Equal(t, "ok", res)
+	assert.InDelta(t, 900*time.Millisecond, remaining, float64(20*time.Millisecond))
+
+	doomed, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = interceptor(doomed, nil, info, func(context.Context, any) (any, error) {
+		t.Error("the handler of a doomed call must not be called")
+		return nil, nil
+	})
+
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	require.Len(t, logger.logs, 1)
+	assert.Contains(t, logger.logs[0], "/Hello/SayHello is doomed to miss its deadline")
+
+	res, err = interceptor(t.Context(), nil, info, func(ctx context.Context, _ any) (any, error) {
+		_, ok := ctx.Deadline()
+		assert.False(t, ok)
+
+		return "no deadline", nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "no deadline", res)
+}
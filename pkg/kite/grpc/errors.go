@@ -0,0 +1,181 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	kiteErrors "github.com/sllt/kite/pkg/kite/errors"
+	kiteHTTP "github.com/sllt/kite/pkg/kite/http"
+)
+
+// errorDomain is the domain of the errdetails.ErrorInfo attached to the statuses of the kite errors.
+const errorDomain = "kite"
+
+// httpCodes maps the HTTP status codes of the errors to the gRPC codes, as the Responder maps the errors to the
+// HTTP status codes.
+var httpCodes = map[int]codes.Code{
+	http.StatusBadRequest:            codes.InvalidArgument,
+	http.StatusUnauthorized:          codes.Unauthenticated,
+	http.StatusForbidden:             codes.PermissionDenied,
+	http.StatusNotFound:              codes.NotFound,
+	http.StatusMethodNotAllowed:      codes.Unimplemented,
+	http.StatusRequestTimeout:        codes.DeadlineExceeded,
+	http.StatusConflict:              codes.AlreadyExists,
+	http.StatusPreconditionFailed:    codes.FailedPrecondition,
+	http.StatusRequestEntityTooLarge: codes.ResourceExhausted,
+	http.StatusTooManyRequests:       codes.ResourceExhausted,
+	http.StatusNotImplemented:        codes.Unimplemented,
+	http.StatusServiceUnavailable:    codes.Unavailable,
+	http.StatusGatewayTimeout:        codes.DeadlineExceeded,
+}
+
+// ToStatus converts an error returned by a handler into a gRPC status error, so that gRPC clients get the same
+// failure as HTTP clients of the same handler:
+//
+//   - the errors of the kite errors package get the code of their kind, e.g. NOT_FOUND for errors.NotFound,
+//   - the errors carrying an HTTP status code, e.g. http.ErrorEntityNotFound, get the matching code, e.g. 400 is
+//     INVALID_ARGUMENT, 429 is RESOURCE_EXHAUSTED and 503 is UNAVAILABLE,
+//   - the context errors get CANCELLED or DEADLINE_EXCEEDED,
+//   - gRPC statuses are returned unchanged, and the other errors are INTERNAL.
+//
+// The message is the message of the error. The details contain an errdetails.ErrorInfo with the reason of the
+// error, e.g. NOT_FOUND, and its business code and metadata, an errdetails.RetryInfo with the delay of the errors
+// telling the clients when to retry, and an errdetails.BadRequest listing the field errors of the validation errors.
+// It returns nil for a nil error.
+func ToStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var (
+		kiteErr *kiteErrors.Error
+		reason  string
+		st      *status.Status
+	)
+
+	switch {
+	case errors.As(err, &kiteErr):
+		reason = kiteErr.Kind().String()
+		st = status.New(kiteErr.GRPCStatus().Code(), err.Error())
+	case isStatus(err):
+		return err
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	default:
+		var responder kiteHTTP.StatusCodeResponder
+		if !errors.As(err, &responder) {
+			return status.Error(codes.Internal, err.Error())
+		}
+
+		reason = http.StatusText(responder.StatusCode())
+		st = status.New(grpcCode(responder.StatusCode()), err.Error())
+	}
+
+	return withDetails(st, err, reason).Err()
+}
+
+// isStatus reports whether err is, or wraps, a gRPC status.
+func isStatus(err error) bool {
+	var grpcStatus interface{ GRPCStatus() *status.Status }
+
+	return errors.As(err, &grpcStatus)
+}
+
+// grpcCode returns the gRPC code of an HTTP status code, INVALID_ARGUMENT for the other client errors and INTERNAL
+// for the other status codes.
+func grpcCode(statusCode int) codes.Code {
+	if code, ok := httpCodes[statusCode]; ok {
+		return code
+	}
+
+	if statusCode >= http.StatusBadRequest && statusCode < http.StatusInternalServerError {
+		return codes.InvalidArgument
+	}
+
+	return codes.Internal
+}
+
+// withDetails attaches the reason, business code, metadata, retry delay and field errors of err to the status.
+func withDetails(st *status.Status, err error, reason string) *status.Status {
+	info := &errdetails.ErrorInfo{
+		Reason: strings.ToUpper(strings.NewReplacer(" ", "_", "-", "_").Replace(reason)),
+		Domain: errorDomain,
+	}
+
+	var coder kiteHTTP.CodeResponder
+	if errors.As(err, &coder) {
+		info.Metadata = map[string]string{"code": strconv.Itoa(coder.Code())}
+	}
+
+	var marshaller kiteHTTP.ResponseMarshaller
+	if errors.As(err, &marshaller) {
+		for key, value := range marshaller.Response() {
+			if info.Metadata == nil {
+				info.Metadata = make(map[string]string)
+			}
+
+			info.Metadata[key] = fmt.Sprint(value)
+		}
+	}
+
+	details := []protoadapt.MessageV1{info}
+
+	var retryable kiteHTTP.RetryAfterResponder
+	if errors.As(err, &retryable) && retryable.RetryAfter() > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(retryable.RetryAfter())})
+	}
+
+	var fieldErrs kiteHTTP.FieldErrorsResponder
+	if errors.As(err, &fieldErrs) {
+		if fieldErrors := fieldErrs.FieldErrors(); len(fieldErrors) > 0 {
+			violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(fieldErrors))
+			for _, fe := range fieldErrors {
+				violations = append(violations, &errdetails.BadRequest_FieldViolation{
+					Field:       fe.Field,
+					Description: fe.Message,
+					Reason:      fe.Rule,
+				})
+			}
+
+			details = append(details, &errdetails.BadRequest{FieldViolations: violations})
+		}
+	}
+
+	if detailed, detailsErr := st.WithDetails(details...); detailsErr == nil {
+		return detailed
+	}
+
+	return st
+}
+
+// ErrorInterceptor returns a unary server interceptor converting the errors returned by the handlers with ToStatus.
+// It is added to the gRPC server of kite applications.
+func ErrorInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, ToStatus(err)
+		}
+
+		return resp, nil
+	}
+}
+
+// StreamErrorInterceptor returns a stream server interceptor converting the errors returned by the handlers with
+// ToStatus. It is added to the gRPC server of kite applications.
+func StreamErrorInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return ToStatus(handler(srv, ss))
+	}
+}
@@ -0,0 +1,185 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	kiteErrors "github.com/sllt/kite/pkg/kite/errors"
+	kiteHTTP "github.com/sllt/kite/pkg/kite/http"
+)
+
+var errTestHandler = errors.New("handler failed")
+
+func TestToStatus(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		err     error
+		code    codes.Code
+		message string
+		reason  string
+	}{
+		{"kite not found error", kiteErrors.NotFound("user not found"), codes.NotFound, "user not found", "NOT_FOUND"},
+		{"kite invalid error", kiteErrors.Invalid("bad id"), codes.InvalidArgument, "bad id", "INVALID"},
+		{"kite conflict error", kiteErrors.Conflict("exists"), codes.AlreadyExists, "exists", "CONFLICT"},
+		{"wrapped kite error", fmt.Errorf("loading: %w", kiteErrors.Forbidden("denied")), codes.PermissionDenied,
+			"loading: denied", "FORBIDDEN"},
+		{"entity not found", kiteHTTP.ErrorEntityNotFound{Name: "id", Value: "2"}, codes.NotFound,
+			"No entity found with id: 2", "NOT_FOUND"},
+		{"missing param", kiteHTTP.ErrorMissingParam{Params: []string{"name"}}, codes.InvalidArgument,
+			"'1' missing parameter(s): name", "BAD_REQUEST"},
+		{"too many requests", kiteHTTP.ErrorTooManyRequests{}, codes.ResourceExhausted,
+			kiteHTTP.ErrorTooManyRequests{}.Error(), "TOO_MANY_REQUESTS"},
+		{"service unavailable", kiteHTTP.ErrorServiceUnavailable{Dependency: "redis", ErrorMessage: "down"},
+			codes.Unavailable, kiteHTTP.ErrorServiceUnavailable{Dependency: "redis", ErrorMessage: "down"}.Error(),
+			"SERVICE_UNAVAILABLE"},
+		{"plain error", errTestHandler, codes.Internal, "handler failed", ""},
+		{"context cancelled", context.Canceled, codes.Canceled, context.Canceled.Error(), ""},
+		{"context deadline", context.DeadlineExceeded, codes.DeadlineExceeded, context.DeadlineExceeded.Error(), ""},
+		{"grpc status", status.Error(codes.Aborted, "retry"), codes.Aborted, "retry", ""},
+	}
+
+	for i, tc := range testCases {
+		st := status.Convert(ToStatus(tc.err))
+
+		assert.Equal(t, tc.code, st.Code(), "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, tc.message, st.Message(), "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, tc.reason, errorInfo(st).GetReason(), "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestToStatus_Nil(t *testing.T) {
+	assert.NoError(t, ToStatus(nil))
+}
+
+func TestToStatus_CodeAndMeta(t *testing.T) {
+	err := kiteErrors.NotFound("user not found").WithCode(10404).WithMeta("id", 42)
+
+	info := errorInfo(status.Convert(ToStatus(err)))
+
+	require.NotNil(t, info)
+	assert.Equal(t, "kite", info.GetDomain())
+	assert.Equal(t, map[string]string{"code": "10404", "id": "42"}, info.GetMetadata())
+}
+
+type testFieldErrors struct{}
+
+func (testFieldErrors) Error() string   { return "name: too short" }
+func (testFieldErrors) StatusCode() int { return 400 }
+func (testFieldErrors) FieldErrors() []kiteHTTP.FieldError {
+	return []kiteHTTP.FieldError{{Field: "name", Rule: "min", Message: "too short"}}
+}
+
+func TestToStatus_FieldErrors(t *testing.T) {
+	st := status.Convert(ToStatus(testFieldErrors{}))
+
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+
+	var violations []*errdetails.BadRequest_FieldViolation
+
+	for _, d := range st.Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			violations = br.GetFieldViolations()
+		}
+	}
+
+	require.Len(t, violations, 1)
+	assert.Equal(t, "name", violations[0].GetField())
+	assert.Equal(t, "too short", violations[0].GetDescription())
+	assert.Equal(t, "min", violations[0].GetReason())
+}
+
+func TestToStatus_RetryInfo(t *testing.T) {
+	testCases := []struct {
+		desc  string
+		err   error
+		delay time.Duration
+	}{
+		{"kite error", kiteErrors.TooManyRequests("quota exceeded").WithRetryAfter(time.Minute), time.Minute},
+		{"http error", kiteHTTP.ErrorServiceUnavailable{RetryIn: 5 * time.Second}, 5 * time.Second},
+		{"no delay", kiteErrors.Unavailable("down"), 0},
+	}
+
+	for i, tc := range testCases {
+		var delay time.Duration
+
+		for _, d := range status.Convert(ToStatus(tc.err)).Details() {
+			if info, ok := d.(*errdetails.RetryInfo); ok {
+				delay = info.GetRetryDelay().AsDuration()
+			}
+		}
+
+		assert.Equal(t, tc.delay, delay, "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestGRPCCode(t *testing.T) {
+	testCases := []struct {
+		statusCode int
+		code       codes.Code
+	}{
+		{401, codes.Unauthenticated},
+		{409, codes.AlreadyExists},
+		{418, codes.InvalidArgument},
+		{501, codes.Unimplemented},
+		{502, codes.Internal},
+		{504, codes.DeadlineExceeded},
+	}
+
+	for i, tc := range testCases {
+		assert.Equal(t, tc.code, grpcCode(tc.statusCode), "TEST[%d], Failed.\n%d", i, tc.statusCode)
+	}
+}
+
+func TestErrorInterceptor(t *testing.T) {
+	interceptor := ErrorInterceptor()
+
+	resp, err := interceptor(t.Context(), "req", &grpc.UnaryServerInfo{}, func(context.Context, any) (any, error) {
+		return nil, kiteErrors.NotFound("order not found")
+	})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	resp, err = interceptor(t.Context(), "req", &grpc.UnaryServerInfo{}, func(context.Context, any) (any, error) {
+		return "ok", nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+}
+
+func TestStreamErrorInterceptor(t *testing.T) {
+	interceptor := StreamErrorInterceptor()
+
+	err := interceptor(nil, nil, &grpc.StreamServerInfo{}, func(any, grpc.ServerStream) error {
+		return kiteErrors.Unavailable("try later")
+	})
+
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	err = interceptor(nil, nil, &grpc.StreamServerInfo{}, func(any, grpc.ServerStream) error {
+		return nil
+	})
+
+	assert.NoError(t, err)
+}
+
+func errorInfo(st *status.Status) *errdetails.ErrorInfo {
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			return info
+		}
+	}
+
+	return nil
+}
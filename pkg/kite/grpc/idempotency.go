@@ -0,0 +1,260 @@
+package grpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/sllt/kite/pkg/kite/http/middleware"
+)
+
+const (
+	// IdempotencyKeyMetadata is the default metadata key carrying the idempotency key of a call.
+	IdempotencyKeyMetadata = "idempotency-key"
+
+	// IdempotentReplayedMetadata is set in the header of the responses replayed from the idempotency store.
+	IdempotentReplayedMetadata = "idempotent-replayed"
+
+	defaultIdempotencyTTL     = 24 * time.Hour
+	defaultIdempotencyLockTTL = 30 * time.Second
+	maxIdempotencyKeyLength   = 255
+
+	// idempotencyMessageType is the header of the stored responses holding the full name of the response message,
+	// to decode it when it is replayed.
+	idempotencyMessageType = "Grpc-Message-Type"
+)
+
+// replayedCodes are the codes of the errors which are stored and replayed, as a retry would fail the same way. The
+// calls failing with the other codes, e.g. UNAVAILABLE, are handled again when they are retried.
+var replayedCodes = []codes.Code{
+	codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.FailedPrecondition, codes.OutOfRange,
+	codes.Unimplemented,
+}
+
+// IdempotencyConfig holds the configuration of IdempotencyInterceptor.
+type IdempotencyConfig struct {
+	// Store persists the responses and the locks. Defaults to an in-memory store, which is only suitable for
+	// single-instance deployments; use middleware.NewRedisIdempotencyStore when running multiple replicas.
+	Store middleware.IdempotencyStore
+	// TTL is how long a stored response is replayed for retries. Defaults to 24 hours.
+	TTL time.Duration
+	// LockTTL bounds how long a call holds the lock for its key, so that a crashed instance does not block retries
+	// forever. Defaults to 30 seconds.
+	LockTTL time.Duration
+	// Metadata is the metadata key carrying the idempotency key. Defaults to "idempotency-key".
+	Metadata string
+	// Methods restricts the interceptor to these full method names, e.g. "/orders.Orders/Create". Defaults to every
+	// unary method.
+	Methods []string
+	// Required rejects the calls of the methods which do not carry a key.
+	Required bool
+}
+
+// WithIdempotencyKey returns a copy of ctx whose outgoing calls carry key in the "idempotency-key" metadata, for the
+// servers using IdempotencyInterceptor.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, IdempotencyKeyMetadata, key)
+}
+
+// IdempotencyInterceptor deduplicates the unary calls carrying an idempotency key in their metadata, like the
+// middleware.Idempotency HTTP middleware, for the callers which deliver at least once, e.g. the consumers of a queue.
+//
+// The first response of a method and key, or its error when a retry would fail the same way, e.g. INVALID_ARGUMENT,
+// is stored and replayed to the calls with the same key within the TTL, with the "idempotent-replayed: true" header.
+// Concurrent calls with a key which is still being handled fail with ABORTED, and reusing a key with a different
+// request fails with INVALID_ARGUMENT.
+//
+// The interceptor fails closed: if the store cannot be reached the call fails with UNAVAILABLE instead of risking a
+// duplicate side effect.
+func IdempotencyInterceptor(config IdempotencyConfig) grpc.UnaryServerInterceptor {
+	config = config.withDefaults()
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if len(config.Methods) > 0 && !slices.Contains(config.Methods, info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		var key string
+
+		if values := metadata.ValueFromIncomingContext(ctx, config.Metadata); len(values) > 0 {
+			key = strings.TrimSpace(values[0])
+		}
+
+		switch {
+		case key == "" && config.Required:
+			return nil, status.Errorf(codes.InvalidArgument, "missing %s metadata", config.Metadata)
+		case key == "":
+			return handler(ctx, req)
+		case len(key) > maxIdempotencyKeyLength:
+			return nil, status.Errorf(codes.InvalidArgument, "%s metadata is longer than %d characters",
+				config.Metadata, maxIdempotencyKeyLength)
+		}
+
+		fingerprint, err := fingerprintMessage(req)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "cannot fingerprint the request: %v", err)
+		}
+
+		return handleIdempotent(ctx, req, handler, &config, info.FullMethod+" "+key, fingerprint)
+	}
+}
+
+func handleIdempotent(ctx context.Context, req any, handler grpc.UnaryHandler, config *IdempotencyConfig,
+	key, fingerprint string) (any, error) {
+	stored, err := config.Store.Get(ctx, key)
+	if err != nil {
+		return nil, storeUnavailable(err)
+	}
+
+	if stored != nil {
+		return replayIdempotentResponse(ctx, stored, fingerprint)
+	}
+
+	locked, err := config.Store.Lock(ctx, key, config.LockTTL)
+	if err != nil {
+		return nil, storeUnavailable(err)
+	}
+
+	if !locked {
+		return nil, status.Error(codes.Aborted, "a call with the same idempotency key is already being handled")
+	}
+
+	defer func() { _ = config.Store.Unlock(context.WithoutCancel(ctx), key) }()
+
+	// Another call may have completed between the first lookup and acquiring the lock.
+	if stored, err = config.Store.Get(ctx, key); err == nil && stored != nil {
+		return replayIdempotentResponse(ctx, stored, fingerprint)
+	}
+
+	resp, err := handler(ctx, req)
+
+	if stored := storableResponse(resp, err, fingerprint); stored != nil {
+		_ = config.Store.Save(context.WithoutCancel(ctx), key, stored, config.TTL)
+	}
+
+	return resp, err
+}
+
+// storableResponse returns the response to store for the result of a call, or nil when the call must be handled
+// again when it is retried.
+func storableResponse(resp any, err error, fingerprint string) *middleware.IdempotentResponse {
+	if err != nil {
+		st := status.Convert(ToStatus(err))
+		if !slices.Contains(replayedCodes, st.Code()) {
+			return nil
+		}
+
+		body, marshalErr := proto.Marshal(st.Proto())
+		if marshalErr != nil {
+			return nil
+		}
+
+		return &middleware.IdempotentResponse{StatusCode: int(st.Code()), Body: body, Fingerprint: fingerprint}
+	}
+
+	msg, ok := resp.(proto.Message)
+	if !ok {
+		return nil
+	}
+
+	body, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	if err != nil {
+		return nil
+	}
+
+	return &middleware.IdempotentResponse{
+		StatusCode:  int(codes.OK),
+		Header:      http.Header{idempotencyMessageType: {string(msg.ProtoReflect().Descriptor().FullName())}},
+		Body:        body,
+		Fingerprint: fingerprint,
+	}
+}
+
+// replayIdempotentResponse decodes the stored response, or error, of a call.
+func replayIdempotentResponse(ctx context.Context, stored *middleware.IdempotentResponse,
+	fingerprint string) (any, error) {
+	if stored.Fingerprint != "" && stored.Fingerprint != fingerprint {
+		return nil, status.Error(codes.InvalidArgument,
+			"the idempotency key was already used with a different request")
+	}
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs(IdempotentReplayedMetadata, "true"))
+
+	if codes.Code(stored.StatusCode) != codes.OK { //nolint:gosec // the stored codes are gRPC codes.
+		var st spb.Status
+
+		if err := proto.Unmarshal(stored.Body, &st); err != nil {
+			return nil, status.Errorf(codes.Internal, "cannot decode the stored error: %v", err)
+		}
+
+		return nil, status.ErrorProto(&st)
+	}
+
+	messageType, err := protoregistry.GlobalTypes.FindMessageByName(
+		protoreflect.FullName(stored.Header.Get(idempotencyMessageType)))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot decode the stored response: %v", err)
+	}
+
+	msg := messageType.New().Interface()
+
+	if err := proto.Unmarshal(stored.Body, msg); err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot decode the stored response: %v", err)
+	}
+
+	return msg, nil
+}
+
+// fingerprintMessage hashes the request so that the reuse of a key with a different request can be detected.
+func fingerprintMessage(req any) (string, error) {
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return "", nil
+	}
+
+	body, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(body)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func storeUnavailable(err error) error {
+	return status.Errorf(codes.Unavailable, "idempotency store unavailable: %v", err)
+}
+
+func (c IdempotencyConfig) withDefaults() IdempotencyConfig {
+	if c.Store == nil {
+		c.Store = middleware.NewMemoryIdempotencyStore()
+	}
+
+	if c.TTL <= 0 {
+		c.TTL = defaultIdempotencyTTL
+	}
+
+	if c.LockTTL <= 0 {
+		c.LockTTL = defaultIdempotencyLockTTL
+	}
+
+	if c.Metadata == "" {
+		c.Metadata = IdempotencyKeyMetadata
+	}
+
+	return c
+}
@@ -0,0 +1,133 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/sllt/kite/pkg/kite/http/middleware"
+)
+
+var errStoreDown = errors.New("connection refused")
+
+// failingIdempotencyStore is an idempotency store which cannot be reached.
+type failingIdempotencyStore struct {
+	middleware.IdempotencyStore
+}
+
+func (failingIdempotencyStore) Get(context.Context, string) (*middleware.IdempotentResponse, error) {
+	return nil, errStoreDown
+}
+
+func idempotentCall(key string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(IdempotencyKeyMetadata, key))
+}
+
+func TestIdempotencyInterceptor(t *testing.T) {
+	interceptor := IdempotencyInterceptor(IdempotencyConfig{})
+	info := &grpc.UnaryServerInfo{FullMethod: "/orders.Orders/Create"}
+
+	calls := 0
+	handler := func(_ context.Context, req any) (any, error) {
+		calls++
+
+		return wrapperspb.String("order of " + req.(*wrapperspb.StringValue).GetValue()), nil
+	}
+
+	first, err := interceptor(idempotentCall("k1"), wrapperspb.String("ann"), info, handler)
+	require.NoError(t, err)
+
+	replayed, err := interceptor(idempotentCall("k1"), wrapperspb.String("ann"), info, handler)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, calls, "the retry is not handled again")
+	assert.True(t, proto.Equal(first.(proto.Message), replayed.(proto.Message)))
+
+	_, err = interceptor(idempotentCall("k1"), wrapperspb.String("bob"), info, handler)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "the key is reused with a different request")
+
+	_, err = interceptor(context.Background(), wrapperspb.String("ann"), info, handler)
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls, "the calls without a key are not deduplicated")
+}
+
+func TestIdempotencyInterceptor_Errors(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		err      error
+		replayed bool
+	}{
+		{desc: "client error", err: status.Error(codes.NotFound, "no such customer"), replayed: true},
+		{desc: "server error", err: status.Error(codes.Unavailable, "database down")},
+		{desc: "internal error", err: errors.New("unexpected")},
+	}
+
+	for i, tc := range testCases {
+		interceptor := IdempotencyInterceptor(IdempotencyConfig{})
+		info := &grpc.UnaryServerInfo{FullMethod: "/orders.Orders/Create"}
+
+		calls := 0
+		handler := func(context.Context, any) (any, error) {
+			calls++
+
+			return nil, tc.err
+		}
+
+		_, err := interceptor(idempotentCall("k1"), wrapperspb.String("ann"), info, handler)
+		require.Error(t, err, "TEST[%d], Failed.\n%s", i, tc.desc)
+
+		_, retryErr := interceptor(idempotentCall("k1"), wrapperspb.String("ann"), info, handler)
+
+		assert.Equal(t, status.Code(tc.err), status.Code(retryErr), "TEST[%d], Failed.\n%s", i, tc.desc)
+
+		if tc.replayed {
+			assert.Equal(t, 1, calls, "TEST[%d], Failed.\n%s", i, tc.desc)
+		} else {
+			assert.Equal(t, 2, calls, "TEST[%d], Failed.\n%s", i, tc.desc)
+		}
+	}
+}
+
+func TestIdempotencyInterceptor_Config(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/orders.Orders/Create"}
+	handler := func(context.Context, any) (any, error) { return wrapperspb.String("ok"), nil }
+
+	testCases := []struct {
+		desc   string
+		config IdempotencyConfig
+		ctx    context.Context
+		code   codes.Code
+	}{
+		{desc: "required", config: IdempotencyConfig{Required: true}, ctx: context.Background(),
+			code: codes.InvalidArgument},
+		{desc: "other method", config: IdempotencyConfig{Required: true, Methods: []string{"/orders.Orders/Cancel"}},
+			ctx: context.Background(), code: codes.OK},
+		{desc: "store unavailable", config: IdempotencyConfig{Store: failingIdempotencyStore{}}, ctx: idempotentCall("k1"),
+			code: codes.Unavailable},
+		{desc: "key too long", config: IdempotencyConfig{}, ctx: idempotentCall(strings.Repeat("k", 256)),
+			code: codes.InvalidArgument},
+	}
+
+	for i, tc := range testCases {
+		_, err := IdempotencyInterceptor(tc.config)(tc.ctx, wrapperspb.String("ann"), info, handler)
+
+		assert.Equal(t, tc.code, status.Code(err), "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestWithIdempotencyKey(t *testing.T) {
+	md, ok := metadata.FromOutgoingContext(WithIdempotencyKey(t.Context(), "k1"))
+
+	require.True(t, ok)
+	assert.Equal(t, []string{"k1"}, md.Get(IdempotencyKeyMetadata))
+}
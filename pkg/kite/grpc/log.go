@@ -37,6 +37,8 @@ type Logger interface {
 }
 
 type Metrics interface {
+	IncrementCounter(ctx context.Context, name string, labels ...string)
+	DeltaUpDownCounter(ctx context.Context, name string, value float64, labels ...string)
 	RecordHistogram(ctx context.Context, name string, value float64, labels ...string)
 }
 
@@ -100,12 +102,20 @@ func StreamObservabilityInterceptor(logger Logger, metrics Metrics) grpc.StreamS
 
 		defer span.End()
 
-		// Wrap the stream to propagate context with tracing
+		// An unknown service handler is invoked without a service implementation.
+		labels := newMethodLabels(info.FullMethod, streamRPCType(info), srv != nil)
+
+		// Wrap the stream to propagate context with tracing and record message sizes
 		wrappedStream := &wrappedServerStream{
 			ServerStream: ss,
 			ctx:          ctx,
+			metrics:      metrics,
+			labels:       labels,
 		}
 
+		trackActiveStream(ctx, metrics, labels, 1)
+		defer trackActiveStream(ctx, metrics, labels, -1)
+
 		// Process the stream
 		err := handler(srv, wrappedStream)
 
@@ -113,6 +123,7 @@ func StreamObservabilityInterceptor(logger Logger, metrics Metrics) grpc.StreamS
 
 		// Log and record metrics
 		logStreamRPC(ctx, logger, metrics, start, err, grpcMethodName, streamType, "app_gRPC-Stream_stats")
+		recordMethodMetrics(ctx, metrics, labels, time.Since(start), err)
 
 		return err
 	}
@@ -137,26 +148,53 @@ func getStreamTypeAndMethod(info *grpc.StreamServerInfo) (streamType, methodName
 	return streamType, methodName
 }
 
-// wrappedServerStream propagates context with tracing for streaming RPCs.
+// wrappedServerStream propagates context with tracing for streaming RPCs and records the size of
+// the messages sent and received on the stream.
 type wrappedServerStream struct {
 	grpc.ServerStream
-	ctx context.Context
+	ctx     context.Context
+	metrics Metrics
+	labels  methodLabels
 }
 
 func (w *wrappedServerStream) Context() context.Context {
 	return w.ctx
 }
 
+func (w *wrappedServerStream) SendMsg(m any) error {
+	err := w.ServerStream.SendMsg(m)
+	if err == nil {
+		recordMessageSize(w.ctx, w.metrics, msgSentMetric, w.labels, m)
+	}
+
+	return err
+}
+
+func (w *wrappedServerStream) RecvMsg(m any) error {
+	err := w.ServerStream.RecvMsg(m)
+	if err == nil {
+		recordMessageSize(w.ctx, w.metrics, msgReceivedMetric, w.labels, m)
+	}
+
+	return err
+}
+
 func ObservabilityInterceptor(logger Logger, metrics Metrics) grpc.UnaryServerInterceptor {
 	tracer := otel.GetTracerProvider().Tracer("kite", trace.WithInstrumentationVersion("v0.1"))
 
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
 		start := time.Now()
+		labels := newMethodLabels(info.FullMethod, rpcTypeUnary, true)
 
 		ctx = initializeSpanContext(ctx)
 		ctx, span := tracer.Start(ctx, info.FullMethod)
 
+		recordMessageSize(ctx, metrics, msgReceivedMetric, labels, req)
+
 		resp, err := handler(ctx, req)
+		if err == nil {
+			recordMessageSize(ctx, metrics, msgSentMetric, labels, resp)
+		}
 		if err != nil {
 			logger.Errorf("error while handling gRPC request to method %q: %q", info.FullMethod, err)
 		}
@@ -169,6 +207,7 @@ func ObservabilityInterceptor(logger Logger, metrics Metrics) grpc.UnaryServerIn
 		}
 
 		logRPC(ctx, logger, metrics, start, err, info.FullMethod, "app_gRPC-Server_stats")
+		recordMethodMetrics(ctx, metrics, labels, time.Since(start), err)
 
 		span.End()
 
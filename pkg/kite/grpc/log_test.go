@@ -205,6 +205,17 @@ func createMocks(t *testing.T) (*infra.MockLogger, *infra.MockMetrics, *gomock.C
 	return mockLogger, mockMetrics, ctrl
 }
 
+// expectMethodMetrics sets up expectations for the per-method metrics recorded by the interceptors.
+func expectMethodMetrics(mockMetrics *infra.MockMetrics, stream bool) {
+	mockMetrics.EXPECT().IncrementCounter(gomock.Any(), requestsTotalMetric, gomock.Any()).Times(1)
+	mockMetrics.EXPECT().RecordHistogram(gomock.Any(), durationMetric, gomock.Any(), gomock.Any()).Times(1)
+
+	if stream {
+		mockMetrics.EXPECT().DeltaUpDownCounter(gomock.Any(), activeStreamsMetric, 1.0, gomock.Any()).Times(1)
+		mockMetrics.EXPECT().DeltaUpDownCounter(gomock.Any(), activeStreamsMetric, -1.0, gomock.Any()).Times(1)
+	}
+}
+
 func TestGRPCLog_DocumentRPCLog(t *testing.T) {
 	mockLogger, mockMetrics, ctrl := createMocks(t)
 	defer ctrl.Finish()
@@ -241,7 +252,8 @@ func TestObservabilityInterceptor(t *testing.T) {
 
 	// Set up expectations
 	mockLogger.EXPECT().Info(gomock.Any()).Times(1)
-	mockMetrics.EXPECT().RecordHistogram(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+	mockMetrics.EXPECT().RecordHistogram(gomock.Any(), "app_gRPC-Server_stats", gomock.Any(), gomock.Any()).Times(1)
+	expectMethodMetrics(mockMetrics, false)
 
 	resp, err := interceptor(ctx, req, info, handler)
 
@@ -268,7 +280,8 @@ func TestObservabilityInterceptor_WithError(t *testing.T) {
 	// Set up expectations - the function logs errors with Errorf and then with Info
 	mockLogger.EXPECT().Errorf(gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
 	mockLogger.EXPECT().Info(gomock.Any()).Times(1)
-	mockMetrics.EXPECT().RecordHistogram(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+	mockMetrics.EXPECT().RecordHistogram(gomock.Any(), "app_gRPC-Server_stats", gomock.Any(), gomock.Any()).Times(1)
+	expectMethodMetrics(mockMetrics, false)
 
 	resp, err := interceptor(ctx, req, info, handler)
 
@@ -296,7 +309,15 @@ func TestObservabilityInterceptor_HealthCheck(t *testing.T) {
 
 	// Set up expectations
 	mockLogger.EXPECT().Info(gomock.Any()).Times(1)
-	mockMetrics.EXPECT().RecordHistogram(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+	mockMetrics.EXPECT().RecordHistogram(gomock.Any(), "app_gRPC-Server_stats", gomock.Any(), gomock.Any()).Times(1)
+	mockMetrics.EXPECT().RecordHistogram(gomock.Any(), msgReceivedMetric, gomock.Any(),
+		"service", "grpc.health.v1.Health", "method", "Check", "type", rpcTypeUnary).Times(1)
+	mockMetrics.EXPECT().RecordHistogram(gomock.Any(), msgSentMetric, gomock.Any(),
+		"service", "grpc.health.v1.Health", "method", "Check", "type", rpcTypeUnary).Times(1)
+	mockMetrics.EXPECT().IncrementCounter(gomock.Any(), requestsTotalMetric,
+		"service", "grpc.health.v1.Health", "method", "Check", "type", rpcTypeUnary, "code", "OK").Times(1)
+	mockMetrics.EXPECT().RecordHistogram(gomock.Any(), durationMetric, gomock.Any(),
+		"service", "grpc.health.v1.Health", "method", "Check", "type", rpcTypeUnary).Times(1)
 
 	resp, err := interceptor(ctx, req, info, handler)
 
@@ -322,7 +343,8 @@ func TestStreamObservabilityInterceptor(t *testing.T) {
 
 	// Set up expectations
 	mockLogger.EXPECT().Info(gomock.Any()).Times(1)
-	mockMetrics.EXPECT().RecordHistogram(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+	mockMetrics.EXPECT().RecordHistogram(gomock.Any(), "app_gRPC-Stream_stats", gomock.Any(), gomock.Any()).Times(1)
+	expectMethodMetrics(mockMetrics, true)
 
 	err := interceptor(nil, &mockServerStream{}, info, handler)
 
@@ -347,7 +369,8 @@ func TestStreamObservabilityInterceptor_ClientStream(t *testing.T) {
 
 	// Set up expectations
 	mockLogger.EXPECT().Info(gomock.Any()).Times(1)
-	mockMetrics.EXPECT().RecordHistogram(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+	mockMetrics.EXPECT().RecordHistogram(gomock.Any(), "app_gRPC-Stream_stats", gomock.Any(), gomock.Any()).Times(1)
+	expectMethodMetrics(mockMetrics, true)
 
 	err := interceptor(nil, &mockServerStream{}, info, handler)
 
@@ -372,7 +395,8 @@ func TestStreamObservabilityInterceptor_BidirectionalStream(t *testing.T) {
 
 	// Set up expectations
 	mockLogger.EXPECT().Info(gomock.Any()).Times(1)
-	mockMetrics.EXPECT().RecordHistogram(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+	mockMetrics.EXPECT().RecordHistogram(gomock.Any(), "app_gRPC-Stream_stats", gomock.Any(), gomock.Any()).Times(1)
+	expectMethodMetrics(mockMetrics, true)
 
 	err := interceptor(nil, &mockServerStream{}, info, handler)
 
@@ -397,7 +421,8 @@ func TestStreamObservabilityInterceptor_WithError(t *testing.T) {
 
 	// Set up expectations
 	mockLogger.EXPECT().Info(gomock.Any()).Times(1)
-	mockMetrics.EXPECT().RecordHistogram(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+	mockMetrics.EXPECT().RecordHistogram(gomock.Any(), "app_gRPC-Stream_stats", gomock.Any(), gomock.Any()).Times(1)
+	expectMethodMetrics(mockMetrics, true)
 
 	err := interceptor(nil, &mockServerStream{}, info, handler)
 
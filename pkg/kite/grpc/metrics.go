@@ -0,0 +1,101 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Per-method gRPC server metrics. They are registered by the kite package when the gRPC server is created.
+const (
+	requestsTotalMetric = "grpc_server_requests_total"
+	durationMetric      = "grpc_server_request_duration_ms"
+	msgReceivedMetric   = "grpc_server_msg_received_bytes"
+	msgSentMetric       = "grpc_server_msg_sent_bytes"
+	activeStreamsMetric = "grpc_server_active_streams"
+
+	unknownLabel = "unknown"
+
+	rpcTypeUnary        = "unary"
+	rpcTypeClientStream = "client_stream"
+	rpcTypeServerStream = "server_stream"
+	rpcTypeBidiStream   = "bidi_stream"
+)
+
+// methodLabels holds the label values describing an RPC. Service and method are derived from the
+// registered method only, so that the metric cardinality stays bounded.
+type methodLabels struct {
+	service string
+	method  string
+	rpcType string
+}
+
+// newMethodLabels splits a full method name of the form "/package.Service/Method".
+// Malformed names and RPCs served by an unknown service handler are reported as "unknown".
+func newMethodLabels(fullMethod, rpcType string, registered bool) methodLabels {
+	l := methodLabels{service: unknownLabel, method: unknownLabel, rpcType: rpcType}
+
+	if !registered {
+		return l
+	}
+
+	service, method, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok || service == "" || method == "" || strings.Contains(method, "/") {
+		return l
+	}
+
+	l.service, l.method = service, method
+
+	return l
+}
+
+func streamRPCType(info *grpc.StreamServerInfo) string {
+	switch {
+	case info.IsClientStream && info.IsServerStream:
+		return rpcTypeBidiStream
+	case info.IsClientStream:
+		return rpcTypeClientStream
+	default:
+		return rpcTypeServerStream
+	}
+}
+
+// recordMethodMetrics records the request counter and latency histogram for a finished RPC.
+func recordMethodMetrics(ctx context.Context, metrics Metrics, l methodLabels, duration time.Duration, err error) {
+	if metrics == nil {
+		return
+	}
+
+	durationMs := float64(duration.Milliseconds()) + float64(duration.Nanoseconds()%nanosecondsPerMillisecond)/nanosecondsPerMillisecond
+
+	metrics.IncrementCounter(ctx, requestsTotalMetric,
+		"service", l.service, "method", l.method, "type", l.rpcType, "code", status.Code(err).String())
+	metrics.RecordHistogram(ctx, durationMetric, durationMs,
+		"service", l.service, "method", l.method, "type", l.rpcType)
+}
+
+// recordMessageSize records the size of a protobuf message. Messages which are not protobuf messages are ignored.
+func recordMessageSize(ctx context.Context, metrics Metrics, name string, l methodLabels, msg any) {
+	if metrics == nil {
+		return
+	}
+
+	m, ok := msg.(proto.Message)
+	if !ok {
+		return
+	}
+
+	metrics.RecordHistogram(ctx, name, float64(proto.Size(m)), "service", l.service, "method", l.method, "type", l.rpcType)
+}
+
+func trackActiveStream(ctx context.Context, metrics Metrics, l methodLabels, delta float64) {
+	if metrics == nil {
+		return
+	}
+
+	metrics.DeltaUpDownCounter(ctx, activeStreamsMetric, delta, "service", l.service, "method", l.method, "type", l.rpcType)
+}
@@ -0,0 +1,59 @@
+package grpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func Test_newMethodLabels(t *testing.T) {
+	tests := []struct {
+		desc       string
+		fullMethod string
+		registered bool
+		service    string
+		method     string
+	}{
+		{"registered method", "/test.Service/Method", true, "test.Service", "Method"},
+		{"unknown service handler", "/random.Service/Method", false, unknownLabel, unknownLabel},
+		{"missing method", "/test.Service", true, unknownLabel, unknownLabel},
+		{"extra path segments", "/test.Service/Method/extra", true, unknownLabel, unknownLabel},
+		{"empty", "", true, unknownLabel, unknownLabel},
+	}
+
+	for i, tc := range tests {
+		l := newMethodLabels(tc.fullMethod, rpcTypeUnary, tc.registered)
+
+		assert.Equal(t, tc.service, l.service, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, tc.method, l.method, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, rpcTypeUnary, l.rpcType, "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func Test_streamRPCType(t *testing.T) {
+	assert.Equal(t, rpcTypeBidiStream, streamRPCType(&grpc.StreamServerInfo{IsClientStream: true, IsServerStream: true}))
+	assert.Equal(t, rpcTypeClientStream, streamRPCType(&grpc.StreamServerInfo{IsClientStream: true}))
+	assert.Equal(t, rpcTypeServerStream, streamRPCType(&grpc.StreamServerInfo{IsServerStream: true}))
+}
+
+func TestWrappedServerStream_RecordsMessageSizes(t *testing.T) {
+	_, mockMetrics, ctrl := createMocks(t)
+	defer ctrl.Finish()
+
+	labels := newMethodLabels("/test.Service/Stream", rpcTypeServerStream, true)
+	msg := &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}
+
+	mockMetrics.EXPECT().RecordHistogram(gomock.Any(), msgSentMetric, 2.0,
+		"service", "test.Service", "method", "Stream", "type", rpcTypeServerStream).Times(1)
+
+	stream := &wrappedServerStream{ServerStream: &mockServerStream{}, ctx: t.Context(), metrics: mockMetrics, labels: labels}
+
+	require.NoError(t, stream.SendMsg(msg))
+
+	// Messages which are not protobuf messages are not recorded.
+	require.NoError(t, stream.SendMsg("not a proto message"))
+}
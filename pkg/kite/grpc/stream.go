@@ -0,0 +1,113 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+
+	kiteErrors "github.com/sllt/kite/pkg/kite/errors"
+)
+
+// Receiver is the receiving side of a client stream, implemented by grpc.ServerStreamingClient and
+// grpc.BidiStreamingClient.
+type Receiver[T any] interface {
+	Recv() (*T, error)
+}
+
+// Sender is the sending side of a client-streaming call, implemented by grpc.ClientStreamingClient.
+type Sender[Req, Res any] interface {
+	Send(*Req) error
+	CloseAndRecv() (*Res, error)
+}
+
+// Stream returns an iterator over the messages of a stream opened by open. The stream is opened when the iteration
+// starts, with a context derived from ctx, so that the deadline of ctx applies to the whole stream, and is closed
+// when ctx is cancelled or the iteration stops, including on an early break.
+//
+// The iteration ends when the server closes the stream. Errors are yielded once, converted to kite errors, and end
+// the iteration.
+func Stream[T any](ctx context.Context, open func(context.Context) (Receiver[T], error)) iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		streamCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		stream, err := open(streamCtx)
+		if err != nil {
+			yield(nil, kiteErrors.FromGRPC(err))
+			return
+		}
+
+		for msg, err := range Messages(streamCtx, stream) {
+			if !yield(msg, err) {
+				return
+			}
+		}
+	}
+}
+
+// Messages returns an iterator over the messages received on an open stream, e.g. the receiving side of a
+// bidirectional stream. It stops when the server closes the stream or ctx is done. Errors are yielded once, converted
+// to kite errors, and end the iteration.
+func Messages[T any](ctx context.Context, stream Receiver[T]) iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(nil, kiteErrors.FromGRPC(err))
+				return
+			}
+
+			msg, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+
+			if err != nil {
+				yield(nil, kiteErrors.FromGRPC(err))
+				return
+			}
+
+			if !yield(msg, nil) {
+				return
+			}
+		}
+	}
+}
+
+// CollectAll receives every message of a stream opened by open, see Stream, and returns them once the server closes
+// the stream. It returns the messages received so far along with the first error.
+func CollectAll[T any](ctx context.Context, open func(context.Context) (Receiver[T], error)) ([]*T, error) {
+	var msgs []*T
+
+	for msg, err := range Stream(ctx, open) {
+		if err != nil {
+			return msgs, err
+		}
+
+		msgs = append(msgs, msg)
+	}
+
+	return msgs, nil
+}
+
+// SendAll sends every request of reqs on a client-streaming call, then closes it and returns the response of the
+// server. Errors are converted to kite errors. The call is cancelled with the context it was opened with.
+func SendAll[Req, Res any](stream Sender[Req, Res], reqs iter.Seq[*Req]) (*Res, error) {
+	for req := range reqs {
+		// Send returns io.EOF when the server ended the call, its status is returned by CloseAndRecv.
+		if err := stream.Send(req); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, kiteErrors.FromGRPC(err)
+		}
+	}
+
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, kiteErrors.FromGRPC(err)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,145 @@
+package grpc
+
+import (
+	"context"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	kiteErrors "github.com/sllt/kite/pkg/kite/errors"
+)
+
+type testMessage struct {
+	ID int
+}
+
+type testReceiver struct {
+	ctx  context.Context
+	msgs []*testMessage
+	err  error
+}
+
+func (r *testReceiver) Recv() (*testMessage, error) {
+	if err := r.ctx.Err(); err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+
+	if len(r.msgs) == 0 {
+		return nil, r.err
+	}
+
+	msg := r.msgs[0]
+	r.msgs = r.msgs[1:]
+
+	return msg, nil
+}
+
+func openTestStream(r *testReceiver, streamCtx *context.Context) func(context.Context) (Receiver[testMessage], error) {
+	return func(ctx context.Context) (Receiver[testMessage], error) {
+		r.ctx = ctx
+
+		if streamCtx != nil {
+			*streamCtx = ctx
+		}
+
+		return r, nil
+	}
+}
+
+func TestCollectAll(t *testing.T) {
+	r := &testReceiver{msgs: []*testMessage{{1}, {2}}, err: io.EOF}
+
+	msgs, err := CollectAll(t.Context(), openTestStream(r, nil))
+
+	require.NoError(t, err)
+	assert.Equal(t, []*testMessage{{1}, {2}}, msgs)
+}
+
+func TestCollectAll_Error(t *testing.T) {
+	r := &testReceiver{msgs: []*testMessage{{1}}, err: status.Error(codes.NotFound, "no such user")}
+
+	msgs, err := CollectAll(t.Context(), openTestStream(r, nil))
+
+	assert.Equal(t, []*testMessage{{1}}, msgs)
+	require.EqualError(t, err, "no such user")
+	assert.Equal(t, kiteErrors.KindNotFound, kiteErrors.KindOf(err))
+}
+
+func TestCollectAll_OpenError(t *testing.T) {
+	msgs, err := CollectAll(t.Context(), func(context.Context) (Receiver[testMessage], error) {
+		return nil, status.Error(codes.Unavailable, "connection refused")
+	})
+
+	assert.Empty(t, msgs)
+	assert.Equal(t, kiteErrors.KindUnavailable, kiteErrors.KindOf(err))
+}
+
+func TestStream_BreakClosesStream(t *testing.T) {
+	var streamCtx context.Context
+
+	r := &testReceiver{msgs: []*testMessage{{1}, {2}, {3}}, err: io.EOF}
+
+	for msg, err := range Stream(t.Context(), openTestStream(r, &streamCtx)) {
+		require.NoError(t, err)
+
+		if msg.ID == 2 {
+			break
+		}
+	}
+
+	require.Error(t, streamCtx.Err(), "the stream context must be cancelled when the loop exits")
+}
+
+func TestStream_DeadlineExceeded(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 0)
+	defer cancel()
+
+	r := &testReceiver{msgs: []*testMessage{{1}}, err: io.EOF}
+
+	msgs, err := CollectAll(ctx, openTestStream(r, nil))
+
+	assert.Empty(t, msgs)
+	assert.Equal(t, kiteErrors.KindUnavailable, kiteErrors.KindOf(err))
+}
+
+type testSender struct {
+	sent []*testMessage
+	err  error
+}
+
+func (s *testSender) Send(m *testMessage) error {
+	s.sent = append(s.sent, m)
+	return s.err
+}
+
+func (s *testSender) CloseAndRecv() (*testMessage, error) {
+	if s.err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid id")
+	}
+
+	return &testMessage{ID: len(s.sent)}, nil
+}
+
+func TestSendAll(t *testing.T) {
+	s := &testSender{}
+
+	res, err := SendAll(s, slices.Values([]*testMessage{{1}, {2}, {3}}))
+
+	require.NoError(t, err)
+	assert.Equal(t, &testMessage{ID: 3}, res)
+}
+
+func TestSendAll_ServerEndsCall(t *testing.T) {
+	s := &testSender{err: io.EOF}
+
+	res, err := SendAll(s, slices.Values([]*testMessage{{1}, {2}}))
+
+	assert.Nil(t, res)
+	assert.Len(t, s.sent, 1)
+	assert.Equal(t, kiteErrors.KindInvalid, kiteErrors.KindOf(err))
+}
@@ -0,0 +1,158 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"buf.build/go/protovalidate"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// pgvAllValidator is implemented by the messages generated by protoc-gen-validate, whose ValidateAll method
+// reports all the violations of the message.
+type pgvAllValidator interface {
+	ValidateAll() error
+}
+
+// pgvValidator is implemented by the messages generated by protoc-gen-validate, whose Validate method reports
+// the first violation of the message.
+type pgvValidator interface {
+	Validate() error
+}
+
+// pgvMultiError is implemented by the errors of the ValidateAll methods generated by protoc-gen-validate.
+type pgvMultiError interface {
+	AllErrors() []error
+}
+
+// pgvFieldError is implemented by the errors of the fields generated by protoc-gen-validate.
+type pgvFieldError interface {
+	Field() string
+	Reason() string
+}
+
+// Validate validates a request message against its validation rules, and returns an INVALID_ARGUMENT status
+// detailing the field violations in an errdetails.BadRequest if it is invalid. It is used by the servers generated
+// by `kite wrap grpc server` before calling the handlers.
+//
+// The messages generated by protoc-gen-validate are validated with their ValidateAll, or Validate, method. The other
+// messages are validated with protovalidate, against their buf.validate rules. Messages without rules are valid.
+func Validate(msg any) error {
+	var err error
+
+	switch m := msg.(type) {
+	case pgvAllValidator:
+		err = m.ValidateAll()
+	case pgvValidator:
+		err = m.Validate()
+	case proto.Message:
+		err = protovalidate.Validate(m)
+	default:
+		return nil
+	}
+
+	if err == nil {
+		return nil
+	}
+
+	return validationStatus(err)
+}
+
+// validationStatus converts a validation error into a gRPC status.
+func validationStatus(err error) error {
+	var (
+		validationErr *protovalidate.ValidationError
+		multiErr      pgvMultiError
+		fieldErr      pgvFieldError
+		violations    []*errdetails.BadRequest_FieldViolation
+	)
+
+	switch {
+	case errors.As(err, &validationErr):
+		for _, v := range validationErr.Violations {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       protovalidate.FieldPathString(v.Proto.GetField()),
+				Description: v.Proto.GetMessage(),
+				Reason:      v.Proto.GetRuleId(),
+			})
+		}
+	case errors.As(err, &multiErr):
+		for _, e := range multiErr.AllErrors() {
+			if errors.As(e, &fieldErr) {
+				violations = append(violations, &errdetails.BadRequest_FieldViolation{
+					Field:       fieldErr.Field(),
+					Description: fieldErr.Reason(),
+				})
+			}
+		}
+	case errors.As(err, &fieldErr):
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       fieldErr.Field(),
+			Description: fieldErr.Reason(),
+		})
+	default:
+		var (
+			compilationErr *protovalidate.CompilationError
+			runtimeErr     *protovalidate.RuntimeError
+		)
+
+		// the rules of the message cannot be evaluated, the request is not at fault.
+		if errors.As(err, &compilationErr) || errors.As(err, &runtimeErr) {
+			return status.Errorf(codes.Internal, "failed to validate request: %v", err)
+		}
+
+		return status.Errorf(codes.InvalidArgument, "invalid request: %v", err)
+	}
+
+	descriptions := make([]string, 0, len(violations))
+	for _, v := range violations {
+		descriptions = append(descriptions, v.GetField()+": "+v.GetDescription())
+	}
+
+	st := status.New(codes.InvalidArgument, "invalid request: "+strings.Join(descriptions, "; "))
+
+	if detailed, detailsErr := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations}); detailsErr == nil {
+		st = detailed
+	}
+
+	return st.Err()
+}
+
+// ValidationInterceptor returns a unary server interceptor which validates the requests with Validate, so that
+// the invalid ones are rejected with INVALID_ARGUMENT before they reach the handler.
+//
+// The servers generated by `kite wrap grpc server` already validate their requests.
+func ValidationInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := Validate(req); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamValidationInterceptor returns a stream server interceptor which validates each message received from the
+// client with Validate. RecvMsg returns the INVALID_ARGUMENT status of the invalid messages.
+func StreamValidationInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validatingServerStream{ServerStream: ss})
+	}
+}
+
+type validatingServerStream struct {
+	grpc.ServerStream
+}
+
+func (s *validatingServerStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	return Validate(m)
+}
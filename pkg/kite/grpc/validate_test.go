@@ -0,0 +1,197 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+var errTestRecv = errors.New("recv failed")
+
+// newSignupRequest builds a message with the following rules, and sets its name:
+//
+//	message SignupRequest {
+//	  string name = 1 [(buf.validate.field).string.min_len = 3];
+//	}
+func newSignupRequest(t *testing.T, name string) proto.Message {
+	t.Helper()
+
+	options := &descriptorpb.FieldOptions{}
+	proto.SetExtension(options, validate.E_Field, validate.FieldRules_builder{
+		String: validate.StringRules_builder{MinLen: proto.Uint64(3)}.Build(),
+	}.Build())
+
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("signup.proto"),
+		Package: proto.String("test"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("SignupRequest"),
+			Field: []*descriptorpb.FieldDescriptorProto{{
+				Name: proto.String("name"), JsonName: proto.String("name"), Number: proto.Int32(1),
+				Label:   descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:    descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				Options: options,
+			}},
+		}},
+	}
+
+	fd, err := protodesc.NewFile(file, nil)
+	require.NoError(t, err)
+
+	desc := fd.Messages().ByName("SignupRequest")
+	msg := dynamicpb.NewMessage(desc)
+	msg.Set(desc.Fields().ByName("name"), protoreflect.ValueOfString(name))
+
+	return msg
+}
+
+// pgvFieldErr mimics the field errors generated by protoc-gen-validate.
+type pgvFieldErr struct {
+	field, reason string
+}
+
+func (e pgvFieldErr) Error() string  { return "invalid " + e.field + ": " + e.reason }
+func (e pgvFieldErr) Field() string  { return e.field }
+func (e pgvFieldErr) Reason() string { return e.reason }
+
+// pgvMultiErr mimics the errors of the ValidateAll methods generated by protoc-gen-validate.
+type pgvMultiErr []error
+
+func (m pgvMultiErr) Error() string      { return errors.Join(m...).Error() }
+func (m pgvMultiErr) AllErrors() []error { return m }
+
+type pgvAllRequest struct{ err error }
+
+func (r pgvAllRequest) ValidateAll() error { return r.err }
+func (pgvAllRequest) Validate() error      { return errors.New("must not be called") }
+
+type pgvRequest struct{ err error }
+
+func (r pgvRequest) Validate() error { return r.err }
+
+func fieldViolations(t *testing.T, err error) []*errdetails.BadRequest_FieldViolation {
+	t.Helper()
+
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+
+	for _, d := range st.Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			return br.GetFieldViolations()
+		}
+	}
+
+	require.Fail(t, "missing BadRequest details")
+
+	return nil
+}
+
+func TestValidate_Protovalidate(t *testing.T) {
+	require.NoError(t, Validate(newSignupRequest(t, "alice")))
+
+	err := Validate(newSignupRequest(t, "al"))
+
+	violations := fieldViolations(t, err)
+	require.Len(t, violations, 1)
+	assert.Equal(t, "name", violations[0].GetField())
+	assert.Equal(t, "string.min_len", violations[0].GetReason())
+	assert.NotEmpty(t, violations[0].GetDescription())
+	assert.Contains(t, status.Convert(err).Message(), "name: ")
+}
+
+func TestValidate_MessageWithoutRules(t *testing.T) {
+	require.NoError(t, Validate(&grpc_health_v1.HealthCheckRequest{}))
+	require.NoError(t, Validate("not a message"))
+}
+
+func TestValidate_ProtocGenValidate(t *testing.T) {
+	require.NoError(t, Validate(pgvAllRequest{}))
+	require.NoError(t, Validate(pgvRequest{}))
+
+	violations := fieldViolations(t, Validate(pgvAllRequest{err: pgvMultiErr{
+		pgvFieldErr{field: "Name", reason: "value length must be at least 3 runes"},
+		pgvFieldErr{field: "Email", reason: "value must be a valid email address"},
+	}}))
+	require.Len(t, violations, 2)
+	assert.Equal(t, "Name", violations[0].GetField())
+	assert.Equal(t, "value length must be at least 3 runes", violations[0].GetDescription())
+	assert.Equal(t, "Email", violations[1].GetField())
+
+	violations = fieldViolations(t, Validate(pgvRequest{err: pgvFieldErr{field: "Name", reason: "too short"}}))
+	require.Len(t, violations, 1)
+	assert.Equal(t, "Name", violations[0].GetField())
+
+	err := Validate(pgvRequest{err: errors.New("bad request")})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "bad request")
+}
+
+func TestValidationInterceptor(t *testing.T) {
+	interceptor := ValidationInterceptor()
+	called := false
+
+	handler := func(context.Context, any) (any, error) {
+		called = true
+
+		return "ok", nil
+	}
+
+	_, err := interceptor(t.Context(), newSignupRequest(t, "al"), &grpc.UnaryServerInfo{}, handler)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.False(t, called, "the handler must not be called with an invalid request")
+
+	resp, err := interceptor(t.Context(), newSignupRequest(t, "alice"), &grpc.UnaryServerInfo{}, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+	assert.True(t, called)
+}
+
+// recvServerStream is a server stream receiving the given requests.
+type recvServerStream struct {
+	grpc.ServerStream
+	requests []error
+}
+
+func (s *recvServerStream) RecvMsg(m any) error {
+	err := s.requests[0]
+	s.requests = s.requests[1:]
+
+	if err != nil {
+		return err
+	}
+
+	*(m.(*pgvRequest)) = pgvRequest{err: pgvFieldErr{field: "Name", reason: "too short"}}
+
+	return nil
+}
+
+func TestStreamValidationInterceptor(t *testing.T) {
+	ss := &recvServerStream{requests: []error{nil, errTestRecv}}
+
+	err := StreamValidationInterceptor()(nil, ss, &grpc.StreamServerInfo{}, func(_ any, stream grpc.ServerStream) error {
+		var req pgvRequest
+
+		err := stream.RecvMsg(&req)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		return stream.RecvMsg(&req)
+	})
+
+	assert.ErrorIs(t, err, errTestRecv)
+}
@@ -0,0 +1,197 @@
+package kite
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/sllt/kite/pkg/kite/config"
+	"github.com/sllt/kite/pkg/kite/logging"
+)
+
+const (
+	defaultGRPCHealthCheckInterval = 10 * time.Second
+	defaultGRPCHealthHysteresis    = 30 * time.Second
+)
+
+// GRPCHealthServer sets the serving status of the services in a gRPC health server, it is implemented by
+// *health.Server of google.golang.org/grpc/health.
+type GRPCHealthServer interface {
+	SetServingStatus(service string, servingStatus healthpb.HealthCheckResponse_ServingStatus)
+}
+
+// grpcHealthMonitor updates the serving status of the gRPC services from the health of the dependencies of the
+// container. A service is NOT_SERVING when one of its dependencies is down, the status only flips once the health of
+// the dependencies has been stable for the hysteresis window, so that a single failed check does not make clients
+// drop the server.
+type grpcHealthMonitor struct {
+	interval   time.Duration
+	hysteresis time.Duration
+	logger     logging.Logger
+	now        func() time.Time
+
+	mu       sync.Mutex
+	server   GRPCHealthServer
+	services map[string]*grpcServiceHealth
+	// draining is set once the application drains before shutting down, see App.drain.
+	draining bool
+}
+
+type grpcServiceHealth struct {
+	// dependencies of the service, all the dependencies of the container if empty.
+	dependencies []string
+	serving      bool
+	// changingSince is when the health of the dependencies started to disagree with the serving status.
+	changingSince time.Time
+}
+
+func newGRPCHealthMonitor(logger logging.Logger, cfg config.Config) *grpcHealthMonitor {
+	return &grpcHealthMonitor{
+		interval:   getDurationConfig(logger, cfg, "GRPC_HEALTH_CHECK_INTERVAL", defaultGRPCHealthCheckInterval),
+		hysteresis: getDurationConfig(logger, cfg, "GRPC_HEALTH_HYSTERESIS", defaultGRPCHealthHysteresis),
+		logger:     logger,
+		now:        time.Now,
+		services:   make(map[string]*grpcServiceHealth),
+	}
+}
+
+// getDurationConfig reads a duration config, e.g. "10s", returning the default for an empty or invalid value.
+func getDurationConfig(logger logging.Logger, cfg config.Config, key string, defaultValue time.Duration) time.Duration {
+	value := cfg.Get(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		logger.Errorf("invalid %s %q, using the default of %v", key, value, defaultValue)
+
+		return defaultValue
+	}
+
+	return d
+}
+
+// MonitorGRPCHealth makes the serving status of service in the gRPC health server follow the health of the
+// dependencies of the container, e.g. "sql", "redis" or the name of an HTTP service, as reported by the health
+// endpoint. With no dependencies the service depends on all of them. The service is SERVING when registered, use
+// the empty name for the overall status of the server.
+//
+// The dependencies are checked every GRPC_HEALTH_CHECK_INTERVAL (10s by default) while the gRPC server runs, and the
+// status only flips after the dependencies stayed up or down for GRPC_HEALTH_HYSTERESIS (30s by default).
+// The generated health servers register every service with all the dependencies.
+func (a *App) MonitorGRPCHealth(server GRPCHealthServer, service string, dependencies ...string) {
+	if a.grpcHealth == nil {
+		a.grpcHealth = newGRPCHealthMonitor(a.container.Logger, a.Config)
+	}
+
+	a.grpcHealth.add(server, service, dependencies)
+}
+
+func (m *grpcHealthMonitor) add(server GRPCHealthServer, service string, dependencies []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.server = server
+	m.services[service] = &grpcServiceHealth{dependencies: dependencies, serving: true}
+
+	server.SetServingStatus(service, healthpb.HealthCheckResponse_SERVING)
+}
+
+// run checks the dependencies every interval until ctx is done.
+func (m *grpcHealthMonitor) run(ctx context.Context, dependenciesUp func(ctx context.Context) map[string]bool) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.update(dependenciesUp(ctx))
+		}
+	}
+}
+
+// update sets the serving status of the services from the health of the dependencies.
+func (m *grpcHealthMonitor) update(up map[string]bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.draining {
+		return
+	}
+
+	now := m.now()
+
+	for _, name := range m.serviceNames() {
+		s := m.services[name]
+
+		down := downDependencies(up, s.dependencies)
+		healthy := len(down) == 0
+
+		if healthy == s.serving {
+			s.changingSince = time.Time{}
+			continue
+		}
+
+		if s.changingSince.IsZero() {
+			s.changingSince = now
+		}
+
+		if now.Sub(s.changingSince) < m.hysteresis {
+			continue
+		}
+
+		s.serving = healthy
+		s.changingSince = time.Time{}
+
+		if healthy {
+			m.logger.Infof("gRPC service %q is SERVING, its dependencies are up", name)
+			m.server.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+		} else {
+			m.logger.Warnf("gRPC service %q is NOT_SERVING, dependencies down: %v", name, down)
+			m.server.SetServingStatus(name, healthpb.HealthCheckResponse_NOT_SERVING)
+		}
+	}
+}
+
+func (m *grpcHealthMonitor) serviceNames() []string {
+	names := make([]string, 0, len(m.services))
+	for name := range m.services {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// downDependencies returns the dependencies which are down, among all the dependencies if none is given.
+// A dependency which is not registered in the container is not reported as down.
+func downDependencies(up map[string]bool, dependencies []string) []string {
+	var down []string
+
+	if len(dependencies) == 0 {
+		for name, isUp := range up {
+			if !isUp {
+				down = append(down, name)
+			}
+		}
+
+		sort.Strings(down)
+
+		return down
+	}
+
+	for _, name := range dependencies {
+		if isUp, ok := up[name]; ok && !isUp {
+			down = append(down, name)
+		}
+	}
+
+	return down
+}
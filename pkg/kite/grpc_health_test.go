@@ -0,0 +1,99 @@
+package kite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/sllt/kite/pkg/kite/config"
+	"github.com/sllt/kite/pkg/kite/infra"
+	"github.com/sllt/kite/pkg/kite/logging"
+)
+
+type fakeGRPCHealthServer struct {
+	statuses map[string]healthpb.HealthCheckResponse_ServingStatus
+}
+
+func (s *fakeGRPCHealthServer) SetServingStatus(service string, status healthpb.HealthCheckResponse_ServingStatus) {
+	s.statuses[service] = status
+}
+
+func TestApp_MonitorGRPCHealth(t *testing.T) {
+	app := &App{
+		container: infra.NewContainer(config.NewMockConfig(nil)),
+		Config: config.NewMockConfig(map[string]string{
+			"GRPC_HEALTH_CHECK_INTERVAL": "5s",
+			"GRPC_HEALTH_HYSTERESIS":     "invalid",
+		}),
+	}
+
+	server := &fakeGRPCHealthServer{statuses: make(map[string]healthpb.HealthCheckResponse_ServingStatus)}
+
+	app.MonitorGRPCHealth(server, "")
+	app.MonitorGRPCHealth(server, "Orders", "sql")
+
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, server.statuses[""])
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, server.statuses["Orders"])
+	assert.Equal(t, 5*time.Second, app.grpcHealth.interval)
+	assert.Equal(t, defaultGRPCHealthHysteresis, app.grpcHealth.hysteresis)
+}
+
+func TestGRPCHealthMonitor_Hysteresis(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	m := newGRPCHealthMonitor(logging.NewMockLogger(logging.ERROR), config.NewMockConfig(nil))
+	m.now = func() time.Time { return now }
+
+	server := &fakeGRPCHealthServer{statuses: make(map[string]healthpb.HealthCheckResponse_ServingStatus)}
+
+	m.add(server, "", nil)
+	m.add(server, "Orders", []string{"sql"})
+	m.add(server, "Search", []string{"elasticsearch"})
+
+	sqlDown := map[string]bool{"sql": false, "redis": true}
+
+	// the dependency must stay down for the hysteresis window.
+	m.update(sqlDown)
+
+	now = now.Add(20 * time.Second)
+	m.update(sqlDown)
+
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, server.statuses["Orders"])
+
+	now = now.Add(10 * time.Second)
+	m.update(sqlDown)
+
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, server.statuses[""])
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, server.statuses["Orders"])
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, server.statuses["Search"],
+		"dependencies which are not registered are not reported as down")
+
+	// a recovery interrupted by a failure restarts the window.
+	allUp := map[string]bool{"sql": true, "redis": true}
+
+	m.update(allUp)
+
+	now = now.Add(20 * time.Second)
+	m.update(sqlDown)
+
+	now = now.Add(20 * time.Second)
+	m.update(allUp)
+
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, server.statuses["Orders"])
+
+	now = now.Add(30 * time.Second)
+	m.update(allUp)
+
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, server.statuses[""])
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, server.statuses["Orders"])
+}
+
+func TestDownDependencies(t *testing.T) {
+	up := map[string]bool{"sql": false, "redis": true, "mongo": false}
+
+	assert.Equal(t, []string{"mongo", "sql"}, downDependencies(up, nil))
+	assert.Equal(t, []string{"sql"}, downDependencies(up, []string{"sql", "redis", "kafka"}))
+	assert.Empty(t, downDependencies(up, []string{"redis"}))
+}
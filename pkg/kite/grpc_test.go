@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
 
@@ -45,6 +46,11 @@ func setupGRPCMetricExpectations(mockMetrics *infra.MockMetrics) {
 	mockMetrics.EXPECT().IncrementCounter(gomock.Any(), "grpc_server_errors_total").AnyTimes()
 	mockMetrics.EXPECT().IncrementCounter(gomock.Any(), "grpc_services_registered_total").AnyTimes()
 	mockMetrics.EXPECT().RecordHistogram(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockMetrics.EXPECT().NewCounter("grpc_server_requests_total", gomock.Any()).AnyTimes()
+	mockMetrics.EXPECT().NewHistogram(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockMetrics.EXPECT().NewUpDownCounter("grpc_server_active_streams", gomock.Any()).AnyTimes()
+	mockMetrics.EXPECT().IncrementCounter(gomock.Any(), "grpc_server_requests_total", gomock.Any()).AnyTimes()
+	mockMetrics.EXPECT().DeltaUpDownCounter(gomock.Any(), "grpc_server_active_streams", gomock.Any(), gomock.Any()).AnyTimes()
 }
 
 // setupTestGRPCServer creates a mock container and gRPC server for testing.
@@ -120,7 +126,7 @@ func TestGRPCServer_AddUnaryInterceptors(t *testing.T) {
 	interceptors := createTestInterceptors()
 	g.addUnaryInterceptors(interceptors...)
 
-	assert.Len(t, g.interceptors, 4) // 2 default + 2 test interceptors
+	assert.Len(t, g.interceptors, 7) // 5 default + 2 test interceptors
 	assert.False(t, g.serverCreated, "server should not be created yet")
 }
 
@@ -381,6 +387,86 @@ func TestGRPC_Shutdown_BeforeStart(t *testing.T) {
 	assert.NoError(t, err, "Expected shutdown to succeed even if server was not started")
 }
 
+func Test_getGRPCDrainTimeout(t *testing.T) {
+	tests := []struct {
+		desc     string
+		value    string
+		expected time.Duration
+	}{
+		{"not set", "", 0},
+		{"valid duration", "5s", 5 * time.Second},
+		{"invalid duration", "five seconds", 0},
+		{"negative duration", "-1s", 0},
+	}
+
+	for i, tc := range tests {
+		cfg := config.NewMockConfig(map[string]string{"GRPC_DRAIN_TIMEOUT": tc.value})
+
+		timeout := getGRPCDrainTimeout(logging.NewMockLogger(logging.ERROR), cfg)
+
+		assert.Equal(t, tc.expected, timeout, "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestGRPC_ServerShutdown_DrainTimeoutForceCloses(t *testing.T) {
+	port := testutil.GetFreePort(t)
+	started := make(chan struct{})
+
+	desc := &grpc.ServiceDesc{
+		ServiceName: "test.Drain",
+		HandlerType: (*any)(nil),
+		Streams: []grpc.StreamDesc{{
+			StreamName:    "Block",
+			ServerStreams: true,
+			Handler: func(_ any, stream grpc.ServerStream) error {
+				close(started)
+				<-stream.Context().Done()
+
+				return stream.Context().Err()
+			},
+		}},
+	}
+
+	var shutdownErr error
+
+	out := testutil.StdoutOutputForFunc(func() {
+		c, mocks := infra.NewMockContainer(t)
+		setupGRPCMetricExpectations(mocks.Metrics)
+
+		cfg := config.NewMockConfig(map[string]string{"GRPC_DRAIN_TIMEOUT": "100ms"})
+
+		g, err := newGRPCServer(c, port, cfg)
+		require.NoError(t, err)
+		require.NoError(t, g.createServer())
+
+		g.server.RegisterService(desc, struct{}{})
+
+		go g.Run(c)
+
+		time.Sleep(100 * time.Millisecond)
+
+		conn, err := grpc.NewClient(fmt.Sprintf("localhost:%d", port), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		require.NoError(t, err)
+
+		defer conn.Close()
+
+		_, err = conn.NewStream(t.Context(), &grpc.StreamDesc{ServerStreams: true}, "/test.Drain/Block")
+		require.NoError(t, err)
+
+		<-started
+
+		// The shutdown context outlives the drain timeout, so the drain timeout must cut the stream.
+		ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+		defer cancel()
+
+		shutdownErr = g.Shutdown(ctx)
+	})
+
+	require.ErrorIs(t, shutdownErr, context.DeadlineExceeded)
+	assert.Contains(t, out, "draining gRPC server with 1 in-flight RPCs")
+	assert.Contains(t, out, "force closed 1 in-flight RPCs")
+}
+
 func TestGRPC_ServerRun_WithInterceptorAndOptions(t *testing.T) {
 	freePort := testutil.GetFreePort(t)
 	c, _, g := setupTestGRPCServer(t, freePort, false)
@@ -430,7 +516,7 @@ func TestGRPC_ServerRun_WithInterceptorAndOptions(t *testing.T) {
 
 	// Verify that the server was created with the interceptors and options
 	assert.NotNil(t, app.grpcServer.server)
-	assert.Len(t, app.grpcServer.interceptors, 4) // 2 default + 2 test interceptors
+	assert.Len(t, app.grpcServer.interceptors, 7) // 5 default + 2 test interceptors
 	assert.Len(t, app.grpcServer.options, 4)      // 2 test options + 2 default (interceptor) options
 }
 
@@ -478,7 +564,7 @@ func TestApp_InterceptorOrderingWithServiceRegistration(t *testing.T) {
 	time.Sleep(100 * time.Millisecond)
 
 	assert.True(t, g.serverCreated)
-	assert.Len(t, g.interceptors, 3) // 2 default + 1 test
+	assert.Len(t, g.interceptors, 6) // 5 default + 1 test
 
 	// Cleanup
 	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
@@ -41,6 +41,8 @@ type handler struct {
 	function       Handler
 	container      *infra.Container
 	requestTimeout time.Duration
+	errorMode      kiteHTTP.ErrorMode
+	afterHandlers  []AfterHandlerFunc
 }
 
 type ErrorLogEntry struct {
@@ -53,7 +55,10 @@ func (el *ErrorLogEntry) PrettyPrint(writer io.Writer) {
 }
 
 func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	c := newContext(kiteHTTP.NewResponder(w, r.Method), kiteHTTP.NewRequest(r), h.container)
+	responder := kiteHTTP.NewResponder(w, r.Method)
+	responder.SetErrorMode(h.errorMode)
+
+	c := newContext(responder, kiteHTTP.NewRequest(r), h.container)
 
 	traceID := trace.SpanFromContext(r.Context()).SpanContext().TraceID().String()
 
@@ -71,17 +76,25 @@ func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	panicked := make(chan struct{})
 
 	var (
-		result any
-		err    error
+		result, handlerResult any
+		err, handlerErr       error
+		recovered             panicLog
 	)
 
 	go func() {
 		defer func() {
-			panicRecoveryHandler(recover(), h.container.Logger, panicked)
+			panicRecoveryHandler(recover(), h.container.Logger, &recovered, panicked)
 		}()
 		// Execute the handler function
-		result, err = h.function(c)
-		h.logError(traceID, err)
+		handlerResult, handlerErr = h.function(c)
+		handlerResult, handlerErr = runAfterHandlers(c, h.afterHandlers, handlerResult, handlerErr)
+
+		// the errors of the calls canceled with the request, e.g. of the database, are due to the client.
+		if handlerErr != nil && clientDisconnected(r) {
+			handlerErr = kiteHTTP.ErrorClientClosedRequest{}
+		}
+
+		h.logError(traceID, handlerErr)
 		close(done)
 	}()
 
@@ -98,9 +111,22 @@ func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			err = kiteHTTP.ErrorClientClosedRequest{}
 		}
 	case <-done:
+		result, err = handlerResult, handlerErr
+
 		handleWebSocketUpgrade(r)
 	case <-panicked:
 		err = kiteHTTP.ErrorPanicRecovery{}
+
+		if h.errorMode == kiteHTTP.ErrorModeVerbose {
+			err = kiteHTTP.ErrorPanicRecovery{Panic: recovered.Error, StackTrace: recovered.StackTrace}
+		}
+	}
+
+	// Nobody reads the response of a client which is gone: only its status is recorded, for the logs and metrics.
+	if errors.As(err, &kiteHTTP.ErrorClientClosedRequest{}) {
+		w.WriteHeader(kiteHTTP.StatusClientClosedRequest)
+
+		return
 	}
 
 	// Handle custom headers if 'result' is a 'Response'.
@@ -110,6 +136,13 @@ func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	// Handler function completed
 	c.responder.Respond(result, err)
+
+	h.logWriteError(traceID, r, responder.WriteError())
+}
+
+// clientDisconnected reports whether the client of r closed the connection, which cancels the context of r.
+func clientDisconnected(r *http.Request) bool {
+	return errors.Is(r.Context().Err(), context.Canceled)
 }
 
 func healthHandler(c *Context) (any, error) {
@@ -138,16 +171,41 @@ func catchAllHandler(*Context) (any, error) {
 	return nil, kiteHTTP.ErrorInvalidRoute{}
 }
 
-func panicRecoveryHandler(re any, log logging.Logger, panicked chan struct{}) {
+func methodNotAllowedHandler(*Context) (any, error) {
+	return nil, kiteHTTP.ErrorMethodNotAllowed{}
+}
+
+// panicRecoveryHandler stores the recovered panic re in recovered, closes panicked and logs it.
+func panicRecoveryHandler(re any, log logging.Logger, recovered *panicLog, panicked chan struct{}) {
 	if re == nil {
 		return
 	}
 
-	close(panicked)
-	log.Error(panicLog{
+	*recovered = panicLog{
 		Error:      fmt.Sprint(re),
 		StackTrace: string(debug.Stack()),
-	})
+	}
+
+	close(panicked)
+	log.Error(*recovered)
+}
+
+// logWriteError logs a response body not fully written apart from the errors of the handlers, as the response was
+// sent: a client closing the connection, e.g. with a broken pipe, is a warning, and only the failures of the server
+// are errors.
+func (h handler) logWriteError(traceID string, r *http.Request, err *kiteHTTP.WriteError) {
+	if err == nil {
+		return
+	}
+
+	if err.ClientGone() {
+		h.container.Warnf("client closed the connection before receiving the response of %s %s (trace %s): %v",
+			r.Method, r.URL.Path, traceID, err)
+
+		return
+	}
+
+	h.container.Errorf("failed to write the response of %s %s (trace %s): %v", r.Method, r.URL.Path, traceID, err)
 }
 
 // Log the error(if any) with traceID and errorMessage.
@@ -13,6 +13,7 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
 
@@ -70,6 +71,54 @@ func TestHandler_ServeHTTP(t *testing.T) {
 	}
 }
 
+// failingResponseWriter fails to write the bodies with err, without writing any byte.
+type failingResponseWriter struct {
+	*httptest.ResponseRecorder
+	err error
+}
+
+func (w failingResponseWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
+
+func TestHandler_ServeHTTP_WriteError(t *testing.T) {
+	tests := []struct {
+		desc   string
+		err    error
+		stdout string
+		stderr string
+	}{
+		{"client gone", syscall.EPIPE, "client closed the connection before receiving the response of GET /orders", ""},
+		{"server failure", errTest, "", "failed to write the response of GET /orders"},
+	}
+
+	for i, tc := range tests {
+		h := handler{
+			container: &infra.Container{Logger: logging.NewLogger(logging.INFO)},
+			function: func(*Context) (any, error) {
+				return "orders", nil
+			},
+		}
+
+		var stdout string
+
+		stderr := testutil.StderrOutputForFunc(func() {
+			stdout = testutil.StdoutOutputForFunc(func() {
+				w := failingResponseWriter{ResponseRecorder: httptest.NewRecorder(), err: tc.err}
+
+				h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orders", http.NoBody))
+			})
+		})
+
+		assert.Contains(t, stdout, tc.stdout, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Contains(t, stderr, tc.stderr, "TEST[%d], Failed.\n%s", i, tc.desc)
+
+		if tc.stderr == "" {
+			assert.NotContains(t, stderr, "failed to write", "TEST[%d], Failed.\n%s", i, tc.desc)
+		}
+	}
+}
+
 func TestHandler_ServeHTTP_Timeout(t *testing.T) {
 	w := httptest.NewRecorder()
 	r := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
@@ -107,6 +156,35 @@ func TestHandler_ServeHTTP_Panic(t *testing.T) {
 	assert.Contains(t, w.Body.String(), http.StatusText(http.StatusInternalServerError), "TestHandler_ServeHTTP_Panic Failed")
 }
 
+func TestHandler_ServeHTTP_PanicErrorModes(t *testing.T) {
+	testCases := []struct {
+		mode       kiteHTTP.ErrorMode
+		stackTrace bool
+	}{
+		{mode: kiteHTTP.ErrorModeDefault},
+		{mode: kiteHTTP.ErrorModeVerbose, stackTrace: true},
+		{mode: kiteHTTP.ErrorModeStrict},
+	}
+
+	for i, tc := range testCases {
+		w := httptest.NewRecorder()
+
+		h := handler{
+			container: &infra.Container{Logger: logging.NewLogger(logging.FATAL)},
+			errorMode: tc.mode,
+			function:  func(*Context) (any, error) { panic("runtime panic") },
+		}
+
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code, "TEST[%d], Failed.\n%s", i, tc.mode)
+		assert.Equal(t, tc.stackTrace, strings.Contains(w.Body.String(), `"stack_trace":"goroutine`),
+			"TEST[%d], Failed.\n%s", i, tc.mode)
+		assert.Equal(t, tc.stackTrace, strings.Contains(w.Body.String(), `"panic":"runtime panic"`),
+			"TEST[%d], Failed.\n%s", i, tc.mode)
+	}
+}
+
 func TestHandler_ServeHTTP_WithHeaders(t *testing.T) {
 	testCases := []struct {
 		desc       string
@@ -287,7 +365,38 @@ func TestHandler_ServeHTTP_ContextCanceled(t *testing.T) {
 	h.ServeHTTP(w, r)
 
 	assert.Equal(t, 499, w.Code, "Should return HTTP 499 for client closed request")
-	assert.Contains(t, w.Body.String(), "client closed request", "Should contain error message")
+	assert.Empty(t, w.Body.String(), "Should not serialize a response for a client which is gone")
+}
+
+func TestHandler_ServeHTTP_ClientDisconnectedDuringCall(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+
+	ctx, cancel := context.WithCancel(r.Context())
+	r = r.WithContext(ctx)
+
+	out := testutil.StderrOutputForFunc(func() {
+		h := handler{
+			container: &infra.Container{Logger: logging.NewLogger(logging.INFO)},
+			// the request timeout wraps the context of the request, which the client cancels.
+			requestTimeout: time.Minute,
+		}
+
+		h.function = func(c *Context) (any, error) {
+			cancel()
+
+			// a downstream call, e.g. to a database, fails as its context is canceled.
+			<-c.Done()
+
+			return nil, fmt.Errorf("query failed: %w", c.Err())
+		}
+
+		h.ServeHTTP(w, r)
+	})
+
+	assert.Equal(t, kiteHTTP.StatusClientClosedRequest, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.NotContains(t, out, "query failed", "the downstream error is reported as a client closed request")
 }
 
 func TestHandler_ServeHTTP_ContextTimeout(t *testing.T) {
@@ -0,0 +1,255 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type strictJSONKey struct{}
+
+// WithStrictJSON returns a copy of ctx in which the JSON bodies bound by Request.Bind must not have fields which are
+// not in the bound struct.
+func WithStrictJSON(ctx context.Context) context.Context {
+	return context.WithValue(ctx, strictJSONKey{}, true)
+}
+
+func isStrictJSON(ctx context.Context) bool {
+	strict, _ := ctx.Value(strictJSONKey{}).(bool)
+
+	return strict
+}
+
+// bindJSON decodes the JSON body into i, reporting the position of the syntax and type errors as ErrorInvalidJSON.
+func (r *Request) bindJSON(i any) error {
+	body, err := r.body()
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return ErrorRequestEntityTooLarge{Limit: maxBytesErr.Limit}
+		}
+
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+
+	if isStrictJSON(r.req.Context()) {
+		dec.DisallowUnknownFields()
+	}
+
+	if err := dec.Decode(&i); err != nil {
+		return jsonError(body, err)
+	}
+
+	// a body holds a single value, as with json.Unmarshal.
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return ErrorInvalidJSON{Offset: dec.InputOffset(), Reason: "unexpected data after the JSON value"}
+	}
+
+	return nil
+}
+
+// jsonError returns the ErrorInvalidJSON of the error of decoding body, or err when it has no position.
+func jsonError(body []byte, err error) error {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+
+	if errors.As(err, &syntaxErr) {
+		return ErrorInvalidJSON{Field: jsonPath(body, syntaxErr.Offset), Offset: syntaxErr.Offset, Reason: syntaxErr.Error()}
+	}
+
+	if errors.As(err, &typeErr) {
+		field := jsonPath(body, typeErr.Offset)
+		if field == "" {
+			field = typeErr.Field
+		}
+
+		expected := jsonTypeName(typeErr.Type)
+
+		return ErrorInvalidJSON{Field: field, Expected: expected, Offset: typeErr.Offset,
+			Reason: fmt.Sprintf("expected %s, got %s", expected, typeErr.Value)}
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return ErrorInvalidJSON{Offset: int64(len(body)), Reason: "unexpected end of JSON input"}
+	}
+
+	// the decoder reports the unknown fields of the strict mode by their name only.
+	if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		name, _ = strconv.Unquote(name)
+		field, offset := jsonKey(body, name)
+
+		return ErrorInvalidJSON{Field: field, Offset: offset, Reason: "unknown field"}
+	}
+
+	return err
+}
+
+// jsonTypeName returns the JSON type of the values decoded into t.
+func jsonTypeName(t reflect.Type) string {
+	if t == nil {
+		return ""
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8,
+		reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Pointer:
+		return jsonTypeName(t.Elem())
+	default:
+		return t.String()
+	}
+}
+
+// jsonFrame is an object or an array being read by a jsonWalker.
+type jsonFrame struct {
+	path  string
+	array bool
+	index int
+	// key is the key of the current value of an object, read when hasKey is set.
+	key    string
+	hasKey bool
+}
+
+// jsonWalker reads the tokens of a JSON body, tracking the path of the current value.
+type jsonWalker struct {
+	dec   *json.Decoder
+	stack []*jsonFrame
+}
+
+// walkJSON calls visit for each key and value of body, with its path and the offset of its end, until visit returns
+// true or the body is invalid.
+func walkJSON(body []byte, visit func(path string, key bool, token json.Token, offset int64) bool) {
+	w := &jsonWalker{dec: json.NewDecoder(bytes.NewReader(body))}
+
+	for {
+		token, err := w.dec.Token()
+		if err != nil {
+			return
+		}
+
+		top := w.top()
+
+		if delim, ok := token.(json.Delim); ok && (delim == '}' || delim == ']') {
+			w.stack = w.stack[:len(w.stack)-1]
+
+			if visit(top.path, false, token, w.dec.InputOffset()) {
+				return
+			}
+
+			w.next()
+
+			continue
+		}
+
+		if top != nil && !top.array && !top.hasKey {
+			top.key, top.hasKey = token.(string), true
+
+			if visit(w.path(), true, token, w.dec.InputOffset()) {
+				return
+			}
+
+			continue
+		}
+
+		path := w.path()
+
+		if delim, ok := token.(json.Delim); ok {
+			w.stack = append(w.stack, &jsonFrame{path: path, array: delim == '['})
+
+			if visit(path, false, token, w.dec.InputOffset()) {
+				return
+			}
+
+			continue
+		}
+
+		if visit(path, false, token, w.dec.InputOffset()) {
+			return
+		}
+
+		w.next()
+	}
+}
+
+func (w *jsonWalker) top() *jsonFrame {
+	if len(w.stack) == 0 {
+		return nil
+	}
+
+	return w.stack[len(w.stack)-1]
+}
+
+// path returns the path of the current value, e.g. "items[2].price".
+func (w *jsonWalker) path() string {
+	top := w.top()
+
+	switch {
+	case top == nil:
+		return ""
+	case top.array:
+		return top.path + "[" + strconv.Itoa(top.index) + "]"
+	case top.path == "":
+		return top.key
+	default:
+		return top.path + "." + top.key
+	}
+}
+
+// next moves past a value of the current object or array.
+func (w *jsonWalker) next() {
+	if top := w.top(); top != nil {
+		if top.array {
+			top.index++
+		} else {
+			top.hasKey = false
+		}
+	}
+}
+
+// jsonPath returns the path of the value of body read at offset.
+func jsonPath(body []byte, offset int64) string {
+	var found string
+
+	walkJSON(body, func(path string, _ bool, _ json.Token, end int64) bool {
+		found = path
+
+		return end >= offset
+	})
+
+	return found
+}
+
+// jsonKey returns the path and the offset of the first key name of body.
+func jsonKey(body []byte, name string) (path string, offset int64) {
+	walkJSON(body, func(p string, key bool, token json.Token, end int64) bool {
+		if key && token == name {
+			path, offset = p, end
+
+			return true
+		}
+
+		return false
+	})
+
+	return path, offset
+}
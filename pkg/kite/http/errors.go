@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/sllt/kite/pkg/kite/logging"
 )
@@ -97,6 +98,21 @@ func (ErrorInvalidRoute) StatusCode() int {
 	return http.StatusNotFound
 }
 
+// ErrorMethodNotAllowed represents an error for a request whose route is only registered for other methods.
+type ErrorMethodNotAllowed struct{}
+
+func (ErrorMethodNotAllowed) Error() string {
+	return "method not allowed"
+}
+
+func (ErrorMethodNotAllowed) LogLevel() logging.Level {
+	return logging.INFO
+}
+
+func (ErrorMethodNotAllowed) StatusCode() int {
+	return http.StatusMethodNotAllowed
+}
+
 // ErrorRequestTimeout represents an error for request which timed out.
 type ErrorRequestTimeout struct{}
 
@@ -130,6 +146,8 @@ func (ErrorClientClosedRequest) LogLevel() logging.Level {
 type ErrorServiceUnavailable struct {
 	Dependency   string
 	ErrorMessage string
+	// RetryIn is sent in the Retry-After header of the response when set.
+	RetryIn time.Duration
 }
 
 func (e ErrorServiceUnavailable) Error() string {
@@ -148,8 +166,17 @@ func (ErrorServiceUnavailable) LogLevel() logging.Level {
 	return logging.ERROR
 }
 
+func (e ErrorServiceUnavailable) RetryAfter() time.Duration {
+	return e.RetryIn
+}
+
 // ErrorPanicRecovery represents an error for request which panicked.
-type ErrorPanicRecovery struct{}
+type ErrorPanicRecovery struct {
+	// Panic and StackTrace describe the panic. They are sent in the meta of the response when set, which Kite only
+	// does with ErrorModeVerbose.
+	Panic      string
+	StackTrace string
+}
 
 func (ErrorPanicRecovery) Error() string {
 	return http.StatusText(http.StatusInternalServerError)
@@ -163,8 +190,21 @@ func (ErrorPanicRecovery) LogLevel() logging.Level {
 	return logging.ERROR
 }
 
+// Response implements ResponseMarshaller, sending the panic and its stack trace in the meta of the response when they
+// are set.
+func (e ErrorPanicRecovery) Response() map[string]any {
+	if e.Panic == "" && e.StackTrace == "" {
+		return nil
+	}
+
+	return map[string]any{"panic": e.Panic, "stack_trace": e.StackTrace}
+}
+
 // ErrorTooManyRequests represents an error when rate limit is exceeded.
-type ErrorTooManyRequests struct{}
+type ErrorTooManyRequests struct {
+	// RetryIn is sent in the Retry-After header of the response when set.
+	RetryIn time.Duration
+}
 
 func (ErrorTooManyRequests) Error() string {
 	return "rate limit exceeded"
@@ -178,6 +218,116 @@ func (ErrorTooManyRequests) LogLevel() logging.Level {
 	return logging.WARN
 }
 
+func (e ErrorTooManyRequests) RetryAfter() time.Duration {
+	return e.RetryIn
+}
+
+// ErrorIdempotencyConflict represents an error when a request with the same idempotency key is still being processed.
+type ErrorIdempotencyConflict struct{}
+
+func (ErrorIdempotencyConflict) Error() string {
+	return "a request with the same idempotency key is already being processed"
+}
+
+func (ErrorIdempotencyConflict) StatusCode() int {
+	return http.StatusConflict
+}
+
+func (ErrorIdempotencyConflict) LogLevel() logging.Level {
+	return logging.WARN
+}
+
+// ErrorIdempotencyKeyReused represents an error when an idempotency key is reused with a different request payload.
+type ErrorIdempotencyKeyReused struct{}
+
+func (ErrorIdempotencyKeyReused) Error() string {
+	return "idempotency key was already used with a different request payload"
+}
+
+func (ErrorIdempotencyKeyReused) StatusCode() int {
+	return http.StatusUnprocessableEntity
+}
+
+func (ErrorIdempotencyKeyReused) LogLevel() logging.Level {
+	return logging.WARN
+}
+
+// ErrorNoEvent represents the response of a long-poll request for which no event arrived before the timeout.
+type ErrorNoEvent struct{}
+
+func (ErrorNoEvent) Error() string {
+	return "no event received before the timeout"
+}
+
+func (ErrorNoEvent) StatusCode() int {
+	return http.StatusNoContent
+}
+
+func (ErrorNoEvent) LogLevel() logging.Level {
+	return logging.DEBUG // Timing out is the normal outcome of a long-poll
+}
+
+// ErrorInvalidJSON represents a JSON request body which cannot be bound, with the position of the error. Its fields are
+// sent in the meta of the response.
+type ErrorInvalidJSON struct {
+	// Field is the path of the field in error, e.g. "items[2].price", empty for the errors of the whole body.
+	Field string
+	// Expected is the JSON type expected for the field, e.g. "number", when it has another type.
+	Expected string
+	// Offset is the number of bytes of the body read before the error.
+	Offset int64
+	// Reason describes the error.
+	Reason string
+}
+
+func (e ErrorInvalidJSON) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("invalid JSON body at offset %d: %s", e.Offset, e.Reason)
+	}
+
+	return fmt.Sprintf("invalid JSON body at offset %d: field %s: %s", e.Offset, e.Field, e.Reason)
+}
+
+func (ErrorInvalidJSON) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (ErrorInvalidJSON) LogLevel() logging.Level {
+	return logging.INFO
+}
+
+// Response implements ResponseMarshaller, sending the position of the error in the meta of the response.
+func (e ErrorInvalidJSON) Response() map[string]any {
+	meta := map[string]any{"offset": e.Offset}
+
+	if e.Field != "" {
+		meta["field"] = e.Field
+	}
+
+	if e.Expected != "" {
+		meta["expected"] = e.Expected
+	}
+
+	return meta
+}
+
+// ErrorRequestEntityTooLarge represents a request whose body exceeds the limit set with http.MaxBytesReader.
+type ErrorRequestEntityTooLarge struct {
+	Limit int64
+}
+
+func (e ErrorRequestEntityTooLarge) Error() string {
+	return fmt.Sprintf("request body exceeds %d bytes", e.Limit)
+}
+
+func (ErrorRequestEntityTooLarge) StatusCode() int {
+	return http.StatusRequestEntityTooLarge
+}
+
+func (ErrorRequestEntityTooLarge) LogLevel() logging.Level {
+	return logging.INFO
+}
+
 // validate the errors satisfy the underlying interfaces they depend on.
 var (
 	_ StatusCodeResponder = ErrorEntityNotFound{}
@@ -185,11 +335,17 @@ var (
 	_ StatusCodeResponder = ErrorInvalidParam{}
 	_ StatusCodeResponder = ErrorMissingParam{}
 	_ StatusCodeResponder = ErrorInvalidRoute{}
+	_ StatusCodeResponder = ErrorMethodNotAllowed{}
 	_ StatusCodeResponder = ErrorRequestTimeout{}
 	_ StatusCodeResponder = ErrorPanicRecovery{}
 	_ StatusCodeResponder = ErrorServiceUnavailable{}
 	_ StatusCodeResponder = ErrorClientClosedRequest{}
 	_ StatusCodeResponder = ErrorTooManyRequests{}
+	_ StatusCodeResponder = ErrorIdempotencyConflict{}
+	_ StatusCodeResponder = ErrorIdempotencyKeyReused{}
+	_ StatusCodeResponder = ErrorNoEvent{}
+	_ StatusCodeResponder = ErrorInvalidJSON{}
+	_ StatusCodeResponder = ErrorRequestEntityTooLarge{}
 
 	_ logging.LogLevelResponder = ErrorClientClosedRequest{}
 	_ logging.LogLevelResponder = ErrorEntityNotFound{}
@@ -197,8 +353,17 @@ var (
 	_ logging.LogLevelResponder = ErrorInvalidParam{}
 	_ logging.LogLevelResponder = ErrorMissingParam{}
 	_ logging.LogLevelResponder = ErrorInvalidRoute{}
+	_ logging.LogLevelResponder = ErrorMethodNotAllowed{}
 	_ logging.LogLevelResponder = ErrorRequestTimeout{}
 	_ logging.LogLevelResponder = ErrorPanicRecovery{}
 	_ logging.LogLevelResponder = ErrorServiceUnavailable{}
 	_ logging.LogLevelResponder = ErrorTooManyRequests{}
+	_ logging.LogLevelResponder = ErrorIdempotencyConflict{}
+	_ logging.LogLevelResponder = ErrorIdempotencyKeyReused{}
+	_ logging.LogLevelResponder = ErrorNoEvent{}
+	_ logging.LogLevelResponder = ErrorInvalidJSON{}
+	_ logging.LogLevelResponder = ErrorRequestEntityTooLarge{}
+
+	_ ResponseMarshaller = ErrorInvalidJSON{}
+	_ ResponseMarshaller = ErrorPanicRecovery{}
 )
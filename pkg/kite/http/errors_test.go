@@ -105,6 +105,14 @@ func TestErrorInvalidRoute(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, err.StatusCode(), "TEST Failed.\n")
 }
 
+func TestErrorMethodNotAllowed(t *testing.T) {
+	err := ErrorMethodNotAllowed{}
+
+	require.ErrorContainsf(t, err, "method not allowed", "TEST Failed.\n")
+
+	assert.Equal(t, http.StatusMethodNotAllowed, err.StatusCode(), "TEST Failed.\n")
+}
+
 func Test_ErrorRequestTimeout(t *testing.T) {
 	err := ErrorRequestTimeout{}
 
@@ -119,6 +127,11 @@ func Test_ErrorErrorPanicRecovery(t *testing.T) {
 	require.ErrorContainsf(t, err, http.StatusText(http.StatusInternalServerError), "TEST Failed.\n")
 
 	assert.Equal(t, http.StatusInternalServerError, err.StatusCode(), "TEST Failed.\n")
+	assert.Nil(t, err.Response(), "the panic is not responded by default")
+
+	err = ErrorPanicRecovery{Panic: "boom", StackTrace: "goroutine 1"}
+
+	assert.Equal(t, map[string]any{"panic": "boom", "stack_trace": "goroutine 1"}, err.Response())
 }
 
 func Test_ServiceUnavailable(t *testing.T) {
@@ -154,3 +167,27 @@ func TestErrorClientClosedRequest(t *testing.T) {
 	assert.Equal(t, StatusClientClosedRequest, err.StatusCode())
 	assert.Equal(t, logging.DEBUG, err.LogLevel())
 }
+
+func TestErrorIdempotencyConflict(t *testing.T) {
+	err := ErrorIdempotencyConflict{}
+
+	require.ErrorContains(t, err, "already being processed")
+	assert.Equal(t, http.StatusConflict, err.StatusCode())
+	assert.Equal(t, logging.WARN, err.LogLevel())
+}
+
+func TestErrorIdempotencyKeyReused(t *testing.T) {
+	err := ErrorIdempotencyKeyReused{}
+
+	require.ErrorContains(t, err, "different request payload")
+	assert.Equal(t, http.StatusUnprocessableEntity, err.StatusCode())
+	assert.Equal(t, logging.WARN, err.LogLevel())
+}
+
+func TestErrorNoEvent(t *testing.T) {
+	err := ErrorNoEvent{}
+
+	require.ErrorContains(t, err, "no event received")
+	assert.Equal(t, http.StatusNoContent, err.StatusCode())
+	assert.Equal(t, logging.DEBUG, err.LogLevel())
+}
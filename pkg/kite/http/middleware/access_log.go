@@ -0,0 +1,226 @@
+package middleware
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AccessLogConfig holds configuration for the AccessLog middleware.
+type AccessLogConfig struct {
+	// Probes disables the logs of the health check requests.
+	Probes LogProbes
+	// Sampling logs a share of the requests of some statuses, e.g. 1% of the successful ones. The first rule matching
+	// the status of a request applies, and the requests matching no rule are all logged.
+	Sampling []AccessLogSampleRule
+	// Fields returns custom fields added to the log of each request, when not nil.
+	Fields func(r *http.Request) map[string]any
+}
+
+// AccessLogSampleRule logs a Rate, between 0 and 1, of the requests whose status is between MinStatus and MaxStatus.
+type AccessLogSampleRule struct {
+	MinStatus int
+	MaxStatus int
+	Rate      float64
+}
+
+func (r AccessLogSampleRule) matches(status int) bool {
+	return status >= r.MinStatus && status <= r.MaxStatus
+}
+
+// accessLogAnnotations collects the fields added to the log of a request by the middlewares and handlers which run
+// after the AccessLog middleware, whose requests are not seen by it.
+type accessLogAnnotations struct {
+	mu     sync.Mutex
+	user   string
+	tenant string
+	fields map[string]any
+}
+
+type accessLogKey struct{}
+
+// AddAccessLogField adds a custom field to the access log of the request of ctx, e.g. from a handler. It does nothing
+// when the request is not logged by the AccessLog middleware.
+func AddAccessLogField(ctx context.Context, key string, value any) {
+	annotateAccessLog(ctx, func(a *accessLogAnnotations) {
+		if a.fields == nil {
+			a.fields = make(map[string]any)
+		}
+
+		a.fields[key] = value
+	})
+}
+
+// AccessLogFields is a middleware adding the fields returned by fields to the access log of each request, e.g. for the
+// fields read from the context set by other middlewares.
+func AccessLogFields(fields func(r *http.Request) map[string]any) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for key, value := range fields(r) {
+				AddAccessLogField(r.Context(), key, value)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func annotateAccessLog(ctx context.Context, annotate func(a *accessLogAnnotations)) {
+	a, ok := ctx.Value(accessLogKey{}).(*accessLogAnnotations)
+	if !ok {
+		return
+	}
+
+	a.mu.Lock()
+	annotate(a)
+	a.mu.Unlock()
+}
+
+// AccessLog is a middleware which logs one structured line per request, with its latency, status, size, route, client
+// IP, trace and, when they are known, its user and tenant. The requests failing with a 5xx status are logged as errors.
+// It also recovers the panics of the handlers, responding with 500 Internal Server Error.
+func AccessLog(config AccessLogConfig, logger logger) func(inner http.Handler) http.Handler {
+	return func(inner http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			srw := &StatusResponseWriter{ResponseWriter: w}
+			traceID := trace.SpanFromContext(r.Context()).SpanContext().TraceID().String()
+			spanID := trace.SpanFromContext(r.Context()).SpanContext().SpanID().String()
+
+			srw.Header().Set("X-Correlation-ID", traceID)
+
+			defer func() { panicRecovery(recover(), srw, logger) }()
+
+			// Skip logging for default probe paths if log probes are disabled
+			if isLogProbeDisabled(config.Probes, r.URL.Path) {
+				inner.ServeHTTP(w, r)
+				return
+			}
+
+			annotations := &accessLogAnnotations{}
+			r = r.WithContext(context.WithValue(r.Context(), accessLogKey{}, annotations))
+
+			defer func() {
+				if !config.sampled(srw.status) {
+					return
+				}
+
+				l := newRequestLog(srw, r, start, traceID, spanID)
+				config.annotate(l, r, annotations)
+
+				logRequest(l, logger)
+			}()
+
+			inner.ServeHTTP(srw, r)
+		})
+	}
+}
+
+// sampled reports whether a request responded with status is logged.
+func (c *AccessLogConfig) sampled(status int) bool {
+	for _, rule := range c.Sampling {
+		if rule.matches(status) {
+			return rule.Rate >= 1 || rand.Float64() < rule.Rate //nolint:gosec // sampling is not security sensitive.
+		}
+	}
+
+	return true
+}
+
+func (c *AccessLogConfig) annotate(l *RequestLog, r *http.Request, a *accessLogAnnotations) {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		l.Route = rctx.RoutePattern()
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	l.User, l.Tenant = a.user, a.tenant
+
+	for key, value := range a.fields {
+		l.addField(key, value)
+	}
+
+	if c.Fields != nil {
+		for key, value := range c.Fields(r) {
+			l.addField(key, value)
+		}
+	}
+}
+
+// setAccessLogUser sets the user logged for the request of ctx, when it is not empty.
+func setAccessLogUser(ctx context.Context, user string) {
+	if user == "" {
+		return
+	}
+
+	annotateAccessLog(ctx, func(a *accessLogAnnotations) { a.user = user })
+}
+
+// accessLogUser returns the user of an authenticated request: the username of basic auth or the subject of a JWT.
+// The API keys are secrets, which are not logged.
+func accessLogUser(method AuthMethod, value any) string {
+	switch method {
+	case Username:
+		user, _ := value.(string)
+
+		return user
+	case JWTClaim:
+		if claims, ok := value.(jwt.MapClaims); ok {
+			sub, _ := claims.GetSubject()
+
+			return sub
+		}
+	}
+
+	return ""
+}
+
+// parseAccessLogSampling parses ACCESS_LOG_SAMPLING, e.g. "2xx=0.01,404=0.1", where a status class or a status is
+// followed by the rate of its requests which are logged. The statuses are matched before the classes, and the
+// invalid rules are ignored.
+func parseAccessLogSampling(value string) []AccessLogSampleRule {
+	var statuses, classes []AccessLogSampleRule
+
+	for _, rule := range strings.Split(value, ",") {
+		status, rate, ok := strings.Cut(rule, "=")
+		if !ok {
+			continue
+		}
+
+		r, err := strconv.ParseFloat(strings.TrimSpace(rate), 64)
+		if err != nil || r < 0 || r > 1 {
+			continue
+		}
+
+		status = strings.ToLower(strings.TrimSpace(status))
+
+		if class, ok := strings.CutSuffix(status, "xx"); ok {
+			c, err := strconv.Atoi(class)
+			if err != nil || c < 1 || c > 5 {
+				continue
+			}
+
+			classes = append(classes, AccessLogSampleRule{MinStatus: c * 100, MaxStatus: c*100 + 99, Rate: r})
+
+			continue
+		}
+
+		code, err := strconv.Atoi(status)
+		if err != nil || code < 100 || code > 599 {
+			continue
+		}
+
+		statuses = append(statuses, AccessLogSampleRule{MinStatus: code, MaxStatus: code, Rate: r})
+	}
+
+	return append(statuses, classes...)
+}
@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sllt/kite/pkg/kite/tenant"
+)
+
+type accessLogRecorder struct {
+	logs   []*RequestLog
+	errors []*RequestLog
+}
+
+func (r *accessLogRecorder) Log(args ...any) {
+	if l, ok := args[0].(*RequestLog); ok {
+		r.logs = append(r.logs, l)
+	}
+}
+
+func (r *accessLogRecorder) Error(args ...any) {
+	if l, ok := args[0].(*RequestLog); ok {
+		r.errors = append(r.errors, l)
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	recorder := &accessLogRecorder{}
+
+	router := chi.NewRouter()
+	router.Use(
+		AccessLog(AccessLogConfig{Fields: func(r *http.Request) map[string]any {
+			return map[string]any{"region": r.Header.Get("X-Region")}
+		}}, recorder),
+		Tenant(TenantConfig{Resolver: tenant.Header("X-Tenant-ID")}),
+		AccessLogFields(func(*http.Request) map[string]any { return map[string]any{"plan": "pro"} }),
+	)
+	router.Get("/orders/{id}", func(w http.ResponseWriter, r *http.Request) {
+		AddAccessLogField(r.Context(), "order", chi.URLParam(r, "id"))
+
+		_, _ = w.Write([]byte("order"))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/orders/42?expand=items", http.NoBody)
+	req.Header.Set("X-Tenant-ID", "acme")
+	req.Header.Set("X-Region", "eu")
+
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Len(t, recorder.logs, 1)
+
+	l := recorder.logs[0]
+
+	assert.Equal(t, "/orders/{id}", l.Route)
+	assert.Equal(t, "/orders/42?expand=items", l.URI)
+	assert.Equal(t, http.StatusOK, l.Response)
+	assert.Equal(t, int64(len("order")), l.Bytes)
+	assert.Equal(t, "acme", l.Tenant)
+	assert.Equal(t, map[string]any{"order": "42", "plan": "pro", "region": "eu"}, l.Fields)
+}
+
+func TestAccessLog_Sampling(t *testing.T) {
+	recorder := &accessLogRecorder{}
+
+	handler := AccessLog(AccessLogConfig{Sampling: parseAccessLogSampling("2xx=0,404=1,4xx=0")}, recorder)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/missing":
+				w.WriteHeader(http.StatusNotFound)
+			case "/invalid":
+				w.WriteHeader(http.StatusBadRequest)
+			case "/error":
+				w.WriteHeader(http.StatusInternalServerError)
+			default:
+				w.WriteHeader(http.StatusOK)
+			}
+		}))
+
+	for _, path := range []string{"/", "/missing", "/invalid", "/error"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, http.NoBody))
+	}
+
+	require.Len(t, recorder.logs, 1)
+	assert.Equal(t, http.StatusNotFound, recorder.logs[0].Response)
+
+	require.Len(t, recorder.errors, 1, "the errors are logged when no rule matches them")
+	assert.Equal(t, http.StatusInternalServerError, recorder.errors[0].Response)
+}
+
+func TestAccessLog_User(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		method   AuthMethod
+		value    any
+		expected string
+	}{
+		{"basic auth", Username, "alice", "alice"},
+		{"jwt", JWTClaim, jwt.MapClaims{"sub": "user-1"}, "user-1"},
+		{"jwt without subject", JWTClaim, jwt.MapClaims{}, ""},
+		{"api key", APIKey, "secret", ""},
+	}
+
+	for i, tc := range testCases {
+		assert.Equal(t, tc.expected, accessLogUser(tc.method, tc.value), "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestAddAccessLogField_NotLogged(t *testing.T) {
+	assert.NotPanics(t, func() { AddAccessLogField(t.Context(), "key", "value") })
+}
+
+func TestParseAccessLogSampling(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected []AccessLogSampleRule
+	}{
+		{"", nil},
+		{"2xx=0.01", []AccessLogSampleRule{{MinStatus: 200, MaxStatus: 299, Rate: 0.01}}},
+		{"4XX = 0.5, 404=0", []AccessLogSampleRule{
+			{MinStatus: 404, MaxStatus: 404, Rate: 0},
+			{MinStatus: 400, MaxStatus: 499, Rate: 0.5},
+		}},
+		{"2xx=2,6xx=1,abc=1,200,200=x,99=1", nil},
+	}
+
+	for i, tc := range testCases {
+		assert.Equal(t, tc.expected, parseAccessLogSampling(tc.value), "TEST[%d], Failed.\n%s", i, tc.value)
+	}
+}
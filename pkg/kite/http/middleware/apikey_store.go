@@ -0,0 +1,303 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/sllt/kite/pkg/kite/datasource/sql/qb"
+)
+
+// CreateAPIKeyTableSQL creates the table used by the SQL API key store.
+// It can be applied from a migration before enabling the store.
+const CreateAPIKeyTableSQL = `CREATE TABLE IF NOT EXISTS kite_api_keys (
+    key_id VARCHAR(32) NOT NULL PRIMARY KEY,
+    key_hash VARCHAR(64) NOT NULL UNIQUE,
+    data TEXT NOT NULL
+);`
+
+const (
+	redisAPIKeyPrefix   = "kite:apikey:"
+	redisAPIKeyIDPrefix = "kite:apikey:id:"
+	apiKeyPrefix        = "kite"
+	apiKeyIDLength      = 6
+	apiKeySecretLength  = 32
+)
+
+// ErrAPIKeyNotFound is returned when revoking an API key which is not in the store.
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// APIKeyRecord describes an API key. Only the hash of the key is stored, the key itself is returned once by
+// MintAPIKey.
+type APIKeyRecord struct {
+	// ID identifies the key, e.g. in the logs and to revoke it. It is the second part of the key
+	// "kite_<id>_<secret>", so that it can be read from a leaked key.
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Hash is the hex SHA-256 of the key, see HashAPIKey.
+	Hash string `json:"hash"`
+	// Scopes are the permissions of the key, used as roles by rbac with subject.apiKey.scopes.
+	Scopes []string `json:"scopes,omitempty"`
+	// RequestsPerSecond and Burst limit the requests made with the key, overriding the limit of APIKeysConfig.
+	RequestsPerSecond float64 `json:"requestsPerSecond,omitempty"`
+	Burst             int     `json:"burst,omitempty"`
+	// ExpiresAt is the expiry of the key, which never expires if it is zero.
+	ExpiresAt time.Time `json:"expiresAt,omitzero"`
+	Revoked   bool      `json:"revoked,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// valid reports whether the key is neither revoked nor expired.
+func (k *APIKeyRecord) valid(now time.Time) bool {
+	return !k.Revoked && (k.ExpiresAt.IsZero() || now.Before(k.ExpiresAt))
+}
+
+// APIKeyStore abstracts the storage of the API keys of the APIKeys middleware.
+type APIKeyStore interface {
+	// Get returns the key with the given hash, or nil if there is none.
+	Get(ctx context.Context, hash string) (*APIKeyRecord, error)
+	// Save stores the key, replacing the key with the same ID.
+	Save(ctx context.Context, key *APIKeyRecord) error
+	// Revoke marks the key with the given ID as revoked, or returns ErrAPIKeyNotFound.
+	Revoke(ctx context.Context, id string) error
+}
+
+// HashAPIKey returns the hex SHA-256 of key, under which it is stored.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// MintAPIKey generates a new key "kite_<id>_<secret>" with the name, scopes, rate limit and expiry of spec, and saves
+// it in store. The key is returned once, only its hash is stored.
+func MintAPIKey(ctx context.Context, store APIKeyStore, spec APIKeyRecord) (string, *APIKeyRecord, error) {
+	id := make([]byte, apiKeyIDLength)
+	secret := make([]byte, apiKeySecretLength)
+
+	if _, err := rand.Read(id); err != nil {
+		return "", nil, err
+	}
+
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, err
+	}
+
+	spec.ID = hex.EncodeToString(id)
+	key := apiKeyPrefix + "_" + spec.ID + "_" + base64.RawURLEncoding.EncodeToString(secret)
+
+	spec.Hash = HashAPIKey(key)
+	spec.Revoked = false
+	spec.CreatedAt = time.Now().UTC()
+
+	if err := store.Save(ctx, &spec); err != nil {
+		return "", nil, err
+	}
+
+	return key, &spec, nil
+}
+
+// memoryAPIKeyStore implements APIKeyStore in memory.
+type memoryAPIKeyStore struct {
+	mu   sync.Mutex
+	keys map[string]APIKeyRecord
+}
+
+// NewMemoryAPIKeyStore creates a new in-memory API key store, only suitable for a single instance and for tests.
+func NewMemoryAPIKeyStore() APIKeyStore {
+	return &memoryAPIKeyStore{keys: make(map[string]APIKeyRecord)}
+}
+
+func (m *memoryAPIKeyStore) Get(_ context.Context, hash string) (*APIKeyRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, key := range m.keys {
+		if key.Hash == hash {
+			return &key, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (m *memoryAPIKeyStore) Save(_ context.Context, key *APIKeyRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.keys[key.ID] = *key
+
+	return nil
+}
+
+func (m *memoryAPIKeyStore) Revoke(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key, ok := m.keys[id]
+	if !ok {
+		return ErrAPIKeyNotFound
+	}
+
+	key.Revoked = true
+	m.keys[id] = key
+
+	return nil
+}
+
+// RedisAPIKeyStore implements APIKeyStore using Redis. The keys are stored under their hash, with an index by ID.
+type RedisAPIKeyStore struct {
+	client redis.Cmdable
+}
+
+// NewRedisAPIKeyStore creates an API key store backed by the given Redis client,
+// e.g. the application's Redis datasource.
+func NewRedisAPIKeyStore(client redis.Cmdable) *RedisAPIKeyStore {
+	return &RedisAPIKeyStore{client: client}
+}
+
+func (r *RedisAPIKeyStore) Get(ctx context.Context, hash string) (*APIKeyRecord, error) {
+	data, err := r.client.Get(ctx, redisAPIKeyPrefix+hash).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var key APIKeyRecord
+	if err = json.Unmarshal(data, &key); err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+func (r *RedisAPIKeyStore) Save(ctx context.Context, key *APIKeyRecord) error {
+	data, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, redisAPIKeyPrefix+key.Hash, data, 0)
+		pipe.Set(ctx, redisAPIKeyIDPrefix+key.ID, key.Hash, 0)
+
+		return nil
+	})
+
+	return err
+}
+
+func (r *RedisAPIKeyStore) Revoke(ctx context.Context, id string) error {
+	hash, err := r.client.Get(ctx, redisAPIKeyIDPrefix+id).Result()
+	if errors.Is(err, redis.Nil) {
+		return ErrAPIKeyNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	key, err := r.Get(ctx, hash)
+	if err != nil {
+		return err
+	}
+
+	if key == nil {
+		return ErrAPIKeyNotFound
+	}
+
+	key.Revoked = true
+
+	return r.Save(ctx, key)
+}
+
+// APIKeySQL is the subset of the SQL datasource used by the SQL API key store.
+type APIKeySQL interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	Dialect() string
+}
+
+// SQLAPIKeyStore implements APIKeyStore on the kite_api_keys table, see CreateAPIKeyTableSQL.
+type SQLAPIKeyStore struct {
+	db APIKeySQL
+}
+
+// NewSQLAPIKeyStore creates an API key store backed by the given SQL datasource.
+func NewSQLAPIKeyStore(db APIKeySQL) *SQLAPIKeyStore {
+	return &SQLAPIKeyStore{db: db}
+}
+
+func (s *SQLAPIKeyStore) Get(ctx context.Context, hash string) (*APIKeyRecord, error) {
+	return s.get(ctx, "SELECT data FROM kite_api_keys WHERE key_hash = ?", hash)
+}
+
+func (s *SQLAPIKeyStore) get(ctx context.Context, query string, arg string) (*APIKeyRecord, error) {
+	var data string
+
+	err := s.db.QueryRowContext(ctx, qb.RebindWithDialect(s.db.Dialect(), query), arg).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var key APIKeyRecord
+	if err = json.Unmarshal([]byte(data), &key); err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+func (s *SQLAPIKeyStore) Save(ctx context.Context, key *APIKeyRecord) error {
+	data, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+
+	// UPDATE then INSERT works on every dialect, unlike the upsert syntaxes.
+	res, err := s.db.ExecContext(ctx, qb.RebindWithDialect(s.db.Dialect(),
+		"UPDATE kite_api_keys SET key_hash = ?, data = ? WHERE key_id = ?"), key.Hash, string(data), key.ID)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n > 0 {
+		return nil
+	}
+
+	_, err = s.db.ExecContext(ctx, qb.RebindWithDialect(s.db.Dialect(),
+		"INSERT INTO kite_api_keys (key_id, key_hash, data) VALUES (?, ?, ?)"), key.ID, key.Hash, string(data))
+
+	return err
+}
+
+func (s *SQLAPIKeyStore) Revoke(ctx context.Context, id string) error {
+	key, err := s.get(ctx, "SELECT data FROM kite_api_keys WHERE key_id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	if key == nil {
+		return ErrAPIKeyNotFound
+	}
+
+	key.Revoked = true
+
+	return s.Save(ctx, key)
+}
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMintAPIKey(t *testing.T) {
+	store := NewMemoryAPIKeyStore()
+
+	key, record, err := MintAPIKey(context.Background(), store, APIKeyRecord{Name: "ci", Scopes: []string{"read"}})
+	require.NoError(t, err)
+
+	assert.Regexp(t, regexp.MustCompile(`^kite_[0-9a-f]{12}_[A-Za-z0-9_-]{43}$`), key)
+	assert.Equal(t, "kite_"+record.ID+"_", key[:len("kite_")+len(record.ID)+1])
+	assert.Equal(t, HashAPIKey(key), record.Hash)
+	assert.NotContains(t, record.Hash, key)
+	assert.False(t, record.CreatedAt.IsZero())
+
+	stored, err := store.Get(context.Background(), record.Hash)
+	require.NoError(t, err)
+	assert.Equal(t, record, stored)
+}
+
+func TestMemoryAPIKeyStore_Revoke(t *testing.T) {
+	store := NewMemoryAPIKeyStore()
+	ctx := context.Background()
+
+	_, record, err := MintAPIKey(ctx, store, APIKeyRecord{Name: "ci"})
+	require.NoError(t, err)
+
+	require.NoError(t, store.Revoke(ctx, record.ID))
+	require.ErrorIs(t, store.Revoke(ctx, "unknown"), ErrAPIKeyNotFound)
+
+	stored, err := store.Get(ctx, record.Hash)
+	require.NoError(t, err)
+	assert.True(t, stored.Revoked)
+
+	stored, err = store.Get(ctx, HashAPIKey("unknown"))
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+}
+
+func TestRedisAPIKeyStore(t *testing.T) {
+	s := miniredis.RunT(t)
+	store := NewRedisAPIKeyStore(redis.NewClient(&redis.Options{Addr: s.Addr()}))
+	ctx := context.Background()
+
+	stored, err := store.Get(ctx, HashAPIKey("unknown"))
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+
+	_, record, err := MintAPIKey(ctx, store, APIKeyRecord{Name: "ci", Scopes: []string{"read"}})
+	require.NoError(t, err)
+
+	stored, err = store.Get(ctx, record.Hash)
+	require.NoError(t, err)
+	assert.Equal(t, record.Scopes, stored.Scopes)
+
+	require.NoError(t, store.Revoke(ctx, record.ID))
+	require.ErrorIs(t, store.Revoke(ctx, "unknown"), ErrAPIKeyNotFound)
+
+	stored, err = store.Get(ctx, record.Hash)
+	require.NoError(t, err)
+	assert.True(t, stored.Revoked)
+}
+
+func TestSQLAPIKeyStore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	store := NewSQLAPIKeyStore(sqlmockDialect{DB: db, dialect: "postgres"})
+	ctx := context.Background()
+	record := &APIKeyRecord{ID: "id", Name: "ci", Hash: "hash"}
+
+	data, err := json.Marshal(record)
+	require.NoError(t, err)
+
+	mock.ExpectQuery("SELECT data FROM kite_api_keys WHERE key_hash = \\$1").WithArgs("hash").
+		WillReturnError(sql.ErrNoRows)
+
+	stored, err := store.Get(ctx, "hash")
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+
+	// a new key is inserted after the update finds no row.
+	mock.ExpectExec("UPDATE kite_api_keys SET key_hash = \\$1, data = \\$2 WHERE key_id = \\$3").
+		WithArgs("hash", string(data), "id").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO kite_api_keys \\(key_id, key_hash, data\\) VALUES \\(\\$1, \\$2, \\$3\\)").
+		WithArgs("id", "hash", string(data)).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	require.NoError(t, store.Save(ctx, record))
+
+	// a revoked key is updated in place.
+	mock.ExpectQuery("SELECT data FROM kite_api_keys WHERE key_id = \\$1").WithArgs("id").
+		WillReturnRows(sqlmock.NewRows([]string{"data"}).AddRow(string(data)))
+	mock.ExpectExec("UPDATE kite_api_keys").WithArgs("hash", sqlmock.AnyArg(), "id").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, store.Revoke(ctx, "id"))
+
+	mock.ExpectQuery("SELECT data FROM kite_api_keys WHERE key_id = \\$1").WithArgs("unknown").
+		WillReturnError(sql.ErrNoRows)
+
+	require.ErrorIs(t, store.Revoke(ctx, "unknown"), ErrAPIKeyNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,167 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	kiteHttp "github.com/sllt/kite/pkg/kite/http"
+)
+
+const staticAPIKeyIDLength = 12
+
+type apiKeyContextKey struct{}
+
+// APIKeysConfig holds configuration for the APIKeys middleware.
+type APIKeysConfig struct {
+	// Store holds the keys minted with MintAPIKey, as hashes.
+	Store APIKeyStore
+	// Keys are static keys, mapped to their scopes. They are checked before Store.
+	Keys map[string][]string
+	// Header carries the key, "X-Api-Key" by default.
+	Header string
+	// QueryParam, when set, also reads the key from this query parameter when the header is missing. Keys in URLs
+	// end up in access logs and browser histories, so it is disabled by default.
+	QueryParam string
+	// RequestsPerSecond and Burst limit the requests of each key without a limit of its own. The requests are not
+	// limited when RequestsPerSecond is zero. The limits are enforced by each instance independently.
+	RequestsPerSecond float64
+	Burst             int
+}
+
+// APIKeyFromContext returns the API key verified by the APIKeys middleware, or nil.
+func APIKeyFromContext(ctx context.Context) *APIKeyRecord {
+	key, _ := ctx.Value(apiKeyContextKey{}).(*APIKeyRecord)
+
+	return key
+}
+
+// APIKeys is a middleware that authenticates the requests with static API keys or the keys of a store, see
+// MintAPIKey. Revoked and expired keys are rejected with 401 Unauthorized, and the requests over the rate limit of
+// their key with 429 Too Many Requests. The verified key is returned by APIKeyFromContext, its scopes being used as
+// roles by rbac with subject.apiKey.scopes.
+func APIKeys(config APIKeysConfig) func(http.Handler) http.Handler {
+	if config.Header == "" {
+		config.Header = headerXAPIKey
+	}
+
+	static := make(map[string]*APIKeyRecord, len(config.Keys))
+
+	for key, scopes := range config.Keys {
+		hash := HashAPIKey(key)
+		static[key] = &APIKeyRecord{ID: hash[:staticAPIKeyIDLength], Name: "static", Hash: hash, Scopes: scopes}
+	}
+
+	limiters := &apiKeyLimiters{config: config}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isWellKnown(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			responder := kiteHttp.NewResponder(w, r.Method)
+
+			raw := r.Header.Get(config.Header)
+			if raw == "" && config.QueryParam != "" {
+				raw = r.URL.Query().Get(config.QueryParam)
+			}
+
+			if raw == "" {
+				responder.Respond(nil, NewMissingAuthHeaderError(config.Header))
+				return
+			}
+
+			key, err := lookupAPIKey(r.Context(), config.Store, static, raw)
+			if err != nil {
+				responder.Respond(nil, kiteHttp.ErrorServiceUnavailable{
+					Dependency: "api key store", ErrorMessage: err.Error()})
+				return
+			}
+
+			if key == nil || !key.valid(time.Now()) {
+				responder.Respond(nil, NewInvalidAuthorizationHeaderError(config.Header))
+				return
+			}
+
+			if allowed, retryAfter := limiters.allow(key); !allowed {
+				w.Header().Set("Retry-After", fmt.Sprintf("%.0f", math.Ceil(retryAfter.Seconds())))
+				responder.Respond(nil, kiteHttp.ErrorTooManyRequests{})
+
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), APIKey, raw)
+			ctx = context.WithValue(ctx, apiKeyContextKey{}, key)
+
+			// the id of the key is logged, as the key is a secret.
+			setAccessLogUser(ctx, key.ID)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// lookupAPIKey returns the static key matching raw, compared in constant time, or else the key of the store with its
+// hash. It returns nil when the key is unknown.
+func lookupAPIKey(ctx context.Context, store APIKeyStore, static map[string]*APIKeyRecord,
+	raw string) (*APIKeyRecord, error) {
+	var found *APIKeyRecord
+
+	for candidate, key := range static {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(raw)) == 1 {
+			found = key
+		}
+	}
+
+	if found != nil || store == nil {
+		return found, nil
+	}
+
+	return store.Get(ctx, HashAPIKey(raw))
+}
+
+// apiKeyLimiters holds the token bucket of each API key.
+type apiKeyLimiters struct {
+	config   APIKeysConfig
+	limiters sync.Map // map[string]*rate.Limiter
+}
+
+// allow reports whether a request can be made with the key, or else the wait before the next one.
+func (l *apiKeyLimiters) allow(key *APIKeyRecord) (bool, time.Duration) {
+	rps, burst := key.RequestsPerSecond, key.Burst
+	if rps <= 0 {
+		rps, burst = l.config.RequestsPerSecond, l.config.Burst
+	}
+
+	if rps <= 0 {
+		return true, 0
+	}
+
+	burst = max(burst, 1)
+
+	val, _ := l.limiters.LoadOrStore(key.ID, rate.NewLimiter(rate.Limit(rps), burst))
+	limiter := val.(*rate.Limiter)
+
+	// The limit of a key can be changed in the store.
+	if limiter.Limit() != rate.Limit(rps) || limiter.Burst() != burst {
+		limiter.SetLimit(rate.Limit(rps))
+		limiter.SetBurst(burst)
+	}
+
+	reservation := limiter.Reserve()
+	if delay := reservation.Delay(); delay > 0 {
+		reservation.Cancel()
+
+		return false, delay
+	}
+
+	return true, 0
+}
@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errAPIKeyStoreDown = errors.New("api key store down")
+
+func serveAPIKeys(config APIKeysConfig, target, key string) (*httptest.ResponseRecorder, *APIKeyRecord) {
+	var verified *APIKeyRecord
+
+	handler := APIKeys(config)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		verified = APIKeyFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, target, http.NoBody)
+	if key != "" {
+		req.Header.Set("X-Api-Key", key)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	return rec, verified
+}
+
+func TestAPIKeys_StoreKeys(t *testing.T) {
+	store := NewMemoryAPIKeyStore()
+	ctx := context.Background()
+
+	valid, record, err := MintAPIKey(ctx, store, APIKeyRecord{Name: "ci", Scopes: []string{"orders:read"}})
+	require.NoError(t, err)
+
+	expired, _, err := MintAPIKey(ctx, store, APIKeyRecord{Name: "old", ExpiresAt: time.Now().Add(-time.Minute)})
+	require.NoError(t, err)
+
+	revoked, revokedRecord, err := MintAPIKey(ctx, store, APIKeyRecord{Name: "leaked"})
+	require.NoError(t, err)
+	require.NoError(t, store.Revoke(ctx, revokedRecord.ID))
+
+	testCases := []struct {
+		desc       string
+		key        string
+		statusCode int
+	}{
+		{"valid key", valid, http.StatusOK},
+		{"missing key", "", http.StatusUnauthorized},
+		{"unknown key", "kite_000000000000_unknown", http.StatusUnauthorized},
+		{"expired key", expired, http.StatusUnauthorized},
+		{"revoked key", revoked, http.StatusUnauthorized},
+	}
+
+	for i, tc := range testCases {
+		rec, verified := serveAPIKeys(APIKeysConfig{Store: store}, "/orders", tc.key)
+
+		assert.Equal(t, tc.statusCode, rec.Code, "TEST[%d], Failed.\n%s", i, tc.desc)
+
+		if tc.statusCode == http.StatusOK {
+			assert.Equal(t, record, verified, "TEST[%d], Failed.\n%s", i, tc.desc)
+		}
+	}
+}
+
+func TestAPIKeys_StaticKeysAndQueryParam(t *testing.T) {
+	config := APIKeysConfig{Keys: map[string][]string{"static-key": {"admin"}}, QueryParam: "api_key"}
+
+	rec, verified := serveAPIKeys(config, "/orders?api_key=static-key", "")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	require.NotNil(t, verified)
+	assert.Equal(t, []string{"admin"}, verified.Scopes)
+	assert.Equal(t, HashAPIKey("static-key"), verified.Hash)
+
+	rec, _ = serveAPIKeys(APIKeysConfig{Keys: config.Keys}, "/orders?api_key=static-key", "")
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code, "the query parameter must be disabled by default")
+}
+
+func TestAPIKeys_RateLimit(t *testing.T) {
+	store := NewMemoryAPIKeyStore()
+
+	limited, _, err := MintAPIKey(context.Background(), store, APIKeyRecord{RequestsPerSecond: 0.1, Burst: 1})
+	require.NoError(t, err)
+
+	config := APIKeysConfig{Store: store, Keys: map[string][]string{"static-key": nil}, RequestsPerSecond: 0.1, Burst: 2}
+	handler := APIKeys(config)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+	statusCodes := func(key string, n int) []int {
+		codes := make([]int, n)
+
+		for i := range codes {
+			req := httptest.NewRequest(http.MethodGet, "/orders", http.NoBody)
+			req.Header.Set("X-Api-Key", key)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			codes[i] = rec.Code
+
+			if rec.Code == http.StatusTooManyRequests {
+				assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+			}
+		}
+
+		return codes
+	}
+
+	assert.Equal(t, []int{http.StatusOK, http.StatusTooManyRequests}, statusCodes(limited, 2), "limit of the key")
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}, statusCodes("static-key", 3),
+		"default limit")
+}
+
+func TestAPIKeys_StoreFailure(t *testing.T) {
+	rec, _ := serveAPIKeys(APIKeysConfig{Store: failingAPIKeyStore{}}, "/orders", "key")
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestAPIKeys_WellKnownPaths(t *testing.T) {
+	rec, _ := serveAPIKeys(APIKeysConfig{Store: NewMemoryAPIKeyStore()}, "/.well-known/alive", "")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+type failingAPIKeyStore struct{}
+
+func (failingAPIKeyStore) Get(context.Context, string) (*APIKeyRecord, error) {
+	return nil, errAPIKeyStoreDown
+}
+
+func (failingAPIKeyStore) Save(context.Context, *APIKeyRecord) error {
+	return errAPIKeyStoreDown
+}
+
+func (failingAPIKeyStore) Revoke(context.Context, string) error {
+	return errAPIKeyStoreDown
+}
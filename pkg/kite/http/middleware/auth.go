@@ -53,6 +53,7 @@ func AuthMiddleware(a AuthProvider) func(handler http.Handler) http.Handler {
 			}
 
 			ctx := context.WithValue(r.Context(), a.GetAuthMethod(), authHeader)
+			setAccessLogUser(ctx, accessLogUser(a.GetAuthMethod(), authHeader))
 			*r = *r.Clone(ctx)
 
 			handler.ServeHTTP(w, r)
@@ -0,0 +1,309 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/sllt/kite/pkg/kite/logging"
+)
+
+const defaultBodyLogMaxSize = 4096
+
+var (
+	// defaultBodyLogContentTypes are the media types of the bodies logged when BodyLogConfig.ContentTypes is empty.
+	defaultBodyLogContentTypes = []string{
+		"application/json", "application/*+json", "application/xml", "application/*+xml",
+		"application/x-www-form-urlencoded", "text/*",
+	}
+
+	// jsonFieldPattern matches the string, number and literal fields of a JSON object, to mask the truncated bodies
+	// which cannot be decoded. The string value may be cut by the truncation.
+	jsonFieldPattern = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"(\s*:\s*)("(?:[^"\\]|\\.)*"?|[^\s,}\]]+)`)
+)
+
+// BodyLogConfig holds the configuration of a BodyLogger.
+type BodyLogConfig struct {
+	// MaxSize is the number of bytes of each body which are logged, the rest is truncated. Defaults to 4 KiB.
+	MaxSize int
+	// ContentTypes are the media types of the bodies which are logged, with wildcards, e.g. "text/*" or
+	// "application/*+json". Defaults to JSON, XML, forms and text.
+	ContentTypes []string
+	// Sink receives the bodies instead of the logger when not nil, e.g. to keep them by trace ID.
+	Sink BodyLogSink
+	// Redactor masks the sensitive fields of the bodies sent to the Sink. The bodies which are logged are masked by
+	// the redactor of the logger.
+	Redactor *logging.Redactor
+}
+
+// BodyLogSink receives the bodies of the requests logged by a BodyLogger.
+type BodyLogSink interface {
+	LogBody(ctx context.Context, log *BodyLog)
+}
+
+// BodyLog holds the bodies of a request and of its response. A body is empty when it was not read by the handler, or
+// when its content type is not logged.
+type BodyLog struct {
+	TraceID             string `json:"trace_id,omitempty"`
+	Method              string `json:"method,omitempty"`
+	URI                 string `json:"uri,omitempty"`
+	Response            int    `json:"response,omitempty"`
+	RequestContentType  string `json:"request_content_type,omitempty"`
+	RequestBody         string `json:"request_body,omitempty"`
+	RequestTruncated    bool   `json:"request_truncated,omitempty"`
+	ResponseContentType string `json:"response_content_type,omitempty"`
+	ResponseBody        string `json:"response_body,omitempty"`
+	ResponseTruncated   bool   `json:"response_truncated,omitempty"`
+}
+
+func (l *BodyLog) PrettyPrint(writer io.Writer) {
+	fmt.Fprintf(writer, "\u001B[38;5;8m%s \u001B[38;5;%dm%-6d\u001B[0m %s %s\n", l.TraceID,
+		colorForStatusCode(l.Response), l.Response, l.Method, l.URI)
+
+	if l.RequestBody != "" {
+		fmt.Fprintf(writer, "\u001B[38;5;8m  request:\u001B[0m  %s\n", l.RequestBody)
+	}
+
+	if l.ResponseBody != "" {
+		fmt.Fprintf(writer, "\u001B[38;5;8m  response:\u001B[0m %s\n", l.ResponseBody)
+	}
+}
+
+// Redact implements logging.Redactable, masking the sensitive query parameters of the URI and fields of the bodies.
+func (l *BodyLog) Redact(r *logging.Redactor) any {
+	c := *l
+	c.URI = r.URL(l.URI)
+	c.RequestBody = redactBody(r, l.RequestContentType, l.RequestBody, l.RequestTruncated)
+	c.ResponseBody = redactBody(r, l.ResponseContentType, l.ResponseBody, l.ResponseTruncated)
+
+	return &c
+}
+
+// redactBody masks a body: the fields of forms and JSON payloads, and the patterns of the redactor.
+func redactBody(r *logging.Redactor, contentType, body string, truncated bool) string {
+	if r == nil || body == "" {
+		return body
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		return strings.TrimPrefix(r.URL("?"+body), "?")
+	case truncated && strings.HasSuffix(mediaType, "json"):
+		// a truncated payload is not valid JSON, its fields are masked one by one.
+		body = jsonFieldPattern.ReplaceAllStringFunc(body, func(field string) string {
+			match := jsonFieldPattern.FindStringSubmatch(field)
+			if !r.IsRedactedField(match[1]) {
+				return field
+			}
+
+			return `"` + match[1] + `"` + match[2] + `"` + logging.RedactedValue + `"`
+		})
+
+		return r.String(body)
+	default:
+		return r.Payload(body)
+	}
+}
+
+// BodyLogger is a middleware logging the bodies of the requests and of their responses, to debug the integrations
+// with other services. It is disabled until enabled with SetEnabled, which can be called while it serves requests.
+type BodyLogger struct {
+	config  BodyLogConfig
+	logger  logger
+	enabled atomic.Bool
+}
+
+// NewBodyLogger returns a disabled BodyLogger, logging the bodies with logger unless config.Sink is set.
+func NewBodyLogger(config BodyLogConfig, logger logger) *BodyLogger {
+	if config.MaxSize <= 0 {
+		config.MaxSize = defaultBodyLogMaxSize
+	}
+
+	if len(config.ContentTypes) == 0 {
+		config.ContentTypes = defaultBodyLogContentTypes
+	}
+
+	return &BodyLogger{config: config, logger: logger}
+}
+
+// SetEnabled enables or disables the logs of the bodies of the next requests.
+func (b *BodyLogger) SetEnabled(enabled bool) {
+	b.enabled.Store(enabled)
+}
+
+// Enabled reports whether the bodies are logged.
+func (b *BodyLogger) Enabled() bool {
+	return b.enabled.Load()
+}
+
+// Handler returns the middleware. The request body is logged as read by the handler, and the requests whose body is
+// not read have none.
+func (b *BodyLogger) Handler(inner http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !b.enabled.Load() {
+			inner.ServeHTTP(w, r)
+
+			return
+		}
+
+		l := &BodyLog{
+			TraceID:            trace.SpanFromContext(r.Context()).SpanContext().TraceID().String(),
+			Method:             r.Method,
+			URI:                r.RequestURI,
+			RequestContentType: r.Header.Get("Content-Type"),
+		}
+
+		var request *bodyCapture
+
+		if r.Body != nil && r.Body != http.NoBody && b.logged(l.RequestContentType) {
+			request = &bodyCapture{max: b.config.MaxSize}
+			r.Body = &bodyLogReader{ReadCloser: r.Body, capture: request}
+		}
+
+		bw := &bodyLogWriter{ResponseWriter: w, logger: b, status: http.StatusOK}
+
+		defer func() {
+			if request != nil {
+				l.RequestBody, l.RequestTruncated = request.String(), request.truncated
+			}
+
+			l.Response, l.ResponseContentType = bw.status, bw.Header().Get("Content-Type")
+
+			if bw.capture != nil {
+				l.ResponseBody, l.ResponseTruncated = bw.capture.String(), bw.capture.truncated
+			}
+
+			b.log(r.Context(), l)
+		}()
+
+		inner.ServeHTTP(bw, r)
+	})
+}
+
+func (b *BodyLogger) log(ctx context.Context, l *BodyLog) {
+	if b.config.Sink != nil {
+		if b.config.Redactor != nil {
+			l, _ = l.Redact(b.config.Redactor).(*BodyLog)
+		}
+
+		b.config.Sink.LogBody(ctx, l)
+
+		return
+	}
+
+	if b.logger != nil {
+		b.logger.Log(l)
+	}
+}
+
+// logged reports whether the bodies of contentType are logged.
+func (b *BodyLogger) logged(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, pattern := range b.config.ContentTypes {
+		if ok, _ := path.Match(strings.ToLower(pattern), mediaType); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bodyCapture keeps the first max bytes of a body.
+type bodyCapture struct {
+	bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (c *bodyCapture) capture(p []byte) {
+	if remaining := c.max - c.Len(); len(p) > remaining {
+		p = p[:max(remaining, 0)]
+		c.truncated = true
+	}
+
+	c.Write(p)
+}
+
+type bodyLogReader struct {
+	io.ReadCloser
+	capture *bodyCapture
+}
+
+func (r *bodyLogReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.capture.capture(p[:n])
+
+	return n, err
+}
+
+// bodyLogWriter captures the status and the body of a response. The content type of the body is known once the
+// header is written.
+type bodyLogWriter struct {
+	http.ResponseWriter
+	logger      *BodyLogger
+	status      int
+	wroteHeader bool
+	capture     *bodyCapture
+}
+
+func (w *bodyLogWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader, w.status = true, status
+
+		if w.logger.logged(w.Header().Get("Content-Type")) {
+			w.capture = &bodyCapture{max: w.logger.config.MaxSize}
+		}
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *bodyLogWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(p))
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+
+	n, err := w.ResponseWriter.Write(p)
+
+	if w.capture != nil {
+		w.capture.capture(p[:n])
+	}
+
+	return n, err
+}
+
+// Flush implements http.Flusher for the streamed responses.
+func (w *bodyLogWriter) Flush() {
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack implements http.Hijacker for the WebSocket upgrades.
+func (w *bodyLogWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Unwrap returns the wrapped writer, for http.ResponseController.
+func (w *bodyLogWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sllt/kite/pkg/kite/logging"
+)
+
+type bodyLogRecorder struct {
+	logs []*BodyLog
+}
+
+func (r *bodyLogRecorder) Log(args ...any) {
+	if l, ok := args[0].(*BodyLog); ok {
+		r.logs = append(r.logs, l)
+	}
+}
+
+func (*bodyLogRecorder) Error(...any) {}
+
+func (r *bodyLogRecorder) LogBody(_ context.Context, l *BodyLog) {
+	r.logs = append(r.logs, l)
+}
+
+func echoHandler(contentType string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(body)
+	})
+}
+
+func TestBodyLogger(t *testing.T) {
+	recorder := &bodyLogRecorder{}
+
+	bodyLogger := NewBodyLogger(BodyLogConfig{MaxSize: 16}, recorder)
+	handler := bodyLogger.Handler(echoHandler("application/json"))
+
+	send := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		return w
+	}
+
+	send(`{"id":1}`)
+	assert.Empty(t, recorder.logs, "disabled by default")
+
+	bodyLogger.SetEnabled(true)
+
+	w := send(`{"id":1,"items":["book","pen"]}`)
+
+	assert.JSONEq(t, `{"id":1,"items":["book","pen"]}`, w.Body.String(), "the response is not truncated")
+	require.Len(t, recorder.logs, 1)
+
+	l := recorder.logs[0]
+
+	assert.Equal(t, "/orders", l.URI)
+	assert.Equal(t, http.StatusCreated, l.Response)
+	assert.Equal(t, `{"id":1,"items":`, l.RequestBody)
+	assert.True(t, l.RequestTruncated)
+	assert.Equal(t, `{"id":1,"items":`, l.ResponseBody)
+	assert.True(t, l.ResponseTruncated)
+}
+
+func TestBodyLogger_ContentTypes(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		contentTypes []string
+		contentType  string
+		logged       bool
+	}{
+		{desc: "default JSON", contentType: "application/json", logged: true},
+		{desc: "default suffix", contentType: "application/problem+json", logged: true},
+		{desc: "default text", contentType: "text/plain; charset=utf-8", logged: true},
+		{desc: "default binary", contentType: "application/octet-stream"},
+		{desc: "custom", contentTypes: []string{"image/*"}, contentType: "image/png", logged: true},
+		{desc: "custom excluded", contentTypes: []string{"image/*"}, contentType: "application/json"},
+		{desc: "invalid", contentType: ";"},
+	}
+
+	for i, tc := range testCases {
+		recorder := &bodyLogRecorder{}
+
+		bodyLogger := NewBodyLogger(BodyLogConfig{ContentTypes: tc.contentTypes}, recorder)
+		bodyLogger.SetEnabled(true)
+
+		req := httptest.NewRequest(http.MethodPost, "/files", strings.NewReader("content"))
+		req.Header.Set("Content-Type", tc.contentType)
+
+		bodyLogger.Handler(echoHandler(tc.contentType)).ServeHTTP(httptest.NewRecorder(), req)
+
+		require.Len(t, recorder.logs, 1, "TEST[%d], Failed.\n%s", i, tc.desc)
+
+		if tc.logged {
+			assert.Equal(t, "content", recorder.logs[0].RequestBody, "TEST[%d], Failed.\n%s", i, tc.desc)
+			assert.Equal(t, "content", recorder.logs[0].ResponseBody, "TEST[%d], Failed.\n%s", i, tc.desc)
+		} else {
+			assert.Empty(t, recorder.logs[0].RequestBody, "TEST[%d], Failed.\n%s", i, tc.desc)
+			assert.Empty(t, recorder.logs[0].ResponseBody, "TEST[%d], Failed.\n%s", i, tc.desc)
+		}
+	}
+}
+
+func TestBodyLogger_SinkRedacted(t *testing.T) {
+	recorder := &bodyLogRecorder{}
+
+	bodyLogger := NewBodyLogger(BodyLogConfig{Sink: recorder, Redactor: logging.NewRedactor()}, nil)
+	bodyLogger.SetEnabled(true)
+
+	req := httptest.NewRequest(http.MethodPost, "/login?token=abc", strings.NewReader("user=ann&password=secret"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	bodyLogger.Handler(echoHandler("application/json")).ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Len(t, recorder.logs, 1)
+	assert.Equal(t, "/login?token=[REDACTED]", recorder.logs[0].URI)
+	assert.Equal(t, "user=ann&password=[REDACTED]", recorder.logs[0].RequestBody)
+}
+
+func TestBodyLog_Redact(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		body      string
+		truncated bool
+		expected  string
+	}{
+		{desc: "JSON", body: `{"user":"ann","password":"secret"}`,
+			expected: `{"password":"[REDACTED]","user":"ann"}`},
+		{desc: "truncated JSON", body: `{"user":"ann","password":"sec`, truncated: true,
+			expected: `{"user":"ann","password":"[REDACTED]"`},
+		{desc: "truncated JSON number", body: `{"cvv":123,"user":"a`, truncated: true,
+			expected: `{"cvv":"[REDACTED]","user":"a`},
+	}
+
+	for i, tc := range testCases {
+		l := &BodyLog{ResponseContentType: "application/json", ResponseBody: tc.body, ResponseTruncated: tc.truncated}
+
+		redacted, _ := l.Redact(logging.NewRedactor()).(*BodyLog)
+
+		assert.Equal(t, tc.expected, redacted.ResponseBody, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, tc.body, l.ResponseBody, "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
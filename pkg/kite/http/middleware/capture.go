@@ -0,0 +1,229 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/sllt/kite/pkg/kite/logging"
+)
+
+const (
+	defaultCaptureSize        = 100
+	defaultCaptureMaxBodySize = 64 << 10 // 64 KiB
+)
+
+// captureMaskedHeaders are the headers masked in the captured requests, even without a Redactor, as they carry the
+// credentials of the clients.
+var captureMaskedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+// CaptureConfig holds the configuration of a RequestCapture. A served request is captured when it matches Routes,
+// MinStatus and Filter.
+type CaptureConfig struct {
+	// Routes restricts the captured requests to routes, by method and route pattern, e.g. "POST /orders". Defaults to
+	// every route.
+	Routes []string
+	// MinStatus restricts the captured requests to the responses of this status or above, e.g. 500 to capture the
+	// requests which failed. Defaults to every status.
+	MinStatus int
+	// Filter decides whether a served request, answered with status, is captured.
+	Filter func(r *http.Request, status int) bool
+	// Size is the number of the last captured requests kept in memory, returned by Captured. Defaults to 100.
+	Size int
+	// File is appended the captured requests, one JSON object per line, which `kite replay` resends.
+	File string
+	// MaxBodySize is the size above which the body of a request is not captured, the request being captured without
+	// it. Defaults to 64 KiB.
+	MaxBodySize int64
+	// Redactor masks the sensitive headers, query parameters and body fields of the captured requests. The
+	// Authorization, Proxy-Authorization and Cookie headers are always masked.
+	Redactor *logging.Redactor
+}
+
+// CapturedRequest is a sanitized copy of a request captured by a RequestCapture.
+type CapturedRequest struct {
+	Time    time.Time   `json:"time"`
+	TraceID string      `json:"trace_id,omitempty"`
+	Method  string      `json:"method"`
+	URI     string      `json:"uri"`
+	Route   string      `json:"route,omitempty"`
+	Header  http.Header `json:"header,omitempty"`
+	Body    string      `json:"body,omitempty"`
+	// BodyOmitted is set when the body was larger than MaxBodySize, the request cannot be replayed as is.
+	BodyOmitted bool `json:"body_omitted,omitempty"`
+	Status      int  `json:"status"`
+	// Duration is the time the request took to serve, in milliseconds.
+	Duration int64 `json:"duration_ms"`
+}
+
+// RequestCapture is a middleware keeping sanitized copies of the requests matching its filter, e.g. the ones failing
+// with a 5xx status, in a ring buffer and in a file, so that `kite replay` resends them to a local build to reproduce
+// the bugs only seen in production. It is disabled until enabled with SetEnabled, which can be called while it serves
+// requests.
+type RequestCapture struct {
+	config  CaptureConfig
+	logger  logger
+	enabled atomic.Bool
+
+	mu       sync.Mutex
+	captured []CapturedRequest
+	// next is the index of the ring buffer overwritten by the next capture, once it is full.
+	next int
+	file *os.File
+}
+
+// NewRequestCapture returns a disabled RequestCapture, appending the captured requests to config.File if set.
+func NewRequestCapture(config CaptureConfig, logger logger) (*RequestCapture, error) {
+	if config.Size <= 0 {
+		config.Size = defaultCaptureSize
+	}
+
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = defaultCaptureMaxBodySize
+	}
+
+	c := &RequestCapture{config: config, logger: logger}
+
+	if config.File != "" {
+		file, err := os.OpenFile(config.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("opening the request capture file: %w", err)
+		}
+
+		c.file = file
+	}
+
+	return c, nil
+}
+
+// SetEnabled enables or disables the capture of the next requests.
+func (c *RequestCapture) SetEnabled(enabled bool) {
+	c.enabled.Store(enabled)
+}
+
+// Enabled reports whether the requests are captured.
+func (c *RequestCapture) Enabled() bool {
+	return c.enabled.Load()
+}
+
+// Captured returns the last captured requests, oldest first.
+func (c *RequestCapture) Captured() []CapturedRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return slices.Concat(c.captured[c.next:], c.captured[:c.next])
+}
+
+// Close closes the capture file.
+func (c *RequestCapture) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.file == nil {
+		return nil
+	}
+
+	err := c.file.Close()
+	c.file = nil
+
+	return err
+}
+
+// Handler returns the middleware. The body of the requests matching Routes is buffered, up to MaxBodySize, as the
+// status deciding whether they are captured is only known once they are served.
+func (c *RequestCapture) Handler(inner http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !c.enabled.Load() || isWellKnown(r.URL.Path) {
+			inner.ServeHTTP(w, r)
+
+			return
+		}
+
+		route := routeKey(r)
+		if len(c.config.Routes) > 0 && !slices.Contains(c.config.Routes, route) {
+			inner.ServeHTTP(w, r)
+
+			return
+		}
+
+		start := time.Now()
+		body, bodyCaptured := bufferShadowBody(r, c.config.MaxBodySize)
+		header := r.Header.Clone()
+		srw := &StatusResponseWriter{ResponseWriter: w}
+
+		inner.ServeHTTP(srw, r)
+
+		status := srw.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		if status < c.config.MinStatus || (c.config.Filter != nil && !c.config.Filter(r, status)) {
+			return
+		}
+
+		c.capture(c.sanitize(&CapturedRequest{
+			Time:        start.UTC(),
+			TraceID:     trace.SpanFromContext(r.Context()).SpanContext().TraceID().String(),
+			Method:      r.Method,
+			URI:         r.RequestURI,
+			Route:       routePattern(r),
+			Header:      header,
+			Body:        string(body),
+			BodyOmitted: !bodyCaptured,
+			Status:      status,
+			Duration:    time.Since(start).Milliseconds(),
+		}))
+	})
+}
+
+// sanitize masks the credentials and the sensitive data of a captured request.
+func (c *RequestCapture) sanitize(req *CapturedRequest) *CapturedRequest {
+	for name, values := range req.Header {
+		for i, value := range values {
+			if slices.Contains(captureMaskedHeaders, name) {
+				values[i] = logging.RedactedValue
+			} else if masked, ok := c.config.Redactor.Field(name, value).(string); ok {
+				values[i] = masked
+			}
+		}
+	}
+
+	req.URI = c.config.Redactor.URL(req.URI)
+	req.Body = redactBody(c.config.Redactor, req.Header.Get("Content-Type"), req.Body, false)
+
+	return req
+}
+
+// capture keeps the request in the ring buffer, and appends it to the file.
+func (c *RequestCapture) capture(req *CapturedRequest) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.captured) < c.config.Size {
+		c.captured = append(c.captured, *req)
+	} else {
+		c.captured[c.next] = *req
+		c.next = (c.next + 1) % c.config.Size
+	}
+
+	if c.file == nil {
+		return
+	}
+
+	line, err := json.Marshal(req)
+	if err == nil {
+		_, err = c.file.Write(append(line, '\n'))
+	}
+
+	if err != nil && c.logger != nil {
+		c.logger.Error(fmt.Sprintf("failed to write the captured request %s %s: %v", req.Method, req.URI, err))
+	}
+}
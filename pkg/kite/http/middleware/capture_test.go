@@ -0,0 +1,202 @@
+package middleware
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sllt/kite/pkg/kite/logging"
+)
+
+// respondWithStatus reads the body of the request, and responds with the status given by its "status" parameter.
+var respondWithStatus = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	_, _ = io.ReadAll(r.Body)
+
+	switch r.URL.Query().Get("status") {
+	case "500":
+		w.WriteHeader(http.StatusInternalServerError)
+	case "404":
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		_, _ = w.Write([]byte("ok"))
+	}
+})
+
+func serveCaptured(handler http.Handler, method, target, body string) {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("X-Api-Key", "key")
+	req.Header.Set("X-Request-Source", "mobile")
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestRequestCapture_Handler(t *testing.T) {
+	tests := []struct {
+		desc     string
+		config   CaptureConfig
+		targets  []string
+		captured []int
+	}{
+		{"every request", CaptureConfig{}, []string{"/orders", "/orders?status=500"},
+			[]int{http.StatusOK, http.StatusInternalServerError}},
+		{"failed requests", CaptureConfig{MinStatus: http.StatusInternalServerError},
+			[]string{"/orders", "/orders?status=404", "/orders?status=500"}, []int{http.StatusInternalServerError}},
+		{"filtered requests", CaptureConfig{Filter: func(_ *http.Request, status int) bool {
+			return status == http.StatusNotFound
+		}}, []string{"/orders?status=404", "/orders?status=500"}, []int{http.StatusNotFound}},
+		{"unmatched routes", CaptureConfig{Routes: []string{"POST /orders"}}, []string{"/orders"}, nil},
+		{"well known endpoints", CaptureConfig{}, []string{"/.well-known/alive"}, nil},
+	}
+
+	for i, tc := range tests {
+		capture, err := NewRequestCapture(tc.config, nil)
+		require.NoError(t, err)
+
+		capture.SetEnabled(true)
+
+		handler := capture.Handler(respondWithStatus)
+
+		for _, target := range tc.targets {
+			serveCaptured(handler, http.MethodPost, target, `{"id":1}`)
+		}
+
+		var statuses []int
+
+		for _, req := range capture.Captured() {
+			statuses = append(statuses, req.Status)
+		}
+
+		assert.Equal(t, tc.captured, statuses, "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestRequestCapture_Disabled(t *testing.T) {
+	capture, err := NewRequestCapture(CaptureConfig{}, nil)
+	require.NoError(t, err)
+
+	serveCaptured(capture.Handler(respondWithStatus), http.MethodPost, "/orders?status=500", "")
+
+	assert.False(t, capture.Enabled())
+	assert.Empty(t, capture.Captured())
+}
+
+func TestRequestCapture_Sanitized(t *testing.T) {
+	capture, err := NewRequestCapture(CaptureConfig{Redactor: logging.NewRedactor()}, nil)
+	require.NoError(t, err)
+
+	capture.SetEnabled(true)
+
+	serveCaptured(capture.Handler(respondWithStatus), http.MethodPost, "/orders?status=500&token=abc",
+		`{"id":1,"password":"hunter2"}`)
+
+	captured := capture.Captured()
+	require.Len(t, captured, 1)
+
+	req := captured[0]
+
+	assert.Equal(t, http.MethodPost, req.Method)
+	assert.Equal(t, "/orders?status=500&token="+logging.RedactedValue, req.URI)
+	assert.JSONEq(t, `{"id":1,"password":"`+logging.RedactedValue+`"}`, req.Body)
+	assert.Equal(t, logging.RedactedValue, req.Header.Get("Authorization"))
+	assert.Equal(t, logging.RedactedValue, req.Header.Get("X-Api-Key"))
+	assert.Equal(t, "mobile", req.Header.Get("X-Request-Source"))
+	assert.False(t, req.BodyOmitted)
+}
+
+func TestRequestCapture_BodyOmitted(t *testing.T) {
+	capture, err := NewRequestCapture(CaptureConfig{MaxBodySize: 4}, nil)
+	require.NoError(t, err)
+
+	capture.SetEnabled(true)
+
+	var read string
+
+	handler := capture.Handler(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		read = string(body)
+	}))
+
+	serveCaptured(handler, http.MethodPost, "/orders", `{"id":1}`)
+
+	captured := capture.Captured()
+	require.Len(t, captured, 1)
+
+	assert.Equal(t, `{"id":1}`, read, "the handler must read the whole body")
+	assert.True(t, captured[0].BodyOmitted)
+	assert.Empty(t, captured[0].Body)
+}
+
+func TestRequestCapture_RingBuffer(t *testing.T) {
+	capture, err := NewRequestCapture(CaptureConfig{Size: 2}, nil)
+	require.NoError(t, err)
+
+	capture.SetEnabled(true)
+
+	handler := capture.Handler(respondWithStatus)
+
+	for _, target := range []string{"/orders/1", "/orders/2", "/orders/3"} {
+		serveCaptured(handler, http.MethodGet, target, "")
+	}
+
+	var uris []string
+
+	for _, req := range capture.Captured() {
+		uris = append(uris, req.URI)
+	}
+
+	assert.Equal(t, []string{"/orders/2", "/orders/3"}, uris)
+}
+
+func TestRequestCapture_File(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "captured.jsonl")
+
+	capture, err := NewRequestCapture(CaptureConfig{File: file}, nil)
+	require.NoError(t, err)
+
+	capture.SetEnabled(true)
+
+	handler := capture.Handler(respondWithStatus)
+
+	serveCaptured(handler, http.MethodPost, "/orders?status=500", `{"id":1}`)
+	serveCaptured(handler, http.MethodPost, "/orders?status=404", `{"id":2}`)
+
+	require.NoError(t, capture.Close())
+	require.NoError(t, capture.Close())
+
+	f, err := os.Open(file)
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	var captured []CapturedRequest
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var req CapturedRequest
+
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &req))
+
+		captured = append(captured, req)
+	}
+
+	require.Len(t, captured, 2)
+	assert.Equal(t, http.StatusInternalServerError, captured[0].Status)
+	assert.JSONEq(t, `{"id":2}`, captured[1].Body)
+}
+
+func TestNewRequestCapture_FileError(t *testing.T) {
+	_, err := NewRequestCapture(CaptureConfig{File: filepath.Join(t.TempDir(), "missing", "captured.jsonl")}, nil)
+
+	require.Error(t, err)
+}
@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	kiteHttp "github.com/sllt/kite/pkg/kite/http"
+)
+
+var errInvalidTrustedProxy = errors.New("invalid trusted proxy, expected an IP or a CIDR")
+
+// TrustedProxies are the networks of the reverse proxies, e.g. load balancers, whose forwarding headers are trusted to
+// resolve the address of the client.
+type TrustedProxies []netip.Prefix
+
+// ParseTrustedProxies parses a list of IPs and CIDRs, e.g. "10.0.0.0/8" or "192.168.1.10".
+func ParseTrustedProxies(entries []string) (TrustedProxies, error) {
+	proxies := make(TrustedProxies, 0, len(entries))
+
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %q", errInvalidTrustedProxy, entry)
+			}
+
+			proxies = append(proxies, prefix.Masked())
+
+			continue
+		}
+
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", errInvalidTrustedProxy, entry)
+		}
+
+		addr = addr.Unmap()
+		proxies = append(proxies, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+
+	return proxies, nil
+}
+
+func (p TrustedProxies) trusts(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	for _, prefix := range p {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ClientIP resolves the address of the client of r. The forwarding headers are only used when the peer is a trusted
+// proxy: the RFC 7239 Forwarded header, or X-Forwarded-For, is read from right to left and the first address which
+// is not a trusted proxy is the client. X-Real-IP is used when neither is set.
+func (p TrustedProxies) ClientIP(r *http.Request) string {
+	peer := getRemoteAddr(r)
+
+	addr, err := netip.ParseAddr(peer)
+	if err != nil || !p.trusts(addr) {
+		return peer
+	}
+
+	chain := forwardedFor(r.Header.Values("Forwarded"))
+	if len(chain) == 0 {
+		chain = xForwardedFor(r.Header.Values("X-Forwarded-For"))
+	}
+
+	if len(chain) == 0 {
+		if realIP, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+			return realIP.Unmap().String()
+		}
+
+		return peer
+	}
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(chain[i])
+		if err != nil {
+			// an address which cannot be parsed, e.g. an obfuscated identifier, was not added by a trusted proxy.
+			return chain[i]
+		}
+
+		if !p.trusts(addr) {
+			return addr.Unmap().String()
+		}
+	}
+
+	// all the hops are trusted proxies, the first one is the client.
+	return chain[0]
+}
+
+// ClientIP returns a middleware which resolves the address of the client with the trusted proxies, it is then
+// available with kiteHttp.ClientIPFromContext.
+func ClientIP(proxies TrustedProxies) func(http.Handler) http.Handler {
+	return func(inner http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := kiteHttp.WithClientIP(r.Context(), proxies.ClientIP(r))
+
+			inner.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// xForwardedFor returns the addresses of the X-Forwarded-For headers, from the client to the last proxy.
+func xForwardedFor(values []string) []string {
+	var chain []string
+
+	for _, value := range values {
+		for _, ip := range strings.Split(value, ",") {
+			if ip = strings.TrimSpace(ip); ip != "" {
+				chain = append(chain, ip)
+			}
+		}
+	}
+
+	return chain
+}
+
+// forwardedFor returns the "for" addresses of the RFC 7239 Forwarded headers, from the client to the last proxy,
+// without the quotes, the brackets of IPv6 addresses and the ports.
+func forwardedFor(values []string) []string {
+	var chain []string
+
+	for _, value := range values {
+		for _, element := range strings.Split(value, ",") {
+			for _, pair := range strings.Split(element, ";") {
+				name, node, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if !ok || !strings.EqualFold(name, "for") {
+					continue
+				}
+
+				chain = append(chain, forwardedNode(node))
+			}
+		}
+	}
+
+	return chain
+}
+
+// forwardedNode strips the quotes, the brackets and the port of a node of the Forwarded header, e.g.
+// "[2001:db8::1]:4711" or 192.0.2.60:8080.
+func forwardedNode(node string) string {
+	node = strings.Trim(strings.TrimSpace(node), `"`)
+
+	if strings.HasPrefix(node, "[") {
+		if end := strings.Index(node, "]"); end > 0 {
+			return node[1:end]
+		}
+	}
+
+	if host, _, err := net.SplitHostPort(node); err == nil {
+		return host
+	}
+
+	return node
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	kiteHttp "github.com/sllt/kite/pkg/kite/http"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", " 192.168.1.10 ", "", "2001:db8::/32"})
+
+	require.NoError(t, err)
+	assert.Len(t, proxies, 3)
+
+	_, err = ParseTrustedProxies([]string{"10.0.0.0/8", "proxy.internal"})
+
+	require.ErrorIs(t, err, errInvalidTrustedProxy)
+	assert.Contains(t, err.Error(), "proxy.internal")
+
+	_, err = ParseTrustedProxies([]string{"10.0.0.0/33"})
+
+	require.ErrorIs(t, err, errInvalidTrustedProxy)
+}
+
+func TestTrustedProxies_ClientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.10"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		desc       string
+		remoteAddr string
+		headers    map[string]string
+		expected   string
+	}{
+		{"untrusted peer, headers are ignored", "203.0.113.7:4000",
+			map[string]string{"X-Forwarded-For": "198.51.100.1", "X-Real-IP": "198.51.100.2"}, "203.0.113.7"},
+		{"trusted peer without headers", "10.1.2.3:4000", nil, "10.1.2.3"},
+		{"rightmost untrusted address of X-Forwarded-For", "10.1.2.3:4000",
+			map[string]string{"X-Forwarded-For": "6.6.6.6, 198.51.100.1, 10.0.0.5"}, "198.51.100.1"},
+		{"all the hops are trusted", "10.1.2.3:4000",
+			map[string]string{"X-Forwarded-For": "10.0.0.9, 192.168.1.10"}, "10.0.0.9"},
+		{"X-Real-IP", "192.168.1.10:4000", map[string]string{"X-Real-IP": " 198.51.100.2 "}, "198.51.100.2"},
+		{"invalid X-Real-IP", "192.168.1.10:4000", map[string]string{"X-Real-IP": "unknown"}, "192.168.1.10"},
+		{"Forwarded takes precedence", "10.1.2.3:4000", map[string]string{
+			"Forwarded":       `for=198.51.100.3;proto=https, for="10.0.0.7:8080"`,
+			"X-Forwarded-For": "198.51.100.1",
+		}, "198.51.100.3"},
+		{"Forwarded with IPv6", "10.1.2.3:4000",
+			map[string]string{"Forwarded": `For="[2001:db8:cafe::17]:4711"`}, "2001:db8:cafe::17"},
+		{"Forwarded with an obfuscated identifier", "10.1.2.3:4000",
+			map[string]string{"Forwarded": "for=_hidden"}, "_hidden"},
+	}
+
+	for i, tc := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.RemoteAddr = tc.remoteAddr
+
+		for k, v := range tc.headers {
+			req.Header.Set(k, v)
+		}
+
+		assert.Equal(t, tc.expected, proxies.ClientIP(req), "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestClientIP_Middleware(t *testing.T) {
+	var clientIP string
+
+	handler := ClientIP(TrustedProxies{})(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		clientIP = kiteHttp.ClientIPFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	req.RemoteAddr = "203.0.113.7:4000"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "203.0.113.7", clientIP)
+}
+
+func TestGetIP_ResolvedClientIP(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/test", http.NoBody)
+	req.RemoteAddr = "10.0.0.1:12345"
+	req = req.WithContext(kiteHttp.WithClientIP(req.Context(), "198.51.100.1"))
+
+	assert.Equal(t, "198.51.100.1", getIP(req, false), "the client IP resolved with the trusted proxies is used")
+	assert.Equal(t, "198.51.100.1", getIPAddress(req))
+}
@@ -0,0 +1,184 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	kiteHttp "github.com/sllt/kite/pkg/kite/http"
+)
+
+const (
+	defaultConcurrencyRetryAfter = time.Second
+
+	// globalConcurrencyLimit is the "limit" label of the metrics of the server-wide limit.
+	globalConcurrencyLimit = "global"
+)
+
+// ConcurrencyLimitConfig holds the configuration of ConcurrencyLimiter.
+type ConcurrencyLimitConfig struct {
+	// MaxInFlight caps the requests served at once by the server, 0 disables the server-wide limit.
+	MaxInFlight int
+	// Routes caps the requests served at once per route, keyed by method and route pattern, e.g. "GET /users/{id}".
+	Routes map[string]int
+	// QueueTimeout is how long a request waits for a free slot before it is shed, requests are shed right away when 0.
+	QueueTimeout time.Duration
+	// RetryAfter is sent in the Retry-After header of the shed requests, 1 second by default.
+	RetryAfter time.Duration
+}
+
+// ConcurrencyLimiter creates a middleware that caps the number of requests served at once, globally and per route,
+// to protect the downstream dependencies during traffic spikes. A request waits up to QueueTimeout for a free slot,
+// and is answered with 503 Service Unavailable and a Retry-After header if none frees up.
+//
+// The number of requests in flight is reported by the app_http_requests_in_flight gauge and the shed requests by the
+// app_http_requests_shed_total counter, both labelled by "limit": "global" or the route, e.g. "GET /users/{id}".
+// Health check endpoints are never limited.
+func ConcurrencyLimiter(config ConcurrencyLimitConfig, m metrics) func(http.Handler) http.Handler {
+	if config.RetryAfter <= 0 {
+		config.RetryAfter = defaultConcurrencyRetryAfter
+	}
+
+	var global *semaphore
+	if config.MaxInFlight > 0 {
+		global = newSemaphore(globalConcurrencyLimit, config.MaxInFlight)
+	}
+
+	routes := make(map[string]*semaphore, len(config.Routes))
+
+	for route, limit := range config.Routes {
+		if limit > 0 {
+			routes[route] = newSemaphore(route, limit)
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isWellKnown(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// the route slot is taken first, so that requests queued on a busy route do not hold a global slot.
+			sems := make([]*semaphore, 0, 2)
+
+			if len(routes) > 0 {
+				if sem, ok := routes[routeKey(r)]; ok {
+					sems = append(sems, sem)
+				}
+			}
+
+			if global != nil {
+				sems = append(sems, global)
+			}
+
+			for i, sem := range sems {
+				if !sem.acquire(r, config.QueueTimeout) {
+					for _, acquired := range sems[:i] {
+						acquired.release(m)
+					}
+
+					shedRequest(w, r, sem, config.RetryAfter, m)
+
+					return
+				}
+
+				sem.recordInFlight(m)
+			}
+
+			defer func() {
+				for _, sem := range sems {
+					sem.release(m)
+				}
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// routeKey returns the method and the pattern of the route matching the request, e.g. "GET /users/{id}".
+// It is empty when no route matches.
+func routeKey(r *http.Request) string {
+	pattern := routePattern(r)
+	if pattern == "" {
+		return ""
+	}
+
+	return r.Method + " " + pattern
+}
+
+// routePattern returns the pattern of the route matching r, or "" when the router is unknown or no route matches.
+func routePattern(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil || rctx.Routes == nil {
+		return ""
+	}
+
+	return rctx.Routes.Find(chi.NewRouteContext(), r.Method, r.URL.Path)
+}
+
+func shedRequest(w http.ResponseWriter, r *http.Request, sem *semaphore, retryAfter time.Duration, m metrics) {
+	if m != nil {
+		m.IncrementCounter(r.Context(), "app_http_requests_shed_total", "limit", sem.name)
+	}
+
+	respondShed(w, r, retryAfter)
+}
+
+// respondShed answers a shed request with 503 Service Unavailable, telling the client to retry after retryAfter.
+func respondShed(w http.ResponseWriter, r *http.Request, retryAfter time.Duration) {
+	w.Header().Set("Retry-After", fmt.Sprintf("%.0f", math.Ceil(retryAfter.Seconds())))
+
+	kiteHttp.NewResponder(w, r.Method).Respond(nil, kiteHttp.ErrorServiceUnavailable{})
+}
+
+// semaphore bounds the requests served at once under a limit.
+type semaphore struct {
+	name  string
+	slots chan struct{}
+}
+
+func newSemaphore(name string, limit int) *semaphore {
+	return &semaphore{name: name, slots: make(chan struct{}, limit)}
+}
+
+// acquire takes a slot, waiting up to timeout for one to free up. It gives up when the client goes away.
+func (s *semaphore) acquire(r *http.Request, timeout time.Duration) bool {
+	select {
+	case s.slots <- struct{}{}:
+		return true
+	default:
+	}
+
+	if timeout <= 0 {
+		return false
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case s.slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+func (s *semaphore) release(m metrics) {
+	<-s.slots
+
+	s.recordInFlight(m)
+}
+
+func (s *semaphore) recordInFlight(m metrics) {
+	if m != nil {
+		m.SetGauge("app_http_requests_in_flight", float64(len(s.slots)), "limit", s.name)
+	}
+}
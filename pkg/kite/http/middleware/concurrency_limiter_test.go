@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingRouter returns a router serving GET /users/{id} and GET /orders, whose handlers block until release
+// is closed. started receives a value each time a handler starts.
+func blockingRouter(limiter func(http.Handler) http.Handler, started chan<- struct{}, release <-chan struct{}) http.Handler {
+	r := chi.NewRouter()
+	r.Use(limiter)
+
+	h := func(w http.ResponseWriter, _ *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}
+
+	r.Get("/users/{id}", h)
+	r.Get("/orders", h)
+	r.Get("/.well-known/alive", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	return r
+}
+
+func serve(h http.Handler, path string) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, http.NoBody))
+
+	return rr
+}
+
+func TestConcurrencyLimiter_Global(t *testing.T) {
+	metrics := newRateLimiterMockMetrics()
+	started, release := make(chan struct{}, 2), make(chan struct{})
+
+	h := blockingRouter(ConcurrencyLimiter(ConcurrencyLimitConfig{MaxInFlight: 2, RetryAfter: 3 * time.Second}, metrics),
+		started, release)
+
+	var wg sync.WaitGroup
+
+	for _, path := range []string{"/users/1", "/orders"} {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, http.StatusOK, serve(h, path).Code)
+		}()
+
+		<-started
+	}
+
+	rr := serve(h, "/users/2")
+
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, "3", rr.Header().Get("Retry-After"))
+	assert.Equal(t, 1, metrics.GetCounter("app_http_requests_shed_total"))
+
+	assert.Equal(t, http.StatusOK, serve(h, "/.well-known/alive").Code, "health checks must not be limited")
+
+	close(release)
+	wg.Wait()
+
+	go func() { <-started }()
+
+	assert.Equal(t, http.StatusOK, serve(h, "/orders").Code, "slots must be released")
+}
+
+func TestConcurrencyLimiter_PerRoute(t *testing.T) {
+	started, release := make(chan struct{}, 3), make(chan struct{})
+
+	h := blockingRouter(ConcurrencyLimiter(ConcurrencyLimitConfig{
+		MaxInFlight: 10,
+		Routes:      map[string]int{"GET /users/{id}": 1},
+	}, nil), started, release)
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		serve(h, "/users/1")
+	}()
+
+	<-started
+
+	assert.Equal(t, http.StatusServiceUnavailable, serve(h, "/users/2").Code)
+	assert.Equal(t, "1", serve(h, "/users/3").Header().Get("Retry-After"))
+
+	go func() {
+		serve(h, "/orders")
+	}()
+
+	<-started
+
+	close(release)
+	<-done
+}
+
+func TestConcurrencyLimiter_QueueTimeout(t *testing.T) {
+	started, release := make(chan struct{}, 2), make(chan struct{})
+
+	h := blockingRouter(ConcurrencyLimiter(ConcurrencyLimitConfig{MaxInFlight: 1, QueueTimeout: time.Second}, nil),
+		started, release)
+
+	go func() {
+		serve(h, "/orders")
+	}()
+
+	<-started
+
+	queued := make(chan int)
+
+	go func() {
+		queued <- serve(h, "/orders").Code
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	<-started
+	require.Equal(t, http.StatusOK, <-queued, "the queued request must be served once a slot frees up")
+}
@@ -3,6 +3,7 @@ package middleware
 import (
 	"strconv"
 	"strings"
+	"time"
 
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
@@ -14,6 +15,18 @@ import (
 type Config struct {
 	CorsHeaders map[string]string
 	LogProbes   LogProbes
+	// AccessLogSampling samples the access logs by status, see ACCESS_LOG_SAMPLING.
+	AccessLogSampling []AccessLogSampleRule
+	// SecurityHeaders is nil when the security headers are disabled.
+	SecurityHeaders *SecurityHeadersConfig
+	// MethodOverride enables routing POST requests with the X-HTTP-Method-Override header, see MethodOverride.
+	MethodOverride bool
+	// TrustedProxies are the IPs and CIDRs of TRUSTED_PROXIES, whose forwarding headers resolve the client IP.
+	TrustedProxies []string
+	// ConcurrencyLimit is nil when no concurrency limit is configured, see ConcurrencyLimiter.
+	ConcurrencyLimit *ConcurrencyLimitConfig
+	// DeadlineOverhead is subtracted from the deadline of the callers of the requests, see DeadlineBudget.
+	DeadlineOverhead time.Duration
 }
 
 type LogProbes struct {
@@ -50,9 +63,84 @@ func GetConfigs(c config.Config) Config {
 		middlewareConfigs.LogProbes.Disabled = value
 	}
 
+	middlewareConfigs.AccessLogSampling = parseAccessLogSampling(c.Get("ACCESS_LOG_SAMPLING"))
+
+	middlewareConfigs.SecurityHeaders = getSecurityHeadersConfig(c)
+
+	if value, err := strconv.ParseBool(c.GetOrDefault("HTTP_METHOD_OVERRIDE", "false")); err == nil {
+		middlewareConfigs.MethodOverride = value
+	}
+
+	if trustedProxies := c.Get("TRUSTED_PROXIES"); trustedProxies != "" {
+		middlewareConfigs.TrustedProxies = strings.Split(trustedProxies, ",")
+	}
+
+	middlewareConfigs.ConcurrencyLimit = getConcurrencyLimitConfig(c)
+
+	if overhead, err := time.ParseDuration(c.Get("REQUEST_DEADLINE_OVERHEAD")); err == nil && overhead > 0 {
+		middlewareConfigs.DeadlineOverhead = overhead
+	}
+
 	return middlewareConfigs
 }
 
+// getConcurrencyLimitConfig reads HTTP_MAX_CONCURRENT_REQUESTS, HTTP_ROUTE_CONCURRENCY_LIMITS, e.g.
+// "GET /users/{id}=10,POST /orders=5", and HTTP_CONCURRENCY_QUEUE_TIMEOUT, e.g. "100ms". Invalid values are ignored.
+// It returns nil when neither limit is set.
+func getConcurrencyLimitConfig(c config.Config) *ConcurrencyLimitConfig {
+	var limits ConcurrencyLimitConfig
+
+	if maxInFlight, err := strconv.Atoi(c.Get("HTTP_MAX_CONCURRENT_REQUESTS")); err == nil && maxInFlight > 0 {
+		limits.MaxInFlight = maxInFlight
+	}
+
+	for _, routeLimit := range strings.Split(c.Get("HTTP_ROUTE_CONCURRENCY_LIMITS"), ",") {
+		route, value, ok := strings.Cut(routeLimit, "=")
+		if !ok {
+			continue
+		}
+
+		limit, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || limit <= 0 {
+			continue
+		}
+
+		if limits.Routes == nil {
+			limits.Routes = make(map[string]int)
+		}
+
+		limits.Routes[strings.Join(strings.Fields(route), " ")] = limit
+	}
+
+	if limits.MaxInFlight == 0 && len(limits.Routes) == 0 {
+		return nil
+	}
+
+	if timeout, err := time.ParseDuration(c.Get("HTTP_CONCURRENCY_QUEUE_TIMEOUT")); err == nil && timeout > 0 {
+		limits.QueueTimeout = timeout
+	}
+
+	return &limits
+}
+
+// getSecurityHeadersConfig reads the security headers configs. The headers are enabled by the presets of staging and
+// production, see config.Environment.
+func getSecurityHeadersConfig(c config.Config) *SecurityHeadersConfig {
+	enabled, err := strconv.ParseBool(config.GetOrPreset(c, "SECURITY_HEADERS_ENABLED", "false"))
+	if err != nil || !enabled {
+		return nil
+	}
+
+	securityHeaders := DefaultSecurityHeadersConfig()
+	securityHeaders.ContentSecurityPolicy = c.Get("SECURITY_HEADERS_CSP")
+
+	if maxAge, err := strconv.Atoi(c.Get("SECURITY_HEADERS_HSTS_MAX_AGE")); err == nil && maxAge >= 0 {
+		securityHeaders.HSTSMaxAge = time.Duration(maxAge) * time.Second
+	}
+
+	return &securityHeaders
+}
+
 func convertHeaderNames(header string) string {
 	words := strings.Split(header, "_")
 	titleCaser := cases.Title(language.Und)
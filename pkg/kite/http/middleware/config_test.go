@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -37,3 +38,91 @@ func TestLogDisableProbesConfig(t *testing.T) {
 
 	assert.True(t, middlewareConfigs.LogProbes.Disabled, "TestLogDisableProbesConfig Failed!")
 }
+
+func TestMethodOverrideConfig(t *testing.T) {
+	assert.False(t, GetConfigs(config.NewMockConfig(nil)).MethodOverride)
+
+	middlewareConfigs := GetConfigs(config.NewMockConfig(map[string]string{
+		"HTTP_METHOD_OVERRIDE": "true",
+	}))
+
+	assert.True(t, middlewareConfigs.MethodOverride, "TestMethodOverrideConfig Failed!")
+}
+
+func TestTrustedProxiesConfig(t *testing.T) {
+	assert.Nil(t, GetConfigs(config.NewMockConfig(nil)).TrustedProxies)
+
+	middlewareConfigs := GetConfigs(config.NewMockConfig(map[string]string{
+		"TRUSTED_PROXIES": "10.0.0.0/8, 192.168.1.10",
+	}))
+
+	assert.Equal(t, []string{"10.0.0.0/8", " 192.168.1.10"}, middlewareConfigs.TrustedProxies)
+}
+
+func TestSecurityHeadersConfig(t *testing.T) {
+	tests := []struct {
+		desc     string
+		configs  map[string]string
+		expected *SecurityHeadersConfig
+	}{
+		{"disabled outside production", map[string]string{"APP_ENV": "dev"}, nil},
+		{"enabled in production", map[string]string{"APP_ENV": "production"}, &SecurityHeadersConfig{
+			HSTSMaxAge:            365 * 24 * time.Hour,
+			HSTSIncludeSubdomains: true,
+			ContentTypeOptions:    "nosniff",
+			FrameOptions:          "DENY",
+			ReferrerPolicy:        "strict-origin-when-cross-origin",
+		}},
+		{"enabled in staging", map[string]string{"APP_ENV": "stage"}, &SecurityHeadersConfig{
+			HSTSMaxAge:            365 * 24 * time.Hour,
+			HSTSIncludeSubdomains: true,
+			ContentTypeOptions:    "nosniff",
+			FrameOptions:          "DENY",
+			ReferrerPolicy:        "strict-origin-when-cross-origin",
+		}},
+		{"disabled in production", map[string]string{"APP_ENV": "production", "SECURITY_HEADERS_ENABLED": "false"}, nil},
+		{"enabled with overrides", map[string]string{
+			"SECURITY_HEADERS_ENABLED":      "true",
+			"SECURITY_HEADERS_CSP":          "default-src 'self'",
+			"SECURITY_HEADERS_HSTS_MAX_AGE": "0",
+		}, &SecurityHeadersConfig{
+			HSTSIncludeSubdomains: true,
+			ContentTypeOptions:    "nosniff",
+			FrameOptions:          "DENY",
+			ReferrerPolicy:        "strict-origin-when-cross-origin",
+			ContentSecurityPolicy: "default-src 'self'",
+		}},
+	}
+
+	for i, tc := range tests {
+		middlewareConfigs := GetConfigs(config.NewMockConfig(tc.configs))
+
+		assert.Equal(t, tc.expected, middlewareConfigs.SecurityHeaders, "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestConcurrencyLimitConfig(t *testing.T) {
+	assert.Nil(t, GetConfigs(config.NewMockConfig(nil)).ConcurrencyLimit)
+
+	middlewareConfigs := GetConfigs(config.NewMockConfig(map[string]string{
+		"HTTP_MAX_CONCURRENT_REQUESTS":   "100",
+		"HTTP_ROUTE_CONCURRENCY_LIMITS":  "GET  /users/{id}=10, POST /orders=5,PUT /orders=abc",
+		"HTTP_CONCURRENCY_QUEUE_TIMEOUT": "250ms",
+	}))
+
+	assert.Equal(t, &ConcurrencyLimitConfig{
+		MaxInFlight:  100,
+		Routes:       map[string]int{"GET /users/{id}": 10, "POST /orders": 5},
+		QueueTimeout: 250 * time.Millisecond,
+	}, middlewareConfigs.ConcurrencyLimit)
+}
+
+func TestDeadlineOverheadConfig(t *testing.T) {
+	assert.Zero(t, GetConfigs(config.NewMockConfig(nil)).DeadlineOverhead)
+
+	middlewareConfigs := GetConfigs(config.NewMockConfig(map[string]string{
+		"REQUEST_DEADLINE_OVERHEAD": "20ms",
+	}))
+
+	assert.Equal(t, 20*time.Millisecond, middlewareConfigs.DeadlineOverhead)
+}
@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	kiteHttp "github.com/sllt/kite/pkg/kite/http"
+	"github.com/sllt/kite/pkg/kite/service"
+)
+
+type deadlineLogger interface {
+	Warnf(format string, args ...any)
+}
+
+// DeadlineBudget creates a middleware which applies the deadline of the caller to the request. The time the caller
+// waits for the response is read from the X-Request-Timeout header, in milliseconds, or else the grpc-timeout header,
+// e.g. of gRPC-Web and gRPC-gateway calls. overhead is subtracted from it to leave time for the response to travel
+// back, and the rest is set as the deadline of the context of the request, which is also the one of the calls made by
+// the handler: the HTTP services, which forward it in X-Request-Timeout, the gRPC clients and the datasources.
+//
+// Requests with no budget left are doomed to miss their deadline: they are logged and answered with a timeout
+// without calling the handler.
+func DeadlineBudget(overhead time.Duration, logger deadlineLogger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			timeout, ok := incomingTimeout(r.Header)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			budget := timeout - overhead
+			if budget <= 0 {
+				logger.Warnf("%s %s is doomed to miss its deadline: the caller waits %v, less than the overhead of %v",
+					r.Method, r.URL.Path, timeout, overhead)
+
+				kiteHttp.NewResponder(w, r.Method).Respond(nil, kiteHttp.ErrorRequestTimeout{})
+
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), budget)
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// incomingTimeout returns the time the caller waits for the response, read from the X-Request-Timeout or
+// grpc-timeout header.
+func incomingTimeout(h http.Header) (time.Duration, bool) {
+	if value := h.Get(service.RequestTimeoutHeader); value != "" {
+		ms, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil || ms < 0 || ms > math.MaxInt64/int64(time.Millisecond) {
+			return 0, false
+		}
+
+		return time.Duration(ms) * time.Millisecond, true
+	}
+
+	if value := h.Get("Grpc-Timeout"); value != "" {
+		return parseGRPCTimeout(value)
+	}
+
+	return 0, false
+}
+
+// grpcTimeoutUnits are the units of the grpc-timeout header, see
+// https://github.com/grpc/grpc/blob/master/doc/PROTOCOL-HTTP2.md.
+var grpcTimeoutUnits = map[byte]time.Duration{
+	'H': time.Hour,
+	'M': time.Minute,
+	'S': time.Second,
+	'm': time.Millisecond,
+	'u': time.Microsecond,
+	'n': time.Nanosecond,
+}
+
+// parseGRPCTimeout parses a grpc-timeout header, a positive integer of at most 8 digits followed by a unit,
+// e.g. "250m" for 250 milliseconds.
+func parseGRPCTimeout(value string) (time.Duration, bool) {
+	const maxDigits = 8
+
+	if len(value) < 2 || len(value) > maxDigits+1 {
+		return 0, false
+	}
+
+	unit, ok := grpcTimeoutUnits[value[len(value)-1]]
+	if !ok {
+		return 0, false
+	}
+
+	n, err := strconv.ParseInt(value[:len(value)-1], 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+
+	// timeouts too long to be represented, e.g. 99999999H, do not limit the request.
+	if n > math.MaxInt64/int64(unit) {
+		return 0, false
+	}
+
+	return time.Duration(n) * unit, true
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type deadlineMockLogger struct {
+	logs []string
+}
+
+func (l *deadlineMockLogger) Warnf(format string, args ...any) {
+	l.logs = append(l.logs, fmt.Sprintf(format, args...))
+}
+
+func TestDeadlineBudget(t *testing.T) {
+	tests := []struct {
+		desc     string
+		headers  map[string]string
+		expected time.Duration
+	}{
+		{"X-Request-Timeout", map[string]string{"X-Request-Timeout": "1500"}, 1400 * time.Millisecond},
+		{"grpc-timeout", map[string]string{"Grpc-Timeout": "2S"}, 1900 * time.Millisecond},
+		{"X-Request-Timeout first", map[string]string{"X-Request-Timeout": "500", "Grpc-Timeout": "2S"},
+			400 * time.Millisecond},
+		{"no header", nil, 0},
+		{"invalid header", map[string]string{"X-Request-Timeout": "1s"}, 0},
+	}
+
+	for i, tc := range tests {
+		var remaining time.Duration
+
+		h := DeadlineBudget(100*time.Millisecond, &deadlineMockLogger{})(http.HandlerFunc(func(_ http.ResponseWriter,
+			r *http.Request) {
+			if deadline, ok := r.Context().Deadline(); ok {
+				remaining = time.Until(deadline)
+			}
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/orders", http.NoBody)
+		for k, v := range tc.headers {
+			req.Header.Set(k, v)
+		}
+
+		h.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.InDelta(t, tc.expected, remaining, float64(20*time.Millisecond), "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestDeadlineBudget_Doomed(t *testing.T) {
+	logger := &deadlineMockLogger{}
+
+	h := DeadlineBudget(100*time.Millisecond, logger)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		t.Error("the handler of a doomed request must not be called")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/orders", http.NoBody)
+	req.Header.Set("X-Request-Timeout", "50")
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusRequestTimeout, rr.Code)
+	require.Len(t, logger.logs, 1)
+	assert.Contains(t, logger.logs[0], "GET /orders is doomed to miss its deadline")
+}
+
+func TestParseGRPCTimeout(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{"250m", 250 * time.Millisecond, true},
+		{"1H", time.Hour, true},
+		{"30u", 30 * time.Microsecond, true},
+		{"99999999H", 0, false},
+		{"123456789S", 0, false},
+		{"10", 0, false},
+		{"m", 0, false},
+		{"-1S", 0, false},
+	}
+
+	for i, tc := range tests {
+		d, ok := parseGRPCTimeout(tc.value)
+
+		assert.Equal(t, tc.ok, ok, "TEST[%d], Failed.\n%s", i, tc.value)
+		assert.Equal(t, tc.expected, d, "TEST[%d], Failed.\n%s", i, tc.value)
+	}
+}
@@ -0,0 +1,248 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	kiteHttp "github.com/sllt/kite/pkg/kite/http"
+)
+
+const (
+	// IdempotencyKeyHeader is the default request header carrying the idempotency key.
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// IdempotentReplayedHeader is set on responses that were replayed from the idempotency store.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	defaultIdempotencyTTL     = 24 * time.Hour
+	defaultIdempotencyLockTTL = 30 * time.Second
+	maxIdempotencyKeyLength   = 255
+)
+
+// IdempotencyConfig holds configuration for the idempotency middleware.
+type IdempotencyConfig struct {
+	// Store persists responses and locks. Defaults to an in-memory store, which is only
+	// suitable for single-instance deployments; use NewRedisIdempotencyStore or
+	// NewSQLIdempotencyStore when running multiple replicas.
+	Store IdempotencyStore
+	// TTL is how long a stored response is replayed for retries. Defaults to 24 hours.
+	TTL time.Duration
+	// LockTTL bounds how long a request holds the lock for its key, so that a crashed
+	// instance does not block retries forever. Defaults to 30 seconds.
+	LockTTL time.Duration
+	// Header is the request header carrying the key. Defaults to "Idempotency-Key".
+	Header string
+	// Methods restricts the HTTP methods the middleware applies to. Defaults to POST, PUT, PATCH and DELETE.
+	Methods []string
+	// Required rejects requests of the configured methods which do not carry a key.
+	Required bool
+}
+
+// Idempotency creates a middleware that honors the Idempotency-Key request header.
+//
+// The first response (status, headers and body) for a given method, path and key is stored and replayed
+// to retries within the configured TTL, with the "Idempotent-Replayed: true" header set. Concurrent requests
+// with a key that is still being processed are rejected with 409 Conflict, and reusing a key with a different
+// request body is rejected with 422 Unprocessable Entity. Server errors (5xx) are not stored, so clients can
+// safely retry them.
+//
+// Unlike the rate limiter, the middleware fails closed: if the store cannot be reached the request is
+// rejected with 503 Service Unavailable instead of risking a duplicate side effect.
+func Idempotency(config IdempotencyConfig) func(http.Handler) http.Handler {
+	config = config.withDefaults()
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !slices.Contains(config.Methods, r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := strings.TrimSpace(r.Header.Get(config.Header))
+
+			switch {
+			case key == "" && config.Required:
+				respondIdempotencyError(w, r, kiteHttp.ErrorMissingParam{Params: []string{config.Header}})
+				return
+			case key == "":
+				next.ServeHTTP(w, r)
+				return
+			case len(key) > maxIdempotencyKeyLength:
+				respondIdempotencyError(w, r, kiteHttp.ErrorInvalidParam{Params: []string{config.Header}})
+				return
+			}
+
+			fingerprint, err := fingerprintRequest(r)
+			if err != nil {
+				respondIdempotencyError(w, r, err)
+				return
+			}
+
+			serveIdempotent(w, r, next, &config, r.Method+" "+r.URL.Path+" "+key, fingerprint)
+		})
+	}
+}
+
+func serveIdempotent(w http.ResponseWriter, r *http.Request, next http.Handler, config *IdempotencyConfig, key, fingerprint string) {
+	ctx := r.Context()
+
+	stored, err := config.Store.Get(ctx, key)
+	if err != nil {
+		respondIdempotencyError(w, r, storeUnavailable(err))
+		return
+	}
+
+	if stored != nil {
+		replayIdempotentResponse(w, r, stored, fingerprint)
+		return
+	}
+
+	locked, err := config.Store.Lock(ctx, key, config.LockTTL)
+	if err != nil {
+		respondIdempotencyError(w, r, storeUnavailable(err))
+		return
+	}
+
+	if !locked {
+		respondIdempotencyError(w, r, kiteHttp.ErrorIdempotencyConflict{})
+		return
+	}
+
+	defer func() { _ = config.Store.Unlock(ctx, key) }()
+
+	// Another request may have completed between the first lookup and acquiring the lock.
+	if stored, err = config.Store.Get(ctx, key); err == nil && stored != nil {
+		replayIdempotentResponse(w, r, stored, fingerprint)
+		return
+	}
+
+	rec := &idempotencyRecorder{ResponseWriter: w, status: http.StatusOK}
+
+	next.ServeHTTP(rec, r)
+
+	if rec.status >= http.StatusInternalServerError {
+		return
+	}
+
+	resp := &IdempotentResponse{
+		StatusCode:  rec.status,
+		Header:      storableHeaders(w.Header()),
+		Body:        rec.body.Bytes(),
+		Fingerprint: fingerprint,
+	}
+
+	_ = config.Store.Save(ctx, key, resp, config.TTL)
+}
+
+func replayIdempotentResponse(w http.ResponseWriter, r *http.Request, resp *IdempotentResponse, fingerprint string) {
+	if resp.Fingerprint != "" && resp.Fingerprint != fingerprint {
+		respondIdempotencyError(w, r, kiteHttp.ErrorIdempotencyKeyReused{})
+		return
+	}
+
+	for name, values := range resp.Header {
+		w.Header()[name] = values
+	}
+
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(resp.StatusCode)
+	_, _ = w.Write(resp.Body)
+}
+
+// fingerprintRequest hashes the request body so that reuse of a key with a different payload can be detected.
+// The body is restored so that handlers can still read it.
+func fingerprintRequest(r *http.Request) (string, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return "", nil
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return "", err
+	}
+
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	sum := sha256.Sum256(body)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// storableHeaders returns the response headers that are replayed to retries.
+// Headers that are specific to a single request are dropped.
+func storableHeaders(h http.Header) http.Header {
+	stored := h.Clone()
+
+	for _, name := range []string{"X-Correlation-Id", "Date", "Set-Cookie"} {
+		stored.Del(name)
+	}
+
+	return stored
+}
+
+func storeUnavailable(err error) error {
+	return kiteHttp.ErrorServiceUnavailable{Dependency: "idempotency store", ErrorMessage: err.Error()}
+}
+
+func respondIdempotencyError(w http.ResponseWriter, r *http.Request, err error) {
+	kiteHttp.NewResponder(w, r.Method).Respond(nil, err)
+}
+
+func (c IdempotencyConfig) withDefaults() IdempotencyConfig {
+	if c.Store == nil {
+		c.Store = NewMemoryIdempotencyStore()
+	}
+
+	if c.TTL <= 0 {
+		c.TTL = defaultIdempotencyTTL
+	}
+
+	if c.LockTTL <= 0 {
+		c.LockTTL = defaultIdempotencyLockTTL
+	}
+
+	if c.Header == "" {
+		c.Header = IdempotencyKeyHeader
+	}
+
+	if len(c.Methods) == 0 {
+		c.Methods = []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	}
+
+	return c
+}
+
+// idempotencyRecorder tees the response written by the handler so that it can be stored.
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (w *idempotencyRecorder) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+
+	w.status = status
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *idempotencyRecorder) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	w.body.Write(b)
+
+	return w.ResponseWriter.Write(b)
+}
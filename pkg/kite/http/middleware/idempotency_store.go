@@ -0,0 +1,261 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/sllt/kite/pkg/kite/datasource/sql/qb"
+)
+
+// CreateIdempotencyTableSQL creates the table used by the SQL idempotency store.
+// It can be applied from a migration before enabling the store.
+const CreateIdempotencyTableSQL = `CREATE TABLE IF NOT EXISTS kite_idempotency_keys (
+    idempotency_key VARCHAR(512) NOT NULL PRIMARY KEY,
+    response TEXT,
+    expires_at BIGINT NOT NULL
+);`
+
+const redisIdempotencyPrefix = "kite:idempotency:"
+
+// IdempotentResponse is the response stored for an idempotency key and replayed to retries.
+type IdempotentResponse struct {
+	StatusCode  int         `json:"status_code"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+	Fingerprint string      `json:"fingerprint,omitempty"`
+}
+
+// IdempotencyStore abstracts the storage of responses and locks used by the idempotency middleware.
+type IdempotencyStore interface {
+	// Get returns the stored response for the key, or nil if there is none.
+	Get(ctx context.Context, key string) (*IdempotentResponse, error)
+	// Lock marks the key as being processed. It returns false if the key is already locked.
+	Lock(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// Save stores the response for the key for the given duration.
+	Save(ctx context.Context, key string, resp *IdempotentResponse, ttl time.Duration) error
+	// Unlock releases the lock acquired by Lock.
+	Unlock(ctx context.Context, key string) error
+}
+
+// memoryIdempotencyStore implements IdempotencyStore in memory.
+type memoryIdempotencyStore struct {
+	mu        sync.Mutex
+	responses map[string]memoryIdempotencyEntry
+	locks     map[string]time.Time
+}
+
+type memoryIdempotencyEntry struct {
+	resp      *IdempotentResponse
+	expiresAt time.Time
+}
+
+// NewMemoryIdempotencyStore creates a new in-memory idempotency store.
+// Expired entries are evicted lazily whenever the store is written to.
+func NewMemoryIdempotencyStore() IdempotencyStore {
+	return &memoryIdempotencyStore{
+		responses: make(map[string]memoryIdempotencyEntry),
+		locks:     make(map[string]time.Time),
+	}
+}
+
+func (m *memoryIdempotencyStore) Get(_ context.Context, key string) (*IdempotentResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.responses[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, nil
+	}
+
+	return entry.resp, nil
+}
+
+func (m *memoryIdempotencyStore) Lock(_ context.Context, key string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+
+	if until, ok := m.locks[key]; ok && now.Before(until) {
+		return false, nil
+	}
+
+	m.locks[key] = now.Add(ttl)
+
+	return true, nil
+}
+
+func (m *memoryIdempotencyStore) Save(_ context.Context, key string, resp *IdempotentResponse, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+
+	for k, entry := range m.responses {
+		if now.After(entry.expiresAt) {
+			delete(m.responses, k)
+		}
+	}
+
+	m.responses[key] = memoryIdempotencyEntry{resp: resp, expiresAt: now.Add(ttl)}
+
+	return nil
+}
+
+func (m *memoryIdempotencyStore) Unlock(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.locks, key)
+
+	return nil
+}
+
+// RedisIdempotencyStore implements IdempotencyStore using Redis.
+// Locks are taken with SET NX and both locks and responses expire through Redis TTLs.
+type RedisIdempotencyStore struct {
+	client redis.Cmdable
+}
+
+// NewRedisIdempotencyStore creates an idempotency store backed by the given Redis client,
+// e.g. the application's Redis datasource.
+func NewRedisIdempotencyStore(client redis.Cmdable) *RedisIdempotencyStore {
+	return &RedisIdempotencyStore{client: client}
+}
+
+func (r *RedisIdempotencyStore) Get(ctx context.Context, key string) (*IdempotentResponse, error) {
+	data, err := r.client.Get(ctx, redisIdempotencyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var resp IdempotentResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+func (r *RedisIdempotencyStore) Lock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return r.client.SetNX(ctx, redisIdempotencyPrefix+"lock:"+key, 1, ttl).Result()
+}
+
+func (r *RedisIdempotencyStore) Save(ctx context.Context, key string, resp *IdempotentResponse, ttl time.Duration) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+
+	return r.client.Set(ctx, redisIdempotencyPrefix+key, data, ttl).Err()
+}
+
+func (r *RedisIdempotencyStore) Unlock(ctx context.Context, key string) error {
+	return r.client.Del(ctx, redisIdempotencyPrefix+"lock:"+key).Err()
+}
+
+// IdempotencySQL is the subset of the SQL datasource used by the SQL idempotency store.
+type IdempotencySQL interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	Dialect() string
+}
+
+// SQLIdempotencyStore implements IdempotencyStore on the kite_idempotency_keys table, see CreateIdempotencyTableSQL.
+//
+// A row without a response represents a lock, so acquiring the lock relies on the primary key
+// rejecting a second insert for the same key.
+type SQLIdempotencyStore struct {
+	db IdempotencySQL
+}
+
+// NewSQLIdempotencyStore creates an idempotency store backed by the given SQL datasource.
+func NewSQLIdempotencyStore(db IdempotencySQL) *SQLIdempotencyStore {
+	return &SQLIdempotencyStore{db: db}
+}
+
+func (s *SQLIdempotencyStore) Get(ctx context.Context, key string) (*IdempotentResponse, error) {
+	var data sql.NullString
+
+	err := s.db.QueryRowContext(ctx, s.rebind(
+		"SELECT response FROM kite_idempotency_keys WHERE idempotency_key = ? AND expires_at >= ?"),
+		key, time.Now().Unix()).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !data.Valid) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var resp IdempotentResponse
+	if err := json.Unmarshal([]byte(data.String), &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+func (s *SQLIdempotencyStore) Lock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+
+	// Expired rows (stale locks or responses) must not prevent the key from being used again.
+	_, err := s.db.ExecContext(ctx, s.rebind(
+		"DELETE FROM kite_idempotency_keys WHERE idempotency_key = ? AND expires_at < ?"), key, now.Unix())
+	if err != nil {
+		return false, err
+	}
+
+	_, err = s.db.ExecContext(ctx, s.rebind(
+		"INSERT INTO kite_idempotency_keys (idempotency_key, response, expires_at) VALUES (?, NULL, ?)"),
+		key, now.Add(ttl).Unix())
+	if err == nil {
+		return true, nil
+	}
+
+	// Distinguish a primary key violation from other failures by checking whether the row exists.
+	var exists int
+
+	if scanErr := s.db.QueryRowContext(ctx, s.rebind(
+		"SELECT 1 FROM kite_idempotency_keys WHERE idempotency_key = ?"), key).Scan(&exists); scanErr == nil {
+		return false, nil
+	}
+
+	return false, err
+}
+
+func (s *SQLIdempotencyStore) Save(ctx context.Context, key string, resp *IdempotentResponse, ttl time.Duration) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, s.rebind(
+		"UPDATE kite_idempotency_keys SET response = ?, expires_at = ? WHERE idempotency_key = ?"),
+		string(data), time.Now().Add(ttl).Unix(), key)
+
+	return err
+}
+
+func (s *SQLIdempotencyStore) Unlock(ctx context.Context, key string) error {
+	_, err := s.db.ExecContext(ctx, s.rebind(
+		"DELETE FROM kite_idempotency_keys WHERE idempotency_key = ? AND response IS NULL"), key)
+
+	return err
+}
+
+// rebind converts the placeholders of query for the dialect of the store.
+func (s *SQLIdempotencyStore) rebind(query string) string {
+	return qb.RebindWithDialect(s.db.Dialect(), query)
+}
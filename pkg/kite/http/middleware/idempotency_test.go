@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newIdempotencyTestHandler(calls *int32, status int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n := atomic.AddInt32(calls, 1)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"call":` + strconv.Itoa(int(n)) + `}`))
+	})
+}
+
+func TestIdempotency_ReplaysStoredResponse(t *testing.T) {
+	var calls int32
+
+	h := Idempotency(IdempotencyConfig{})(newIdempotencyTestHandler(&calls, http.StatusCreated))
+
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/payments", strings.NewReader(`{"amount":10}`))
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.JSONEq(t, `{"call":1}`, rec.Body.String())
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+		if i > 0 {
+			assert.Equal(t, "true", rec.Header().Get(IdempotentReplayedHeader))
+		}
+	}
+
+	assert.Equal(t, int32(1), calls)
+}
+
+func TestIdempotency_SkipsWithoutKeyOrSafeMethod(t *testing.T) {
+	var calls int32
+
+	h := Idempotency(IdempotencyConfig{})(newIdempotencyTestHandler(&calls, http.StatusOK))
+
+	for i := 0; i < 2; i++ {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/payments", http.NoBody))
+
+		req := httptest.NewRequest(http.MethodGet, "/payments", http.NoBody)
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	assert.Equal(t, int32(4), calls)
+}
+
+func TestIdempotency_RequiredKey(t *testing.T) {
+	var calls int32
+
+	h := Idempotency(IdempotencyConfig{Required: true})(newIdempotencyTestHandler(&calls, http.StatusOK))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/payments", http.NoBody))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), IdempotencyKeyHeader)
+	assert.Zero(t, calls)
+}
+
+func TestIdempotency_KeyReusedWithDifferentPayload(t *testing.T) {
+	var calls int32
+
+	h := Idempotency(IdempotencyConfig{})(newIdempotencyTestHandler(&calls, http.StatusCreated))
+
+	req := httptest.NewRequest(http.MethodPost, "/payments", strings.NewReader(`{"amount":10}`))
+	req.Header.Set(IdempotencyKeyHeader, "key-1")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	req = httptest.NewRequest(http.MethodPost, "/payments", strings.NewReader(`{"amount":20}`))
+	req.Header.Set(IdempotencyKeyHeader, "key-1")
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, int32(1), calls)
+}
+
+func TestIdempotency_ConcurrentRequestIsRejected(t *testing.T) {
+	store := NewMemoryIdempotencyStore()
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	h := Idempotency(IdempotencyConfig{Store: store})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		req := httptest.NewRequest(http.MethodPost, "/payments", http.NoBody)
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}()
+
+	<-started
+
+	req := httptest.NewRequest(http.MethodPost, "/payments", http.NoBody)
+	req.Header.Set(IdempotencyKeyHeader, "key-1")
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	close(release)
+	<-done
+}
+
+func TestIdempotency_ServerErrorsAreNotStored(t *testing.T) {
+	var calls int32
+
+	h := Idempotency(IdempotencyConfig{})(newIdempotencyTestHandler(&calls, http.StatusInternalServerError))
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/payments", http.NoBody)
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	assert.Equal(t, int32(2), calls)
+}
+
+func TestRedisIdempotencyStore(t *testing.T) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	store := NewRedisIdempotencyStore(client)
+	ctx := context.Background()
+
+	resp, err := store.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Nil(t, resp)
+
+	locked, err := store.Lock(ctx, "k", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, locked)
+
+	locked, err = store.Lock(ctx, "k", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, locked)
+
+	require.NoError(t, store.Save(ctx, "k", &IdempotentResponse{StatusCode: http.StatusCreated, Body: []byte("ok")}, time.Minute))
+	require.NoError(t, store.Unlock(ctx, "k"))
+
+	resp, err = store.Get(ctx, "k")
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, []byte("ok"), resp.Body)
+
+	locked, err = store.Lock(ctx, "k", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, locked)
+}
+
+func TestSQLIdempotencyStore_rebind(t *testing.T) {
+	pg := NewSQLIdempotencyStore(dialectOnlySQL("postgres"))
+	my := NewSQLIdempotencyStore(dialectOnlySQL("mysql"))
+
+	query := "UPDATE t SET a = ? WHERE b = ? AND c = ?"
+
+	assert.Equal(t, "UPDATE t SET a = $1 WHERE b = $2 AND c = $3", pg.rebind(query))
+	assert.Equal(t, query, my.rebind(query))
+}
+
+type dialectOnlySQL string
+
+func (dialectOnlySQL) ExecContext(context.Context, string, ...any) (sql.Result, error) {
+	return nil, nil
+}
+
+func (dialectOnlySQL) QueryRowContext(context.Context, string, ...any) *sql.Row {
+	return nil
+}
+
+func (d dialectOnlySQL) Dialect() string {
+	return string(d)
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/sllt/kite/pkg/kite/i18n"
+)
+
+const (
+	defaultLocaleQueryParam = "lang"
+	defaultLocaleCookieName = "lang"
+)
+
+// LocaleConfig holds configuration for the Locale middleware.
+type LocaleConfig struct {
+	// Bundle holds the messages translated with ctx.T.
+	Bundle *i18n.Bundle
+	// Supported are the locales resolved for the requests, the locales of Bundle by default.
+	Supported []string
+	// Default is the locale of the requests asking for no supported locale, the first supported locale by default.
+	Default string
+	// QueryParam and CookieName carry the locale chosen by the user, "lang" by default. They take precedence over
+	// the Accept-Language header.
+	QueryParam string
+	CookieName string
+}
+
+func (c LocaleConfig) withDefaults() LocaleConfig {
+	if len(c.Supported) == 0 && c.Bundle != nil {
+		c.Supported = c.Bundle.Locales()
+	}
+
+	if c.Default == "" && len(c.Supported) > 0 {
+		c.Default = c.Supported[0]
+	}
+
+	if c.QueryParam == "" {
+		c.QueryParam = defaultLocaleQueryParam
+	}
+
+	if c.CookieName == "" {
+		c.CookieName = defaultLocaleCookieName
+	}
+
+	return c
+}
+
+// Locale is a middleware that resolves the locale of each request from the query parameter, the cookie and then the
+// Accept-Language header. Handlers translate messages to it with ctx.T, and the validation errors of ctx.Bind are
+// reported in it.
+func Locale(config LocaleConfig) func(http.Handler) http.Handler {
+	config = config.withDefaults()
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			locale := config.resolve(r)
+
+			w.Header().Add("Vary", "Accept-Language")
+
+			if locale != "" {
+				w.Header().Set("Content-Language", locale)
+			}
+
+			next.ServeHTTP(w, r.WithContext(i18n.WithLocalizer(r.Context(), i18n.NewLocalizer(config.Bundle, locale))))
+		})
+	}
+}
+
+func (c LocaleConfig) resolve(r *http.Request) string {
+	candidates := []string{r.URL.Query().Get(c.QueryParam)}
+
+	if cookie, err := r.Cookie(c.CookieName); err == nil {
+		candidates = append(candidates, cookie.Value)
+	}
+
+	candidates = append(candidates, i18n.ParseAcceptLanguage(r.Header.Get("Accept-Language"))...)
+
+	if locale := i18n.Match(c.Supported, candidates...); locale != "" {
+		return locale
+	}
+
+	return c.Default
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sllt/kite/pkg/kite/i18n"
+)
+
+func TestLocale(t *testing.T) {
+	bundle, err := i18n.NewBundle(fstest.MapFS{
+		"locales/en.json": {Data: []byte(`{"greeting": "Hello"}`)},
+		"locales/fr.json": {Data: []byte(`{"greeting": "Bonjour"}`)},
+	}, "locales", "en")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc           string
+		target         string
+		cookie         string
+		acceptLanguage string
+		expected       string
+	}{
+		{"default locale", "/", "", "", "en"},
+		{"accept-language", "/", "", "de, fr-CA;q=0.8", "fr"},
+		{"cookie over accept-language", "/", "en", "fr", "en"},
+		{"query over cookie", "/?lang=fr", "en", "en", "fr"},
+		{"unsupported query", "/?lang=de", "", "fr", "fr"},
+	}
+
+	for i, tc := range testCases {
+		var greeting string
+
+		handler := Locale(LocaleConfig{Bundle: bundle})(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			greeting = i18n.FromContext(r.Context()).T("greeting")
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, tc.target, http.NoBody)
+		req.Header.Set("Accept-Language", tc.acceptLanguage)
+
+		if tc.cookie != "" {
+			req.AddCookie(&http.Cookie{Name: "lang", Value: tc.cookie})
+		}
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, tc.expected, rec.Header().Get("Content-Language"), "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, bundle.T(tc.expected, "greeting"), greeting, "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
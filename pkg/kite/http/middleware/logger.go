@@ -12,7 +12,8 @@ import (
 	"strings"
 	"time"
 
-	"go.opentelemetry.io/otel/trace"
+	kiteHttp "github.com/sllt/kite/pkg/kite/http"
+	"github.com/sllt/kite/pkg/kite/logging"
 )
 
 var errHijackNotSupported = errors.New("response writer does not support hijacking")
@@ -25,6 +26,8 @@ type StatusResponseWriter struct {
 	// `superfluous response.WriteHeader call`. This is particularly helpful in scenarios where the developer has already written header
 	// in any custom middlewares.
 	wroteHeader bool
+	// bytes is the size of the body written.
+	bytes int64
 }
 
 func (w *StatusResponseWriter) WriteHeader(status int) {
@@ -37,6 +40,18 @@ func (w *StatusResponseWriter) WriteHeader(status int) {
 	w.ResponseWriter.WriteHeader(status)
 }
 
+// Write writes the body, with the 200 OK status when no status was written, and counts its size.
+func (w *StatusResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+
+	return n, err
+}
+
 // Hijack implements the http.Hijacker interface. So that we are able to upgrade to a websocket
 // connection that requires the responseWriter implementation to implement this method.
 func (w *StatusResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
@@ -58,6 +73,12 @@ type RequestLog struct {
 	IP           string `json:"ip,omitempty"`
 	URI          string `json:"uri,omitempty"`
 	Response     int    `json:"response,omitempty"`
+	Route        string `json:"route,omitempty"`
+	Bytes        int64  `json:"bytes,omitempty"`
+	User         string `json:"user,omitempty"`
+	Tenant       string `json:"tenant,omitempty"`
+	// Fields are the custom fields added by AccessLogConfig.Fields, AccessLogFields or AddAccessLogField.
+	Fields map[string]any `json:"fields,omitempty"`
 }
 
 func (rl *RequestLog) PrettyPrint(writer io.Writer) {
@@ -65,6 +86,22 @@ func (rl *RequestLog) PrettyPrint(writer io.Writer) {
 		"%8d\u001B[38;5;8mµs\u001B[0m %s %s \n", rl.TraceID, colorForStatusCode(rl.Response), rl.Response, rl.ResponseTime, rl.Method, rl.URI)
 }
 
+func (rl *RequestLog) addField(key string, value any) {
+	if rl.Fields == nil {
+		rl.Fields = make(map[string]any)
+	}
+
+	rl.Fields[key] = value
+}
+
+// Redact implements logging.Redactable, masking the sensitive query parameters of the URI.
+func (rl *RequestLog) Redact(r *logging.Redactor) any {
+	c := *rl
+	c.URI = r.URL(rl.URI)
+
+	return &c
+}
+
 func colorForStatusCode(status int) int {
 	const (
 		blue   = 34
@@ -90,33 +127,13 @@ type logger interface {
 }
 
 // Logging is a middleware which logs response status and time in milliseconds along with other data.
+// It is the AccessLog middleware without sampling nor custom fields.
 func Logging(probes LogProbes, logger logger) func(inner http.Handler) http.Handler {
-	return func(inner http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			start := time.Now()
-			srw := &StatusResponseWriter{ResponseWriter: w}
-			traceID := trace.SpanFromContext(r.Context()).SpanContext().TraceID().String()
-			spanID := trace.SpanFromContext(r.Context()).SpanContext().SpanID().String()
-
-			srw.Header().Set("X-Correlation-ID", traceID)
-
-			defer func() { panicRecovery(recover(), srw, logger) }()
-
-			// Skip logging for default probe paths if log probes are disabled
-			if isLogProbeDisabled(probes, r.URL.Path) {
-				inner.ServeHTTP(w, r)
-				return
-			}
-
-			defer handleRequestLog(srw, r, start, traceID, spanID, logger)
-
-			inner.ServeHTTP(srw, r)
-		})
-	}
+	return AccessLog(AccessLogConfig{Probes: probes}, logger)
 }
 
-func handleRequestLog(srw *StatusResponseWriter, r *http.Request, start time.Time, traceID, spanID string, logger logger) {
-	l := &RequestLog{
+func newRequestLog(srw *StatusResponseWriter, r *http.Request, start time.Time, traceID, spanID string) *RequestLog {
+	return &RequestLog{
 		TraceID:      traceID,
 		SpanID:       spanID,
 		StartTime:    start.Format("2006-01-02T15:04:05.999999999-07:00"),
@@ -126,14 +143,19 @@ func handleRequestLog(srw *StatusResponseWriter, r *http.Request, start time.Tim
 		IP:           getIPAddress(r),
 		URI:          r.RequestURI,
 		Response:     srw.status,
+		Bytes:        srw.bytes,
+	}
+}
+
+func logRequest(l *RequestLog, logger logger) {
+	if logger == nil {
+		return
 	}
 
-	if logger != nil {
-		if srw.status >= http.StatusInternalServerError {
-			logger.Error(l)
-		} else {
-			logger.Log(l)
-		}
+	if l.Response >= http.StatusInternalServerError {
+		logger.Error(l)
+	} else {
+		logger.Log(l)
 	}
 }
 
@@ -156,6 +178,10 @@ func isLogProbeDisabled(probes LogProbes, urlPath string) bool {
 }
 
 func getIPAddress(r *http.Request) string {
+	if ip := kiteHttp.ClientIPFromContext(r.Context()); ip != "" {
+		return ip
+	}
+
 	ips := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
 
 	// According to GCLB Documentation (https://cloud.google.com/load-balancing/docs/https/), IPs are added in following sequence.
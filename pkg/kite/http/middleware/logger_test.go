@@ -303,6 +303,17 @@ func (*hijackableResponseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error)
 	return conn, rw, nil
 }
 
+func TestRequestLog_Redact(t *testing.T) {
+	l := &RequestLog{Method: http.MethodGet, URI: "/reset?email=alice&token=abc"}
+
+	redacted, ok := l.Redact(logging.NewRedactor()).(*RequestLog)
+
+	require.True(t, ok)
+	assert.Equal(t, "/reset?email=alice&token=[REDACTED]", redacted.URI)
+	assert.Equal(t, http.MethodGet, redacted.Method)
+	assert.Equal(t, "/reset?email=alice&token=abc", l.URI, "the log must not be modified")
+}
+
 // mockConn is a mock implementation of net.Conn for testing purposes.
 type mockConn struct{}
 
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// MethodOverrideHeader is the header with which legacy clients, which can only send GET and POST requests, tunnel
+// the method of a request through POST.
+const MethodOverrideHeader = "X-HTTP-Method-Override"
+
+// MethodOverride is a middleware that routes POST requests with the X-HTTP-Method-Override header as requests of the
+// overriding method. Only PUT, PATCH and DELETE can override POST, so that the header cannot turn a request into a
+// safe method which would bypass checks made on unsafe ones, e.g. CSRF protection.
+func MethodOverride(inner http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			switch method := strings.ToUpper(strings.TrimSpace(r.Header.Get(MethodOverrideHeader))); method {
+			case http.MethodPut, http.MethodPatch, http.MethodDelete:
+				r.Method = method
+				r.Header.Del(MethodOverrideHeader)
+			}
+		}
+
+		inner.ServeHTTP(w, r)
+	})
+}
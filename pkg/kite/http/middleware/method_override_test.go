@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMethodOverride(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		method   string
+		override string
+		expected string
+	}{
+		{"POST overridden with PUT", http.MethodPost, "PUT", http.MethodPut},
+		{"POST overridden with lowercase patch", http.MethodPost, "patch", http.MethodPatch},
+		{"POST overridden with DELETE", http.MethodPost, "DELETE", http.MethodDelete},
+		{"POST without header", http.MethodPost, "", http.MethodPost},
+		{"POST cannot be overridden with GET", http.MethodPost, "GET", http.MethodPost},
+		{"GET cannot be overridden", http.MethodGet, "DELETE", http.MethodGet},
+	}
+
+	for i, tc := range testCases {
+		var method string
+
+		handler := MethodOverride(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			method = r.Method
+		}))
+
+		req := httptest.NewRequest(tc.method, "/users/1", http.NoBody)
+		if tc.override != "" {
+			req.Header.Set(MethodOverrideHeader, tc.override)
+		}
+
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Equal(t, tc.expected, method, "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
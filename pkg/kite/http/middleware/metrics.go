@@ -18,7 +18,9 @@ type metrics interface {
 	SetGauge(name string, value float64, labels ...string)
 }
 
-// Metrics is a middleware that records request response time metrics using the provided metrics interface.
+// Metrics is a middleware that records request response time metrics using the provided metrics interface, and the
+// size of the response bodies written per route in the app_http_response_bytes histogram, e.g. to attribute the egress
+// costs.
 func Metrics(metrics metrics) func(inner http.Handler) http.Handler {
 	return func(inner http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -52,6 +54,8 @@ func Metrics(metrics metrics) func(inner http.Handler) http.Handler {
 
 			metrics.RecordHistogram(context.Background(), "app_http_response", duration.Seconds(),
 				"path", path, "method", r.Method, "status", fmt.Sprintf("%d", srw.status))
+			metrics.RecordHistogram(context.Background(), "app_http_response_bytes", float64(srw.bytes),
+				"path", path, "method", r.Method)
 		})
 	}
 }
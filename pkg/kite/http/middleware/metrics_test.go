@@ -52,11 +52,31 @@ func TestMetrics(t *testing.T) {
 		[]string{"path", "/test", "method", "GET", "status", "200"})
 }
 
+func TestMetrics_ResponseBytes(t *testing.T) {
+	mockMetrics := &mockMetrics{}
+
+	mockMetrics.On("RecordHistogram", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil)
+
+	router := chi.NewRouter()
+	router.Use(Metrics(mockMetrics))
+	router.Get("/orders/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"id":"42"}`))
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders/42", http.NoBody))
+
+	mockMetrics.AssertCalled(t, "RecordHistogram", mock.Anything, "app_http_response_bytes", 11.0,
+		[]string{"path", "/orders/{id}", "method", "GET"})
+}
+
 func TestMetrics_StaticFile(t *testing.T) {
 	mockMetrics := &mockMetrics{}
 
 	mockMetrics.On("RecordHistogram", mock.Anything, "app_http_response", mock.Anything,
 		[]string{"path", "/static/example.js", "method", "GET", "status", "200"}).Return(nil)
+	mockMetrics.On("RecordHistogram", mock.Anything, "app_http_response_bytes", mock.Anything,
+		[]string{"path", "/static/example.js", "method", "GET"}).Return(nil)
 
 	// Create a temporary static file for the test
 	tempDir := t.TempDir()
@@ -89,6 +109,8 @@ func TestMetrics_StaticFileWithQueryParam(t *testing.T) {
 
 	mockMetrics.On("RecordHistogram", mock.Anything, "app_http_response", mock.Anything,
 		[]string{"path", "/static/example.js", "method", "GET", "status", "200"}).Return(nil)
+	mockMetrics.On("RecordHistogram", mock.Anything, "app_http_response_bytes", mock.Anything,
+		[]string{"path", "/static/example.js", "method", "GET"}).Return(nil)
 
 	// Create a temporary static file for the test
 	tempDir := t.TempDir()
@@ -0,0 +1,242 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics of the priority classes, labelled by "class".
+const (
+	priorityInFlightMetric  = "app_http_priority_requests_in_flight"
+	priorityQueuedMetric    = "app_http_priority_requests_queued"
+	priorityShedMetric      = "app_http_priority_requests_shed_total"
+	priorityQueueWaitMetric = "app_http_priority_queue_wait"
+)
+
+type priorityClassContextKey struct{}
+
+// PriorityClass is a class of requests served under a concurrency budget of its own.
+type PriorityClass struct {
+	// Name identifies the class in the classification rules and labels its metrics, e.g. "interactive" or "bulk".
+	Name string
+	// MaxInFlight caps the requests of the class served at once, the class is not limited when 0.
+	MaxInFlight int
+	// MaxQueue caps the requests of the class waiting for a slot, the others are shed right away. The queue is only
+	// bounded by QueueTimeout when 0.
+	MaxQueue int
+	// QueueTimeout is how long a request waits for a slot before it is shed, requests are shed right away when 0.
+	QueueTimeout time.Duration
+}
+
+// PriorityConfig holds the configuration of PriorityClasses. The class of a request is the first one found by
+// Classify, Routes, APIKeyScopes and Header, in this order, or Default.
+type PriorityConfig struct {
+	Classes []PriorityClass
+	// Default is the class of the requests matching no rule, the first class when empty.
+	Default string
+	// Classify returns the class of a request, or "" to apply the other rules.
+	Classify func(r *http.Request) string
+	// Routes maps the routes to their class, keyed by method and route pattern, e.g. "POST /exports".
+	Routes map[string]string
+	// APIKeyScopes maps the scopes of the API keys verified by the APIKeys middleware to a class, e.g. the tier of
+	// the customer "tier:free" to "bulk". The APIKeys middleware must be registered first.
+	APIKeyScopes map[string]string
+	// Header carries the class chosen by the caller, e.g. "X-Priority". It is only read when set, as the clients
+	// could raise the priority of their own requests: it is meant for the headers set by a trusted gateway.
+	Header string
+	// RetryAfter is sent in the Retry-After header of the shed requests, 1 second by default.
+	RetryAfter time.Duration
+}
+
+// PriorityClassFromContext returns the class of the request given by the PriorityClasses middleware, or "".
+func PriorityClassFromContext(ctx context.Context) string {
+	class, _ := ctx.Value(priorityClassContextKey{}).(string)
+
+	return class
+}
+
+// PriorityClasses creates a middleware that classifies the requests, e.g. as interactive or bulk, and serves each
+// class under a concurrency budget of its own, so that during an overload the low priority requests are shed before
+// they starve the others. A request waits up to the QueueTimeout of its class for a free slot, and is answered with
+// 503 Service Unavailable and a Retry-After header if none frees up.
+//
+// The metrics are labelled by "class": the app_http_priority_requests_in_flight and app_http_priority_requests_queued
+// gauges, the app_http_priority_requests_shed_total counter, and the app_http_priority_queue_wait histogram of the
+// time the requests waited for a slot, in seconds. Health check endpoints are never classified.
+func PriorityClasses(config PriorityConfig, m metrics) func(http.Handler) http.Handler {
+	if config.RetryAfter <= 0 {
+		config.RetryAfter = defaultConcurrencyRetryAfter
+	}
+
+	classes := make(map[string]*priorityClass, len(config.Classes))
+
+	for _, class := range config.Classes {
+		classes[class.Name] = newPriorityClass(class)
+	}
+
+	if config.Default == "" && len(config.Classes) > 0 {
+		config.Default = config.Classes[0].Name
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isWellKnown(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			class, ok := classes[config.classify(r, classes)]
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !class.acquire(r, m) {
+				if m != nil {
+					m.IncrementCounter(r.Context(), priorityShedMetric, "class", class.Name)
+				}
+
+				respondShed(w, r, config.RetryAfter)
+
+				return
+			}
+
+			defer class.release(m)
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), priorityClassContextKey{}, class.Name)))
+		})
+	}
+}
+
+// classify returns the class of r given by the first rule returning a known class.
+func (c *PriorityConfig) classify(r *http.Request, classes map[string]*priorityClass) string {
+	known := func(class string) bool {
+		_, ok := classes[class]
+
+		return ok
+	}
+
+	if c.Classify != nil {
+		if class := c.Classify(r); known(class) {
+			return class
+		}
+	}
+
+	if len(c.Routes) > 0 {
+		if class := c.Routes[routeKey(r)]; known(class) {
+			return class
+		}
+	}
+
+	if key := APIKeyFromContext(r.Context()); key != nil && len(c.APIKeyScopes) > 0 {
+		for _, scope := range key.Scopes {
+			if class := c.APIKeyScopes[scope]; known(class) {
+				return class
+			}
+		}
+	}
+
+	if c.Header != "" {
+		if class := r.Header.Get(c.Header); known(class) {
+			return class
+		}
+	}
+
+	return c.Default
+}
+
+// priorityClass bounds the requests of a class served at once, and counts the ones waiting for a slot.
+type priorityClass struct {
+	PriorityClass
+
+	// slots is nil when the class is not limited.
+	slots  chan struct{}
+	queued atomic.Int64
+}
+
+func newPriorityClass(config PriorityClass) *priorityClass {
+	class := &priorityClass{PriorityClass: config}
+
+	if config.MaxInFlight > 0 {
+		class.slots = make(chan struct{}, config.MaxInFlight)
+	}
+
+	return class
+}
+
+// acquire takes a slot, waiting up to QueueTimeout for one to free up if the queue is not full. It gives up when the
+// client goes away.
+func (c *priorityClass) acquire(r *http.Request, m metrics) bool {
+	if c.slots == nil {
+		return true
+	}
+
+	select {
+	case c.slots <- struct{}{}:
+		c.recordInFlight(m)
+
+		return true
+	default:
+	}
+
+	if c.QueueTimeout <= 0 {
+		return false
+	}
+
+	if queued := c.queued.Add(1); c.MaxQueue > 0 && queued > int64(c.MaxQueue) {
+		c.queued.Add(-1)
+
+		return false
+	}
+
+	c.recordQueued(m)
+
+	start := time.Now()
+
+	defer func() {
+		c.queued.Add(-1)
+		c.recordQueued(m)
+
+		if m != nil {
+			m.RecordHistogram(r.Context(), priorityQueueWaitMetric, time.Since(start).Seconds(), "class", c.Name)
+		}
+	}()
+
+	timer := time.NewTimer(c.QueueTimeout)
+	defer timer.Stop()
+
+	select {
+	case c.slots <- struct{}{}:
+		c.recordInFlight(m)
+
+		return true
+	case <-timer.C:
+		return false
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+func (c *priorityClass) release(m metrics) {
+	if c.slots == nil {
+		return
+	}
+
+	<-c.slots
+
+	c.recordInFlight(m)
+}
+
+func (c *priorityClass) recordInFlight(m metrics) {
+	if m != nil {
+		m.SetGauge(priorityInFlightMetric, float64(len(c.slots)), "class", c.Name)
+	}
+}
+
+func (c *priorityClass) recordQueued(m metrics) {
+	if m != nil {
+		m.SetGauge(priorityQueuedMetric, float64(c.queued.Load()), "class", c.Name)
+	}
+}
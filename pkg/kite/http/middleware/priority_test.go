@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriorityClasses_Classify(t *testing.T) {
+	config := PriorityConfig{
+		Classes: []PriorityClass{{Name: "interactive"}, {Name: "bulk"}, {Name: "critical"}},
+		Classify: func(r *http.Request) string {
+			return r.URL.Query().Get("class")
+		},
+		Routes:       map[string]string{"GET /exports": "bulk"},
+		APIKeyScopes: map[string]string{"tier:free": "bulk", "tier:enterprise": "critical"},
+		Header:       "X-Priority",
+	}
+
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if scopes := r.Header.Values("X-Scope"); len(scopes) > 0 {
+				r = r.WithContext(context.WithValue(r.Context(), apiKeyContextKey{}, &APIKeyRecord{Scopes: scopes}))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}, PriorityClasses(config, nil))
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(PriorityClassFromContext(r.Context())))
+	}
+
+	r.Get("/exports", h)
+	r.Get("/orders", h)
+	r.Get("/.well-known/alive", h)
+
+	testCases := []struct {
+		desc    string
+		target  string
+		headers map[string][]string
+		class   string
+	}{
+		{desc: "default class", target: "/orders", class: "interactive"},
+		{desc: "route", target: "/exports", class: "bulk"},
+		{desc: "classify overrides the route", target: "/exports?class=critical", class: "critical"},
+		{desc: "unknown class of classify", target: "/exports?class=unknown", class: "bulk"},
+		{desc: "api key scope", target: "/orders", headers: map[string][]string{"X-Scope": {"orders:read", "tier:free"}},
+			class: "bulk"},
+		{desc: "route overrides the api key", target: "/exports",
+			headers: map[string][]string{"X-Scope": {"tier:enterprise"}}, class: "bulk"},
+		{desc: "header", target: "/orders", headers: map[string][]string{"X-Priority": {"critical"}}, class: "critical"},
+		{desc: "unknown class of header", target: "/orders", headers: map[string][]string{"X-Priority": {"urgent"}},
+			class: "interactive"},
+		{desc: "health checks are not classified", target: "/.well-known/alive", class: ""},
+	}
+
+	for i, tc := range testCases {
+		req := httptest.NewRequest(http.MethodGet, tc.target, http.NoBody)
+		for name, values := range tc.headers {
+			req.Header[name] = values
+		}
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		assert.Equal(t, tc.class, rr.Body.String(), "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestPriorityClasses_Budgets(t *testing.T) {
+	metrics := newRateLimiterMockMetrics()
+	started, release := make(chan struct{}, 2), make(chan struct{})
+
+	h := blockingRouter(PriorityClasses(PriorityConfig{
+		Classes:    []PriorityClass{{Name: "interactive", MaxInFlight: 1}, {Name: "bulk", MaxInFlight: 1}},
+		Routes:     map[string]string{"GET /orders": "bulk"},
+		RetryAfter: 5 * time.Second,
+	}, metrics), started, release)
+
+	done := make(chan int, 2)
+
+	go func() { done <- serve(h, "/orders").Code }()
+
+	<-started
+
+	rr := serve(h, "/orders")
+
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code, "the bulk budget must be exhausted")
+	assert.Equal(t, "5", rr.Header().Get("Retry-After"))
+	assert.Equal(t, 1, metrics.GetCounter(priorityShedMetric))
+
+	go func() { done <- serve(h, "/users/1").Code }()
+
+	<-started
+
+	close(release)
+
+	assert.Equal(t, http.StatusOK, <-done, "the interactive requests must not be starved by the bulk ones")
+	assert.Equal(t, http.StatusOK, <-done)
+}
+
+func TestPriorityClasses_Queue(t *testing.T) {
+	metrics := newRateLimiterMockMetrics()
+	started, release := make(chan struct{}, 2), make(chan struct{})
+
+	h := blockingRouter(PriorityClasses(PriorityConfig{
+		Classes: []PriorityClass{{Name: "bulk", MaxInFlight: 1, MaxQueue: 1, QueueTimeout: time.Second}},
+	}, metrics), started, release)
+
+	go func() { serve(h, "/orders") }()
+
+	<-started
+
+	queued := make(chan int)
+
+	go func() { queued <- serve(h, "/orders").Code }()
+
+	time.Sleep(50 * time.Millisecond)
+
+	assert.Equal(t, http.StatusServiceUnavailable, serve(h, "/orders").Code,
+		"the requests over the queue size must be shed right away")
+	assert.Equal(t, 1, metrics.GetCounter(priorityShedMetric))
+
+	close(release)
+
+	<-started
+	require.Equal(t, http.StatusOK, <-queued, "the queued request must be served once a slot frees up")
+}
@@ -57,9 +57,14 @@ func (c RateLimiterConfig) Validate() error {
 }
 
 // getIP extracts the client IP address from the request.
-// If trustProxies is false, only RemoteAddr is used to prevent IP spoofing.
+// If trustProxies is false, only RemoteAddr, or the client IP resolved with TRUSTED_PROXIES, is used to prevent
+// IP spoofing.
 func getIP(r *http.Request, trustProxies bool) string {
 	if !trustProxies {
+		if ip := kiteHttp.ClientIPFromContext(r.Context()); ip != "" {
+			return ip
+		}
+
 		return getRemoteAddr(r)
 	}
 
@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultHSTSMaxAge = 365 * 24 * time.Hour
+
+// SecurityHeadersConfig holds the values of the headers set by the SecurityHeaders middleware.
+// A header with an empty value is not set.
+type SecurityHeadersConfig struct {
+	// HSTSMaxAge is the max-age of the Strict-Transport-Security header. HSTS is not set if it is zero.
+	HSTSMaxAge            time.Duration
+	HSTSIncludeSubdomains bool
+	HSTSPreload           bool
+	// ContentTypeOptions is the value of X-Content-Type-Options, "nosniff" by default.
+	ContentTypeOptions string
+	// FrameOptions is the value of X-Frame-Options, "DENY" by default.
+	FrameOptions string
+	// ReferrerPolicy is the value of Referrer-Policy, "strict-origin-when-cross-origin" by default.
+	ReferrerPolicy string
+	// ContentSecurityPolicy is the value of Content-Security-Policy, see NewCSP. It is not set by default, as
+	// a policy depends on the assets the application serves.
+	ContentSecurityPolicy string
+}
+
+// DefaultSecurityHeadersConfig returns the configuration used when security headers are enabled from configs.
+func DefaultSecurityHeadersConfig() SecurityHeadersConfig {
+	return SecurityHeadersConfig{
+		HSTSMaxAge:            defaultHSTSMaxAge,
+		HSTSIncludeSubdomains: true,
+		ContentTypeOptions:    "nosniff",
+		FrameOptions:          "DENY",
+		ReferrerPolicy:        "strict-origin-when-cross-origin",
+	}
+}
+
+// SecurityHeaders is a middleware that sets the HSTS, X-Content-Type-Options, X-Frame-Options, Referrer-Policy and
+// Content-Security-Policy response headers. Handlers can still override any of them.
+func SecurityHeaders(config SecurityHeadersConfig) func(inner http.Handler) http.Handler {
+	headers := config.headers()
+
+	return func(inner http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for name, value := range headers {
+				w.Header().Set(name, value)
+			}
+
+			inner.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (c SecurityHeadersConfig) headers() map[string]string {
+	headers := make(map[string]string)
+
+	if c.HSTSMaxAge > 0 {
+		hsts := "max-age=" + strconv.FormatInt(int64(c.HSTSMaxAge.Seconds()), 10)
+
+		if c.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+
+		if c.HSTSPreload {
+			hsts += "; preload"
+		}
+
+		headers["Strict-Transport-Security"] = hsts
+	}
+
+	for name, value := range map[string]string{
+		"X-Content-Type-Options":  c.ContentTypeOptions,
+		"X-Frame-Options":         c.FrameOptions,
+		"Referrer-Policy":         c.ReferrerPolicy,
+		"Content-Security-Policy": c.ContentSecurityPolicy,
+	} {
+		if value != "" {
+			headers[name] = value
+		}
+	}
+
+	return headers
+}
+
+// CSP builds a Content-Security-Policy header value. Directives are written in the order they are added:
+//
+//	policy := middleware.NewCSP().
+//		DefaultSrc(middleware.CSPSelf).
+//		ScriptSrc(middleware.CSPSelf, "https://cdn.example.com").
+//		FrameAncestors(middleware.CSPNone).
+//		String()
+type CSP struct {
+	directives []string
+	sources    map[string][]string
+}
+
+// Source keywords of a Content-Security-Policy, which must be quoted.
+const (
+	CSPSelf          = "'self'"
+	CSPNone          = "'none'"
+	CSPUnsafeInline  = "'unsafe-inline'"
+	CSPUnsafeEval    = "'unsafe-eval'"
+	CSPStrictDynamic = "'strict-dynamic'"
+)
+
+// NewCSP returns an empty Content-Security-Policy builder.
+func NewCSP() *CSP {
+	return &CSP{sources: make(map[string][]string)}
+}
+
+// Directive adds sources to the directive name. Directives without sources, like upgrade-insecure-requests,
+// are added by passing no source.
+func (p *CSP) Directive(name string, sources ...string) *CSP {
+	if _, ok := p.sources[name]; !ok {
+		p.directives = append(p.directives, name)
+	}
+
+	p.sources[name] = append(p.sources[name], sources...)
+
+	return p
+}
+
+// DefaultSrc adds sources to the default-src directive.
+func (p *CSP) DefaultSrc(sources ...string) *CSP {
+	return p.Directive("default-src", sources...)
+}
+
+// ScriptSrc adds sources to the script-src directive.
+func (p *CSP) ScriptSrc(sources ...string) *CSP {
+	return p.Directive("script-src", sources...)
+}
+
+// StyleSrc adds sources to the style-src directive.
+func (p *CSP) StyleSrc(sources ...string) *CSP {
+	return p.Directive("style-src", sources...)
+}
+
+// ImgSrc adds sources to the img-src directive.
+func (p *CSP) ImgSrc(sources ...string) *CSP {
+	return p.Directive("img-src", sources...)
+}
+
+// ConnectSrc adds sources to the connect-src directive.
+func (p *CSP) ConnectSrc(sources ...string) *CSP {
+	return p.Directive("connect-src", sources...)
+}
+
+// FontSrc adds sources to the font-src directive.
+func (p *CSP) FontSrc(sources ...string) *CSP {
+	return p.Directive("font-src", sources...)
+}
+
+// FrameAncestors adds sources to the frame-ancestors directive, the successor of X-Frame-Options.
+func (p *CSP) FrameAncestors(sources ...string) *CSP {
+	return p.Directive("frame-ancestors", sources...)
+}
+
+// UpgradeInsecureRequests adds the upgrade-insecure-requests directive.
+func (p *CSP) UpgradeInsecureRequests() *CSP {
+	return p.Directive("upgrade-insecure-requests")
+}
+
+// String returns the header value of the policy.
+func (p *CSP) String() string {
+	parts := make([]string, 0, len(p.directives))
+
+	for _, name := range p.directives {
+		parts = append(parts, strings.TrimSpace(name+" "+strings.Join(p.sources[name], " ")))
+	}
+
+	return strings.Join(parts, "; ")
+}
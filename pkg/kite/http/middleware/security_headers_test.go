@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	tests := []struct {
+		desc     string
+		config   SecurityHeadersConfig
+		expected map[string]string
+	}{
+		{
+			desc:   "defaults",
+			config: DefaultSecurityHeadersConfig(),
+			expected: map[string]string{
+				"Strict-Transport-Security": "max-age=31536000; includeSubDomains",
+				"X-Content-Type-Options":    "nosniff",
+				"X-Frame-Options":           "DENY",
+				"Referrer-Policy":           "strict-origin-when-cross-origin",
+				"Content-Security-Policy":   "",
+			},
+		},
+		{
+			desc: "custom values",
+			config: SecurityHeadersConfig{
+				HSTSMaxAge:            time.Hour,
+				HSTSPreload:           true,
+				FrameOptions:          "SAMEORIGIN",
+				ContentSecurityPolicy: "default-src 'self'",
+			},
+			expected: map[string]string{
+				"Strict-Transport-Security": "max-age=3600; preload",
+				"X-Content-Type-Options":    "",
+				"X-Frame-Options":           "SAMEORIGIN",
+				"Referrer-Policy":           "",
+				"Content-Security-Policy":   "default-src 'self'",
+			},
+		},
+		{
+			desc:   "HSTS disabled",
+			config: SecurityHeadersConfig{HSTSIncludeSubdomains: true},
+			expected: map[string]string{
+				"Strict-Transport-Security": "",
+			},
+		},
+	}
+
+	for i, tc := range tests {
+		handler := SecurityHeaders(tc.config)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+		for header, value := range tc.expected {
+			assert.Equal(t, value, rec.Header().Get(header), "TEST[%d], Failed.\n%s: %s", i, tc.desc, header)
+		}
+	}
+}
+
+func TestSecurityHeaders_HandlerOverrides(t *testing.T) {
+	handler := SecurityHeaders(DefaultSecurityHeadersConfig())(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-Frame-Options", "SAMEORIGIN")
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	assert.Equal(t, "SAMEORIGIN", rec.Header().Get("X-Frame-Options"))
+}
+
+func TestCSP(t *testing.T) {
+	policy := NewCSP().
+		DefaultSrc(CSPSelf).
+		ScriptSrc(CSPSelf, "https://cdn.example.com").
+		ImgSrc(CSPSelf, "data:").
+		ScriptSrc(CSPStrictDynamic).
+		FrameAncestors(CSPNone).
+		UpgradeInsecureRequests().
+		String()
+
+	assert.Equal(t, "default-src 'self'; script-src 'self' https://cdn.example.com 'strict-dynamic'; "+
+		"img-src 'self' data:; frame-ancestors 'none'; upgrade-insecure-requests", policy)
+	assert.Empty(t, NewCSP().String())
+}
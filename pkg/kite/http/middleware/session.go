@@ -0,0 +1,360 @@
+package middleware
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	kiteHttp "github.com/sllt/kite/pkg/kite/http"
+)
+
+const (
+	defaultSessionCookieName = "kite_sid"
+	defaultSessionTTL        = 24 * time.Hour
+	sessionIDLength          = 32
+)
+
+type sessionContextKey struct{}
+
+// SessionConfig holds configuration for the session middleware.
+type SessionConfig struct {
+	// Store persists the session values. Defaults to an in-memory store, which is only suitable for
+	// single-instance deployments; use NewRedisSessionStore or NewSQLSessionStore when running multiple replicas.
+	Store SessionStore
+	// TTL is how long a session is kept after it was last modified. Defaults to 24 hours.
+	TTL time.Duration
+	// CookieName is the name of the cookie carrying the session ID. Defaults to "kite_sid".
+	CookieName string
+	// CookiePath and CookieDomain scope the cookie. CookiePath defaults to "/".
+	CookiePath   string
+	CookieDomain string
+	// SameSite defaults to http.SameSiteLaxMode, which keeps the session on top-level navigations from other
+	// sites while protecting against cross-site form posts.
+	SameSite http.SameSite
+	// InsecureCookie drops the Secure attribute of the cookie, for local development over plain HTTP.
+	InsecureCookie bool
+}
+
+func (c SessionConfig) withDefaults() SessionConfig {
+	if c.Store == nil {
+		c.Store = NewMemorySessionStore()
+	}
+
+	if c.TTL <= 0 {
+		c.TTL = defaultSessionTTL
+	}
+
+	if c.CookieName == "" {
+		c.CookieName = defaultSessionCookieName
+	}
+
+	if c.CookiePath == "" {
+		c.CookiePath = "/"
+	}
+
+	if c.SameSite == 0 {
+		c.SameSite = http.SameSiteLaxMode
+	}
+
+	return c
+}
+
+// Session holds the values of a user session. It is safe for concurrent use.
+//
+// Values are stored as JSON, so they are read back with their JSON types: numbers are float64 and structs
+// are map[string]any. The methods of a nil Session, returned when the session middleware is not enabled,
+// do nothing.
+type Session struct {
+	mu        sync.Mutex
+	id        string
+	values    map[string]any
+	modified  bool
+	rotate    bool
+	destroyed bool
+}
+
+// SessionFromContext returns the session of the request, or nil if the session middleware is not enabled.
+func SessionFromContext(ctx context.Context) *Session {
+	s, _ := ctx.Value(sessionContextKey{}).(*Session)
+
+	return s
+}
+
+// ID returns the session ID, which is empty until the session is first saved.
+func (s *Session) ID() string {
+	if s == nil {
+		return ""
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.id
+}
+
+// Get returns the value stored for key, or nil.
+func (s *Session) Get(key string) any {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.values[key]
+}
+
+// Set stores value for key. The value must be serializable to JSON.
+func (s *Session) Set(key string, value any) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values[key] = value
+	s.modified = true
+}
+
+// Delete removes the value stored for key.
+func (s *Session) Delete(key string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.values[key]; ok {
+		delete(s.values, key)
+		s.modified = true
+	}
+}
+
+// Rotate moves the session to a new ID while keeping its values. It must be called when the privileges of the
+// user change, e.g. on login, so that a session ID planted before the change cannot be used after it.
+func (s *Session) Rotate() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rotate = true
+	s.modified = true
+}
+
+// Destroy removes the session from the store and clears its cookie, e.g. on logout.
+func (s *Session) Destroy() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values = make(map[string]any)
+	s.destroyed = true
+}
+
+// Sessions creates a middleware which loads the session of the request from the store and makes it available
+// through SessionFromContext, or ctx.Session() in kite handlers.
+//
+// Modified sessions are saved before the response headers are written. A session is only created, and its
+// cookie only set, once a value is stored in it. The cookie is HttpOnly, Secure and SameSite=Lax by default.
+//
+// Like the idempotency middleware, it fails closed: if the store cannot be reached the request is rejected with
+// 503 Service Unavailable.
+func Sessions(config SessionConfig) func(http.Handler) http.Handler {
+	config = config.withDefaults()
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sess, err := loadSession(r, config)
+			if err != nil {
+				respondSessionError(w, r, err)
+				return
+			}
+
+			r = r.WithContext(context.WithValue(r.Context(), sessionContextKey{}, sess))
+
+			sw := &sessionResponseWriter{ResponseWriter: w, request: r, session: sess, config: config}
+
+			next.ServeHTTP(sw, r)
+
+			sw.commit()
+		})
+	}
+}
+
+func loadSession(r *http.Request, config SessionConfig) (*Session, error) {
+	sess := &Session{values: make(map[string]any)}
+
+	cookie, err := r.Cookie(config.CookieName)
+	if err != nil || cookie.Value == "" {
+		return sess, nil
+	}
+
+	data, err := config.Store.Get(r.Context(), cookie.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	// an unknown or expired ID is ignored, a new ID is generated when the session is saved.
+	if data == nil {
+		return sess, nil
+	}
+
+	if err := json.Unmarshal(data, &sess.values); err != nil {
+		return sess, nil
+	}
+
+	sess.id = cookie.Value
+
+	return sess, nil
+}
+
+// sessionResponseWriter saves the session right before the response headers are written, as the cookie
+// cannot be set afterwards.
+type sessionResponseWriter struct {
+	http.ResponseWriter
+	request   *http.Request
+	session   *Session
+	config    SessionConfig
+	committed bool
+	failed    bool
+}
+
+func (w *sessionResponseWriter) WriteHeader(statusCode int) {
+	if !w.commit() {
+		return
+	}
+
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *sessionResponseWriter) Write(b []byte) (int, error) {
+	if !w.commit() {
+		return len(b), nil
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+// Hijack implements the http.Hijacker interface, so that websocket upgrades work behind the session middleware.
+// The session is not saved for hijacked connections.
+func (w *sessionResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := w.ResponseWriter.(http.Hijacker); ok {
+		w.committed = true
+
+		return hijacker.Hijack()
+	}
+
+	return nil, nil, fmt.Errorf("%w: cannot hijack connection", errHijackNotSupported)
+}
+
+// commit saves the session once. It returns false if the session could not be saved, in which case the
+// response of the handler is replaced with 503 Service Unavailable and discarded.
+func (w *sessionResponseWriter) commit() bool {
+	if w.committed {
+		return !w.failed
+	}
+
+	w.committed = true
+
+	if err := w.save(); err != nil {
+		w.failed = true
+		respondSessionError(w.ResponseWriter, w.request, err)
+
+		return false
+	}
+
+	return true
+}
+
+func (w *sessionResponseWriter) save() error {
+	s := w.session
+	ctx := w.request.Context()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.destroyed {
+		if s.id != "" {
+			if err := w.config.Store.Delete(ctx, s.id); err != nil {
+				return err
+			}
+
+			w.setCookie("", -1)
+		}
+
+		return nil
+	}
+
+	if !s.modified || (s.id == "" && len(s.values) == 0) {
+		return nil
+	}
+
+	data, err := json.Marshal(s.values)
+	if err != nil {
+		return err
+	}
+
+	oldID := s.id
+
+	if s.id == "" || s.rotate {
+		if s.id, err = newSessionID(); err != nil {
+			return err
+		}
+	}
+
+	if err = w.config.Store.Save(ctx, s.id, data, w.config.TTL); err != nil {
+		return err
+	}
+
+	if oldID != "" && oldID != s.id {
+		if err = w.config.Store.Delete(ctx, oldID); err != nil {
+			return err
+		}
+	}
+
+	w.setCookie(s.id, int(w.config.TTL.Seconds()))
+
+	return nil
+}
+
+func (w *sessionResponseWriter) setCookie(value string, maxAge int) {
+	http.SetCookie(w.ResponseWriter, &http.Cookie{
+		Name:     w.config.CookieName,
+		Value:    value,
+		Path:     w.config.CookiePath,
+		Domain:   w.config.CookieDomain,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   !w.config.InsecureCookie,
+		SameSite: w.config.SameSite,
+	})
+}
+
+func newSessionID() (string, error) {
+	b := make([]byte, sessionIDLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func respondSessionError(w http.ResponseWriter, r *http.Request, err error) {
+	kiteHttp.NewResponder(w, r.Method).Respond(nil,
+		kiteHttp.ErrorServiceUnavailable{Dependency: "session store", ErrorMessage: err.Error()})
+}
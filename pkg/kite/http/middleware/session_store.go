@@ -0,0 +1,189 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/sllt/kite/pkg/kite/datasource/sql/qb"
+)
+
+// CreateSessionTableSQL creates the table used by the SQL session store.
+// It can be applied from a migration before enabling the store.
+const CreateSessionTableSQL = `CREATE TABLE IF NOT EXISTS kite_sessions (
+    session_id VARCHAR(128) NOT NULL PRIMARY KEY,
+    data TEXT NOT NULL,
+    expires_at BIGINT NOT NULL
+);`
+
+const redisSessionPrefix = "kite:session:"
+
+// SessionStore abstracts the storage of the session middleware. Sessions are stored as opaque JSON documents.
+type SessionStore interface {
+	// Get returns the data of the session, or nil if the session does not exist or has expired.
+	Get(ctx context.Context, id string) ([]byte, error)
+	// Save stores the data of the session for the given duration.
+	Save(ctx context.Context, id string, data []byte, ttl time.Duration) error
+	// Delete removes the session.
+	Delete(ctx context.Context, id string) error
+}
+
+// memorySessionStore implements SessionStore in memory.
+type memorySessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]memorySessionEntry
+}
+
+type memorySessionEntry struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+// NewMemorySessionStore creates a new in-memory session store.
+// Expired sessions are evicted lazily whenever the store is written to.
+func NewMemorySessionStore() SessionStore {
+	return &memorySessionStore{sessions: make(map[string]memorySessionEntry)}
+}
+
+func (m *memorySessionStore) Get(_ context.Context, id string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.sessions[id]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, nil
+	}
+
+	return entry.data, nil
+}
+
+func (m *memorySessionStore) Save(_ context.Context, id string, data []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+
+	for k, entry := range m.sessions {
+		if now.After(entry.expiresAt) {
+			delete(m.sessions, k)
+		}
+	}
+
+	m.sessions[id] = memorySessionEntry{data: data, expiresAt: now.Add(ttl)}
+
+	return nil
+}
+
+func (m *memorySessionStore) Delete(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.sessions, id)
+
+	return nil
+}
+
+// RedisSessionStore implements SessionStore using Redis. Sessions expire through Redis TTLs.
+type RedisSessionStore struct {
+	client redis.Cmdable
+}
+
+// NewRedisSessionStore creates a session store backed by the given Redis client,
+// e.g. the application's Redis datasource.
+func NewRedisSessionStore(client redis.Cmdable) *RedisSessionStore {
+	return &RedisSessionStore{client: client}
+}
+
+func (r *RedisSessionStore) Get(ctx context.Context, id string) ([]byte, error) {
+	data, err := r.client.Get(ctx, redisSessionPrefix+id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+
+	return data, err
+}
+
+func (r *RedisSessionStore) Save(ctx context.Context, id string, data []byte, ttl time.Duration) error {
+	return r.client.Set(ctx, redisSessionPrefix+id, data, ttl).Err()
+}
+
+func (r *RedisSessionStore) Delete(ctx context.Context, id string) error {
+	return r.client.Del(ctx, redisSessionPrefix+id).Err()
+}
+
+// SessionSQL is the subset of the SQL datasource used by the SQL session store.
+type SessionSQL interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	Dialect() string
+}
+
+// SQLSessionStore implements SessionStore on the kite_sessions table, see CreateSessionTableSQL.
+// Expired rows are deleted lazily whenever a session is saved.
+type SQLSessionStore struct {
+	db SessionSQL
+}
+
+// NewSQLSessionStore creates a session store backed by the given SQL datasource.
+func NewSQLSessionStore(db SessionSQL) *SQLSessionStore {
+	return &SQLSessionStore{db: db}
+}
+
+func (s *SQLSessionStore) Get(ctx context.Context, id string) ([]byte, error) {
+	var data string
+
+	err := s.db.QueryRowContext(ctx, s.rebind(
+		"SELECT data FROM kite_sessions WHERE session_id = ? AND expires_at >= ?"),
+		id, time.Now().Unix()).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(data), nil
+}
+
+func (s *SQLSessionStore) Save(ctx context.Context, id string, data []byte, ttl time.Duration) error {
+	now := time.Now()
+
+	_, err := s.db.ExecContext(ctx, s.rebind("DELETE FROM kite_sessions WHERE expires_at < ?"), now.Unix())
+	if err != nil {
+		return err
+	}
+
+	// UPDATE then INSERT works on every dialect, unlike the upsert syntaxes.
+	res, err := s.db.ExecContext(ctx, s.rebind(
+		"UPDATE kite_sessions SET data = ?, expires_at = ? WHERE session_id = ?"),
+		string(data), now.Add(ttl).Unix(), id)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n > 0 {
+		return nil
+	}
+
+	_, err = s.db.ExecContext(ctx, s.rebind(
+		"INSERT INTO kite_sessions (session_id, data, expires_at) VALUES (?, ?, ?)"),
+		id, string(data), now.Add(ttl).Unix())
+
+	return err
+}
+
+func (s *SQLSessionStore) Delete(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, s.rebind("DELETE FROM kite_sessions WHERE session_id = ?"), id)
+
+	return err
+}
+
+// rebind converts the placeholders of query for the dialect of the store.
+func (s *SQLSessionStore) rebind(query string) string {
+	return qb.RebindWithDialect(s.db.Dialect(), query)
+}
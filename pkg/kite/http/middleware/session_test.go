@@ -0,0 +1,241 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errSessionStoreDown = errors.New("store down")
+
+// newSessionTestHandler serves /login, /logout, /count and /whoami with the session of the request.
+func newSessionTestHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sess := SessionFromContext(r.Context())
+
+		switch r.URL.Path {
+		case "/login":
+			sess.Set("user", "alice")
+			sess.Rotate()
+		case "/logout":
+			sess.Destroy()
+		case "/count":
+			n, _ := sess.Get("count").(float64)
+			sess.Set("count", n+1)
+		}
+
+		user, _ := sess.Get("user").(string)
+		_, _ = w.Write([]byte(user))
+	})
+}
+
+func sessionRequest(h http.Handler, path string, cookie *http.Cookie) (*httptest.ResponseRecorder, *http.Cookie) {
+	req := httptest.NewRequest(http.MethodGet, path, http.NoBody)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == defaultSessionCookieName {
+			return rec, c
+		}
+	}
+
+	return rec, nil
+}
+
+func TestSessions_Lifecycle(t *testing.T) {
+	store := NewMemorySessionStore()
+	h := Sessions(SessionConfig{Store: store})(newSessionTestHandler())
+
+	// reading an empty session neither creates it nor sets a cookie.
+	_, cookie := sessionRequest(h, "/whoami", nil)
+	assert.Nil(t, cookie)
+
+	_, anonymous := sessionRequest(h, "/count", nil)
+	require.NotNil(t, anonymous)
+	assert.True(t, anonymous.HttpOnly)
+	assert.True(t, anonymous.Secure)
+	assert.Equal(t, http.SameSiteLaxMode, anonymous.SameSite)
+	assert.Equal(t, "/", anonymous.Path)
+
+	// login rotates the session ID and keeps the values.
+	rec, loggedIn := sessionRequest(h, "/login", anonymous)
+	require.NotNil(t, loggedIn)
+	assert.Equal(t, "alice", rec.Body.String())
+	assert.NotEqual(t, anonymous.Value, loggedIn.Value)
+
+	data, err := store.Get(context.Background(), anonymous.Value)
+	require.NoError(t, err)
+	assert.Nil(t, data, "the session must not be reachable with its previous ID")
+
+	_, cookie = sessionRequest(h, "/count", loggedIn)
+	require.NotNil(t, cookie)
+	assert.Equal(t, loggedIn.Value, cookie.Value)
+
+	data, err = store.Get(context.Background(), loggedIn.Value)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"count":2,"user":"alice"}`, string(data))
+
+	// logout removes the session and clears the cookie.
+	_, cookie = sessionRequest(h, "/logout", loggedIn)
+	require.NotNil(t, cookie)
+	assert.Equal(t, -1, cookie.MaxAge)
+
+	rec, _ = sessionRequest(h, "/whoami", loggedIn)
+	assert.Empty(t, rec.Body.String())
+}
+
+func TestSessions_Config(t *testing.T) {
+	h := Sessions(SessionConfig{
+		CookieName:     "sid",
+		CookieDomain:   "example.com",
+		SameSite:       http.SameSiteStrictMode,
+		InsecureCookie: true,
+	})(newSessionTestHandler())
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/login", http.NoBody))
+
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "sid", cookies[0].Name)
+	assert.Equal(t, "example.com", cookies[0].Domain)
+	assert.Equal(t, http.SameSiteStrictMode, cookies[0].SameSite)
+	assert.False(t, cookies[0].Secure)
+	assert.Equal(t, int(defaultSessionTTL.Seconds()), cookies[0].MaxAge)
+}
+
+func TestSessions_StoreFailure(t *testing.T) {
+	h := Sessions(SessionConfig{Store: failingSessionStore{}})(newSessionTestHandler())
+
+	// loading fails when the request carries a session ID.
+	rec, _ := sessionRequest(h, "/whoami", &http.Cookie{Name: defaultSessionCookieName, Value: "id"})
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	// saving fails before the response of the handler is written.
+	rec, cookie := sessionRequest(h, "/login", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "alice")
+	assert.Nil(t, cookie)
+}
+
+func TestSessionFromContext_Disabled(t *testing.T) {
+	sess := SessionFromContext(context.Background())
+
+	assert.Nil(t, sess)
+	assert.NotPanics(t, func() {
+		sess.Set("user", "alice")
+		sess.Rotate()
+		sess.Destroy()
+	})
+	assert.Nil(t, sess.Get("user"))
+	assert.Empty(t, sess.ID())
+}
+
+func TestRedisSessionStore(t *testing.T) {
+	s := miniredis.RunT(t)
+	store := NewRedisSessionStore(redis.NewClient(&redis.Options{Addr: s.Addr()}))
+	ctx := context.Background()
+
+	data, err := store.Get(ctx, "id")
+	require.NoError(t, err)
+	assert.Nil(t, data)
+
+	require.NoError(t, store.Save(ctx, "id", []byte(`{"user":"alice"}`), time.Minute))
+
+	data, err = store.Get(ctx, "id")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"user":"alice"}`, string(data))
+
+	s.FastForward(2 * time.Minute)
+
+	data, err = store.Get(ctx, "id")
+	require.NoError(t, err)
+	assert.Nil(t, data)
+
+	require.NoError(t, store.Save(ctx, "id", []byte(`{}`), time.Minute))
+	require.NoError(t, store.Delete(ctx, "id"))
+	assert.False(t, s.Exists(redisSessionPrefix+"id"))
+}
+
+func TestSQLSessionStore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	store := NewSQLSessionStore(sqlmockDialect{DB: db, dialect: "postgres"})
+	ctx := context.Background()
+
+	mock.ExpectQuery("SELECT data FROM kite_sessions WHERE session_id = \\$1 AND expires_at >= \\$2").
+		WithArgs("id", sqlmock.AnyArg()).WillReturnError(sql.ErrNoRows)
+
+	data, err := store.Get(ctx, "id")
+	require.NoError(t, err)
+	assert.Nil(t, data)
+
+	// a new session is inserted after the update finds no row.
+	mock.ExpectExec("DELETE FROM kite_sessions WHERE expires_at < \\$1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE kite_sessions SET data = \\$1, expires_at = \\$2 WHERE session_id = \\$3").
+		WithArgs(`{"user":"alice"}`, sqlmock.AnyArg(), "id").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO kite_sessions \\(session_id, data, expires_at\\) VALUES \\(\\$1, \\$2, \\$3\\)").
+		WithArgs("id", `{"user":"alice"}`, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	require.NoError(t, store.Save(ctx, "id", []byte(`{"user":"alice"}`), time.Minute))
+
+	// an existing session is updated in place.
+	mock.ExpectExec("DELETE FROM kite_sessions WHERE expires_at < \\$1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE kite_sessions").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, store.Save(ctx, "id", []byte(`{}`), time.Minute))
+
+	mock.ExpectQuery("SELECT data FROM kite_sessions").
+		WillReturnRows(sqlmock.NewRows([]string{"data"}).AddRow(`{}`))
+
+	data, err = store.Get(ctx, "id")
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`{}`), data)
+
+	mock.ExpectExec("DELETE FROM kite_sessions WHERE session_id = \\$1").WithArgs("id").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, store.Delete(ctx, "id"))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+type failingSessionStore struct{}
+
+func (failingSessionStore) Get(context.Context, string) ([]byte, error) {
+	return nil, errSessionStoreDown
+}
+
+func (failingSessionStore) Save(context.Context, string, []byte, time.Duration) error {
+	return errSessionStoreDown
+}
+
+func (failingSessionStore) Delete(context.Context, string) error {
+	return errSessionStoreDown
+}
+
+type sqlmockDialect struct {
+	*sql.DB
+	dialect string
+}
+
+func (s sqlmockDialect) Dialect() string {
+	return s.dialect
+}
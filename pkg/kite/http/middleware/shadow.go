@@ -0,0 +1,267 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// ShadowRequestHeader is set on the mirrored requests, so that the shadow can tell them apart, e.g. to skip the
+	// side effects it cannot undo.
+	ShadowRequestHeader = "X-Shadow-Request"
+
+	defaultShadowTimeout     = 5 * time.Second
+	defaultShadowMaxBodySize = 1 << 20 // 1 MiB
+	defaultShadowMaxInFlight = 100
+)
+
+// ShadowConfig holds the configuration of the ShadowTraffic middleware.
+type ShadowConfig struct {
+	// Handler receives the mirrored requests, e.g. the new implementation of a handler. Either Handler or URL is set.
+	Handler http.Handler
+	// URL is the base URL of the remote service receiving the mirrored requests, e.g. "http://users-v2:8000". The
+	// path and the query of the requests are appended to it.
+	URL string
+	// Client sends the mirrored requests to URL. Defaults to http.DefaultClient.
+	Client *http.Client
+	// Rate is the share of the requests which are mirrored, between 0 and 1.
+	Rate float64
+	// Methods restricts the HTTP methods of the mirrored requests. Defaults to every method.
+	Methods []string
+	// MaxBodySize is the size above which the body of a request is not buffered, and the request not mirrored.
+	// Defaults to 1 MiB.
+	MaxBodySize int64
+	// Timeout bounds each mirrored request. Defaults to 5 seconds.
+	Timeout time.Duration
+	// MaxInFlight bounds the mirrored requests in flight, the requests sampled beyond it are not mirrored, so that a
+	// slow shadow does not pile up goroutines. Defaults to 100.
+	MaxInFlight int
+}
+
+func (c ShadowConfig) withDefaults() ShadowConfig {
+	if c.Client == nil {
+		c.Client = http.DefaultClient
+	}
+
+	if c.MaxBodySize <= 0 {
+		c.MaxBodySize = defaultShadowMaxBodySize
+	}
+
+	if c.Timeout <= 0 {
+		c.Timeout = defaultShadowTimeout
+	}
+
+	if c.MaxInFlight <= 0 {
+		c.MaxInFlight = defaultShadowMaxInFlight
+	}
+
+	return c
+}
+
+// ShadowTraffic creates a middleware which mirrors a sample of the requests to a shadow, a handler or a remote
+// service, to validate a new implementation against the production traffic before it serves it (dark launch).
+//
+// The mirrored requests are sent asynchronously, once the request has been served, with the ShadowRequestHeader
+// header set. The responses of the shadow are discarded, so they never reach the clients, and its failures are logged.
+// The requests whose body is larger than MaxBodySize are not mirrored.
+//
+// The mirrored requests are counted by the app_http_shadow_requests_total counter, labelled by the "status" of the
+// response of the shadow, or "error", and the sampled requests which are not mirrored by the
+// app_http_shadow_requests_dropped_total counter, labelled by "reason": "body_too_large" or "overloaded".
+func ShadowTraffic(config ShadowConfig, logger logger, m metrics) func(http.Handler) http.Handler {
+	config = config.withDefaults()
+	slots := make(chan struct{}, config.MaxInFlight)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !config.sampled(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, ok := bufferShadowBody(r, config.MaxBodySize)
+			if !ok {
+				dropShadowRequest(r.Context(), m, "body_too_large")
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			shadow := r.Clone(context.WithoutCancel(r.Context()))
+
+			next.ServeHTTP(w, r)
+
+			select {
+			case slots <- struct{}{}:
+			default:
+				dropShadowRequest(r.Context(), m, "overloaded")
+				return
+			}
+
+			go func() {
+				defer func() { <-slots }()
+
+				config.mirror(shadow, body, logger, m)
+			}()
+		})
+	}
+}
+
+// sampled reports whether the request r is mirrored.
+func (c *ShadowConfig) sampled(r *http.Request) bool {
+	if isWellKnown(r.URL.Path) || r.Header.Get(ShadowRequestHeader) != "" {
+		return false
+	}
+
+	if len(c.Methods) > 0 && !slices.Contains(c.Methods, r.Method) {
+		return false
+	}
+
+	return c.Rate >= 1 || (c.Rate > 0 && rand.Float64() < c.Rate) //nolint:gosec // sampling is not security sensitive.
+}
+
+// bufferShadowBody reads the body of r, which is restored for the handler, and reports whether it is at most
+// maxSize bytes long.
+func bufferShadowBody(r *http.Request, maxSize int64) ([]byte, bool) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, true
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxSize+1))
+
+	if err != nil || int64(len(body)) > maxSize {
+		// the handler reads the part which was buffered, then the rest of the body.
+		r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), r.Body), Closer: r.Body}
+
+		return nil, false
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, true
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// mirror sends the request to the shadow and discards its response.
+func (c *ShadowConfig) mirror(r *http.Request, body []byte, logger logger, m metrics) {
+	ctx, cancel := context.WithTimeout(r.Context(), c.Timeout)
+	defer cancel()
+
+	method, uri := r.Method, r.URL.RequestURI()
+
+	r = r.WithContext(ctx)
+	r.Header.Set(ShadowRequestHeader, "true")
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+
+	status, err := c.send(r)
+	if err != nil {
+		if logger != nil {
+			logger.Error(fmt.Sprintf("shadow request %s %s failed: %v", method, uri, err))
+		}
+
+		if m != nil {
+			m.IncrementCounter(ctx, "app_http_shadow_requests_total", "status", "error")
+		}
+
+		return
+	}
+
+	if m != nil {
+		m.IncrementCounter(ctx, "app_http_shadow_requests_total", "status", strconv.Itoa(status))
+	}
+}
+
+// send sends the request to the handler or to the URL of the shadow, and returns the status of its response.
+func (c *ShadowConfig) send(r *http.Request) (status int, err error) {
+	if c.Handler != nil {
+		defer func() {
+			if p := recover(); p != nil {
+				err = fmt.Errorf("shadow handler panicked: %v", p)
+			}
+		}()
+
+		w := &shadowResponseWriter{header: make(http.Header)}
+		c.Handler.ServeHTTP(w, r)
+
+		return w.statusCode(), nil
+	}
+
+	target, err := url.Parse(c.URL)
+	if err != nil {
+		return 0, err
+	}
+
+	target = target.JoinPath(r.URL.Path)
+	target.RawQuery = r.URL.RawQuery
+
+	if !strings.HasPrefix(target.Path, "/") {
+		target.Path = "/" + target.Path
+	}
+
+	r.URL = target
+	r.Host = target.Host
+	r.RequestURI = ""
+
+	resp, err := c.Client.Do(r)
+	if err != nil {
+		return 0, err
+	}
+
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return resp.StatusCode, nil
+}
+
+// shadowResponseWriter discards the response of the shadow handler, keeping its status only.
+type shadowResponseWriter struct {
+	header http.Header
+	status int
+}
+
+func (w *shadowResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *shadowResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	return len(b), nil
+}
+
+func (w *shadowResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *shadowResponseWriter) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+
+	return w.status
+}
+
+func dropShadowRequest(ctx context.Context, m metrics, reason string) {
+	if m != nil {
+		m.IncrementCounter(ctx, "app_http_shadow_requests_dropped_total", "reason", reason)
+	}
+}
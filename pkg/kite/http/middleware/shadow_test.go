@@ -0,0 +1,180 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// shadowErrors records the errors logged by the ShadowTraffic middleware.
+type shadowErrors chan string
+
+func (shadowErrors) Log(...any) {}
+
+func (s shadowErrors) Error(args ...any) {
+	s <- fmt.Sprint(args...)
+}
+
+// mirroredRequest is a request received by the shadow.
+type mirroredRequest struct {
+	method, uri, body, header string
+}
+
+func recordShadow(received chan<- mirroredRequest) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- mirroredRequest{method: r.Method, uri: r.URL.RequestURI(), body: string(body),
+			header: r.Header.Get(ShadowRequestHeader)}
+
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// echoBody responds with the body of the request.
+var echoBody = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	_, _ = w.Write(body)
+})
+
+func waitMirrored(t *testing.T, received <-chan mirroredRequest) mirroredRequest {
+	t.Helper()
+
+	select {
+	case r := <-received:
+		return r
+	case <-time.After(time.Second):
+		require.FailNow(t, "the request was not mirrored")
+
+		return mirroredRequest{}
+	}
+}
+
+func TestShadowTraffic_Handler(t *testing.T) {
+	received := make(chan mirroredRequest, 1)
+	metrics := newRateLimiterMockMetrics()
+
+	h := ShadowTraffic(ShadowConfig{Handler: recordShadow(received), Rate: 1}, nil, metrics)(echoBody)
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/users?dry=1", strings.NewReader(`{"name":"kite"}`)))
+
+	assert.Equal(t, `{"name":"kite"}`, rr.Body.String(), "the handler reads the whole body")
+	assert.Equal(t, mirroredRequest{method: http.MethodPost, uri: "/users?dry=1", body: `{"name":"kite"}`, header: "true"},
+		waitMirrored(t, received))
+
+	assert.Eventually(t, func() bool { return metrics.GetCounter("app_http_shadow_requests_total") == 1 },
+		time.Second, 10*time.Millisecond)
+}
+
+func TestShadowTraffic_URL(t *testing.T) {
+	received := make(chan mirroredRequest, 1)
+
+	shadow := httptest.NewServer(recordShadow(received))
+	defer shadow.Close()
+
+	h := ShadowTraffic(ShadowConfig{URL: shadow.URL + "/v2", Rate: 1}, nil, nil)(echoBody)
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "/users/1?force=true", strings.NewReader("body")))
+
+	assert.Equal(t, "body", rr.Body.String())
+	assert.Equal(t, mirroredRequest{method: http.MethodPut, uri: "/v2/users/1?force=true", body: "body", header: "true"},
+		waitMirrored(t, received))
+}
+
+func TestShadowTraffic_NotMirrored(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		config  ShadowConfig
+		request *http.Request
+		dropped int
+	}{
+		{desc: "not sampled", config: ShadowConfig{Rate: 0},
+			request: httptest.NewRequest(http.MethodGet, "/users", http.NoBody)},
+		{desc: "other method", config: ShadowConfig{Rate: 1, Methods: []string{http.MethodGet}},
+			request: httptest.NewRequest(http.MethodPost, "/users", strings.NewReader("body"))},
+		{desc: "health check", config: ShadowConfig{Rate: 1},
+			request: httptest.NewRequest(http.MethodGet, "/.well-known/alive", http.NoBody)},
+		{desc: "body too large", config: ShadowConfig{Rate: 1, MaxBodySize: 4},
+			request: httptest.NewRequest(http.MethodPost, "/users", strings.NewReader("too large")), dropped: 1},
+	}
+
+	for i, tc := range testCases {
+		received := make(chan mirroredRequest, 1)
+		metrics := newRateLimiterMockMetrics()
+
+		tc.config.Handler = recordShadow(received)
+
+		body, _ := io.ReadAll(tc.request.Body)
+		tc.request.Body = io.NopCloser(strings.NewReader(string(body)))
+
+		rr := httptest.NewRecorder()
+		ShadowTraffic(tc.config, nil, metrics)(echoBody).ServeHTTP(rr, tc.request)
+
+		assert.Equal(t, string(body), rr.Body.String(), "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, tc.dropped, metrics.GetCounter("app_http_shadow_requests_dropped_total"),
+			"TEST[%d], Failed.\n%s", i, tc.desc)
+
+		select {
+		case <-received:
+			t.Errorf("TEST[%d], Failed.\n%s: the request was mirrored", i, tc.desc)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+func TestShadowTraffic_Failures(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config ShadowConfig
+		err    string
+	}{
+		{desc: "panic", config: ShadowConfig{Handler: http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			panic("boom")
+		})}, err: "shadow handler panicked: boom"},
+		{desc: "unreachable", config: ShadowConfig{URL: "http://127.0.0.1:1"}, err: "shadow request GET /users"},
+	}
+
+	for i, tc := range testCases {
+		logged := make(shadowErrors, 1)
+		metrics := newRateLimiterMockMetrics()
+
+		tc.config.Rate = 1
+
+		rr := httptest.NewRecorder()
+		ShadowTraffic(tc.config, logged, metrics)(echoBody).
+			ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/users", http.NoBody))
+
+		assert.Equal(t, http.StatusOK, rr.Code, "TEST[%d], Failed.\n%s", i, tc.desc)
+
+		select {
+		case err := <-logged:
+			assert.Contains(t, err, tc.err, "TEST[%d], Failed.\n%s", i, tc.desc)
+		case <-time.After(time.Second):
+			t.Errorf("TEST[%d], Failed.\n%s: the failure was not logged", i, tc.desc)
+		}
+	}
+}
+
+func TestShadowTraffic_Overloaded(t *testing.T) {
+	release := make(chan struct{})
+	metrics := newRateLimiterMockMetrics()
+
+	slow := http.HandlerFunc(func(http.ResponseWriter, *http.Request) { <-release })
+	h := ShadowTraffic(ShadowConfig{Handler: slow, Rate: 1, MaxInFlight: 1}, nil, metrics)(echoBody)
+
+	for range 3 {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", http.NoBody))
+	}
+
+	close(release)
+
+	assert.Equal(t, 2, metrics.GetCounter("app_http_shadow_requests_dropped_total"))
+}
@@ -0,0 +1,15 @@
+package middleware
+
+import (
+	"net/http"
+
+	kiteHttp "github.com/sllt/kite/pkg/kite/http"
+)
+
+// StrictJSON is a middleware making Bind reject the JSON bodies with fields which are not in the struct they are bound
+// to, with 400 Bad Request and the path of the first unknown field.
+func StrictJSON(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(kiteHttp.WithStrictJSON(r.Context())))
+	})
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	kiteHttp "github.com/sllt/kite/pkg/kite/http"
+)
+
+func TestStrictJSON(t *testing.T) {
+	var err error
+
+	handler := StrictJSON(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		var order struct {
+			ID int `json:"id"`
+		}
+
+		err = kiteHttp.NewRequest(r).Bind(&order)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`{"id":1,"qty":2}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, kiteHttp.ErrorInvalidJSON{Field: "qty", Offset: 13, Reason: "unknown field"}, err)
+}
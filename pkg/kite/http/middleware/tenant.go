@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	kiteHttp "github.com/sllt/kite/pkg/kite/http"
+	"github.com/sllt/kite/pkg/kite/tenant"
+)
+
+// TenantRequestsMetric counts the requests served per tenant.
+const TenantRequestsMetric = "app_http_tenant_requests_total"
+
+// TenantConfig holds configuration for the Tenant middleware.
+type TenantConfig struct {
+	// Resolver resolves the tenant of the requests, see tenant.ResolverFromConfig.
+	Resolver tenant.Resolver
+	// Optional serves the requests without a tenant, e.g. of a public API, instead of rejecting them.
+	Optional bool
+	// Allowed reports whether a tenant is known, the requests of the other tenants are rejected with 403 Forbidden.
+	// Any valid tenant is served when nil, see tenant.AllowList.
+	Allowed func(id string) bool
+	// Metrics counts the requests of each tenant in the app_http_tenant_requests_total metric, when not nil.
+	Metrics tenantMetrics
+}
+
+type tenantMetrics interface {
+	IncrementCounter(ctx context.Context, name string, labels ...string)
+}
+
+// ErrorMissingTenant is returned for the requests without a tenant, or with an invalid one.
+type ErrorMissingTenant struct {
+	invalid string
+}
+
+func (e ErrorMissingTenant) Error() string {
+	if e.invalid != "" {
+		return "invalid tenant '" + e.invalid + "'"
+	}
+
+	return "missing tenant"
+}
+
+func (ErrorMissingTenant) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// ErrorUnknownTenant is returned for the requests of a tenant which is not allowed by TenantConfig.Allowed.
+type ErrorUnknownTenant struct {
+	id string
+}
+
+func (e ErrorUnknownTenant) Error() string {
+	return "unknown tenant '" + e.id + "'"
+}
+
+func (ErrorUnknownTenant) StatusCode() int {
+	return http.StatusForbidden
+}
+
+// Tenant is a middleware that resolves the tenant of each request with the resolver of config, and adds it to the
+// context of the request, where it is read with ctx.Tenant or tenant.FromContext. The requests without a valid tenant
+// are rejected with 400 Bad Request, unless the tenant is optional, and the requests of a tenant which is not allowed
+// with 403 Forbidden. The /.well-known endpoints, e.g. the health checks, are served without a tenant.
+func Tenant(config TenantConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isWellKnown(r.URL.Path) {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			id := config.Resolver(r)
+
+			switch {
+			case id != "" && !tenant.ValidID(id):
+				respondTenantError(w, r, ErrorMissingTenant{invalid: id})
+
+				return
+			case id == "" && !config.Optional:
+				respondTenantError(w, r, ErrorMissingTenant{})
+
+				return
+			case id == "":
+				next.ServeHTTP(w, r)
+
+				return
+			case config.Allowed != nil && !config.Allowed(id):
+				respondTenantError(w, r, ErrorUnknownTenant{id: id})
+
+				return
+			}
+
+			if config.Metrics != nil {
+				config.Metrics.IncrementCounter(r.Context(), TenantRequestsMetric, "tenant", id)
+			}
+
+			annotateAccessLog(r.Context(), func(a *accessLogAnnotations) { a.tenant = id })
+
+			next.ServeHTTP(w, r.WithContext(tenant.WithTenant(r.Context(), id)))
+		})
+	}
+}
+
+func respondTenantError(w http.ResponseWriter, r *http.Request, err error) {
+	kiteHttp.NewResponder(w, r.Method).Respond(nil, err)
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sllt/kite/pkg/kite/tenant"
+)
+
+type tenantCounter struct {
+	labels [][]string
+}
+
+func (c *tenantCounter) IncrementCounter(_ context.Context, name string, labels ...string) {
+	if name == TenantRequestsMetric {
+		c.labels = append(c.labels, labels)
+	}
+}
+
+func TestTenant(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		target   string
+		header   string
+		optional bool
+		status   int
+		tenant   string
+		body     string
+	}{
+		{"tenant resolved", "/orders", "acme", false, http.StatusOK, "acme", ""},
+		{"missing tenant", "/orders", "", false, http.StatusBadRequest, "", "missing tenant"},
+		{"invalid tenant", "/orders", "acme;drop", false, http.StatusBadRequest, "", "invalid tenant 'acme;drop'"},
+		{"optional tenant", "/orders", "", true, http.StatusOK, "", ""},
+		{"invalid optional tenant", "/orders", "a b", true, http.StatusBadRequest, "", "invalid tenant 'a b'"},
+		{"well-known path", "/.well-known/health", "", false, http.StatusOK, "", ""},
+		{"unknown tenant", "/orders", "gamma", false, http.StatusForbidden, "", "unknown tenant 'gamma'"},
+	}
+
+	for i, tc := range testCases {
+		var resolved string
+
+		metrics := &tenantCounter{}
+
+		handler := Tenant(TenantConfig{Resolver: tenant.Header("X-Tenant-ID"), Optional: tc.optional,
+			Allowed: tenant.AllowList("acme", "beta"), Metrics: metrics})(
+			http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				resolved = tenant.FromContext(r.Context())
+			}))
+
+		req := httptest.NewRequest(http.MethodGet, tc.target, http.NoBody)
+		req.Header.Set("X-Tenant-ID", tc.header)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, tc.status, rec.Code, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, tc.tenant, resolved, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Contains(t, rec.Body.String(), tc.body, "TEST[%d], Failed.\n%s", i, tc.desc)
+
+		if tc.tenant != "" {
+			assert.Equal(t, [][]string{{"tenant", tc.tenant}}, metrics.labels, "TEST[%d], Failed.\n%s", i, tc.desc)
+		} else {
+			assert.Empty(t, metrics.labels, "TEST[%d], Failed.\n%s", i, tc.desc)
+		}
+	}
+}
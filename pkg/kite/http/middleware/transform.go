@@ -0,0 +1,313 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"gopkg.in/yaml.v3"
+)
+
+var errUnsupportedTransformFile = errors.New("unsupported transform config file, use .json, .yaml or .yml")
+
+// Rewriter rewrites a JSON body, decoded with its numbers as json.Number, and returns the new body. It may change the
+// body in place or return another value, e.g. to unwrap the data of the responses for legacy clients.
+type Rewriter func(body any) any
+
+// FieldMapping declares the rewrites of the fields of a JSON body. The fields are addressed by their dotted path in the
+// body as received, e.g. "user.fullName", and the arrays on the path are traversed: "items.sku" is the sku of every
+// item. The fields are removed first, then the values of the enums are mapped and the fields renamed.
+type FieldMapping struct {
+	// Rename gives the fields a new name in the same object, e.g. {"user.fullName": "name"}.
+	Rename map[string]string `json:"rename,omitempty" yaml:"rename,omitempty"`
+	// Enums maps the values of the fields, e.g. {"status": {"A": "active", "I": "inactive"}}. The values are matched by
+	// their text, so that both 1 and "1" match the key "1".
+	Enums map[string]map[string]any `json:"enums,omitempty" yaml:"enums,omitempty"`
+	// Remove drops the fields.
+	Remove []string `json:"remove,omitempty" yaml:"remove,omitempty"`
+}
+
+func (m FieldMapping) empty() bool {
+	return len(m.Rename) == 0 && len(m.Enums) == 0 && len(m.Remove) == 0
+}
+
+// TransformRoute holds the rewrites of the requests and responses of a route.
+type TransformRoute struct {
+	// Method of the route, any method when empty.
+	Method string `json:"method,omitempty" yaml:"method,omitempty"`
+	// Path is the pattern of the route, as registered on the app, e.g. "/v1/users/{id}". Every path when empty.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+	// Request and Response rewrite the JSON bodies of the route. The paths of the response are relative to the whole
+	// body, e.g. "data.fullName".
+	Request  FieldMapping `json:"request" yaml:"request"`
+	Response FieldMapping `json:"response" yaml:"response"`
+	// RequestRewriters and ResponseRewriters run after the mappings, for the rewrites which cannot be declared.
+	RequestRewriters  []Rewriter `json:"-" yaml:"-"`
+	ResponseRewriters []Rewriter `json:"-" yaml:"-"`
+}
+
+// TransformConfig holds configuration for the Transform middleware.
+type TransformConfig struct {
+	// Routes are matched in order, the first route matching the request is applied.
+	Routes []TransformRoute `json:"routes" yaml:"routes"`
+}
+
+// LoadTransformConfig reads the routes of the Transform middleware from a JSON or YAML file. The rewriters, which
+// cannot be declared in the file, can be added to the routes afterward.
+func LoadTransformConfig(path string) (TransformConfig, error) {
+	var config TransformConfig
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, fmt.Errorf("failed to read transform config file %s: %w", path, err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &config)
+	case ".json":
+		err = json.Unmarshal(data, &config)
+	default:
+		return config, fmt.Errorf("%w: %s", errUnsupportedTransformFile, path)
+	}
+
+	if err != nil {
+		return config, fmt.Errorf("failed to parse transform config file %s: %w", path, err)
+	}
+
+	return config, nil
+}
+
+// transformRoute is a route of the config with the router matching its path.
+type transformRoute struct {
+	TransformRoute
+	router *chi.Mux
+}
+
+func (t transformRoute) matches(r *http.Request) bool {
+	if t.Method != "" && !strings.EqualFold(t.Method, r.Method) {
+		return false
+	}
+
+	return t.router == nil || t.router.Match(chi.NewRouteContext(), http.MethodGet, r.URL.Path)
+}
+
+// Transform is a middleware that rewrites the JSON bodies of the requests before they reach the handlers, and of the
+// responses before they are sent, so that legacy clients can be served by the handlers of a newer version of the API,
+// e.g. while they migrate. The other bodies are left untouched.
+//
+// The responses of the routes rewriting them are buffered, they cannot be streamed.
+func Transform(config TransformConfig) func(http.Handler) http.Handler {
+	routes := make([]transformRoute, 0, len(config.Routes))
+
+	for _, route := range config.Routes {
+		t := transformRoute{TransformRoute: route}
+
+		if route.Path != "" {
+			t.router = chi.NewRouter()
+			t.router.Get(route.Path, func(http.ResponseWriter, *http.Request) {})
+		}
+
+		routes = append(routes, t)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, route := range routes {
+				if route.matches(r) {
+					route.serve(next, w, r)
+
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (t transformRoute) serve(next http.Handler, w http.ResponseWriter, r *http.Request) {
+	if (!t.Request.empty() || len(t.RequestRewriters) > 0) && isJSONContent(r.Header.Get("Content-Type")) {
+		rewriteRequest(r, t.Request, t.RequestRewriters)
+	}
+
+	if t.Response.empty() && len(t.ResponseRewriters) == 0 {
+		next.ServeHTTP(w, r)
+
+		return
+	}
+
+	rec := &transformRecorder{ResponseWriter: w, status: http.StatusOK}
+
+	next.ServeHTTP(rec, r)
+
+	body := rec.body.Bytes()
+
+	if isJSONContent(w.Header().Get("Content-Type")) {
+		if rewritten, err := rewriteJSON(body, t.Response, t.ResponseRewriters); err == nil {
+			body = rewritten
+
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		}
+	}
+
+	w.WriteHeader(rec.status)
+	_, _ = w.Write(body)
+}
+
+// rewriteRequest replaces the body of the request with its rewritten body. An invalid body is left for the handler
+// to reject.
+func rewriteRequest(r *http.Request, mapping FieldMapping, rewriters []Rewriter) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	r.Body.Close()
+
+	if err == nil {
+		if rewritten, rewriteErr := rewriteJSON(body, mapping, rewriters); rewriteErr == nil {
+			body = rewritten
+		}
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	r.Header.Set("Content-Length", strconv.Itoa(len(body)))
+}
+
+// rewriteJSON applies the mapping and then the rewriters to a JSON document.
+func rewriteJSON(data []byte, mapping FieldMapping, rewriters []Rewriter) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var body any
+	if err := decoder.Decode(&body); err != nil {
+		return nil, err
+	}
+
+	for _, path := range mapping.Remove {
+		walkFields(body, splitFieldPath(path), func(obj map[string]any, key string) {
+			delete(obj, key)
+		})
+	}
+
+	for path, values := range mapping.Enums {
+		walkFields(body, splitFieldPath(path), func(obj map[string]any, key string) {
+			if value, ok := obj[key]; ok {
+				if mapped, found := values[fmt.Sprint(value)]; found {
+					obj[key] = mapped
+				}
+			}
+		})
+	}
+
+	for _, path := range renamePaths(mapping.Rename) {
+		name := mapping.Rename[path]
+
+		walkFields(body, splitFieldPath(path), func(obj map[string]any, key string) {
+			if value, ok := obj[key]; ok {
+				delete(obj, key)
+				obj[name] = value
+			}
+		})
+	}
+
+	for _, rewrite := range rewriters {
+		body = rewrite(body)
+	}
+
+	var buf bytes.Buffer
+
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+
+	if err := encoder.Encode(body); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// renamePaths returns the paths to rename, the deepest first so that renaming an object does not hide its fields.
+func renamePaths(rename map[string]string) []string {
+	paths := make([]string, 0, len(rename))
+	for path := range rename {
+		paths = append(paths, path)
+	}
+
+	sort.Slice(paths, func(i, j int) bool {
+		di, dj := strings.Count(paths[i], "."), strings.Count(paths[j], ".")
+		if di != dj {
+			return di > dj
+		}
+
+		return paths[i] < paths[j]
+	})
+
+	return paths
+}
+
+func splitFieldPath(path string) []string {
+	return strings.Split(strings.Trim(path, "."), ".")
+}
+
+// walkFields calls fn with each object holding the last field of path, traversing the arrays on the path.
+func walkFields(node any, path []string, fn func(obj map[string]any, key string)) {
+	switch v := node.(type) {
+	case []any:
+		for _, element := range v {
+			walkFields(element, path, fn)
+		}
+	case map[string]any:
+		if len(path) == 1 {
+			fn(v, path[0])
+
+			return
+		}
+
+		if child, ok := v[path[0]]; ok {
+			walkFields(child, path[1:], fn)
+		}
+	}
+}
+
+// isJSONContent reports whether the content type is JSON, e.g. application/json or application/problem+json.
+func isJSONContent(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// transformRecorder buffers the response so that it can be rewritten.
+type transformRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (w *transformRecorder) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+
+	w.status = status
+	w.wroteHeader = true
+}
+
+func (w *transformRecorder) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+
+	return w.body.Write(b)
+}
@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransform_Request(t *testing.T) {
+	var received string
+
+	handler := Transform(TransformConfig{Routes: []TransformRoute{{
+		Method: http.MethodPost,
+		Path:   "/v1/users/{id}",
+		Request: FieldMapping{
+			Rename: map[string]string{"fullName": "name", "addresses.zip": "postalCode"},
+			Enums:  map[string]map[string]any{"status": {"A": "active", "1": "admin"}, "role": {"1": "admin"}},
+			Remove: []string{"legacyFlag"},
+		},
+	}}})(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+
+		assert.Equal(t, int64(len(body)), r.ContentLength)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/users/42", strings.NewReader(
+		`{"fullName":"Ada","status":"A","role":1,"legacyFlag":true,"addresses":[{"zip":"75001"},{"zip":"10115"}]}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.JSONEq(t,
+		`{"name":"Ada","status":"active","role":"admin","addresses":[{"postalCode":"75001"},{"postalCode":"10115"}]}`,
+		received)
+}
+
+func TestTransform_RequestNotMatched(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		method      string
+		path        string
+		contentType string
+		body        string
+	}{
+		{"other method", http.MethodPut, "/v1/users/42", "application/json", `{"fullName":"Ada"}`},
+		{"other path", http.MethodPost, "/v2/users/42", "application/json", `{"fullName":"Ada"}`},
+		{"not json", http.MethodPost, "/v1/users/42", "text/plain", `{"fullName":"Ada"}`},
+		{"invalid json", http.MethodPost, "/v1/users/42", "application/json", `{"fullName":`},
+	}
+
+	for i, tc := range testCases {
+		var received string
+
+		handler := Transform(TransformConfig{Routes: []TransformRoute{{
+			Method:  http.MethodPost,
+			Path:    "/v1/users/{id}",
+			Request: FieldMapping{Rename: map[string]string{"fullName": "name"}},
+		}}})(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			received = string(body)
+		}))
+
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", tc.contentType)
+
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Equal(t, tc.body, received, "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestTransform_Response(t *testing.T) {
+	unwrap := func(body any) any {
+		if m, ok := body.(map[string]any); ok {
+			return m["data"]
+		}
+
+		return body
+	}
+
+	handler := Transform(TransformConfig{Routes: []TransformRoute{{
+		Path: "/v1/orders/{id}",
+		Response: FieldMapping{
+			Rename: map[string]string{"data.total": "amount"},
+			Enums:  map[string]map[string]any{"data.state": {"shipped": "S"}},
+		},
+		ResponseRewriters: []Rewriter{unwrap},
+	}}})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"code":0,"data":{"total":12.50,"state":"shipped"},"message":"ok"}`))
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/orders/7", http.NoBody))
+
+	// the rewriter runs after the mapping.
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"amount":12.50,"state":"S"}`, w.Body.String())
+
+	handler = Transform(TransformConfig{Routes: []TransformRoute{{
+		Path:     "/v1/orders/{id}",
+		Response: FieldMapping{Rename: map[string]string{"data.total": "amount"}},
+	}}})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_, _ = w.Write([]byte(`{"data":{"total":12.50}}`))
+	}))
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/orders/7", http.NoBody))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"data":{"amount":12.50}}`+"\n", w.Body.String(), "the numbers are kept as written")
+	assert.Equal(t, "26", w.Header().Get("Content-Length"))
+}
+
+func TestTransform_ResponseNotJSON(t *testing.T) {
+	handler := Transform(TransformConfig{Routes: []TransformRoute{{
+		Response: FieldMapping{Remove: []string{"secret"}},
+	}}})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/csv")
+		_, _ = w.Write([]byte(`{"secret":1}`))
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/export", http.NoBody))
+
+	assert.Equal(t, `{"secret":1}`, w.Body.String())
+}
+
+func TestLoadTransformConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	yamlPath := filepath.Join(dir, "transform.yaml")
+	require.NoError(t, os.WriteFile(yamlPath, []byte(`
+routes:
+  - method: POST
+    path: /v1/users
+    request:
+      rename:
+        fullName: name
+      enums:
+        status:
+          A: active
+    response:
+      remove: [data.password]
+`), 0o600))
+
+	config, err := LoadTransformConfig(yamlPath)
+	require.NoError(t, err)
+	require.Len(t, config.Routes, 1)
+
+	route := config.Routes[0]
+	assert.Equal(t, "POST", route.Method)
+	assert.Equal(t, "/v1/users", route.Path)
+	assert.Equal(t, map[string]string{"fullName": "name"}, route.Request.Rename)
+	assert.Equal(t, map[string]map[string]any{"status": {"A": "active"}}, route.Request.Enums)
+	assert.Equal(t, []string{"data.password"}, route.Response.Remove)
+
+	jsonPath := filepath.Join(dir, "transform.json")
+	require.NoError(t, os.WriteFile(jsonPath, []byte(`{"routes":[{"path":"/v1/users","request":{"remove":["x"]}}]}`),
+		0o600))
+
+	config, err = LoadTransformConfig(jsonPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"x"}, config.Routes[0].Request.Remove)
+
+	_, err = LoadTransformConfig(filepath.Join(dir, "transform.toml"))
+	require.Error(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bad.json"), []byte(`{`), 0o600))
+	_, err = LoadTransformConfig(filepath.Join(dir, "bad.json"))
+	require.Error(t, err)
+
+	_, err = LoadTransformConfig(filepath.Join(dir, "missing.yaml"))
+	require.Error(t, err)
+}
@@ -2,19 +2,39 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"net/http"
 
 	gorillaWebsocket "github.com/gorilla/websocket"
 
+	kiteHttp "github.com/sllt/kite/pkg/kite/http"
 	"github.com/sllt/kite/pkg/kite/infra"
 	"github.com/sllt/kite/pkg/kite/websocket"
 )
 
 // WSHandlerUpgrade middleware upgrades the incoming http request to a websocket connection using websocket upgrader.
+//
+// When the route has an upgrade hook, set with websocket.Manager.SetUpgradeAuth, the handshake is authenticated first:
+// a rejected handshake is answered with an HTTP error and never upgraded, and the authenticated principal is stored in
+// the request context under websocket.WSPrincipalKey.
 func WSHandlerUpgrade(c *infra.Container, wsManager *websocket.Manager) func(inner http.Handler) http.Handler {
 	return func(inner http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			if gorillaWebsocket.IsWebSocketUpgrade(r) {
+				ctx := r.Context()
+
+				if auth := wsManager.UpgradeAuth(routePattern(r)); auth != nil {
+					principal, err := auth(r)
+					if err != nil {
+						c.Debugf("WebSocket upgrade of %s rejected: %v", r.URL.Path, err)
+						kiteHttp.NewResponder(w, r.Method).Respond(nil, newUpgradeRejection(err))
+
+						return
+					}
+
+					ctx = context.WithValue(ctx, websocket.WSPrincipalKey, principal)
+				}
+
 				conn, err := wsManager.WebSocketUpgrader.Upgrade(w, r, nil)
 				if err != nil {
 					c.Errorf("Failed to upgrade to WebSocket: %v", err)
@@ -27,7 +47,7 @@ func WSHandlerUpgrade(c *infra.Container, wsManager *websocket.Manager) func(inn
 				wsManager.AddWebsocketConnection(r.Header.Get("Sec-WebSocket-Key"), &websocket.Connection{Conn: conn})
 
 				// Store the websocket connection key in the context
-				ctx := context.WithValue(r.Context(), websocket.WSConnectionKey, r.Header.Get("Sec-WebSocket-Key"))
+				ctx = context.WithValue(ctx, websocket.WSConnectionKey, r.Header.Get("Sec-WebSocket-Key"))
 				r = r.WithContext(ctx)
 			}
 
@@ -35,3 +55,28 @@ func WSHandlerUpgrade(c *infra.Container, wsManager *websocket.Manager) func(inn
 		})
 	}
 }
+
+// upgradeRejection is the error of an upgrade hook, responded with its status code, 401 Unauthorized by default.
+type upgradeRejection struct {
+	error
+	status int
+}
+
+func newUpgradeRejection(err error) upgradeRejection {
+	status := http.StatusUnauthorized
+
+	var httpErr ErrorHTTP
+	if errors.As(err, &httpErr) {
+		status = httpErr.StatusCode()
+	}
+
+	return upgradeRejection{error: err, status: status}
+}
+
+func (e upgradeRejection) StatusCode() int {
+	return e.status
+}
+
+func (e upgradeRejection) Unwrap() error {
+	return e.error
+}
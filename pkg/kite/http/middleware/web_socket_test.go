@@ -2,10 +2,12 @@ package middleware
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
@@ -14,7 +16,10 @@ import (
 	kiteWebSocket "github.com/sllt/kite/pkg/kite/websocket"
 )
 
-var errConnection = errors.New("can't create connection")
+var (
+	errConnection   = errors.New("can't create connection")
+	errInvalidToken = errors.New("invalid token")
+)
 
 func initializeWebSocketMocks(t *testing.T) (kiteWebSocket.MockUpgrader, *kiteWebSocket.Manager) {
 	t.Helper()
@@ -82,3 +87,74 @@ func Test_WSConnectionCreate_Success(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, rec.Code)
 }
+
+func TestWSHandlerUpgrade_UpgradeAuth(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		target    string
+		status    int
+		principal any
+	}{
+		{desc: "authenticated", target: "/ws/chat?token=alice", status: http.StatusOK, principal: "alice"},
+		{desc: "missing token", target: "/ws/chat", status: http.StatusUnauthorized},
+		{desc: "error with a status code", target: "/ws/chat?token=mallory", status: http.StatusForbidden},
+		{desc: "route without hook", target: "/ws/public", status: http.StatusOK},
+	}
+
+	for i, tc := range testCases {
+		mockUpgrader, wsManager := initializeWebSocketMocks(t)
+		mockContainer, _ := infra.NewMockContainer(t)
+
+		wsManager.SetUpgradeAuth("/ws/{room}", func(r *http.Request) (any, error) {
+			switch token := r.URL.Query().Get("token"); token {
+			case "":
+				return nil, errInvalidToken
+			case "mallory":
+				return nil, NewUnauthorized("banned")
+			default:
+				return token, nil
+			}
+		})
+
+		if tc.status == http.StatusOK {
+			mockUpgrader.EXPECT().Upgrade(gomock.Any(), gomock.Any(), gomock.Any()).Return(&websocket.Conn{}, nil)
+		}
+
+		var principal any
+
+		router := chi.NewRouter()
+		router.Use(WSHandlerUpgrade(mockContainer, wsManager))
+		router.Get("/ws/{room}", func(_ http.ResponseWriter, r *http.Request) {
+			principal = r.Context().Value(kiteWebSocket.WSPrincipalKey)
+		})
+		router.Get("/ws/public", func(http.ResponseWriter, *http.Request) {})
+
+		req := httptest.NewRequest(http.MethodGet, tc.target, http.NoBody)
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "websocket")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, tc.status, rec.Code, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, tc.principal, principal, "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestNewUpgradeRejection(t *testing.T) {
+	testCases := []struct {
+		err    error
+		status int
+	}{
+		{err: errInvalidToken, status: http.StatusUnauthorized},
+		{err: NewUnauthorized("banned"), status: http.StatusForbidden},
+		{err: fmt.Errorf("verifying token: %w", NewInvalidConfigurationError("jwks")), status: http.StatusInternalServerError},
+	}
+
+	for i, tc := range testCases {
+		err := newUpgradeRejection(tc.err)
+
+		assert.Equal(t, tc.status, err.StatusCode(), "TEST[%d], Failed.\n%v", i, tc.err)
+		assert.ErrorIs(t, err, tc.err, "TEST[%d], Failed.\n%v", i, tc.err)
+	}
+}
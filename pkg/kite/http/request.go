@@ -3,15 +3,20 @@ package http
 import (
 	"bytes"
 	"context"
-	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"reflect"
 	"strings"
 
 	"github.com/go-chi/chi/v5"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
+
+	"github.com/sllt/kite/pkg/kite/i18n"
 )
 
 const (
@@ -22,6 +27,7 @@ var (
 	errNoFileFound    = errors.New("no files were bounded")
 	errNonPointerBind = errors.New("bind error, cannot bind to a non pointer type")
 	errNonSliceBind   = errors.New("bind error: input is not a pointer to a byte slice")
+	errNonProtoBind   = errors.New("bind error: input is not a proto.Message")
 )
 
 // Request is an abstraction over the underlying http.Request. This abstraction is useful because it allows us
@@ -52,7 +58,8 @@ func (r *Request) PathParam(key string) string {
 	return chi.URLParam(r.req, key)
 }
 
-// Bind parses the request body and binds it to the provided interface.
+// Bind parses the request body and binds it to the provided interface, the decoding is chosen from the Content-Type:
+// JSON, XML, YAML, protobuf into a proto.Message, multipart and URL encoded forms or binary data.
 // It also validates the struct using "binding" or "validate" tags.
 func (r *Request) Bind(i any) error {
 	v := r.req.Header.Get("Content-Type")
@@ -62,12 +69,13 @@ func (r *Request) Bind(i any) error {
 
 	switch contentType {
 	case "application/json":
-		body, bodyErr := r.body()
-		if bodyErr != nil {
-			return bodyErr
-		}
-
-		err = json.Unmarshal(body, &i)
+		err = r.bindJSON(i)
+	case "application/xml", "text/xml":
+		err = r.bindXML(i)
+	case "application/x-yaml", "application/yaml", "text/yaml":
+		err = r.bindYAML(i)
+	case "application/x-protobuf", "application/protobuf":
+		err = r.bindProtobuf(i)
 	case "multipart/form-data":
 		err = r.bindMultipart(i)
 	case "application/x-www-form-urlencoded":
@@ -81,7 +89,37 @@ func (r *Request) Bind(i any) error {
 	}
 
 	// Validate the struct after binding
-	return validateStruct(i)
+	return validateStruct(i, i18n.LocaleFromContext(r.req.Context()))
+}
+
+type clientIPKey struct{}
+
+// WithClientIP returns a copy of ctx holding the IP of the client, as resolved from the trusted proxies.
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// ClientIPFromContext returns the IP of the client set with WithClientIP, it is empty when no trusted proxy is
+// configured.
+func ClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+
+	return ip
+}
+
+// ClientIP returns the IP of the client. The forwarding headers are only used when the request was received from one
+// of the TRUSTED_PROXIES, otherwise it is the IP of the peer.
+func (r *Request) ClientIP() string {
+	if ip := ClientIPFromContext(r.req.Context()); ip != "" {
+		return ip
+	}
+
+	host, _, err := net.SplitHostPort(r.req.RemoteAddr)
+	if err != nil {
+		return r.req.RemoteAddr
+	}
+
+	return host
 }
 
 // HostName retrieves the hostname from the request.
@@ -167,6 +205,41 @@ func (r *Request) bindForm(ptr any, isMultipart bool) error {
 	return nil
 }
 
+// bindXML handles binding for application/xml and text/xml content types.
+func (r *Request) bindXML(i any) error {
+	body, err := r.body()
+	if err != nil {
+		return err
+	}
+
+	return xml.Unmarshal(body, i)
+}
+
+// bindYAML handles binding for application/x-yaml, application/yaml and text/yaml content types.
+func (r *Request) bindYAML(i any) error {
+	body, err := r.body()
+	if err != nil {
+		return err
+	}
+
+	return yaml.Unmarshal(body, i)
+}
+
+// bindProtobuf handles binding for application/x-protobuf content type, i must be a proto.Message.
+func (r *Request) bindProtobuf(i any) error {
+	m, ok := i.(proto.Message)
+	if !ok {
+		return fmt.Errorf("%w: %T", errNonProtoBind, i)
+	}
+
+	body, err := r.body()
+	if err != nil {
+		return err
+	}
+
+	return proto.Unmarshal(body, m)
+}
+
 // bindBinary handles binding for binary/octet-stream content type.
 func (r *Request) bindBinary(raw any) error {
 	// Ensure raw is a pointer to a byte slice
@@ -9,13 +9,17 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 
 	"github.com/sllt/kite/pkg/kite/file"
+	"github.com/sllt/kite/pkg/kite/i18n"
 )
 
 func TestParam(t *testing.T) {
@@ -317,6 +321,93 @@ func TestBind_BinaryOctetStream_NotPointerToByteSlice(t *testing.T) {
 	}
 }
 
+func TestBind_XMLAndYAML(t *testing.T) {
+	type order struct {
+		ID    string `xml:"id" yaml:"id"`
+		Items int    `xml:"items" yaml:"items"`
+	}
+
+	testCases := []struct {
+		contentType string
+		body        string
+	}{
+		{"application/xml", `<order><id>o-1</id><items>3</items></order>`},
+		{"text/xml; charset=utf-8", `<order><id>o-1</id><items>3</items></order>`},
+		{"application/x-yaml", "id: o-1\nitems: 3\n"},
+		{"application/yaml", "id: o-1\nitems: 3\n"},
+		{"text/yaml", "id: o-1\nitems: 3\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.contentType, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(tc.body))
+			r.Header.Set("Content-Type", tc.contentType)
+
+			var o order
+
+			require.NoError(t, NewRequest(r).Bind(&o))
+			assert.Equal(t, order{ID: "o-1", Items: 3}, o)
+		})
+	}
+}
+
+func TestBind_XMLValidation(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/abc", strings.NewReader(`<user><email>invalid</email></user>`))
+	r.Header.Set("Content-Type", "application/xml")
+
+	x := struct {
+		Email string `xml:"email" binding:"required,email"`
+	}{}
+
+	var validationErr *ValidationError
+
+	err := NewRequest(r).Bind(&x)
+
+	require.ErrorAs(t, err, &validationErr)
+	assert.Contains(t, err.Error(), "Email")
+}
+
+func TestBind_YAMLInvalidBody(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/abc", strings.NewReader("id: [o-1"))
+	r.Header.Set("Content-Type", "application/x-yaml")
+
+	x := struct {
+		ID string `yaml:"id"`
+	}{}
+
+	assert.Error(t, NewRequest(r).Bind(&x))
+}
+
+func TestBind_Protobuf(t *testing.T) {
+	body, err := proto.Marshal(wrapperspb.String("hello"))
+	require.NoError(t, err)
+
+	for _, contentType := range []string{"application/x-protobuf", "application/protobuf"} {
+		t.Run(contentType, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/abc", bytes.NewReader(body))
+			r.Header.Set("Content-Type", contentType)
+
+			var msg wrapperspb.StringValue
+
+			require.NoError(t, NewRequest(r).Bind(&msg))
+			assert.Equal(t, "hello", msg.GetValue())
+		})
+	}
+}
+
+func TestBind_Protobuf_NotProtoMessage(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/abc", strings.NewReader("data"))
+	r.Header.Set("Content-Type", "application/x-protobuf")
+
+	x := struct {
+		Value string
+	}{}
+
+	err := NewRequest(r).Bind(&x)
+
+	require.ErrorIs(t, err, errNonProtoBind)
+}
+
 func TestBind_ValidationRequired(t *testing.T) {
 	r := httptest.NewRequest(http.MethodPost, "/abc", strings.NewReader(`{"email": ""}`))
 	r.Header.Set("Content-Type", "application/json")
@@ -332,6 +423,32 @@ func TestBind_ValidationRequired(t *testing.T) {
 	assert.Contains(t, err.Error(), "email")
 }
 
+func TestBind_ValidationLocaleOfRequest(t *testing.T) {
+	testCases := []struct {
+		locale   string
+		expected string
+	}{
+		{"zh-CN", "email为必填字段"},
+		{"en", "email is a required field"},
+		{"", "email is a required field"},
+		{"de", "email is a required field"},
+	}
+
+	for i, tc := range testCases {
+		r := httptest.NewRequest(http.MethodPost, "/abc", strings.NewReader(`{"email": ""}`))
+		r.Header.Set("Content-Type", "application/json")
+		r = r.WithContext(i18n.WithLocalizer(r.Context(), i18n.NewLocalizer(nil, tc.locale)))
+
+		x := struct {
+			Email string `json:"email" binding:"required"`
+		}{}
+
+		err := NewRequest(r).Bind(&x)
+
+		assert.EqualError(t, err, tc.expected, "TEST[%d], Failed.\n%s", i, tc.locale)
+	}
+}
+
 func TestBind_ValidationEmail(t *testing.T) {
 	r := httptest.NewRequest(http.MethodPost, "/abc", strings.NewReader(`{"email": "invalid-email"}`))
 	r.Header.Set("Content-Type", "application/json")
@@ -454,3 +571,142 @@ func TestParseMsgTag(t *testing.T) {
 	m = parseMsgTag("")
 	assert.Empty(t, m)
 }
+
+type validationOrderItem struct {
+	SKU      string `json:"sku" binding:"required"`
+	Quantity int    `json:"quantity" binding:"gte=1" msg:"gte:at least {param}"`
+}
+
+type validationAudit struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+type validationOrder struct {
+	validationAudit
+	Email string                `json:"email" binding:"required,email"`
+	Items []validationOrderItem `json:"items" binding:"required,dive"`
+}
+
+func TestValidationError_FieldErrors(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/orders",
+		strings.NewReader(`{"email": "invalid", "items": [{"sku": "a", "quantity": 1}, {"quantity": 0}]}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	var order validationOrder
+
+	err := NewRequest(r).Bind(&order)
+
+	var ve *ValidationError
+	require.ErrorAs(t, err, &ve)
+	assert.Nil(t, ve.FieldErrors(), "field errors must be disabled by default")
+
+	ve.structured = true
+
+	fieldErrors := ve.FieldErrors()
+	require.Len(t, fieldErrors, 4)
+	assert.Equal(t, FieldError{Field: "reason", Rule: "required", Message: fieldErrors[0].Message}, fieldErrors[0])
+	assert.Equal(t, "email", fieldErrors[1].Field)
+	assert.Equal(t, "email", fieldErrors[1].Rule)
+	assert.Equal(t, FieldError{Field: "items[1].sku", Rule: "required", Message: fieldErrors[2].Message}, fieldErrors[2])
+	assert.Equal(t, FieldError{Field: "items[1].quantity", Rule: "gte", Message: "at least 1"}, fieldErrors[3])
+}
+
+func TestFieldPath(t *testing.T) {
+	root := reflect.TypeOf(validationOrder{})
+
+	tests := map[string]string{
+		"validationOrder.Email":                  "email",
+		"validationOrder.Items[2].SKU":           "items[2].sku",
+		"validationOrder.validationAudit.Reason": "reason",
+		"validationOrder.Unknown.Field":          "Unknown.Field",
+	}
+
+	for namespace, expected := range tests {
+		assert.Equal(t, expected, fieldPath(root, namespace), namespace)
+	}
+}
+
+func TestBind_JSONErrors(t *testing.T) {
+	type item struct {
+		Price float64 `json:"price"`
+	}
+
+	type order struct {
+		ID    int    `json:"id"`
+		Items []item `json:"items"`
+	}
+
+	testCases := []struct {
+		desc     string
+		body     string
+		strict   bool
+		expected ErrorInvalidJSON
+	}{
+		{desc: "type error", body: `{"id":1,"items":[{"price":1},{"price":"2"}]}`,
+			expected: ErrorInvalidJSON{Field: "items[1].price", Expected: "number", Offset: 41,
+				Reason: "expected number, got string"}},
+		{desc: "object instead of number", body: `{"id":{"value":1}}`,
+			expected: ErrorInvalidJSON{Field: "id", Expected: "number", Offset: 7, Reason: "expected number, got object"}},
+		{desc: "syntax error", body: `{"id":1,"items":[}`,
+			expected: ErrorInvalidJSON{Field: "items", Offset: 18, Reason: "invalid character '}' looking for beginning of value"}},
+		{desc: "truncated", body: `{"id":1`,
+			expected: ErrorInvalidJSON{Offset: 7, Reason: "unexpected end of JSON input"}},
+		{desc: "empty", body: ``,
+			expected: ErrorInvalidJSON{Reason: "unexpected end of JSON input"}},
+		{desc: "trailing data", body: `{"id":1} {"id":2}`,
+			expected: ErrorInvalidJSON{Offset: 10, Reason: "unexpected data after the JSON value"}},
+		{desc: "unknown field", body: `{"id":1,"items":[{"price":1,"qty":2}]}`, strict: true,
+			expected: ErrorInvalidJSON{Field: "items[0].qty", Offset: 33, Reason: "unknown field"}},
+	}
+
+	for i, tc := range testCases {
+		r := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(tc.body))
+		r.Header.Set("Content-Type", "application/json")
+
+		if tc.strict {
+			r = r.WithContext(WithStrictJSON(r.Context()))
+		}
+
+		var o order
+
+		err := NewRequest(r).Bind(&o)
+
+		assert.Equal(t, tc.expected, err, "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestBind_JSONUnknownFieldsAllowedByDefault(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`{"id":1,"qty":2}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	var o struct {
+		ID int `json:"id"`
+	}
+
+	require.NoError(t, NewRequest(r).Bind(&o))
+	assert.Equal(t, 1, o.ID)
+}
+
+func TestBind_JSONTooLarge(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`{"id":12345}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.Body = http.MaxBytesReader(httptest.NewRecorder(), r.Body, 4)
+
+	var o struct {
+		ID int `json:"id"`
+	}
+
+	err := NewRequest(r).Bind(&o)
+
+	assert.Equal(t, ErrorRequestEntityTooLarge{Limit: 4}, err)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, err.(StatusCodeResponder).StatusCode()) //nolint:errorlint // the error is not wrapped.
+}
+
+func TestErrorInvalidJSON_Response(t *testing.T) {
+	err := ErrorInvalidJSON{Field: "items[1].price", Expected: "number", Offset: 41, Reason: "expected number, got string"}
+
+	assert.Equal(t, "invalid JSON body at offset 41: field items[1].price: expected number, got string", err.Error())
+	assert.Equal(t, map[string]any{"field": "items[1].price", "expected": "number", "offset": int64(41)}, err.Response())
+	assert.Equal(t, "invalid JSON body at offset 7: unexpected end of JSON input",
+		ErrorInvalidJSON{Offset: 7, Reason: "unexpected end of JSON input"}.Error())
+}
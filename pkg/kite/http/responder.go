@@ -2,32 +2,135 @@
 package http
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
 	"net/http"
 	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
 
 	resTypes "github.com/sllt/kite/pkg/kite/http/response"
 )
 
+// maxJSONPCallbackLength is the length of the longest JSONP callback.
+const maxJSONPCallbackLength = 128
+
 var (
 	errEmptyResponse = errors.New("internal server error")
+
+	// jsonpCallbackPattern matches the JSONP callbacks: JavaScript identifiers, optionally dotted, e.g. "jQuery.cb_1".
+	jsonpCallbackPattern = regexp.MustCompile(`^[A-Za-z_$][\w$]*(\.[A-Za-z_$][\w$]*)*$`)
+)
+
+// ErrorMode is the level of detail of the errors in the responses, set by HTTP_ERROR_MODE.
+type ErrorMode string
+
+const (
+	// ErrorModeDefault responds with the messages of the errors.
+	ErrorModeDefault ErrorMode = ""
+	// ErrorModeVerbose also responds with the value and the stack trace of the panics, see ErrorPanicRecovery. It is
+	// preset in development.
+	ErrorModeVerbose ErrorMode = "verbose"
+	// ErrorModeStrict hides the message and the details of the internal errors, responded with a status code of 500 or
+	// above, which could leak the internals of the application, e.g. a failing SQL query. It is preset in production.
+	ErrorModeStrict ErrorMode = "strict"
 )
 
 // NewResponder creates a new Responder instance from the given http.ResponseWriter.
 func NewResponder(w http.ResponseWriter, method string) *Responder {
-	return &Responder{w: w, method: method}
+	return &Responder{w: w, method: method, headers: &responseHeaders{}, written: &writeResult{}}
 }
 
 // Responder encapsulates an http.ResponseWriter and is responsible for crafting structured responses.
 type Responder struct {
 	w      http.ResponseWriter
 	method string
+	// headers are set by the handler through the Context, see SetHeader.
+	headers *responseHeaders
+	// errorMode is the level of detail of the errors in the responses.
+	errorMode ErrorMode
+	// written records the writing of the body, see WriteError.
+	written *writeResult
+}
+
+type writeResult struct {
+	bytes int
+	err   *WriteError
+}
+
+// WriteError is the error of a response body which was not fully written, e.g. as the client closed the connection.
+type WriteError struct {
+	// Written is the number of bytes written, out of Expected.
+	Written  int
+	Expected int
+	Err      error
+}
+
+func (e *WriteError) Error() string {
+	return fmt.Sprintf("response body written partially, %d of %d bytes: %v", e.Written, e.Expected, e.Err)
+}
+
+func (e *WriteError) Unwrap() error {
+	return e.Err
+}
+
+// ClientGone reports whether the body was not fully written as the client closed the connection, e.g. with a broken
+// pipe, rather than as the server failed.
+func (e *WriteError) ClientGone() bool {
+	return errors.Is(e.Err, syscall.EPIPE) || errors.Is(e.Err, syscall.ECONNRESET) || errors.Is(e.Err, net.ErrClosed)
+}
+
+// BytesWritten returns the number of bytes of the body written by Respond.
+func (r *Responder) BytesWritten() int {
+	if r.written == nil {
+		return 0
+	}
+
+	return r.written.bytes
+}
+
+// WriteError returns the error of the body not fully written by Respond, or nil. It is not an error of the handler:
+// the status of the response was sent, and the client may not have received all of its body.
+func (r *Responder) WriteError() *WriteError {
+	if r.written == nil {
+		return nil
+	}
+
+	return r.written.err
+}
+
+// SetErrorMode sets the level of detail of the errors in the responses, ErrorModeDefault by default.
+func (r *Responder) SetErrorMode(mode ErrorMode) {
+	r.errorMode = mode
 }
 
 // Respond sends a response with the given data and handles potential errors, setting appropriate
 // status codes and formatting responses as JSON with {code, data, message, meta} format.
 func (r Responder) Respond(data any, err error) {
+	if r.headers != nil {
+		r.headers.write(r.w)
+	}
+
+	setRetryAfter(r.w, err)
+
+	var callback string
+
+	if v, ok := data.(resTypes.JSONP); ok {
+		data, callback = v.Data, v.Callback
+
+		if callback != "" && (len(callback) > maxJSONPCallbackLength || !jsonpCallbackPattern.MatchString(callback)) {
+			data, err, callback = nil, ErrorInvalidParam{Params: []string{"callback"}}, ""
+		}
+	}
+
 	if r.handleSpecialResponseTypes(data, err) {
 		return
 	}
@@ -47,23 +150,78 @@ func (r Responder) Respond(data any, err error) {
 		resp = r.buildResponse(data, nil, err)
 	}
 
-	if r.w.Header().Get("Content-Type") == "" {
+	if callback != "" {
+		r.w.Header().Set("Content-Type", "application/javascript; charset=utf-8")
+		r.w.Header().Set("X-Content-Type-Options", "nosniff")
+	} else if r.w.Header().Get("Content-Type") == "" {
 		r.w.Header().Set("Content-Type", "application/json")
 	}
 
 	jsonData, encodeErr := json.Marshal(resp)
 	if encodeErr != nil {
-		r.w.WriteHeader(http.StatusInternalServerError)
-
-		_, _ = r.w.Write([]byte(`{"code":-1,"data":null,"message":"failed to encode response as JSON"}` + "\n"))
+		r.write(http.StatusInternalServerError,
+			[]byte(`{"code":-1,"data":null,"message":"failed to encode response as JSON"}`+"\n"))
 
 		return
 	}
 
 	statusCode := r.getHTTPStatusCode(data, err)
+
+	if callback != "" {
+		// the comment prevents the responses starting with the callback from being sniffed as other content.
+		r.write(statusCode, slices.Concat([]byte("/**/"+callback+"("), jsonData, []byte(");\n")))
+
+		return
+	}
+
+	r.write(statusCode, append(jsonData, '\n'))
+}
+
+// write writes the status and the body, whose size is sent in the Content-Length header, and records whether the body
+// was fully written.
+func (r Responder) write(statusCode int, body []byte) {
+	if bodyAllowedForStatus(statusCode) {
+		r.w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	}
+
 	r.w.WriteHeader(statusCode)
-	_, _ = r.w.Write(jsonData)
-	_, _ = r.w.Write([]byte("\n"))
+
+	if len(body) == 0 {
+		return
+	}
+
+	n, err := r.w.Write(body)
+
+	// the body of e.g. 204 No Content is dropped, it was never meant to be sent.
+	if errors.Is(err, http.ErrBodyNotAllowed) {
+		return
+	}
+
+	if err == nil && n < len(body) {
+		err = io.ErrShortWrite
+	}
+
+	if r.written == nil {
+		return
+	}
+
+	r.written.bytes += n
+
+	if err != nil {
+		r.written.err = &WriteError{Written: n, Expected: len(body), Err: err}
+	}
+}
+
+// bodyAllowedForStatus reports whether a response of the status can have a body, as in the net/http package.
+func bodyAllowedForStatus(status int) bool {
+	switch {
+	case status >= http.StatusContinue && status < http.StatusOK:
+		return false
+	case status == http.StatusNoContent, status == http.StatusNotModified:
+		return false
+	}
+
+	return true
 }
 
 // buildResponse constructs the unified response structure.
@@ -79,7 +237,24 @@ func (r Responder) buildResponse(data any, meta map[string]any, err error) respo
 
 	code := getErrorCode(err)
 
-	return response{Code: code, Data: nil, Message: err.Error(), Meta: meta}
+	if status := r.getHTTPStatusCode(data, err); r.errorMode == ErrorModeStrict &&
+		status >= http.StatusInternalServerError {
+		return response{Code: code, Data: nil, Message: http.StatusText(status)}
+	}
+
+	if m, ok := err.(ResponseMarshaller); ok && meta == nil {
+		meta = m.Response()
+	}
+
+	resp := response{Code: code, Data: nil, Message: err.Error(), Meta: meta}
+
+	if f, ok := err.(FieldErrorsResponder); ok {
+		if fieldErrors := f.FieldErrors(); len(fieldErrors) > 0 {
+			resp.Errors = fieldErrors
+		}
+	}
+
+	return resp
 }
 
 // getHTTPStatusCode returns the HTTP status code for the response.
@@ -121,15 +296,24 @@ func (r Responder) handleSpecialResponseTypes(data any, err error) bool {
 	switch v := data.(type) {
 	case resTypes.File:
 		r.w.Header().Set("Content-Type", v.ContentType)
-		r.w.WriteHeader(statusCode)
-		_, _ = r.w.Write(v.Content)
+		r.write(statusCode, v.Content)
+
+		return true
+
+	case resTypes.Text:
+		r.w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		r.write(statusCode, []byte(v.Content))
 
 		return true
 
 	case resTypes.Template:
+		// the template is rendered first, for its size to be known.
+		var body bytes.Buffer
+
+		v.Render(&body)
+
 		r.w.Header().Set("Content-Type", "text/html")
-		r.w.WriteHeader(statusCode)
-		v.Render(r.w)
+		r.write(statusCode, body.Bytes())
 
 		return true
 
@@ -141,11 +325,7 @@ func (r Responder) handleSpecialResponseTypes(data any, err error) bool {
 		}
 
 		r.w.Header().Set("Content-Type", contentType)
-		r.w.WriteHeader(statusCode)
-
-		if len(v.Content) > 0 {
-			_, _ = r.w.Write(v.Content)
-		}
+		r.write(statusCode, v.Content)
 
 		return true
 
@@ -193,6 +373,8 @@ func getCustomStatusCode(data any) (int, bool) {
 		statusCode = v.StatusCode
 	case resTypes.File:
 		statusCode = v.StatusCode
+	case resTypes.Text:
+		statusCode = v.StatusCode
 	default:
 		return 0, false
 	}
@@ -260,6 +442,7 @@ type response struct {
 	Data    any            `json:"data"`
 	Message string         `json:"message"`
 	Meta    map[string]any `json:"meta,omitempty"`
+	Errors  []FieldError   `json:"errors,omitempty"`
 }
 
 // StatusCodeResponder allows errors to specify the HTTP status code.
@@ -274,6 +457,30 @@ type CodeResponder interface {
 	Code() int
 }
 
+// RetryAfterResponder allows errors to tell the clients when to retry the request, e.g. of a 429 Too Many Requests
+// or 503 Service Unavailable response, sent in the Retry-After header.
+type RetryAfterResponder interface {
+	RetryAfter() time.Duration
+}
+
+// setRetryAfter sets the Retry-After header of the error, rounded up to the second.
+func setRetryAfter(w http.ResponseWriter, err error) {
+	e, ok := err.(RetryAfterResponder)
+	if !ok {
+		return
+	}
+
+	if d := e.RetryAfter(); d > 0 {
+		w.Header().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10))
+	}
+}
+
+// FieldErrorsResponder allows errors to report the errors of each field of the request, sent in the
+// "errors" field of the response so that clients can highlight them.
+type FieldErrorsResponder interface {
+	FieldErrors() []FieldError
+}
+
 // isNil checks if the given any value is nil.
 // It returns true if the value is nil or if it is a pointer that points to nil.
 func isNil(i any) bool {
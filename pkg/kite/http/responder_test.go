@@ -3,11 +3,17 @@ package http
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"math"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strconv"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -15,7 +21,10 @@ import (
 	resTypes "github.com/sllt/kite/pkg/kite/http/response"
 )
 
-var errTest = fmt.Errorf("internal server error")
+var (
+	errTest     = fmt.Errorf("internal server error")
+	errDatabase = fmt.Errorf("dial tcp 10.0.0.1:3306: i/o timeout")
+)
 
 func TestResponder(t *testing.T) {
 	tests := []struct {
@@ -338,6 +347,34 @@ func TestResponder_TemplateResponse(t *testing.T) {
 	assert.Equal(t, expectedBody, responseBody)
 }
 
+func TestResponder_TemplateResponse_Reload(t *testing.T) {
+	defer resTypes.SetTemplateReload(true)
+
+	testCases := []struct {
+		reload   bool
+		expected string
+	}{
+		{reload: true, expected: "<p>v2</p>"},
+		{reload: false, expected: "<p>v1</p>"},
+	}
+
+	for i, tc := range testCases {
+		resTypes.SetTemplateReload(tc.reload)
+
+		createTemplateFile(t, "./templates/version.html", "<p>v1</p>")
+		NewResponder(httptest.NewRecorder(), http.MethodGet).Respond(resTypes.Template{Name: "version.html"}, nil)
+
+		createTemplateFile(t, "./templates/version.html", "<p>v2</p>")
+
+		recorder := httptest.NewRecorder()
+		NewResponder(recorder, http.MethodGet).Respond(resTypes.Template{Name: "version.html"}, nil)
+
+		assert.Equal(t, tc.expected, recorder.Body.String(), "TEST[%d], Failed.\nreload: %t", i, tc.reload)
+
+		removeTemplateDir(t)
+	}
+}
+
 func TestIsEmptyStruct(t *testing.T) {
 	tests := []struct {
 		desc     string
@@ -790,3 +827,249 @@ func TestResponder_ValidEncodableData(t *testing.T) {
 		assert.NotEmpty(t, body.String(), "TEST[%d] Failed: %s", i, tc.desc)
 	}
 }
+
+type fieldsError struct{}
+
+func (fieldsError) Error() string { return "invalid order" }
+
+func (fieldsError) StatusCode() int { return http.StatusBadRequest }
+
+func (fieldsError) FieldErrors() []FieldError {
+	return []FieldError{{Field: "items[0].sku", Rule: "required", Message: "sku is a required field"}}
+}
+
+func TestResponder_ErrorModes(t *testing.T) {
+	panicErr := ErrorPanicRecovery{Panic: "boom", StackTrace: "goroutine 1"}
+
+	testCases := []struct {
+		desc     string
+		mode     ErrorMode
+		err      error
+		expected string
+	}{
+		{desc: "default", mode: ErrorModeDefault, err: errDatabase,
+			expected: `{"code":-1,"data":null,"message":"dial tcp 10.0.0.1:3306: i/o timeout"}`},
+		{desc: "strict internal error", mode: ErrorModeStrict, err: errDatabase,
+			expected: `{"code":-1,"data":null,"message":"Internal Server Error"}`},
+		{desc: "strict panic", mode: ErrorModeStrict, err: panicErr,
+			expected: `{"code":500,"data":null,"message":"Internal Server Error"}`},
+		{desc: "strict client error", mode: ErrorModeStrict, err: ErrorEntityNotFound{Name: "id", Value: "2"},
+			expected: `{"code":404,"data":null,"message":"No entity found with id: 2"}`},
+		{desc: "verbose panic", mode: ErrorModeVerbose, err: panicErr,
+			expected: `{"code":500,"data":null,"message":"Internal Server Error",` +
+				`"meta":{"panic":"boom","stack_trace":"goroutine 1"}}`},
+	}
+
+	for i, tc := range testCases {
+		recorder := httptest.NewRecorder()
+
+		r := NewResponder(recorder, http.MethodGet)
+		r.SetErrorMode(tc.mode)
+		r.Respond(nil, tc.err)
+
+		assert.JSONEq(t, tc.expected, recorder.Body.String(), "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestResponder_FieldErrors(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	NewResponder(recorder, http.MethodPost).Respond(nil, fieldsError{})
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.JSONEq(t, `{"code":400,"data":null,"message":"invalid order",`+
+		`"errors":[{"field":"items[0].sku","rule":"required","message":"sku is a required field"}]}`, recorder.Body.String())
+
+	// validation errors only report their fields when structured errors are enabled.
+	recorder = httptest.NewRecorder()
+
+	NewResponder(recorder, http.MethodPost).Respond(nil, &ValidationError{})
+
+	assert.NotContains(t, recorder.Body.String(), `"errors"`)
+}
+
+func TestResponder_RetryAfter(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		err        error
+		statusCode int
+		retryAfter string
+	}{
+		{"too many requests", ErrorTooManyRequests{RetryIn: 30 * time.Second}, http.StatusTooManyRequests, "30"},
+		{"rounded up", ErrorServiceUnavailable{RetryIn: 200 * time.Millisecond}, http.StatusServiceUnavailable, "1"},
+		{"no delay", ErrorTooManyRequests{}, http.StatusTooManyRequests, ""},
+		{"no retry", ErrorEntityNotFound{Name: "id", Value: "2"}, http.StatusNotFound, ""},
+	}
+
+	for i, tc := range testCases {
+		recorder := httptest.NewRecorder()
+
+		NewResponder(recorder, http.MethodGet).Respond(nil, tc.err)
+
+		assert.Equal(t, tc.statusCode, recorder.Code, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, tc.retryAfter, recorder.Header().Get("Retry-After"), "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestResponder_Text(t *testing.T) {
+	tests := []struct {
+		desc         string
+		data         resTypes.Text
+		method       string
+		expectedCode int
+	}{
+		{desc: "default status", data: resTypes.Text{Content: "OK"}, method: http.MethodGet, expectedCode: http.StatusOK},
+		{desc: "custom status", data: resTypes.Text{Content: "OK", StatusCode: http.StatusServiceUnavailable},
+			method: http.MethodGet, expectedCode: http.StatusServiceUnavailable},
+		{desc: "post", data: resTypes.Text{Content: "OK"}, method: http.MethodPost, expectedCode: http.StatusCreated},
+	}
+
+	for i, tc := range tests {
+		recorder := httptest.NewRecorder()
+
+		NewResponder(recorder, tc.method).Respond(tc.data, nil)
+
+		assert.Equal(t, tc.expectedCode, recorder.Code, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, "text/plain; charset=utf-8", recorder.Header().Get("Content-Type"), "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, "OK", recorder.Body.String(), "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestResponder_JSONP(t *testing.T) {
+	tests := []struct {
+		desc         string
+		data         resTypes.JSONP
+		err          error
+		expectedCode int
+		contentType  string
+		expectedBody string
+	}{
+		{
+			desc:         "callback",
+			data:         resTypes.JSONP{Data: map[string]int{"id": 1}, Callback: "jQuery.cb_1"},
+			expectedCode: http.StatusOK,
+			contentType:  "application/javascript; charset=utf-8",
+			expectedBody: `/**/jQuery.cb_1({"code":0,"data":{"id":1},"message":"ok"});` + "\n",
+		},
+		{
+			desc:         "error",
+			data:         resTypes.JSONP{Callback: "cb"},
+			err:          ErrorEntityNotFound{Name: "id", Value: "1"},
+			expectedCode: http.StatusNotFound,
+			contentType:  "application/javascript; charset=utf-8",
+			expectedBody: `/**/cb({"code":404,"data":null,"message":"No entity found with id: 1"});` + "\n",
+		},
+		{
+			desc:         "no callback",
+			data:         resTypes.JSONP{Data: resTypes.Raw{Data: []int{1}}},
+			expectedCode: http.StatusOK,
+			contentType:  "application/json",
+			expectedBody: "[1]\n",
+		},
+		{
+			desc:         "invalid callback",
+			data:         resTypes.JSONP{Data: "secret", Callback: "alert(1);cb"},
+			expectedCode: http.StatusBadRequest,
+			contentType:  "application/json",
+			expectedBody: `{"code":400,"data":null,"message":"'1' invalid parameter(s): callback"}` + "\n",
+		},
+		{
+			desc:         "long callback",
+			data:         resTypes.JSONP{Data: "secret", Callback: strings.Repeat("a", 129)},
+			expectedCode: http.StatusBadRequest,
+			contentType:  "application/json",
+			expectedBody: `{"code":400,"data":null,"message":"'1' invalid parameter(s): callback"}` + "\n",
+		},
+	}
+
+	for i, tc := range tests {
+		recorder := httptest.NewRecorder()
+
+		NewResponder(recorder, http.MethodGet).Respond(tc.data, tc.err)
+
+		assert.Equal(t, tc.expectedCode, recorder.Code, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, tc.contentType, recorder.Header().Get("Content-Type"), "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, tc.expectedBody, recorder.Body.String(), "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestResponder_ContentLength(t *testing.T) {
+	tests := []struct {
+		desc      string
+		method    string
+		data      any
+		hasLength bool
+	}{
+		{"JSON", http.MethodGet, map[string]string{"id": "1"}, true},
+		{"JSONP", http.MethodGet, resTypes.JSONP{Data: "ok", Callback: "cb"}, true},
+		{"text", http.MethodGet, resTypes.Text{Content: "hello"}, true},
+		{"file", http.MethodGet, resTypes.File{Content: []byte("a,b\n"), ContentType: "text/csv"}, true},
+		{"empty XML", http.MethodGet, resTypes.XML{}, true},
+		{"no content", http.MethodDelete, nil, false},
+	}
+
+	for i, tc := range tests {
+		recorder := httptest.NewRecorder()
+		r := NewResponder(recorder, tc.method)
+
+		r.Respond(tc.data, nil)
+
+		// the body of a 204 No Content is never sent.
+		contentLength, written := "", 0
+		if tc.hasLength {
+			contentLength, written = strconv.Itoa(recorder.Body.Len()), recorder.Body.Len()
+		}
+
+		assert.Equal(t, contentLength, recorder.Header().Get("Content-Length"), "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, written, r.BytesWritten(), "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+// brokenWriter writes n bytes of each body, failing with err.
+type brokenWriter struct {
+	*httptest.ResponseRecorder
+	n   int
+	err error
+}
+
+func (w *brokenWriter) Write(b []byte) (int, error) {
+	n, _ := w.ResponseRecorder.Write(b[:min(w.n, len(b))])
+
+	return n, w.err
+}
+
+func TestResponder_WriteError(t *testing.T) {
+	tests := []struct {
+		desc       string
+		n          int
+		err        error
+		expected   error
+		clientGone bool
+	}{
+		{"broken pipe", 3, &net.OpError{Op: "write", Err: os.NewSyscallError("write", syscall.EPIPE)}, syscall.EPIPE,
+			true},
+		{"connection reset", 0, syscall.ECONNRESET, syscall.ECONNRESET, true},
+		{"short write", 10, nil, io.ErrShortWrite, false},
+		{"server failure", 0, errTest, errTest, false},
+	}
+
+	for i, tc := range tests {
+		r := NewResponder(&brokenWriter{ResponseRecorder: httptest.NewRecorder(), n: tc.n, err: tc.err}, http.MethodGet)
+
+		r.Respond(resTypes.Text{Content: strings.Repeat("a", 20)}, nil)
+
+		writeErr := r.WriteError()
+
+		require.Error(t, writeErr, "TEST[%d], Failed.\n%s", i, tc.desc)
+		require.ErrorIs(t, writeErr, tc.expected, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, tc.clientGone, writeErr.ClientGone(), "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, tc.n, writeErr.Written, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, 20, writeErr.Expected, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, tc.n, r.BytesWritten(), "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+
+	r := NewResponder(httptest.NewRecorder(), http.MethodGet)
+	r.Respond(resTypes.Text{Content: "ok"}, nil)
+
+	assert.Nil(t, r.WriteError())
+}
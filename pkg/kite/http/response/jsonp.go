@@ -0,0 +1,13 @@
+package response
+
+// JSONP represents a JSON response wrapped in a call to the JavaScript function Callback, for the legacy browser
+// integrations loading the responses as scripts. Callback is usually read from the query of the request:
+//
+//	return response.JSONP{Data: orders, Callback: ctx.Param("callback")}, nil
+//
+// Data is sent as a plain JSON response when Callback is empty. The requests with a Callback which is not a
+// JavaScript identifier, optionally dotted, fail with 400 Bad Request.
+type JSONP struct {
+	Data     any
+	Callback string
+}
@@ -3,14 +3,52 @@ package response
 import (
 	"html/template"
 	"io"
+	"sync"
+	"sync/atomic"
 )
 
+var (
+	// templatesReload reports whether the templates are parsed on every render, so that their changes are served
+	// without restarting the application, which is the default.
+	templatesReload atomic.Bool
+
+	// templates caches the parsed templates by name when they are not reloaded.
+	templates sync.Map
+)
+
+func init() {
+	templatesReload.Store(true)
+}
+
+// SetTemplateReload sets whether the templates are parsed on every render, or parsed once and cached. Kite sets it
+// from TEMPLATE_RELOAD, which is disabled by the presets of staging and production.
+func SetTemplateReload(reload bool) {
+	templatesReload.Store(reload)
+
+	if reload {
+		templates.Clear()
+	}
+}
+
 type Template struct { // Named as such to avoid conflict with imported template
 	Data any
 	Name string
 }
 
 func (t *Template) Render(w io.Writer) {
-	tmpl := template.Must(template.ParseFiles("./templates/" + t.Name))
-	_ = tmpl.Execute(w, t.Data)
+	_ = t.parse().Execute(w, t.Data)
+}
+
+func (t *Template) parse() *template.Template {
+	if templatesReload.Load() {
+		return template.Must(template.ParseFiles("./templates/" + t.Name))
+	}
+
+	if tmpl, ok := templates.Load(t.Name); ok {
+		return tmpl.(*template.Template)
+	}
+
+	tmpl, _ := templates.LoadOrStore(t.Name, template.Must(template.ParseFiles("./templates/"+t.Name)))
+
+	return tmpl.(*template.Template)
 }
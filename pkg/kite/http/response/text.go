@@ -0,0 +1,11 @@
+package response
+
+// Text represents a plain text response, sent as text/plain without the JSON envelope, e.g. for the health checks of
+// load balancers.
+type Text struct {
+	Content string
+
+	// StatusCode overrides Kite's default success HTTP status code when set to a valid HTTP status.
+	// If not set (0) or invalid, Kite uses its existing status selection logic.
+	StatusCode int
+}
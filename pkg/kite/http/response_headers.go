@@ -0,0 +1,74 @@
+package http
+
+import (
+	"net/http"
+	"sync"
+)
+
+// responseHeaders are the headers and cookies set by a handler, which the Responder writes before the response
+// whatever its type. They are buffered as the handler runs in its own goroutine, and may still be running when the
+// request times out.
+type responseHeaders struct {
+	mu      sync.Mutex
+	ops     []headerOp
+	written bool
+}
+
+type headerOp struct {
+	key   string
+	value string
+	add   bool
+}
+
+func (h *responseHeaders) append(op headerOp) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// the response is already sent.
+	if h.written {
+		return
+	}
+
+	h.ops = append(h.ops, op)
+}
+
+// write sets the buffered headers in the order they were set, once.
+func (h *responseHeaders) write(w http.ResponseWriter) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.written {
+		return
+	}
+
+	h.written = true
+
+	for _, op := range h.ops {
+		if op.add {
+			w.Header().Add(op.key, op.value)
+		} else {
+			w.Header().Set(op.key, op.value)
+		}
+	}
+}
+
+// SetHeader sets the header of the response, replacing its values.
+func (r Responder) SetHeader(key, value string) {
+	if r.headers != nil {
+		r.headers.append(headerOp{key: key, value: value})
+	}
+}
+
+// AddHeader adds a value to the header of the response.
+func (r Responder) AddHeader(key, value string) {
+	if r.headers != nil {
+		r.headers.append(headerOp{key: key, value: value, add: true})
+	}
+}
+
+// SetCookie adds the cookie to the response. The cookies with an invalid name are dropped, as by http.SetCookie.
+func (r Responder) SetCookie(cookie *http.Cookie) {
+	if v := cookie.String(); v != "" {
+		r.AddHeader("Set-Cookie", v)
+	}
+}
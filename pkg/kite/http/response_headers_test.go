@@ -0,0 +1,59 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	resTypes "github.com/sllt/kite/pkg/kite/http/response"
+)
+
+func TestResponder_Headers(t *testing.T) {
+	createTemplateFile(t, "./templates/headers.html", "<p>ok</p>")
+	defer removeTemplateDir(t)
+
+	testCases := []struct {
+		desc string
+		data any
+		err  error
+	}{
+		{"json response", map[string]string{"id": "1"}, nil},
+		{"error response", nil, ErrorEntityNotFound{Name: "id", Value: "1"}},
+		{"xml response", resTypes.XML{Content: []byte(`<ok/>`)}, nil},
+		{"file response", resTypes.File{Content: []byte("a,b"), ContentType: "text/csv"}, nil},
+		{"template response", resTypes.Template{Name: "headers.html"}, nil},
+		{"redirect response", resTypes.Redirect{URL: "/next"}, nil},
+		{"raw response", resTypes.Raw{Data: "ok"}, nil},
+	}
+
+	for i, tc := range testCases {
+		w := httptest.NewRecorder()
+		r := NewResponder(w, http.MethodGet)
+
+		r.SetHeader("Cache-Control", "no-store")
+		r.AddHeader("X-Trace", "a")
+		r.AddHeader("X-Trace", "b")
+		r.SetCookie(&http.Cookie{Name: "seen", Value: "1"})
+
+		r.Respond(tc.data, tc.err)
+
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"), "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, []string{"a", "b"}, w.Header().Values("X-Trace"), "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, "seen=1", w.Header().Get("Set-Cookie"), "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestResponder_HeadersAfterResponse(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := NewResponder(w, http.MethodGet)
+
+	r.Respond("ok", nil)
+
+	r.SetHeader("X-Late", "value")
+	r.SetCookie(&http.Cookie{Name: "", Value: "invalid"})
+
+	assert.Empty(t, w.Header().Get("X-Late"), "the headers set after the response are not sent")
+	assert.Empty(t, w.Header().Values("Set-Cookie"))
+}
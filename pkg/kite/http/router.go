@@ -21,6 +21,12 @@ const (
 
 var errReadPermissionDenied = fmt.Errorf("file does not have read permission")
 
+// routableMethods are the methods looked up in the router to list the methods allowed for a path.
+var routableMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+	http.MethodConnect, http.MethodTrace,
+}
+
 // Router is responsible for routing HTTP request.
 type Router struct {
 	mux              *chi.Mux
@@ -60,10 +66,45 @@ func (rou *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// OPTIONS requests are answered by the CORS middleware, the Allow header tells the methods of the path.
+	if r.Method == http.MethodOptions {
+		if allowed := rou.AllowedMethods(r.URL.Path); len(allowed) > 0 {
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+		}
+	}
+
 	// Delegate to the underlying chi router
 	rou.mux.ServeHTTP(w, r)
 }
 
+// AllowedMethods returns the methods for which a route matches the path, followed by OPTIONS which is answered for
+// all the paths. It returns nil if no route matches the path.
+func (rou *Router) AllowedMethods(urlPath string) []string {
+	var allowed []string
+
+	for _, method := range routableMethods {
+		if rou.mux.Match(chi.NewRouteContext(), method, urlPath) {
+			allowed = append(allowed, method)
+		}
+	}
+
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	return append(allowed, http.MethodOptions)
+}
+
+// MethodNotAllowed sets the handler for requests whose path only matches routes of other methods. The Allow header
+// listing the methods of the path is set before calling it.
+func (rou *Router) MethodNotAllowed(handler http.Handler) {
+	rou.mux.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", strings.Join(rou.AllowedMethods(r.URL.Path), ", "))
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
 // Add adds a new route with the given HTTP method, pattern, and handler, wrapping the handler with OpenTelemetry instrumentation.
 func (rou *Router) Add(method, pattern string, handler http.Handler) {
 	h := otelhttp.NewHandler(handler, "kite-router")
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/sllt/kite/pkg/kite/config"
@@ -477,6 +478,49 @@ func TestRouter_NotFound(t *testing.T) {
 	assert.Equal(t, http.StatusOK, rec.Code)
 }
 
+// TestRouter_MethodNotAllowed verifies that requests to a path registered for other methods
+// get the custom handler with the Allow header.
+func TestRouter_MethodNotAllowed(t *testing.T) {
+	router := NewRouter()
+
+	router.MethodNotAllowed(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, _ = w.Write([]byte("custom 405"))
+	}))
+
+	router.RouteGroup("/api", func(r chi.Router) {
+		r.Get("/users/{id}", func(w http.ResponseWriter, _ *http.Request) {})
+		r.Delete("/users/{id}", func(w http.ResponseWriter, _ *http.Request) {})
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/users/1", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, "custom 405", rec.Body.String())
+	assert.Equal(t, "GET, DELETE, OPTIONS", rec.Header().Get("Allow"))
+}
+
+// TestRouter_AllowedMethods verifies the methods listed for a path.
+func TestRouter_AllowedMethods(t *testing.T) {
+	router := NewRouter()
+
+	router.Add(http.MethodGet, "/users", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	router.Add(http.MethodPost, "/users", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	assert.Equal(t, []string{http.MethodGet, http.MethodPost, http.MethodOptions}, router.AllowedMethods("/users"))
+	assert.Nil(t, router.AllowedMethods("/orders"))
+
+	req := httptest.NewRequest(http.MethodOptions, "/users", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, "GET, POST, OPTIONS", rec.Header().Get("Allow"))
+}
+
 // TestRouter_Walk verifies route traversal functionality.
 func TestRouter_Walk(t *testing.T) {
 	router := NewRouter()
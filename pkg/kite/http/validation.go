@@ -17,9 +17,11 @@ import (
 )
 
 var (
-	validate     *validator.Validate
-	trans        ut.Translator
-	validateOnce sync.Once
+	validate         *validator.Validate
+	translators      *ut.UniversalTranslator
+	trans            ut.Translator
+	structuredErrors bool
+	validateOnce     sync.Once
 )
 
 func initValidator() {
@@ -41,21 +43,20 @@ func initValidator() {
 		return name
 	})
 
-	// Initialize translator based on VALIDATION_LOCALE env
-	locale := os.Getenv("VALIDATION_LOCALE")
-
-	switch locale {
-	case "zh":
-		loc := zh.New()
-		uni := ut.New(loc, loc)
-		trans, _ = uni.GetTranslator("zh")
-		_ = zhTrans.RegisterDefaultTranslations(validate, trans)
-	default: // "en" or unset
-		loc := en.New()
-		uni := ut.New(loc, loc)
-		trans, _ = uni.GetTranslator("en")
-		_ = enTrans.RegisterDefaultTranslations(validate, trans)
-	}
+	// VALIDATION_ERROR_FORMAT=structured adds the errors of each field to the responses
+	structuredErrors = os.Getenv("VALIDATION_ERROR_FORMAT") == "structured"
+
+	// The errors are translated to the locale of the request, see middleware.Locale, and otherwise to the
+	// VALIDATION_LOCALE env, "en" by default.
+	translators = ut.New(en.New(), en.New(), zh.New())
+
+	enTranslator, _ := translators.GetTranslator("en")
+	_ = enTrans.RegisterDefaultTranslations(validate, enTranslator)
+
+	zhTranslator, _ := translators.GetTranslator("zh")
+	_ = zhTrans.RegisterDefaultTranslations(validate, zhTranslator)
+
+	trans, _ = translators.GetTranslator(os.Getenv("VALIDATION_LOCALE"))
 }
 
 func getValidator() *validator.Validate {
@@ -64,15 +65,26 @@ func getValidator() *validator.Validate {
 	return validate
 }
 
-func getTranslator() ut.Translator {
+// getTranslator returns the translator of locale, e.g. "zh" for "zh-CN", or the one of VALIDATION_LOCALE when
+// locale is empty or not supported.
+func getTranslator(locale string) ut.Translator {
 	validateOnce.Do(initValidator)
 
+	if locale == "" {
+		return trans
+	}
+
+	language, _, _ := strings.Cut(locale, "-")
+	if t, found := translators.FindTranslator(strings.ReplaceAll(locale, "-", "_"), language); found {
+		return t
+	}
+
 	return trans
 }
 
-// validateStruct validates the given struct using "binding" tags.
+// validateStruct validates the given struct using "binding" tags, the errors being translated to locale.
 // Priority: msg tag > label + translator > default translator.
-func validateStruct(i any) error {
+func validateStruct(i any, locale string) error {
 	val := reflect.ValueOf(i)
 	if val.Kind() == reflect.Ptr {
 		val = val.Elem()
@@ -97,6 +109,8 @@ func validateStruct(i any) error {
 		return &ValidationError{
 			Errors:     validationErrors,
 			structType: val.Type(),
+			structured: structuredErrors,
+			locale:     locale,
 		}
 	}
 
@@ -125,10 +139,20 @@ func hasValidationTags(t reflect.Type) bool {
 type ValidationError struct {
 	Errors     validator.ValidationErrors
 	structType reflect.Type
+	structured bool
+	locale     string
+}
+
+// FieldError is the validation error of a single field.
+type FieldError struct {
+	// Field is the path of the field in the request body, using the json names, e.g. "items[0].name".
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
 }
 
 func (e *ValidationError) Error() string {
-	t := getTranslator()
+	t := getTranslator(e.locale)
 
 	var msgs []string
 
@@ -144,10 +168,113 @@ func (e *ValidationError) StatusCode() int {
 	return http.StatusBadRequest
 }
 
+// FieldErrors returns the error of each field, which the Responder sends in the "errors" field of the response.
+// It returns nil unless structured validation errors are enabled with VALIDATION_ERROR_FORMAT=structured.
+func (e *ValidationError) FieldErrors() []FieldError {
+	if !e.structured {
+		return nil
+	}
+
+	t := getTranslator(e.locale)
+	fieldErrors := make([]FieldError, 0, len(e.Errors))
+
+	for _, fe := range e.Errors {
+		fieldErrors = append(fieldErrors, FieldError{
+			Field:   fieldPath(e.structType, fe.StructNamespace()),
+			Rule:    fe.Tag(),
+			Message: e.resolveMessage(fe, t),
+		})
+	}
+
+	return fieldErrors
+}
+
+// fieldPath converts the Go namespace of a field, e.g. "Order.Items[0].Name", to its path in the request body,
+// e.g. "items[0].name". Embedded structs without a json name are flattened, as in encoding/json.
+func fieldPath(root reflect.Type, structNamespace string) string {
+	if root != nil && root.Name() != "" {
+		structNamespace = strings.TrimPrefix(structNamespace, root.Name()+".")
+	}
+
+	t := root
+	path := make([]string, 0, strings.Count(structNamespace, ".")+1)
+
+	for _, part := range strings.Split(structNamespace, ".") {
+		name, index := part, ""
+		if i := strings.IndexByte(part, '['); i >= 0 {
+			name, index = part[:i], part[i:]
+		}
+
+		field := findStructField(t, name)
+		if field == nil {
+			path = append(path, part)
+			t = nil
+
+			continue
+		}
+
+		t = elemType(field.Type)
+
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			continue
+		}
+
+		path = append(path, jsonFieldName(field)+index)
+	}
+
+	return strings.Join(path, ".")
+}
+
+// namespaceField returns the field of a Go namespace, e.g. "Order.Items[0].Name", which may be nested in the structs
+// of the root type, or nil if it is not found.
+func namespaceField(root reflect.Type, structNamespace string) *reflect.StructField {
+	if root != nil && root.Name() != "" {
+		structNamespace = strings.TrimPrefix(structNamespace, root.Name()+".")
+	}
+
+	var field *reflect.StructField
+
+	t := root
+
+	for _, part := range strings.Split(structNamespace, ".") {
+		name, _, _ := strings.Cut(part, "[")
+
+		if field = findStructField(t, name); field == nil {
+			return nil
+		}
+
+		t = elemType(field.Type)
+	}
+
+	return field
+}
+
+// elemType returns the struct type reached through pointers, slices, arrays and maps of t.
+func elemType(t reflect.Type) reflect.Type {
+	for {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		default:
+			return t
+		}
+	}
+}
+
+// jsonFieldName returns the json name of field, or its Go name if it has none.
+func jsonFieldName(field *reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "" || name == "-" {
+		return field.Name
+	}
+
+	return name
+}
+
 // resolveMessage resolves the error message for a single field error.
 // Priority: msg tag (per-rule > wildcard) > translator.
 func (e *ValidationError) resolveMessage(fe validator.FieldError, t ut.Translator) string {
-	field := findStructField(e.structType, fe.StructField())
+	field := namespaceField(e.structType, fe.StructNamespace())
 	if field != nil {
 		msgTag := field.Tag.Get("msg")
 		if msgTag != "" {
@@ -213,10 +340,7 @@ func parseMsgTag(msgTag string) map[string]string {
 //   - {param} : rule parameter (e.g. "6" for min=6)
 //   - {value} : current field value
 func replaceVars(msg string, fe validator.FieldError, field *reflect.StructField) string {
-	jsonName := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
-	if jsonName == "" || jsonName == "-" {
-		jsonName = field.Name
-	}
+	jsonName := jsonFieldName(field)
 
 	labelName := field.Tag.Get("label")
 	if labelName == "" {
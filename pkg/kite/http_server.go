@@ -18,6 +18,7 @@ type httpServer struct {
 	router      *kiteHTTP.Router
 	registry    *RouteRegistry
 	port        int
+	bind        serverListener
 	ws          *websocket.Manager
 	srv         *http.Server
 	certFile    string
@@ -34,14 +35,42 @@ func newHTTPServer(c *infra.Container, port int, middlewareConfigs middleware.Co
 	r := kiteHTTP.NewRouter()
 	wsManager := websocket.New()
 
+	// the client IP is resolved first, so that the logs and the rate limiter see the address of the client.
+	if len(middlewareConfigs.TrustedProxies) > 0 {
+		proxies, err := middleware.ParseTrustedProxies(middlewareConfigs.TrustedProxies)
+		if err != nil {
+			c.Logger.Errorf("invalid TRUSTED_PROXIES, forwarding headers are not trusted: %v", err)
+		}
+
+		r.Use(middleware.ClientIP(proxies))
+	}
+
+	// the method is overridden before any other middleware, so that they all see the method the request is routed to.
+	if middlewareConfigs.MethodOverride {
+		r.Use(middleware.MethodOverride)
+	}
+
 	r.Use(
 		middleware.Tracer,
-		middleware.Logging(middlewareConfigs.LogProbes, c.Logger),
+		middleware.AccessLog(middleware.AccessLogConfig{Probes: middlewareConfigs.LogProbes,
+			Sampling: middlewareConfigs.AccessLogSampling}, c.Logger),
 		middleware.CORS(middlewareConfigs.CorsHeaders, r.RegisteredRoutes),
 		middleware.Metrics(c.Metrics()),
 		middleware.WSHandlerUpgrade(c, wsManager),
 	)
 
+	// the deadline of the caller is applied first, so that the doomed requests are not queued by the concurrency limits.
+	r.Use(middleware.DeadlineBudget(middlewareConfigs.DeadlineOverhead, c.Logger))
+
+	// requests are shed after the logging and metrics middlewares, so that the 503 responses are logged and counted.
+	if middlewareConfigs.ConcurrencyLimit != nil {
+		r.Use(middleware.ConcurrencyLimiter(*middlewareConfigs.ConcurrencyLimit, c.Metrics()))
+	}
+
+	if middlewareConfigs.SecurityHeaders != nil {
+		r.Use(middleware.SecurityHeaders(*middlewareConfigs.SecurityHeaders))
+	}
+
 	return &httpServer{
 		router:      r,
 		registry:    newRouteRegistry(),
@@ -57,23 +86,35 @@ func (s *httpServer) run(c *infra.Container) {
 		return
 	}
 
-	c.Logf("Starting server on port: %d", s.port)
+	if s.bind.usesPort() {
+		c.Logf("Starting server on port: %d", s.port)
+	} else {
+		c.Logf("Starting server on %s", s.bind.address(s.port))
+	}
 
 	s.srv = &http.Server{
-		Addr:              fmt.Sprintf(":%d", s.port),
 		Handler:           s.router,
 		ReadHeaderTimeout: 5 * time.Second,
 	}
 
-	// If both certFile and keyFile are provided, validate and run HTTPS server
-	if s.certFile != "" && s.keyFile != "" {
+	// If both certFile and keyFile are provided, validate them before listening
+	useTLS := s.certFile != "" && s.keyFile != ""
+	if useTLS {
 		if err := validateCertificateAndKeyFiles(s.certFile, s.keyFile); err != nil {
 			c.Error(err)
 			return
 		}
+	}
+
+	listener, err := s.bind.listen(s.port)
+	if err != nil {
+		c.Errorf("error while listening to http server, err: %v", err)
+		return
+	}
 
-		// Start HTTPS server with TLS
-		if err := s.srv.ListenAndServeTLS(s.certFile, s.keyFile); err != nil {
+	// Start HTTPS server with TLS
+	if useTLS {
+		if err := s.srv.ServeTLS(listener, s.certFile, s.keyFile); err != nil {
 			c.Errorf("error while listening to https server, err: %v", err)
 		}
 
@@ -81,7 +122,7 @@ func (s *httpServer) run(c *infra.Container) {
 	}
 
 	// If no certFile/keyFile is provided, run the HTTP server
-	if err := s.srv.ListenAndServe(); err != nil {
+	if err := s.srv.Serve(listener); err != nil {
 		c.Errorf("error while listening to http server, err: %v", err)
 	}
 }
@@ -102,6 +143,20 @@ func (s *httpServer) Shutdown(ctx context.Context) error {
 	})
 }
 
+// recordRegisteredMethods records the methods of the routes of the router, which the CORS middleware allows.
+func (s *httpServer) recordRegisteredMethods() {
+	var registeredMethods []string
+
+	_ = s.router.Walk(func(method, _ string) error {
+		if !contains(registeredMethods, method) {
+			registeredMethods = append(registeredMethods, method)
+		}
+		return nil
+	})
+
+	*s.router.RegisteredRoutes = registeredMethods
+}
+
 func validateCertificateAndKeyFiles(certificateFile, keyFile string) error {
 	if _, err := os.Stat(certificateFile); os.IsNotExist(err) {
 		return fmt.Errorf("%w : %v", errInvalidCertificateFile, certificateFile)
@@ -0,0 +1,191 @@
+// Package i18n translates the messages of an application to the locale of each request.
+package i18n
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+var errNoBundleFiles = errors.New("no message bundle files found")
+
+// Bundle holds the messages of each locale, loaded from JSON files named after their locale, e.g. "en.json" and
+// "pt-BR.json". The nested objects of a file are flattened to dotted keys:
+//
+//	{"order": {"created": "Order %s created"}}
+//
+// is the message "order.created".
+type Bundle struct {
+	defaultLocale string
+	messages      map[string]map[string]string
+}
+
+// NewBundle loads the "*.json" files of dir in fsys, typically an embed.FS. The messages missing from a locale are
+// read from defaultLocale.
+//
+//	//go:embed locales
+//	var locales embed.FS
+//
+//	bundle, err := i18n.NewBundle(locales, "locales", "en")
+func NewBundle(fsys fs.FS, dir, defaultLocale string) (*Bundle, error) {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w in %q", errNoBundleFiles, dir)
+	}
+
+	b := &Bundle{defaultLocale: canonical(defaultLocale), messages: make(map[string]map[string]string, len(files))}
+
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		var values map[string]any
+		if err = json.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("parsing message bundle %q: %w", file, err)
+		}
+
+		messages := make(map[string]string)
+		flatten("", values, messages)
+
+		b.messages[canonical(strings.TrimSuffix(path.Base(file), ".json"))] = messages
+	}
+
+	return b, nil
+}
+
+// flatten adds the string values of the nested values to messages, under their dotted keys.
+func flatten(prefix string, values map[string]any, messages map[string]string) {
+	for key, value := range values {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		switch v := value.(type) {
+		case string:
+			messages[key] = v
+		case map[string]any:
+			flatten(key, v, messages)
+		}
+	}
+}
+
+// Locales returns the locales of the bundle, sorted.
+func (b *Bundle) Locales() []string {
+	locales := make([]string, 0, len(b.messages))
+	for locale := range b.messages {
+		locales = append(locales, locale)
+	}
+
+	sort.Strings(locales)
+
+	return locales
+}
+
+// T returns the message of key in locale, formatted with args as in fmt.Sprintf. The message is looked up in
+// locale, then in its base language, e.g. "pt" for "pt-BR", then in the default locale. The key itself is returned
+// when no message is found.
+func (b *Bundle) T(locale, key string, args ...any) string {
+	message, ok := b.lookup(canonical(locale), key)
+	if !ok {
+		message, ok = b.lookup(b.defaultLocale, key)
+	}
+
+	if !ok {
+		message = key
+	}
+
+	return format(message, args)
+}
+
+func format(message string, args []any) string {
+	if len(args) == 0 {
+		return message
+	}
+
+	return fmt.Sprintf(message, args...)
+}
+
+func (b *Bundle) lookup(locale, key string) (string, bool) {
+	if message, ok := b.messages[locale][key]; ok {
+		return message, true
+	}
+
+	base, _, found := strings.Cut(locale, "-")
+	if !found {
+		return "", false
+	}
+
+	message, ok := b.messages[base][key]
+
+	return message, ok
+}
+
+type localizerKey struct{}
+
+// Localizer translates the messages of a request to its locale.
+type Localizer struct {
+	bundle *Bundle
+	locale string
+}
+
+// NewLocalizer returns the Localizer of bundle for locale.
+func NewLocalizer(bundle *Bundle, locale string) *Localizer {
+	return &Localizer{bundle: bundle, locale: canonical(locale)}
+}
+
+// Locale returns the locale of the Localizer, empty for a nil Localizer.
+func (l *Localizer) Locale() string {
+	if l == nil {
+		return ""
+	}
+
+	return l.locale
+}
+
+// T returns the message of key in the locale of the Localizer, see Bundle.T. A nil Localizer, returned when no
+// locale is resolved for the request, formats the key itself.
+func (l *Localizer) T(key string, args ...any) string {
+	if l == nil || l.bundle == nil {
+		return format(key, args)
+	}
+
+	return l.bundle.T(l.locale, key, args...)
+}
+
+// WithLocalizer returns a copy of ctx carrying the Localizer.
+func WithLocalizer(ctx context.Context, l *Localizer) context.Context {
+	return context.WithValue(ctx, localizerKey{}, l)
+}
+
+// FromContext returns the Localizer carried by ctx, or nil.
+func FromContext(ctx context.Context) *Localizer {
+	l, _ := ctx.Value(localizerKey{}).(*Localizer)
+
+	return l
+}
+
+// LocaleFromContext returns the locale resolved for the request carried by ctx, or an empty string.
+func LocaleFromContext(ctx context.Context) string {
+	return FromContext(ctx).Locale()
+}
+
+// canonical returns the locale with a lower case language and an upper case region, e.g. "pt-BR" for "pt_br".
+func canonical(locale string) string {
+	language, region, found := strings.Cut(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"), "-")
+	if !found {
+		return strings.ToLower(language)
+	}
+
+	return strings.ToLower(language) + "-" + strings.ToUpper(region)
+}
@@ -0,0 +1,72 @@
+package i18n
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testBundle(t *testing.T) *Bundle {
+	t.Helper()
+
+	bundle, err := NewBundle(fstest.MapFS{
+		"locales/en.json":    {Data: []byte(`{"greeting": "Hello %s", "order": {"created": "Order created"}, "bye": "Bye"}`)},
+		"locales/fr.json":    {Data: []byte(`{"greeting": "Bonjour %s", "order": {"created": "Commande créée"}}`)},
+		"locales/pt_br.json": {Data: []byte(`{"greeting": "Olá %s"}`)},
+	}, "locales", "en")
+	require.NoError(t, err)
+
+	return bundle
+}
+
+func TestBundle_T(t *testing.T) {
+	bundle := testBundle(t)
+
+	testCases := []struct {
+		desc     string
+		locale   string
+		key      string
+		args     []any
+		expected string
+	}{
+		{"formatted message", "fr", "greeting", []any{"Ana"}, "Bonjour Ana"},
+		{"nested key", "fr", "order.created", nil, "Commande créée"},
+		{"base language", "fr-CA", "greeting", []any{"Ana"}, "Bonjour Ana"},
+		{"region of the file name", "pt-BR", "greeting", []any{"Ana"}, "Olá Ana"},
+		{"message of the default locale", "fr", "bye", nil, "Bye"},
+		{"unsupported locale", "de", "greeting", []any{"Ana"}, "Hello Ana"},
+		{"missing key", "fr", "missing", nil, "missing"},
+	}
+
+	for i, tc := range testCases {
+		assert.Equal(t, tc.expected, bundle.T(tc.locale, tc.key, tc.args...), "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestBundle_Locales(t *testing.T) {
+	assert.Equal(t, []string{"en", "fr", "pt-BR"}, testBundle(t).Locales())
+}
+
+func TestNewBundle_Errors(t *testing.T) {
+	_, err := NewBundle(fstest.MapFS{}, "locales", "en")
+	require.ErrorIs(t, err, errNoBundleFiles)
+
+	_, err = NewBundle(fstest.MapFS{"locales/en.json": {Data: []byte(`{`)}}, "locales", "en")
+	require.ErrorContains(t, err, `parsing message bundle "locales/en.json"`)
+}
+
+func TestLocalizer_FromContext(t *testing.T) {
+	ctx := WithLocalizer(t.Context(), NewLocalizer(testBundle(t), "fr"))
+
+	assert.Equal(t, "fr", LocaleFromContext(ctx))
+	assert.Equal(t, "Bonjour Ana", FromContext(ctx).T("greeting", "Ana"))
+}
+
+func TestLocalizer_Nil(t *testing.T) {
+	l := FromContext(t.Context())
+
+	assert.Empty(t, LocaleFromContext(t.Context()))
+	assert.Equal(t, "order.created", l.T("order.created"))
+}
@@ -0,0 +1,77 @@
+package i18n
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseAcceptLanguage returns the locales of an Accept-Language header, by decreasing preference, e.g.
+// ["fr-CH", "fr", "en"] for "fr-CH, fr;q=0.9, en;q=0.8, *;q=0.5". The wildcard and the locales with q=0 are ignored.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+
+	var locales []weighted
+
+	for _, part := range strings.Split(header, ",") {
+		locale, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+
+		locale = strings.TrimSpace(locale)
+		if locale == "" || locale == "*" {
+			continue
+		}
+
+		q := 1.0
+
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+
+			q = parsed
+		}
+
+		if q > 0 {
+			locales = append(locales, weighted{locale: canonical(locale), q: q})
+		}
+	}
+
+	sort.SliceStable(locales, func(i, j int) bool { return locales[i].q > locales[j].q })
+
+	result := make([]string, len(locales))
+	for i, l := range locales {
+		result[i] = l.locale
+	}
+
+	return result
+}
+
+// Match returns the first of the candidates which is supported, or whose base language is, e.g. "pt" for "pt-BR".
+// It returns an empty string when none of them is supported.
+func Match(supported []string, candidates ...string) string {
+	locales := make([]string, len(supported))
+	for i, locale := range supported {
+		locales[i] = canonical(locale)
+	}
+
+	for _, candidate := range candidates {
+		if candidate = canonical(candidate); candidate == "" {
+			continue
+		}
+
+		if slices.Contains(locales, candidate) {
+			return candidate
+		}
+
+		if base, _, found := strings.Cut(candidate, "-"); found && slices.Contains(locales, base) {
+			return base
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,42 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	testCases := []struct {
+		header   string
+		expected []string
+	}{
+		{"", []string{}},
+		{"fr-CH, fr;q=0.9, en;q=0.8, *;q=0.5", []string{"fr-CH", "fr", "en"}},
+		{"en;q=0.5, de", []string{"de", "en"}},
+		{"en;q=0, de;q=invalid, zh_cn", []string{"zh-CN"}},
+	}
+
+	for i, tc := range testCases {
+		assert.Equal(t, tc.expected, ParseAcceptLanguage(tc.header), "TEST[%d], Failed.\n%s", i, tc.header)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	supported := []string{"en", "pt", "pt-BR"}
+
+	testCases := []struct {
+		desc       string
+		candidates []string
+		expected   string
+	}{
+		{"exact locale", []string{"pt-br"}, "pt-BR"},
+		{"base language", []string{"pt-PT"}, "pt"},
+		{"first supported candidate", []string{"", "de", "en-US"}, "en"},
+		{"no supported candidate", []string{"de"}, ""},
+	}
+
+	for i, tc := range testCases {
+		assert.Equal(t, tc.expected, Match(supported, tc.candidates...), "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
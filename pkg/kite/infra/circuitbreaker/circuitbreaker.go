@@ -0,0 +1,296 @@
+// Package circuitbreaker provides a circuit breaker shared by the HTTP service clients and the datasources of an
+// application, so that an outage of a dependency fails fast instead of piling up requests in its callers.
+//
+// A breaker counts the successes and failures of the calls in a rolling window. It opens when the ratio of failures
+// reaches FailureRatio, rejecting all calls with ErrOpen. After OpenTimeout it lets HalfOpenRequests probe calls
+// through: the breaker closes if they all succeed and opens again on the first failure.
+//
+//	breaker := app.Container().CircuitBreaker("payments-db")
+//
+//	err := breaker.Execute(ctx, func(ctx context.Context) error {
+//		_, err := db.ExecContext(ctx, query, args...)
+//		return err
+//	})
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned for the calls rejected while the breaker is open.
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State is the state of a breaker.
+type State int
+
+const (
+	// Closed lets all the calls through.
+	Closed State = iota
+	// Open rejects all the calls.
+	Open
+	// HalfOpen lets a limited number of probe calls through.
+	HalfOpen
+)
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+const (
+	defaultWindow           = 10 * time.Second
+	defaultBuckets          = 10
+	defaultMinRequests      = 20
+	defaultFailureRatio     = 0.5
+	defaultOpenTimeout      = 30 * time.Second
+	defaultHalfOpenRequests = 1
+)
+
+// Config holds the configuration of a breaker. Zero values are replaced with the defaults.
+type Config struct {
+	// Name identifies the breaker in the logs and metrics.
+	Name string
+	// Window is the duration of the rolling window in which calls are counted. Defaults to 10 seconds.
+	Window time.Duration
+	// Buckets is the number of buckets the window is divided into, older buckets are dropped as time passes.
+	// Defaults to 10.
+	Buckets int
+	// MinRequests is the number of calls in the window below which the breaker does not open. Defaults to 20.
+	MinRequests int
+	// FailureRatio is the ratio of failed calls in the window at which the breaker opens. Defaults to 0.5.
+	FailureRatio float64
+	// OpenTimeout is how long the breaker stays open before letting probe calls through. Defaults to 30 seconds.
+	OpenTimeout time.Duration
+	// HalfOpenRequests is the number of probe calls which must succeed to close the breaker. Defaults to 1.
+	HalfOpenRequests int
+	// IsFailure reports whether the error of a call counts as a failure. Defaults to any non-nil error, it can
+	// be set to ignore the errors which do not tell about the health of the dependency, e.g. sql.ErrNoRows.
+	IsFailure func(err error) bool
+}
+
+func (c Config) withDefaults() Config {
+	if c.Window <= 0 {
+		c.Window = defaultWindow
+	}
+
+	if c.Buckets <= 0 {
+		c.Buckets = defaultBuckets
+	}
+
+	if c.MinRequests <= 0 {
+		c.MinRequests = defaultMinRequests
+	}
+
+	if c.FailureRatio <= 0 || c.FailureRatio > 1 {
+		c.FailureRatio = defaultFailureRatio
+	}
+
+	if c.OpenTimeout <= 0 {
+		c.OpenTimeout = defaultOpenTimeout
+	}
+
+	if c.HalfOpenRequests <= 0 {
+		c.HalfOpenRequests = defaultHalfOpenRequests
+	}
+
+	if c.IsFailure == nil {
+		c.IsFailure = func(err error) bool { return err != nil }
+	}
+
+	return c
+}
+
+// Logger is used to log the state changes of the breakers.
+type Logger interface {
+	Infof(format string, args ...any)
+	Warnf(format string, args ...any)
+}
+
+// Metrics is used to record the state of the breakers and the calls they reject.
+type Metrics interface {
+	SetGauge(name string, value float64, labels ...string)
+	IncrementCounter(ctx context.Context, name string, labels ...string)
+}
+
+// Breaker is a circuit breaker. It is safe for concurrent use.
+type Breaker struct {
+	config  Config
+	logger  Logger
+	metrics Metrics
+	now     func() time.Time
+
+	mu         sync.Mutex
+	state      State
+	generation uint64
+	window     *window
+	openedAt   time.Time
+	probes     int
+	successes  int
+}
+
+// New returns a closed breaker. The logger and metrics may be nil.
+func New(config Config, logger Logger, metrics Metrics) *Breaker {
+	config = config.withDefaults()
+
+	b := &Breaker{
+		config:  config,
+		logger:  logger,
+		metrics: metrics,
+		now:     time.Now,
+		window:  newWindow(config.Window, config.Buckets),
+	}
+
+	if metrics != nil {
+		metrics.SetGauge("app_circuit_breaker_state", float64(Closed), "name", config.Name)
+	}
+
+	return b
+}
+
+// Name returns the name of the breaker.
+func (b *Breaker) Name() string {
+	return b.config.Name
+}
+
+// State returns the current state of the breaker.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.checkOpenTimeout()
+
+	return b.state
+}
+
+// IsFailure reports whether err counts as a failure for the breaker.
+func (b *Breaker) IsFailure(err error) bool {
+	return b.config.IsFailure(err)
+}
+
+// Execute runs fn if the breaker lets the call through, and records its result. It returns ErrOpen without
+// running fn if the breaker is open.
+func (b *Breaker) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
+	done, err := b.Allow(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = fn(ctx)
+	done(err)
+
+	return err
+}
+
+// Allow reports whether a call can be made, for callers which cannot wrap the call in Execute. If the call is
+// allowed, done must be called with its error once it completes; otherwise Allow returns ErrOpen.
+func (b *Breaker) Allow(ctx context.Context) (done func(err error), err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.checkOpenTimeout()
+
+	switch b.state {
+	case Open:
+		b.reject(ctx)
+
+		return nil, ErrOpen
+	case HalfOpen:
+		if b.probes >= b.config.HalfOpenRequests {
+			b.reject(ctx)
+
+			return nil, ErrOpen
+		}
+
+		b.probes++
+	case Closed:
+	}
+
+	generation := b.generation
+
+	return func(err error) { b.record(generation, b.config.IsFailure(err)) }, nil
+}
+
+func (b *Breaker) record(generation uint64, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// the result of a call made before the last state change does not tell about the current state.
+	if generation != b.generation {
+		return
+	}
+
+	switch b.state {
+	case Closed:
+		b.window.add(b.now(), failed)
+
+		total, failures := b.window.counts(b.now())
+		if total >= b.config.MinRequests && float64(failures)/float64(total) >= b.config.FailureRatio {
+			b.setState(Open)
+		}
+	case HalfOpen:
+		if failed {
+			b.setState(Open)
+			return
+		}
+
+		b.successes++
+		if b.successes >= b.config.HalfOpenRequests {
+			b.setState(Closed)
+		}
+	case Open:
+	}
+}
+
+// checkOpenTimeout moves an open breaker to half-open once OpenTimeout has elapsed.
+func (b *Breaker) checkOpenTimeout() {
+	if b.state == Open && b.now().Sub(b.openedAt) >= b.config.OpenTimeout {
+		b.setState(HalfOpen)
+	}
+}
+
+func (b *Breaker) setState(state State) {
+	previous := b.state
+
+	b.state = state
+	b.generation++
+	b.probes = 0
+	b.successes = 0
+
+	switch state {
+	case Open:
+		b.openedAt = b.now()
+	case Closed:
+		b.window.reset()
+	case HalfOpen:
+	}
+
+	if b.logger != nil {
+		if state == Open {
+			b.logger.Warnf("circuit breaker %q changed from %v to %v", b.config.Name, previous, state)
+		} else {
+			b.logger.Infof("circuit breaker %q changed from %v to %v", b.config.Name, previous, state)
+		}
+	}
+
+	if b.metrics != nil {
+		b.metrics.SetGauge("app_circuit_breaker_state", float64(state), "name", b.config.Name)
+	}
+}
+
+func (b *Breaker) reject(ctx context.Context) {
+	if b.metrics != nil {
+		b.metrics.IncrementCounter(ctx, "app_circuit_breaker_rejected_total", "name", b.config.Name)
+	}
+}
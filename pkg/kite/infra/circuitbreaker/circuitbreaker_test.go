@@ -0,0 +1,184 @@
+package circuitbreaker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errDown = errors.New("connection refused")
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+type testLogger struct {
+	logs []string
+}
+
+func (l *testLogger) Infof(format string, args ...any) {
+	l.logs = append(l.logs, fmt.Sprintf(format, args...))
+}
+
+func (l *testLogger) Warnf(format string, args ...any) {
+	l.logs = append(l.logs, fmt.Sprintf(format, args...))
+}
+
+type testMetrics struct {
+	state    float64
+	rejected int
+}
+
+func (m *testMetrics) SetGauge(_ string, value float64, _ ...string) {
+	m.state = value
+}
+
+func (m *testMetrics) IncrementCounter(context.Context, string, ...string) {
+	m.rejected++
+}
+
+func newTestBreaker(config Config) (*Breaker, *testClock, *testLogger, *testMetrics) {
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	logger := &testLogger{}
+	metrics := &testMetrics{}
+
+	b := New(config, logger, metrics)
+	b.now = clock.Now
+
+	return b, clock, logger, metrics
+}
+
+func call(b *Breaker, err error) error {
+	return b.Execute(context.Background(), func(context.Context) error { return err })
+}
+
+func TestBreaker_Lifecycle(t *testing.T) {
+	b, clock, logger, metrics := newTestBreaker(Config{
+		Name: "payments", MinRequests: 4, FailureRatio: 0.5, OpenTimeout: time.Minute, HalfOpenRequests: 2,
+	})
+
+	// failures below MinRequests do not open the breaker.
+	require.ErrorIs(t, call(b, errDown), errDown)
+	require.ErrorIs(t, call(b, errDown), errDown)
+	require.NoError(t, call(b, nil))
+	assert.Equal(t, Closed, b.State())
+
+	require.ErrorIs(t, call(b, errDown), errDown)
+	assert.Equal(t, Open, b.State())
+	assert.InDelta(t, float64(Open), metrics.state, 0)
+
+	// calls are rejected without running while the breaker is open.
+	ran := false
+	err := b.Execute(context.Background(), func(context.Context) error { ran = true; return nil })
+
+	require.ErrorIs(t, err, ErrOpen)
+	assert.False(t, ran)
+	assert.Equal(t, 1, metrics.rejected)
+
+	// after OpenTimeout, HalfOpenRequests probes are let through.
+	clock.Advance(time.Minute)
+	assert.Equal(t, HalfOpen, b.State())
+
+	done1, err := b.Allow(context.Background())
+	require.NoError(t, err)
+
+	done2, err := b.Allow(context.Background())
+	require.NoError(t, err)
+
+	_, err = b.Allow(context.Background())
+	require.ErrorIs(t, err, ErrOpen)
+
+	done1(nil)
+	assert.Equal(t, HalfOpen, b.State())
+
+	done2(nil)
+	assert.Equal(t, Closed, b.State())
+	assert.InDelta(t, float64(Closed), metrics.state, 0)
+
+	assert.Equal(t, []string{
+		`circuit breaker "payments" changed from closed to open`,
+		`circuit breaker "payments" changed from open to half-open`,
+		`circuit breaker "payments" changed from half-open to closed`,
+	}, logger.logs)
+}
+
+func TestBreaker_HalfOpenFailure(t *testing.T) {
+	b, clock, _, _ := newTestBreaker(Config{MinRequests: 1, OpenTimeout: time.Second})
+
+	require.Error(t, call(b, errDown))
+	assert.Equal(t, Open, b.State())
+
+	clock.Advance(time.Second)
+
+	require.Error(t, call(b, errDown))
+	assert.Equal(t, Open, b.State())
+
+	require.ErrorIs(t, call(b, nil), ErrOpen)
+}
+
+func TestBreaker_RollingWindow(t *testing.T) {
+	b, clock, _, _ := newTestBreaker(Config{Window: 10 * time.Second, Buckets: 10, MinRequests: 4})
+
+	require.Error(t, call(b, errDown))
+	require.Error(t, call(b, errDown))
+	require.Error(t, call(b, errDown))
+
+	// the failures fall out of the window.
+	clock.Advance(11 * time.Second)
+
+	require.Error(t, call(b, errDown))
+	assert.Equal(t, Closed, b.State())
+
+	require.NoError(t, call(b, nil))
+	require.NoError(t, call(b, nil))
+	require.Error(t, call(b, errDown))
+	assert.Equal(t, Open, b.State(), "2 failures out of 4 calls reach the failure ratio")
+}
+
+func TestBreaker_IsFailure(t *testing.T) {
+	b, _, _, _ := newTestBreaker(Config{
+		MinRequests: 1,
+		IsFailure:   func(err error) bool { return err != nil && !errors.Is(err, sql.ErrNoRows) },
+	})
+
+	require.ErrorIs(t, call(b, sql.ErrNoRows), sql.ErrNoRows)
+	assert.Equal(t, Closed, b.State())
+	assert.False(t, b.IsFailure(sql.ErrNoRows))
+}
+
+func TestBreaker_StaleResults(t *testing.T) {
+	b, clock, _, _ := newTestBreaker(Config{MinRequests: 1, OpenTimeout: time.Second})
+
+	// a call started while closed completes after the breaker opened and reached half-open.
+	slow, err := b.Allow(context.Background())
+	require.NoError(t, err)
+
+	require.Error(t, call(b, errDown))
+
+	clock.Advance(time.Second)
+	assert.Equal(t, HalfOpen, b.State())
+
+	slow(nil)
+	assert.Equal(t, HalfOpen, b.State())
+}
+
+func TestState_String(t *testing.T) {
+	assert.Equal(t, "closed", Closed.String())
+	assert.Equal(t, "open", Open.String())
+	assert.Equal(t, "half-open", HalfOpen.String())
+	assert.Equal(t, "unknown", State(7).String())
+}
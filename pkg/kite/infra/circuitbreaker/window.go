@@ -0,0 +1,60 @@
+package circuitbreaker
+
+import "time"
+
+// window counts the calls of the last duration in a ring of buckets. Each bucket covers duration/len(buckets)
+// and is reused once it falls out of the window.
+type window struct {
+	width   time.Duration
+	buckets []bucket
+}
+
+type bucket struct {
+	// epoch is the index of the period of the bucket since the Unix epoch, used to drop stale counts.
+	epoch     int64
+	successes int
+	failures  int
+}
+
+func newWindow(duration time.Duration, buckets int) *window {
+	width := duration / time.Duration(buckets)
+	if width <= 0 {
+		width = time.Nanosecond
+	}
+
+	return &window{width: width, buckets: make([]bucket, buckets)}
+}
+
+func (w *window) add(now time.Time, failed bool) {
+	epoch := now.UnixNano() / int64(w.width)
+	b := &w.buckets[epoch%int64(len(w.buckets))]
+
+	if b.epoch != epoch {
+		*b = bucket{epoch: epoch}
+	}
+
+	if failed {
+		b.failures++
+	} else {
+		b.successes++
+	}
+}
+
+func (w *window) counts(now time.Time) (total, failures int) {
+	epoch := now.UnixNano() / int64(w.width)
+
+	for _, b := range w.buckets {
+		if epoch-b.epoch < int64(len(w.buckets)) {
+			total += b.successes + b.failures
+			failures += b.failures
+		}
+	}
+
+	return total, failures
+}
+
+func (w *window) reset() {
+	for i := range w.buckets {
+		w.buckets[i] = bucket{}
+	}
+}
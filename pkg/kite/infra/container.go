@@ -15,13 +15,16 @@ package infra
 import (
 	"context"
 	"errors"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql" // This is required to be blank import
 
 	"github.com/sllt/kite/pkg/kite/config"
+	"github.com/sllt/kite/pkg/kite/datasource"
 	"github.com/sllt/kite/pkg/kite/datasource/file"
 	"github.com/sllt/kite/pkg/kite/datasource/pubsub"
 	"github.com/sllt/kite/pkg/kite/datasource/pubsub/google"
@@ -29,16 +32,23 @@ import (
 	"github.com/sllt/kite/pkg/kite/datasource/pubsub/mqtt"
 	"github.com/sllt/kite/pkg/kite/datasource/redis"
 	"github.com/sllt/kite/pkg/kite/datasource/sql"
+	"github.com/sllt/kite/pkg/kite/infra/circuitbreaker"
+	"github.com/sllt/kite/pkg/kite/infra/events"
+	"github.com/sllt/kite/pkg/kite/infra/grpcpool"
+	"github.com/sllt/kite/pkg/kite/infra/tasks"
 	"github.com/sllt/kite/pkg/kite/logging"
 	"github.com/sllt/kite/pkg/kite/logging/remotelogger"
 	"github.com/sllt/kite/pkg/kite/metrics"
 	"github.com/sllt/kite/pkg/kite/metrics/exporters"
 	"github.com/sllt/kite/pkg/kite/service"
+	"github.com/sllt/kite/pkg/kite/tenant"
 	"github.com/sllt/kite/pkg/kite/version"
 	"github.com/sllt/kite/pkg/kite/websocket"
 )
 
 const (
+	defaultLogBufferSize = 1024
+
 	redisPubSubModeStreams = "streams"
 	redisPubSubModePubSub  = "pubsub"
 )
@@ -60,6 +70,11 @@ type Container struct {
 	Redis Redis
 	SQL   DB
 
+	// namedSQL holds the additional SQL connections, keyed by connection name.
+	namedSQL map[string]DB
+	// tenantSQL opens the SQL connections of the tenants, it is nil when the app does not route them per tenant.
+	tenantSQL *tenant.Pool[DB]
+
 	Cassandra     CassandraWithContext
 	Clickhouse    Clickhouse
 	Mongo         Mongo
@@ -77,6 +92,21 @@ type Container struct {
 	KVStore KVStore
 
 	File file.FileSystem
+
+	breakersMu sync.Mutex
+	breakers   map[string]*circuitbreaker.Breaker
+
+	grpcPoolsMu sync.Mutex
+	grpcPools   map[string]*grpcpool.Pool
+
+	eventsOnce sync.Once
+	events     *events.Bus
+
+	tasksOnce sync.Once
+	tasks     tasks.Store
+
+	healthChecksMu sync.RWMutex
+	healthChecks   map[string]func(context.Context) datasource.Health
 }
 
 func NewContainer(conf config.Config) *Container {
@@ -123,6 +153,12 @@ func (c *Container) Create(conf config.Config) {
 		cc.SetShowCaller(showCaller != "false")
 	}
 
+	if rc, ok := c.Logger.(logging.RedactorConfigurer); ok {
+		rc.SetRedactor(c.newRedactor(conf))
+	}
+
+	c.setLogFormat(conf)
+
 	c.Logger.Debug("Container is being created")
 
 	c.metricsManager = metrics.NewMetricsManager(exporters.Prometheus(c.GetAppName(), c.GetAppVersion()), c.Logger)
@@ -132,6 +168,8 @@ func (c *Container) Create(conf config.Config) {
 	// Register framework metrics
 	c.registerFrameworkMetrics()
 
+	c.setAsyncLogging(conf)
+
 	// Populating an instance of app_info with the app details, the value is set as 1 to depict the no. of instances
 	c.Metrics().SetGauge("app_info", 1,
 		"app_name", c.GetAppName(), "app_version", c.GetAppVersion(), "framework_version", version.Framework)
@@ -140,6 +178,10 @@ func (c *Container) Create(conf config.Config) {
 
 	c.SQL = sql.NewSQL(conf, c.Logger, c.metricsManager)
 
+	if secrets, ok := conf.(*config.SecretConfig); ok {
+		c.updateCredentialsOnRotation(secrets)
+	}
+
 	c.createPubSub(conf)
 
 	c.File = file.NewLocalFileSystem(c.Logger)
@@ -147,6 +189,70 @@ func (c *Container) Create(conf config.Config) {
 	c.WSManager = websocket.New()
 }
 
+// setAsyncLogging makes the logger write its logs from a background goroutine when LOG_ASYNC is true, buffering up
+// to LOG_BUFFER_SIZE entries. LOG_OVERFLOW_POLICY decides whether the oldest entries are dropped, the default, or
+// logging waits when the buffer is full.
+func (c *Container) setAsyncLogging(conf config.Config) {
+	ac, ok := c.Logger.(logging.AsyncConfigurer)
+	if !ok {
+		return
+	}
+
+	if async, err := strconv.ParseBool(conf.GetOrDefault("LOG_ASYNC", "false")); err != nil || !async {
+		return
+	}
+
+	size, err := strconv.Atoi(conf.Get("LOG_BUFFER_SIZE"))
+	if err != nil || size <= 0 {
+		size = defaultLogBufferSize
+	}
+
+	ac.SetAsync(size, logging.GetOverflowPolicyFromString(conf.Get("LOG_OVERFLOW_POLICY")), func() {
+		c.Metrics().IncrementCounter(context.Background(), "app_logs_dropped_total")
+	})
+}
+
+// setLogFormat sets the format of the logs from LOG_FORMAT, preset by the environment. With no format, the logs are
+// pretty printed only when written to a terminal.
+func (c *Container) setLogFormat(conf config.Config) {
+	fc, ok := c.Logger.(logging.FormatConfigurer)
+	if !ok {
+		return
+	}
+
+	switch format := strings.ToLower(config.GetOrPreset(conf, "LOG_FORMAT", "")); format {
+	case "":
+	case logging.FormatText, logging.FormatJSON:
+		fc.SetFormat(format)
+	default:
+		c.Logger.Errorf("invalid value %q for LOG_FORMAT, use %q or %q", format, logging.FormatText, logging.FormatJSON)
+	}
+}
+
+// newRedactor returns the redactor masking sensitive data in the logs, or nil if LOG_REDACT is false.
+func (c *Container) newRedactor(conf config.Config) *logging.Redactor {
+	if strings.EqualFold(conf.GetOrDefault("LOG_REDACT", "true"), "false") {
+		return nil
+	}
+
+	redactor := logging.NewRedactor()
+
+	if fields := conf.Get("LOG_REDACT_FIELDS"); fields != "" {
+		redactor.RedactFields(strings.Split(fields, ",")...)
+	}
+
+	if pattern := conf.Get("LOG_REDACT_PATTERN"); pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			c.Logger.Errorf("invalid LOG_REDACT_PATTERN %q: %v", pattern, err)
+		} else {
+			redactor.RedactPatterns(re)
+		}
+	}
+
+	return redactor
+}
+
 func (c *Container) createPubSub(conf config.Config) {
 	switch strings.ToUpper(conf.Get("PUBSUB_BACKEND")) {
 	case "KAFKA":
@@ -167,6 +273,22 @@ func (c *Container) Close() error {
 		err = errors.Join(err, c.SQL.Close())
 	}
 
+	for _, db := range c.namedSQL {
+		err = errors.Join(err, db.Close())
+	}
+
+	if c.tenantSQL != nil {
+		err = errors.Join(err, c.tenantSQL.Close())
+	}
+
+	c.grpcPoolsMu.Lock()
+
+	for _, pool := range c.grpcPools {
+		err = errors.Join(err, pool.Close())
+	}
+
+	c.grpcPoolsMu.Unlock()
+
 	if !isNil(c.Redis) {
 		err = errors.Join(err, c.Redis.Close())
 	}
@@ -218,6 +340,7 @@ func (c *Container) createMqttPubSub(conf config.Config) pubsub.Client {
 		RetrieveRetained: retrieveRetained,
 		KeepAlive:        keepAlive,
 		CloseTimeout:     0 * time.Millisecond,
+		SharedGroup:      conf.Get("MQTT_SHARED_SUBSCRIPTION_GROUP"),
 	}
 
 	return mqtt.New(configs, c.Logger, c.metricsManager)
@@ -229,6 +352,169 @@ func (c *Container) GetHTTPService(serviceName string) service.HTTP {
 	return c.Services[serviceName]
 }
 
+// AddNamedSQL registers an additional SQL connection identified by name, replacing any connection
+// previously registered with the same name. Connections must be registered before the app starts.
+func (c *Container) AddNamedSQL(name string, db DB) {
+	if c.namedSQL == nil {
+		c.namedSQL = make(map[string]DB)
+	}
+
+	c.namedSQL[name] = db
+}
+
+// NamedSQL returns the SQL connection registered with the given name, or nil if there is none.
+// The default connection configured with the DB_* configs is available as SQL.
+func (c *Container) NamedSQL(name string) DB {
+	return c.namedSQL[name]
+}
+
+// UseTenantSQL routes the SQL queries of the tenants to the connections opened by pool, which are closed with the
+// container. It must be called before the app starts.
+func (c *Container) UseTenantSQL(pool *tenant.Pool[DB]) {
+	c.tenantSQL = pool
+}
+
+// TenantSQL returns the SQL connection of the tenant id, opening it on its first use. It returns the default SQL
+// connection when the connections are not routed per tenant, e.g. for tenants sharing tables with a tenant column.
+// The connection is closed as soon as it is evicted from the pool, see AcquireTenantSQL to hold it.
+func (c *Container) TenantSQL(id string) (DB, error) {
+	if id == "" {
+		return nil, tenant.ErrNoTenant
+	}
+
+	if c.tenantSQL == nil {
+		return c.SQL, nil
+	}
+
+	return c.tenantSQL.Get(id)
+}
+
+// AcquireTenantSQL returns the SQL connection of the tenant id like TenantSQL, and holds it until release is called, so
+// that it is not closed meanwhile when the connections of other tenants evict it from the pool. release must be
+// called once.
+func (c *Container) AcquireTenantSQL(id string) (db DB, release func(), err error) {
+	if id == "" {
+		return nil, nil, tenant.ErrNoTenant
+	}
+
+	if c.tenantSQL == nil {
+		return c.SQL, func() {}, nil
+	}
+
+	return c.tenantSQL.Acquire(id)
+}
+
+// CircuitBreaker returns the circuit breaker registered with name, creating it with the default configuration if
+// there is none. Breakers are shared, so that all the callers of a dependency stop calling it during an outage.
+func (c *Container) CircuitBreaker(name string) *circuitbreaker.Breaker {
+	c.breakersMu.Lock()
+	defer c.breakersMu.Unlock()
+
+	if b, ok := c.breakers[name]; ok {
+		return b
+	}
+
+	return c.addCircuitBreaker(circuitbreaker.Config{Name: name})
+}
+
+// AddCircuitBreaker registers a circuit breaker with the given configuration, replacing any breaker previously
+// registered with the same name.
+func (c *Container) AddCircuitBreaker(config circuitbreaker.Config) *circuitbreaker.Breaker {
+	c.breakersMu.Lock()
+	defer c.breakersMu.Unlock()
+
+	return c.addCircuitBreaker(config)
+}
+
+func (c *Container) addCircuitBreaker(config circuitbreaker.Config) *circuitbreaker.Breaker {
+	if c.breakers == nil {
+		c.breakers = make(map[string]*circuitbreaker.Breaker)
+	}
+
+	b := circuitbreaker.New(config, c.Logger, c.metricsManager)
+	c.breakers[config.Name] = b
+
+	return b
+}
+
+// GRPCClientPool returns the pool of gRPC client connections to target, creating it with the default configuration if
+// there is none. Pools are shared, so that all the clients of a service spread their calls over the same connections.
+func (c *Container) GRPCClientPool(target string) (*grpcpool.Pool, error) {
+	c.grpcPoolsMu.Lock()
+	defer c.grpcPoolsMu.Unlock()
+
+	if pool, ok := c.grpcPools[target]; ok {
+		return pool, nil
+	}
+
+	return c.addGRPCClientPool(grpcpool.Config{Target: target})
+}
+
+// AddGRPCClientPool registers a pool of gRPC client connections with the given configuration, replacing and closing
+// any pool previously registered for the same target.
+func (c *Container) AddGRPCClientPool(config grpcpool.Config) (*grpcpool.Pool, error) {
+	c.grpcPoolsMu.Lock()
+	defer c.grpcPoolsMu.Unlock()
+
+	previous := c.grpcPools[config.Target]
+
+	pool, err := c.addGRPCClientPool(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if previous != nil {
+		_ = previous.Close()
+	}
+
+	return pool, nil
+}
+
+func (c *Container) addGRPCClientPool(config grpcpool.Config) (*grpcpool.Pool, error) {
+	pool, err := grpcpool.New(config, c.Logger, c.metricsManager)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.grpcPools == nil {
+		c.grpcPools = make(map[string]*grpcpool.Pool)
+	}
+
+	c.grpcPools[config.Target] = pool
+
+	return pool, nil
+}
+
+// Events returns the in-process event bus of the application, which dispatches the typed events published by its
+// modules to their subscribers, and on which requests wait for events, see Context.WaitForEvent.
+func (c *Container) Events() *events.Bus {
+	c.eventsOnce.Do(func() {
+		c.events = events.NewBus(c.Logger)
+	})
+
+	return c.events
+}
+
+// Tasks returns the store of the one-shot tasks scheduled by the application: the Redis datasource if it is
+// configured, else the SQL one, on the kite_scheduled_tasks table, else an in-memory store which does not
+// survive restarts.
+func (c *Container) Tasks() tasks.Store {
+	c.tasksOnce.Do(func() {
+		switch {
+		case !isNil(c.Redis):
+			c.tasks = tasks.NewRedisStore(c.Redis)
+		case !isNil(c.SQL):
+			c.tasks = tasks.NewSQLStore(c.SQL)
+		default:
+			c.Logger.Warn("no Redis or SQL datasource to persist the scheduled tasks, they do not survive restarts")
+
+			c.tasks = tasks.NewMemoryStore()
+		}
+	})
+
+	return c.tasks
+}
+
 func (c *Container) Metrics() metrics.Manager {
 	return c.metricsManager
 }
@@ -241,18 +527,41 @@ func (c *Container) registerFrameworkMetrics() {
 	c.Metrics().NewGauge("app_sys_total_alloc", "Number of cumulative bytes allocated for heap objects.")
 	c.Metrics().NewGauge("app_go_numGC", "Number of completed Garbage Collector cycles.")
 	c.Metrics().NewGauge("app_go_sys", "Number of total bytes of memory.")
+	c.Metrics().NewCounter("app_logs_dropped_total", "Number of log entries dropped as the asynchronous log buffer was full.")
 
 	{ // HTTP metrics
 		httpBuckets := []float64{.001, .003, .005, .01, .02, .03, .05, .1, .2, .3, .5, .75, 1, 2, 3, 5, 10, 30}
 		c.Metrics().NewHistogram("app_http_response", "Response time of HTTP requests in seconds.", httpBuckets...)
 		c.Metrics().NewHistogram("app_http_service_response", "Response time of HTTP service requests in seconds.", httpBuckets...)
+		c.Metrics().NewHistogram("app_http_response_bytes", "Size of the HTTP response bodies written in bytes.",
+			256, 1024, 4096, 16384, 65536, 262144, 1048576, 4194304, 16777216, 67108864)
 		c.Metrics().NewCounter("app_http_retry_count", "Total number of retry events")
 		c.Metrics().NewGauge("app_http_circuit_breaker_state", "Current state of the circuit breaker (0 for Closed, 1 for Open)")
+		c.Metrics().NewCounter("app_http_api_version_requests_total", "Number of HTTP requests served per API version.")
+		c.Metrics().NewGauge("app_http_requests_in_flight", "Number of HTTP requests in flight per concurrency limit.")
+		c.Metrics().NewCounter("app_http_requests_shed_total", "Number of HTTP requests shed by the concurrency limits.")
+		c.Metrics().NewGauge("app_http_priority_requests_in_flight", "Number of HTTP requests in flight per priority class.")
+		c.Metrics().NewGauge("app_http_priority_requests_queued", "Number of HTTP requests waiting for a slot per priority class.")
+		c.Metrics().NewCounter("app_http_priority_requests_shed_total", "Number of HTTP requests shed per priority class.")
+		c.Metrics().NewHistogram("app_http_priority_queue_wait", "Time HTTP requests waited for a slot of their priority class in seconds.",
+			httpBuckets...)
+		c.Metrics().NewCounter("app_http_tenant_requests_total", "Number of HTTP requests served per tenant.")
+		c.Metrics().NewCounter("app_http_shadow_requests_total", "Number of HTTP requests mirrored to the shadow, by status.")
+		c.Metrics().NewCounter("app_http_shadow_requests_dropped_total", "Number of sampled HTTP requests not mirrored to the shadow.")
+	}
+
+	{ // WebSocket client metrics
+		wsBuckets := []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+		c.Metrics().NewHistogram("app_ws_client_dial_duration_seconds", "Duration of the WebSocket client handshakes in seconds.", wsBuckets...)
+		c.Metrics().NewCounter("app_ws_client_messages_total", "Number of messages sent and received by WebSocket clients.")
+		c.Metrics().NewCounter("app_ws_client_reconnects_total", "Number of reconnections of WebSocket clients.")
 	}
 
 	{ // Redis metrics
 		redisBuckets := getDefaultDatasourceBuckets()
 		c.Metrics().NewHistogram("app_redis_stats", "Response time of Redis commands in milliseconds.", redisBuckets...)
+		c.Metrics().NewHistogram("app_redis_node_stats", "Response time of Redis commands per cluster node in milliseconds.",
+			redisBuckets...)
 	}
 
 	{ // SQL metrics
@@ -260,13 +569,34 @@ func (c *Container) registerFrameworkMetrics() {
 		c.Metrics().NewHistogram("app_sql_stats", "Response time of SQL queries in milliseconds.", sqlBuckets...)
 		c.Metrics().NewGauge("app_sql_open_connections", "Number of open SQL connections.")
 		c.Metrics().NewGauge("app_sql_inUse_connections", "Number of inUse SQL connections.")
+		c.Metrics().NewCounter(sql.MetricFingerprintQueries, "Number of SQL queries per query fingerprint.")
+		c.Metrics().NewHistogram(sql.MetricFingerprintDuration,
+			"Response time of SQL queries per query fingerprint in milliseconds.", sqlBuckets...)
 	}
 
+	// circuit breaker metrics
+	c.Metrics().NewGauge("app_circuit_breaker_state", "Current state of the circuit breakers (0 closed, 1 open, 2 half-open).")
+	c.Metrics().NewCounter("app_circuit_breaker_rejected_total", "Number of calls rejected by open circuit breakers.")
+
+	// synthetic check metrics
+	c.Metrics().NewGauge("app_synthetic_check_up", "Result of the last run of the synthetic checks (1 passed, 0 failed).")
+	c.Metrics().NewCounter("app_synthetic_check_failures_total", "Number of failed runs of the synthetic checks.")
+	c.Metrics().NewHistogram("app_synthetic_check_duration_seconds", "Duration of the runs of the synthetic checks in seconds.",
+		.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10)
+
+	// gRPC client pool metrics
+	c.Metrics().NewGauge(grpcpool.HealthyConnectionsMetric, "Number of healthy connections of the gRPC client pools.")
+	c.Metrics().NewCounter(grpcpool.EvictionsMetric, "Number of unhealthy connections replaced by the gRPC client pools.")
+
+	// standard metrics of all the datasources
+	datasource.RegisterMetrics(c.Metrics())
+
 	// pubsub metrics
 	c.Metrics().NewCounter("app_pubsub_publish_total_count", "Number of total publish operations.")
 	c.Metrics().NewCounter("app_pubsub_publish_success_count", "Number of successful publish operations.")
 	c.Metrics().NewCounter("app_pubsub_subscribe_total_count", "Number of total subscribe operations.")
 	c.Metrics().NewCounter("app_pubsub_subscribe_success_count", "Number of successful subscribe operations.")
+	c.Metrics().NewCounter("app_pubsub_redis_claimed_total", "Number of pending Redis Streams messages claimed from other consumers.")
 }
 
 func (c *Container) GetAppName() string {
@@ -323,10 +653,7 @@ func (c *Container) RemoveConnection(connID string) {
 // Covers 0-30s range to align with typical request timeout boundaries and provide consistent observability
 // across SQL, Redis, MongoDB, Cassandra, and other datasources.
 func getDefaultDatasourceBuckets() []float64 {
-	return []float64{
-		.05, .075, .1, .125, .15, .2, .3, .5, .75, 1, 2, 3, 5, 7.5, 10, // 0-10ms: fast operations
-		25, 50, 100, 250, 500, 1000, 5000, 10000, 30000, // 10ms-30s: slower operations
-	}
+	return datasource.DurationBuckets()
 }
 
 func (c *Container) createKafkaPubSub(conf config.Config) {
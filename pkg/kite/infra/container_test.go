@@ -15,8 +15,12 @@ import (
 	"github.com/sllt/kite/pkg/kite/datasource/pubsub/mqtt"
 	kiteRedis "github.com/sllt/kite/pkg/kite/datasource/redis"
 	kiteSql "github.com/sllt/kite/pkg/kite/datasource/sql"
+	"github.com/sllt/kite/pkg/kite/infra/circuitbreaker"
+	"github.com/sllt/kite/pkg/kite/infra/grpcpool"
+	"github.com/sllt/kite/pkg/kite/infra/tasks"
 	"github.com/sllt/kite/pkg/kite/logging"
 	"github.com/sllt/kite/pkg/kite/service"
+	"github.com/sllt/kite/pkg/kite/tenant"
 	ws "github.com/sllt/kite/pkg/kite/websocket"
 )
 
@@ -48,7 +52,7 @@ func Test_newContainerDBInitializationFail(t *testing.T) {
 	// container is a pointer, and we need to see if db are not initialized, comparing the container object
 	// will not suffice the purpose of this test
 	require.Error(t, db.DB.PingContext(t.Context()), "TEST, Failed.\ninvalid db connections")
-	assert.NotNil(t, redis.Client, "TEST, Failed.\ninvalid redis connections")
+	assert.NotNil(t, redis.UniversalClient, "TEST, Failed.\ninvalid redis connections")
 }
 
 func Test_newContainerPubSubInitializationFail(t *testing.T) {
@@ -131,6 +135,86 @@ func TestContainer_GetAppVersion(t *testing.T) {
 	assert.Equal(t, "v0.1.0", out)
 }
 
+func TestContainer_CircuitBreaker(t *testing.T) {
+	c := &Container{}
+
+	b := c.CircuitBreaker("payments")
+
+	assert.Equal(t, "payments", b.Name())
+	assert.Same(t, b, c.CircuitBreaker("payments"), "breakers are shared by name")
+
+	replaced := c.AddCircuitBreaker(circuitbreaker.Config{Name: "payments", MinRequests: 5})
+
+	assert.NotSame(t, b, replaced)
+	assert.Same(t, replaced, c.CircuitBreaker("payments"))
+}
+
+func TestContainer_GRPCClientPool(t *testing.T) {
+	c := &Container{WSManager: ws.New()}
+
+	pool, err := c.GRPCClientPool("localhost:50051")
+	require.NoError(t, err)
+
+	assert.Equal(t, "localhost:50051", pool.Target())
+
+	shared, err := c.GRPCClientPool("localhost:50051")
+	require.NoError(t, err)
+	assert.Same(t, pool, shared, "pools are shared by target")
+
+	replaced, err := c.AddGRPCClientPool(grpcpool.Config{Target: "localhost:50051", Size: 2, HealthCheckInterval: -1})
+	require.NoError(t, err)
+
+	assert.NotSame(t, pool, replaced)
+	require.ErrorIs(t, pool.Invoke(t.Context(), "/grpc.health.v1.Health/Check", nil, nil), grpcpool.ErrClosed,
+		"the replaced pool is closed")
+
+	_, err = c.AddGRPCClientPool(grpcpool.Config{})
+	require.Error(t, err)
+
+	require.NoError(t, c.Close())
+	require.ErrorIs(t, replaced.Invoke(t.Context(), "/grpc.health.v1.Health/Check", nil, nil), grpcpool.ErrClosed)
+}
+
+func TestContainer_TenantSQL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	defaultDB, acmeDB := NewMockDB(ctrl), NewMockDB(ctrl)
+	c := &Container{SQL: defaultDB, WSManager: ws.New()}
+
+	_, err := c.TenantSQL("")
+	require.ErrorIs(t, err, tenant.ErrNoTenant)
+
+	db, err := c.TenantSQL("acme")
+	require.NoError(t, err)
+	assert.Same(t, defaultDB, db, "the default connection is shared when the tenants are not routed")
+
+	c.UseTenantSQL(tenant.NewPool(0, func(string) (DB, error) { return acmeDB, nil }, DB.Close))
+
+	db, err = c.TenantSQL("acme")
+	require.NoError(t, err)
+	assert.Same(t, acmeDB, db)
+
+	defaultDB.EXPECT().Close().Return(nil)
+	acmeDB.EXPECT().Close().Return(nil)
+
+	require.NoError(t, c.Close())
+}
+
+func TestContainer_Tasks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	logger := logging.NewMockLogger(logging.ERROR)
+
+	redisContainer := &Container{Logger: logger, Redis: NewMockRedis(ctrl), SQL: NewMockDB(ctrl)}
+	assert.IsType(t, &tasks.RedisStore{}, redisContainer.Tasks())
+	assert.Same(t, redisContainer.Tasks(), redisContainer.Tasks())
+
+	sqlContainer := &Container{Logger: logger, SQL: NewMockDB(ctrl)}
+	assert.IsType(t, &tasks.SQLStore{}, sqlContainer.Tasks())
+
+	memoryContainer := &Container{Logger: logger}
+	assert.IsType(t, tasks.NewMemoryStore(), memoryContainer.Tasks())
+}
+
 func TestContainer_GetPublisher(t *testing.T) {
 	publisher := &MockPubSub{}
 
@@ -462,13 +546,35 @@ func TestContainer_registerFrameworkMetrics_RegistersExpectedMetrics(t *testing.
 	}
 
 	mockMetrics.EXPECT().NewGauge("app_http_circuit_breaker_state", gomock.Any()).Times(1)
+	mockMetrics.EXPECT().NewGauge("app_http_requests_in_flight", gomock.Any()).Times(1)
+	mockMetrics.EXPECT().NewGauge("app_http_priority_requests_in_flight", gomock.Any()).Times(1)
+	mockMetrics.EXPECT().NewGauge("app_http_priority_requests_queued", gomock.Any()).Times(1)
+	mockMetrics.EXPECT().NewGauge("app_synthetic_check_up", gomock.Any()).Times(1)
+	mockMetrics.EXPECT().NewGauge("app_circuit_breaker_state", gomock.Any()).Times(1)
+	mockMetrics.EXPECT().NewGauge("app_grpc_client_pool_healthy_connections", gomock.Any()).Times(1)
 
 	counters := []string{
+		"app_logs_dropped_total",
 		"app_pubsub_publish_total_count",
 		"app_pubsub_publish_success_count",
 		"app_pubsub_subscribe_total_count",
 		"app_pubsub_subscribe_success_count",
+		"app_pubsub_redis_claimed_total",
 		"app_http_retry_count",
+		"app_http_api_version_requests_total",
+		"app_http_requests_shed_total",
+		"app_http_priority_requests_shed_total",
+		"app_http_tenant_requests_total",
+		"app_http_shadow_requests_total",
+		"app_http_shadow_requests_dropped_total",
+		"app_ws_client_messages_total",
+		"app_ws_client_reconnects_total",
+		"app_circuit_breaker_rejected_total",
+		"app_synthetic_check_failures_total",
+		"app_grpc_client_pool_evictions_total",
+		"app_datasource_operations_total",
+		"app_datasource_errors_total",
+		"app_sql_fingerprint_queries_total",
 	}
 	for _, counter := range counters {
 		mockMetrics.EXPECT().NewCounter(counter, gomock.Any()).Times(1)
@@ -476,6 +582,9 @@ func TestContainer_registerFrameworkMetrics_RegistersExpectedMetrics(t *testing.
 
 	httpBuckets := []float64{.001, .003, .005, .01, .02, .03, .05, .1, .2, .3, .5, .75, 1, 2, 3, 5, 10, 30}
 	dsBuckets := getDefaultDatasourceBuckets()
+	wsBuckets := []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+	syntheticBuckets := []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+	bytesBuckets := []float64{256, 1024, 4096, 16384, 65536, 262144, 1048576, 4194304, 16777216, 67108864}
 
 	histograms := []struct {
 		name    string
@@ -483,8 +592,15 @@ func TestContainer_registerFrameworkMetrics_RegistersExpectedMetrics(t *testing.
 	}{
 		{name: "app_http_response", buckets: httpBuckets},
 		{name: "app_http_service_response", buckets: httpBuckets},
+		{name: "app_http_response_bytes", buckets: bytesBuckets},
+		{name: "app_http_priority_queue_wait", buckets: httpBuckets},
+		{name: "app_ws_client_dial_duration_seconds", buckets: wsBuckets},
 		{name: "app_redis_stats", buckets: dsBuckets},
+		{name: "app_redis_node_stats", buckets: dsBuckets},
 		{name: "app_sql_stats", buckets: dsBuckets},
+		{name: "app_sql_fingerprint_duration", buckets: dsBuckets},
+		{name: "app_datasource_duration", buckets: dsBuckets},
+		{name: "app_synthetic_check_duration_seconds", buckets: syntheticBuckets},
 	}
 
 	for _, tc := range histograms {
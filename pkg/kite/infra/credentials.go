@@ -0,0 +1,43 @@
+package infra
+
+import (
+	"github.com/sllt/kite/pkg/kite/config"
+)
+
+// credentialsUpdater is implemented by the datasources whose credentials can be updated without reconnecting.
+type credentialsUpdater interface {
+	UpdateCredentials(user, password string) error
+}
+
+// credentialKeys are the configs holding the user and password of the datasources, keyed by any of them.
+var credentialKeys = map[string][2]string{
+	"DB_USER":        {"DB_USER", "DB_PASSWORD"},
+	"DB_PASSWORD":    {"DB_USER", "DB_PASSWORD"},
+	"REDIS_USER":     {"REDIS_USER", "REDIS_PASSWORD"},
+	"REDIS_PASSWORD": {"REDIS_USER", "REDIS_PASSWORD"},
+}
+
+// updateCredentialsOnRotation updates the credentials of the SQL and Redis connections when a secret referenced by
+// their configs rotates, so that the next connections they open use the new ones.
+func (c *Container) updateCredentialsOnRotation(conf *config.SecretConfig) {
+	conf.OnRotate(func(key string) {
+		keys, ok := credentialKeys[key]
+		if !ok {
+			return
+		}
+
+		var datasource any = c.Redis
+		if keys[0] == "DB_USER" {
+			datasource = c.SQL
+		}
+
+		updater, ok := datasource.(credentialsUpdater)
+		if !ok {
+			return
+		}
+
+		if err := updater.UpdateCredentials(conf.Get(keys[0]), conf.Get(keys[1])); err != nil {
+			c.Errorf("could not update the credentials after %s rotated: %v", key, err)
+		}
+	})
+}
@@ -185,6 +185,15 @@ type CassandraBatchWithContext interface {
 
 	// ExecuteBatchCASWithCtx executes a batch operation with context and returns the result.
 	ExecuteBatchCASWithCtx(ctx context.Context, name string, dest ...any) (bool, error)
+
+	// WriteBatchWithCtx executes the statement once for each of the rows of values, in batches of the batch type
+	// holding at most size statements, or the batch size of the client when size is not positive.
+	//
+	// Example:
+	//
+	//	rows := [][]any{{1, "John Doe"}, {2, "Jane Smith"}}
+	//	err := c.WriteBatchWithCtx(ctx, cassandra.UnloggedBatch, "INSERT INTO users (id, name) VALUES(?, ?)", rows, 100)
+	WriteBatchWithCtx(ctx context.Context, batchType int, stmt string, rows [][]any, size int) error
 }
 
 type CassandraProvider interface {
@@ -277,6 +286,10 @@ type Mongo interface {
 	// CreateCollection creates a new collection with specified name and default options.
 	CreateCollection(ctx context.Context, name string) error
 
+	// Aggregate runs an aggregation pipeline on a collection and stores the resulting documents
+	// into the provided results interface.
+	Aggregate(ctx context.Context, collection string, pipeline any, results any) error
+
 	// StartSession starts a session and provide methods to run commands in a transaction.
 	StartSession() (any, error)
 
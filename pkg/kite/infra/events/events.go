@@ -0,0 +1,156 @@
+// Package events provides an in-process event bus, which decouples the modules of a service: typed events are
+// published with Publish and dispatched to the handlers registered for their type with Subscribe, and requests
+// can wait for the events of a topic, e.g. the long-poll handlers waiting with ctx.WaitForEvent.
+//
+// Events are only delivered to the handlers and waiters registered when they are published, they are not buffered.
+// The bus of an application is returned by App.Events and Container.Events:
+//
+//	app.Events().Subscribe(func(ctx context.Context, e UserCreated) error {
+//		return sendWelcomeMail(ctx, e.Email)
+//	}, events.Async())
+//
+//	app.POST("/users", func(ctx *kite.Context) (any, error) {
+//		// ...
+//		return user, ctx.Events().Publish(ctx, UserCreated{ID: user.ID, Email: user.Email})
+//	})
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrTimeout is returned by Wait when no event is published on the topic before the timeout.
+	ErrTimeout = errors.New("no event received before the timeout")
+	// ErrClosed is returned by Publish once the bus is closed.
+	ErrClosed = errors.New("event bus is closed")
+)
+
+// Logger is the logger used by a Bus to report the failures of the asynchronous handlers.
+type Logger interface {
+	Errorf(format string, args ...any)
+}
+
+// Event is an event published on a topic of the bus.
+type Event struct {
+	Topic   string
+	Payload any
+}
+
+// Bus dispatches the events published on it to their subscribers, and delivers the events of a topic to the
+// waiters of the topic.
+type Bus struct {
+	logger Logger
+
+	mu      sync.Mutex
+	waiters map[string]map[chan Event]struct{}
+
+	subsMu sync.RWMutex
+	subs   []*subscription
+	closed bool
+	// async tracks the asynchronous handlers running, which Close waits for.
+	async sync.WaitGroup
+}
+
+// NewBus returns an empty bus, logging the failures of the asynchronous handlers with logger.
+func NewBus(logger Logger) *Bus {
+	return &Bus{logger: logger, waiters: make(map[string]map[chan Event]struct{})}
+}
+
+// Notify delivers an event with payload to all the current waiters of topic and returns their number.
+// Unlike Publish, it does not dispatch the event to the subscribers.
+func (b *Bus) Notify(topic string, payload any) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	waiters := b.waiters[topic]
+
+	for w := range waiters {
+		// the channel of a waiter has room for one event and is removed once it got it, so this never blocks.
+		w <- Event{Topic: topic, Payload: payload}
+	}
+
+	delete(b.waiters, topic)
+
+	return len(waiters)
+}
+
+// Wait blocks until an event is published on topic, the timeout elapses or ctx is done. It returns ErrTimeout
+// when the timeout elapses, and the error of ctx when it is done.
+func (b *Bus) Wait(ctx context.Context, topic string, timeout time.Duration) (Event, error) {
+	w := make(chan Event, 1)
+
+	b.mu.Lock()
+	if b.waiters[topic] == nil {
+		b.waiters[topic] = make(map[chan Event]struct{})
+	}
+
+	b.waiters[topic][w] = struct{}{}
+	b.mu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case e := <-w:
+		return e, nil
+	case <-timer.C:
+		return b.cancel(topic, w, ErrTimeout)
+	case <-ctx.Done():
+		return b.cancel(topic, w, ctx.Err())
+	}
+}
+
+// Waiting returns the number of waiters of topic.
+func (b *Bus) Waiting(topic string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.waiters[topic])
+}
+
+// cancel removes the waiter w of topic. The event published while it was giving up, if any, is returned instead
+// of err, so that it is not lost.
+func (b *Bus) cancel(topic string, w chan Event, err error) (Event, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	select {
+	case e := <-w:
+		return e, nil
+	default:
+	}
+
+	delete(b.waiters[topic], w)
+
+	if len(b.waiters[topic]) == 0 {
+		delete(b.waiters, topic)
+	}
+
+	return Event{}, err
+}
+
+// Close closes the bus, Publish fails afterward, and waits for the asynchronous handlers running to finish,
+// until ctx is done.
+func (b *Bus) Close(ctx context.Context) error {
+	b.subsMu.Lock()
+	b.closed = true
+	b.subsMu.Unlock()
+
+	done := make(chan struct{})
+
+	go func() {
+		b.async.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
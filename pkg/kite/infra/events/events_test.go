@@ -0,0 +1,89 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func waitForWaiters(t *testing.T, b *Bus, topic string, n int) {
+	t.Helper()
+
+	require.Eventually(t, func() bool { return b.Waiting(topic) == n }, time.Second, time.Millisecond)
+}
+
+func TestBus_NotifyDeliversToAllWaiters(t *testing.T) {
+	b := NewBus(nil)
+
+	var wg sync.WaitGroup
+
+	events := make(chan Event, 3)
+
+	for range 3 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			e, err := b.Wait(t.Context(), "orders", time.Minute)
+			assert.NoError(t, err)
+
+			events <- e
+		}()
+	}
+
+	waitForWaiters(t, b, "orders", 3)
+
+	assert.Equal(t, 0, b.Notify("payments", "ignored"))
+	assert.Equal(t, 3, b.Notify("orders", 42))
+
+	wg.Wait()
+	close(events)
+
+	for e := range events {
+		assert.Equal(t, Event{Topic: "orders", Payload: 42}, e)
+	}
+
+	assert.Equal(t, 0, b.Waiting("orders"))
+}
+
+func TestBus_EventsAreNotBuffered(t *testing.T) {
+	b := NewBus(nil)
+
+	assert.Equal(t, 0, b.Notify("orders", 1))
+
+	_, err := b.Wait(t.Context(), "orders", 10*time.Millisecond)
+	require.ErrorIs(t, err, ErrTimeout)
+	assert.Equal(t, 0, b.Waiting("orders"))
+}
+
+func TestBus_WaitContextDone(t *testing.T) {
+	b := NewBus(nil)
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	go func() {
+		waitForWaiters(t, b, "orders", 1)
+		cancel()
+	}()
+
+	_, err := b.Wait(ctx, "orders", time.Minute)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 0, b.Waiting("orders"))
+}
+
+func TestBus_CancelKeepsPublishedEvent(t *testing.T) {
+	b := NewBus(nil)
+	w := make(chan Event, 1)
+
+	b.waiters["orders"] = map[chan Event]struct{}{w: {}}
+	b.Notify("orders", "late")
+
+	e, err := b.cancel("orders", w, ErrTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, "late", e.Payload)
+}
@@ -0,0 +1,207 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"slices"
+)
+
+var (
+	// ErrInvalidHandler is returned by Subscribe when the handler does not have one of the supported signatures.
+	ErrInvalidHandler = errors.New("event handler must be a func(E) or func(context.Context, E), optionally returning an error")
+	// ErrHandlerPanic is returned by Publish when a synchronous handler panics.
+	ErrHandlerPanic = errors.New("event handler panicked")
+
+	errNilEvent = errors.New("event cannot be nil")
+
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// Named is implemented by the events which choose the topic they are delivered on to the waiters, see Name.
+type Named interface {
+	EventName() string
+}
+
+// Name returns the topic of event for the waiters: its EventName if it implements Named, else its type,
+// e.g. "main.UserCreated".
+func Name(event any) string {
+	if n, ok := event.(Named); ok {
+		return n.EventName()
+	}
+
+	return reflect.TypeOf(event).String()
+}
+
+// SubscribeOption is a function type that applies a configuration to a subscription.
+type SubscribeOption func(s *subscription)
+
+// Async dispatches the events to the handler in a new goroutine, so that Publish does not wait for it.
+// The errors and panics of the handler are logged, and the context it gets is not canceled with the one
+// of Publish.
+func Async() SubscribeOption {
+	return func(s *subscription) {
+		s.async = true
+	}
+}
+
+type subscription struct {
+	eventType reflect.Type
+	handler   reflect.Value
+	withCtx   bool
+	withErr   bool
+	async     bool
+}
+
+// Subscribe registers handler for the events of type E, where handler is one of:
+//
+//	func(E)
+//	func(E) error
+//	func(context.Context, E)
+//	func(context.Context, E) error
+//
+// When E is an interface, the handler gets all the events implementing it, e.g. func(any) gets every event.
+// The handlers run synchronously by default, in the order they were registered; see Async.
+//
+// It returns the function removing the subscription.
+func (b *Bus) Subscribe(handler any, opts ...SubscribeOption) (unsubscribe func(), err error) {
+	s, err := newSubscription(handler)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	b.subsMu.Lock()
+	b.subs = append(b.subs, s)
+	b.subsMu.Unlock()
+
+	return func() {
+		b.subsMu.Lock()
+		defer b.subsMu.Unlock()
+
+		// the slice is replaced rather than modified, as Publish dispatches to a snapshot of it.
+		b.subs = slices.DeleteFunc(slices.Clone(b.subs), func(sub *subscription) bool { return sub == s })
+	}, nil
+}
+
+// Publish dispatches event to the subscribers of its type, then delivers it to the waiters of Name(event).
+//
+// The synchronous handlers run before Publish returns, which returns their errors joined. A handler which panics
+// does not prevent the other handlers from getting the event: the panic is recovered, logged and returned as
+// ErrHandlerPanic.
+func (b *Bus) Publish(ctx context.Context, event any) error {
+	if event == nil {
+		return errNilEvent
+	}
+
+	eventType := reflect.TypeOf(event)
+
+	b.subsMu.RLock()
+
+	if b.closed {
+		b.subsMu.RUnlock()
+
+		return ErrClosed
+	}
+
+	var matched []*subscription
+
+	for _, s := range b.subs {
+		if !eventType.AssignableTo(s.eventType) {
+			continue
+		}
+
+		matched = append(matched, s)
+
+		// the asynchronous handlers are counted before the lock is released, so that Close waits for them.
+		if s.async {
+			b.async.Add(1)
+		}
+	}
+
+	b.subsMu.RUnlock()
+
+	var err error
+
+	for _, s := range matched {
+		if s.async {
+			go func() {
+				defer b.async.Done()
+
+				if err := b.call(context.WithoutCancel(ctx), s, event); err != nil {
+					b.logger.Errorf("asynchronous handler of event %v failed: %v", eventType, err)
+				}
+			}()
+
+			continue
+		}
+
+		err = errors.Join(err, b.call(ctx, s, event))
+	}
+
+	b.Notify(Name(event), event)
+
+	return err
+}
+
+// call runs the handler of s with event, turning its panic into an error.
+func (b *Bus) call(ctx context.Context, s *subscription, event any) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.logger.Errorf("handler of event %T panicked: %v\n%s", event, r, debug.Stack())
+
+			err = fmt.Errorf("%w: %v", ErrHandlerPanic, r)
+		}
+	}()
+
+	args := []reflect.Value{reflect.ValueOf(event)}
+	if s.withCtx {
+		args = []reflect.Value{reflect.ValueOf(ctx), args[0]}
+	}
+
+	out := s.handler.Call(args)
+
+	if s.withErr && !out[0].IsNil() {
+		return out[0].Interface().(error)
+	}
+
+	return nil
+}
+
+func newSubscription(handler any) (*subscription, error) {
+	v := reflect.ValueOf(handler)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return nil, fmt.Errorf("%w, got %T", ErrInvalidHandler, handler)
+	}
+
+	t := v.Type()
+	s := &subscription{handler: v}
+
+	switch {
+	case t.IsVariadic():
+		return nil, fmt.Errorf("%w, got %T", ErrInvalidHandler, handler)
+	case t.NumIn() == 1:
+	case t.NumIn() == 2 && t.In(0) == contextType:
+		s.withCtx = true
+	default:
+		return nil, fmt.Errorf("%w, got %T", ErrInvalidHandler, handler)
+	}
+
+	switch {
+	case t.NumOut() == 0:
+	case t.NumOut() == 1 && t.Out(0) == errorType:
+		s.withErr = true
+	default:
+		return nil, fmt.Errorf("%w, got %T", ErrInvalidHandler, handler)
+	}
+
+	s.eventType = t.In(t.NumIn() - 1)
+
+	return s, nil
+}
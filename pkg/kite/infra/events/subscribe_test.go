@@ -0,0 +1,196 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errHandler = errors.New("handler error")
+
+type userCreated struct {
+	ID string
+}
+
+type orderPlaced struct {
+	ID string
+}
+
+func (orderPlaced) EventName() string { return "orders.placed" }
+
+type testLogger struct {
+	mu   sync.Mutex
+	logs []string
+}
+
+func (l *testLogger) Errorf(format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.logs = append(l.logs, fmt.Sprintf(format, args...))
+}
+
+func (l *testLogger) entries() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]string(nil), l.logs...)
+}
+
+func TestBus_PublishDispatchesByType(t *testing.T) {
+	b := NewBus(&testLogger{})
+
+	var got []string
+
+	_, err := b.Subscribe(func(e userCreated) { got = append(got, "typed:"+e.ID) })
+	require.NoError(t, err)
+
+	_, err = b.Subscribe(func(_ context.Context, e userCreated) error {
+		got = append(got, "ctx:"+e.ID)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	_, err = b.Subscribe(func(e any) { got = append(got, fmt.Sprintf("any:%T", e)) })
+	require.NoError(t, err)
+
+	require.NoError(t, b.Publish(t.Context(), userCreated{ID: "u-1"}))
+	require.NoError(t, b.Publish(t.Context(), orderPlaced{ID: "o-1"}))
+
+	assert.Equal(t, []string{"typed:u-1", "ctx:u-1", "any:events.userCreated", "any:events.orderPlaced"}, got)
+}
+
+func TestBus_PublishJoinsErrorsAndRecoversPanics(t *testing.T) {
+	logger := &testLogger{}
+	b := NewBus(logger)
+
+	var called bool
+
+	_, _ = b.Subscribe(func(userCreated) error { return errHandler })
+	_, _ = b.Subscribe(func(userCreated) { panic("boom") })
+	_, _ = b.Subscribe(func(userCreated) { called = true })
+
+	err := b.Publish(t.Context(), userCreated{ID: "u-1"})
+
+	require.ErrorIs(t, err, errHandler)
+	require.ErrorIs(t, err, ErrHandlerPanic)
+	assert.True(t, called, "a panicking handler must not prevent the next ones from running")
+	require.Len(t, logger.entries(), 1)
+	assert.Contains(t, logger.entries()[0], "panicked: boom")
+}
+
+func TestBus_PublishAsync(t *testing.T) {
+	logger := &testLogger{}
+	b := NewBus(logger)
+
+	release := make(chan struct{})
+	done := make(chan string, 1)
+
+	_, _ = b.Subscribe(func(ctx context.Context, e userCreated) error {
+		<-release
+
+		done <- e.ID
+
+		return ctx.Err()
+	}, Async())
+	_, _ = b.Subscribe(func(userCreated) error { return errHandler }, Async())
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	require.NoError(t, b.Publish(ctx, userCreated{ID: "u-1"}))
+
+	// the context of the asynchronous handlers is not canceled with the one of Publish.
+	cancel()
+	close(release)
+
+	assert.Equal(t, "u-1", <-done)
+
+	require.NoError(t, b.Close(t.Context()))
+	assert.Equal(t, []string{"asynchronous handler of event events.userCreated failed: handler error"}, logger.entries())
+}
+
+func TestBus_Unsubscribe(t *testing.T) {
+	b := NewBus(&testLogger{})
+
+	var calls int
+
+	unsubscribe, err := b.Subscribe(func(userCreated) { calls++ })
+	require.NoError(t, err)
+
+	require.NoError(t, b.Publish(t.Context(), userCreated{}))
+	unsubscribe()
+	require.NoError(t, b.Publish(t.Context(), userCreated{}))
+
+	assert.Equal(t, 1, calls)
+}
+
+func TestBus_PublishNotifiesWaiters(t *testing.T) {
+	b := NewBus(&testLogger{})
+
+	events := make(chan Event, 1)
+
+	go func() {
+		e, err := b.Wait(t.Context(), "orders.placed", time.Minute)
+		assert.NoError(t, err)
+
+		events <- e
+	}()
+
+	waitForWaiters(t, b, "orders.placed", 1)
+
+	require.NoError(t, b.Publish(t.Context(), orderPlaced{ID: "o-1"}))
+	assert.Equal(t, Event{Topic: "orders.placed", Payload: orderPlaced{ID: "o-1"}}, <-events)
+}
+
+func TestBus_Close(t *testing.T) {
+	b := NewBus(&testLogger{})
+
+	release := make(chan struct{})
+
+	_, _ = b.Subscribe(func(userCreated) { <-release }, Async())
+
+	require.NoError(t, b.Publish(t.Context(), userCreated{}))
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, b.Close(ctx), context.DeadlineExceeded)
+	require.ErrorIs(t, b.Publish(t.Context(), userCreated{}), ErrClosed)
+
+	close(release)
+
+	require.NoError(t, b.Close(t.Context()))
+}
+
+func TestBus_SubscribeInvalidHandler(t *testing.T) {
+	b := NewBus(&testLogger{})
+
+	for i, handler := range []any{
+		nil,
+		"handler",
+		func() {},
+		func(string, userCreated) {},
+		func(userCreated) int { return 0 },
+		func(...userCreated) {},
+	} {
+		unsubscribe, err := b.Subscribe(handler)
+
+		assert.Nil(t, unsubscribe, "TEST[%d]", i)
+		require.ErrorIs(t, err, ErrInvalidHandler, "TEST[%d]", i)
+	}
+
+	require.Error(t, b.Publish(t.Context(), nil))
+}
+
+func TestName(t *testing.T) {
+	assert.Equal(t, "orders.placed", Name(orderPlaced{}))
+	assert.Equal(t, "events.userCreated", Name(userCreated{}))
+	assert.Equal(t, "*events.userCreated", Name(&userCreated{}))
+}
@@ -0,0 +1,387 @@
+// Package grpcpool provides a pool of gRPC client connections to a server, for the clients of high-QPS services which
+// are limited by the concurrent streams of a single connection.
+//
+// A pool holds Size connections and spreads the calls over them in round-robin. Each connection goes idle after
+// IdleTimeout without calls, and reconnects with an exponential backoff after a failure. The connections are checked
+// every HealthCheckInterval with the standard grpc.health.v1 service: the calls avoid the unhealthy connections, which
+// are replaced by new ones.
+//
+// A pool implements grpc.ClientConnInterface, so that the clients generated by protoc use it as a connection:
+//
+//	pool, err := app.Container().GRPCClientPool("dns:///orders:50051")
+//
+//	client := NewOrdersKiteClientFromPool(pool, app.Metrics())
+package grpcpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultSize                = 4
+	defaultIdleTimeout         = 30 * time.Minute
+	defaultHealthCheckInterval = 10 * time.Second
+	defaultMaxBackoff          = 30 * time.Second
+	healthCheckTimeout         = 5 * time.Second
+
+	// HealthyConnectionsMetric is the number of healthy connections of the pools.
+	HealthyConnectionsMetric = "app_grpc_client_pool_healthy_connections"
+	// EvictionsMetric counts the unhealthy connections replaced by the pools.
+	EvictionsMetric = "app_grpc_client_pool_evictions_total"
+)
+
+var (
+	// ErrClosed is returned for the calls made with a closed pool.
+	ErrClosed = errors.New("gRPC client pool is closed")
+
+	errMissingTarget = errors.New("gRPC client pool target is empty")
+)
+
+// Config holds the configuration of a pool. Zero values are replaced with the defaults.
+type Config struct {
+	// Target is the address of the server, e.g. "dns:///orders:50051", see grpc.NewClient.
+	Target string
+	// Size is the number of connections of the pool. Defaults to 4.
+	Size int
+	// IdleTimeout is how long a connection without calls stays connected. Defaults to 30 minutes.
+	IdleTimeout time.Duration
+	// HealthCheckInterval is the interval of the health checks of the connections. Defaults to 10 seconds, a negative
+	// interval disables the health checks.
+	HealthCheckInterval time.Duration
+	// HealthService is the service checked with the grpc.health.v1 service. Defaults to the whole server.
+	HealthService string
+	// MaxBackoff is the longest delay between the reconnection attempts of a connection. Defaults to 30 seconds.
+	MaxBackoff time.Duration
+	// DialOptions are added to the default options of the connections, which use an insecure transport.
+	DialOptions []grpc.DialOption
+}
+
+func (c Config) withDefaults() Config {
+	if c.Size <= 0 {
+		c.Size = defaultSize
+	}
+
+	if c.IdleTimeout <= 0 {
+		c.IdleTimeout = defaultIdleTimeout
+	}
+
+	if c.HealthCheckInterval == 0 {
+		c.HealthCheckInterval = defaultHealthCheckInterval
+	}
+
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = defaultMaxBackoff
+	}
+
+	return c
+}
+
+// Logger is used to log the connections replaced by the pools.
+type Logger interface {
+	Warnf(format string, args ...any)
+}
+
+// Metrics is used to record the healthy connections of the pools and their evictions.
+type Metrics interface {
+	SetGauge(name string, value float64, labels ...string)
+	IncrementCounter(ctx context.Context, name string, labels ...string)
+}
+
+// Pool is a pool of connections to a gRPC server. It is safe for concurrent use.
+type Pool struct {
+	config  Config
+	logger  Logger
+	metrics Metrics
+
+	next atomic.Uint64
+
+	mu     sync.RWMutex
+	conns  []*conn
+	closed bool
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+type conn struct {
+	*grpc.ClientConn
+	healthy atomic.Bool
+}
+
+// New returns a pool of connections to config.Target. The connections are established on their first call. The logger
+// and metrics may be nil.
+func New(config Config, logger Logger, metrics Metrics) (*Pool, error) {
+	config = config.withDefaults()
+
+	if config.Target == "" {
+		return nil, errMissingTarget
+	}
+
+	p := &Pool{config: config, logger: logger, metrics: metrics, done: make(chan struct{})}
+
+	for range config.Size {
+		c, err := p.dial()
+		if err != nil {
+			_ = p.closeConns()
+
+			return nil, err
+		}
+
+		c.healthy.Store(true)
+		p.conns = append(p.conns, c)
+	}
+
+	p.recordHealthy()
+
+	if config.HealthCheckInterval > 0 {
+		p.wg.Add(1)
+
+		go p.checkHealth()
+	}
+
+	return p, nil
+}
+
+// Target returns the address of the server.
+func (p *Pool) Target() string {
+	return p.config.Target
+}
+
+// Invoke performs a unary call on a connection of the pool.
+func (p *Pool) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	c, err := p.pick()
+	if err != nil {
+		return err
+	}
+
+	return c.Invoke(ctx, method, args, reply, opts...)
+}
+
+// NewStream opens a stream on a connection of the pool.
+func (p *Pool) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string,
+	opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	c, err := p.pick()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.NewStream(ctx, desc, method, opts...)
+}
+
+// Healthy returns the number of healthy connections of the pool.
+func (p *Pool) Healthy() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	healthy := 0
+
+	for _, c := range p.conns {
+		if c.usable() {
+			healthy++
+		}
+	}
+
+	return healthy
+}
+
+// Close closes the connections of the pool. The calls in progress fail.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+
+	if p.closed {
+		p.mu.Unlock()
+
+		return nil
+	}
+
+	p.closed = true
+	close(p.done)
+
+	p.mu.Unlock()
+
+	p.wg.Wait()
+
+	return p.closeConns()
+}
+
+// pick returns the next healthy connection in round-robin, or the next connection when none is healthy, so that the
+// call waits for it to reconnect or fails with its error.
+func (p *Pool) pick() (*conn, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return nil, ErrClosed
+	}
+
+	start := p.next.Add(1)
+	size := uint64(len(p.conns))
+
+	for i := range size {
+		if c := p.conns[(start+i)%size]; c.usable() {
+			return c, nil
+		}
+	}
+
+	return p.conns[start%size], nil
+}
+
+// usable reports whether the connection passed its last health check and is not failing to connect.
+func (c *conn) usable() bool {
+	if !c.healthy.Load() {
+		return false
+	}
+
+	state := c.GetState()
+
+	return state != connectivity.TransientFailure && state != connectivity.Shutdown
+}
+
+func (p *Pool) dial() (*conn, error) {
+	opts := append([]grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithIdleTimeout(p.config.IdleTimeout),
+		grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff:           backoff.Config{BaseDelay: time.Second, Multiplier: 1.6, Jitter: 0.2, MaxDelay: p.config.MaxBackoff},
+			MinConnectTimeout: 20 * time.Second,
+		}),
+	}, p.config.DialOptions...)
+
+	cc, err := grpc.NewClient(p.config.Target, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("creating gRPC connection to %s: %w", p.config.Target, err)
+	}
+
+	return &conn{ClientConn: cc}, nil
+}
+
+func (p *Pool) checkHealth() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.config.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			p.checkConns()
+		}
+	}
+}
+
+// checkConns checks the health of the connections, and replaces the ones which became unhealthy. The healthy idle
+// connections are not checked, so that they are not reconnected by the checks. The replacements are used once they
+// pass a check, reconnecting with a backoff until then.
+func (p *Pool) checkConns() {
+	p.mu.RLock()
+	conns := append([]*conn(nil), p.conns...)
+	p.mu.RUnlock()
+
+	for i, c := range conns {
+		if c.healthy.Load() && c.GetState() == connectivity.Idle {
+			continue
+		}
+
+		if p.healthy(c) {
+			c.healthy.Store(true)
+
+			continue
+		}
+
+		if c.healthy.Swap(false) {
+			p.evict(i, c)
+		}
+	}
+
+	p.recordHealthy()
+}
+
+// healthy reports whether the server of the connection is serving. The servers which do not implement the health
+// service are considered healthy when they answer.
+func (p *Pool) healthy(c *conn) bool {
+	timeout := healthCheckTimeout
+	if p.config.HealthCheckInterval > 0 {
+		timeout = min(p.config.HealthCheckInterval, healthCheckTimeout)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	res, err := grpc_health_v1.NewHealthClient(c).Check(ctx,
+		&grpc_health_v1.HealthCheckRequest{Service: p.config.HealthService})
+	if status.Code(err) == codes.Unimplemented {
+		return true
+	}
+
+	return err == nil && res.GetStatus() == grpc_health_v1.HealthCheckResponse_SERVING
+}
+
+// evict replaces the unhealthy connection at index i with a new connection, which is used once it passes a check.
+func (p *Pool) evict(i int, c *conn) {
+	replacement, err := p.dial()
+	if err != nil {
+		p.warnf("could not replace unhealthy gRPC connection to %s: %v", p.config.Target, err)
+
+		return
+	}
+
+	p.mu.Lock()
+
+	if p.closed || p.conns[i] != c {
+		p.mu.Unlock()
+
+		_ = replacement.Close()
+
+		return
+	}
+
+	p.conns[i] = replacement
+
+	p.mu.Unlock()
+
+	p.warnf("replaced unhealthy gRPC connection to %s", p.config.Target)
+
+	if p.metrics != nil {
+		p.metrics.IncrementCounter(context.Background(), EvictionsMetric, "target", p.config.Target)
+	}
+
+	// the calls in progress on the connection fail, as the server is not healthy.
+	_ = c.Close()
+}
+
+func (p *Pool) closeConns() error {
+	var err error
+
+	for _, c := range p.conns {
+		err = errors.Join(err, c.Close())
+	}
+
+	return err
+}
+
+func (p *Pool) recordHealthy() {
+	if p.metrics != nil {
+		p.metrics.SetGauge(HealthyConnectionsMetric, float64(p.Healthy()), "target", p.config.Target)
+	}
+}
+
+func (p *Pool) warnf(format string, args ...any) {
+	if p.logger != nil {
+		p.logger.Warnf(format, args...)
+	}
+}
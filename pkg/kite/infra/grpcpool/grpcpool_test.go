@@ -0,0 +1,171 @@
+package grpcpool
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type poolMetrics struct {
+	mu        sync.Mutex
+	healthy   float64
+	evictions int
+}
+
+func (m *poolMetrics) SetGauge(name string, value float64, _ ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if name == HealthyConnectionsMetric {
+		m.healthy = value
+	}
+}
+
+func (m *poolMetrics) IncrementCounter(_ context.Context, name string, _ ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if name == EvictionsMetric {
+		m.evictions++
+	}
+}
+
+func (m *poolMetrics) get() (healthy float64, evictions int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.healthy, m.evictions
+}
+
+// startServer starts a gRPC server, serving the health service when healthServer is not nil.
+func startServer(t *testing.T, healthServer *health.Server) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	if healthServer != nil {
+		grpc_health_v1.RegisterHealthServer(server, healthServer)
+	}
+
+	go func() { _ = server.Serve(listener) }()
+
+	t.Cleanup(server.Stop)
+
+	return listener.Addr().String()
+}
+
+func TestPool_Invoke(t *testing.T) {
+	target := startServer(t, health.NewServer())
+
+	pool, err := New(Config{Target: target, Size: 3, HealthCheckInterval: -1}, nil, nil)
+	require.NoError(t, err)
+
+	defer pool.Close()
+
+	client := grpc_health_v1.NewHealthClient(pool)
+
+	for range 6 {
+		res, err := client.Check(t.Context(), &grpc_health_v1.HealthCheckRequest{})
+
+		require.NoError(t, err)
+		assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, res.GetStatus())
+	}
+
+	// the calls are spread over all the connections, which are all connected.
+	for _, c := range pool.conns {
+		assert.NotEqual(t, "IDLE", c.GetState().String())
+	}
+
+	assert.Equal(t, target, pool.Target())
+	assert.Equal(t, 3, pool.Healthy())
+}
+
+func TestPool_HealthAwareEviction(t *testing.T) {
+	healthServer := health.NewServer()
+	target := startServer(t, healthServer)
+	metrics := &poolMetrics{}
+
+	pool, err := New(Config{Target: target, Size: 2, HealthCheckInterval: 20 * time.Millisecond}, nil, metrics)
+	require.NoError(t, err)
+
+	defer pool.Close()
+
+	// the idle connections are not checked, so that they are connected by calls.
+	for range 2 {
+		_, err = grpc_health_v1.NewHealthClient(pool).Check(t.Context(), &grpc_health_v1.HealthCheckRequest{})
+		require.NoError(t, err)
+	}
+
+	original := append([]*conn(nil), pool.conns...)
+
+	healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+
+	require.Eventually(t, func() bool {
+		healthy, evictions := metrics.get()
+
+		return healthy == 0 && evictions == 2
+	}, time.Second, 10*time.Millisecond)
+
+	pool.mu.RLock()
+	assert.NotSame(t, original[0], pool.conns[0], "the unhealthy connections are replaced")
+	assert.NotSame(t, original[1], pool.conns[1], "the unhealthy connections are replaced")
+	pool.mu.RUnlock()
+
+	healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
+
+	require.Eventually(t, func() bool { return pool.Healthy() == 2 }, time.Second, 10*time.Millisecond)
+
+	_, evictions := metrics.get()
+	assert.Equal(t, 2, evictions, "the unhealthy replacements are not replaced again")
+}
+
+func TestPool_HealthServiceNotImplemented(t *testing.T) {
+	pool, err := New(Config{Target: startServer(t, nil), Size: 1, HealthCheckInterval: -1}, nil, nil)
+	require.NoError(t, err)
+
+	defer pool.Close()
+
+	assert.True(t, pool.healthy(pool.conns[0]))
+}
+
+func TestPool_Close(t *testing.T) {
+	pool, err := New(Config{Target: startServer(t, health.NewServer())}, nil, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, pool.Close())
+	require.NoError(t, pool.Close())
+
+	err = pool.Invoke(t.Context(), "/grpc.health.v1.Health/Check", &grpc_health_v1.HealthCheckRequest{},
+		&grpc_health_v1.HealthCheckResponse{})
+	require.ErrorIs(t, err, ErrClosed)
+
+	_, err = pool.NewStream(t.Context(), &grpc.StreamDesc{}, "/grpc.health.v1.Health/Watch")
+	require.ErrorIs(t, err, ErrClosed)
+}
+
+func TestNew_Error(t *testing.T) {
+	_, err := New(Config{}, nil, nil)
+	require.ErrorIs(t, err, errMissingTarget)
+
+	// the connections cannot be created without transport credentials.
+	_, err = New(Config{Target: "localhost:50051", DialOptions: []grpc.DialOption{
+		grpc.WithTransportCredentials(nil), grpc.WithCredentialsBundle(nil)}}, nil, nil)
+	require.Error(t, err)
+}
+
+func TestConfig_withDefaults(t *testing.T) {
+	config := Config{Target: "localhost:50051"}.withDefaults()
+
+	assert.Equal(t, Config{Target: "localhost:50051", Size: defaultSize, IdleTimeout: defaultIdleTimeout,
+		HealthCheckInterval: defaultHealthCheckInterval, MaxBackoff: defaultMaxBackoff}, config)
+}
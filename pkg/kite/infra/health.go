@@ -3,29 +3,76 @@ package infra
 import (
 	"context"
 	"reflect"
+	"slices"
+
+	"github.com/sllt/kite/pkg/kite/datasource"
 )
 
 func (c *Container) Health(ctx context.Context) any {
-	var (
-		healthMap = make(map[string]any)
-		downCount int
-	)
+	healthMap, down := c.checkDependencies(ctx)
+
+	c.appHealth(healthMap, len(down))
+
+	return healthMap
+}
+
+// DependenciesUp runs the health checks of the dependencies of the container and reports, for each of them, whether
+// it is up. Dependencies are named as in Health, e.g. "sql", "redis", "sql:<name>" or the name of an HTTP service.
+func (c *Container) DependenciesUp(ctx context.Context) map[string]bool {
+	healthMap, down := c.checkDependencies(ctx)
+
+	up := make(map[string]bool, len(healthMap))
+	for name := range healthMap {
+		up[name] = !down[name]
+	}
+
+	return up
+}
+
+// AddHealthCheck adds a check to the health of the container, reported under name in Health and DependenciesUp,
+// e.g. "synthetic:orders". The check runs on every call to Health, so it must be fast: a slow check should rather run
+// in the background and report its last result.
+func (c *Container) AddHealthCheck(name string, check func(ctx context.Context) datasource.Health) {
+	c.healthChecksMu.Lock()
+	defer c.healthChecksMu.Unlock()
+
+	if c.healthChecks == nil {
+		c.healthChecks = make(map[string]func(context.Context) datasource.Health)
+	}
+
+	c.healthChecks[name] = check
+}
+
+// checkDependencies returns the health of each dependency of the container, and the set of the dependencies
+// which are down.
+func (c *Container) checkDependencies(ctx context.Context) (healthMap map[string]any, down map[string]bool) {
+	healthMap = make(map[string]any)
+	down = make(map[string]bool)
 
 	const statusDown = "DOWN"
 
 	if !isNil(c.SQL) {
 		health := c.SQL.HealthCheck()
 		if health.Status == statusDown {
-			downCount++
+			down["sql"] = true
 		}
 
 		healthMap["sql"] = health
 	}
 
+	for name, db := range c.namedSQL {
+		health := db.HealthCheck()
+		if health.Status == statusDown {
+			down["sql:"+name] = true
+		}
+
+		healthMap["sql:"+name] = health
+	}
+
 	if !isNil(c.Redis) {
 		health := c.Redis.HealthCheck()
 		if health.Status == statusDown {
-			downCount++
+			down["redis"] = true
 		}
 
 		healthMap["redis"] = health
@@ -34,30 +81,88 @@ func (c *Container) Health(ctx context.Context) any {
 	if c.PubSub != nil {
 		health := c.PubSub.Health()
 		if health.Status == statusDown {
-			downCount++
+			down["pubsub"] = true
 		}
 
 		healthMap["pubsub"] = health
 	}
 
-	downCount += checkExternalDBHealth(ctx, c, healthMap)
+	checkExternalDBHealth(ctx, c, healthMap, down)
+
+	c.healthChecksMu.RLock()
+
+	for name, check := range c.healthChecks {
+		health := check(ctx)
+		if health.Status == statusDown {
+			down[name] = true
+		}
+
+		healthMap[name] = health
+	}
+
+	c.healthChecksMu.RUnlock()
 
 	for name, svc := range c.Services {
 		health := svc.HealthCheck(ctx)
 		if health.Status == statusDown {
-			downCount++
+			down[name] = true
 		}
 
 		healthMap[name] = health
 	}
 
-	c.appHealth(healthMap, downCount)
+	return healthMap, down
+}
+
+// Datasources returns the names of the datasources of the container, sorted, as named in Health.
+func (c *Container) Datasources() []string {
+	var names []string
 
-	return healthMap
+	if !isNil(c.SQL) {
+		names = append(names, "sql")
+	}
+
+	for name := range c.namedSQL {
+		names = append(names, "sql:"+name)
+	}
+
+	if !isNil(c.Redis) {
+		names = append(names, "redis")
+	}
+
+	if c.PubSub != nil {
+		names = append(names, "pubsub")
+	}
+
+	for name, service := range c.externalDBs() {
+		if !isNil(service) {
+			names = append(names, name)
+		}
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+func checkExternalDBHealth(ctx context.Context, c *Container, healthMap map[string]any, down map[string]bool) {
+	for name, service := range c.externalDBs() {
+		if !isNil(service) {
+			health, err := service.HealthCheck(ctx)
+			if err != nil {
+				down[name] = true
+			}
+
+			healthMap[name] = health
+		}
+	}
 }
 
-func checkExternalDBHealth(ctx context.Context, c *Container, healthMap map[string]any) (downCount int) {
-	services := map[string]interface {
+// externalDBs returns the external datasources of the container, keyed by their name, including the unset ones.
+func (c *Container) externalDBs() map[string]interface {
+	HealthCheck(context.Context) (any, error)
+} {
+	return map[string]interface {
 		HealthCheck(context.Context) (any, error)
 	}{
 		"mongo":         c.Mongo,
@@ -71,19 +176,6 @@ func checkExternalDBHealth(ctx context.Context, c *Container, healthMap map[stri
 		"couchbase":     c.Couchbase,
 		"influx":        c.InfluxDB,
 	}
-
-	for name, service := range services {
-		if !isNil(service) {
-			health, err := service.HealthCheck(ctx)
-			if err != nil {
-				downCount++
-			}
-
-			healthMap[name] = health
-		}
-	}
-
-	return downCount
 }
 
 func (c *Container) appHealth(healthMap map[string]any, downCount int) {
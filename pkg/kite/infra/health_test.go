@@ -1,6 +1,7 @@
 package infra
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -53,6 +54,48 @@ func TestContainer_Health(t *testing.T) {
 	}
 }
 
+func TestContainer_DependenciesUp(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c, mocks := NewMockContainer(t)
+
+	registerMocks(mocks, "DOWN")
+
+	c.Services = map[string]service.HTTP{
+		"test-service": service.NewHTTPService(srv.URL, logging.NewMockLogger(logging.ERROR), nil),
+	}
+
+	up := c.DependenciesUp(t.Context())
+
+	assert.False(t, up["sql"])
+	assert.False(t, up["redis"])
+	assert.False(t, up["pubsub"])
+	assert.True(t, up["test-service"])
+	assert.NotContains(t, up, "status")
+}
+
+func TestContainer_AddHealthCheck(t *testing.T) {
+	c := &Container{}
+
+	c.AddHealthCheck("synthetic:orders", func(context.Context) datasource.Health {
+		return datasource.Health{Status: datasource.StatusDown, Details: map[string]any{"error": "timeout"}}
+	})
+	c.AddHealthCheck("synthetic:db", func(context.Context) datasource.Health {
+		return datasource.Health{Status: datasource.StatusUp}
+	})
+
+	health, ok := c.Health(t.Context()).(map[string]any)
+
+	assert.True(t, ok)
+	assert.Equal(t, "DEGRADED", health["status"])
+	assert.Equal(t, datasource.Health{Status: datasource.StatusDown, Details: map[string]any{"error": "timeout"}},
+		health["synthetic:orders"])
+	assert.Equal(t, map[string]bool{"synthetic:orders": false, "synthetic:db": true}, c.DependenciesUp(t.Context()))
+}
+
 func registerMocks(mocks *Mocks, health string) {
 	mocks.SQL.ExpectHealthCheck().WillReturnHealthCheck(&datasource.Health{
 		Status: health,
@@ -242,3 +285,16 @@ func getExpectedData(datasourceHealth, appHealth, srvURL string) map[string]any
 		"version": "test",
 	}
 }
+
+func TestContainer_Datasources(t *testing.T) {
+	assert.Empty(t, (&Container{}).Datasources())
+
+	c, _ := NewMockContainer(t)
+
+	c.AddNamedSQL("analytics", c.SQL)
+
+	datasources := c.Datasources()
+
+	assert.Subset(t, datasources, []string{"sql", "sql:analytics", "redis", "pubsub", "mongo", "cassandra"})
+	assert.IsIncreasing(t, datasources)
+}
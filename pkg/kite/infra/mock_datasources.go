@@ -9328,6 +9328,20 @@ func (mr *MockCassandraWithContextMockRecorder) QueryWithCtx(ctx, dest, stmt any
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryWithCtx", reflect.TypeOf((*MockCassandraWithContext)(nil).QueryWithCtx), varargs...)
 }
 
+// WriteBatchWithCtx mocks base method.
+func (m *MockCassandraWithContext) WriteBatchWithCtx(ctx context.Context, batchType int, stmt string, rows [][]any, size int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteBatchWithCtx", ctx, batchType, stmt, rows, size)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteBatchWithCtx indicates an expected call of WriteBatchWithCtx.
+func (mr *MockCassandraWithContextMockRecorder) WriteBatchWithCtx(ctx, batchType, stmt, rows, size any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteBatchWithCtx", reflect.TypeOf((*MockCassandraWithContext)(nil).WriteBatchWithCtx), ctx, batchType, stmt, rows, size)
+}
+
 // MockCassandraBatchWithContext is a mock of CassandraBatchWithContext interface.
 type MockCassandraBatchWithContext struct {
 	ctrl     *gomock.Controller
@@ -9405,6 +9419,20 @@ func (mr *MockCassandraBatchWithContextMockRecorder) ExecuteBatchWithCtx(ctx, na
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteBatchWithCtx", reflect.TypeOf((*MockCassandraBatchWithContext)(nil).ExecuteBatchWithCtx), ctx, name)
 }
 
+// WriteBatchWithCtx mocks base method.
+func (m *MockCassandraBatchWithContext) WriteBatchWithCtx(ctx context.Context, batchType int, stmt string, rows [][]any, size int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteBatchWithCtx", ctx, batchType, stmt, rows, size)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteBatchWithCtx indicates an expected call of WriteBatchWithCtx.
+func (mr *MockCassandraBatchWithContextMockRecorder) WriteBatchWithCtx(ctx, batchType, stmt, rows, size any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteBatchWithCtx", reflect.TypeOf((*MockCassandraBatchWithContext)(nil).WriteBatchWithCtx), ctx, batchType, stmt, rows, size)
+}
+
 // MockCassandraProvider is a mock of CassandraProvider interface.
 type MockCassandraProvider struct {
 	ctrl     *gomock.Controller
@@ -9742,6 +9770,20 @@ func (mr *MockCassandraProviderMockRecorder) UseTracer(tracer any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTracer", reflect.TypeOf((*MockCassandraProvider)(nil).UseTracer), tracer)
 }
 
+// WriteBatchWithCtx mocks base method.
+func (m *MockCassandraProvider) WriteBatchWithCtx(ctx context.Context, batchType int, stmt string, rows [][]any, size int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteBatchWithCtx", ctx, batchType, stmt, rows, size)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteBatchWithCtx indicates an expected call of WriteBatchWithCtx.
+func (mr *MockCassandraProviderMockRecorder) WriteBatchWithCtx(ctx, batchType, stmt, rows, size any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteBatchWithCtx", reflect.TypeOf((*MockCassandraProvider)(nil).WriteBatchWithCtx), ctx, batchType, stmt, rows, size)
+}
+
 // MockClickhouse is a mock of Clickhouse interface.
 type MockClickhouse struct {
 	ctrl     *gomock.Controller
@@ -10328,6 +10370,20 @@ func (m *MockMongo) EXPECT() *MockMongoMockRecorder {
 	return m.recorder
 }
 
+// Aggregate mocks base method.
+func (m *MockMongo) Aggregate(ctx context.Context, collection string, pipeline, results any) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Aggregate", ctx, collection, pipeline, results)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Aggregate indicates an expected call of Aggregate.
+func (mr *MockMongoMockRecorder) Aggregate(ctx, collection, pipeline, results any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Aggregate", reflect.TypeOf((*MockMongo)(nil).Aggregate), ctx, collection, pipeline, results)
+}
+
 // CountDocuments mocks base method.
 func (m *MockMongo) CountDocuments(ctx context.Context, collection string, filter any) (int64, error) {
 	m.ctrl.T.Helper()
@@ -10635,6 +10691,20 @@ func (m *MockMongoProvider) EXPECT() *MockMongoProviderMockRecorder {
 	return m.recorder
 }
 
+// Aggregate mocks base method.
+func (m *MockMongoProvider) Aggregate(ctx context.Context, collection string, pipeline, results any) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Aggregate", ctx, collection, pipeline, results)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Aggregate indicates an expected call of Aggregate.
+func (mr *MockMongoProviderMockRecorder) Aggregate(ctx, collection, pipeline, results any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Aggregate", reflect.TypeOf((*MockMongoProvider)(nil).Aggregate), ctx, collection, pipeline, results)
+}
+
 // Connect mocks base method.
 func (m *MockMongoProvider) Connect() {
 	m.ctrl.T.Helper()
@@ -0,0 +1,116 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisDueKey     = "kite:tasks:due"
+	redisRunningKey = "kite:tasks:running"
+	redisDataKey    = "kite:tasks:data"
+	redisDonePrefix = "kite:tasks:done:"
+)
+
+// scheduleScript stores the task and adds it to the due set, unless it is already stored or was completed.
+var scheduleScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[3]) == 1 or redis.call('HSETNX', KEYS[2], ARGV[1], ARGV[2]) == 0 then
+	return 0
+end
+
+redis.call('ZADD', KEYS[1], ARGV[3], ARGV[1])
+
+return 1
+`)
+
+// claimScript moves the tasks whose lease expired back to the due set, then moves the due tasks to the running set,
+// scored by the end of their lease, and returns them.
+var claimScript = redis.NewScript(`
+local expired = redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', ARGV[1])
+for _, id in ipairs(expired) do
+	redis.call('ZREM', KEYS[2], id)
+	redis.call('ZADD', KEYS[1], ARGV[1], id)
+end
+
+local tasks = {}
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[3])
+for _, id in ipairs(ids) do
+	redis.call('ZREM', KEYS[1], id)
+
+	local data = redis.call('HGET', KEYS[3], id)
+	if data then
+		redis.call('ZADD', KEYS[2], ARGV[2], id)
+		table.insert(tasks, data)
+	end
+end
+
+return tasks
+`)
+
+// RedisStore implements Store using Redis. The tasks are kept in a hash, and their ids in sorted sets of the due
+// tasks, scored by their RunAt, and of the running ones, scored by the end of their lease. Claims run as Lua scripts,
+// so that a task is claimed by a single replica.
+type RedisStore struct {
+	client redis.Cmdable
+}
+
+// NewRedisStore creates a store backed by the given Redis client, e.g. the application's Redis datasource.
+func NewRedisStore(client redis.Cmdable) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+func (r *RedisStore) Schedule(ctx context.Context, task Task) (bool, error) {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := scheduleScript.Run(ctx, r.client, []string{redisDueKey, redisDataKey, redisDonePrefix + task.ID},
+		task.ID, data, task.RunAt.UnixMilli()).Int()
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
+
+func (r *RedisStore) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Task, error) {
+	values, err := claimScript.Run(ctx, r.client, []string{redisDueKey, redisRunningKey, redisDataKey},
+		now.UnixMilli(), now.Add(lease).UnixMilli(), limit).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make([]Task, 0, len(values))
+
+	for _, v := range values {
+		var task Task
+		if err := json.Unmarshal([]byte(v), &task); err != nil {
+			return nil, err
+		}
+
+		claimed = append(claimed, task)
+	}
+
+	return claimed, nil
+}
+
+func (r *RedisStore) Renew(ctx context.Context, id string, now time.Time, lease time.Duration) error {
+	// XX only updates the score of a running task, a completed one is not added back.
+	return r.client.ZAddXX(ctx, redisRunningKey, redis.Z{Score: float64(now.Add(lease).UnixMilli()), Member: id}).Err()
+}
+
+func (r *RedisStore) Complete(ctx context.Context, id string) error {
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, redisRunningKey, id)
+		pipe.HDel(ctx, redisDataKey, id)
+		pipe.Set(ctx, redisDonePrefix+id, 1, Retention)
+
+		return nil
+	})
+
+	return err
+}
@@ -0,0 +1,194 @@
+package tasks
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/sllt/kite/pkg/kite/datasource/sql/qb"
+)
+
+// CreateTableSQL creates the table used by the SQL store. The store runs it before its first use; it can also be
+// applied from a migration, e.g. when the user of the application cannot create tables.
+const CreateTableSQL = `CREATE TABLE IF NOT EXISTS kite_scheduled_tasks (
+    id VARCHAR(255) NOT NULL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    payload TEXT,
+    run_at BIGINT NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    lease_until BIGINT NOT NULL
+);`
+
+const (
+	statusPending = "pending"
+	statusRunning = "running"
+	statusDone    = "done"
+)
+
+// SQL is the subset of the SQL datasource used by the SQL store.
+type SQL interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	Dialect() string
+}
+
+// SQLStore implements Store on the kite_scheduled_tasks table, created on first use, see CreateTableSQL.
+//
+// A task is claimed by a conditional update of its status, so that a single replica gets it. The completed tasks
+// are kept with the "done" status until Retention elapses, in lease_until.
+type SQLStore struct {
+	db SQL
+
+	mu      sync.Mutex
+	created bool
+}
+
+// NewSQLStore creates a store backed by the given SQL datasource.
+func NewSQLStore(db SQL) *SQLStore {
+	return &SQLStore{db: db}
+}
+
+// createTable creates the table of the store, unless done already. A failure is returned, and the creation tried
+// again by the next call.
+func (s *SQLStore) createTable(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.created {
+		return nil
+	}
+
+	if _, err := s.db.ExecContext(ctx, CreateTableSQL); err != nil {
+		return err
+	}
+
+	s.created = true
+
+	return nil
+}
+
+func (s *SQLStore) Schedule(ctx context.Context, task Task) (bool, error) {
+	if err := s.createTable(ctx); err != nil {
+		return false, err
+	}
+
+	// an expired completion record must not prevent the task from being scheduled again.
+	_, err := s.db.ExecContext(ctx, s.rebind(
+		"DELETE FROM kite_scheduled_tasks WHERE id = ? AND status = ? AND lease_until < ?"),
+		task.ID, statusDone, time.Now().UnixMilli())
+	if err != nil {
+		return false, err
+	}
+
+	_, err = s.db.ExecContext(ctx, s.rebind(
+		"INSERT INTO kite_scheduled_tasks (id, name, payload, run_at, status, lease_until) VALUES (?, ?, ?, ?, ?, 0)"),
+		task.ID, task.Name, string(task.Payload), task.RunAt.UnixMilli(), statusPending)
+	if err == nil {
+		return true, nil
+	}
+
+	// Distinguish a primary key violation from other failures by checking whether the row exists.
+	var exists int
+
+	if scanErr := s.db.QueryRowContext(ctx, s.rebind(
+		"SELECT 1 FROM kite_scheduled_tasks WHERE id = ?"), task.ID).Scan(&exists); scanErr == nil {
+		return false, nil
+	}
+
+	return false, err
+}
+
+func (s *SQLStore) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Task, error) {
+	if err := s.createTable(ctx); err != nil {
+		return nil, err
+	}
+
+	due, err := s.due(ctx, now, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make([]Task, 0, len(due))
+
+	for _, task := range due {
+		// the update only succeeds for the replica which first claims the task, as it moves it out of the condition.
+		res, err := s.db.ExecContext(ctx, s.rebind("UPDATE kite_scheduled_tasks SET status = ?, lease_until = ? "+
+			"WHERE id = ? AND (status = ? OR (status = ? AND lease_until <= ?))"),
+			statusRunning, now.Add(lease).UnixMilli(), task.ID, statusPending, statusRunning, now.UnixMilli())
+		if err != nil {
+			return claimed, err
+		}
+
+		if n, err := res.RowsAffected(); err == nil && n == 1 {
+			claimed = append(claimed, task)
+		}
+	}
+
+	return claimed, nil
+}
+
+// due returns up to limit tasks due at now, pending or whose lease expired.
+func (s *SQLStore) due(ctx context.Context, now time.Time, limit int) ([]Task, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind("SELECT id, name, payload, run_at FROM kite_scheduled_tasks "+
+		"WHERE (status = ? AND run_at <= ?) OR (status = ? AND lease_until <= ?) ORDER BY run_at LIMIT ?"),
+		statusPending, now.UnixMilli(), statusRunning, now.UnixMilli(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var due []Task
+
+	for rows.Next() {
+		var (
+			task    Task
+			payload sql.NullString
+			runAt   int64
+		)
+
+		if err := rows.Scan(&task.ID, &task.Name, &payload, &runAt); err != nil {
+			return nil, err
+		}
+
+		if payload.String != "" {
+			task.Payload = []byte(payload.String)
+		}
+
+		task.RunAt = time.UnixMilli(runAt)
+
+		due = append(due, task)
+	}
+
+	return due, rows.Err()
+}
+
+func (s *SQLStore) Renew(ctx context.Context, id string, now time.Time, lease time.Duration) error {
+	_, err := s.db.ExecContext(ctx, s.rebind(
+		"UPDATE kite_scheduled_tasks SET lease_until = ? WHERE id = ? AND status = ?"),
+		now.Add(lease).UnixMilli(), id, statusRunning)
+
+	return err
+}
+
+func (s *SQLStore) Complete(ctx context.Context, id string) error {
+	now := time.Now()
+
+	_, err := s.db.ExecContext(ctx, s.rebind(
+		"UPDATE kite_scheduled_tasks SET status = ?, lease_until = ? WHERE id = ?"),
+		statusDone, now.Add(Retention).UnixMilli(), id)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, s.rebind(
+		"DELETE FROM kite_scheduled_tasks WHERE status = ? AND lease_until < ?"), statusDone, now.UnixMilli())
+
+	return err
+}
+
+// rebind converts the placeholders of query for the dialect of the store.
+func (s *SQLStore) rebind(query string) string {
+	return qb.RebindWithDialect(s.db.Dialect(), query)
+}
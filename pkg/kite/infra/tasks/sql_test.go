@@ -0,0 +1,144 @@
+package tasks
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errDuplicate = errors.New("duplicate key")
+
+type dialectSQL struct {
+	*sql.DB
+	dialect string
+}
+
+func (d dialectSQL) Dialect() string {
+	return d.dialect
+}
+
+func newSQLStore(t *testing.T, dialect string) (*SQLStore, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+
+	t.Cleanup(func() { db.Close() })
+
+	return NewSQLStore(dialectSQL{DB: db, dialect: dialect}), mock
+}
+
+func TestSQLStore_CreateTable(t *testing.T) {
+	store, mock := newSQLStore(t, "mysql")
+	now := time.UnixMilli(1700000000000)
+
+	due := "SELECT id, name, payload, run_at FROM kite_scheduled_tasks " +
+		"WHERE (status = ? AND run_at <= ?) OR (status = ? AND lease_until <= ?) ORDER BY run_at LIMIT ?"
+
+	mock.ExpectExec(CreateTableSQL).WillReturnError(errDuplicate)
+
+	_, err := store.Claim(t.Context(), now, time.Minute, 10)
+	require.ErrorIs(t, err, errDuplicate)
+
+	// the creation is tried again after a failure, and only once it succeeded.
+	mock.ExpectExec(CreateTableSQL).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(due).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "payload", "run_at"}))
+	mock.ExpectQuery(due).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "payload", "run_at"}))
+
+	for range 2 {
+		claimed, err := store.Claim(t.Context(), now, time.Minute, 10)
+		require.NoError(t, err)
+		assert.Empty(t, claimed)
+	}
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLStore_Schedule(t *testing.T) {
+	store, mock := newSQLStore(t, "mysql")
+	runAt := time.UnixMilli(1700000000000)
+
+	mock.ExpectExec(CreateTableSQL).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	expectPurge := func() {
+		mock.ExpectExec("DELETE FROM kite_scheduled_tasks WHERE id = ? AND status = ? AND lease_until < ?").
+			WithArgs("t-1", statusDone, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+
+	expectPurge()
+	mock.ExpectExec("INSERT INTO kite_scheduled_tasks (id, name, payload, run_at, status, lease_until) VALUES (?, ?, ?, ?, ?, 0)").
+		WithArgs("t-1", "remind", `{"a":1}`, runAt.UnixMilli(), statusPending).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	scheduled, err := store.Schedule(t.Context(), Task{ID: "t-1", Name: "remind", Payload: []byte(`{"a":1}`), RunAt: runAt})
+	require.NoError(t, err)
+	assert.True(t, scheduled)
+
+	expectPurge()
+	mock.ExpectExec("INSERT INTO kite_scheduled_tasks (id, name, payload, run_at, status, lease_until) VALUES (?, ?, ?, ?, ?, 0)").
+		WillReturnError(errDuplicate)
+	mock.ExpectQuery("SELECT 1 FROM kite_scheduled_tasks WHERE id = ?").WithArgs("t-1").
+		WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+
+	scheduled, err = store.Schedule(t.Context(), Task{ID: "t-1", Name: "remind", RunAt: runAt})
+	require.NoError(t, err)
+	assert.False(t, scheduled)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLStore_Claim(t *testing.T) {
+	store, mock := newSQLStore(t, "postgres")
+	now := time.UnixMilli(1700000000000)
+
+	mock.ExpectExec(CreateTableSQL).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT id, name, payload, run_at FROM kite_scheduled_tasks "+
+		"WHERE (status = $1 AND run_at <= $2) OR (status = $3 AND lease_until <= $4) ORDER BY run_at LIMIT $5").
+		WithArgs(statusPending, now.UnixMilli(), statusRunning, now.UnixMilli(), 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "payload", "run_at"}).
+			AddRow("t-1", "remind", `{"a":1}`, now.UnixMilli()).
+			AddRow("t-2", "remind", nil, now.UnixMilli()))
+
+	claim := "UPDATE kite_scheduled_tasks SET status = $1, lease_until = $2 " +
+		"WHERE id = $3 AND (status = $4 OR (status = $5 AND lease_until <= $6))"
+
+	mock.ExpectExec(claim).
+		WithArgs(statusRunning, now.Add(time.Minute).UnixMilli(), "t-1", statusPending, statusRunning, now.UnixMilli()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// t-2 was claimed by another replica meanwhile.
+	mock.ExpectExec(claim).
+		WithArgs(statusRunning, now.Add(time.Minute).UnixMilli(), "t-2", statusPending, statusRunning, now.UnixMilli()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	claimed, err := store.Claim(t.Context(), now, time.Minute, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []Task{{ID: "t-1", Name: "remind", Payload: []byte(`{"a":1}`), RunAt: now}}, claimed)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLStore_Renew(t *testing.T) {
+	store, mock := newSQLStore(t, "postgres")
+	now := time.UnixMilli(1700000000000)
+
+	mock.ExpectExec("UPDATE kite_scheduled_tasks SET lease_until = $1 WHERE id = $2 AND status = $3").
+		WithArgs(now.Add(time.Minute).UnixMilli(), "t-1", statusRunning).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, store.Renew(t.Context(), "t-1", now, time.Minute))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLStore_Complete(t *testing.T) {
+	store, mock := newSQLStore(t, "mysql")
+
+	mock.ExpectExec("UPDATE kite_scheduled_tasks SET status = ?, lease_until = ? WHERE id = ?").
+		WithArgs(statusDone, sqlmock.AnyArg(), "t-1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM kite_scheduled_tasks WHERE status = ? AND lease_until < ?").
+		WithArgs(statusDone, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 3))
+
+	require.NoError(t, store.Complete(t.Context(), "t-1"))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,129 @@
+// Package tasks provides the stores of the one-shot tasks scheduled by an application, e.g. with app.ScheduleAt and
+// ctx.ScheduleIn, which run a named handler once at a given time.
+//
+// The Redis and SQL stores persist the tasks, so that they survive restarts, and let the replicas of an application
+// claim the due tasks atomically, so that each task is run by a single replica. A claimed task is leased, and its lease
+// renewed while it runs: if its replica dies before completing it, the task is claimed again once the lease expires.
+package tasks
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Retention is how long a completed task is remembered, during which a task with the same ID is not scheduled again.
+const Retention = 7 * 24 * time.Hour
+
+// Task is a one-shot task, run by the handler registered for its name from RunAt.
+type Task struct {
+	ID      string    `json:"id"`
+	Name    string    `json:"name"`
+	Payload []byte    `json:"payload,omitempty"`
+	RunAt   time.Time `json:"run_at"`
+}
+
+// Store abstracts the storage of the scheduled tasks.
+type Store interface {
+	// Schedule stores the task, to be claimed from its RunAt. It returns false, without error, if a task with the
+	// same ID is pending, running or was completed less than Retention ago.
+	Schedule(ctx context.Context, task Task) (bool, error)
+	// Claim returns up to limit tasks due at now, leased until now+lease: they are not returned by the next claims,
+	// of any replica, until they are completed or their lease expires.
+	Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Task, error)
+	// Renew extends the lease of the claimed task with the given id until now+lease, while it runs.
+	Renew(ctx context.Context, id string, now time.Time, lease time.Duration) error
+	// Complete marks the claimed task with the given id as completed.
+	Complete(ctx context.Context, id string) error
+}
+
+// memoryStore implements Store in memory.
+type memoryStore struct {
+	mu    sync.Mutex
+	tasks map[string]*memoryTask
+	// done holds the expiry of the completion records.
+	done map[string]time.Time
+}
+
+type memoryTask struct {
+	task       Task
+	leaseUntil time.Time
+}
+
+// NewMemoryStore creates a new in-memory store. The tasks do not survive restarts and are not shared between
+// replicas, it is meant for development and tests.
+func NewMemoryStore() Store {
+	return &memoryStore{tasks: make(map[string]*memoryTask), done: make(map[string]time.Time)}
+}
+
+func (m *memoryStore) Schedule(_ context.Context, task Task) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+
+	for id, expiresAt := range m.done {
+		if now.After(expiresAt) {
+			delete(m.done, id)
+		}
+	}
+
+	if _, ok := m.tasks[task.ID]; ok {
+		return false, nil
+	}
+
+	if _, ok := m.done[task.ID]; ok {
+		return false, nil
+	}
+
+	m.tasks[task.ID] = &memoryTask{task: task}
+
+	return true, nil
+}
+
+func (m *memoryStore) Claim(_ context.Context, now time.Time, lease time.Duration, limit int) ([]Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var due []*memoryTask
+
+	for _, t := range m.tasks {
+		if !t.task.RunAt.After(now) && !t.leaseUntil.After(now) {
+			due = append(due, t)
+		}
+	}
+
+	slices.SortFunc(due, func(a, b *memoryTask) int { return a.task.RunAt.Compare(b.task.RunAt) })
+
+	claimed := make([]Task, 0, min(limit, len(due)))
+
+	for _, t := range due[:min(limit, len(due))] {
+		t.leaseUntil = now.Add(lease)
+
+		claimed = append(claimed, t.task)
+	}
+
+	return claimed, nil
+}
+
+func (m *memoryStore) Renew(_ context.Context, id string, now time.Time, lease time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if t, ok := m.tasks[id]; ok {
+		t.leaseUntil = now.Add(lease)
+	}
+
+	return nil
+}
+
+func (m *memoryStore) Complete(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.tasks, id)
+	m.done[id] = time.Now().Add(Retention)
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package tasks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testStore runs the scenario shared by the stores: scheduling is idempotent, a task is claimed once while it is
+// leased or its lease renewed, again once its lease expired, and not anymore once completed.
+func testStore(t *testing.T, store Store) {
+	t.Helper()
+
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Millisecond)
+
+	task := Task{ID: "t-1", Name: "expire-trial", Payload: []byte(`{"user":"u-1"}`), RunAt: now.Add(-time.Minute)}
+
+	scheduled, err := store.Schedule(ctx, task)
+	require.NoError(t, err)
+	assert.True(t, scheduled)
+
+	scheduled, err = store.Schedule(ctx, task)
+	require.NoError(t, err)
+	assert.False(t, scheduled, "a pending task must not be scheduled twice")
+
+	_, err = store.Schedule(ctx, Task{ID: "t-2", Name: "remind", RunAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+
+	claimed, err := store.Claim(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, task.ID, claimed[0].ID)
+	assert.Equal(t, task.Name, claimed[0].Name)
+	assert.Equal(t, task.Payload, claimed[0].Payload)
+	assert.True(t, task.RunAt.Equal(claimed[0].RunAt))
+
+	claimed, err = store.Claim(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed, "a leased task must not be claimed again")
+
+	require.NoError(t, store.Renew(ctx, task.ID, now.Add(30*time.Second), 2*time.Minute))
+
+	claimed, err = store.Claim(ctx, now.Add(2*time.Minute), time.Minute, 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed, "a task whose lease was renewed must not be claimed again")
+
+	claimed, err = store.Claim(ctx, now.Add(3*time.Minute), time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1, "a task whose lease expired must be claimed again")
+
+	require.NoError(t, store.Complete(ctx, task.ID))
+	require.NoError(t, store.Renew(ctx, task.ID, now.Add(3*time.Minute), time.Hour), "renewing a completed task is a no-op")
+
+	claimed, err = store.Claim(ctx, now.Add(10*time.Minute), time.Minute, 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	scheduled, err = store.Schedule(ctx, task)
+	require.NoError(t, err)
+	assert.False(t, scheduled, "a completed task must not be scheduled again")
+
+	claimed, err = store.Claim(ctx, now.Add(2*time.Hour), time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, "t-2", claimed[0].ID)
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestRedisStore(t *testing.T) {
+	s := miniredis.RunT(t)
+
+	testStore(t, NewRedisStore(redis.NewClient(&redis.Options{Addr: s.Addr()})))
+
+	assert.True(t, s.Exists(redisDonePrefix+"t-1"))
+	assert.Equal(t, Retention, s.TTL(redisDonePrefix+"t-1"))
+}
+
+func TestMemoryStore_ClaimLimitAndOrder(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now()
+
+	for i, id := range []string{"c", "a", "b"} {
+		_, err := store.Schedule(t.Context(), Task{ID: id, Name: "n", RunAt: now.Add(time.Duration(i-10) * time.Second)})
+		require.NoError(t, err)
+	}
+
+	claimed, err := store.Claim(t.Context(), now, time.Minute, 2)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+	assert.Equal(t, "c", claimed[0].ID)
+	assert.Equal(t, "a", claimed[1].ID)
+}
@@ -10,15 +10,20 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"golang.org/x/sync/errgroup"
 
 	"github.com/sllt/kite/pkg/kite/config"
+	"github.com/sllt/kite/pkg/kite/config/secrets"
+	"github.com/sllt/kite/pkg/kite/http/middleware"
 	"github.com/sllt/kite/pkg/kite/infra"
 	"github.com/sllt/kite/pkg/kite/logging"
 	"github.com/sllt/kite/pkg/kite/metrics"
 	"github.com/sllt/kite/pkg/kite/migration"
 	"github.com/sllt/kite/pkg/kite/service"
+	"github.com/sllt/kite/pkg/kite/startup"
 )
 
 const (
@@ -36,8 +41,27 @@ type App struct {
 	httpServer   *httpServer
 	metricServer *metricServer
 
-	cmd  *cmd
-	cron *Crontab
+	// adminServer is the internal HTTP listener of HTTP_ADMIN_PORT, see Admin.
+	adminServer *httpServer
+	// adminAuth is the AdminAPIConfig.Auth given to UseAdminAPI, see adminAPIAuth.
+	adminAuth func(http.Handler) http.Handler
+
+	// grpcHealth updates the serving status of the gRPC services, see MonitorGRPCHealth.
+	grpcHealth *grpcHealthMonitor
+
+	cmd   *cmd
+	cron  *Crontab
+	tasks *taskScheduler
+
+	// commands are the one-off commands of a server application, see Command.
+	commands *cmd
+
+	// requestCapture is closed on shutdown, see UseRequestCapture.
+	requestCapture *middleware.RequestCapture
+
+	// eventOrigin identifies the instance of the application in the events it forwards, see ForwardEvents.
+	eventOrigin     string
+	eventOriginOnce sync.Once
 
 	// container is unexported because this is an internal implementation and applications are provided access to it via Context
 	container *infra.Container
@@ -45,8 +69,17 @@ type App struct {
 	grpcRegistered bool
 	httpRegistered bool
 
+	// draining is set while the application fails its readiness before shutting down, see READINESS_DRAIN_DELAY.
+	draining atomic.Bool
+
 	subscriptionManager SubscriptionManager
 	onStartHooks        []func(ctx *Context) error
+
+	// startupSteps are run concurrently before the application serves, see runStartup. startupDatasources are the
+	// names of the steps connecting the datasources, and startupErr the errors of the other steps.
+	startupSteps       startup.Graph
+	startupDatasources map[string]bool
+	startupErr         error
 }
 
 func (a *App) runOnStartHooks(ctx context.Context) error {
@@ -93,23 +126,45 @@ func (a *App) Shutdown(ctx context.Context) error {
 		err = errors.Join(err, a.httpServer.Shutdown(ctx))
 	}
 
+	if a.adminServer != nil {
+		err = errors.Join(err, a.adminServer.Shutdown(ctx))
+	}
+
 	if a.grpcServer != nil {
 		err = errors.Join(err, a.grpcServer.Shutdown(ctx))
 	}
 
+	if a.tasks != nil {
+		err = errors.Join(err, a.tasks.Close(ctx))
+	}
+
+	if a.requestCapture != nil {
+		err = errors.Join(err, a.requestCapture.Close())
+	}
+
 	if a.container != nil {
+		// the asynchronous event handlers may still use the datasources, which are closed next.
+		err = errors.Join(err, a.container.Events().Close(ctx))
 		err = errors.Join(err, a.container.Close())
 	}
 
+	// the secrets referenced by the config are watched for rotations until it is closed.
+	if secrets, ok := a.Config.(*config.SecretConfig); ok {
+		err = errors.Join(err, secrets.Close())
+	}
+
 	if a.metricServer != nil {
 		err = errors.Join(err, a.metricServer.Shutdown(ctx))
 	}
 
-	if err != nil {
-		return err
+	if err == nil {
+		a.container.Logger.Info("Application shutdown complete")
 	}
 
-	a.container.Logger.Info("Application shutdown complete")
+	// the logs written asynchronously are lost unless they are written out before the process exits.
+	if f, ok := a.container.Logger.(logging.Flusher); ok {
+		f.Flush()
+	}
 
 	return err
 }
@@ -139,17 +194,27 @@ func (a *App) httpServerSetup() {
 	}
 
 	// Register default routes - these are only added when HTTP server is actually starting
-	a.add(http.MethodGet, service.HealthPath, healthHandler)
+	a.add(http.MethodGet, service.HealthPath, a.readinessHandler)
 	a.add(http.MethodGet, service.AlivePath, liveHandler)
 	a.add(http.MethodGet, "/favicon.ico", faviconHandler)
 
 	// Add OpenAPI/Swagger routes if openapi.json exists
 	a.checkAndAddOpenAPIDocumentation()
 
+	// Routes registered twice for the same method and pattern would silently replace each other.
+	if err := a.httpServer.registry.checkConflicts(); err != nil {
+		a.container.Logger.Fatalf("%v", err)
+	}
+
+	a.setTemplateReload()
+
 	// Compile the route registry: walks the GroupNode tree and registers
 	// all routes and middleware onto the chi router.
+	a.httpServer.registry.errorMode = a.httpErrorMode()
 	a.httpServer.registry.compile(a.httpServer.router.Mux(), a.container, a.getRequestTimeout())
 
+	a.logRoutes(a.getRequestTimeout())
+
 	for dirName, endpoint := range a.httpServer.staticFiles {
 		a.httpServer.router.AddStaticFiles(a.Logger(), endpoint, dirName)
 	}
@@ -159,16 +224,12 @@ func (a *App) httpServerSetup() {
 		container: a.container,
 	})
 
-	var registeredMethods []string
-
-	_ = a.httpServer.router.Walk(func(method, route string) error {
-		if !contains(registeredMethods, method) {
-			registeredMethods = append(registeredMethods, method)
-		}
-		return nil
+	a.httpServer.router.MethodNotAllowed(handler{
+		function:  methodNotAllowedHandler,
+		container: a.container,
 	})
 
-	*a.httpServer.router.RegisteredRoutes = registeredMethods
+	a.httpServer.recordRegisteredMethods()
 }
 
 func (a *App) startSubscriptions(ctx context.Context) error {
@@ -178,11 +239,9 @@ func (a *App) startSubscriptions(ctx context.Context) error {
 
 	group := errgroup.Group{}
 	// Start subscribers concurrently using go-routines
-	for topic, handler := range a.subscriptionManager.subscriptions {
-		subscriberTopic, subscriberHandler := topic, handler
-
+	for _, sub := range a.subscriptionManager.subscriptions {
 		group.Go(func() error {
-			return a.subscriptionManager.startSubscriber(ctx, subscriberTopic, subscriberHandler)
+			return a.subscriptionManager.startSubscriber(ctx, sub)
 		})
 	}
 
@@ -197,13 +256,28 @@ func (a *App) readConfig(isAppCMD bool) {
 		location = configLocation
 	}
 
+	var logger logging.Logger
+
 	if isAppCMD {
-		a.Config = config.NewEnvFile(location, logging.NewFileLogger(""))
+		logger = logging.NewFileLogger("")
+	} else {
+		logger = logging.NewLogger(logging.INFO)
+	}
 
-		return
+	a.Config = withSecrets(config.NewEnvFile(location, logger), logger)
+}
+
+// withSecrets resolves the references to secrets in the values of conf, e.g. DB_PASSWORD=${secret:db_password},
+// with the provider selected by SECRETS_PROVIDER.
+func withSecrets(conf config.Config, logger logging.Logger) config.Config {
+	provider, err := secrets.New(conf)
+	if err != nil {
+		logger.Errorf("could not create the secrets provider: %v", err)
+
+		return conf
 	}
 
-	a.Config = config.NewEnvFile(location, logging.NewLogger(logging.INFO))
+	return config.WithSecrets(conf, provider, logger)
 }
 
 // AddHTTPService registers HTTP service in infra.
@@ -247,34 +321,86 @@ func (a *App) SubCommand(pattern string, handler Handler, options ...Options) {
 // Migrate applies a set of migrations to the application's database.
 //
 // The migrationsMap argument is a map where the key is the version number of the migration
-// and the value is a migration.Migrate instance that implements the migration logic.
+// and the value is a migration.Migrate instance that implements the migration logic. The migrations run can be
+// restricted to some datasources and tags with the MIGRATIONS_DATASOURCES and MIGRATIONS_TAGS configs, see
+// migration.Filter.
 func (a *App) Migrate(migrationsMap map[int64]migration.Migrate) {
 	// TODO : Move panic recovery at central location which will manage for all the different cases.
 	defer func() {
 		panicRecovery(recover(), a.container.Logger)
 	}()
 
-	migration.Run(migrationsMap, a.container)
+	// the datasources connecting concurrently must be up before the migrations run.
+	if err := a.runStartup(context.Background()); err != nil {
+		a.container.Errorf("startup failed: %v", err)
+	}
+
+	migration.RunFiltered(migrationsMap, a.container, migration.Filter{
+		Datasources: a.configList("MIGRATIONS_DATASOURCES"),
+		Tags:        a.configList("MIGRATIONS_TAGS"),
+	})
+}
+
+// MigrateSQL applies a set of migrations to the SQL connection added with AddSQL under the given name.
+// The migrations and their kite_migrations table are scoped to that connection only.
+func (a *App) MigrateSQL(name string, migrationsMap map[int64]migration.Migrate) {
+	defer func() {
+		panicRecovery(recover(), a.container.Logger)
+	}()
+
+	if err := a.runStartup(context.Background()); err != nil {
+		a.container.Errorf("startup failed: %v", err)
+	}
+
+	db := a.container.NamedSQL(name)
+	if db == nil {
+		a.container.Errorf("no migrations are running as SQL connection %q is not added", name)
+
+		return
+	}
+
+	migration.Run(migrationsMap, &infra.Container{Logger: a.container.Logger, SQL: db})
+}
+
+// Seed applies the seeds matching the APP_ENV config to the application's SQL database. It is meant to be
+// called after Migrate. Each seed is applied once, see migration.Seed.
+func (a *App) Seed(seeds []migration.Seed) {
+	defer func() {
+		panicRecovery(recover(), a.container.Logger)
+	}()
+
+	if err := a.runStartup(context.Background()); err != nil {
+		a.container.Errorf("startup failed: %v", err)
+	}
+
+	migration.RunSeeds(seeds, a.Config.Get("APP_ENV"), a.container)
 }
 
-// Subscribe registers a handler for the given topic.
+// Subscribe registers a handler for the given topic and returns the handle of the subscription, which can pause,
+// resume or close the subscriber at runtime.
 //
 // If the subscriber is not initialized in the container, an error is logged and
-// the subscription is not registered.
-func (a *App) Subscribe(topic string, handler SubscribeFunc) {
+// the subscription is not registered, nil is returned. The first subscription registers the per-topic
+// consumer metrics: messages consumed, processing duration, handler errors and consumer lag.
+func (a *App) Subscribe(topic string, handler SubscribeFunc) *Subscription {
 	if topic == "" || handler == nil {
 		a.container.Logger.Errorf("invalid subscription: topic and handler must not be empty or nil")
 
-		return
+		return nil
 	}
 
 	if a.container.GetSubscriber() == nil {
 		a.container.Logger.Errorf("subscriber not initialized in the container")
 
-		return
+		return nil
 	}
 
-	a.subscriptionManager.subscriptions[topic] = handler
+	sub := newSubscription(topic, handler)
+
+	a.subscriptionManager.registerMetrics(a.container.Metrics())
+	a.subscriptionManager.subscriptions[topic] = sub
+
+	return sub
 }
 
 // Use registers HTTP middleware (func(http.Handler) http.Handler) at the global level.
@@ -287,6 +413,101 @@ func (a *App) Use(middlewares ...func(http.Handler) http.Handler) {
 	a.httpServer.registry.root.httpMWs = append(a.httpServer.registry.root.httpMWs, middlewares...)
 }
 
+// AddAccessLogFields adds the fields returned by fields to the access log of each HTTP request, e.g. the plan of the
+// customer of the request read from its context. See ACCESS_LOG_SAMPLING to sample the access logs.
+func (a *App) AddAccessLogFields(fields func(r *http.Request) map[string]any) {
+	a.Use(middleware.AccessLogFields(fields))
+}
+
+// UseBodyLogging logs the bodies of the HTTP requests and of their responses, truncated and masked by the log
+// redactor, to debug the integrations with other services. The logs are disabled unless HTTP_BODY_LOG_ENABLED is
+// true, and are toggled at runtime with the returned logger, or with the admin endpoint when HTTP_ADMIN_PORT is set,
+// authenticated as the admin API, see UseAdminAPI:
+//
+//	curl -X PUT localhost:9001/body-logging -H 'X-Api-Key: <key>' -d '{"enabled":true}'
+func (a *App) UseBodyLogging(config middleware.BodyLogConfig) *middleware.BodyLogger {
+	bodyLogger := middleware.NewBodyLogger(config, a.container.Logger)
+
+	if enabled, err := strconv.ParseBool(a.Config.GetOrDefault("HTTP_BODY_LOG_ENABLED", "false")); err == nil {
+		bodyLogger.SetEnabled(enabled)
+	}
+
+	a.Use(bodyLogger.Handler)
+
+	if a.adminServer != nil {
+		a.Admin().Group("/body-logging").Use(a.requireAdminAuth).PUT("/", func(ctx *Context) (any, error) {
+			var toggle struct {
+				Enabled bool `json:"enabled"`
+			}
+
+			if err := ctx.Bind(&toggle); err != nil {
+				return nil, err
+			}
+
+			bodyLogger.SetEnabled(toggle.Enabled)
+			ctx.Infof("HTTP body logging enabled: %t", toggle.Enabled)
+
+			return toggle, nil
+		})
+	}
+
+	return bodyLogger
+}
+
+// UseRequestCapture keeps sanitized copies of the HTTP requests matching config, e.g. the ones failing with a 5xx
+// status, in memory and in config.File, to reproduce the bugs only seen in production by resending them to a local
+// build with `kite replay <file>`. The credentials headers are always masked, and the other headers, the query
+// parameters and the bodies are masked by config.Redactor, a logging.NewRedactor by default.
+//
+// The capture is disabled unless HTTP_CAPTURE_ENABLED is true, and is toggled at runtime with the returned capture,
+// or with the admin endpoints when HTTP_ADMIN_PORT is set, which also return the last captured requests. They are
+// authenticated as the admin API, see UseAdminAPI:
+//
+//	curl -X PUT localhost:9001/request-capture -H 'X-Api-Key: <key>' -d '{"enabled":true}'
+//	curl localhost:9001/request-capture -H 'X-Api-Key: <key>'
+func (a *App) UseRequestCapture(config middleware.CaptureConfig) (*middleware.RequestCapture, error) {
+	if config.Redactor == nil {
+		config.Redactor = logging.NewRedactor()
+	}
+
+	capture, err := middleware.NewRequestCapture(config, a.container.Logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if enabled, err := strconv.ParseBool(a.Config.GetOrDefault("HTTP_CAPTURE_ENABLED", "false")); err == nil {
+		capture.SetEnabled(enabled)
+	}
+
+	a.Use(capture.Handler)
+	a.requestCapture = capture
+
+	if a.adminServer != nil {
+		admin := a.Admin().Group("/request-capture").Use(a.requireAdminAuth)
+
+		admin.GET("/", func(*Context) (any, error) {
+			return capture.Captured(), nil
+		})
+
+		admin.PUT("/", func(ctx *Context) (any, error) {
+			var toggle struct {
+				Enabled bool `json:"enabled"`
+			}
+
+			if err := ctx.Bind(&toggle); err != nil {
+				return nil, err
+			}
+
+			capture.SetEnabled(toggle.Enabled)
+			ctx.Infof("HTTP request capture enabled: %t", toggle.Enabled)
+
+			return toggle, nil
+		})
+	}
+
+	return capture, nil
+}
+
 // UseMiddleware registers KiteMiddleware that runs at the application layer with *Context access.
 // This is a BREAKING CHANGE: the signature changed from func(http.Handler) http.Handler
 // to func(next Handler) Handler (KiteMiddleware).
@@ -311,6 +532,22 @@ func (a *App) UseMiddlewareWithContainer(middlewareHandler func(c *infra.Contain
 	})
 }
 
+// UseLogRedactor replaces the redactor masking sensitive data in the request, SQL and pub/sub logs, configured by
+// default from LOG_REDACT_FIELDS and LOG_REDACT_PATTERN. Custom sanitizers are added with Redactor.AddSanitizer:
+//
+//	app.UseLogRedactor(logging.NewRedactor().RedactFields("ssn").AddSanitizer(maskEmails))
+//
+// A nil redactor disables masking.
+func (a *App) UseLogRedactor(r *logging.Redactor) {
+	rc, ok := a.container.Logger.(logging.RedactorConfigurer)
+	if !ok {
+		a.container.Logger.Error("the logger does not support redaction")
+		return
+	}
+
+	rc.SetRedactor(r)
+}
+
 // Group creates or gets a route group with the given prefix and returns it.
 // An optional callback can be provided for backward-compatible inline registration.
 func (a *App) Group(prefix string, fns ...func(sub *RouteGroup)) *RouteGroup {
@@ -393,7 +630,7 @@ func contains(elems []string, v string) bool {
 // If `filePath` starts with "./", it will be interpreted as a relative path
 // to the current working directory.
 func (a *App) AddStaticFiles(endpoint, filePath string) {
-	if !a.httpRegistered && !isPortAvailable(a.httpServer.port) {
+	if !a.httpRegistered && a.httpServer.bind.usesPort() && !isPortAvailable(a.httpServer.port) {
 		a.container.Logger.Fatalf("http port %d is blocked or unreachable", a.httpServer.port)
 	}
 
@@ -338,6 +338,18 @@ func TestApp_MigratePanicRecovery(t *testing.T) {
 	assert.Contains(t, logs, "test panic")
 }
 
+func TestApp_RunMigrationsOnly(t *testing.T) {
+	logs := testutil.StdoutOutputForFunc(func() {
+		testutil.NewServerConfigs(t)
+		t.Setenv("MIGRATIONS_ONLY", "true")
+
+		app := New()
+		app.Run()
+	})
+
+	assert.Contains(t, logs, "Exiting after the migrations as MIGRATIONS_ONLY is set")
+}
+
 func Test_otelErrorHandler(t *testing.T) {
 	logs := testutil.StderrOutputForFunc(func() {
 		h := otelErrorHandler{
@@ -0,0 +1,183 @@
+package kite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sllt/kite/pkg/kite/config"
+	"github.com/sllt/kite/pkg/kite/logging"
+)
+
+const (
+	defaultSocketMode os.FileMode = 0o660
+
+	activatedHTTP = "http"
+	activatedGRPC = "grpc"
+)
+
+var (
+	// listenFDsStart is the first file descriptor passed by systemd socket activation, SD_LISTEN_FDS_START.
+	listenFDsStart = 3
+
+	errInvalidFileDescriptor = errors.New("invalid file descriptor")
+)
+
+// serverListener is where a server accepts its connections: a listener passed by systemd socket activation, else a
+// Unix domain socket, else its TCP port.
+type serverListener struct {
+	activated  net.Listener
+	socket     string
+	socketMode os.FileMode
+}
+
+// newServerListener returns the listener of the server whose configs are prefixed by prefix, e.g. HTTP_SOCKET and
+// HTTP_SOCKET_MODE for "HTTP". The activated listener, if any, takes precedence over the socket.
+func newServerListener(configs config.Config, prefix string, activated net.Listener, logger logging.Logger) serverListener {
+	l := serverListener{
+		activated:  activated,
+		socket:     configs.Get(prefix + "_SOCKET"),
+		socketMode: defaultSocketMode,
+	}
+
+	if value := configs.Get(prefix + "_SOCKET_MODE"); value != "" {
+		mode, err := strconv.ParseUint(value, 8, 32)
+		if err != nil || mode > 0o777 {
+			logger.Errorf("invalid %s_SOCKET_MODE %q, using %#o", prefix, value, defaultSocketMode)
+		} else {
+			l.socketMode = os.FileMode(mode)
+		}
+	}
+
+	return l
+}
+
+// usesPort reports whether the server listens on its TCP port, which is then checked to be available.
+func (l serverListener) usesPort() bool {
+	return l.activated == nil && l.socket == ""
+}
+
+// address returns the address the server listens on, for the logs.
+func (l serverListener) address(port int) string {
+	switch {
+	case l.activated != nil:
+		return l.activated.Addr().String()
+	case l.socket != "":
+		return "unix:" + l.socket
+	default:
+		return ":" + strconv.Itoa(port)
+	}
+}
+
+// listen returns the listener of the server. A stale socket file left by a previous run is replaced, and the socket
+// is given the permissions of its mode.
+func (l serverListener) listen(port int) (net.Listener, error) {
+	if l.activated != nil {
+		return l.activated, nil
+	}
+
+	if l.socket == "" {
+		return (&net.ListenConfig{}).Listen(context.Background(), "tcp", ":"+strconv.Itoa(port))
+	}
+
+	if info, err := os.Stat(l.socket); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(l.socket); err != nil {
+			return nil, fmt.Errorf("removing stale socket %s: %w", l.socket, err)
+		}
+	}
+
+	listener, err := (&net.ListenConfig{}).Listen(context.Background(), "unix", l.socket)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Chmod(l.socket, l.socketMode); err != nil {
+		listener.Close()
+
+		return nil, fmt.Errorf("setting the permissions of socket %s: %w", l.socket, err)
+	}
+
+	return listener, nil
+}
+
+// activatedListeners returns the listeners passed by systemd socket activation with LISTEN_PID, LISTEN_FDS and
+// LISTEN_FDNAMES, keyed by the server they are for. The sockets named "http" or "grpc" with FileDescriptorName are
+// for that server, the unnamed ones are for the HTTP server and then the gRPC server, in their order. The variables
+// are unset, so that the child processes do not take the listeners as their own.
+func activatedListeners(logger logging.Logger) map[string]net.Listener {
+	pid, count, names := os.Getenv("LISTEN_PID"), os.Getenv("LISTEN_FDS"), os.Getenv("LISTEN_FDNAMES")
+	if count == "" {
+		return nil
+	}
+
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	if pid != strconv.Itoa(os.Getpid()) {
+		return nil
+	}
+
+	n, err := strconv.Atoi(count)
+	if err != nil || n <= 0 {
+		logger.Errorf("invalid LISTEN_FDS %q, socket activation is ignored", count)
+
+		return nil
+	}
+
+	fdNames := strings.Split(names, ":")
+	listeners := make(map[string]net.Listener, n)
+
+	var unnamed []net.Listener
+
+	for i := range n {
+		var name string
+		if i < len(fdNames) {
+			name = fdNames[i]
+		}
+
+		listener, err := fileListener(listenFDsStart+i, name)
+		if err != nil {
+			logger.Errorf("socket activation: file descriptor %d is not a listener: %v", listenFDsStart+i, err)
+
+			continue
+		}
+
+		if _, taken := listeners[name]; (name == activatedHTTP || name == activatedGRPC) && !taken {
+			listeners[name] = listener
+
+			continue
+		}
+
+		unnamed = append(unnamed, listener)
+	}
+
+	for _, name := range []string{activatedHTTP, activatedGRPC} {
+		if _, ok := listeners[name]; !ok && len(unnamed) > 0 {
+			listeners[name], unnamed = unnamed[0], unnamed[1:]
+		}
+	}
+
+	for _, listener := range unnamed {
+		logger.Warnf("socket activation: listener %s is not used by any server", listener.Addr())
+		listener.Close()
+	}
+
+	return listeners
+}
+
+// fileListener returns the listener of the file descriptor fd, which is closed as the listener holds a copy of it.
+func fileListener(fd int, name string) (net.Listener, error) {
+	f := os.NewFile(uintptr(fd), name)
+	if f == nil {
+		return nil, errInvalidFileDescriptor
+	}
+
+	defer f.Close()
+
+	return net.FileListener(f)
+}
@@ -0,0 +1,153 @@
+package kite
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sllt/kite/pkg/kite/config"
+	"github.com/sllt/kite/pkg/kite/logging"
+	"github.com/sllt/kite/pkg/kite/testutil"
+)
+
+func TestServerListener_Socket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "kite.sock")
+
+	l := newServerListener(config.NewMockConfig(map[string]string{
+		"HTTP_SOCKET":      socket,
+		"HTTP_SOCKET_MODE": "600",
+	}), "HTTP", nil, logging.NewMockLogger(logging.ERROR))
+
+	assert.False(t, l.usesPort())
+	assert.Equal(t, "unix:"+socket, l.address(8000))
+
+	// a socket left by a previous run does not prevent listening.
+	stale, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	listener, err := l.listen(8000)
+	require.NoError(t, err)
+
+	defer listener.Close()
+
+	info, err := os.Stat(socket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestServerListener_Config(t *testing.T) {
+	logger := logging.NewMockLogger(logging.FATAL)
+
+	l := newServerListener(config.NewMockConfig(map[string]string{"GRPC_SOCKET_MODE": "rw"}), "GRPC", nil, logger)
+
+	assert.True(t, l.usesPort())
+	assert.Equal(t, ":9000", l.address(9000))
+	assert.Equal(t, defaultSocketMode, l.socketMode)
+
+	activated, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	defer activated.Close()
+
+	l = newServerListener(config.NewMockConfig(map[string]string{"GRPC_SOCKET": "/tmp/grpc.sock"}), "GRPC", activated, logger)
+
+	assert.False(t, l.usesPort())
+	assert.Equal(t, activated.Addr().String(), l.address(9000))
+
+	listener, err := l.listen(9000)
+	require.NoError(t, err)
+	assert.Equal(t, activated, listener)
+}
+
+func TestActivatedListeners(t *testing.T) {
+	tcp, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	defer tcp.Close()
+
+	f, err := tcp.(*net.TCPListener).File()
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	start := listenFDsStart
+	listenFDsStart = int(f.Fd())
+
+	t.Cleanup(func() { listenFDsStart = start })
+
+	logger := logging.NewMockLogger(logging.ERROR)
+
+	t.Setenv("LISTEN_PID", "1")
+	t.Setenv("LISTEN_FDS", "1")
+	assert.Nil(t, activatedListeners(logger), "the listeners are passed to another process")
+	assert.Empty(t, os.Getenv("LISTEN_FDS"))
+
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	t.Setenv("LISTEN_FDS", "1")
+	t.Setenv("LISTEN_FDNAMES", "grpc")
+
+	listeners := activatedListeners(logger)
+	require.Len(t, listeners, 1)
+	require.Contains(t, listeners, activatedGRPC)
+
+	assert.Equal(t, tcp.Addr().String(), listeners[activatedGRPC].Addr().String())
+	assert.Empty(t, os.Getenv("LISTEN_PID"))
+	assert.Empty(t, os.Getenv("LISTEN_FDNAMES"))
+
+	listeners[activatedGRPC].Close()
+}
+
+func TestApp_HTTPSocket(t *testing.T) {
+	testutil.NewServerConfigs(t)
+
+	socket := filepath.Join(t.TempDir(), "http.sock")
+
+	t.Setenv("HTTP_SOCKET", socket)
+
+	app := New()
+
+	app.GET("/hello", func(*Context) (any, error) {
+		return helloWorld, nil
+	})
+
+	go app.Run()
+
+	t.Cleanup(func() { _ = app.Shutdown(t.Context()) })
+
+	time.Sleep(100 * time.Millisecond)
+
+	client := &http.Client{
+		Timeout: time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://kite/hello", http.NoBody)
+	require.NoError(t, err)
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), helloWorld)
+}
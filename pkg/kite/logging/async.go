@@ -0,0 +1,189 @@
+package logging
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const defaultAsyncBufferSize = 1024
+
+// OverflowPolicy decides what an AsyncWriter does with a log entry written while its buffer is full.
+type OverflowPolicy int
+
+const (
+	// DropOldest drops the oldest buffered entry to make room for the new one, so that logging never waits.
+	DropOldest OverflowPolicy = iota
+	// Block waits for the background writer to make room, so that no entry is lost.
+	Block
+)
+
+// GetOverflowPolicyFromString returns the OverflowPolicy named "drop-oldest" or "block", DropOldest by default.
+func GetOverflowPolicyFromString(policy string) OverflowPolicy {
+	if strings.EqualFold(strings.TrimSpace(policy), "block") {
+		return Block
+	}
+
+	return DropOldest
+}
+
+// AsyncWriter buffers the log entries in a bounded ring buffer and writes them to the underlying writer from a
+// background goroutine, so that a slow stdout or disk does not add to the latency of the requests. Each Write is
+// expected to be a whole log entry.
+type AsyncWriter struct {
+	out    io.Writer
+	policy OverflowPolicy
+	onDrop func()
+
+	mu       sync.Mutex
+	notEmpty *sync.Cond
+	// changed is signaled when entries are written out, for the writers waiting for room and for Flush.
+	changed *sync.Cond
+	entries [][]byte
+	head    int
+	size    int
+	writing bool
+	closed  bool
+	done    chan struct{}
+
+	dropped atomic.Uint64
+}
+
+// NewAsyncWriter returns an AsyncWriter buffering up to size entries for out. onDrop, if not nil, is called each
+// time an entry is dropped by the DropOldest policy.
+func NewAsyncWriter(out io.Writer, size int, policy OverflowPolicy, onDrop func()) *AsyncWriter {
+	if size <= 0 {
+		size = defaultAsyncBufferSize
+	}
+
+	w := &AsyncWriter{
+		out:     out,
+		policy:  policy,
+		onDrop:  onDrop,
+		entries: make([][]byte, size),
+		done:    make(chan struct{}),
+	}
+
+	w.notEmpty = sync.NewCond(&w.mu)
+	w.changed = sync.NewCond(&w.mu)
+
+	go w.run()
+
+	return w
+}
+
+// Write buffers a copy of p. It never fails, the errors of the underlying writer are ignored as there is nowhere
+// left to report them.
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+	entry := append([]byte(nil), p...)
+	dropped := false
+
+	w.mu.Lock()
+
+	if w.closed {
+		w.mu.Unlock()
+
+		return w.out.Write(p)
+	}
+
+	for w.size == len(w.entries) {
+		if w.policy == DropOldest {
+			w.head = (w.head + 1) % len(w.entries)
+			w.size--
+			dropped = true
+
+			break
+		}
+
+		w.changed.Wait()
+	}
+
+	w.entries[(w.head+w.size)%len(w.entries)] = entry
+	w.size++
+
+	w.notEmpty.Signal()
+	w.mu.Unlock()
+
+	if dropped {
+		w.dropped.Add(1)
+
+		if w.onDrop != nil {
+			w.onDrop()
+		}
+	}
+
+	return len(p), nil
+}
+
+// run writes out the buffered entries until the writer is closed.
+func (w *AsyncWriter) run() {
+	defer close(w.done)
+
+	batch := make([][]byte, 0, len(w.entries))
+
+	for {
+		w.mu.Lock()
+
+		for w.size == 0 && !w.closed {
+			w.notEmpty.Wait()
+		}
+
+		if w.size == 0 && w.closed {
+			w.mu.Unlock()
+
+			return
+		}
+
+		batch = batch[:0]
+
+		for w.size > 0 {
+			batch = append(batch, w.entries[w.head])
+			w.entries[w.head] = nil
+			w.head = (w.head + 1) % len(w.entries)
+			w.size--
+		}
+
+		w.writing = true
+		w.changed.Broadcast()
+		w.mu.Unlock()
+
+		for _, entry := range batch {
+			_, _ = w.out.Write(entry)
+		}
+
+		w.mu.Lock()
+		w.writing = false
+		w.changed.Broadcast()
+		w.mu.Unlock()
+	}
+}
+
+// Flush waits until the buffered entries are written to the underlying writer.
+func (w *AsyncWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for (w.size > 0 || w.writing) && !w.closed {
+		w.changed.Wait()
+	}
+}
+
+// Close writes out the buffered entries and stops the background goroutine. The entries written afterward are
+// written synchronously.
+func (w *AsyncWriter) Close() error {
+	w.mu.Lock()
+	w.closed = true
+	w.notEmpty.Signal()
+	w.changed.Broadcast()
+	w.mu.Unlock()
+
+	<-w.done
+
+	return nil
+}
+
+// Dropped returns the number of entries dropped by the DropOldest policy.
+func (w *AsyncWriter) Dropped() uint64 {
+	return w.dropped.Load()
+}
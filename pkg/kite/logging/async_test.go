@@ -0,0 +1,141 @@
+package logging
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gatedWriter blocks its writes until the gate is opened.
+type gatedWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	gate    chan struct{}
+	writing chan struct{}
+}
+
+func newGatedWriter() *gatedWriter {
+	return &gatedWriter{gate: make(chan struct{}), writing: make(chan struct{}, 100)}
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	w.writing <- struct{}{}
+	<-w.gate
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.buf.Write(p)
+}
+
+func (w *gatedWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.buf.String()
+}
+
+func TestAsyncWriter_WritesInOrder(t *testing.T) {
+	var out bytes.Buffer
+
+	w := NewAsyncWriter(&out, 4, Block, nil)
+
+	for _, entry := range []string{"a\n", "b\n", "c\n", "d\n", "e\n", "f\n"} {
+		n, err := w.Write([]byte(entry))
+
+		require.NoError(t, err)
+		assert.Equal(t, len(entry), n)
+	}
+
+	w.Flush()
+
+	assert.Equal(t, "a\nb\nc\nd\ne\nf\n", out.String())
+	require.NoError(t, w.Close())
+}
+
+func TestAsyncWriter_DropOldest(t *testing.T) {
+	out := newGatedWriter()
+	drops := 0
+
+	w := NewAsyncWriter(out, 2, DropOldest, func() { drops++ })
+
+	_, _ = w.Write([]byte("1\n"))
+	<-out.writing // the background writer is stuck writing 1
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for _, entry := range []string{"2\n", "3\n", "4\n"} {
+			_, _ = w.Write([]byte(entry))
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the writes must not wait for a slow output")
+	}
+
+	close(out.gate)
+	w.Flush()
+
+	assert.Equal(t, "1\n3\n4\n", out.String())
+	assert.Equal(t, uint64(1), w.Dropped())
+	assert.Equal(t, 1, drops)
+}
+
+func TestAsyncWriter_Block(t *testing.T) {
+	out := newGatedWriter()
+
+	w := NewAsyncWriter(out, 1, Block, nil)
+
+	_, _ = w.Write([]byte("1\n"))
+	<-out.writing
+
+	_, _ = w.Write([]byte("2\n"))
+
+	written := make(chan struct{})
+
+	go func() {
+		_, _ = w.Write([]byte("3\n"))
+		close(written)
+	}()
+
+	select {
+	case <-written:
+		t.Fatal("the write must wait for room in the buffer")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(out.gate)
+	<-written
+	w.Flush()
+
+	assert.Equal(t, "1\n2\n3\n", out.String())
+	assert.Zero(t, w.Dropped())
+}
+
+func TestAsyncWriter_Close(t *testing.T) {
+	var out bytes.Buffer
+
+	w := NewAsyncWriter(&out, 0, DropOldest, nil)
+
+	_, _ = w.Write([]byte("buffered\n"))
+	require.NoError(t, w.Close())
+
+	_, _ = w.Write([]byte("after close\n"))
+
+	assert.Equal(t, "buffered\nafter close\n", out.String())
+}
+
+func TestGetOverflowPolicyFromString(t *testing.T) {
+	assert.Equal(t, Block, GetOverflowPolicyFromString(" BLOCK "))
+	assert.Equal(t, DropOldest, GetOverflowPolicyFromString("drop-oldest"))
+	assert.Equal(t, DropOldest, GetOverflowPolicyFromString(""))
+}
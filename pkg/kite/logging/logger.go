@@ -1,12 +1,14 @@
 package logging
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/term"
@@ -50,6 +52,9 @@ type logger struct {
 	isTerminal bool
 	showCaller bool
 	lock       chan struct{}
+	redactor   atomic.Pointer[Redactor]
+	// async holds the AsyncWriters of the outputs, it is empty unless SetAsync is called.
+	async []*AsyncWriter
 }
 
 type logEntry struct {
@@ -91,13 +96,20 @@ func (l *logger) logfWithSkip(skip int, level Level, format string, args ...any)
 	traceID, filteredArgs := extractTraceIDAndFilterArgs(args)
 	entry.TraceID = traceID
 
+	redactor := l.redactor.Load()
+	if redactor != nil {
+		for i, arg := range filteredArgs {
+			filteredArgs[i] = redactor.message(arg)
+		}
+	}
+
 	switch {
 	case len(filteredArgs) == 1 && format == "":
 		entry.Message = filteredArgs[0]
 	case len(filteredArgs) != 1 && format == "":
 		entry.Message = filteredArgs
 	case format != "":
-		entry.Message = fmt.Sprintf(format, filteredArgs...)
+		entry.Message = redactor.String(fmt.Sprintf(format, filteredArgs...))
 	}
 
 	if l.isTerminal {
@@ -159,6 +171,8 @@ func (l *logger) Fatal(args ...any) {
 	l.logf(FATAL, "", args...)
 
 	// Flush output before exiting
+	l.Flush()
+
 	if f, ok := l.errorOut.(*os.File); ok {
 		_ = f.Sync() // Ignore sync error as we're about to exit
 	}
@@ -171,6 +185,8 @@ func (l *logger) Fatalf(format string, args ...any) {
 	l.logf(FATAL, format, args...)
 
 	// Flush output before exiting
+	l.Flush()
+
 	if f, ok := l.errorOut.(*os.File); ok {
 		_ = f.Sync() // Ignore sync error as we're about to exit
 	}
@@ -189,6 +205,15 @@ func (l *logger) prettyPrint(e *logEntry, out io.Writer) {
 		<-l.lock // Release the channel's token
 	}()
 
+	// the entry is written at once, so that an asynchronous output buffers it as a whole.
+	buf := &bytes.Buffer{}
+
+	defer func(w io.Writer) {
+		_, _ = w.Write(buf.Bytes())
+	}(out)
+
+	out = buf
+
 	// Pretty printing if the message interface defines a method PrettyPrint else print the log message
 	// This decouples the logger implementation from its usage
 	fmt.Fprintf(out, "\u001B[38;5;%dm%s\u001B[0m [%s]", e.Level.color(), e.Level.String()[0:4], e.Time.Format(time.TimeOnly))
@@ -251,6 +276,35 @@ func NewFileLogger(path string) Logger {
 	return l
 }
 
+// Formats of the log entries accepted by NewWriterLogger.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// NewWriterLogger creates a new Logger instance that writes the logs of the given level and above to w, pretty printed
+// with FormatText and encoded as JSON with FormatJSON. With an empty format the logs are pretty printed only when w
+// is a terminal.
+func NewWriterLogger(w io.Writer, level Level, format string) Logger {
+	l := &logger{
+		level:     level,
+		normalOut: w,
+		errorOut:  w,
+		lock:      make(chan struct{}, 1),
+	}
+
+	switch format {
+	case FormatText:
+		l.isTerminal = true
+	case FormatJSON:
+		l.isTerminal = false
+	default:
+		l.isTerminal = checkIfTerminal(w)
+	}
+
+	return l
+}
+
 func checkIfTerminal(w io.Writer) bool {
 	// Force JSON output in test environments
 	if os.Getenv("KITE_EXITER") == "1" {
@@ -276,12 +330,69 @@ func (l *logger) SetShowCaller(show bool) {
 	l.showCaller = show
 }
 
+// SetFormat pretty prints the logs with FormatText and encodes them as JSON with FormatJSON. Other formats are ignored.
+func (l *logger) SetFormat(format string) {
+	switch format {
+	case FormatText:
+		l.isTerminal = true
+	case FormatJSON:
+		l.isTerminal = false
+	}
+}
+
+// SetRedactor sets the Redactor masking sensitive data in the log messages. A nil Redactor disables masking.
+func (l *logger) SetRedactor(r *Redactor) {
+	l.redactor.Store(r)
+}
+
+// SetAsync makes the logger write its logs from a background goroutine through an AsyncWriter buffering up to size
+// entries per output. onDrop is called for each entry dropped by the DropOldest policy.
+func (l *logger) SetAsync(size int, policy OverflowPolicy, onDrop func()) {
+	if len(l.async) > 0 {
+		return
+	}
+
+	normalOut := NewAsyncWriter(l.normalOut, size, policy, onDrop)
+	l.async = append(l.async, normalOut)
+
+	errorOut := normalOut
+	if l.errorOut != l.normalOut {
+		errorOut = NewAsyncWriter(l.errorOut, size, policy, onDrop)
+		l.async = append(l.async, errorOut)
+	}
+
+	l.normalOut, l.errorOut = normalOut, errorOut
+}
+
+// Flush waits until the logs written asynchronously are written to the outputs.
+func (l *logger) Flush() {
+	for _, w := range l.async {
+		w.Flush()
+	}
+}
+
+// AsyncConfigurer is an optional interface for loggers which can write their logs asynchronously, see AsyncWriter.
+type AsyncConfigurer interface {
+	SetAsync(size int, policy OverflowPolicy, onDrop func())
+}
+
+// Flusher is an optional interface for loggers which buffer their logs, e.g. to flush them on shutdown.
+type Flusher interface {
+	Flush()
+}
+
 // CallerConfigurer is an optional interface for loggers that support
 // enabling/disabling caller information in log output.
 type CallerConfigurer interface {
 	SetShowCaller(show bool)
 }
 
+// FormatConfigurer is an optional interface for loggers whose format, FormatText or FormatJSON, can be set instead of
+// depending on whether they write to a terminal.
+type FormatConfigurer interface {
+	SetFormat(format string)
+}
+
 // LogLevelResponder provides a method to get the log level.
 type LogLevelResponder interface {
 	LogLevel() Level
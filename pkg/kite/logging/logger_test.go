@@ -281,3 +281,69 @@ func TestNewFileLogger_NilPath(t *testing.T) {
 	assert.Equal(t, io.Discard, logger.normalOut)
 	assert.Equal(t, io.Discard, logger.errorOut)
 }
+
+func TestNewWriterLogger(t *testing.T) {
+	var b bytes.Buffer
+
+	l := NewWriterLogger(&b, WARN, FormatJSON)
+	l.Info("hidden")
+	l.Warn("shown")
+
+	assert.NotContains(t, b.String(), "hidden")
+	assert.Contains(t, b.String(), `"message":"shown"`)
+
+	b.Reset()
+
+	l = NewWriterLogger(&b, DEBUG, FormatText)
+	l.Debug("pretty")
+
+	assert.Contains(t, b.String(), "DEBU")
+	assert.Contains(t, b.String(), "pretty")
+	assert.NotContains(t, b.String(), `"message"`)
+
+	b.Reset()
+
+	l = NewWriterLogger(&b, DEBUG, "")
+	l.Error("auto")
+
+	assert.Contains(t, b.String(), `"message":"auto"`, "logs are encoded as JSON when not written to a terminal")
+}
+
+func TestLogger_SetFormat(t *testing.T) {
+	var b bytes.Buffer
+
+	l := NewWriterLogger(&b, DEBUG, "")
+	l.(FormatConfigurer).SetFormat(FormatText)
+	l.Info("pretty")
+
+	assert.NotContains(t, b.String(), `"message"`, "the format overrides the detection of the terminal")
+
+	b.Reset()
+
+	l.(FormatConfigurer).SetFormat(FormatJSON)
+	l.(FormatConfigurer).SetFormat("yaml")
+	l.Info("json")
+
+	assert.Contains(t, b.String(), `"message":"json"`, "unknown formats are ignored")
+}
+
+func TestLogger_SetAsync(t *testing.T) {
+	out := newGatedWriter()
+
+	l := NewWriterLogger(out, DEBUG, FormatText)
+	l.(AsyncConfigurer).SetAsync(16, DropOldest, nil)
+
+	l.Info("first")
+	<-out.writing
+
+	l.Warnf("second %d", 2)
+
+	assert.Empty(t, out.String(), "logging must not wait for the output")
+
+	close(out.gate)
+	l.(Flusher).Flush()
+
+	assert.Contains(t, out.String(), "first")
+	assert.Contains(t, out.String(), "second 2")
+	assert.Equal(t, 2, strings.Count(out.String(), "\n"), "each entry must be written as a whole")
+}
@@ -0,0 +1,282 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// RedactedValue replaces the values masked by a Redactor.
+const RedactedValue = "[REDACTED]"
+
+var (
+	// defaultRedactedFields are the field names masked by NewRedactor, compared after normalization, see RedactFields.
+	defaultRedactedFields = []string{"password", "passwd", "secret", "token", "authorization", "apikey", "cardnumber", "cvv"}
+
+	// cardNumberPattern matches 13 to 19 digits, optionally grouped by spaces or dashes. Matches are only masked
+	// when they pass the Luhn check, so that IDs and timestamps are kept.
+	cardNumberPattern = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+)
+
+// Sanitizer masks the value of a logged field. field is the name of the field, or empty when the value has no
+// name, e.g. a SQL argument or a log message. It returns the value to log.
+type Sanitizer func(field, value string) string
+
+// Redactable is implemented by the structured log messages carrying user data, e.g. request, SQL and pub/sub logs.
+// Redact returns a copy of the message with its data masked by r.
+type Redactable interface {
+	Redact(r *Redactor) any
+}
+
+// RedactorConfigurer is an optional interface for loggers that support masking sensitive data before it is written.
+type RedactorConfigurer interface {
+	SetRedactor(r *Redactor)
+}
+
+// Redactor masks sensitive data in log messages: values of fields whose name matches a redacted field name, and
+// parts of values matching a redacted pattern. Custom sanitizers run after the built-in rules.
+//
+// A Redactor must be fully configured before it is set on a logger. The methods of a nil Redactor return their
+// input unchanged.
+type Redactor struct {
+	fields      []string
+	patterns    []*regexp.Regexp
+	cardNumbers bool
+	sanitizers  []Sanitizer
+}
+
+// NewRedactor returns a Redactor masking the fields named like passwords, secrets, tokens, authorization headers,
+// API keys and card numbers, and the card numbers found in any value.
+func NewRedactor() *Redactor {
+	r := &Redactor{cardNumbers: true}
+
+	return r.RedactFields(defaultRedactedFields...)
+}
+
+// RedactFields masks the values of the fields whose name contains one of names. Names are compared ignoring case,
+// dashes and underscores, so "api_key" matches "apiKey", "X-Api-Key" and "api_key_id".
+func (r *Redactor) RedactFields(names ...string) *Redactor {
+	for _, name := range names {
+		if name = normalizeFieldName(name); name != "" {
+			r.fields = append(r.fields, name)
+		}
+	}
+
+	return r
+}
+
+// RedactPatterns masks the parts of values matching one of patterns.
+func (r *Redactor) RedactPatterns(patterns ...*regexp.Regexp) *Redactor {
+	r.patterns = append(r.patterns, patterns...)
+
+	return r
+}
+
+// AddSanitizer adds a custom sanitizer, called for every string value after the built-in rules.
+func (r *Redactor) AddSanitizer(s Sanitizer) *Redactor {
+	r.sanitizers = append(r.sanitizers, s)
+
+	return r
+}
+
+// IsRedactedField reports whether the values of the field name are masked.
+func (r *Redactor) IsRedactedField(name string) bool {
+	if r == nil {
+		return false
+	}
+
+	name = normalizeFieldName(name)
+
+	for _, field := range r.fields {
+		if strings.Contains(name, field) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String masks the parts of s matching the redacted patterns.
+func (r *Redactor) String(s string) string {
+	return r.value("", s)
+}
+
+// Field masks the value of the field name. Maps and slices are masked recursively, other values that are
+// not strings are returned unchanged unless the field itself is redacted.
+func (r *Redactor) Field(name string, value any) any {
+	if r == nil || value == nil {
+		return value
+	}
+
+	if name != "" && r.IsRedactedField(name) {
+		return RedactedValue
+	}
+
+	switch v := value.(type) {
+	case string:
+		return r.value(name, v)
+	case map[string]any:
+		return r.Map(v)
+	case map[string]string:
+		m := make(map[string]string, len(v))
+
+		for key, val := range v {
+			m[key], _ = r.Field(key, val).(string)
+		}
+
+		return m
+	case []any:
+		s := make([]any, len(v))
+
+		for i, val := range v {
+			s[i] = r.Field(name, val)
+		}
+
+		return s
+	default:
+		return value
+	}
+}
+
+// Map returns a copy of m with the values of its fields masked.
+func (r *Redactor) Map(m map[string]any) map[string]any {
+	if r == nil || m == nil {
+		return m
+	}
+
+	masked := make(map[string]any, len(m))
+
+	for key, val := range m {
+		masked[key] = r.Field(key, val)
+	}
+
+	return masked
+}
+
+// Payload masks a message payload. JSON objects and arrays are masked field by field, other payloads are
+// masked as strings.
+func (r *Redactor) Payload(payload string) string {
+	if r == nil {
+		return payload
+	}
+
+	trimmed := strings.TrimSpace(payload)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return r.String(payload)
+	}
+
+	dec := json.NewDecoder(strings.NewReader(trimmed))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return r.String(payload)
+	}
+
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(r.Field("", v)); err != nil {
+		return r.String(payload)
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// URL masks the query parameters of a request URI. The parameters are kept in their order and encoding,
+// only the masked values are rewritten.
+func (r *Redactor) URL(uri string) string {
+	if r == nil {
+		return uri
+	}
+
+	path, query, ok := strings.Cut(uri, "?")
+	if !ok {
+		return r.String(uri)
+	}
+
+	params := strings.Split(query, "&")
+
+	for i, param := range params {
+		key, value, _ := strings.Cut(param, "=")
+
+		if masked := r.Field(key, value).(string); masked != value {
+			params[i] = key + "=" + masked
+		}
+	}
+
+	return r.String(path) + "?" + strings.Join(params, "&")
+}
+
+// message masks a log message: redactable messages, strings and maps.
+func (r *Redactor) message(msg any) any {
+	switch m := msg.(type) {
+	case Redactable:
+		return m.Redact(r)
+	case string:
+		return r.String(m)
+	case map[string]any:
+		return r.Map(m)
+	default:
+		return msg
+	}
+}
+
+func (r *Redactor) value(field, s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+
+	for _, p := range r.patterns {
+		s = p.ReplaceAllString(s, RedactedValue)
+	}
+
+	if r.cardNumbers {
+		s = cardNumberPattern.ReplaceAllStringFunc(s, func(match string) string {
+			if luhnValid(match) {
+				return RedactedValue
+			}
+
+			return match
+		})
+	}
+
+	for _, sanitize := range r.sanitizers {
+		s = sanitize(field, s)
+	}
+
+	return s
+}
+
+func normalizeFieldName(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "", " ", "").Replace(name))
+}
+
+// luhnValid reports whether the digits of s pass the Luhn checksum used by card numbers.
+func luhnValid(s string) bool {
+	sum, double := 0, false
+
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+
+		d := int(c - '0')
+
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+
+		sum += d
+		double = !double
+	}
+
+	return sum%10 == 0
+}
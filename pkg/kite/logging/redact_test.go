@@ -0,0 +1,127 @@
+package logging
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactor_Fields(t *testing.T) {
+	r := NewRedactor().RedactFields("ssn")
+
+	masked := r.Map(map[string]any{
+		"user":          "alice",
+		"Password":      "hunter2",
+		"X-Api-Key":     "abc",
+		"access_token":  []any{"t1", "t2"},
+		"customer_ssn":  123456789,
+		"profile":       map[string]any{"clientSecret": "s3cr3t", "age": 30},
+		"headers":       map[string]string{"Authorization": "Bearer abc", "Accept": "*/*"},
+		"tokens_issued": nil,
+	})
+
+	assert.Equal(t, map[string]any{
+		"user":          "alice",
+		"Password":      RedactedValue,
+		"X-Api-Key":     RedactedValue,
+		"access_token":  RedactedValue,
+		"customer_ssn":  RedactedValue,
+		"profile":       map[string]any{"clientSecret": RedactedValue, "age": 30},
+		"headers":       map[string]string{"Authorization": RedactedValue, "Accept": "*/*"},
+		"tokens_issued": nil,
+	}, masked)
+}
+
+func TestRedactor_Values(t *testing.T) {
+	r := NewRedactor().
+		RedactPatterns(regexp.MustCompile(`[\w.]+@[\w.]+`)).
+		AddSanitizer(func(field, value string) string {
+			if field == "phone" {
+				return "***" + value[len(value)-2:]
+			}
+
+			return value
+		})
+
+	tests := []struct {
+		desc     string
+		field    string
+		value    string
+		expected string
+	}{
+		{"card number", "", "paid with 4111111111111111", "paid with " + RedactedValue},
+		{"grouped card number", "", "card 5500-0000-0000-0004 declined", "card " + RedactedValue + " declined"},
+		{"digits failing the Luhn check", "", "order 1234567812345678 at 1728000000000", "order 1234567812345678 at 1728000000000"},
+		{"pattern", "note", "contact alice@example.com", "contact " + RedactedValue},
+		{"custom sanitizer", "phone", "5551234", "***34"},
+	}
+
+	for i, tc := range tests {
+		assert.Equal(t, tc.expected, r.Field(tc.field, tc.value), "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestRedactor_Payload(t *testing.T) {
+	r := NewRedactor()
+
+	assert.JSONEq(t, `{"order":{"id":1234567812345678,"card_number":"[REDACTED]"},"items":[{"token":"[REDACTED]"}]}`,
+		r.Payload(`{"order":{"id":1234567812345678,"card_number":"4111111111111111"},"items":[{"token":"abc"}]}`))
+	assert.Equal(t, "card "+RedactedValue, r.Payload("card 4111111111111111"))
+	assert.Equal(t, `{"password":`, r.Payload(`{"password":`))
+}
+
+func TestRedactor_URL(t *testing.T) {
+	r := NewRedactor()
+
+	assert.Equal(t, "/login?user=alice&password=[REDACTED]&next=%2Fhome",
+		r.URL("/login?user=alice&password=hunter2&next=%2Fhome"))
+	assert.Equal(t, "/users/1", r.URL("/users/1"))
+}
+
+func TestRedactor_Nil(t *testing.T) {
+	var r *Redactor
+
+	assert.Equal(t, "4111111111111111", r.String("4111111111111111"))
+	assert.Equal(t, "hunter2", r.Field("password", "hunter2"))
+	assert.Equal(t, "/login?password=hunter2", r.URL("/login?password=hunter2"))
+	assert.Equal(t, `{"password":"hunter2"}`, r.Payload(`{"password":"hunter2"}`))
+}
+
+type paymentLog struct {
+	Card string `json:"card"`
+}
+
+func (p *paymentLog) Redact(r *Redactor) any {
+	return &paymentLog{Card: r.String(p.Card)}
+}
+
+func TestLogger_Redactor(t *testing.T) {
+	var out bytes.Buffer
+
+	l := &logger{level: INFO, normalOut: &out, errorOut: &out, lock: make(chan struct{}, 1)}
+	l.SetRedactor(NewRedactor())
+
+	p := &paymentLog{Card: "4111111111111111"}
+
+	l.Info(p)
+	l.Info(map[string]any{"password": "hunter2"})
+	l.Infof("charged card %s", "4111 1111 1111 1111")
+
+	logs := strings.Split(strings.TrimSpace(out.String()), "\n")
+
+	assert.Len(t, logs, 3)
+	assert.Contains(t, logs[0], `"message":{"card":"[REDACTED]"}`)
+	assert.Contains(t, logs[1], `"message":{"password":"[REDACTED]"}`)
+	assert.Contains(t, logs[2], `"message":"charged card [REDACTED]"`)
+	assert.Equal(t, "4111111111111111", p.Card, "the logged message must not be modified")
+	assert.NotContains(t, out.String(), "hunter2")
+
+	out.Reset()
+	l.SetRedactor(nil)
+	l.Info(map[string]any{"password": "hunter2"})
+
+	assert.Contains(t, out.String(), "hunter2")
+}
@@ -168,6 +168,34 @@ func (r *remoteLogger) SetShowCaller(show bool) {
 	}
 }
 
+// SetFormat delegates to the underlying logger if it supports FormatConfigurer.
+func (r *remoteLogger) SetFormat(format string) {
+	if fc, ok := r.Logger.(logging.FormatConfigurer); ok {
+		fc.SetFormat(format)
+	}
+}
+
+// SetRedactor delegates to the underlying logger if it supports RedactorConfigurer.
+func (r *remoteLogger) SetRedactor(redactor *logging.Redactor) {
+	if rc, ok := r.Logger.(logging.RedactorConfigurer); ok {
+		rc.SetRedactor(redactor)
+	}
+}
+
+// SetAsync delegates to the underlying logger if it supports AsyncConfigurer.
+func (r *remoteLogger) SetAsync(size int, policy logging.OverflowPolicy, onDrop func()) {
+	if ac, ok := r.Logger.(logging.AsyncConfigurer); ok {
+		ac.SetAsync(size, policy, onDrop)
+	}
+}
+
+// Flush delegates to the underlying logger if it supports Flusher.
+func (r *remoteLogger) Flush() {
+	if f, ok := r.Logger.(logging.Flusher); ok {
+		f.Flush()
+	}
+}
+
 // UpdateLogLevel continuously fetches the log level from the remote configuration URL at the specified interval
 // and updates the underlying log level if it has changed.
 func (r *remoteLogger) UpdateLogLevel() {
@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// maxRecentGCPauses is the number of most recent GC pauses reported by the runtime stats.
+const maxRecentGCPauses = 16
+
+// RuntimeStats is the snapshot of the Go runtime served on /debug/runtime.
+type RuntimeStats struct {
+	Goroutines      int       `json:"goroutines"`
+	GOMAXPROCS      int       `json:"gomaxprocs"`
+	NumCPU          int       `json:"num_cpu"`
+	GoVersion       string    `json:"go_version"`
+	HeapAlloc       uint64    `json:"heap_alloc_bytes"`
+	HeapInuse       uint64    `json:"heap_inuse_bytes"`
+	HeapObjects     uint64    `json:"heap_objects"`
+	Sys             uint64    `json:"sys_bytes"`
+	NumGC           uint32    `json:"num_gc"`
+	LastGC          time.Time `json:"last_gc,omitzero"`
+	GCPauseTotal    uint64    `json:"gc_pause_total_ns"`
+	RecentGCPauses  []uint64  `json:"recent_gc_pauses_ns"`
+	GCCPUFraction   float64   `json:"gc_cpu_fraction"`
+	NextGCHeapAlloc uint64    `json:"next_gc_bytes"`
+}
+
+// ReadRuntimeStats returns the current RuntimeStats. The recent GC pauses are listed from the most recent one.
+func ReadRuntimeStats() RuntimeStats {
+	var m runtime.MemStats
+
+	runtime.ReadMemStats(&m)
+
+	stats := RuntimeStats{
+		Goroutines:      runtime.NumGoroutine(),
+		GOMAXPROCS:      runtime.GOMAXPROCS(0),
+		NumCPU:          runtime.NumCPU(),
+		GoVersion:       runtime.Version(),
+		HeapAlloc:       m.HeapAlloc,
+		HeapInuse:       m.HeapInuse,
+		HeapObjects:     m.HeapObjects,
+		Sys:             m.Sys,
+		NumGC:           m.NumGC,
+		GCPauseTotal:    m.PauseTotalNs,
+		GCCPUFraction:   m.GCCPUFraction,
+		NextGCHeapAlloc: m.NextGC,
+		RecentGCPauses:  make([]uint64, 0, min(int(m.NumGC), maxRecentGCPauses)),
+	}
+
+	if m.LastGC != 0 {
+		stats.LastGC = time.Unix(0, int64(m.LastGC)).UTC() //nolint:gosec // nanoseconds since the epoch fit in an int64.
+	}
+
+	// PauseNs is a circular buffer, whose most recent pause is at (NumGC+255)%256.
+	for i := uint32(0); i < m.NumGC && i < maxRecentGCPauses; i++ {
+		stats.RecentGCPauses = append(stats.RecentGCPauses, m.PauseNs[(m.NumGC-1-i)%uint32(len(m.PauseNs))])
+	}
+
+	return stats
+}
+
+// DebugHandler creates a new HTTP handler that serves the diagnostics endpoints of the application:
+//
+//   - /debug/pprof/ (index), /debug/pprof/cmdline, /debug/pprof/profile, /debug/pprof/symbol and /debug/pprof/trace,
+//     the profiles of net/http/pprof;
+//   - /debug/vars, the variables published with expvar;
+//   - /debug/runtime, the RuntimeStats as JSON.
+//
+// These endpoints expose the internals of the application, they must not be reachable without authentication.
+func DebugHandler() http.Handler {
+	router := chi.NewRouter()
+
+	router.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	router.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	router.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	router.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	router.Get("/debug/pprof/*", pprof.Index)
+
+	router.Get("/debug/vars", expvar.Handler().ServeHTTP)
+	router.Get("/debug/runtime", runtimeStatsHandler)
+
+	return router
+}
+
+func runtimeStatsHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_ = json.NewEncoder(w).Encode(ReadRuntimeStats())
+}
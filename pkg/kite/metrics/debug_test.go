@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DebugHandler_RegisteredProfilingRoutes(t *testing.T) {
+	server := httptest.NewServer(DebugHandler())
+	defer server.Close()
+
+	expectedRoutes := []string{
+		"/debug/pprof/",
+		"/debug/pprof/cmdline",
+		"/debug/pprof/symbol",
+		"/debug/pprof/goroutine",
+		"/debug/vars",
+		"/debug/runtime",
+	}
+
+	for _, route := range expectedRoutes {
+		req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+route, http.NoBody)
+		resp, err := server.Client().Do(req)
+
+		require.NoError(t, err, route)
+		require.Equal(t, http.StatusOK, resp.StatusCode, route)
+
+		resp.Body.Close()
+	}
+}
+
+func Test_DebugHandler_RuntimeStats(t *testing.T) {
+	runtime.GC()
+
+	server := httptest.NewServer(DebugHandler())
+	defer server.Close()
+
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+"/debug/runtime", http.NoBody)
+	resp, err := server.Client().Do(req)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	var stats RuntimeStats
+
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&stats))
+
+	assert.Positive(t, stats.Goroutines)
+	assert.Positive(t, stats.HeapAlloc)
+	assert.Equal(t, runtime.Version(), stats.GoVersion)
+	assert.Positive(t, stats.NumGC)
+	assert.False(t, stats.LastGC.IsZero())
+	assert.NotEmpty(t, stats.RecentGCPauses)
+	assert.LessOrEqual(t, len(stats.RecentGCPauses), maxRecentGCPauses)
+}
+
+func Test_DebugHandler_Expvar(t *testing.T) {
+	server := httptest.NewServer(DebugHandler())
+	defer server.Close()
+
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+"/debug/vars", http.NoBody)
+	resp, err := server.Client().Do(req)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	var vars map[string]json.RawMessage
+
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&vars))
+	assert.Contains(t, vars, "memstats")
+	assert.Contains(t, vars, "cmdline")
+}
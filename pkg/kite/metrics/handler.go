@@ -2,7 +2,6 @@ package metrics
 
 import (
 	"net/http"
-	"net/http/pprof"
 	"runtime"
 
 	"github.com/go-chi/chi/v5"
@@ -16,22 +15,6 @@ func GetHandler(m Manager) http.Handler {
 	// Prometheus
 	router.Get("/metrics", systemMetricsHandler(m, promhttp.Handler()).ServeHTTP)
 
-	//   - /debug/pprof/cmdline
-	//   - /debug/pprof/profile
-	//   - /debug/pprof/symbol
-	//   - /debug/pprof/trace
-	//   - /debug/pprof/ (index)
-	//
-	// These endpoints provide various profiling information for the application,
-	// such as command-line arguments, memory profiles, symbol information, and
-	// execution traces.
-	router.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
-	router.HandleFunc("/debug/pprof/profile", pprof.Profile)
-	router.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
-	router.HandleFunc("/debug/pprof/trace", pprof.Trace)
-
-	router.Get("/debug/pprof/*", pprof.Index)
-
 	return router
 }
 
@@ -14,6 +14,9 @@ import (
 type metricServer struct {
 	port int
 	srv  *http.Server
+
+	// debug serves the diagnostics endpoints under /debug/, when they are enabled, see newDebugHandler.
+	debug http.Handler
 }
 
 func newMetricServer(port int) *metricServer {
@@ -24,9 +27,19 @@ func (m *metricServer) Run(c *infra.Container) {
 	if m != nil {
 		c.Logf("Starting metrics server on port: %d", m.port)
 
+		handler := metrics.GetHandler(c.Metrics())
+
+		if m.debug != nil {
+			mux := http.NewServeMux()
+			mux.Handle("/debug/", m.debug)
+			mux.Handle("/", handler)
+
+			handler = mux
+		}
+
 		m.srv = &http.Server{
 			Addr:              fmt.Sprintf(":%d", m.port),
-			Handler:           metrics.GetHandler(c.Metrics()),
+			Handler:           handler,
 			ReadHeaderTimeout: 5 * time.Second,
 		}
 
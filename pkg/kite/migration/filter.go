@@ -0,0 +1,67 @@
+package migration
+
+import (
+	"slices"
+	"strings"
+)
+
+// Targets are the datasources a migration can apply to, see Migrate.Datasources.
+const (
+	TargetSQL           = "sql"
+	TargetRedis         = "redis"
+	TargetMongo         = "mongo"
+	TargetCassandra     = "cassandra"
+	TargetClickhouse    = "clickhouse"
+	TargetOracle        = "oracle"
+	TargetPubSub        = "pubsub"
+	TargetDGraph        = "dgraph"
+	TargetArangoDB      = "arangodb"
+	TargetSurrealDB     = "surrealdb"
+	TargetElasticsearch = "elasticsearch"
+	TargetOpenTSDB      = "opentsdb"
+	TargetScyllaDB      = "scylladb"
+)
+
+// Tags classifying the migrations, see Migrate.Tags.
+const (
+	// TagSchema marks the migrations changing the structure of the data, e.g. tables and indexes.
+	TagSchema = "schema"
+	// TagData marks the migrations changing the data itself, e.g. backfills.
+	TagData = "data"
+)
+
+// Filter selects the migrations which are run. As a migration is only run after all the previous ones, the first
+// pending migration which is not selected is deferred along with the next ones, until a run selecting it.
+type Filter struct {
+	// Datasources restricts the migrations to the ones applying to one of these datasources, or to no datasource in
+	// particular.
+	Datasources []string
+	// Tags restricts the migrations to the ones with one of these tags.
+	Tags []string
+}
+
+// appliesTo reports whether the migration applies to one of the initialized datasources, or to no datasource in
+// particular.
+func appliesTo(m Migrate, initialized []string) bool {
+	return len(m.Datasources) == 0 || containsAnyFold(initialized, m.Datasources)
+}
+
+// deferReason returns why the migration is not selected by the filter, or an empty string when it is.
+func (f Filter) deferReason(m Migrate) string {
+	if len(f.Datasources) > 0 && len(m.Datasources) > 0 && !containsAnyFold(f.Datasources, m.Datasources) {
+		return "it applies to " + strings.Join(m.Datasources, ", ")
+	}
+
+	if len(f.Tags) > 0 && !containsAnyFold(f.Tags, m.Tags) {
+		return "it is not tagged " + strings.Join(f.Tags, " or ")
+	}
+
+	return ""
+}
+
+// containsAnyFold reports whether values contains one of the candidates, ignoring case.
+func containsAnyFold(values, candidates []string) bool {
+	return slices.ContainsFunc(candidates, func(candidate string) bool {
+		return slices.ContainsFunc(values, func(v string) bool { return strings.EqualFold(v, candidate) })
+	})
+}
@@ -0,0 +1,97 @@
+package migration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/sllt/kite/pkg/kite/testutil"
+)
+
+func TestAppliesTo(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		datasources []string
+		expected    bool
+	}{
+		{"no datasource in particular", nil, true},
+		{"initialized datasource", []string{TargetSQL}, true},
+		{"initialized datasource in another case", []string{"SQL"}, true},
+		{"one of the datasources initialized", []string{TargetMongo, TargetRedis}, true},
+		{"datasource not initialized", []string{TargetMongo}, false},
+	}
+
+	for i, tc := range testCases {
+		applies := appliesTo(Migrate{Datasources: tc.datasources}, []string{TargetSQL, TargetRedis})
+
+		assert.Equal(t, tc.expected, applies, "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestFilter_DeferReason(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		filter    Filter
+		migration Migrate
+		expected  string
+	}{
+		{"no filter", Filter{}, Migrate{Datasources: []string{TargetMongo}, Tags: []string{TagData}}, ""},
+		{"selected datasource", Filter{Datasources: []string{"sql"}},
+			Migrate{Datasources: []string{TargetSQL}}, ""},
+		{"no datasource in particular", Filter{Datasources: []string{"sql"}}, Migrate{}, ""},
+		{"other datasource", Filter{Datasources: []string{"sql"}},
+			Migrate{Datasources: []string{TargetMongo}}, "it applies to mongo"},
+		{"selected tag", Filter{Tags: []string{"Schema"}}, Migrate{Tags: []string{TagSchema}}, ""},
+		{"other tag", Filter{Tags: []string{"schema"}},
+			Migrate{Tags: []string{TagData}}, "it is not tagged schema"},
+		{"no tag", Filter{Tags: []string{"schema", "data"}}, Migrate{}, "it is not tagged schema or data"},
+	}
+
+	for i, tc := range testCases {
+		assert.Equal(t, tc.expected, tc.filter.deferReason(tc.migration), "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestRunFiltered_DefersMigrationsOfUninitializedDatasources(t *testing.T) {
+	logs := testutil.StdoutOutputForFunc(func() {
+		mockClickHouse, mockContainer := initializeClickHouseRunMocks(t)
+
+		mockClickHouse.EXPECT().Exec(gomock.Any(), CheckAndCreateChMigrationTable).Return(nil)
+		mockClickHouse.EXPECT().Select(gomock.Any(), gomock.Any(), getLastChKiteMigration).Return(nil)
+		mockClickHouse.EXPECT().Exec(gomock.Any(), insertChKiteMigrationRow, int64(1),
+			"UP", gomock.Any(), gomock.Any()).Return(nil)
+
+		RunFiltered(map[int64]Migrate{
+			1: {UP: func(Datasource) error { return nil }, Datasources: []string{TargetClickhouse}},
+			2: {UP: func(Datasource) error { return nil }, Datasources: []string{TargetMongo}},
+			3: {UP: func(Datasource) error { return nil }, Datasources: []string{TargetClickhouse}},
+		}, mockContainer, Filter{})
+	})
+
+	assert.Contains(t, logs, "Migration 1 ran successfully")
+	assert.Contains(t, logs, "deferring migration 2 and the next ones as none of [mongo] is initialized")
+	assert.NotContains(t, logs, "Migration 3 ran successfully",
+		"a later migration must not be recorded before the deferred one ran")
+}
+
+func TestRunFiltered_DefersUnselectedMigrations(t *testing.T) {
+	logs := testutil.StdoutOutputForFunc(func() {
+		mockClickHouse, mockContainer := initializeClickHouseRunMocks(t)
+
+		mockClickHouse.EXPECT().Exec(gomock.Any(), CheckAndCreateChMigrationTable).Return(nil)
+		mockClickHouse.EXPECT().Select(gomock.Any(), gomock.Any(), getLastChKiteMigration).Return(nil)
+		mockClickHouse.EXPECT().Exec(gomock.Any(), insertChKiteMigrationRow, int64(1),
+			"UP", gomock.Any(), gomock.Any()).Return(nil)
+
+		RunFiltered(map[int64]Migrate{
+			1: {UP: func(Datasource) error { return nil }, Tags: []string{TagSchema}},
+			2: {UP: func(Datasource) error { return nil }, Tags: []string{TagData}},
+			3: {UP: func(Datasource) error { return nil }, Tags: []string{TagSchema}},
+		}, mockContainer, Filter{Tags: []string{TagSchema}})
+	})
+
+	assert.Contains(t, logs, "Migration 1 ran successfully")
+	assert.Contains(t, logs, "deferring migration 2 and the next ones as it is not tagged schema")
+	assert.NotContains(t, logs, "Migration 3 ran successfully")
+}
@@ -15,6 +15,12 @@ type MigrateFunc func(d Datasource) error
 
 type Migrate struct {
 	UP MigrateFunc
+	// Datasources are the datasources the migration applies to, e.g. TargetMongo. The migration is deferred along
+	// with the next ones when none of them is initialized, e.g. a Mongo index in an application which only uses SQL,
+	// until a run where one of them is. A migration with no datasources is always run.
+	Datasources []string
+	// Tags classify the migration, e.g. TagSchema or TagData, to run some of the migrations only, see Filter.
+	Tags []string
 }
 
 type transactionData struct {
@@ -27,6 +33,11 @@ type transactionData struct {
 }
 
 func Run(migrationsMap map[int64]Migrate, c *infra.Container) {
+	RunFiltered(migrationsMap, c, Filter{})
+}
+
+// RunFiltered runs the pending migrations selected by filter, in order.
+func RunFiltered(migrationsMap map[int64]Migrate, c *infra.Container, filter Filter) {
 	invalidKeys, keys := getKeys(migrationsMap)
 	if len(invalidKeys) > 0 {
 		c.Errorf("migration run failed! UP not defined for the following keys: %v", invalidKeys)
@@ -36,12 +47,12 @@ func Run(migrationsMap map[int64]Migrate, c *infra.Container) {
 
 	sortkeys.Int64s(keys)
 
-	ds, mg, ok := getMigrator(c)
+	ds, mg, initialized := getMigrator(c)
 	ds.Logger = c.Logger
 
 	// Returning with an error log as migration would eventually fail as No databases are initialized.
 	// Pub/Sub is considered as initialized if its configurations are given.
-	if !ok {
+	if len(initialized) == 0 {
 		c.Errorf("no migrations are running as datasources are not initialized")
 
 		return
@@ -68,6 +79,20 @@ func Run(migrationsMap map[int64]Migrate, c *infra.Container) {
 			continue
 		}
 
+		// the migration cannot be skipped, as it would never run once a later one is recorded.
+		if !appliesTo(migrationsMap[currentMigration], initialized) {
+			c.Warnf("deferring migration %v and the next ones as none of %v is initialized", currentMigration,
+				migrationsMap[currentMigration].Datasources)
+
+			return
+		}
+
+		if reason := filter.deferReason(migrationsMap[currentMigration]); reason != "" {
+			c.Infof("deferring migration %v and the next ones as %s", currentMigration, reason)
+
+			return
+		}
+
 		c.Logger.Infof("running migration %v", currentMigration)
 
 		migrationInfo := mg.beginTransaction(c)
@@ -120,16 +145,17 @@ func getKeys(migrationsMap map[int64]Migrate) (invalidKey, keys []int64) {
 	return invalidKey, keys
 }
 
-func getMigrator(c *infra.Container) (Datasource, migrator, bool) {
+// getMigrator returns the datasources of the migrations, their migrator and the targets of the initialized ones.
+func getMigrator(c *infra.Container) (Datasource, migrator, []string) {
 	var (
-		ds Datasource
-		mg migrator = &ds
-		ok bool
+		ds          Datasource
+		mg          migrator = &ds
+		initialized []string
 	)
 
-	mg, ok = initializeDatasources(c, &ds, mg)
+	mg, initialized = initializeDatasources(c, &ds, mg)
 
-	return ds, mg, ok
+	return ds, mg, initialized
 }
 
 type datasourceInitializer struct {
@@ -137,10 +163,11 @@ type datasourceInitializer struct {
 	setDS         func()
 	apply         func(m migrator) migrator
 	logIdentifier string
+	target        string
 }
 
-func initializeDatasources(c *infra.Container, ds *Datasource, mg migrator) (migrator, bool) {
-	var initialized bool
+func initializeDatasources(c *infra.Container, ds *Datasource, mg migrator) (migrator, []string) {
+	var initialized []string
 
 	initializers := []datasourceInitializer{
 		{
@@ -148,30 +175,35 @@ func initializeDatasources(c *infra.Container, ds *Datasource, mg migrator) (mig
 			setDS:         func() { ds.SQL = c.SQL },
 			apply:         func(m migrator) migrator { return (&sqlDS{ds.SQL}).apply(m) },
 			logIdentifier: "SQL",
+			target:        TargetSQL,
 		},
 		{
 			condition:     func() bool { return !isNil(c.Redis) },
 			setDS:         func() { ds.Redis = c.Redis },
 			apply:         func(m migrator) migrator { return redisDS{ds.Redis}.apply(m) },
 			logIdentifier: "Redis",
+			target:        TargetRedis,
 		},
 		{
 			condition:     func() bool { return !isNil(c.DGraph) },
 			setDS:         func() { ds.DGraph = dgraphDS{c.DGraph} },
 			apply:         func(m migrator) migrator { return dgraphDS{c.DGraph}.apply(m) },
 			logIdentifier: "DGraph",
+			target:        TargetDGraph,
 		},
 		{
 			condition:     func() bool { return !isNil(c.Clickhouse) },
 			setDS:         func() { ds.Clickhouse = c.Clickhouse },
 			apply:         func(m migrator) migrator { return clickHouseDS{ds.Clickhouse}.apply(m) },
 			logIdentifier: "Clickhouse",
+			target:        TargetClickhouse,
 		},
 		{
 			condition:     func() bool { return !isNil(c.Oracle) },
 			setDS:         func() { ds.Oracle = c.Oracle },
 			apply:         func(m migrator) migrator { return oracleDS{c.Oracle}.apply(m) },
 			logIdentifier: "Oracle",
+			target:        TargetOracle,
 		},
 
 		{
@@ -179,48 +211,56 @@ func initializeDatasources(c *infra.Container, ds *Datasource, mg migrator) (mig
 			setDS:         func() { ds.PubSub = c.PubSub },
 			apply:         func(m migrator) migrator { return pubsubDS{c.PubSub}.apply(m) },
 			logIdentifier: "PubSub",
+			target:        TargetPubSub,
 		},
 		{
 			condition:     func() bool { return !isNil(c.Cassandra) },
 			setDS:         func() { ds.Cassandra = cassandraDS{c.Cassandra} },
 			apply:         func(m migrator) migrator { return cassandraDS{c.Cassandra}.apply(m) },
 			logIdentifier: "Cassandra",
+			target:        TargetCassandra,
 		},
 		{
 			condition:     func() bool { return !isNil(c.Mongo) },
 			setDS:         func() { ds.Mongo = mongoDS{c.Mongo} },
 			apply:         func(m migrator) migrator { return mongoDS{c.Mongo}.apply(m) },
 			logIdentifier: "Mongo",
+			target:        TargetMongo,
 		},
 		{
 			condition:     func() bool { return !isNil(c.ArangoDB) },
 			setDS:         func() { ds.ArangoDB = arangoDS{c.ArangoDB} },
 			apply:         func(m migrator) migrator { return arangoDS{c.ArangoDB}.apply(m) },
 			logIdentifier: "ArangoDB",
+			target:        TargetArangoDB,
 		},
 		{
 			condition:     func() bool { return !isNil(c.SurrealDB) },
 			setDS:         func() { ds.SurrealDB = surrealDS{c.SurrealDB} },
 			apply:         func(m migrator) migrator { return surrealDS{c.SurrealDB}.apply(m) },
 			logIdentifier: "SurrealDB",
+			target:        TargetSurrealDB,
 		},
 		{
 			condition:     func() bool { return !isNil(c.Elasticsearch) },
 			setDS:         func() { ds.Elasticsearch = c.Elasticsearch },
 			apply:         func(m migrator) migrator { return elasticsearchDS{c.Elasticsearch}.apply(m) },
 			logIdentifier: "Elasticsearch",
+			target:        TargetElasticsearch,
 		},
 		{
 			condition:     func() bool { return !isNil(c.OpenTSDB) },
 			setDS:         func() { ds.OpenTSDB = c.OpenTSDB },
 			apply:         func(m migrator) migrator { return openTSDBDS{c.OpenTSDB, "kite_migrations.json"}.apply(m) },
 			logIdentifier: "OpenTSDB",
+			target:        TargetOpenTSDB,
 		},
 		{
 			condition:     func() bool { return !isNil(c.ScyllaDB) },
 			setDS:         func() { ds.ScyllaDB = c.ScyllaDB },
 			apply:         func(m migrator) migrator { return scyllaDS{c.ScyllaDB}.apply(m) },
 			logIdentifier: "ScyllaDB",
+			target:        TargetScyllaDB,
 		},
 	}
 
@@ -231,7 +271,7 @@ func initializeDatasources(c *infra.Container, ds *Datasource, mg migrator) (mig
 
 		init.setDS()
 		mg = init.apply(mg)
-		initialized = true
+		initialized = append(initialized, init.target)
 
 		c.Debugf("initialized data source for %s", init.logIdentifier)
 	}
@@ -47,11 +47,11 @@ func TestMigration_NoDatasource(t *testing.T) {
 func Test_getMigratorDBInitialisation(t *testing.T) {
 	cntnr, _ := infra.NewMockContainer(t)
 
-	datasource, _, isInitialized := getMigrator(cntnr)
+	datasource, _, initialized := getMigrator(cntnr)
 
 	assert.NotNil(t, datasource.SQL, "TEST Failed \nSQL not initialized, but should have been initialized")
 	assert.NotNil(t, datasource.Redis, "TEST Failed \nRedis not initialized, but should have been initialized")
-	assert.True(t, isInitialized, "TEST Failed \nNo datastores are Initialized")
+	assert.Subset(t, initialized, []string{TargetSQL, TargetRedis}, "TEST Failed \nNo datastores are Initialized")
 }
 
 func TestMigrationRunClickhouseSuccess(t *testing.T) {
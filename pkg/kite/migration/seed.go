@@ -0,0 +1,152 @@
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/sllt/kite/pkg/kite/datasource/sql/qb"
+	"github.com/sllt/kite/pkg/kite/infra"
+)
+
+const createSQLKiteSeedsTable = `CREATE TABLE IF NOT EXISTS kite_seeds (
+    name VARCHAR(255) not null ,
+    applied_at TIMESTAMP not null ,
+    constraint seed_primary_key primary key (name)
+);`
+
+var (
+	errSeedName    = errors.New("seed name must not be empty")
+	errSeedNoData  = errors.New("seed must define either Run or Table and Rows")
+	errSeedApplied = errors.New("seed is already applied")
+)
+
+// Seed is a set of data inserted into the SQL database after the migrations, e.g. fixtures for development
+// and tests. Every seed is applied once: the names of the applied seeds are recorded in the kite_seeds table.
+type Seed struct {
+	// Name identifies the seed. Renaming a seed applies it again.
+	Name string
+	// Environments restricts the seed to the given values of APP_ENV. The seed is applied in every environment
+	// if it is empty.
+	Environments []string
+
+	// Table and Rows insert the rows into the table with a single INSERT statement built with qb.
+	Table string
+	Rows  []map[string]any
+
+	// Run inserts the data of the seed, for seeds which cannot be expressed with Table and Rows.
+	// It runs after the rows are inserted, in the same transaction.
+	Run MigrateFunc
+}
+
+// RunSeeds applies, in order, the seeds which match env and have not been applied yet. Each seed runs in
+// its own transaction, and seeding stops at the first seed which fails.
+func RunSeeds(seeds []Seed, env string, c *infra.Container) {
+	if isNil(c.SQL) {
+		c.Errorf("no seeds are running as SQL is not initialized")
+
+		return
+	}
+
+	if _, err := c.SQL.Exec(createSQLKiteSeedsTable); err != nil {
+		c.Errorf("failed to create kite_seeds table, err: %v", err)
+
+		return
+	}
+
+	for i := range seeds {
+		seed := &seeds[i]
+
+		if len(seed.Environments) > 0 && !slices.Contains(seed.Environments, env) {
+			c.Debugf("skipping seed %q as it does not apply to environment %q", seed.Name, env)
+
+			continue
+		}
+
+		err := applySeed(seed, c)
+
+		switch {
+		case errors.Is(err, errSeedApplied):
+			c.Infof("skipping seed %q as it is already applied", seed.Name)
+		case err != nil:
+			c.Errorf("failed to apply seed %q, err: %v", seed.Name, err)
+
+			return
+		default:
+			c.Infof("seed %q applied successfully", seed.Name)
+		}
+	}
+}
+
+func applySeed(seed *Seed, c *infra.Container) error {
+	if seed.Name == "" {
+		return errSeedName
+	}
+
+	if seed.Run == nil && (seed.Table == "" || len(seed.Rows) == 0) {
+		return errSeedNoData
+	}
+
+	dialect := c.SQL.Dialect()
+
+	query, args, err := qb.BuildSelectWithDialect(dialect, "kite_seeds",
+		map[string]any{"name": seed.Name}, []string{"COUNT(*)"})
+	if err != nil {
+		return err
+	}
+
+	var applied int
+
+	if err = c.SQL.QueryRow(query, args...).Scan(&applied); err != nil {
+		return err
+	}
+
+	if applied > 0 {
+		return errSeedApplied
+	}
+
+	tx, err := c.SQL.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err = runSeed(seed, dialect, tx, c); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			err = errors.Join(err, rbErr)
+		}
+
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func runSeed(seed *Seed, dialect string, tx SQL, c *infra.Container) error {
+	if len(seed.Rows) > 0 {
+		query, args, err := qb.BuildInsertWithDialect(dialect, seed.Table, seed.Rows)
+		if err != nil {
+			return fmt.Errorf("building insert into %s: %w", seed.Table, err)
+		}
+
+		if _, err = tx.Exec(query, args...); err != nil {
+			return err
+		}
+	}
+
+	if seed.Run != nil {
+		if err := seed.Run(Datasource{Logger: c.Logger, SQL: tx}); err != nil {
+			return err
+		}
+	}
+
+	query, args, err := qb.BuildInsertWithDialect(dialect, "kite_seeds",
+		[]map[string]any{{"name": seed.Name, "applied_at": time.Now().UTC()}})
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(query, args...)
+
+	return err
+}
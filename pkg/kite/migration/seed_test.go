@@ -0,0 +1,115 @@
+package migration
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/sllt/kite/pkg/kite/config"
+	kiteSql "github.com/sllt/kite/pkg/kite/datasource/sql"
+	"github.com/sllt/kite/pkg/kite/infra"
+	"github.com/sllt/kite/pkg/kite/logging"
+	"github.com/sllt/kite/pkg/kite/testutil"
+)
+
+func newSeedContainer(t *testing.T) *infra.Container {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	mockMetrics := kiteSql.NewMockMetrics(ctrl)
+	mockMetrics.EXPECT().SetGauge(gomock.Any(), gomock.Any()).AnyTimes()
+	mockMetrics.EXPECT().RecordHistogram(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	logger := logging.NewMockLogger(logging.DEBUG)
+
+	db := kiteSql.NewSQL(config.NewMockConfig(map[string]string{
+		"DB_DIALECT": "sqlite",
+		"DB_NAME":    filepath.Join(t.TempDir(), "seed.db"),
+	}), logger, mockMetrics)
+	require.NotNil(t, db)
+
+	t.Cleanup(func() { db.Close() })
+
+	_, err := db.Exec("CREATE TABLE roles (id INTEGER PRIMARY KEY, name TEXT NOT NULL)")
+	require.NoError(t, err)
+
+	return &infra.Container{Logger: logger, SQL: db}
+}
+
+func countRoles(t *testing.T, c *infra.Container) int {
+	t.Helper()
+
+	var n int
+
+	require.NoError(t, c.SQL.QueryRow("SELECT COUNT(*) FROM roles").Scan(&n))
+
+	return n
+}
+
+func TestRunSeeds(t *testing.T) {
+	c := newSeedContainer(t)
+
+	seeds := []Seed{
+		{Name: "roles", Table: "roles", Rows: []map[string]any{{"id": 1, "name": "admin"}, {"id": 2, "name": "viewer"}}},
+		{Name: "demo roles", Environments: []string{"dev"}, Run: func(d Datasource) error {
+			_, err := d.SQL.Exec("INSERT INTO roles (id, name) VALUES (3, 'demo')")
+			return err
+		}},
+		{Name: "prod roles", Environments: []string{"prod"}, Table: "roles", Rows: []map[string]any{{"id": 4, "name": "ops"}}},
+	}
+
+	logs := testutil.StdoutOutputForFunc(func() {
+		RunSeeds(seeds, "dev", c)
+	})
+
+	assert.Contains(t, logs, `seed \"roles\" applied successfully`)
+	assert.Equal(t, 3, countRoles(t, c))
+
+	// seeds are idempotent
+	logs = testutil.StdoutOutputForFunc(func() {
+		RunSeeds(seeds, "dev", c)
+	})
+
+	assert.Contains(t, logs, `skipping seed \"roles\" as it is already applied`)
+	assert.Equal(t, 3, countRoles(t, c))
+}
+
+func TestRunSeeds_FailureRollsBack(t *testing.T) {
+	c := newSeedContainer(t)
+
+	seeds := []Seed{
+		{Name: "broken", Table: "roles", Rows: []map[string]any{{"id": 1, "name": "admin"}}, Run: func(d Datasource) error {
+			_, err := d.SQL.Exec("INSERT INTO roles (id, name) VALUES (1, 'duplicate')")
+			return err
+		}},
+		{Name: "after broken", Table: "roles", Rows: []map[string]any{{"id": 2, "name": "viewer"}}},
+	}
+
+	logs := testutil.StderrOutputForFunc(func() {
+		RunSeeds(seeds, "", c)
+	})
+
+	assert.Contains(t, logs, `failed to apply seed \"broken\"`)
+	assert.Equal(t, 0, countRoles(t, c))
+}
+
+func TestRunSeeds_InvalidSeed(t *testing.T) {
+	c := newSeedContainer(t)
+
+	logs := testutil.StderrOutputForFunc(func() {
+		RunSeeds([]Seed{{Name: "empty"}}, "", c)
+	})
+
+	assert.Contains(t, logs, errSeedNoData.Error())
+}
+
+func TestRunSeeds_NoSQL(t *testing.T) {
+	logs := testutil.StderrOutputForFunc(func() {
+		RunSeeds([]Seed{{Name: "roles"}}, "", &infra.Container{Logger: logging.NewMockLogger(logging.ERROR)})
+	})
+
+	assert.Contains(t, logs, "no seeds are running as SQL is not initialized")
+}
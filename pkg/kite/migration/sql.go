@@ -132,3 +132,27 @@ func (d sqlMigrator) rollback(c *infra.Container, data transactionData) {
 
 	c.Fatalf("Migration %v failed and rolled back", data.MigrationNumber)
 }
+
+// CreateDocumentTable creates the table of a kiteSql.DocumentStore, if it does not exist. It is meant to be called
+// from the UP function of a migration on Postgres:
+//
+//	UP: func(d migration.Datasource) error {
+//		if err := migration.CreateDocumentTable(d.SQL, "orders"); err != nil {
+//			return err
+//		}
+//
+//		return migration.CreateGINIndex(d.SQL, "orders", "data", true)
+//	}
+func CreateDocumentTable(db SQL, table string) error {
+	_, err := db.Exec(kiteSql.DocumentTableQuery(table))
+
+	return err
+}
+
+// CreateGINIndex creates a GIN index on the jsonb column of table, if it does not exist. See kiteSql.GINIndexQuery
+// for pathOps.
+func CreateGINIndex(db SQL, table, column string, pathOps bool) error {
+	_, err := db.Exec(kiteSql.GINIndexQuery(table, column, pathOps))
+
+	return err
+}
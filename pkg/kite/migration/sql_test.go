@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 
+	kiteSql "github.com/sllt/kite/pkg/kite/datasource/sql"
 	"github.com/sllt/kite/pkg/kite/infra"
 )
 
@@ -403,3 +404,15 @@ func TestCheckAndCreateMigrationTable_ErrorCreatingTable(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "create table error")
 }
+
+func TestCreateDocumentTableAndGINIndex(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockSQL := NewMockSQL(ctrl)
+
+	mockSQL.EXPECT().Exec(kiteSql.DocumentTableQuery("orders")).Return(nil, nil)
+	mockSQL.EXPECT().Exec("CREATE INDEX IF NOT EXISTS orders_data_gin ON orders USING GIN (data jsonb_path_ops);").
+		Return(nil, errCreateTable)
+
+	require.NoError(t, CreateDocumentTable(mockSQL, "orders"))
+	require.ErrorIs(t, CreateGINIndex(mockSQL, "orders", "data", true), errCreateTable)
+}
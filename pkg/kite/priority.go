@@ -0,0 +1,28 @@
+package kite
+
+import "github.com/sllt/kite/pkg/kite/http/middleware"
+
+// EnablePriorityClasses classifies the HTTP requests, by route, API key tier, header or with a function of their own,
+// and serves each class under a concurrency budget of its own, so that low priority bulk endpoints cannot starve the
+// interactive traffic during an overload:
+//
+//	app.EnablePriorityClasses(middleware.PriorityConfig{
+//		Classes: []middleware.PriorityClass{
+//			{Name: "interactive", MaxInFlight: 200, QueueTimeout: 100 * time.Millisecond},
+//			{Name: "bulk", MaxInFlight: 10, MaxQueue: 20, QueueTimeout: 5 * time.Second},
+//		},
+//		Routes:       map[string]string{"POST /exports": "bulk"},
+//		APIKeyScopes: map[string]string{"tier:free": "bulk"},
+//	})
+//
+// The requests over the budget of their class are shed with 503 Service Unavailable, and counted by the
+// app_http_priority_requests_shed_total metric. To classify the requests by API key, the middleware.APIKeys middleware
+// must be registered with app.Use first.
+func (a *App) EnablePriorityClasses(cfg middleware.PriorityConfig) {
+	a.Use(middleware.PriorityClasses(cfg, a.Metrics()))
+}
+
+// PriorityClass returns the class of the request given by App.EnablePriorityClasses, or an empty string.
+func (c *Context) PriorityClass() string {
+	return middleware.PriorityClassFromContext(c.Context)
+}
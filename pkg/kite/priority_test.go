@@ -0,0 +1,47 @@
+package kite
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sllt/kite/pkg/kite/http/middleware"
+)
+
+func TestApp_EnablePriorityClasses(t *testing.T) {
+	app := newVersioningTestApp(nil)
+
+	app.EnablePriorityClasses(middleware.PriorityConfig{
+		Classes: []middleware.PriorityClass{{Name: "interactive", MaxInFlight: 10}, {Name: "bulk", MaxInFlight: 1}},
+		Routes:  map[string]string{"POST /exports": "bulk"},
+	})
+
+	handler := func(c *Context) (any, error) {
+		return c.PriorityClass(), nil
+	}
+
+	app.GET("/orders", handler)
+	app.POST("/exports", handler)
+
+	app.httpServer.registry.compile(app.httpServer.router.Mux(), app.container, 0)
+
+	testCases := []struct {
+		method     string
+		target     string
+		statusCode int
+		class      string
+	}{
+		{method: http.MethodGet, target: "/orders", statusCode: http.StatusOK, class: "interactive"},
+		{method: http.MethodPost, target: "/exports", statusCode: http.StatusCreated, class: "bulk"},
+	}
+
+	for i, tc := range testCases {
+		rec := httptest.NewRecorder()
+		app.httpServer.router.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.target, http.NoBody))
+
+		assert.Equal(t, tc.statusCode, rec.Code, "TEST[%d], Failed.\n%s %s", i, tc.method, tc.target)
+		assert.Contains(t, rec.Body.String(), `"`+tc.class+`"`, "TEST[%d], Failed.\n%s %s", i, tc.method, tc.target)
+	}
+}
@@ -24,12 +24,31 @@ import (
 //
 // Role extraction is configured in the config file:
 // - Set "roleHeader" for header-based extraction (e.g., "X-User-Role")
-// - Set "jwtClaimPath" for JWT-based extraction (e.g., "role", "roles[0]").
+// - Set "jwtClaimPath" for JWT-based extraction (e.g., "role", "roles[0]")
+// - Set "subject" to derive the roles from JWT claims, API keys or client certificates.
 func (a *App) EnableRBAC(configPath ...string) {
 	var path string
 	if len(configPath) > 0 {
 		path = configPath[0]
-	} else {
+	}
+
+	a.enableRBAC(path, nil)
+}
+
+// EnableRBACWithSubjects enables RBAC like EnableRBAC, deriving the roles of the requests with custom subject
+// extractors before the ones of the config file. An empty configPath uses the default paths.
+//
+// Usage:
+//
+//	app.EnableRBACWithSubjects("", rbac.SubjectExtractorFunc(func(r *http.Request) ([]string, error) {
+//		return rolesOfTenant(r.Header.Get("X-Tenant-ID")), nil
+//	}))
+func (a *App) EnableRBACWithSubjects(configPath string, extractors ...rbac.SubjectExtractor) {
+	a.enableRBAC(configPath, extractors)
+}
+
+func (a *App) enableRBAC(path string, extractors []rbac.SubjectExtractor) {
+	if path == "" {
 		// Use rbac.DefaultConfigPath (empty string) to trigger default path resolution
 		path = rbac.ResolveRBACConfigPath(rbac.DefaultConfigPath)
 	}
@@ -46,6 +65,8 @@ func (a *App) EnableRBAC(configPath ...string) {
 		return
 	}
 
+	config.SubjectExtractors = extractors
+
 	a.Logger().Infof("Loaded RBAC config successfully")
 
 	// Apply middleware using the config
@@ -100,6 +100,14 @@ type Config struct {
 	// If set, role is extracted from JWT claims in request context
 	JWTClaimPath string `json:"jwtClaimPath,omitempty" yaml:"jwtClaimPath,omitempty"`
 
+	// Subject configures the built-in subject extractors, deriving the roles from JWT claims, API keys or client
+	// certificates. The subject extractors are tried before JWTClaimPath and RoleHeader.
+	Subject SubjectConfig `json:"subject,omitempty" yaml:"subject,omitempty"`
+
+	// SubjectExtractors are custom subject extractors, tried before the built-in ones.
+	// Set in code, see App.EnableRBACWithSubjects.
+	SubjectExtractors []SubjectExtractor `json:"-" yaml:"-"`
+
 	// ErrorHandler is called when authorization fails
 	// If nil, default error response is sent
 	ErrorHandler func(w http.ResponseWriter, r *http.Request, role, route string, err error)
@@ -172,6 +180,10 @@ func LoadPermissions(path string, logger datasource.Logger, metrics infra.Metric
 
 // validate validates the RBAC configuration.
 func (c *Config) validate() error {
+	if err := c.Subject.validate(); err != nil {
+		return err
+	}
+
 	// Validate endpoints: non-public endpoints must have RequiredPermissions
 	// Also validate that paths use mux patterns only (no wildcards or old regex)
 	for i, endpoint := range c.Endpoints {
@@ -126,8 +126,8 @@ func Middleware(config *Config) func(handler http.Handler) http.Handler {
 				return
 			}
 
-			// Extract role using header-based or JWT-based extraction
-			role, err := extractRole(r, config)
+			// Extract roles using the subject extractors, or header-based or JWT-based extraction
+			roles, err := extractRoles(r, config)
 			if err != nil {
 				if config.Metrics != nil {
 					config.Metrics.IncrementCounter(r.Context(), "rbac_role_extraction_failures")
@@ -141,10 +141,10 @@ func Middleware(config *Config) func(handler http.Handler) http.Handler {
 			// Role not included in traces for privacy (roles are PII)
 			// Only include authorization status (safe boolean) - set below after authorization check
 
-			// Check authorization using unified endpoint-based authorization
-			authorized, _ := checkEndpointAuthorization(role, endpoint, config)
+			// Check authorization using unified endpoint-based authorization, the subject needs ANY authorized role
+			role, authorized := authorizedRole(roles, endpoint, config)
 			if !authorized {
-				handleAuthError(w, r, config, role, routeLabel, ErrAccessDenied)
+				handleAuthError(w, r, config, strings.Join(roles, ","), routeLabel, ErrAccessDenied)
 
 				return
 			}
@@ -196,6 +196,38 @@ func handleAuthError(w http.ResponseWriter, r *http.Request, config *Config, rol
 	http.Error(w, "Forbidden: Access denied", http.StatusForbidden)
 }
 
+// extractRoles extracts the roles of the subject of the request with the subject extractors of config. Without
+// subject extractors, or when none finds the roles while JWTClaimPath or RoleHeader is set, the role is extracted
+// by extractRole.
+func extractRoles(r *http.Request, config *Config) ([]string, error) {
+	roles, configured := subjectRoles(r, config)
+	if len(roles) > 0 {
+		return roles, nil
+	}
+
+	if configured && config.JWTClaimPath == "" && config.RoleHeader == "" {
+		return nil, ErrRoleNotFound
+	}
+
+	role, err := extractRole(r, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{role}, nil
+}
+
+// authorizedRole returns the first of roles authorized to access the endpoint.
+func authorizedRole(roles []string, endpoint *EndpointMapping, config *Config) (string, bool) {
+	for _, role := range roles {
+		if authorized, _ := checkEndpointAuthorization(role, endpoint, config); authorized {
+			return role, true
+		}
+	}
+
+	return "", false
+}
+
 // extractRole extracts the user's role from the request.
 // Supports header-based extraction (via RoleHeader) or JWT-based extraction (via JWTClaimPath).
 // Precedence: JWT takes precedence over header (JWT is more secure).
@@ -0,0 +1,267 @@
+package rbac
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/sllt/kite/pkg/kite/http/middleware"
+)
+
+const defaultAPIKeyHeader = "X-Api-Key"
+
+var (
+	// errEmptySubjectClaimPath is returned when the JWT subject has no claim path.
+	errEmptySubjectClaimPath = errors.New("subject.jwt.claimPath is required")
+
+	// errEmptySANRoles is returned when the certificate subject maps no SAN to roles.
+	errEmptySANRoles = errors.New("subject.certificate.sanRoles is required")
+
+	// errAPIKeyStoreMissing is returned when the API key subject has neither keys, a store nor scopes.
+	errAPIKeyStoreMissing = errors.New("subject.apiKey requires keys, a store or scopes")
+)
+
+// SubjectExtractor derives the roles of the subject of a request, typically from the credentials verified by an
+// authentication middleware. It returns ErrRoleNotFound, or no roles, when the request has no subject it knows.
+type SubjectExtractor interface {
+	Roles(r *http.Request) ([]string, error)
+}
+
+// SubjectExtractorFunc adapts a function to a SubjectExtractor.
+type SubjectExtractorFunc func(r *http.Request) ([]string, error)
+
+// Roles calls f(r).
+func (f SubjectExtractorFunc) Roles(r *http.Request) ([]string, error) {
+	return f(r)
+}
+
+// SubjectConfig configures the built-in subject extractors. They are tried in the order JWT, APIKey, Certificate,
+// after the custom Config.SubjectExtractors.
+type SubjectConfig struct {
+	// JWT derives the roles from a claim of the JWT verified by the OAuth middleware.
+	JWT *JWTSubject `json:"jwt,omitempty" yaml:"jwt,omitempty"`
+
+	// APIKey derives the roles from the API key of the request, looked up in a store.
+	APIKey *APIKeySubject `json:"apiKey,omitempty" yaml:"apiKey,omitempty"`
+
+	// Certificate derives the roles from the SANs of the verified client certificate of mTLS connections.
+	Certificate *CertificateSubject `json:"certificate,omitempty" yaml:"certificate,omitempty"`
+}
+
+// JWTSubject derives the roles of a request from a claim of its JWT, e.g. "roles", "groups" or "realm_access.roles".
+// The claim may hold a string or an array of strings.
+type JWTSubject struct {
+	// ClaimPath is the path of the claim, with the syntax of Config.JWTClaimPath.
+	ClaimPath string `json:"claimPath" yaml:"claimPath"`
+
+	// ClaimRoles maps the values of the claim to roles, e.g. {"org-admins": ["admin"]}. When it is set, the values
+	// which are not mapped grant no role. Otherwise, the values of the claim are the roles.
+	ClaimRoles map[string][]string `json:"claimRoles,omitempty" yaml:"claimRoles,omitempty"`
+}
+
+// Roles returns the roles of the claim of the JWT claims set in the request context by the OAuth middleware.
+func (s *JWTSubject) Roles(r *http.Request) ([]string, error) {
+	claims, ok := r.Context().Value(middleware.JWTClaim).(jwt.MapClaims)
+	if !ok || claims == nil {
+		return nil, ErrRoleNotFound
+	}
+
+	value, err := extractClaimValue(claims, s.ClaimPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRoleNotFound, err)
+	}
+
+	var values []string
+
+	switch v := value.(type) {
+	case string:
+		values = []string{v}
+	case []any:
+		for _, item := range v {
+			if str, ok := item.(string); ok {
+				values = append(values, str)
+			}
+		}
+	case nil:
+	default:
+		values = []string{fmt.Sprint(v)}
+	}
+
+	return mapRoles(values, s.ClaimRoles), nil
+}
+
+// APIKeyStore looks up the roles of API keys, e.g. in a database. It returns no roles for unknown keys.
+type APIKeyStore interface {
+	Roles(ctx context.Context, key string) ([]string, error)
+}
+
+// APIKeyRoles is an APIKeyStore of static keys, mapped to their roles.
+type APIKeyRoles map[string][]string
+
+// Roles returns the roles of key. Keys are compared in constant time.
+func (k APIKeyRoles) Roles(_ context.Context, key string) ([]string, error) {
+	var roles []string
+
+	for candidate, candidateRoles := range k {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(key)) == 1 {
+			roles = candidateRoles
+		}
+	}
+
+	return roles, nil
+}
+
+// APIKeySubject derives the roles of a request from its API key: the key verified by the API key authentication
+// middleware if it ran, or else the value of Header.
+type APIKeySubject struct {
+	// Header holds the API key when no authentication middleware verified it. It defaults to X-Api-Key.
+	Header string `json:"header,omitempty" yaml:"header,omitempty"`
+
+	// Keys maps static API keys to their roles. They are only used when Store is not set.
+	Keys APIKeyRoles `json:"keys,omitempty" yaml:"keys,omitempty"`
+
+	// Store looks up the roles of the API keys. It can only be set in code, see App.EnableRBACWithSubjects.
+	Store APIKeyStore `json:"-" yaml:"-"`
+
+	// Scopes uses the scopes of the key verified by the middleware.APIKeys middleware as roles.
+	Scopes bool `json:"scopes,omitempty" yaml:"scopes,omitempty"`
+}
+
+// Roles returns the roles of the API key of the request.
+func (s *APIKeySubject) Roles(r *http.Request) ([]string, error) {
+	if managed := middleware.APIKeyFromContext(r.Context()); s.Scopes && managed != nil {
+		return managed.Scopes, nil
+	}
+
+	key, _ := r.Context().Value(middleware.APIKey).(string)
+	if key == "" {
+		header := s.Header
+		if header == "" {
+			header = defaultAPIKeyHeader
+		}
+
+		key = r.Header.Get(header)
+	}
+
+	if key == "" || (len(s.Keys) == 0 && s.Store == nil) {
+		return nil, ErrRoleNotFound
+	}
+
+	var store APIKeyStore = s.Keys
+	if s.Store != nil {
+		store = s.Store
+	}
+
+	return store.Roles(r.Context(), key)
+}
+
+// CertificateSubject derives the roles of a request from the SANs of its client certificate: DNS names, URIs, e.g.
+// SPIFFE IDs, and email addresses. Only the certificates verified by the TLS server are used, which requires its
+// tls.Config.ClientAuth to be tls.VerifyClientCertIfGiven or tls.RequireAndVerifyClientCert.
+type CertificateSubject struct {
+	// SANRoles maps the SANs to roles, e.g. {"spiffe://example.org/billing": ["billing-service"]}.
+	SANRoles map[string][]string `json:"sanRoles" yaml:"sanRoles"`
+}
+
+// Roles returns the roles of the SANs of the verified client certificate of the request.
+func (s *CertificateSubject) Roles(r *http.Request) ([]string, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, ErrRoleNotFound
+	}
+
+	cert := r.TLS.VerifiedChains[0][0]
+
+	sans := make([]string, 0, len(cert.DNSNames)+len(cert.URIs)+len(cert.EmailAddresses))
+	sans = append(sans, cert.DNSNames...)
+
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+
+	sans = append(sans, cert.EmailAddresses...)
+
+	return mapRoles(sans, s.SANRoles), nil
+}
+
+// mapRoles returns the roles of values: the roles they are mapped to when mapping is set, or else the values.
+// Duplicate roles are removed.
+func mapRoles(values []string, mapping map[string][]string) []string {
+	var roles []string
+
+	seen := make(map[string]bool)
+
+	add := func(role string) {
+		if role != "" && !seen[role] {
+			seen[role] = true
+
+			roles = append(roles, role)
+		}
+	}
+
+	for _, value := range values {
+		if len(mapping) == 0 {
+			add(value)
+
+			continue
+		}
+
+		for _, role := range mapping[value] {
+			add(role)
+		}
+	}
+
+	return roles
+}
+
+// validate validates the configuration of the built-in subject extractors.
+func (s *SubjectConfig) validate() error {
+	if s.JWT != nil && s.JWT.ClaimPath == "" {
+		return errEmptySubjectClaimPath
+	}
+
+	if s.APIKey != nil && len(s.APIKey.Keys) == 0 && s.APIKey.Store == nil && !s.APIKey.Scopes {
+		return errAPIKeyStoreMissing
+	}
+
+	if s.Certificate != nil && len(s.Certificate.SANRoles) == 0 {
+		return errEmptySANRoles
+	}
+
+	return nil
+}
+
+// subjectRoles returns the roles of the subject of the request, from the first subject extractor of config which
+// finds them. configured is false when config has no subject extractor.
+func subjectRoles(r *http.Request, config *Config) (roles []string, configured bool) {
+	extractors := make([]SubjectExtractor, 0, len(config.SubjectExtractors)+3)
+	extractors = append(extractors, config.SubjectExtractors...)
+
+	if config.Subject.JWT != nil {
+		extractors = append(extractors, config.Subject.JWT)
+	}
+
+	if config.Subject.APIKey != nil {
+		extractors = append(extractors, config.Subject.APIKey)
+	}
+
+	if config.Subject.Certificate != nil {
+		extractors = append(extractors, config.Subject.Certificate)
+	}
+
+	for _, extractor := range extractors {
+		roles, err := extractor.Roles(r)
+		if err == nil && len(roles) > 0 {
+			return roles, true
+		}
+
+		if err != nil && !errors.Is(err, ErrRoleNotFound) && config.Logger != nil {
+			config.Logger.Errorf("failed to extract the roles of the request subject: %v", err)
+		}
+	}
+
+	return nil, len(extractors) > 0
+}
@@ -0,0 +1,369 @@
+package rbac
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sllt/kite/pkg/kite/http/middleware"
+)
+
+var errStoreUnavailable = errors.New("store unavailable")
+
+func TestJWTSubject_Roles(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		subject       JWTSubject
+		claims        jwt.MapClaims
+		expectedRoles []string
+		expectedErr   error
+	}{
+		{
+			desc:          "string claim",
+			subject:       JWTSubject{ClaimPath: "role"},
+			claims:        jwt.MapClaims{"role": "admin"},
+			expectedRoles: []string{"admin"},
+		},
+		{
+			desc:          "array claim",
+			subject:       JWTSubject{ClaimPath: "roles"},
+			claims:        jwt.MapClaims{"roles": []any{"admin", "viewer", "admin"}},
+			expectedRoles: []string{"admin", "viewer"},
+		},
+		{
+			desc:          "nested claim",
+			subject:       JWTSubject{ClaimPath: "realm_access.roles"},
+			claims:        jwt.MapClaims{"realm_access": map[string]any{"roles": []any{"editor"}}},
+			expectedRoles: []string{"editor"},
+		},
+		{
+			desc: "mapped claim values",
+			subject: JWTSubject{
+				ClaimPath:  "groups",
+				ClaimRoles: map[string][]string{"org-admins": {"admin", "editor"}, "org-users": {"viewer"}},
+			},
+			claims:        jwt.MapClaims{"groups": []any{"org-admins", "unknown"}},
+			expectedRoles: []string{"admin", "editor"},
+		},
+		{
+			desc:        "missing claim",
+			subject:     JWTSubject{ClaimPath: "roles"},
+			claims:      jwt.MapClaims{"sub": "alice"},
+			expectedErr: ErrRoleNotFound,
+		},
+		{
+			desc:        "no claims",
+			subject:     JWTSubject{ClaimPath: "roles"},
+			expectedErr: ErrRoleNotFound,
+		},
+	}
+
+	for i, tc := range testCases {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		if tc.claims != nil {
+			req = req.WithContext(context.WithValue(req.Context(), middleware.JWTClaim, tc.claims))
+		}
+
+		roles, err := tc.subject.Roles(req)
+
+		require.ErrorIs(t, err, tc.expectedErr, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, tc.expectedRoles, roles, "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+type mockAPIKeyStore struct {
+	roles map[string][]string
+	err   error
+}
+
+func (m *mockAPIKeyStore) Roles(_ context.Context, key string) ([]string, error) {
+	return m.roles[key], m.err
+}
+
+func TestAPIKeySubject_Roles(t *testing.T) {
+	keys := APIKeyRoles{"key-1": {"admin"}, "key-2": {"viewer"}}
+
+	testCases := []struct {
+		desc          string
+		subject       APIKeySubject
+		ctxKey        string
+		header        string
+		headerValue   string
+		expectedRoles []string
+		expectedErr   error
+	}{
+		{
+			desc:          "key verified by the middleware",
+			subject:       APIKeySubject{Keys: keys},
+			ctxKey:        "key-1",
+			header:        "X-Api-Key",
+			headerValue:   "key-2",
+			expectedRoles: []string{"admin"},
+		},
+		{
+			desc:          "key of the default header",
+			subject:       APIKeySubject{Keys: keys},
+			header:        "X-Api-Key",
+			headerValue:   "key-2",
+			expectedRoles: []string{"viewer"},
+		},
+		{
+			desc:          "key of a custom header",
+			subject:       APIKeySubject{Header: "X-Service-Key", Keys: keys},
+			header:        "X-Service-Key",
+			headerValue:   "key-1",
+			expectedRoles: []string{"admin"},
+		},
+		{
+			desc:        "unknown key",
+			subject:     APIKeySubject{Keys: keys},
+			header:      "X-Api-Key",
+			headerValue: "key-3",
+		},
+		{
+			desc: "key of the store",
+			subject: APIKeySubject{
+				Keys:  keys,
+				Store: &mockAPIKeyStore{roles: map[string][]string{"key-3": {"billing"}}},
+			},
+			header:        "X-Api-Key",
+			headerValue:   "key-3",
+			expectedRoles: []string{"billing"},
+		},
+		{
+			desc:        "store error",
+			subject:     APIKeySubject{Store: &mockAPIKeyStore{err: errStoreUnavailable}},
+			header:      "X-Api-Key",
+			headerValue: "key-1",
+			expectedErr: errStoreUnavailable,
+		},
+		{
+			desc:        "no key",
+			subject:     APIKeySubject{Keys: keys},
+			expectedErr: ErrRoleNotFound,
+		},
+	}
+
+	for i, tc := range testCases {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		if tc.header != "" {
+			req.Header.Set(tc.header, tc.headerValue)
+		}
+
+		if tc.ctxKey != "" {
+			req = req.WithContext(context.WithValue(req.Context(), middleware.APIKey, tc.ctxKey))
+		}
+
+		roles, err := tc.subject.Roles(req)
+
+		require.ErrorIs(t, err, tc.expectedErr, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, tc.expectedRoles, roles, "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestAPIKeySubject_Scopes(t *testing.T) {
+	subject := &APIKeySubject{Scopes: true}
+
+	var roles []string
+
+	handler := middleware.APIKeys(middleware.APIKeysConfig{Keys: map[string][]string{"key-1": {"orders:read"}}})(
+		http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			roles, _ = subject.Roles(r)
+		}))
+
+	req := httptest.NewRequest(http.MethodGet, "/orders", http.NoBody)
+	req.Header.Set("X-Api-Key", "key-1")
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, []string{"orders:read"}, roles)
+
+	_, err := subject.Roles(req)
+	require.ErrorIs(t, err, ErrRoleNotFound, "keys not verified by the middleware have no scopes")
+}
+
+func TestCertificateSubject_Roles(t *testing.T) {
+	spiffeID, err := url.Parse("spiffe://example.org/billing")
+	require.NoError(t, err)
+
+	cert := &x509.Certificate{
+		DNSNames:       []string{"orders.internal"},
+		URIs:           []*url.URL{spiffeID},
+		EmailAddresses: []string{"ops@example.org"},
+	}
+
+	subject := CertificateSubject{SANRoles: map[string][]string{
+		"spiffe://example.org/billing": {"billing-service"},
+		"ops@example.org":              {"operator"},
+		"unused.internal":              {"admin"},
+	}}
+
+	testCases := []struct {
+		desc          string
+		tls           *tls.ConnectionState
+		expectedRoles []string
+		expectedErr   error
+	}{
+		{
+			desc:          "verified client certificate",
+			tls:           &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+			expectedRoles: []string{"billing-service", "operator"},
+		},
+		{
+			desc:        "unverified client certificate",
+			tls:         &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}},
+			expectedErr: ErrRoleNotFound,
+		},
+		{
+			desc:        "no TLS",
+			expectedErr: ErrRoleNotFound,
+		},
+	}
+
+	for i, tc := range testCases {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.TLS = tc.tls
+
+		roles, err := subject.Roles(req)
+
+		require.ErrorIs(t, err, tc.expectedErr, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, tc.expectedRoles, roles, "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestSubjectConfig_Validate(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		subject     SubjectConfig
+		expectedErr error
+	}{
+		{desc: "no subject"},
+		{desc: "JWT without claim path", subject: SubjectConfig{JWT: &JWTSubject{}}, expectedErr: errEmptySubjectClaimPath},
+		{desc: "API key without keys", subject: SubjectConfig{APIKey: &APIKeySubject{}}, expectedErr: errAPIKeyStoreMissing},
+		{desc: "API key scopes", subject: SubjectConfig{APIKey: &APIKeySubject{Scopes: true}}},
+		{
+			desc:    "API key with a store",
+			subject: SubjectConfig{APIKey: &APIKeySubject{Store: &mockAPIKeyStore{}}},
+		},
+		{
+			desc:        "certificate without SANs",
+			subject:     SubjectConfig{Certificate: &CertificateSubject{}},
+			expectedErr: errEmptySANRoles,
+		},
+	}
+
+	for i, tc := range testCases {
+		err := tc.subject.validate()
+
+		assert.ErrorIs(t, err, tc.expectedErr, "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestMiddleware_SubjectRoles(t *testing.T) {
+	config := &Config{
+		Subject: SubjectConfig{
+			JWT:    &JWTSubject{ClaimPath: "groups", ClaimRoles: map[string][]string{"org-users": {"viewer"}}},
+			APIKey: &APIKeySubject{Keys: APIKeyRoles{"key-1": {"auditor", "admin"}}},
+		},
+		SubjectExtractors: []SubjectExtractor{
+			SubjectExtractorFunc(func(r *http.Request) ([]string, error) {
+				if r.Header.Get("X-Tenant-ID") == "" {
+					return nil, ErrRoleNotFound
+				}
+
+				return []string{"editor"}, nil
+			}),
+		},
+		Roles: []RoleDefinition{
+			{Name: "viewer", Permissions: []string{"users:read"}},
+			{Name: "auditor", Permissions: []string{"audit:read"}},
+			{Name: "editor", Permissions: []string{"users:read", "users:write"}},
+			{Name: "admin", Permissions: []string{"users:read", "users:write"}},
+		},
+		Endpoints: []EndpointMapping{
+			{Path: "/api/users", Methods: []string{"POST"}, RequiredPermissions: []string{"users:write"}},
+		},
+	}
+	require.NoError(t, config.processUnifiedConfig())
+
+	var grantingRole any
+
+	handler := Middleware(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		grantingRole = r.Context().Value(userRole)
+
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	testCases := []struct {
+		desc         string
+		setup        func(r *http.Request) *http.Request
+		expectedCode int
+		expectedRole any
+	}{
+		{
+			desc: "custom extractor",
+			setup: func(r *http.Request) *http.Request {
+				r.Header.Set("X-Tenant-ID", "acme")
+				return r
+			},
+			expectedCode: http.StatusOK,
+			expectedRole: "editor",
+		},
+		{
+			desc: "any role of the API key grants access",
+			setup: func(r *http.Request) *http.Request {
+				r.Header.Set("X-Api-Key", "key-1")
+				return r
+			},
+			expectedCode: http.StatusOK,
+			expectedRole: "admin",
+		},
+		{
+			desc: "JWT role without the permission",
+			setup: func(r *http.Request) *http.Request {
+				claims := jwt.MapClaims{"groups": []any{"org-users"}}
+				return r.WithContext(context.WithValue(r.Context(), middleware.JWTClaim, claims))
+			},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			desc:         "no subject",
+			setup:        func(r *http.Request) *http.Request { return r },
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for i, tc := range testCases {
+		grantingRole = nil
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, tc.setup(httptest.NewRequest(http.MethodPost, "/api/users", http.NoBody)))
+
+		assert.Equal(t, tc.expectedCode, w.Code, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, tc.expectedRole, grantingRole, "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestExtractRoles_FallsBackToRoleHeader(t *testing.T) {
+	config := &Config{
+		RoleHeader: "X-User-Role",
+		Subject:    SubjectConfig{APIKey: &APIKeySubject{Keys: APIKeyRoles{"key-1": {"admin"}}}},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	req.Header.Set("X-User-Role", "viewer")
+
+	roles, err := extractRoles(req, config)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"viewer"}, roles)
+}
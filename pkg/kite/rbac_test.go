@@ -1,12 +1,16 @@
 package kite
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/sllt/kite/pkg/kite/rbac"
 	"github.com/sllt/kite/pkg/kite/testutil"
 )
 
@@ -127,6 +131,44 @@ func TestEnableRBAC_WithDefaultConfigPath(t *testing.T) {
 	}
 }
 
+func TestApp_EnableRBACWithSubjects(t *testing.T) {
+	_ = testutil.NewServerConfigs(t)
+
+	path, err := createTestConfigFile("test_subjects.json", `{
+		"roles": [{"name": "tenant-admin", "permissions": ["users:read"]}],
+		"endpoints": [{"path": "/api/users", "methods": ["GET"], "requiredPermissions": ["users:read"]}]
+	}`)
+	require.NoError(t, err)
+
+	defer os.Remove(path)
+
+	app := New()
+	app.EnableRBACWithSubjects(path, rbac.SubjectExtractorFunc(func(r *http.Request) ([]string, error) {
+		if r.Header.Get("X-Tenant-ID") == "acme" {
+			return []string{"tenant-admin"}, nil
+		}
+
+		return nil, rbac.ErrRoleNotFound
+	}))
+
+	app.GET("/api/users", func(*Context) (any, error) {
+		return "users", nil
+	})
+
+	app.httpServer.registry.compile(app.httpServer.router.Mux(), app.container, 0)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users", http.NoBody)
+	req.Header.Set("X-Tenant-ID", "acme")
+
+	rec := httptest.NewRecorder()
+	app.httpServer.router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	app.httpServer.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users", http.NoBody))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
 func createTestConfigFile(filename, content string) (string, error) {
 	dir := filepath.Dir(filename)
 	if dir != "." && dir != "" {
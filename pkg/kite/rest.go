@@ -41,11 +41,12 @@ func (a *App) add(method, pattern string, h Handler) {
 		Method:  method,
 		Pattern: pattern,
 		Handler: h,
+		source:  routeSource(),
 	})
 }
 
 func (a *App) ensureHTTPAvailable() {
-	if !a.httpRegistered && !isPortAvailable(a.httpServer.port) {
+	if !a.httpRegistered && a.httpServer.bind.usesPort() && !isPortAvailable(a.httpServer.port) {
 		a.container.Logger.Fatalf("http port %d is blocked or unreachable", a.httpServer.port)
 	}
 
@@ -57,7 +58,7 @@ func (a *App) canMutateRoutes(action string) bool {
 		return false
 	}
 
-	if a.httpServer.registry.compiled {
+	if a.httpServer.registry.compiled || (a.adminServer != nil && a.adminServer.registry.compiled) {
 		a.container.Logger.Errorf("cannot %s after routes have been compiled; register all routes and middleware before Run", action)
 		return false
 	}
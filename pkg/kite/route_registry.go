@@ -9,6 +9,8 @@ import (
 	"github.com/go-chi/chi/v5"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 
+	kiteHTTP "github.com/sllt/kite/pkg/kite/http"
+	"github.com/sllt/kite/pkg/kite/http/middleware"
 	"github.com/sllt/kite/pkg/kite/infra"
 )
 
@@ -23,6 +25,9 @@ type RouteDef struct {
 	Pattern        string
 	Handler        Handler
 	RequestTimeout time.Duration
+
+	// source is the location of the code which registered the route, reported in the route table and conflicts.
+	source string
 }
 
 // GroupNode is a node in the route group tree.
@@ -33,6 +38,7 @@ type GroupNode struct {
 	kiteMWs  []KiteMiddleware
 	routes   []RouteDef
 	children []*GroupNode
+	version  string // API version served by this group, set via RouteGroup.Version
 }
 
 // RouteGroup is the public API for declaring routes and middleware within a group.
@@ -45,6 +51,10 @@ type RouteGroup struct {
 type RouteRegistry struct {
 	root     *GroupNode
 	compiled bool
+	// errorMode is the level of detail of the errors responded by the handlers.
+	errorMode kiteHTTP.ErrorMode
+	// afterHandlers post-process the results of the handlers, see App.AfterHandler.
+	afterHandlers []AfterHandlerFunc
 }
 
 func newRouteRegistry() *RouteRegistry {
@@ -95,6 +105,7 @@ func (g *RouteGroup) addRoute(method, pattern string, h Handler, timeout time.Du
 		Pattern:        pattern,
 		Handler:        h,
 		RequestTimeout: timeout,
+		source:         routeSource(),
 	})
 }
 
@@ -120,6 +131,12 @@ func (g *RouteGroup) UseMiddleware(mws ...KiteMiddleware) *RouteGroup {
 	return g
 }
 
+// StrictJSON makes the routes of this group reject the JSON bodies with fields which are not in the struct they are
+// bound to, with 400 Bad Request. It applies to the groups created from this one as well.
+func (g *RouteGroup) StrictJSON() *RouteGroup {
+	return g.Use(middleware.StrictJSON)
+}
+
 // Group creates or gets a child route group with the given prefix and returns it.
 // An optional callback can be provided for backward-compatible inline registration.
 func (g *RouteGroup) Group(prefix string, fns ...func(sub *RouteGroup)) *RouteGroup {
@@ -258,6 +275,14 @@ func (reg *RouteRegistry) registerRoutes(
 	defaultTimeout time.Duration,
 	kiteMWs []KiteMiddleware,
 ) {
+	explicitHEAD := make(map[string]bool)
+
+	for _, rd := range routes {
+		if rd.Method == http.MethodHead {
+			explicitHEAD[rd.Pattern] = true
+		}
+	}
+
 	for _, rd := range routes {
 		timeout := rd.RequestTimeout
 		if timeout == 0 {
@@ -270,10 +295,18 @@ func (reg *RouteRegistry) registerRoutes(
 			function:       composedFn,
 			container:      container,
 			requestTimeout: timeout,
+			errorMode:      reg.errorMode,
+			afterHandlers:  reg.afterHandlers,
 		}
 
 		otelH := otelhttp.NewHandler(h, "kite-router")
 		router.Method(rd.Method, rd.Pattern, otelH)
+
+		// GET routes also answer HEAD requests, the server discards the body of the response while keeping
+		// its headers, e.g. Content-Length.
+		if rd.Method == http.MethodGet && !explicitHEAD[rd.Pattern] {
+			router.Method(http.MethodHead, rd.Pattern, otelH)
+		}
 	}
 }
 
@@ -322,6 +355,10 @@ func (g *GroupNode) mergeFrom(other *GroupNode) {
 	g.kiteMWs = append(g.kiteMWs, other.kiteMWs...)
 	g.routes = append(g.routes, other.routes...)
 	g.children = append(g.children, other.children...)
+
+	if g.version == "" {
+		g.version = other.version
+	}
 }
 
 func mergeChildrenByPrefix(children []*GroupNode) []*GroupNode {
@@ -5,10 +5,12 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/sllt/kite/pkg/kite/config"
 	kiteHTTP "github.com/sllt/kite/pkg/kite/http"
@@ -545,3 +547,76 @@ func newRouteRegistryTestApp() *App {
 		httpRegistered: true,
 	}
 }
+
+func TestRouteRegistry_HEADAndMethodNotAllowed(t *testing.T) {
+	app := newRouteRegistryTestApp()
+
+	app.Group("/api").GET("/users", func(*Context) (any, error) {
+		return "users", nil
+	})
+
+	app.httpServer.registry.compile(app.httpServer.router.Mux(), app.container, 0)
+	app.httpServer.router.MethodNotAllowed(handler{function: methodNotAllowedHandler, container: app.container})
+
+	server := httptest.NewServer(app.httpServer.router)
+	defer server.Close()
+
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodHead, server.URL+"/api/users", http.NoBody)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, body)
+
+	req, _ = http.NewRequestWithContext(t.Context(), http.MethodDelete, server.URL+"/api/users", http.NoBody)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	assert.Equal(t, "GET, HEAD, OPTIONS", resp.Header.Get("Allow"))
+	assert.Contains(t, string(body), "method not allowed")
+}
+
+func TestRouteGroup_StrictJSON(t *testing.T) {
+	app := newRouteRegistryTestApp()
+
+	type order struct {
+		ID int `json:"id"`
+	}
+
+	handler := func(c *Context) (any, error) {
+		var o order
+
+		return o, c.Bind(&o)
+	}
+
+	app.Group("/strict").StrictJSON().Group("/v1").POST("/orders", handler)
+	app.POST("/lenient/orders", handler)
+
+	app.httpServer.registry.compile(app.httpServer.router.Mux(), app.container, 0)
+
+	tests := []struct {
+		desc   string
+		path   string
+		status int
+	}{
+		{desc: "strict group", path: "/strict/v1/orders", status: http.StatusBadRequest},
+		{desc: "default", path: "/lenient/orders", status: http.StatusCreated},
+	}
+
+	for i, tc := range tests {
+		req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(`{"id":1,"qty":2}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		rec := httptest.NewRecorder()
+		app.httpServer.router.ServeHTTP(rec, req)
+
+		assert.Equal(t, tc.status, rec.Code, "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
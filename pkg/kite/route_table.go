@@ -0,0 +1,161 @@
+package kite
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// builtinRouteSource is the source of the routes registered by kite itself, e.g. the health check routes.
+const builtinRouteSource = "kite"
+
+var errRouteConflict = errors.New("conflicting routes")
+
+// kitePackage is the prefix of the function names of this package, used to find the caller registering a route.
+var kitePackage = reflect.TypeOf(App{}).PkgPath() + "."
+
+// routeParamRegex matches the name of a chi URL parameter, with its optional regexp, e.g. {id} or {id:[0-9]+}.
+var routeParamRegex = regexp.MustCompile(`\{[^}:]*(:[^}]*)?}`)
+
+// routeInfo describes a route of the registry as it is compiled to the router. It is listed by the admin API, with
+// its timeout in nanoseconds.
+type routeInfo struct {
+	Method      string        `json:"method"`
+	Pattern     string        `json:"pattern"`
+	Group       string        `json:"group"`
+	Middlewares int           `json:"middlewares"`
+	Timeout     time.Duration `json:"timeout,omitempty"`
+	Source      string        `json:"source,omitempty"`
+}
+
+// routeSource returns the location of the first caller outside this package, which registered the route. Routes
+// registered while setting up the HTTP server are reported as built-in.
+func routeSource() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+
+	for {
+		frame, more := frames.Next()
+
+		if frame.Function == kitePackage+"(*App).httpServerSetup" {
+			return builtinRouteSource
+		}
+
+		if !strings.HasPrefix(frame.Function, kitePackage) || strings.HasSuffix(frame.File, "_test.go") {
+			return filepath.Join(filepath.Base(filepath.Dir(frame.File)), filepath.Base(frame.File)) +
+				fmt.Sprintf(":%d", frame.Line)
+		}
+
+		if !more {
+			return ""
+		}
+	}
+}
+
+// routes returns the routes of the registry with their full patterns, in the order they are compiled.
+func (reg *RouteRegistry) routes(defaultTimeout time.Duration) []routeInfo {
+	var routes []routeInfo
+
+	var walk func(node *GroupNode, prefix string, middlewares int)
+
+	walk = func(node *GroupNode, prefix string, middlewares int) {
+		if node == nil {
+			return
+		}
+
+		prefix += normalizeGroupPrefix(node.prefix)
+		middlewares += len(node.httpMWs) + len(node.kiteMWs)
+
+		group := prefix
+		if group == "" {
+			group = "/"
+		}
+
+		for _, rd := range node.routes {
+			timeout := rd.RequestTimeout
+			if timeout == 0 {
+				timeout = defaultTimeout
+			}
+
+			routes = append(routes, routeInfo{
+				Method:      rd.Method,
+				Pattern:     prefix + rd.Pattern,
+				Group:       group,
+				Middlewares: middlewares,
+				Timeout:     timeout,
+				Source:      rd.source,
+			})
+		}
+
+		for _, child := range node.children {
+			walk(child, prefix, middlewares)
+		}
+	}
+
+	walk(reg.root, "", 0)
+
+	return routes
+}
+
+// checkConflicts returns an error listing the routes registered more than once for the same method and pattern,
+// which would otherwise silently replace each other in the router. Patterns only differing by the names of their
+// URL parameters conflict as well.
+func (reg *RouteRegistry) checkConflicts() error {
+	routes := reg.routes(0)
+	seen := make(map[string]int, len(routes))
+
+	var conflicts []string
+
+	for i, r := range routes {
+		key := r.Method + " " + routeParamRegex.ReplaceAllString(r.Pattern, "{$1}")
+
+		first, ok := seen[key]
+		if !ok {
+			seen[key] = i
+			continue
+		}
+
+		conflicts = append(conflicts, fmt.Sprintf("%s %s registered at %s conflicts with %s %s registered at %s",
+			r.Method, r.Pattern, r.Source, routes[first].Method, routes[first].Pattern, routes[first].Source))
+	}
+
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", errRouteConflict, strings.Join(conflicts, "; "))
+}
+
+// logRoutes logs the route table of the application at INFO.
+func (a *App) logRoutes(defaultTimeout time.Duration) {
+	routes := a.httpServer.registry.routes(defaultTimeout)
+
+	var sb strings.Builder
+
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tPATTERN\tGROUP\tMIDDLEWARES\tTIMEOUT\tSOURCE")
+
+	for _, r := range routes {
+		timeout := "none"
+		if r.Timeout > 0 {
+			timeout = r.Timeout.String()
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n", r.Method, r.Pattern, r.Group, r.Middlewares, timeout, r.Source)
+	}
+
+	_ = w.Flush()
+
+	a.container.Logger.Infof("Starting %s %s with %d routes", a.container.GetAppName(), a.container.GetAppVersion(),
+		len(routes))
+
+	for _, line := range strings.Split(strings.TrimSuffix(sb.String(), "\n"), "\n") {
+		a.container.Logger.Info(line)
+	}
+}
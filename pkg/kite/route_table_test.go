@@ -0,0 +1,92 @@
+package kite
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sllt/kite/pkg/kite/testutil"
+)
+
+func routeTableTestHandler(*Context) (any, error) {
+	return "ok", nil
+}
+
+func TestRouteRegistry_Routes(t *testing.T) {
+	app := newRouteRegistryTestApp()
+
+	app.GET("/hello", routeTableTestHandler)
+
+	api := app.Group("/api")
+	api.Use(func(h http.Handler) http.Handler { return h })
+
+	v1 := api.Group("v1/")
+	v1.UseMiddleware(func(next Handler) Handler { return next })
+	v1.POST("/users/{id}", routeTableTestHandler)
+
+	routes := app.httpServer.registry.routes(5 * time.Second)
+
+	require.Len(t, routes, 2)
+
+	assert.Equal(t, "GET", routes[0].Method)
+	assert.Equal(t, "/hello", routes[0].Pattern)
+	assert.Equal(t, "/", routes[0].Group)
+	assert.Equal(t, 0, routes[0].Middlewares)
+	assert.Equal(t, 5*time.Second, routes[0].Timeout)
+	assert.Contains(t, routes[0].Source, "route_table_test.go:")
+
+	assert.Equal(t, "POST", routes[1].Method)
+	assert.Equal(t, "/api/v1/users/{id}", routes[1].Pattern)
+	assert.Equal(t, "/api/v1", routes[1].Group)
+	assert.Equal(t, 2, routes[1].Middlewares)
+}
+
+func TestRouteRegistry_CheckConflicts(t *testing.T) {
+	app := newRouteRegistryTestApp()
+
+	app.GET("/api/users/{id}", routeTableTestHandler)
+	app.POST("/api/users/{id}", routeTableTestHandler)
+	app.Group("/api").GET("/orders", routeTableTestHandler)
+
+	require.NoError(t, app.httpServer.registry.checkConflicts())
+
+	app.Group("/api").GET("/users/{userID}", routeTableTestHandler)
+
+	err := app.httpServer.registry.checkConflicts()
+
+	require.ErrorIs(t, err, errRouteConflict)
+	assert.Contains(t, err.Error(), "GET /api/users/{userID} registered at kite/route_table_test.go:")
+	assert.Contains(t, err.Error(), "conflicts with GET /api/users/{id} registered at kite/route_table_test.go:")
+}
+
+func TestRouteRegistry_CheckConflictsParamPatterns(t *testing.T) {
+	app := newRouteRegistryTestApp()
+
+	app.GET("/files/{id:[0-9]+}", routeTableTestHandler)
+	app.GET("/files/{name:[a-z]+}", routeTableTestHandler)
+
+	require.NoError(t, app.httpServer.registry.checkConflicts(), "parameters with different regexps do not conflict")
+
+	app.GET("/files/{fileID:[0-9]+}", routeTableTestHandler)
+
+	require.ErrorIs(t, app.httpServer.registry.checkConflicts(), errRouteConflict)
+}
+
+func TestApp_LogRoutes(t *testing.T) {
+	logs := testutil.StdoutOutputForFunc(func() {
+		app := newRouteRegistryTestApp()
+
+		app.GET("/hello", routeTableTestHandler)
+		app.Group("/api").DELETE("/users/{id}", routeTableTestHandler)
+
+		app.logRoutes(0)
+	})
+
+	assert.Contains(t, logs, "with 2 routes")
+	assert.Regexp(t, `METHOD\s+PATTERN\s+GROUP\s+MIDDLEWARES\s+TIMEOUT\s+SOURCE`, logs)
+	assert.Regexp(t, `GET\s+/hello\s+/\s+0\s+none\s+kite/route_table_test.go:\d+`, logs)
+	assert.Regexp(t, `DELETE\s+/api/users/\{id}\s+/api\s+0\s+none`, logs)
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"sync"
 	"syscall"
 	"time"
@@ -13,6 +14,19 @@ import (
 
 // Run starts the application. If it is an HTTP server, it will start the server.
 func (a *App) Run() {
+	// kite migrate up starts the application to apply its migrations only.
+	if migrationsOnly, _ := strconv.ParseBool(a.Config.Get("MIGRATIONS_ONLY")); migrationsOnly {
+		a.Logger().Info("Exiting after the migrations as MIGRATIONS_ONLY is set")
+
+		return
+	}
+
+	if err := a.runStartup(context.Background()); err != nil {
+		a.Logger().Errorf("Startup failed: %v", err)
+
+		return
+	}
+
 	if a.cmd != nil {
 		a.cmd.Run(a.container)
 	}
@@ -21,7 +35,9 @@ func (a *App) Run() {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
-	if !a.handleStartupHooks(ctx) {
+	if err := a.waitForDependencies(ctx); err != nil {
+		a.Logger().Errorf("Startup failed: %v", err)
+
 		return
 	}
 
@@ -30,7 +46,24 @@ func (a *App) Run() {
 		a.Logger().Errorf("error parsing value of shutdown timeout from config: %v. Setting default timeout of 30 sec.", err)
 	}
 
+	// `<binary> run <name>` runs a command registered with Command instead of serving.
+	if ran, err := a.runCommand(ctx, os.Args[1:]); ran {
+		a.shutdownCommand(ctx, timeout, err)
+
+		return
+	}
+
+	if !a.handleStartupHooks(ctx) {
+		return
+	}
+
+	// a single record of the configuration which is actually running, to debug deployments.
+	if a.cmd == nil {
+		a.Logger().Info(a.configReport())
+	}
+
 	a.startShutdownHandler(ctx, timeout)
+	a.startGoroutineDumpHandler(ctx)
 	a.startTelemetryIfEnabled()
 	a.startAllServers(ctx)
 }
@@ -52,12 +85,31 @@ func (a *App) handleStartupHooks(ctx context.Context) bool {
 	return true
 }
 
+// shutdownCommand shuts the application down once a command has run, and exits with the status 1 when it failed.
+func (a *App) shutdownCommand(ctx context.Context, timeout time.Duration, err error) {
+	shutdownCtx, done := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer done()
+
+	if shutdownErr := a.Shutdown(shutdownCtx); shutdownErr != nil {
+		a.Logger().Debugf("Shutdown after the command failed: %v", shutdownErr)
+	}
+
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
 // startShutdownHandler starts a goroutine to handle graceful shutdown.
 func (a *App) startShutdownHandler(ctx context.Context, timeout time.Duration) {
 	// Goroutine to handle shutdown when context is canceled
 	go func() {
 		<-ctx.Done()
 
+		// a second signal ends the drain early.
+		drainCtx, stopDrain := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+		a.drain(drainCtx, getReadinessDrainDelay(a.Logger(), a.Config))
+		stopDrain()
+
 		// Create a shutdown context with a timeout
 		shutdownCtx, done := context.WithTimeout(context.WithoutCancel(ctx), timeout)
 		defer done()
@@ -88,7 +140,10 @@ func (a *App) startAllServers(ctx context.Context) {
 
 	a.startMetricsServer(&wg)
 	a.startHTTPServer(&wg)
+	a.startAdminServer(&wg)
 	a.startGRPCServer(&wg)
+	a.startGRPCHealthMonitor(ctx)
+	a.startSyntheticChecks(ctx)
 	a.startSubscriptionManager(ctx, &wg)
 
 	wg.Wait()
@@ -136,6 +191,13 @@ func (a *App) startGRPCServer(wg *sync.WaitGroup) {
 	}
 }
 
+// startGRPCHealthMonitor starts updating the serving status of the gRPC services, until ctx is done.
+func (a *App) startGRPCHealthMonitor(ctx context.Context) {
+	if a.grpcRegistered && a.grpcHealth != nil {
+		go a.grpcHealth.run(ctx, a.container.DependenciesUp)
+	}
+}
+
 // startSubscriptionManager starts the subscription manager.
 func (a *App) startSubscriptionManager(ctx context.Context, wg *sync.WaitGroup) {
 	wg.Add(1)
@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"sync"
 	"time"
+
+	"github.com/sllt/kite/pkg/kite/infra/circuitbreaker"
 )
 
 // circuitBreaker states.
@@ -24,6 +26,11 @@ var (
 type CircuitBreakerConfig struct {
 	Threshold int           // Threshold represents the max no of retry before switching the circuit breaker state.
 	Interval  time.Duration // Interval represents the time interval duration between hitting the HealthURL
+
+	// Breaker, when set, protects the service with a breaker of the container, see infra.Container.CircuitBreaker,
+	// instead of counting consecutive failures and probing the health endpoint. Threshold and Interval are then
+	// ignored. A breaker can be shared by several services, e.g. the services of the same upstream.
+	Breaker *circuitbreaker.Breaker
 }
 
 // circuitBreaker represents a circuit breaker implementation.
@@ -162,6 +169,10 @@ func (cb *circuitBreaker) resetFailureCount() {
 }
 
 func (cb *CircuitBreakerConfig) AddOption(h HTTP) HTTP {
+	if cb.Breaker != nil {
+		return &sharedCircuitBreaker{breaker: cb.Breaker, HTTP: h}
+	}
+
 	circuitBreaker := NewCircuitBreaker(*cb, h)
 
 	if httpSvc := extractHTTPService(h); httpSvc != nil {
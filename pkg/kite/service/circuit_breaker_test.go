@@ -13,6 +13,7 @@ import (
 	"go.opentelemetry.io/otel"
 	"go.uber.org/mock/gomock"
 
+	"github.com/sllt/kite/pkg/kite/infra/circuitbreaker"
 	"github.com/sllt/kite/pkg/kite/logging"
 	"github.com/sllt/kite/pkg/kite/testutil"
 )
@@ -927,3 +928,53 @@ func TestCircuitBreaker_HealthEndpointWithTimeout(t *testing.T) {
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 	resp.Body.Close()
 }
+
+func TestCircuitBreaker_SharedBreaker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/error":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	ctrl := gomock.NewController(t)
+	mockMetric := NewMockMetrics(ctrl)
+
+	mockMetric.EXPECT().RecordHistogram(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	breaker := circuitbreaker.New(circuitbreaker.Config{Name: "test-service", MinRequests: 2, OpenTimeout: time.Minute},
+		nil, nil)
+
+	httpSvc := NewHTTPService(server.URL, logging.NewMockLogger(logging.DEBUG), mockMetric,
+		&CircuitBreakerConfig{Breaker: breaker})
+
+	// 500 responses are returned by the service itself and do not count as failures
+	resp, err := httpSvc.Get(t.Context(), "error", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	resp, err = httpSvc.Get(t.Context(), "error", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, circuitbreaker.Closed, breaker.State())
+
+	resp, err = httpSvc.Get(t.Context(), "unavailable", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	resp, err = httpSvc.Post(t.Context(), "unavailable", nil, nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, circuitbreaker.Open, breaker.State())
+
+	resp, err = httpSvc.Get(t.Context(), "success", nil)
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Nil(t, resp)
+}
@@ -121,6 +121,8 @@ func extractHTTPService(h HTTP) *httpService {
 		return v
 	case *circuitBreaker:
 		return extractHTTPService(v.HTTP)
+	case *sharedCircuitBreaker:
+		return extractHTTPService(v.HTTP)
 	case *retryProvider:
 		return extractHTTPService(v.HTTP)
 	case *authProvider:
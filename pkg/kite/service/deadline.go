@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RequestTimeoutHeader carries the time, in milliseconds, the caller waits for the response of a request. It is set
+// from the deadline of the context of the calls made by the HTTP services, and kite servers apply it to the requests
+// they serve, so that a deadline is propagated through all the services handling a request.
+const RequestTimeoutHeader = "X-Request-Timeout"
+
+// setRequestTimeout sets RequestTimeoutHeader from the deadline of ctx, unless the caller already set it.
+func setRequestTimeout(ctx context.Context, h http.Header) {
+	deadline, ok := ctx.Deadline()
+	if !ok || h.Get(RequestTimeoutHeader) != "" {
+		return
+	}
+
+	timeout := max(time.Until(deadline).Milliseconds(), 0)
+
+	h.Set(RequestTimeoutHeader, strconv.FormatInt(timeout, 10))
+}
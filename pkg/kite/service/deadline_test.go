@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetRequestTimeout(t *testing.T) {
+	h := http.Header{}
+
+	setRequestTimeout(t.Context(), h)
+	assert.Empty(t, h.Get(RequestTimeoutHeader), "no header must be set without a deadline")
+
+	ctx, cancel := context.WithTimeout(t.Context(), 2*time.Second)
+	defer cancel()
+
+	setRequestTimeout(ctx, h)
+
+	ms, err := strconv.Atoi(h.Get(RequestTimeoutHeader))
+	require.NoError(t, err)
+	assert.InDelta(t, 2000, ms, 100)
+
+	h.Set(RequestTimeoutHeader, "500")
+	setRequestTimeout(ctx, h)
+	assert.Equal(t, "500", h.Get(RequestTimeoutHeader), "the header set by the caller must be kept")
+
+	expired, cancel := context.WithDeadline(t.Context(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	h = http.Header{}
+	setRequestTimeout(expired, h)
+	assert.Equal(t, "0", h.Get(RequestTimeoutHeader))
+}
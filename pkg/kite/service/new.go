@@ -170,6 +170,8 @@ func (h *httpService) createAndSendRequest(ctx context.Context, method string, p
 		req.Header.Set("Content-Type", "application/json")
 	}
 
+	setRequestTimeout(ctx, req.Header)
+
 	// Inject tracing information into the request headers.
 	otel.GetTextMapPropagator().Inject(clientTraceCtx, propagation.HeaderCarrier(req.Header))
 
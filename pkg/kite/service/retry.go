@@ -3,16 +3,29 @@ package service
 import (
 	"context"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
+const defaultMaxRetryAfter = 10 * time.Second
+
 type RetryConfig struct {
 	MaxRetries int
+	// MaxRetryAfter is the longest Retry-After of the 429 Too Many Requests and 5xx responses waited before retrying,
+	// 10 seconds by default. The responses asking to wait longer are returned without retrying.
+	MaxRetryAfter time.Duration
 }
 
 func (r *RetryConfig) AddOption(h HTTP) HTTP {
 	rp := &retryProvider{
-		maxRetries: r.MaxRetries,
-		HTTP:       h,
+		maxRetries:    r.MaxRetries,
+		maxRetryAfter: r.MaxRetryAfter,
+		HTTP:          h,
+	}
+
+	if rp.maxRetryAfter <= 0 {
+		rp.maxRetryAfter = defaultMaxRetryAfter
 	}
 
 	if httpSvc := extractHTTPService(h); httpSvc != nil {
@@ -24,29 +37,30 @@ func (r *RetryConfig) AddOption(h HTTP) HTTP {
 }
 
 type retryProvider struct {
-	maxRetries  int
-	metrics     Metrics
-	serviceName string
+	maxRetries    int
+	maxRetryAfter time.Duration
+	metrics       Metrics
+	serviceName   string
 	HTTP
 }
 
 func (rp *retryProvider) Get(ctx context.Context, path string, queryParams map[string]any) (*http.Response,
 	error) {
-	return rp.doWithRetry(func() (*http.Response, error) {
+	return rp.doWithRetry(ctx, func() (*http.Response, error) {
 		return rp.HTTP.Get(ctx, path, queryParams)
 	})
 }
 
 func (rp *retryProvider) GetWithHeaders(ctx context.Context, path string, queryParams map[string]any,
 	headers map[string]string) (*http.Response, error) {
-	return rp.doWithRetry(func() (*http.Response, error) {
+	return rp.doWithRetry(ctx, func() (*http.Response, error) {
 		return rp.HTTP.GetWithHeaders(ctx, path, queryParams, headers)
 	})
 }
 
 func (rp *retryProvider) Post(ctx context.Context, path string, queryParams map[string]any,
 	body []byte) (*http.Response, error) {
-	return rp.doWithRetry(func() (*http.Response, error) {
+	return rp.doWithRetry(ctx, func() (*http.Response, error) {
 		return rp.HTTP.Post(ctx, path, queryParams, body)
 	})
 }
@@ -54,53 +68,54 @@ func (rp *retryProvider) Post(ctx context.Context, path string, queryParams map[
 func (rp *retryProvider) PostWithHeaders(ctx context.Context, path string, queryParams map[string]any,
 	body []byte,
 	headers map[string]string) (*http.Response, error) {
-	return rp.doWithRetry(func() (*http.Response, error) {
+	return rp.doWithRetry(ctx, func() (*http.Response, error) {
 		return rp.HTTP.PostWithHeaders(ctx, path, queryParams, body, headers)
 	})
 }
 
 func (rp *retryProvider) Put(ctx context.Context, api string, queryParams map[string]any, body []byte) (
 	*http.Response, error) {
-	return rp.doWithRetry(func() (*http.Response, error) {
+	return rp.doWithRetry(ctx, func() (*http.Response, error) {
 		return rp.HTTP.Put(ctx, api, queryParams, body)
 	})
 }
 
 func (rp *retryProvider) PutWithHeaders(ctx context.Context, path string, queryParams map[string]any, body []byte,
 	headers map[string]string) (*http.Response, error) {
-	return rp.doWithRetry(func() (*http.Response, error) {
+	return rp.doWithRetry(ctx, func() (*http.Response, error) {
 		return rp.HTTP.PutWithHeaders(ctx, path, queryParams, body, headers)
 	})
 }
 
 func (rp *retryProvider) Patch(ctx context.Context, path string, queryParams map[string]any, body []byte) (
 	*http.Response, error) {
-	return rp.doWithRetry(func() (*http.Response, error) {
+	return rp.doWithRetry(ctx, func() (*http.Response, error) {
 		return rp.HTTP.Patch(ctx, path, queryParams, body)
 	})
 }
 
 func (rp *retryProvider) PatchWithHeaders(ctx context.Context, path string, queryParams map[string]any, body []byte,
 	headers map[string]string) (*http.Response, error) {
-	return rp.doWithRetry(func() (*http.Response, error) {
+	return rp.doWithRetry(ctx, func() (*http.Response, error) {
 		return rp.HTTP.PatchWithHeaders(ctx, path, queryParams, body, headers)
 	})
 }
 
 func (rp *retryProvider) Delete(ctx context.Context, path string, body []byte) (*http.Response, error) {
-	return rp.doWithRetry(func() (*http.Response, error) {
+	return rp.doWithRetry(ctx, func() (*http.Response, error) {
 		return rp.HTTP.Delete(ctx, path, body)
 	})
 }
 
 func (rp *retryProvider) DeleteWithHeaders(ctx context.Context, path string, body []byte, headers map[string]string) (
 	*http.Response, error) {
-	return rp.doWithRetry(func() (*http.Response, error) {
+	return rp.doWithRetry(ctx, func() (*http.Response, error) {
 		return rp.HTTP.DeleteWithHeaders(ctx, path, body, headers)
 	})
 }
 
-func (rp *retryProvider) doWithRetry(reqFunc func() (*http.Response, error)) (*http.Response, error) {
+func (rp *retryProvider) doWithRetry(ctx context.Context, reqFunc func() (*http.Response, error)) (*http.Response,
+	error) {
 	var (
 		resp *http.Response
 		err  error
@@ -108,14 +123,83 @@ func (rp *retryProvider) doWithRetry(reqFunc func() (*http.Response, error)) (*h
 
 	for i := 0; i <= rp.maxRetries; i++ {
 		resp, err = reqFunc()
-		if err == nil && resp.StatusCode <= 500 {
-			return resp, nil
+
+		var delay time.Duration
+
+		if err == nil {
+			d, retry := rp.retryDelay(resp)
+			if !retry {
+				return resp, nil
+			}
+
+			delay = d
 		}
 
 		if i > 0 && rp.metrics != nil {
 			rp.metrics.IncrementCounter(context.Background(), "app_http_retry_count", "service", rp.serviceName)
 		}
+
+		if delay > 0 && i < rp.maxRetries {
+			// the response is discarded for the retry.
+			resp.Body.Close()
+
+			if waitErr := waitRetry(ctx, delay); waitErr != nil {
+				return nil, waitErr
+			}
+		}
 	}
 
 	return resp, err
 }
+
+// retryDelay reports whether the response is retried, and the delay asked by its Retry-After header. The 5xx
+// responses, except 500 Internal Server Error, are retried, and the 429 Too Many Requests responses are retried when
+// they tell when to. The responses asking to wait longer than maxRetryAfter are not retried.
+func (rp *retryProvider) retryDelay(resp *http.Response) (time.Duration, bool) {
+	delay, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return delay, ok && delay <= rp.maxRetryAfter
+	case resp.StatusCode > http.StatusInternalServerError:
+		return delay, !ok || delay <= rp.maxRetryAfter
+	default:
+		return 0, false
+	}
+}
+
+// parseRetryAfter parses a Retry-After header, given in seconds or as an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	return max(date.Sub(now), 0), true
+}
+
+// waitRetry waits for the delay, unless the context of the request is done first.
+func waitRetry(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -244,3 +245,111 @@ func TestRetryProvider_Metrics(t *testing.T) {
 
 	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
 }
+
+func TestRetryProvider_RetryAfter(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		statusCode int
+		retryAfter string
+		attempts   int
+	}{
+		{"too many requests with retry after", http.StatusTooManyRequests, "0", 3},
+		{"too many requests without retry after", http.StatusTooManyRequests, "", 1},
+		{"too many requests over the budget", http.StatusTooManyRequests, "3600", 1},
+		{"unavailable with retry after", http.StatusServiceUnavailable, "0", 3},
+		{"unavailable without retry after", http.StatusServiceUnavailable, "", 3},
+		{"unavailable over the budget", http.StatusServiceUnavailable, "3600", 1},
+		{"internal server error", http.StatusInternalServerError, "0", 1},
+	}
+
+	for i, tc := range testCases {
+		attempts := 0
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			attempts++
+
+			if tc.retryAfter != "" {
+				w.Header().Set("Retry-After", tc.retryAfter)
+			}
+
+			w.WriteHeader(tc.statusCode)
+		}))
+
+		httpService := NewHTTPService(server.URL, logging.NewMockLogger(logging.INFO), nil, &RetryConfig{MaxRetries: 2})
+
+		resp, err := httpService.Get(t.Context(), "/test", nil)
+		require.NoError(t, err, "TEST[%d], Failed.\n%s", i, tc.desc)
+
+		resp.Body.Close()
+		server.Close()
+
+		assert.Equal(t, tc.statusCode, resp.StatusCode, "TEST[%d], Failed.\n%s", i, tc.desc)
+		assert.Equal(t, tc.attempts, attempts, "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestRetryProvider_RetryAfterWait(t *testing.T) {
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempts++
+
+		if attempts == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	httpService := NewHTTPService(server.URL, logging.NewMockLogger(logging.INFO), nil, &RetryConfig{MaxRetries: 2})
+
+	start := time.Now()
+
+	resp, err := httpService.Get(t.Context(), "/test", nil)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, attempts)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+
+	// the wait ends with the context of the request.
+	attempts = 0
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = httpService.Get(ctx, "/test", nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		value string
+		delay time.Duration
+		ok    bool
+	}{
+		{"120", 2 * time.Minute, true},
+		{" 0 ", 0, true},
+		{"Mon, 01 Jan 2024 12:00:30 GMT", 30 * time.Second, true},
+		{"Mon, 01 Jan 2024 11:00:00 GMT", 0, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{"", 0, false},
+	}
+
+	for i, tc := range testCases {
+		delay, ok := parseRetryAfter(tc.value, now)
+
+		assert.Equal(t, tc.delay, delay, "TEST[%d], Failed.\n%s", i, tc.value)
+		assert.Equal(t, tc.ok, ok, "TEST[%d], Failed.\n%s", i, tc.value)
+	}
+}
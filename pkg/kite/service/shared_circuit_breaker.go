@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/sllt/kite/pkg/kite/infra/circuitbreaker"
+)
+
+// errServerFailure is recorded by the breaker for responses with a status code greater than 500, which tell
+// that the service is unavailable.
+var errServerFailure = errors.New("service responded with a server failure")
+
+// sharedCircuitBreaker protects a service with a rolling window circuit breaker of the container.
+type sharedCircuitBreaker struct {
+	breaker *circuitbreaker.Breaker
+
+	HTTP
+}
+
+func (cb *sharedCircuitBreaker) doRequest(ctx context.Context, f func(ctx context.Context) (*http.Response, error)) (
+	*http.Response, error) {
+	done, err := cb.breaker.Allow(ctx)
+	if err != nil {
+		return nil, ErrCircuitOpen
+	}
+
+	resp, err := f(ctx)
+
+	switch {
+	case err != nil:
+		done(err)
+	case resp != nil && resp.StatusCode > http.StatusInternalServerError:
+		done(errServerFailure)
+	default:
+		done(nil)
+	}
+
+	return resp, err
+}
+
+func (cb *sharedCircuitBreaker) Get(ctx context.Context, path string, queryParams map[string]any) (*http.Response, error) {
+	return cb.GetWithHeaders(ctx, path, queryParams, nil)
+}
+
+func (cb *sharedCircuitBreaker) GetWithHeaders(ctx context.Context, path string, queryParams map[string]any,
+	headers map[string]string) (*http.Response, error) {
+	return cb.doRequest(ctx, func(ctx context.Context) (*http.Response, error) {
+		return cb.HTTP.GetWithHeaders(ctx, path, queryParams, headers)
+	})
+}
+
+func (cb *sharedCircuitBreaker) Post(ctx context.Context, path string, queryParams map[string]any,
+	body []byte) (*http.Response, error) {
+	return cb.PostWithHeaders(ctx, path, queryParams, body, nil)
+}
+
+func (cb *sharedCircuitBreaker) PostWithHeaders(ctx context.Context, path string, queryParams map[string]any,
+	body []byte, headers map[string]string) (*http.Response, error) {
+	return cb.doRequest(ctx, func(ctx context.Context) (*http.Response, error) {
+		return cb.HTTP.PostWithHeaders(ctx, path, queryParams, body, headers)
+	})
+}
+
+func (cb *sharedCircuitBreaker) Put(ctx context.Context, path string, queryParams map[string]any,
+	body []byte) (*http.Response, error) {
+	return cb.PutWithHeaders(ctx, path, queryParams, body, nil)
+}
+
+func (cb *sharedCircuitBreaker) PutWithHeaders(ctx context.Context, path string, queryParams map[string]any,
+	body []byte, headers map[string]string) (*http.Response, error) {
+	return cb.doRequest(ctx, func(ctx context.Context) (*http.Response, error) {
+		return cb.HTTP.PutWithHeaders(ctx, path, queryParams, body, headers)
+	})
+}
+
+func (cb *sharedCircuitBreaker) Patch(ctx context.Context, path string, queryParams map[string]any,
+	body []byte) (*http.Response, error) {
+	return cb.PatchWithHeaders(ctx, path, queryParams, body, nil)
+}
+
+func (cb *sharedCircuitBreaker) PatchWithHeaders(ctx context.Context, path string, queryParams map[string]any,
+	body []byte, headers map[string]string) (*http.Response, error) {
+	return cb.doRequest(ctx, func(ctx context.Context) (*http.Response, error) {
+		return cb.HTTP.PatchWithHeaders(ctx, path, queryParams, body, headers)
+	})
+}
+
+func (cb *sharedCircuitBreaker) Delete(ctx context.Context, path string, body []byte) (*http.Response, error) {
+	return cb.DeleteWithHeaders(ctx, path, body, nil)
+}
+
+func (cb *sharedCircuitBreaker) DeleteWithHeaders(ctx context.Context, path string, body []byte,
+	headers map[string]string) (*http.Response, error) {
+	return cb.doRequest(ctx, func(ctx context.Context) (*http.Response, error) {
+		return cb.HTTP.DeleteWithHeaders(ctx, path, body, headers)
+	})
+}
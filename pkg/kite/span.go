@@ -0,0 +1,146 @@
+package kite
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ContextSpan is a span started with Context.StartSpan. It is annotated through its methods, so that the handlers do not
+// need to import OpenTelemetry.
+type ContextSpan struct {
+	span trace.Span
+}
+
+/*
+StartSpan starts a span, child of the current span of the request, and returns the context of the span with the span.
+The calls traced with the returned context, e.g. the queries of the datasources or the calls to the services, are
+children of the span. Unlike Trace, the context of the handler is left unchanged, so that the later calls are not
+traced as children of the span after it ended. Usage:
+
+	spanCtx, span := c.StartSpan("charge-card")
+	defer span.End()
+
+	span.SetAttribute("payment.provider", provider)
+
+	if err := charge(spanCtx, order); err != nil {
+		span.RecordError(err)
+
+		return nil, err
+	}
+*/
+func (c *Context) StartSpan(name string) (context.Context, *ContextSpan) {
+	ctx, span := otel.GetTracerProvider().Tracer("kite-context").Start(c.Context, name)
+
+	return ctx, &ContextSpan{span: span}
+}
+
+// TraceID returns the trace ID of the request, empty when the request is not traced.
+func (c *Context) TraceID() string {
+	sc := trace.SpanFromContext(c).SpanContext()
+	if !sc.HasTraceID() {
+		return ""
+	}
+
+	return sc.TraceID().String()
+}
+
+// SpanID returns the ID of the current span of the request, empty when the request is not traced.
+func (c *Context) SpanID() string {
+	sc := trace.SpanFromContext(c).SpanContext()
+	if !sc.HasSpanID() {
+		return ""
+	}
+
+	return sc.SpanID().String()
+}
+
+// SetAttribute sets an attribute of the span. The strings, booleans, integers, floats and their slices are recorded
+// with their type, the other values as their text.
+func (s *ContextSpan) SetAttribute(key string, value any) *ContextSpan {
+	s.span.SetAttributes(spanAttribute(key, value))
+
+	return s
+}
+
+// AddEvent records an event of the span, with the attributes given as key-value pairs, e.g.
+// span.AddEvent("retry", "attempt", 2).
+func (s *ContextSpan) AddEvent(name string, keyValues ...any) *ContextSpan {
+	attrs := make([]attribute.KeyValue, 0, len(keyValues)/2)
+
+	for i := 0; i+1 < len(keyValues); i += 2 {
+		attrs = append(attrs, spanAttribute(fmt.Sprint(keyValues[i]), keyValues[i+1]))
+	}
+
+	s.span.AddEvent(name, trace.WithAttributes(attrs...))
+
+	return s
+}
+
+// RecordError records the error as an event of the span and marks the span as failed. A nil error is ignored.
+func (s *ContextSpan) RecordError(err error) *ContextSpan {
+	if err == nil {
+		return s
+	}
+
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+
+	return s
+}
+
+// End ends the span.
+func (s *ContextSpan) End() {
+	s.span.End()
+}
+
+// TraceID returns the trace ID of the span, empty when the request is not traced.
+func (s *ContextSpan) TraceID() string {
+	if sc := s.span.SpanContext(); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+
+	return ""
+}
+
+// SpanID returns the ID of the span, empty when the request is not traced.
+func (s *ContextSpan) SpanID() string {
+	if sc := s.span.SpanContext(); sc.HasSpanID() {
+		return sc.SpanID().String()
+	}
+
+	return ""
+}
+
+func spanAttribute(key string, value any) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	case []string:
+		return attribute.StringSlice(key, v)
+	case []bool:
+		return attribute.BoolSlice(key, v)
+	case []int:
+		return attribute.IntSlice(key, v)
+	case []int64:
+		return attribute.Int64Slice(key, v)
+	case []float64:
+		return attribute.Float64Slice(key, v)
+	case fmt.Stringer:
+		return attribute.String(key, v.String())
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,75 @@
+package kite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var errCardDeclined = errors.New("card declined")
+
+func TestContext_StartSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(trace.NewTracerProvider(trace.WithSpanProcessor(recorder)))
+
+	reqCtx, parent := otel.GetTracerProvider().Tracer("test").Start(context.Background(), "request")
+
+	c := &Context{Context: reqCtx}
+
+	spanCtx, span := c.StartSpan("charge-card")
+
+	span.SetAttribute("payment.provider", "stripe").
+		SetAttribute("amount", 12.5).
+		SetAttribute("items", []string{"a", "b"}).
+		AddEvent("retry", "attempt", 2, "dangling").
+		RecordError(errCardDeclined).
+		RecordError(nil)
+
+	_, child := otel.GetTracerProvider().Tracer("test").Start(spanCtx, "query")
+	child.End()
+	span.End()
+	parent.End()
+
+	// the context of the handler is unchanged.
+	assert.Equal(t, parent.SpanContext().SpanID().String(), c.SpanID())
+	assert.Equal(t, parent.SpanContext().TraceID().String(), c.TraceID())
+	assert.Equal(t, c.TraceID(), span.TraceID())
+	assert.NotEqual(t, c.SpanID(), span.SpanID())
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+
+	assert.Equal(t, "query", spans[0].Name())
+	assert.Equal(t, span.SpanID(), spans[0].Parent().SpanID().String())
+
+	charge := spans[1]
+	assert.Equal(t, "charge-card", charge.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), charge.Parent().SpanID())
+	assert.ElementsMatch(t, []attribute.KeyValue{
+		attribute.String("payment.provider", "stripe"),
+		attribute.Float64("amount", 12.5),
+		attribute.StringSlice("items", []string{"a", "b"}),
+	}, charge.Attributes())
+	assert.Equal(t, codes.Error, charge.Status().Code)
+	assert.Equal(t, "card declined", charge.Status().Description)
+
+	require.Len(t, charge.Events(), 2)
+	assert.Equal(t, "retry", charge.Events()[0].Name)
+	assert.Equal(t, []attribute.KeyValue{attribute.Int("attempt", 2)}, charge.Events()[0].Attributes)
+	assert.Equal(t, "exception", charge.Events()[1].Name)
+}
+
+func TestContext_TraceIDNotTraced(t *testing.T) {
+	c := &Context{Context: context.Background()}
+
+	assert.Empty(t, c.TraceID())
+	assert.Empty(t, c.SpanID())
+}
@@ -0,0 +1,285 @@
+// Package startup initializes the dependencies of an application, e.g. the connections of its datasources,
+// concurrently. A step starts once the steps it depends on have succeeded, and the report of a run lists how long
+// each step took and when it started, to find what slows down the start of the application.
+package startup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+)
+
+// waterfallWidth is the width of the bars of the report showing when each step ran.
+const waterfallWidth = 20
+
+var (
+	// ErrTimeout is the error of the steps which did not complete within their timeout.
+	ErrTimeout = errors.New("startup step timed out")
+	// ErrDependency is the error of the steps which did not run as one of their dependencies failed.
+	ErrDependency = errors.New("startup step dependency failed")
+	// ErrCycle is the error of the steps which depend on themselves, directly or not.
+	ErrCycle = errors.New("startup step depends on itself")
+)
+
+// Step is a unit of the initialization of an application.
+type Step struct {
+	// Name identifies the step in the report and in the dependencies of the other steps.
+	Name string
+	// DependsOn are the names of the steps which must succeed before this one starts. The names of no step of the
+	// graph are ignored, e.g. for the dependencies initialized before the graph runs.
+	DependsOn []string
+	// Timeout is the maximum duration of the step, unlimited when it is zero. The context of Run is cancelled when
+	// the timeout elapses, and the step is reported as failed without waiting for Run to return.
+	Timeout time.Duration
+	// Run initializes the dependency.
+	Run func(ctx context.Context) error
+}
+
+// Graph is a set of steps run concurrently, each one once the steps it depends on have succeeded. It is safe for
+// concurrent use.
+type Graph struct {
+	mu    sync.Mutex
+	steps []Step
+}
+
+// Add adds a step to the graph. It replaces the step with the same name which has not run yet, e.g. for a datasource
+// added twice.
+func (g *Graph) Add(step Step) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for i := range g.steps {
+		if g.steps[i].Name == step.Name {
+			g.steps[i] = step
+
+			return
+		}
+	}
+
+	g.steps = append(g.steps, step)
+}
+
+// Len returns the number of steps which have not run yet.
+func (g *Graph) Len() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return len(g.steps)
+}
+
+// Run runs the steps added since the previous run and returns their report, it returns nil when there is none.
+// It returns once every step has completed, failed or timed out.
+func (g *Graph) Run(ctx context.Context) *Report {
+	g.mu.Lock()
+	steps := g.steps
+	g.steps = nil
+	g.mu.Unlock()
+
+	if len(steps) == 0 {
+		return nil
+	}
+
+	start := time.Now()
+	report := &Report{Results: make([]Result, len(steps))}
+
+	done := make(map[string]chan struct{}, len(steps))
+	for _, step := range steps {
+		done[step.Name] = make(chan struct{})
+	}
+
+	cyclic := cycles(steps)
+
+	var wg sync.WaitGroup
+
+	for i, step := range steps {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			defer close(done[step.Name])
+
+			result := &report.Results[i]
+			result.Name, result.DependsOn = step.Name, step.DependsOn
+
+			if cyclic[step.Name] {
+				result.Err = ErrCycle
+
+				return
+			}
+
+			if failed := waitDependencies(step, done, report, steps); failed != "" {
+				result.Err = fmt.Errorf("%w: %s", ErrDependency, failed)
+
+				return
+			}
+
+			result.Start = time.Since(start)
+			result.Err = run(ctx, step)
+			result.Duration = time.Since(start) - result.Start
+		}()
+	}
+
+	wg.Wait()
+
+	report.Duration = time.Since(start)
+
+	return report
+}
+
+// waitDependencies waits for the dependencies of step and returns the name of the first one which failed.
+func waitDependencies(step Step, done map[string]chan struct{}, report *Report, steps []Step) string {
+	for _, dependency := range step.DependsOn {
+		ch, ok := done[dependency]
+		if !ok {
+			continue
+		}
+
+		<-ch
+
+		for i := range steps {
+			if steps[i].Name == dependency && report.Results[i].Err != nil {
+				return dependency
+			}
+		}
+	}
+
+	return ""
+}
+
+// run runs the step within its timeout.
+func run(ctx context.Context, step Step) error {
+	if step.Timeout <= 0 {
+		return step.Run(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, step.Timeout)
+	defer cancel()
+
+	result := make(chan error, 1)
+
+	go func() { result <- step.Run(ctx) }()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%w after %s", ErrTimeout, step.Timeout)
+		}
+
+		return ctx.Err()
+	}
+}
+
+// cycles returns the names of the steps which depend on themselves.
+func cycles(steps []Step) map[string]bool {
+	dependencies := make(map[string][]string, len(steps))
+	for _, step := range steps {
+		dependencies[step.Name] = step.DependsOn
+	}
+
+	cyclic := make(map[string]bool)
+
+	for _, step := range steps {
+		visited := make(map[string]bool)
+		queue := append([]string(nil), step.DependsOn...)
+
+		for len(queue) > 0 {
+			name := queue[0]
+			queue = queue[1:]
+
+			if name == step.Name {
+				cyclic[step.Name] = true
+
+				break
+			}
+
+			if !visited[name] {
+				visited[name] = true
+				queue = append(queue, dependencies[name]...)
+			}
+		}
+	}
+
+	return cyclic
+}
+
+// Result is the outcome of a step.
+type Result struct {
+	Name      string
+	DependsOn []string
+	// Start is the time elapsed between the start of the run and the start of the step.
+	Start    time.Duration
+	Duration time.Duration
+	// Err is nil when the step succeeded.
+	Err error
+}
+
+// Report is the outcome of the steps of a run, in the order they were added.
+type Report struct {
+	Duration time.Duration
+	Results  []Result
+}
+
+// Err returns the errors of the failed steps, or nil when every step succeeded.
+func (r *Report) Err() error {
+	var err error
+
+	for _, result := range r.Results {
+		if result.Err != nil {
+			err = errors.Join(err, fmt.Errorf("%s: %w", result.Name, result.Err))
+		}
+	}
+
+	return err
+}
+
+// String renders the report as a table of the steps, with their dependencies, start, duration, a bar showing when
+// they ran during the startup and their status.
+func (r *Report) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "startup completed in %s\n", r.Duration.Round(time.Millisecond))
+
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "STEP\tDEPENDS ON\tSTART\tDURATION\tTIMELINE\tSTATUS")
+
+	for _, result := range r.Results {
+		dependsOn := strings.Join(result.DependsOn, ",")
+		if dependsOn == "" {
+			dependsOn = "-"
+		}
+
+		status := "ok"
+		if result.Err != nil {
+			status = "failed: " + result.Err.Error()
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", result.Name, dependsOn, result.Start.Round(time.Millisecond),
+			result.Duration.Round(time.Millisecond), r.waterfall(result), status)
+	}
+
+	_ = w.Flush()
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// waterfall returns a bar showing when a step ran relatively to the duration of the run.
+func (r *Report) waterfall(result Result) string {
+	if r.Duration <= 0 || result.Duration <= 0 {
+		return "|" + strings.Repeat(" ", waterfallWidth) + "|"
+	}
+
+	offset := min(int(int64(waterfallWidth)*int64(result.Start)/int64(r.Duration)), waterfallWidth-1)
+	width := max(int(int64(waterfallWidth)*int64(result.Duration)/int64(r.Duration)), 1)
+
+	bar := strings.Repeat(" ", offset) + strings.Repeat("█", min(width, waterfallWidth-offset))
+
+	return "|" + bar + strings.Repeat(" ", waterfallWidth-utf8.RuneCountInString(bar)) + "|"
+}
@@ -0,0 +1,124 @@
+package startup
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errConnect = errors.New("connection refused")
+
+func sleepStep(name string, d time.Duration, dependsOn ...string) Step {
+	return Step{Name: name, DependsOn: dependsOn, Run: func(ctx context.Context) error {
+		select {
+		case <-time.After(d):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}}
+}
+
+func TestGraph_Run_Concurrently(t *testing.T) {
+	var g Graph
+
+	g.Add(sleepStep("mongo", 50*time.Millisecond))
+	g.Add(sleepStep("kafka", 50*time.Millisecond))
+	g.Add(sleepStep("cassandra", 50*time.Millisecond))
+
+	report := g.Run(t.Context())
+
+	require.NoError(t, report.Err())
+	assert.Less(t, report.Duration, 140*time.Millisecond, "the independent steps run concurrently")
+	assert.Zero(t, g.Len(), "the steps run once")
+	assert.Nil(t, g.Run(t.Context()))
+}
+
+func TestGraph_Run_Dependencies(t *testing.T) {
+	var (
+		g             Graph
+		mongoConnects atomic.Bool
+	)
+
+	g.Add(Step{Name: "cache", DependsOn: []string{"mongo", "sql"}, Run: func(context.Context) error {
+		if !mongoConnects.Load() {
+			return errConnect
+		}
+
+		return nil
+	}})
+	g.Add(Step{Name: "mongo", Run: func(context.Context) error {
+		time.Sleep(20 * time.Millisecond)
+		mongoConnects.Store(true)
+
+		return nil
+	}})
+
+	report := g.Run(t.Context())
+
+	require.NoError(t, report.Err(), "the dependencies of no step of the graph are ignored")
+	assert.Equal(t, "cache", report.Results[0].Name)
+	assert.GreaterOrEqual(t, report.Results[0].Start, report.Results[1].Duration)
+}
+
+func TestGraph_Run_Failures(t *testing.T) {
+	var g Graph
+
+	g.Add(Step{Name: "mongo", Run: func(context.Context) error { return errConnect }})
+	g.Add(Step{Name: "cache", DependsOn: []string{"mongo"}, Run: func(context.Context) error { return nil }})
+	g.Add(Step{Name: "cassandra", Timeout: 10 * time.Millisecond, Run: func(context.Context) error {
+		time.Sleep(time.Second)
+
+		return nil
+	}})
+	g.Add(sleepStep("a", 0, "b"))
+	g.Add(sleepStep("b", 0, "a"))
+	g.Add(sleepStep("c", 0, "a"))
+
+	start := time.Now()
+	report := g.Run(t.Context())
+
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "the timed out steps are not waited for")
+
+	expected := []error{errConnect, ErrDependency, ErrTimeout, ErrCycle, ErrCycle, ErrDependency}
+
+	for i, err := range expected {
+		require.ErrorIs(t, report.Results[i].Err, err, "TEST[%d], Failed.\n%s", i, report.Results[i].Name)
+	}
+
+	require.ErrorIs(t, report.Err(), errConnect)
+	assert.Contains(t, report.Err().Error(), "cache: startup step dependency failed: mongo")
+}
+
+func TestGraph_Add_Replaces(t *testing.T) {
+	var g Graph
+
+	g.Add(Step{Name: "file", Run: func(context.Context) error { return errConnect }})
+	g.Add(sleepStep("mongo", 0))
+	g.Add(sleepStep("file", 0))
+
+	report := g.Run(t.Context())
+
+	require.Len(t, report.Results, 2)
+	assert.Equal(t, "file", report.Results[0].Name)
+	assert.NoError(t, report.Err())
+}
+
+func TestReport_String(t *testing.T) {
+	report := &Report{Duration: time.Second, Results: []Result{
+		{Name: "mongo", Duration: 500 * time.Millisecond},
+		{Name: "cache", DependsOn: []string{"mongo"}, Start: 500 * time.Millisecond, Duration: 500 * time.Millisecond},
+		{Name: "cassandra", Err: ErrTimeout},
+	}}
+
+	assert.Equal(t, `startup completed in 1s
+STEP       DEPENDS ON  START  DURATION  TIMELINE                STATUS
+mongo      -           0s     500ms     |██████████          |  ok
+cache      mongo       500ms  500ms     |          ██████████|  ok
+cassandra  -           0s     0s        |                    |  failed: startup step timed out`, report.String())
+}
@@ -7,35 +7,68 @@ import (
 
 	"github.com/sllt/kite/pkg/kite/infra"
 	"github.com/sllt/kite/pkg/kite/logging"
+	"github.com/sllt/kite/pkg/kite/metrics"
 )
 
 type SubscribeFunc func(c *Context) error
 
+// subscriberBuckets are the buckets of the processing time of consumed messages in milliseconds.
+var subscriberBuckets = []float64{.5, 1, 2, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000}
+
 type SubscriptionManager struct {
-	container     *infra.Container
-	subscriptions map[string]SubscribeFunc
+	container         *infra.Container
+	subscriptions     map[string]*Subscription
+	metricsRegistered bool
 }
 
 func newSubscriptionManager(c *infra.Container) SubscriptionManager {
 	return SubscriptionManager{
 		container:     c,
-		subscriptions: make(map[string]SubscribeFunc),
+		subscriptions: make(map[string]*Subscription),
 	}
 }
 
-// startSubscriber continuously subscribes to a topic and handles messages using the provided handler.
-func (s *SubscriptionManager) startSubscriber(ctx context.Context, topic string, handler SubscribeFunc) error {
+// registerMetrics registers the per-topic consumer metrics once, when the first subscription is added.
+// app_pubsub_consumer_lag is set by the subscribers which know the lag of their consumer group, e.g. Kafka.
+func (s *SubscriptionManager) registerMetrics(m metrics.Manager) {
+	if s.metricsRegistered || m == nil {
+		return
+	}
+
+	s.metricsRegistered = true
+
+	m.NewCounter("app_pubsub_messages_consumed_total", "Number of messages consumed per topic.")
+	m.NewHistogram("app_pubsub_processing_duration_ms", "Processing time of consumed messages in milliseconds.",
+		subscriberBuckets...)
+	m.NewCounter("app_pubsub_handler_errors_total", "Number of consumed messages whose handler returned an error.")
+	m.NewGauge("app_pubsub_consumer_lag", "Number of messages the consumer group is behind the end of the topic.")
+}
+
+// startSubscriber continuously subscribes to the topic of sub and handles messages using its handler, until ctx is
+// done or sub is closed.
+func (s *SubscriptionManager) startSubscriber(ctx context.Context, sub *Subscription) error {
+	if !sub.start() {
+		return nil
+	}
+
+	defer sub.stop()
+
 	var delay time.Duration
 
 	for {
+		if !sub.waitActive(ctx) {
+			s.container.Logger.Infof("shutting down subscriber for topic %s", sub.topic)
+			return nil
+		}
+
 		select {
 		case <-ctx.Done():
-			s.container.Logger.Infof("shutting down subscriber for topic %s", topic)
+			s.container.Logger.Infof("shutting down subscriber for topic %s", sub.topic)
 			return nil
 		case <-time.After(delay):
-			err := s.handleSubscription(ctx, topic, handler)
+			err := s.handleSubscription(ctx, sub)
 			if err != nil {
-				s.container.Logger.Errorf("error in subscription for topic %s: %v", topic, err)
+				s.container.Logger.Errorf("error in subscription for topic %s: %v", sub.topic, err)
 
 				delay = time.Second * 2
 			}
@@ -43,9 +76,22 @@ func (s *SubscriptionManager) startSubscriber(ctx context.Context, topic string,
 	}
 }
 
-func (s *SubscriptionManager) handleSubscription(ctx context.Context, topic string, handler SubscribeFunc) error {
-	msg, err := s.container.GetSubscriber().Subscribe(ctx, topic)
+func (s *SubscriptionManager) handleSubscription(ctx context.Context, sub *Subscription) error {
+	topic := sub.topic
+
+	fetchCtx, cancel := sub.beginFetch(ctx)
+	defer cancel()
+
+	msg, err := s.container.GetSubscriber().Subscribe(fetchCtx, topic)
+
+	sub.endFetch()
+
 	if err != nil {
+		// the read was cancelled to pause or close the subscription.
+		if fetchCtx.Err() != nil && ctx.Err() == nil {
+			return nil
+		}
+
 		s.container.Logger.Errorf("error while reading from topic %v, err: %v", topic, err.Error())
 
 		return err
@@ -55,17 +101,27 @@ func (s *SubscriptionManager) handleSubscription(ctx context.Context, topic stri
 		return nil
 	}
 
+	// a message read while pausing is held until the subscription is resumed, it is not committed if closed.
+	if !sub.waitActive(ctx) {
+		return nil
+	}
+
 	// newContext creates a new context from the msg.Context()
 	msgCtx := newContext(nil, msg, s.container)
 
+	start := time.Now()
+
 	err = func(ctx *Context) error {
 		// TODO : Move panic recovery at central location which will manage for all the different cases.
 		defer func() {
 			panicRecovery(recover(), ctx.Logger)
 		}()
 
-		return handler(ctx)
+		return sub.handler(ctx)
 	}(msgCtx)
+
+	s.recordMetrics(msgCtx, topic, start, err)
+
 	if err != nil {
 		s.container.Logger.Errorf("error in handler for topic %s: %v", topic, err)
 
@@ -80,6 +136,20 @@ func (s *SubscriptionManager) handleSubscription(ctx context.Context, topic stri
 	return nil
 }
 
+func (s *SubscriptionManager) recordMetrics(ctx context.Context, topic string, start time.Time, err error) {
+	m := s.container.Metrics()
+	if m == nil {
+		return
+	}
+
+	m.IncrementCounter(ctx, "app_pubsub_messages_consumed_total", "topic", topic)
+	m.RecordHistogram(ctx, "app_pubsub_processing_duration_ms", float64(time.Since(start).Microseconds())/1e3, "topic", topic)
+
+	if err != nil {
+		m.IncrementCounter(ctx, "app_pubsub_handler_errors_total", "topic", topic)
+	}
+}
+
 type panicLog struct {
 	Error      string `json:"error,omitempty"`
 	StackTrace string `json:"stack_trace,omitempty"`
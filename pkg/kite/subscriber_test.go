@@ -4,10 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 
 	"github.com/sllt/kite/pkg/kite/datasource"
 	"github.com/sllt/kite/pkg/kite/datasource/pubsub"
 	"github.com/sllt/kite/pkg/kite/datasource/pubsub/kafka"
+	"github.com/sllt/kite/pkg/kite/infra"
 )
 
 var errSubscription = errors.New("subscription error")
@@ -56,3 +61,38 @@ func (mockSubscriber) Subscribe(ctx context.Context, topic string) (*pubsub.Mess
 func (mockSubscriber) Close() error {
 	return nil
 }
+
+func TestSubscriptionManager_RegisterMetrics(t *testing.T) {
+	c, mocks := infra.NewMockContainer(t)
+
+	mocks.Metrics.EXPECT().NewCounter("app_pubsub_messages_consumed_total", gomock.Any())
+	mocks.Metrics.EXPECT().NewHistogram("app_pubsub_processing_duration_ms", gomock.Any(), gomock.Any())
+	mocks.Metrics.EXPECT().NewCounter("app_pubsub_handler_errors_total", gomock.Any())
+	mocks.Metrics.EXPECT().NewGauge("app_pubsub_consumer_lag", gomock.Any())
+
+	s := newSubscriptionManager(c)
+
+	// the metrics are registered only once.
+	s.registerMetrics(c.Metrics())
+	s.registerMetrics(c.Metrics())
+}
+
+func TestSubscriptionManager_HandleSubscriptionMetrics(t *testing.T) {
+	c, mocks := infra.NewMockContainer(t)
+	ctx := t.Context()
+
+	msg := pubsub.NewMessage(ctx)
+	msg.Topic = "orders"
+
+	mocks.PubSub.EXPECT().Subscribe(gomock.Any(), "orders").Return(msg, nil).Times(2)
+
+	mocks.Metrics.EXPECT().IncrementCounter(gomock.Any(), "app_pubsub_messages_consumed_total", "topic", "orders").Times(2)
+	mocks.Metrics.EXPECT().RecordHistogram(gomock.Any(), "app_pubsub_processing_duration_ms", gomock.Any(), "topic", "orders").
+		Times(2)
+	mocks.Metrics.EXPECT().IncrementCounter(gomock.Any(), "app_pubsub_handler_errors_total", "topic", "orders")
+
+	s := newSubscriptionManager(c)
+
+	require.NoError(t, s.handleSubscription(ctx, newSubscription("orders", func(*Context) error { return nil })))
+	require.NoError(t, s.handleSubscription(ctx, newSubscription("orders", func(*Context) error { return errSubscription })))
+}
@@ -0,0 +1,178 @@
+package kite
+
+import (
+	"context"
+	"sync"
+)
+
+// Subscription is the handle of a topic subscription returned by App.Subscribe, it lets the subscriber be paused,
+// resumed or closed at runtime, e.g. from an admin endpoint to stop consuming a topic with a poison pill message.
+//
+// Pausing or closing a subscription drains it: the message being handled finishes and is committed, a pending read
+// of the next message is cancelled. A message which was read while pausing is held until the subscription is resumed,
+// one which was read while closing is not committed so that the broker delivers it again.
+type Subscription struct {
+	topic   string
+	handler SubscribeFunc
+
+	mu sync.Mutex
+	// resumed is closed on Resume, it is nil while the subscription is not paused.
+	resumed chan struct{}
+	// cancelFetch cancels the pending read of the next message, it is nil while no message is being read.
+	cancelFetch context.CancelFunc
+	started     bool
+
+	closing   chan struct{}
+	closeOnce sync.Once
+	// done is closed when the subscriber stops.
+	done chan struct{}
+}
+
+func newSubscription(topic string, handler SubscribeFunc) *Subscription {
+	return &Subscription{
+		topic:   topic,
+		handler: handler,
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Topic returns the topic of the subscription.
+func (s *Subscription) Topic() string {
+	return s.topic
+}
+
+// Pause stops reading messages from the topic until Resume is called, the message being handled is still committed.
+func (s *Subscription) Pause() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.resumed != nil || s.isClosed() {
+		return
+	}
+
+	s.resumed = make(chan struct{})
+
+	if s.cancelFetch != nil {
+		s.cancelFetch()
+	}
+}
+
+// Resume resumes reading messages from the topic of a paused subscription.
+func (s *Subscription) Resume() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.resumed == nil {
+		return
+	}
+
+	close(s.resumed)
+	s.resumed = nil
+}
+
+// Paused reports whether the subscription is paused.
+func (s *Subscription) Paused() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.resumed != nil
+}
+
+// Close stops the subscription for good, it waits for the message being handled to be committed when the subscriber
+// is running.
+func (s *Subscription) Close() {
+	s.closeOnce.Do(func() {
+		s.mu.Lock()
+
+		close(s.closing)
+
+		if s.cancelFetch != nil {
+			s.cancelFetch()
+		}
+
+		started := s.started
+		s.mu.Unlock()
+
+		if started {
+			<-s.done
+		}
+	})
+}
+
+func (s *Subscription) isClosed() bool {
+	select {
+	case <-s.closing:
+		return true
+	default:
+		return false
+	}
+}
+
+// start marks the subscriber as running, it returns false if the subscription is already closed.
+func (s *Subscription) start() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.isClosed() {
+		return false
+	}
+
+	s.started = true
+
+	return true
+}
+
+// stop is called when the subscriber stops.
+func (s *Subscription) stop() {
+	close(s.done)
+}
+
+// beginFetch returns the context to read the next message with, which is cancelled on Pause or Close.
+func (s *Subscription) beginFetch(ctx context.Context) (context.Context, context.CancelFunc) {
+	fetchCtx, cancel := context.WithCancel(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the subscription may have been paused or closed since the subscriber checked it.
+	if s.resumed != nil || s.isClosed() {
+		cancel()
+	}
+
+	s.cancelFetch = cancel
+
+	return fetchCtx, cancel
+}
+
+// endFetch is called once the next message is read, the context of the message must outlive a later Pause.
+func (s *Subscription) endFetch() {
+	s.mu.Lock()
+	s.cancelFetch = nil
+	s.mu.Unlock()
+}
+
+// waitActive blocks while the subscription is paused, it returns false if the subscription is closed or ctx is done.
+func (s *Subscription) waitActive(ctx context.Context) bool {
+	for {
+		s.mu.Lock()
+		resumed := s.resumed
+		s.mu.Unlock()
+
+		if s.isClosed() || ctx.Err() != nil {
+			return false
+		}
+
+		if resumed == nil {
+			return true
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-s.closing:
+			return false
+		case <-resumed:
+		}
+	}
+}
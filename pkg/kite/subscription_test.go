@@ -0,0 +1,132 @@
+package kite
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sllt/kite/pkg/kite/datasource/pubsub"
+	"github.com/sllt/kite/pkg/kite/infra"
+	"github.com/sllt/kite/pkg/kite/logging"
+)
+
+// chanSubscriber returns the messages sent on msgs, blocking until one is sent or ctx is done.
+type chanSubscriber struct {
+	mockSubscriber
+
+	msgs chan *pubsub.Message
+}
+
+func (s chanSubscriber) Subscribe(ctx context.Context, _ string) (*pubsub.Message, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case msg := <-s.msgs:
+		return msg, nil
+	}
+}
+
+type countingCommitter struct {
+	commits *atomic.Int32
+}
+
+func (c countingCommitter) Commit() {
+	c.commits.Add(1)
+}
+
+func TestSubscription_PauseResumeClose(t *testing.T) {
+	msgs := make(chan *pubsub.Message, 1)
+	commits := &atomic.Int32{}
+
+	m := newSubscriptionManager(&infra.Container{
+		Logger: logging.NewMockLogger(logging.ERROR),
+		PubSub: chanSubscriber{msgs: msgs},
+	})
+
+	newMsg := func() *pubsub.Message {
+		msg := pubsub.NewMessage(t.Context())
+		msg.Committer = countingCommitter{commits: commits}
+
+		return msg
+	}
+
+	handled := make(chan struct{})
+	sub := newSubscription("orders", func(*Context) error {
+		handled <- struct{}{}
+		return nil
+	})
+
+	errCh := make(chan error)
+
+	go func() { errCh <- m.startSubscriber(t.Context(), sub) }()
+
+	msgs <- newMsg()
+	waitHandled(t, handled)
+
+	sub.Pause()
+	assert.True(t, sub.Paused())
+
+	msgs <- newMsg()
+
+	select {
+	case <-handled:
+		t.Fatal("a paused subscription must not handle messages")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	sub.Resume()
+	assert.False(t, sub.Paused())
+
+	waitHandled(t, handled)
+
+	sub.Close()
+
+	require.NoError(t, <-errCh)
+	assert.Equal(t, int32(2), commits.Load())
+}
+
+func TestSubscription_CloseBeforeStart(t *testing.T) {
+	m := newSubscriptionManager(&infra.Container{
+		Logger: logging.NewMockLogger(logging.ERROR),
+		PubSub: chanSubscriber{msgs: make(chan *pubsub.Message)},
+	})
+
+	sub := newSubscription("orders", func(*Context) error { return nil })
+
+	sub.Close()
+	sub.Pause()
+
+	assert.False(t, sub.Paused(), "a closed subscription cannot be paused")
+	require.NoError(t, m.startSubscriber(t.Context(), sub))
+}
+
+func TestApp_SubscribeReturnsSubscription(t *testing.T) {
+	app := &App{
+		container: &infra.Container{
+			Logger: logging.NewMockLogger(logging.ERROR),
+			PubSub: mockSubscriber{},
+		},
+		subscriptionManager: newSubscriptionManager(nil),
+	}
+
+	sub := app.Subscribe("orders", func(*Context) error { return nil })
+
+	require.NotNil(t, sub)
+	assert.Equal(t, "orders", sub.Topic())
+	assert.Same(t, sub, app.subscriptionManager.subscriptions["orders"])
+	assert.Nil(t, app.Subscribe("", func(*Context) error { return nil }))
+}
+
+func waitHandled(t *testing.T, handled <-chan struct{}) {
+	t.Helper()
+
+	select {
+	case <-handled:
+	case <-time.After(time.Second):
+		t.Fatal("message was not handled")
+	}
+}
@@ -0,0 +1,323 @@
+package kite
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sllt/kite/pkg/kite/config"
+	"github.com/sllt/kite/pkg/kite/datasource"
+	"github.com/sllt/kite/pkg/kite/infra"
+	"github.com/sllt/kite/pkg/kite/logging"
+)
+
+// Types of the synthetic checks, set by SYNTHETIC_CHECK_<NAME>_TYPE.
+const (
+	syntheticCheckHTTP   = "http"
+	syntheticCheckSQL    = "sql"
+	syntheticCheckPubSub = "pubsub"
+
+	defaultSyntheticCheckInterval = time.Minute
+	defaultSyntheticCheckTimeout  = 5 * time.Second
+	defaultSyntheticCheckQuery    = "SELECT 1"
+
+	syntheticCheckUpMetric       = "app_synthetic_check_up"
+	syntheticCheckDurationMetric = "app_synthetic_check_duration_seconds"
+	syntheticCheckFailuresMetric = "app_synthetic_check_failures_total"
+)
+
+var (
+	errSyntheticCheckStatus = errors.New("unexpected status code")
+	errNoSQL                = errors.New("no SQL datasource is configured")
+	errNoPubSub             = errors.New("no PubSub is configured")
+	errNoHTTPServer         = errors.New("no HTTP route is registered")
+)
+
+// syntheticCheck is a check of the application run on an interval, e.g. a call to one of its own endpoints, whose last
+// result is reported by the health endpoint as "synthetic:<name>".
+type syntheticCheck struct {
+	name     string
+	kind     string
+	target   string
+	method   string
+	status   int
+	interval time.Duration
+	timeout  time.Duration
+
+	run func(ctx context.Context) error
+
+	mu      sync.RWMutex
+	lastRun time.Time
+	elapsed time.Duration
+	err     error
+}
+
+// newSyntheticChecks reads the synthetic checks named in SYNTHETIC_CHECKS, e.g. "orders,db", from their configs:
+//
+//	SYNTHETIC_CHECK_<NAME>_TYPE:     "http", "sql" or "pubsub"
+//	SYNTHETIC_CHECK_<NAME>_TARGET:   the path of the endpoint called, the query run, or the topic of the round trip
+//	SYNTHETIC_CHECK_<NAME>_METHOD:   the method of the endpoint called, GET by default
+//	SYNTHETIC_CHECK_<NAME>_STATUS:   the status code the endpoint must respond with, 200 by default
+//	SYNTHETIC_CHECK_<NAME>_INTERVAL: the interval between the runs, SYNTHETIC_CHECK_INTERVAL by default
+//	SYNTHETIC_CHECK_<NAME>_TIMEOUT:  the timeout of a run, SYNTHETIC_CHECK_TIMEOUT by default
+//
+// The checks which are not valid are logged and skipped.
+func newSyntheticChecks(cfg config.Config, logger logging.Logger) []*syntheticCheck {
+	interval := getDurationConfig(logger, cfg, "SYNTHETIC_CHECK_INTERVAL", defaultSyntheticCheckInterval)
+	timeout := getDurationConfig(logger, cfg, "SYNTHETIC_CHECK_TIMEOUT", defaultSyntheticCheckTimeout)
+
+	var checks []*syntheticCheck
+
+	for _, name := range strings.Split(cfg.Get("SYNTHETIC_CHECKS"), ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+
+		prefix := "SYNTHETIC_CHECK_" + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name)) + "_"
+
+		check := &syntheticCheck{
+			name:     name,
+			kind:     strings.ToLower(strings.TrimSpace(cfg.Get(prefix + "TYPE"))),
+			target:   strings.TrimSpace(cfg.Get(prefix + "TARGET")),
+			method:   strings.ToUpper(cfg.GetOrDefault(prefix+"METHOD", http.MethodGet)),
+			status:   http.StatusOK,
+			interval: getDurationConfig(logger, cfg, prefix+"INTERVAL", interval),
+			timeout:  getDurationConfig(logger, cfg, prefix+"TIMEOUT", timeout),
+		}
+
+		if value := cfg.Get(prefix + "STATUS"); value != "" {
+			status, err := strconv.Atoi(value)
+			if err != nil {
+				logger.Errorf("invalid %sSTATUS %q, expecting the status code 200", prefix, value)
+			} else {
+				check.status = status
+			}
+		}
+
+		switch {
+		case check.kind != syntheticCheckHTTP && check.kind != syntheticCheckSQL && check.kind != syntheticCheckPubSub:
+			logger.Errorf("invalid %sTYPE %q, use %q, %q or %q, skipping the synthetic check %s", prefix, check.kind,
+				syntheticCheckHTTP, syntheticCheckSQL, syntheticCheckPubSub, name)
+
+			continue
+		case check.target == "" && check.kind == syntheticCheckSQL:
+			check.target = defaultSyntheticCheckQuery
+		case check.target == "":
+			logger.Errorf("%sTARGET is not set, skipping the synthetic check %s", prefix, name)
+
+			continue
+		}
+
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+// startSyntheticChecks runs the synthetic checks configured with SYNTHETIC_CHECKS, until ctx is done.
+func (a *App) startSyntheticChecks(ctx context.Context) {
+	checks := newSyntheticChecks(a.Config, a.Logger())
+
+	for _, check := range checks {
+		check.run = a.syntheticCheckRunner(check)
+
+		a.container.AddHealthCheck("synthetic:"+check.name, check.health)
+
+		go check.schedule(ctx, a.container)
+	}
+}
+
+// syntheticCheckRunner returns the function running one check.
+func (a *App) syntheticCheckRunner(check *syntheticCheck) func(ctx context.Context) error {
+	switch check.kind {
+	case syntheticCheckHTTP:
+		port := 0
+		if a.httpRegistered && a.httpServer != nil {
+			port = a.httpServer.port
+		}
+
+		return func(ctx context.Context) error {
+			return checkHTTPEndpoint(ctx, http.DefaultClient, port, check.method, check.target, check.status)
+		}
+	case syntheticCheckSQL:
+		return func(ctx context.Context) error {
+			return checkSQLQuery(ctx, a.container.SQL, check.target)
+		}
+	default:
+		return func(ctx context.Context) error {
+			return checkPubSubRoundTrip(ctx, a.container, check.name, check.target)
+		}
+	}
+}
+
+// schedule runs the check on its interval, until ctx is done. The first run waits for an interval as well, so that the
+// servers are started by then.
+func (s *syntheticCheck) schedule(ctx context.Context, c *infra.Container) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.runOnce(ctx, c)
+		}
+	}
+}
+
+// runOnce runs the check, records its result, and logs when it starts or stops failing.
+func (s *syntheticCheck) runOnce(ctx context.Context, c *infra.Container) {
+	runCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := s.run(runCtx)
+	elapsed := time.Since(start)
+
+	s.mu.Lock()
+	failing := s.err != nil
+	s.lastRun, s.elapsed, s.err = start, elapsed, err
+	s.mu.Unlock()
+
+	switch {
+	case err != nil && !failing:
+		c.Errorf("synthetic check %s failed: %v", s.name, err)
+	case err == nil && failing:
+		c.Infof("synthetic check %s recovered", s.name)
+	}
+
+	m := c.Metrics()
+	if m == nil {
+		return
+	}
+
+	up := 1.0
+
+	if err != nil {
+		up = 0
+
+		m.IncrementCounter(ctx, syntheticCheckFailuresMetric, "check", s.name, "type", s.kind)
+	}
+
+	m.SetGauge(syntheticCheckUpMetric, up, "check", s.name, "type", s.kind)
+	m.RecordHistogram(ctx, syntheticCheckDurationMetric, elapsed.Seconds(), "check", s.name, "type", s.kind)
+}
+
+// health reports the last result of the check, UP until it first runs.
+func (s *syntheticCheck) health(context.Context) datasource.Health {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	details := map[string]any{
+		"type":   s.kind,
+		"target": s.target,
+	}
+
+	if s.lastRun.IsZero() {
+		details["last_run"] = "pending"
+
+		return datasource.Health{Status: datasource.StatusUp, Details: details}
+	}
+
+	details["last_run"] = s.lastRun.UTC().Format(time.RFC3339)
+	details["duration"] = s.elapsed.Round(time.Millisecond).String()
+
+	if s.err != nil {
+		details["error"] = s.err.Error()
+
+		return datasource.Health{Status: datasource.StatusDown, Details: details}
+	}
+
+	return datasource.Health{Status: datasource.StatusUp, Details: details}
+}
+
+// checkHTTPEndpoint calls an endpoint of the HTTP server of the application, which must respond with status.
+func checkHTTPEndpoint(ctx context.Context, client *http.Client, port int, method, path string, status int) error {
+	if port == 0 {
+		return errNoHTTPServer
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, fmt.Sprintf("http://localhost:%d%s", port, path), http.NoBody)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	resp.Body.Close()
+
+	if resp.StatusCode != status {
+		return fmt.Errorf("%w %d, expecting %d", errSyntheticCheckStatus, resp.StatusCode, status)
+	}
+
+	return nil
+}
+
+// checkSQLQuery runs query, reading all its rows.
+func checkSQLQuery(ctx context.Context, db infra.DB, query string) error {
+	if db == nil {
+		return errNoSQL
+	}
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	// the errors of some queries only surface once their rows are read.
+	for rows.Next() {
+	}
+
+	return rows.Err()
+}
+
+// checkPubSubRoundTrip publishes a message on topic, and consumes the topic until it is received back. The topic must
+// be dedicated to the check, as the other messages consumed meanwhile are committed and dropped.
+func checkPubSubRoundTrip(ctx context.Context, c *infra.Container, name, topic string) error {
+	if c.PubSub == nil {
+		return errNoPubSub
+	}
+
+	payload := []byte("synthetic check " + name + " " + uuid.NewString())
+
+	if err := c.PubSub.Publish(ctx, topic, payload); err != nil {
+		return fmt.Errorf("publishing: %w", err)
+	}
+
+	for {
+		msg, err := c.PubSub.Subscribe(ctx, topic)
+		if err != nil {
+			return fmt.Errorf("consuming: %w", err)
+		}
+
+		if msg == nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("consuming: %w", ctx.Err())
+			}
+
+			continue
+		}
+
+		if msg.Committer != nil {
+			msg.Commit()
+		}
+
+		if bytes.Equal(msg.Value, payload) {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package kite
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/sllt/kite/pkg/kite/config"
+	"github.com/sllt/kite/pkg/kite/datasource"
+	"github.com/sllt/kite/pkg/kite/datasource/pubsub"
+	"github.com/sllt/kite/pkg/kite/infra"
+	"github.com/sllt/kite/pkg/kite/logging"
+)
+
+var errSyntheticCheck = errors.New("connection refused")
+
+func TestNewSyntheticChecks(t *testing.T) {
+	cfg := config.NewMockConfig(map[string]string{
+		"SYNTHETIC_CHECKS":                 "orders, db,events,unknown,no-target",
+		"SYNTHETIC_CHECK_INTERVAL":         "30s",
+		"SYNTHETIC_CHECK_ORDERS_TYPE":      "HTTP",
+		"SYNTHETIC_CHECK_ORDERS_TARGET":    "/orders?limit=1",
+		"SYNTHETIC_CHECK_ORDERS_METHOD":    "head",
+		"SYNTHETIC_CHECK_ORDERS_STATUS":    "204",
+		"SYNTHETIC_CHECK_ORDERS_TIMEOUT":   "2s",
+		"SYNTHETIC_CHECK_DB_TYPE":          "sql",
+		"SYNTHETIC_CHECK_DB_INTERVAL":      "10s",
+		"SYNTHETIC_CHECK_EVENTS_TYPE":      "pubsub",
+		"SYNTHETIC_CHECK_EVENTS_TARGET":    "synthetic-events",
+		"SYNTHETIC_CHECK_UNKNOWN_TYPE":     "grpc",
+		"SYNTHETIC_CHECK_UNKNOWN_TARGET":   "Orders",
+		"SYNTHETIC_CHECK_NO_TARGET_TYPE":   "http",
+		"SYNTHETIC_CHECK_NO_TARGET_STATUS": "ok",
+	})
+
+	checks := newSyntheticChecks(cfg, logging.NewMockLogger(logging.ERROR))
+
+	require.Len(t, checks, 3)
+
+	tests := []struct {
+		name     string
+		kind     string
+		target   string
+		method   string
+		status   int
+		interval time.Duration
+		timeout  time.Duration
+	}{
+		{"orders", syntheticCheckHTTP, "/orders?limit=1", http.MethodHead, http.StatusNoContent, 30 * time.Second,
+			2 * time.Second},
+		{"db", syntheticCheckSQL, defaultSyntheticCheckQuery, http.MethodGet, http.StatusOK, 10 * time.Second,
+			defaultSyntheticCheckTimeout},
+		{"events", syntheticCheckPubSub, "synthetic-events", http.MethodGet, http.StatusOK, 30 * time.Second,
+			defaultSyntheticCheckTimeout},
+	}
+
+	for i, tc := range tests {
+		check := checks[i]
+
+		assert.Equal(t, []any{tc.name, tc.kind, tc.target, tc.method, tc.status, tc.interval, tc.timeout},
+			[]any{check.name, check.kind, check.target, check.method, check.status, check.interval, check.timeout},
+			"TEST[%d], Failed.\n%s", i, tc.name)
+	}
+}
+
+func TestSyntheticCheck_RunOnce(t *testing.T) {
+	c, mocks := infra.NewMockContainer(t)
+
+	results := []error{errSyntheticCheck, nil}
+
+	check := &syntheticCheck{name: "orders", kind: syntheticCheckHTTP, target: "/orders", timeout: time.Second}
+	check.run = func(context.Context) error {
+		err := results[0]
+		results = results[1:]
+
+		return err
+	}
+
+	assert.Equal(t, datasource.Health{Status: datasource.StatusUp, Details: map[string]any{
+		"type": syntheticCheckHTTP, "target": "/orders", "last_run": "pending",
+	}}, check.health(t.Context()))
+
+	mocks.Metrics.EXPECT().IncrementCounter(gomock.Any(), syntheticCheckFailuresMetric, "check", "orders", "type", "http")
+	mocks.Metrics.EXPECT().SetGauge(syntheticCheckUpMetric, 0.0, "check", "orders", "type", "http")
+	mocks.Metrics.EXPECT().RecordHistogram(gomock.Any(), syntheticCheckDurationMetric, gomock.Any(),
+		"check", "orders", "type", "http").Times(2)
+	mocks.Metrics.EXPECT().SetGauge(syntheticCheckUpMetric, 1.0, "check", "orders", "type", "http")
+
+	check.runOnce(t.Context(), c)
+
+	health := check.health(t.Context())
+
+	assert.Equal(t, datasource.StatusDown, health.Status)
+	assert.Equal(t, errSyntheticCheck.Error(), health.Details["error"])
+
+	check.runOnce(t.Context(), c)
+
+	health = check.health(t.Context())
+
+	assert.Equal(t, datasource.StatusUp, health.Status)
+	assert.NotContains(t, health.Details, "error")
+	assert.Contains(t, health.Details, "duration")
+}
+
+func TestCheckHTTPEndpoint(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/orders" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	port, _ := strconv.Atoi(u.Port())
+
+	tests := []struct {
+		desc string
+		port int
+		path string
+		err  error
+	}{
+		{"expected status", port, "/orders", nil},
+		{"unexpected status", port, "/users", errSyntheticCheckStatus},
+		{"no HTTP server", 0, "/orders", errNoHTTPServer},
+	}
+
+	for i, tc := range tests {
+		err := checkHTTPEndpoint(t.Context(), srv.Client(), tc.port, http.MethodGet, tc.path, http.StatusOK)
+
+		assert.ErrorIs(t, err, tc.err, "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestCheckSQLQuery(t *testing.T) {
+	c, mocks := infra.NewMockContainer(t)
+
+	mocks.SQL.ExpectQuery("SELECT 1").WillReturnRows(mocks.SQL.NewRows([]string{"1"}).AddRow(1))
+
+	require.NoError(t, checkSQLQuery(t.Context(), c.SQL, "SELECT 1"))
+	assert.ErrorIs(t, checkSQLQuery(t.Context(), nil, "SELECT 1"), errNoSQL)
+}
+
+func TestCheckPubSubRoundTrip(t *testing.T) {
+	c, mocks := infra.NewMockContainer(t)
+
+	var published []byte
+
+	mocks.PubSub.EXPECT().Publish(gomock.Any(), "synthetic-events", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, message []byte) error {
+			published = message
+
+			return nil
+		})
+
+	stale := pubsub.NewMessage(t.Context())
+	stale.Value = []byte("synthetic check events of a previous run")
+
+	mocks.PubSub.EXPECT().Subscribe(gomock.Any(), "synthetic-events").Return(stale, nil)
+	mocks.PubSub.EXPECT().Subscribe(gomock.Any(), "synthetic-events").
+		DoAndReturn(func(context.Context, string) (*pubsub.Message, error) {
+			msg := pubsub.NewMessage(t.Context())
+			msg.Value = published
+
+			return msg, nil
+		})
+
+	require.NoError(t, checkPubSubRoundTrip(t.Context(), c, "events", "synthetic-events"))
+	assert.ErrorIs(t, checkPubSubRoundTrip(t.Context(), &infra.Container{}, "events", "synthetic-events"), errNoPubSub)
+}
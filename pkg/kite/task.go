@@ -0,0 +1,283 @@
+package kite
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+
+	"github.com/sllt/kite/pkg/kite/infra"
+	"github.com/sllt/kite/pkg/kite/infra/tasks"
+	"github.com/sllt/kite/pkg/kite/version"
+)
+
+const (
+	taskPollInterval = time.Second
+	// taskLease is how long a claimed task is reserved to its replica, after which it is claimed again, in case
+	// the replica died while running it. The lease is renewed every third of it while the task runs.
+	taskLease      = 5 * time.Minute
+	taskClaimLimit = 10
+)
+
+var errEmptyTaskName = errors.New("task name cannot be empty")
+
+// TaskFunc is the handler of the one-shot tasks of a name. The payload of the task is bound with ctx.Bind.
+// A task is run once: it is not retried when its handler fails, the error is logged.
+type TaskFunc func(ctx *Context) error
+
+// taskScheduler runs the due one-shot tasks of the application, polling the store of the container.
+type taskScheduler struct {
+	container *infra.Container
+	lease     time.Duration
+
+	mu       sync.RWMutex
+	handlers map[string]TaskFunc
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	polling  chan struct{}
+	running  sync.WaitGroup
+}
+
+func newTaskScheduler(c *infra.Container, lease time.Duration) *taskScheduler {
+	s := &taskScheduler{
+		container: c,
+		lease:     lease,
+		handlers:  make(map[string]TaskFunc),
+		stop:      make(chan struct{}),
+		polling:   make(chan struct{}),
+	}
+
+	go s.poll()
+
+	return s
+}
+
+func (s *taskScheduler) poll() {
+	defer close(s.polling)
+
+	ticker := time.NewTicker(taskPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case t := <-ticker.C:
+			s.runDue(t)
+		}
+	}
+}
+
+func (s *taskScheduler) runDue(now time.Time) {
+	claimed, err := s.container.Tasks().Claim(context.Background(), now, s.lease, taskClaimLimit)
+	if err != nil {
+		s.container.Errorf("failed to claim the scheduled tasks: %v", err)
+	}
+
+	for _, task := range claimed {
+		s.mu.RLock()
+		handler, ok := s.handlers[task.Name]
+		s.mu.RUnlock()
+
+		if !ok {
+			// the task is claimed again by a replica having its handler once the lease expires.
+			s.container.Errorf("no handler registered for task %s (%s)", task.Name, task.ID)
+
+			continue
+		}
+
+		s.running.Add(1)
+
+		go s.run(task, handler)
+	}
+}
+
+func (s *taskScheduler) run(task tasks.Task, handler TaskFunc) {
+	defer s.running.Done()
+
+	ctx, span := otel.GetTracerProvider().Tracer("kite-"+version.Framework).
+		Start(context.Background(), "task "+task.Name)
+	defer span.End()
+
+	c := newContext(nil, taskRequest{task: task}, s.container)
+	c.Context = ctx
+
+	c.Infof("Starting task: %s (%s)", task.Name, task.ID)
+
+	start := time.Now()
+
+	stopRenewing := s.renewLease(task)
+
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				c.Errorf("Panic in task %s (%s): %v", task.Name, task.ID, r)
+			}
+		}()
+
+		if err := handler(c); err != nil {
+			c.Errorf("Task %s (%s) failed: %v", task.Name, task.ID, err)
+		}
+	}()
+
+	stopRenewing()
+
+	c.Infof("Finished task: %s (%s) in %s", task.Name, task.ID, time.Since(start))
+
+	if err := s.container.Tasks().Complete(context.Background(), task.ID); err != nil {
+		c.Errorf("failed to complete task %s (%s), it may run again: %v", task.Name, task.ID, err)
+	}
+}
+
+// renewLease renews the lease of the running task every third of the lease, so that the task is not claimed again by
+// another replica while it runs longer than the lease. The returned function stops renewing it.
+func (s *taskScheduler) renewLease(task tasks.Task) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(s.lease / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				if err := s.container.Tasks().Renew(context.Background(), task.ID, now, s.lease); err != nil {
+					s.container.Errorf("failed to renew the lease of task %s (%s), it may run again: %v", task.Name,
+						task.ID, err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// Close stops claiming the due tasks and waits for the running ones to finish, until ctx is done.
+func (s *taskScheduler) Close(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.stop) })
+
+	done := make(chan struct{})
+
+	go func() {
+		// no task is started once polling stopped.
+		<-s.polling
+		s.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// taskRequest is the request of the context of a task, which binds the payload of the task.
+type taskRequest struct {
+	noopRequest
+
+	task tasks.Task
+}
+
+func (r taskRequest) Bind(i any) error {
+	if len(r.task.Payload) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(r.task.Payload, i)
+}
+
+// AddTask registers the handler of the one-shot tasks named name, scheduled with ctx.ScheduleIn. The handler must be
+// registered on every replica of the application, as the due tasks are run by any of them.
+func (a *App) AddTask(name string, handler TaskFunc) {
+	a.addTask(name, handler)
+}
+
+func (a *App) addTask(name string, handler TaskFunc) bool {
+	if name == "" || handler == nil {
+		a.Logger().Errorf("invalid task: name and handler must not be empty or nil")
+
+		return false
+	}
+
+	if a.tasks == nil {
+		a.tasks = newTaskScheduler(a.container, taskLease)
+	}
+
+	a.tasks.mu.Lock()
+	a.tasks.handlers[name] = handler
+	a.tasks.mu.Unlock()
+
+	return true
+}
+
+// ScheduleAt registers handler for the tasks named name, see AddTask, and schedules one of them at the given time.
+//
+// The task is identified by its name and time, so that the application can schedule it on every start: it is only
+// scheduled once, and run once, even by several replicas. A time which passed while the application was down is run
+// at start, unless it passed more than tasks.Retention ago.
+func (a *App) ScheduleAt(at time.Time, name string, handler TaskFunc) {
+	if !a.addTask(name, handler) {
+		return
+	}
+
+	if time.Since(at) > tasks.Retention {
+		a.Logger().Warnf("task %s at %s is older than the retention of the completed tasks, it is not scheduled", name, at)
+
+		return
+	}
+
+	task := tasks.Task{ID: name + "@" + at.UTC().Format(time.RFC3339Nano), Name: name, RunAt: at}
+
+	scheduled, err := a.container.Tasks().Schedule(context.Background(), task)
+	if err != nil {
+		a.Logger().Errorf("error scheduling task %s at %s, err: %v", name, at, err)
+
+		return
+	}
+
+	if scheduled {
+		a.Logger().Infof("Scheduled task %s at %s", name, at)
+	}
+}
+
+// ScheduleIn schedules a one-shot task named name to run after delay, e.g. the expiry of a trial, and returns its id.
+// The task is persisted in the Redis or SQL datasource, so that it survives restarts, and is run once by one of
+// the replicas, with the handler registered with app.AddTask. payload is marshaled to JSON, and bound by the handler
+// with ctx.Bind.
+func (c *Context) ScheduleIn(delay time.Duration, name string, payload any) (string, error) {
+	if name == "" {
+		return "", errEmptyTaskName
+	}
+
+	task := tasks.Task{ID: uuid.NewString(), Name: name, RunAt: time.Now().Add(delay)}
+
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return "", err
+		}
+
+		task.Payload = data
+	}
+
+	if _, err := c.Tasks().Schedule(c, task); err != nil {
+		return "", err
+	}
+
+	return task.ID, nil
+}
@@ -0,0 +1,189 @@
+package kite
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sllt/kite/pkg/kite/infra"
+	"github.com/sllt/kite/pkg/kite/infra/tasks"
+	"github.com/sllt/kite/pkg/kite/logging"
+)
+
+var errTask = errors.New("task error")
+
+func newTaskTestApp() *App {
+	return &App{container: &infra.Container{Logger: logging.NewMockLogger(logging.ERROR)}}
+}
+
+func TestContext_ScheduleIn(t *testing.T) {
+	app := newTaskTestApp()
+
+	type reminder struct {
+		UserID string `json:"user_id"`
+	}
+
+	received := make(chan reminder, 1)
+
+	app.AddTask("remind", func(ctx *Context) error {
+		var r reminder
+
+		if err := ctx.Bind(&r); err != nil {
+			return err
+		}
+
+		received <- r
+
+		return nil
+	})
+
+	ctx := &Context{Context: t.Context(), Container: app.container}
+
+	id, err := ctx.ScheduleIn(0, "remind", reminder{UserID: "u-1"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, id)
+
+	select {
+	case r := <-received:
+		assert.Equal(t, reminder{UserID: "u-1"}, r)
+	case <-time.After(5 * time.Second):
+		t.Fatal("task was not run")
+	}
+
+	require.NoError(t, app.tasks.Close(t.Context()))
+
+	_, err = ctx.ScheduleIn(time.Minute, "", nil)
+	require.ErrorIs(t, err, errEmptyTaskName)
+}
+
+func TestApp_ScheduleAt_RunsOnce(t *testing.T) {
+	app := newTaskTestApp()
+
+	var runs atomic.Int32
+
+	handler := func(*Context) error {
+		runs.Add(1)
+
+		return errTask
+	}
+
+	at := time.Now().Add(-time.Hour)
+
+	// the application schedules the task on every start.
+	app.ScheduleAt(at, "expire-trials", handler)
+	app.ScheduleAt(at, "expire-trials", handler)
+
+	require.Eventually(t, func() bool { return runs.Load() == 1 }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, app.tasks.Close(t.Context()))
+
+	app.ScheduleAt(at, "expire-trials", handler)
+
+	claimed, err := app.container.Tasks().Claim(t.Context(), time.Now(), time.Minute, 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed, "a failed task is completed, and not scheduled again")
+	assert.Equal(t, int32(1), runs.Load())
+}
+
+func TestApp_ScheduleAt_Invalid(t *testing.T) {
+	app := newTaskTestApp()
+
+	app.ScheduleAt(time.Now(), "", func(*Context) error { return nil })
+	assert.Nil(t, app.tasks)
+
+	app.ScheduleAt(time.Now().Add(-tasks.Retention-time.Hour), "stale", func(*Context) error { return nil })
+
+	claimed, err := app.container.Tasks().Claim(t.Context(), time.Now(), time.Minute, 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+	require.NoError(t, app.tasks.Close(t.Context()))
+}
+
+func TestTaskScheduler_Close(t *testing.T) {
+	app := newTaskTestApp()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	app.ScheduleAt(time.Now(), "slow", func(*Context) error {
+		close(started)
+		<-release
+
+		return nil
+	})
+
+	<-started
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, app.tasks.Close(ctx), context.DeadlineExceeded)
+
+	close(release)
+
+	require.NoError(t, app.tasks.Close(t.Context()))
+}
+
+func TestTaskScheduler_RenewsLease(t *testing.T) {
+	app := newTaskTestApp()
+	app.tasks = newTaskScheduler(app.container, 60*time.Millisecond)
+
+	var runs atomic.Int32
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	app.ScheduleAt(time.Now(), "slow", func(*Context) error {
+		if runs.Add(1) == 1 {
+			close(started)
+		}
+
+		<-release
+
+		return nil
+	})
+
+	<-started
+
+	// the task runs for several leases, without being claimed again.
+	time.Sleep(300 * time.Millisecond)
+
+	claimed, err := app.container.Tasks().Claim(t.Context(), time.Now(), time.Minute, 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed, "the lease of a running task must be renewed")
+
+	close(release)
+
+	require.NoError(t, app.tasks.Close(t.Context()))
+	assert.Equal(t, int32(1), runs.Load())
+}
+
+func TestTaskScheduler_RecoversPanics(t *testing.T) {
+	app := newTaskTestApp()
+
+	done := make(chan struct{})
+
+	app.AddTask("ok", func(*Context) error {
+		close(done)
+
+		return nil
+	})
+	app.ScheduleAt(time.Now(), "panics", func(*Context) error { panic("boom") })
+
+	ctx := &Context{Context: t.Context(), Container: app.container}
+
+	_, err := ctx.ScheduleIn(0, "ok", nil)
+	require.NoError(t, err)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("task was not run")
+	}
+
+	require.NoError(t, app.tasks.Close(t.Context()))
+}
@@ -0,0 +1,103 @@
+package kite
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/sllt/kite/pkg/kite/config"
+	"github.com/sllt/kite/pkg/kite/datasource/sql"
+	"github.com/sllt/kite/pkg/kite/http/middleware"
+	"github.com/sllt/kite/pkg/kite/infra"
+	"github.com/sllt/kite/pkg/kite/tenant"
+)
+
+const defaultTenantSQLPools = 100
+
+// TenancyConfig configures the multi-tenancy of the application.
+type TenancyConfig struct {
+	// Resolver resolves the tenant of the requests. It defaults to the resolver configured by TENANT_STRATEGY, see
+	// tenant.ResolverFromConfig.
+	Resolver tenant.Resolver
+	// Optional serves the requests without a tenant instead of rejecting them with 400 Bad Request.
+	Optional bool
+	// Allowed reports whether a tenant is known, the requests of the other tenants are rejected with 403 Forbidden.
+	// It defaults to the tenants of TENANT_ALLOWED_IDS, see tenant.AllowListFromConfig, and any valid tenant is
+	// served when it is not set either.
+	Allowed func(id string) bool
+	// SQL returns the configs of the SQL connection of a tenant. It defaults to tenant.Config, which reads the
+	// TENANT_DB_NAME and TENANT_DB_SCHEMA templates and the TENANT_<ID>_DB_* overrides.
+	SQL func(conf config.Config, id string) config.Config
+}
+
+// EnableTenancy resolves the tenant of each HTTP request, which handlers read with ctx.Tenant, and counts the
+// requests of each tenant in the app_http_tenant_requests_total metric.
+//
+// When TENANT_DB_NAME or TENANT_DB_SCHEMA is set, or cfg.SQL is given, ctx.TenantSQL returns a connection per tenant,
+// to a database or a schema of its own, which is opened on the first request of the tenant. At most
+// TENANT_SQL_MAX_POOLS connections, 100 by default, are kept open, closing the least recently used ones once the
+// requests using them are served. Otherwise, ctx.TenantSQL returns the default connection, shared by the tenants.
+//
+// As each tenant opens a connection of its own, the known tenants should be listed with cfg.Allowed or
+// TENANT_ALLOWED_IDS: otherwise, a client sending many tenants opens a connection per request, and evicts the
+// connections of the other tenants.
+func (a *App) EnableTenancy(cfg TenancyConfig) {
+	if cfg.Resolver == nil {
+		resolver, err := tenant.ResolverFromConfig(a.Config)
+		if err != nil {
+			a.container.Errorf("multi-tenancy is not enabled: %v", err)
+
+			return
+		}
+
+		cfg.Resolver = resolver
+	}
+
+	if cfg.Allowed == nil {
+		cfg.Allowed = tenant.AllowListFromConfig(a.Config)
+	}
+
+	a.Use(middleware.Tenant(middleware.TenantConfig{Resolver: cfg.Resolver, Optional: cfg.Optional,
+		Allowed: cfg.Allowed, Metrics: a.Metrics()}))
+
+	if cfg.SQL == nil {
+		if a.Config.Get("TENANT_DB_NAME") == "" && a.Config.Get("TENANT_DB_SCHEMA") == "" {
+			return
+		}
+
+		cfg.SQL = tenant.Config
+	}
+
+	if cfg.Allowed == nil {
+		a.container.Warnf("TENANT_ALLOWED_IDS is not set, the requests of any tenant open a SQL connection of its own")
+	}
+
+	size, err := strconv.Atoi(a.Config.GetOrDefault("TENANT_SQL_MAX_POOLS", strconv.Itoa(defaultTenantSQLPools)))
+	if err != nil {
+		a.container.Warnf("invalid TENANT_SQL_MAX_POOLS, using %d: %v", defaultTenantSQLPools, err)
+
+		size = defaultTenantSQLPools
+	}
+
+	a.container.UseTenantSQL(tenant.NewPool(size, func(id string) (infra.DB, error) {
+		return sql.NewTenantSQL(id, cfg.SQL(a.Config, id), a.Logger(), a.Metrics()), nil
+	}, infra.DB.Close))
+}
+
+// Tenant returns the tenant of the request resolved by App.EnableTenancy, or an empty string.
+func (c *Context) Tenant() string {
+	return tenant.FromContext(c.Context)
+}
+
+// TenantSQL returns the SQL connection of the tenant of the request, see App.EnableTenancy. It returns
+// tenant.ErrNoTenant when the request has no tenant. The connection is held until the context of the request is
+// done, so that it is not closed while the request uses it.
+func (c *Context) TenantSQL() (infra.DB, error) {
+	db, release, err := c.Container.AcquireTenantSQL(c.Tenant())
+	if err != nil {
+		return nil, err
+	}
+
+	context.AfterFunc(c.Context, release)
+
+	return db, nil
+}
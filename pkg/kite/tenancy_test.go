@@ -0,0 +1,146 @@
+package kite
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sllt/kite/pkg/kite/infra"
+	"github.com/sllt/kite/pkg/kite/logging"
+	"github.com/sllt/kite/pkg/kite/tenant"
+	"github.com/sllt/kite/pkg/kite/testutil"
+)
+
+func TestApp_EnableTenancy(t *testing.T) {
+	app := newVersioningTestApp(map[string]string{"TENANT_HEADER": "X-Org", "TENANT_ALLOWED_IDS": "acme,beta"})
+
+	app.EnableTenancy(TenancyConfig{})
+
+	app.GET("/orders", func(c *Context) (any, error) {
+		db, err := c.TenantSQL()
+
+		return map[string]any{"tenant": c.Tenant(), "shared": db == c.SQL}, err
+	})
+
+	app.httpServer.registry.compile(app.httpServer.router.Mux(), app.container, 0)
+
+	req := httptest.NewRequest(http.MethodGet, "/orders", http.NoBody)
+	req.Header.Set("X-Org", "acme")
+
+	rec := httptest.NewRecorder()
+	app.httpServer.router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"data":{"tenant":"acme","shared":true}}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	app.httpServer.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders", http.NoBody))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "missing tenant")
+
+	req = httptest.NewRequest(http.MethodGet, "/orders", http.NoBody)
+	req.Header.Set("X-Org", "gamma")
+
+	rec = httptest.NewRecorder()
+	app.httpServer.router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "unknown tenant 'gamma'")
+}
+
+func TestApp_EnableTenancy_SQLPerTenant(t *testing.T) {
+	dir := t.TempDir()
+
+	app := newVersioningTestApp(map[string]string{
+		"DB_DIALECT":     "sqlite",
+		"TENANT_DB_NAME": filepath.Join(dir, "{tenant}"),
+	})
+
+	app.EnableTenancy(TenancyConfig{})
+
+	acme, err := app.container.TenantSQL("acme")
+	require.NoError(t, err)
+
+	beta, err := app.container.TenantSQL("beta")
+	require.NoError(t, err)
+
+	_, err = acme.Exec("CREATE TABLE orders (id INTEGER PRIMARY KEY)")
+	require.NoError(t, err)
+
+	_, err = beta.Exec("SELECT 1 FROM orders")
+	require.Error(t, err, "each tenant has its own database")
+
+	same, err := app.container.TenantSQL("acme")
+	require.NoError(t, err)
+	assert.Same(t, acme, same)
+
+	assert.FileExists(t, filepath.Join(dir, "acme.db"))
+
+	require.NoError(t, app.container.Close())
+}
+
+func TestContext_TenantSQL_HeldUntilDone(t *testing.T) {
+	app := newVersioningTestApp(map[string]string{
+		"DB_DIALECT":           "sqlite",
+		"TENANT_DB_NAME":       filepath.Join(t.TempDir(), "{tenant}"),
+		"TENANT_SQL_MAX_POOLS": "1",
+	})
+
+	app.EnableTenancy(TenancyConfig{})
+
+	reqCtx, done := context.WithCancel(t.Context())
+	ctx := &Context{Context: tenant.WithTenant(reqCtx, "acme"), Container: app.container}
+
+	acme, err := ctx.TenantSQL()
+	require.NoError(t, err)
+
+	// the connection of beta evicts the one of acme, which the request still uses.
+	_, err = app.container.TenantSQL("beta")
+	require.NoError(t, err)
+
+	_, err = acme.Exec("SELECT 1")
+	require.NoError(t, err)
+
+	done()
+
+	assert.Eventually(t, func() bool {
+		_, err := acme.Exec("SELECT 1")
+
+		return err != nil
+	}, time.Second, 10*time.Millisecond, "the evicted connection is closed once the request is done")
+
+	require.NoError(t, app.container.Close())
+}
+
+func TestApp_EnableTenancy_InvalidStrategy(t *testing.T) {
+	logs := testutil.StderrOutputForFunc(func() {
+		app := newVersioningTestApp(map[string]string{"TENANT_STRATEGY": "host"})
+		app.container.Logger = logging.NewMockLogger(logging.ERROR)
+
+		app.EnableTenancy(TenancyConfig{})
+
+		assert.Empty(t, app.httpServer.registry.root.httpMWs)
+	})
+
+	assert.Contains(t, logs, "multi-tenancy is not enabled: TENANT_DOMAIN is required by the host strategy")
+}
+
+func TestContext_Tenant(t *testing.T) {
+	ctx := &Context{Context: tenant.WithTenant(t.Context(), "acme"), Container: &infra.Container{}}
+
+	assert.Equal(t, "acme", ctx.Tenant())
+
+	ctx = &Context{Context: t.Context(), Container: &infra.Container{}}
+
+	_, err := ctx.TenantSQL()
+
+	assert.Empty(t, ctx.Tenant())
+	require.ErrorIs(t, err, tenant.ErrNoTenant)
+}
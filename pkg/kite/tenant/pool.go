@@ -0,0 +1,235 @@
+package tenant
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var (
+	errInvalidID   = errors.New("invalid tenant id")
+	errPoolClosed  = errors.New("tenant pool is closed")
+	errNilResource = errors.New("no resource opened for the tenant")
+)
+
+// Pool holds a resource per tenant, e.g. a connection pool to the database of the tenant. The resources are opened
+// on the first use by a tenant and kept for the next requests, and the least recently used ones are evicted when the
+// pool holds more than its size. An evicted resource is closed once it is released by all the callers which acquired
+// it, so that it is not closed while a request uses it. It is safe for concurrent use.
+type Pool[T any] struct {
+	open  func(id string) (T, error)
+	close func(T) error
+	size  int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+	// evicted are the entries evicted while they were acquired, closed by their last release.
+	evicted map[*poolEntry[T]]struct{}
+	closed  bool
+}
+
+type poolEntry[T any] struct {
+	id    string
+	once  sync.Once
+	value T
+	err   error
+
+	// refs counts the callers holding the resource, guarded by the mutex of the pool.
+	refs int
+}
+
+// NewPool returns a pool opening the resource of a tenant with open, and closing it with close when it is evicted or
+// the pool is closed. A size of 0 or less keeps the resources of all the tenants.
+func NewPool[T any](size int, open func(id string) (T, error), close func(T) error) *Pool[T] {
+	return &Pool[T]{open: open, close: close, size: size, order: list.New(), entries: make(map[string]*list.Element),
+		evicted: make(map[*poolEntry[T]]struct{})}
+}
+
+// Get returns the resource of the tenant id, opening it on the first call. The resources which failed to open are
+// opened again by the next call. The resource is not held, and is closed as soon as it is evicted: use Acquire to use
+// it while other tenants could evict it.
+func (p *Pool[T]) Get(id string) (T, error) {
+	value, release, err := p.Acquire(id)
+	if err != nil {
+		return value, err
+	}
+
+	release()
+
+	return value, nil
+}
+
+// Acquire returns the resource of the tenant id, opening it on the first call, and holds it until release is called:
+// the resource is not closed when it is evicted meanwhile, but by the release. release must be called once.
+func (p *Pool[T]) Acquire(id string) (value T, release func(), err error) {
+	var zero T
+
+	if !ValidID(id) {
+		return zero, nil, fmt.Errorf("%w: %q", errInvalidID, id)
+	}
+
+	entry, evicted, err := p.entry(id)
+	if err != nil {
+		return zero, nil, err
+	}
+
+	p.closeAll(evicted)
+
+	entry.once.Do(func() {
+		entry.value, entry.err = p.open(id)
+		if entry.err == nil && isNilResource(entry.value) {
+			entry.err = fmt.Errorf("%w %q", errNilResource, id)
+		}
+	})
+
+	if entry.err != nil {
+		p.remove(entry)
+		p.release(entry)
+
+		return zero, nil, entry.err
+	}
+
+	var once sync.Once
+
+	return entry.value, func() { once.Do(func() { p.release(entry) }) }, nil
+}
+
+// Len returns the number of tenants with a resource in the pool.
+func (p *Pool[T]) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.order.Len()
+}
+
+// Close closes the resources of all the tenants, including the ones still acquired. The pool cannot be used
+// afterward.
+func (p *Pool[T]) Close() error {
+	p.mu.Lock()
+
+	p.closed = true
+
+	entries := make([]*poolEntry[T], 0, p.order.Len()+len(p.evicted))
+	for e := p.order.Front(); e != nil; e = e.Next() {
+		entries = append(entries, e.Value.(*poolEntry[T]))
+	}
+
+	// the resources still held by callers are closed as well, their release is then a no-op.
+	for entry := range p.evicted {
+		entries = append(entries, entry)
+	}
+
+	p.order.Init()
+	clear(p.entries)
+	clear(p.evicted)
+
+	p.mu.Unlock()
+
+	return p.closeAll(entries)
+}
+
+// entry acquires the entry of the tenant, creating it when missing, and returns the entries evicted to make room for
+// it which are not held by any caller, to be closed.
+func (p *Pool[T]) entry(id string) (*poolEntry[T], []*poolEntry[T], error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, nil, errPoolClosed
+	}
+
+	if e, ok := p.entries[id]; ok {
+		p.order.MoveToFront(e)
+
+		entry := e.Value.(*poolEntry[T])
+		entry.refs++
+
+		return entry, nil, nil
+	}
+
+	entry := &poolEntry[T]{id: id, refs: 1}
+	p.entries[id] = p.order.PushFront(entry)
+
+	var evicted []*poolEntry[T]
+
+	for p.size > 0 && p.order.Len() > p.size {
+		oldest := p.order.Back()
+		p.order.Remove(oldest)
+
+		e := oldest.Value.(*poolEntry[T])
+		delete(p.entries, e.id)
+
+		if e.refs > 0 {
+			p.evicted[e] = struct{}{}
+
+			continue
+		}
+
+		evicted = append(evicted, e)
+	}
+
+	return entry, evicted, nil
+}
+
+// release releases an acquired entry, closing its resource when it was evicted and this was its last caller.
+func (p *Pool[T]) release(entry *poolEntry[T]) {
+	p.mu.Lock()
+
+	entry.refs--
+
+	_, draining := p.evicted[entry]
+	if !draining || entry.refs > 0 {
+		p.mu.Unlock()
+
+		return
+	}
+
+	delete(p.evicted, entry)
+	p.mu.Unlock()
+
+	p.closeAll([]*poolEntry[T]{entry})
+}
+
+func (p *Pool[T]) remove(entry *poolEntry[T]) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if e, ok := p.entries[entry.id]; ok && e.Value == entry {
+		p.order.Remove(e)
+		delete(p.entries, entry.id)
+	}
+}
+
+// closeAll closes the resources of the entries which were opened successfully.
+func (p *Pool[T]) closeAll(entries []*poolEntry[T]) error {
+	var err error
+
+	for _, entry := range entries {
+		// waits for the resource to be opened, when it is being opened by another call.
+		entry.once.Do(func() { entry.err = errPoolClosed })
+
+		if entry.err == nil && p.close != nil {
+			err = errors.Join(err, p.close(entry.value))
+		}
+	}
+
+	return err
+}
+
+// isNilResource reports whether v is nil, including a nil pointer held by an interface, as returned by the
+// constructors of the datasources which are not configured.
+func isNilResource(v any) bool {
+	if v == nil {
+		return true
+	}
+
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return rv.IsNil()
+	default:
+		return false
+	}
+}
@@ -0,0 +1,175 @@
+package tenant
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errOpen = errors.New("open failed")
+
+type resource struct {
+	id     string
+	closed bool
+}
+
+type poolRecorder struct {
+	mu     sync.Mutex
+	opened []string
+	fail   map[string]bool
+}
+
+func (r *poolRecorder) open(id string) (*resource, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.opened = append(r.opened, id)
+
+	if r.fail[id] {
+		return nil, errOpen
+	}
+
+	return &resource{id: id}, nil
+}
+
+func closeResource(r *resource) error {
+	r.closed = true
+
+	return nil
+}
+
+func TestPool_Get(t *testing.T) {
+	rec := &poolRecorder{}
+	pool := NewPool(0, rec.open, closeResource)
+
+	var wg sync.WaitGroup
+
+	for range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			r, err := pool.Get("acme")
+
+			assert.NoError(t, err)
+			assert.Equal(t, "acme", r.id)
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, []string{"acme"}, rec.opened)
+	assert.Equal(t, 1, pool.Len())
+}
+
+func TestPool_Eviction(t *testing.T) {
+	rec := &poolRecorder{}
+	pool := NewPool(2, rec.open, closeResource)
+
+	acme, _ := pool.Get("acme")
+	beta, _ := pool.Get("beta")
+
+	// acme becomes the most recently used tenant, so that beta is evicted.
+	_, _ = pool.Get("acme")
+	_, _ = pool.Get("gamma")
+
+	assert.Equal(t, 2, pool.Len())
+	assert.False(t, acme.closed)
+	assert.True(t, beta.closed)
+
+	_, _ = pool.Get("beta")
+
+	assert.Equal(t, []string{"acme", "beta", "gamma", "beta"}, rec.opened)
+}
+
+func TestPool_AcquireEviction(t *testing.T) {
+	rec := &poolRecorder{}
+	pool := NewPool(1, rec.open, closeResource)
+
+	acme, release, err := pool.Acquire("acme")
+	require.NoError(t, err)
+
+	// acme is evicted while a request uses it.
+	beta, err := pool.Get("beta")
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, pool.Len())
+	assert.False(t, acme.closed, "an acquired resource must not be closed when it is evicted")
+
+	release()
+	release()
+
+	assert.True(t, acme.closed)
+	assert.False(t, beta.closed)
+}
+
+func TestPool_CloseAcquired(t *testing.T) {
+	rec := &poolRecorder{}
+	pool := NewPool(1, rec.open, closeResource)
+
+	acme, release, err := pool.Acquire("acme")
+	require.NoError(t, err)
+
+	_, _ = pool.Get("beta")
+
+	require.NoError(t, pool.Close())
+	assert.True(t, acme.closed)
+
+	release()
+}
+
+func TestPool_OpenFailure(t *testing.T) {
+	rec := &poolRecorder{fail: map[string]bool{"acme": true}}
+	pool := NewPool(0, rec.open, closeResource)
+
+	_, err := pool.Get("acme")
+	require.ErrorIs(t, err, errOpen)
+	assert.Equal(t, 0, pool.Len())
+
+	rec.fail["acme"] = false
+
+	r, err := pool.Get("acme")
+	require.NoError(t, err)
+	assert.Equal(t, "acme", r.id)
+	assert.Equal(t, []string{"acme", "acme"}, rec.opened)
+}
+
+func TestPool_NilResource(t *testing.T) {
+	pool := NewPool(0, func(string) (*resource, error) { return nil, nil }, closeResource)
+
+	_, err := pool.Get("acme")
+
+	require.ErrorIs(t, err, errNilResource)
+	assert.Equal(t, 0, pool.Len())
+}
+
+func TestPool_InvalidID(t *testing.T) {
+	rec := &poolRecorder{}
+	pool := NewPool(0, rec.open, closeResource)
+
+	_, err := pool.Get("acme;drop")
+
+	require.ErrorIs(t, err, errInvalidID)
+	assert.Empty(t, rec.opened)
+}
+
+func TestPool_Close(t *testing.T) {
+	rec := &poolRecorder{}
+	pool := NewPool(0, rec.open, closeResource)
+
+	acme, _ := pool.Get("acme")
+	beta, _ := pool.Get("beta")
+
+	require.NoError(t, pool.Close())
+
+	assert.True(t, acme.closed)
+	assert.True(t, beta.closed)
+	assert.Equal(t, 0, pool.Len())
+
+	_, err := pool.Get("acme")
+	require.ErrorIs(t, err, errPoolClosed)
+}
@@ -0,0 +1,228 @@
+// Package tenant resolves the tenant of the requests of multi-tenant applications, and holds the resources opened for
+// each tenant, e.g. their SQL connections.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sllt/kite/pkg/kite/config"
+)
+
+const (
+	defaultHeader = "X-Tenant-ID"
+	maxIDLength   = 63
+)
+
+var (
+	// ErrNoTenant is returned when a tenant is needed, e.g. by ctx.TenantSQL, and the request has none.
+	ErrNoTenant = errors.New("no tenant resolved for the request")
+
+	errUnknownStrategy = errors.New("unknown tenant strategy")
+	errMissingDomain   = errors.New("TENANT_DOMAIN is required by the host strategy")
+)
+
+type contextKey struct{}
+
+// WithTenant returns a copy of ctx holding the tenant id, e.g. to run a job or to handle a message for a tenant.
+func WithTenant(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the tenant held by ctx, or an empty string.
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	id, _ := ctx.Value(contextKey{}).(string)
+
+	return id
+}
+
+// ValidID reports whether id can identify a tenant: 1 to 63 letters, digits, '-' or '_'. The ids are used in the
+// names of the databases, schemas and configs of the tenants, so that the other ids are rejected.
+func ValidID(id string) bool {
+	if id == "" || len(id) > maxIDLength {
+		return false
+	}
+
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// Resolver resolves the tenant of a request, returning an empty string when the request has none.
+type Resolver func(r *http.Request) string
+
+// Header resolves the tenant from the header name, e.g. "X-Tenant-ID".
+func Header(name string) Resolver {
+	return func(r *http.Request) string {
+		return strings.TrimSpace(r.Header.Get(name))
+	}
+}
+
+// Subdomain resolves the tenant from the subdomain of domain in the host of the request, e.g. "acme" for
+// acme.example.com with the domain example.com. The hosts which are not a direct subdomain of domain have no tenant.
+func Subdomain(domain string) Resolver {
+	suffix := "." + strings.ToLower(strings.Trim(domain, "."))
+
+	return func(r *http.Request) string {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+
+		sub, ok := strings.CutSuffix(strings.ToLower(host), suffix)
+		if !ok || strings.Contains(sub, ".") {
+			return ""
+		}
+
+		return sub
+	}
+}
+
+// PathSegment resolves the tenant from the segment of the path at index, starting at 0, e.g. "acme" for
+// /tenants/acme/orders with the index 1.
+func PathSegment(index int) Resolver {
+	return func(r *http.Request) string {
+		segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		if index < 0 || index >= len(segments) {
+			return ""
+		}
+
+		return segments[index]
+	}
+}
+
+// FirstOf resolves the tenant with the first of the resolvers which resolves one.
+func FirstOf(resolvers ...Resolver) Resolver {
+	return func(r *http.Request) string {
+		for _, resolve := range resolvers {
+			if id := resolve(r); id != "" {
+				return id
+			}
+		}
+
+		return ""
+	}
+}
+
+// ResolverFromConfig returns the resolver configured by TENANT_STRATEGY, "header" by default:
+//   - header reads the TENANT_HEADER header, X-Tenant-ID by default.
+//   - host reads the subdomain of TENANT_DOMAIN.
+//   - path reads the TENANT_PATH_SEGMENT segment of the path, the first one by default.
+//
+// Several strategies are tried in order when they are separated by commas, e.g. "host,header".
+func ResolverFromConfig(conf config.Config) (Resolver, error) {
+	var resolvers []Resolver
+
+	for _, strategy := range strings.Split(conf.GetOrDefault("TENANT_STRATEGY", "header"), ",") {
+		switch strings.ToLower(strings.TrimSpace(strategy)) {
+		case "header":
+			resolvers = append(resolvers, Header(conf.GetOrDefault("TENANT_HEADER", defaultHeader)))
+		case "host":
+			domain := conf.Get("TENANT_DOMAIN")
+			if domain == "" {
+				return nil, errMissingDomain
+			}
+
+			resolvers = append(resolvers, Subdomain(domain))
+		case "path":
+			index, err := strconv.Atoi(conf.GetOrDefault("TENANT_PATH_SEGMENT", "0"))
+			if err != nil {
+				return nil, fmt.Errorf("invalid TENANT_PATH_SEGMENT: %w", err)
+			}
+
+			resolvers = append(resolvers, PathSegment(index))
+		default:
+			return nil, fmt.Errorf("%w: %q", errUnknownStrategy, strategy)
+		}
+	}
+
+	if len(resolvers) == 1 {
+		return resolvers[0], nil
+	}
+
+	return FirstOf(resolvers...), nil
+}
+
+// AllowList reports whether a tenant is one of ids, to reject the requests of unknown tenants, which would otherwise
+// open resources of their own, e.g. a connection to a database per tenant.
+func AllowList(ids ...string) func(id string) bool {
+	allowed := make(map[string]bool, len(ids))
+
+	for _, id := range ids {
+		if id = strings.TrimSpace(id); id != "" {
+			allowed[id] = true
+		}
+	}
+
+	return func(id string) bool {
+		return allowed[id]
+	}
+}
+
+// AllowListFromConfig returns the AllowList of the comma separated tenants of TENANT_ALLOWED_IDS, or nil when it is
+// not set, allowing any tenant.
+func AllowListFromConfig(conf config.Config) func(id string) bool {
+	ids := conf.Get("TENANT_ALLOWED_IDS")
+	if strings.TrimSpace(ids) == "" {
+		return nil
+	}
+
+	return AllowList(strings.Split(ids, ",")...)
+}
+
+// Config returns the view of conf for the tenant id, with which the datasources of the tenant are configured:
+//   - TENANT_<ID>_<KEY> overrides any key for the tenant, e.g. TENANT_ACME_DB_HOST for a tenant with its own server,
+//     where <ID> is the upper-cased id with '-' replaced by '_'.
+//   - TENANT_<KEY> is a template of the key for all the tenants, where {tenant} is replaced by the id, e.g.
+//     TENANT_DB_NAME=shop_{tenant} for a database per tenant or TENANT_DB_SCHEMA=tenant_{tenant} for a schema per
+//     tenant.
+//   - the other keys are read from conf.
+func Config(conf config.Config, id string) config.Config {
+	return &tenantConfig{Config: conf, id: id, prefix: "TENANT_" + configKey(id) + "_"}
+}
+
+type tenantConfig struct {
+	config.Config
+
+	id     string
+	prefix string
+}
+
+func (c *tenantConfig) Get(key string) string {
+	if v := c.Config.Get(c.prefix + key); v != "" {
+		return v
+	}
+
+	if v := c.Config.Get("TENANT_" + key); v != "" {
+		return strings.ReplaceAll(v, "{tenant}", c.id)
+	}
+
+	return c.Config.Get(key)
+}
+
+func (c *tenantConfig) GetOrDefault(key, defaultValue string) string {
+	if v := c.Get(key); v != "" {
+		return v
+	}
+
+	return defaultValue
+}
+
+func configKey(id string) string {
+	return strings.ToUpper(strings.ReplaceAll(id, "-", "_"))
+}
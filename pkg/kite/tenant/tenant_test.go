@@ -0,0 +1,142 @@
+package tenant
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sllt/kite/pkg/kite/config"
+)
+
+func TestContext(t *testing.T) {
+	assert.Empty(t, FromContext(context.Background()))
+	assert.Equal(t, "acme", FromContext(WithTenant(context.Background(), "acme")))
+}
+
+func TestValidID(t *testing.T) {
+	testCases := []struct {
+		id    string
+		valid bool
+	}{
+		{"acme", true},
+		{"Acme-Corp_2", true},
+		{"", false},
+		{"acme.corp", false},
+		{"acme corp", false},
+		{"acme;drop table", false},
+		{"été", false},
+		{string(make([]byte, 64)), false},
+	}
+
+	for i, tc := range testCases {
+		assert.Equal(t, tc.valid, ValidID(tc.id), "TEST[%d], Failed.\n%s", i, tc.id)
+	}
+}
+
+func TestResolvers(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		resolver Resolver
+		target   string
+		header   string
+		expected string
+	}{
+		{"header", Header("X-Tenant-ID"), "http://example.com/", " acme ", "acme"},
+		{"missing header", Header("X-Tenant-ID"), "http://example.com/", "", ""},
+		{"subdomain", Subdomain("example.com"), "http://acme.example.com/orders", "", "acme"},
+		{"subdomain with port", Subdomain(".Example.com."), "http://ACME.example.com:8000/", "", "acme"},
+		{"domain without subdomain", Subdomain("example.com"), "http://example.com/", "", ""},
+		{"nested subdomain", Subdomain("example.com"), "http://a.b.example.com/", "", ""},
+		{"other domain", Subdomain("example.com"), "http://acme.example.org/", "", ""},
+		{"path segment", PathSegment(1), "http://example.com/tenants/acme/orders", "", "acme"},
+		{"missing path segment", PathSegment(3), "http://example.com/tenants/acme", "", ""},
+		{"first of", FirstOf(Subdomain("example.com"), Header("X-Tenant-ID")), "http://example.com/", "beta", "beta"},
+	}
+
+	for i, tc := range testCases {
+		req := httptest.NewRequest(http.MethodGet, tc.target, http.NoBody)
+		req.Header.Set("X-Tenant-ID", tc.header)
+
+		assert.Equal(t, tc.expected, tc.resolver(req), "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestResolverFromConfig(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		configs  map[string]string
+		header   string
+		expected string
+	}{
+		{"default header", map[string]string{}, "X-Tenant-ID", "acme"},
+		{"custom header", map[string]string{"TENANT_HEADER": "X-Org"}, "X-Org", "acme"},
+		{"host", map[string]string{"TENANT_STRATEGY": "host", "TENANT_DOMAIN": "example.com"}, "", "shop"},
+		{"path", map[string]string{"TENANT_STRATEGY": "path", "TENANT_PATH_SEGMENT": "1"}, "", "beta"},
+		{"host then header", map[string]string{"TENANT_STRATEGY": "host, header", "TENANT_DOMAIN": "other.com"},
+			"X-Tenant-ID", "acme"},
+	}
+
+	for i, tc := range testCases {
+		resolve, err := ResolverFromConfig(config.NewMockConfig(tc.configs))
+		require.NoError(t, err, "TEST[%d], Failed.\n%s", i, tc.desc)
+
+		req := httptest.NewRequest(http.MethodGet, "http://shop.example.com/t/beta", http.NoBody)
+		if tc.header != "" {
+			req.Header.Set(tc.header, "acme")
+		}
+
+		assert.Equal(t, tc.expected, resolve(req), "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestResolverFromConfig_Errors(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		configs map[string]string
+		err     string
+	}{
+		{"unknown strategy", map[string]string{"TENANT_STRATEGY": "cookie"}, `unknown tenant strategy: "cookie"`},
+		{"missing domain", map[string]string{"TENANT_STRATEGY": "host"}, "TENANT_DOMAIN is required by the host strategy"},
+		{"invalid segment", map[string]string{"TENANT_STRATEGY": "path", "TENANT_PATH_SEGMENT": "x"},
+			"invalid TENANT_PATH_SEGMENT"},
+	}
+
+	for i, tc := range testCases {
+		_, err := ResolverFromConfig(config.NewMockConfig(tc.configs))
+
+		assert.ErrorContains(t, err, tc.err, "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	base := config.NewMockConfig(map[string]string{
+		"DB_HOST":                "db.internal",
+		"DB_NAME":                "shop",
+		"TENANT_DB_NAME":         "shop_{tenant}",
+		"TENANT_ACME_CO_DB_HOST": "acme.db.internal",
+	})
+
+	acme := Config(base, "acme-co")
+	beta := Config(base, "beta")
+
+	assert.Equal(t, "acme.db.internal", acme.Get("DB_HOST"))
+	assert.Equal(t, "shop_acme-co", acme.Get("DB_NAME"))
+	assert.Equal(t, "db.internal", beta.Get("DB_HOST"))
+	assert.Equal(t, "shop_beta", beta.Get("DB_NAME"))
+	assert.Equal(t, "5432", beta.GetOrDefault("DB_PORT", "5432"))
+}
+
+func TestAllowListFromConfig(t *testing.T) {
+	assert.Nil(t, AllowListFromConfig(config.NewMockConfig(nil)))
+
+	allowed := AllowListFromConfig(config.NewMockConfig(map[string]string{"TENANT_ALLOWED_IDS": "acme, beta,"}))
+
+	assert.True(t, allowed("acme"))
+	assert.True(t, allowed("beta"))
+	assert.False(t, allowed("gamma"))
+	assert.False(t, allowed(""))
+}
@@ -0,0 +1,385 @@
+// Package pubsubtest provides helpers to test the subscriber handlers of an application against recorded messages, as
+// the handlers of the HTTP routes are tested against requests.
+//
+// The messages are read from JSON fixtures, delivered to the handler with the kite.Context it receives from the
+// subscriber, and the outcome of each delivery is recorded: whether the message was committed, the error or the
+// panic of the handler, and the messages it published, e.g. to a dead letter topic.
+package pubsubtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/sllt/kite/pkg/kite"
+	"github.com/sllt/kite/pkg/kite/datasource"
+	"github.com/sllt/kite/pkg/kite/datasource/pubsub"
+	"github.com/sllt/kite/pkg/kite/infra"
+	"github.com/sllt/kite/pkg/kite/logging"
+)
+
+var errSubscribeNotSupported = errors.New("subscribing is not supported by the recording publisher")
+
+// Fixture is a recorded message. It is read from a JSON file:
+//
+//	{
+//	  "topic": "orders",
+//	  "headers": {"event-type": "order.created"},
+//	  "value": {"orderId": "42", "status": "created"}
+//	}
+//
+// A value given as a JSON string is delivered as its text, any other value as its JSON encoding.
+type Fixture struct {
+	// Name is the file of the fixture, empty for the fixtures created in the test.
+	Name    string            `json:"-"`
+	Topic   string            `json:"topic"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Value   json.RawMessage   `json:"value"`
+}
+
+// payload returns the value delivered to the handler.
+func (f Fixture) payload() []byte {
+	var text string
+	if err := json.Unmarshal(f.Value, &text); err == nil {
+		return []byte(text)
+	}
+
+	return f.Value
+}
+
+// LoadFixture reads a fixture from a JSON file.
+func LoadFixture(path string) (Fixture, error) {
+	var f Fixture
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return f, fmt.Errorf("failed to read fixture %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, &f); err != nil {
+		return f, fmt.Errorf("failed to parse fixture %s: %w", path, err)
+	}
+
+	f.Name = filepath.Base(path)
+
+	return f, nil
+}
+
+// LoadFixtures reads the fixtures of the JSON files of the directory, in the order of their names.
+func LoadFixtures(dir string) ([]Fixture, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(paths)
+
+	fixtures := make([]Fixture, 0, len(paths))
+
+	for _, path := range paths {
+		f, err := LoadFixture(path)
+		if err != nil {
+			return nil, err
+		}
+
+		fixtures = append(fixtures, f)
+	}
+
+	return fixtures, nil
+}
+
+// PublishedMessage is a message published by a handler.
+type PublishedMessage struct {
+	Topic string
+	Value []byte
+}
+
+// Publisher is a pubsub.Client recording the messages published through it instead of sending them.
+type Publisher struct {
+	mu        sync.Mutex
+	published []PublishedMessage
+	err       error
+}
+
+// Publish records the message, or returns the error set with FailWith.
+func (p *Publisher) Publish(_ context.Context, topic string, message []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.err != nil {
+		return p.err
+	}
+
+	p.published = append(p.published, PublishedMessage{Topic: topic, Value: append([]byte(nil), message...)})
+
+	return nil
+}
+
+// FailWith makes the next publications fail with err, e.g. to test a handler when the broker is down. A nil err
+// restores the publications.
+func (p *Publisher) FailWith(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.err = err
+}
+
+// Published returns the messages published so far.
+func (p *Publisher) Published() []PublishedMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]PublishedMessage(nil), p.published...)
+}
+
+func (*Publisher) Subscribe(context.Context, string) (*pubsub.Message, error) {
+	return nil, errSubscribeNotSupported
+}
+
+func (*Publisher) Health() datasource.Health {
+	return datasource.Health{Status: datasource.StatusUp}
+}
+
+func (*Publisher) CreateTopic(context.Context, string) error { return nil }
+
+func (*Publisher) DeleteTopic(context.Context, string) error { return nil }
+
+func (*Publisher) Query(context.Context, string, ...any) ([]byte, error) { return nil, nil }
+
+func (*Publisher) Close() error { return nil }
+
+// committer counts the commits of a message.
+type committer struct {
+	mu      sync.Mutex
+	commits int
+}
+
+func (c *committer) Commit() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.commits++
+}
+
+func (c *committer) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.commits
+}
+
+// Harness delivers fixtures to subscriber handlers.
+type Harness struct {
+	t         *testing.T
+	container *infra.Container
+	publisher *Publisher
+}
+
+// New returns a harness delivering the messages with the container, e.g. one created with infra.NewMockContainer to
+// set the expectations of the datasources used by the handler. A mock container is created when container is nil.
+//
+// The pubsub client of the container is replaced by a recording Publisher.
+func New(t *testing.T, container *infra.Container) *Harness {
+	t.Helper()
+
+	if container == nil {
+		container, _ = infra.NewMockContainer(t)
+	}
+
+	h := &Harness{t: t, container: container, publisher: &Publisher{}}
+
+	container.PubSub = h.publisher
+
+	return h
+}
+
+// Container returns the container of the handlers.
+func (h *Harness) Container() *infra.Container {
+	return h.container
+}
+
+// Publisher returns the publisher recording the messages published by the handlers.
+func (h *Harness) Publisher() *Publisher {
+	return h.publisher
+}
+
+// Deliver delivers the fixture to the handler as the subscriber does: the handler is called with the message as the
+// request of its context, a panic is recovered, and the message is committed unless the handler returns an error.
+func (h *Harness) Deliver(handler kite.SubscribeFunc, f Fixture) *Delivery {
+	h.t.Helper()
+
+	msg := pubsub.NewMessage(context.Background())
+	msg.Topic = f.Topic
+	msg.Value = f.payload()
+
+	if f.Headers != nil {
+		msg.MetaData = f.Headers
+	}
+
+	c := &committer{}
+	msg.Committer = c
+
+	ctx := &kite.Context{
+		Context:       msg.Context(),
+		Request:       msg,
+		Container:     h.container,
+		ContextLogger: *logging.NewContextLogger(msg.Context(), h.container.Logger),
+	}
+
+	published := len(h.publisher.Published())
+
+	d := &Delivery{t: h.t, Fixture: f}
+
+	d.Err = func() (err error) {
+		defer func() {
+			// as the subscriber, a recovered panic does not prevent the commit.
+			d.Panic = recover()
+		}()
+
+		return handler(ctx)
+	}()
+
+	if d.Err == nil {
+		msg.Commit()
+	}
+
+	d.Commits = c.count()
+	d.Published = h.publisher.Published()[published:]
+
+	return d
+}
+
+// DeliverFile delivers the fixture of the file to the handler, failing the test if it cannot be read.
+func (h *Harness) DeliverFile(handler kite.SubscribeFunc, path string) *Delivery {
+	h.t.Helper()
+
+	f, err := LoadFixture(path)
+	if err != nil {
+		h.t.Fatal(err)
+	}
+
+	return h.Deliver(handler, f)
+}
+
+// Delivery is the outcome of the delivery of a fixture.
+type Delivery struct {
+	t *testing.T
+
+	Fixture Fixture
+	// Err is the error returned by the handler.
+	Err error
+	// Panic is the value of the recovered panic of the handler, nil if it did not panic.
+	Panic any
+	// Commits is the number of commits of the message, by the handler and the subscriber.
+	Commits int
+	// Published are the messages published by the handler.
+	Published []PublishedMessage
+}
+
+// Acked reports whether the message was committed.
+func (d *Delivery) Acked() bool {
+	return d.Commits > 0
+}
+
+// AssertAcked asserts that the message was committed.
+func (d *Delivery) AssertAcked() *Delivery {
+	d.t.Helper()
+
+	if !d.Acked() {
+		d.t.Errorf("%s: message not committed, handler returned error: %v", d.name(), d.Err)
+	}
+
+	return d
+}
+
+// AssertNacked asserts that the handler returned an error and the message was not committed, to be redelivered.
+func (d *Delivery) AssertNacked() *Delivery {
+	d.t.Helper()
+
+	if d.Err == nil {
+		d.t.Errorf("%s: handler returned no error", d.name())
+	}
+
+	if d.Acked() {
+		d.t.Errorf("%s: message committed", d.name())
+	}
+
+	return d
+}
+
+// AssertPanicked asserts that the handler panicked.
+func (d *Delivery) AssertPanicked() *Delivery {
+	d.t.Helper()
+
+	if d.Panic == nil {
+		d.t.Errorf("%s: handler did not panic", d.name())
+	}
+
+	return d
+}
+
+// AssertPublished asserts that the handler published the JSON document to the topic, e.g. to route the message to a
+// dead letter topic. The documents are compared as JSON values, regardless of their formatting.
+func (d *Delivery) AssertPublished(topic, expectedJSON string) *Delivery {
+	d.t.Helper()
+
+	var expected any
+	if err := json.Unmarshal([]byte(expectedJSON), &expected); err != nil {
+		d.t.Fatalf("%s: invalid expected JSON: %v", d.name(), err)
+	}
+
+	values := make([]string, 0, len(d.Published))
+
+	for _, m := range d.Published {
+		if m.Topic != topic {
+			continue
+		}
+
+		var actual any
+		if json.Unmarshal(m.Value, &actual) == nil && equalJSON(expected, actual) {
+			return d
+		}
+
+		values = append(values, string(m.Value))
+	}
+
+	d.t.Errorf("%s: %s not published to topic %s, published: %v", d.name(), expectedJSON, topic, values)
+
+	return d
+}
+
+// AssertNotPublished asserts that the handler published nothing to the topic.
+func (d *Delivery) AssertNotPublished(topic string) *Delivery {
+	d.t.Helper()
+
+	for _, m := range d.Published {
+		if m.Topic == topic {
+			d.t.Errorf("%s: unexpected message published to topic %s: %s", d.name(), topic, m.Value)
+
+			return d
+		}
+	}
+
+	return d
+}
+
+func (d *Delivery) name() string {
+	if d.Fixture.Name != "" {
+		return d.Fixture.Name
+	}
+
+	return "topic " + d.Fixture.Topic
+}
+
+func equalJSON(a, b any) bool {
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+
+	return string(x) == string(y)
+}
@@ -0,0 +1,164 @@
+package pubsubtest
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sllt/kite/pkg/kite"
+	"github.com/sllt/kite/pkg/kite/datasource/pubsub"
+)
+
+var errInvalidOrder = errors.New("invalid order")
+
+type order struct {
+	OrderID string `json:"orderId"`
+	Status  string `json:"status"`
+}
+
+// orderHandler routes the orders without an id to the dead letter topic, and nacks the orders it cannot publish.
+func orderHandler(c *kite.Context) error {
+	var o order
+
+	if err := c.Bind(&o); err != nil {
+		return err
+	}
+
+	if o.OrderID == "" {
+		data, _ := json.Marshal(map[string]any{"reason": "missing orderId", "order": o})
+
+		return c.GetPublisher().Publish(c, "orders-dlq", data)
+	}
+
+	if o.Status == "panic" {
+		panic("unexpected status")
+	}
+
+	if o.Status == "invalid" {
+		return errInvalidOrder
+	}
+
+	data, _ := json.Marshal(map[string]string{"orderId": o.OrderID, "status": "processed"})
+
+	return c.GetPublisher().Publish(c, "order-logs", data)
+}
+
+func writeFixture(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestHarness_Deliver(t *testing.T) {
+	h := New(t, nil)
+
+	h.Deliver(orderHandler, Fixture{Topic: "orders", Value: json.RawMessage(`{"orderId":"42","status":"created"}`)}).
+		AssertAcked().
+		AssertPublished("order-logs", `{"status": "processed", "orderId": "42"}`).
+		AssertNotPublished("orders-dlq")
+
+	h.Deliver(orderHandler, Fixture{Topic: "orders", Value: json.RawMessage(`{"status":"created"}`)}).
+		AssertAcked().
+		AssertPublished("orders-dlq", `{"reason":"missing orderId","order":{"orderId":"","status":"created"}}`).
+		AssertNotPublished("order-logs")
+
+	d := h.Deliver(orderHandler, Fixture{Topic: "orders", Value: json.RawMessage(`{"orderId":"43","status":"invalid"}`)}).
+		AssertNacked()
+	assert.ErrorIs(t, d.Err, errInvalidOrder)
+	assert.Empty(t, d.Published)
+
+	d = h.Deliver(orderHandler, Fixture{Topic: "orders", Value: json.RawMessage(`{"orderId":"44","status":"panic"}`)}).
+		AssertPanicked().
+		AssertAcked()
+	assert.Equal(t, "unexpected status", d.Panic)
+
+	assert.Len(t, h.Publisher().Published(), 2)
+}
+
+func TestHarness_DeliverPublishFailure(t *testing.T) {
+	h := New(t, nil)
+
+	h.Publisher().FailWith(errInvalidOrder)
+
+	d := h.Deliver(orderHandler, Fixture{Topic: "orders", Value: json.RawMessage(`{"orderId":"42"}`)}).AssertNacked()
+	assert.Empty(t, d.Published)
+
+	h.Publisher().FailWith(nil)
+
+	h.Deliver(orderHandler, Fixture{Topic: "orders", Value: json.RawMessage(`{"orderId":"42"}`)}).AssertAcked()
+}
+
+func TestHarness_DeliverFile(t *testing.T) {
+	h := New(t, nil)
+
+	var msg *pubsub.Message
+
+	d := h.DeliverFile(func(c *kite.Context) error {
+		msg, _ = c.Request.(*pubsub.Message)
+
+		return nil
+	}, writeFixture(t, t.TempDir(), "text.json",
+		`{"topic":"logs","headers":{"source":"billing"},"value":"plain text"}`))
+
+	d.AssertAcked()
+	assert.Equal(t, 1, d.Commits)
+	assert.Equal(t, "text.json", d.Fixture.Name)
+
+	require.NotNil(t, msg)
+	assert.Equal(t, "logs", msg.Topic)
+	assert.Equal(t, "plain text", string(msg.Value))
+	assert.Equal(t, map[string]string{"source": "billing"}, msg.MetaData)
+}
+
+func TestLoadFixtures(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFixture(t, dir, "02-invalid.json", `{"topic":"orders","value":{"orderId":"43","status":"invalid"}}`)
+	writeFixture(t, dir, "01-created.json",
+		`{"topic":"orders","headers":{"event-type":"order.created"},"value":{"orderId":"42"}}`)
+	writeFixture(t, dir, "notes.txt", `not a fixture`)
+
+	fixtures, err := LoadFixtures(dir)
+	require.NoError(t, err)
+	require.Len(t, fixtures, 2)
+
+	assert.Equal(t, "01-created.json", fixtures[0].Name)
+	assert.Equal(t, "orders", fixtures[0].Topic)
+	assert.Equal(t, map[string]string{"event-type": "order.created"}, fixtures[0].Headers)
+	assert.JSONEq(t, `{"orderId":"42"}`, string(fixtures[0].payload()))
+	assert.Equal(t, "02-invalid.json", fixtures[1].Name)
+
+	writeFixture(t, dir, "03-bad.json", `{"topic":`)
+
+	_, err = LoadFixtures(dir)
+	require.Error(t, err)
+
+	_, err = LoadFixture(filepath.Join(dir, "missing.json"))
+	require.Error(t, err)
+}
+
+func TestFixture_Payload(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		value    string
+		expected string
+	}{
+		{"object", `{"a":1}`, `{"a":1}`},
+		{"string", `"plain text"`, `plain text`},
+		{"number", `42`, `42`},
+	}
+
+	for i, tc := range testCases {
+		f := Fixture{Value: json.RawMessage(tc.value)}
+
+		assert.Equal(t, tc.expected, string(f.payload()), "TEST[%d], Failed.\n%s", i, tc.desc)
+	}
+}
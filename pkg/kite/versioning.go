@@ -0,0 +1,184 @@
+package kite
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sllt/kite/pkg/kite/config"
+	"github.com/sllt/kite/pkg/kite/logging"
+	"github.com/sllt/kite/pkg/kite/metrics"
+)
+
+const versionRequestsMetric = "app_http_api_version_requests_total"
+
+// versionPolicy holds the deprecation details of an API version as read from configuration.
+type versionPolicy struct {
+	version     string
+	deprecation string // value of the Deprecation header
+	sunset      string // value of the Sunset header
+	link        string // value of the Link header
+}
+
+// Version creates or gets a route group for the given API version, e.g. app.Version("v1").
+// Routes registered on the returned group are served under "/<version>".
+//
+// Deprecation of a version is driven by configuration, where <VERSION> is the upper-cased
+// version with non-alphanumeric characters replaced by underscores (v1.2 -> V1_2):
+//
+//	API_VERSION_<VERSION>_DEPRECATED  "true" or the date (RFC3339 / HTTP-date) the version was deprecated
+//	API_VERSION_<VERSION>_SUNSET      date (RFC3339 / HTTP-date) after which the version will be removed
+//	API_VERSION_<VERSION>_LINK        URL of the migration guide, sent as Link with rel="deprecation"
+//
+// Every request is counted in the app_http_api_version_requests_total metric labeled by version,
+// which can be used to track client migration between versions.
+func (a *App) Version(version string) *RouteGroup {
+	return a.rootRouteGroup().Version(version)
+}
+
+// Version creates or gets a child route group for the given API version.
+// See [App.Version] for the configuration that controls deprecation headers.
+func (g *RouteGroup) Version(version string) *RouteGroup {
+	if g == nil || g.node == nil {
+		return g
+	}
+
+	version = strings.Trim(strings.TrimSpace(version), "/")
+	if version == "" {
+		g.logError("API version cannot be empty")
+
+		return g
+	}
+
+	if g.app != nil {
+		g.app.ensureHTTPAvailable()
+	}
+
+	sub := g.Group(version)
+	if sub == g || sub.node.version != "" {
+		return sub
+	}
+
+	sub.node.version = version
+
+	if g.app == nil || g.app.container == nil {
+		return sub
+	}
+
+	policy := newVersionPolicy(version, g.app.Config, g.app.container.Logger)
+
+	return sub.Use(versionMiddleware(policy, g.app.container.Metrics()))
+}
+
+func (g *RouteGroup) logError(msg string) {
+	if g.app != nil && g.app.container != nil {
+		g.app.container.Logger.Error(msg)
+	}
+}
+
+func newVersionPolicy(version string, cfg config.Config, logger logging.Logger) versionPolicy {
+	policy := versionPolicy{version: version}
+
+	if cfg == nil {
+		return policy
+	}
+
+	prefix := "API_VERSION_" + versionConfigKey(version) + "_"
+
+	if deprecated := strings.TrimSpace(cfg.Get(prefix + "DEPRECATED")); deprecated != "" {
+		value, ok := parseDeprecation(deprecated)
+		if !ok {
+			logger.Warnf("invalid value for %sDEPRECATED: %q, expected a boolean or a date", prefix, deprecated)
+		}
+
+		policy.deprecation = value
+	}
+
+	if sunset := strings.TrimSpace(cfg.Get(prefix + "SUNSET")); sunset != "" {
+		t, err := parseVersionDate(sunset)
+		if err != nil {
+			logger.Warnf("invalid value for %sSUNSET: %q, expected an RFC3339 or HTTP date", prefix, sunset)
+		} else {
+			policy.sunset = t.UTC().Format(http.TimeFormat)
+		}
+	}
+
+	if link := strings.TrimSpace(cfg.Get(prefix + "LINK")); link != "" && policy.deprecation != "" {
+		policy.link = fmt.Sprintf("<%s>; rel=%q", link, "deprecation")
+	}
+
+	return policy
+}
+
+// parseDeprecation returns the Deprecation header value for a config value which
+// is either a boolean or the date at which the version was deprecated.
+func parseDeprecation(value string) (string, bool) {
+	if b, err := strconv.ParseBool(value); err == nil {
+		if !b {
+			return "", true
+		}
+
+		return "true", true
+	}
+
+	t, err := parseVersionDate(value)
+	if err != nil {
+		return "", false
+	}
+
+	// RFC 9745 represents the deprecation date as a structured field date.
+	return "@" + strconv.FormatInt(t.Unix(), 10), true
+}
+
+func parseVersionDate(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+
+	return http.ParseTime(value)
+}
+
+func versionConfigKey(version string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, version)
+}
+
+// versionMiddleware sets deprecation headers for the version and records the per-version request metric.
+func versionMiddleware(policy versionPolicy, m metrics.Manager) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if policy.deprecation != "" {
+				w.Header().Set("Deprecation", policy.deprecation)
+			}
+
+			if policy.sunset != "" {
+				w.Header().Set("Sunset", policy.sunset)
+			}
+
+			if policy.link != "" {
+				w.Header().Add("Link", policy.link)
+			}
+
+			if m != nil {
+				m.IncrementCounter(r.Context(), versionRequestsMetric,
+					"version", policy.version, "deprecated", strconv.FormatBool(policy.deprecation != ""))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
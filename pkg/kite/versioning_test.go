@@ -0,0 +1,141 @@
+package kite
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sllt/kite/pkg/kite/config"
+	kiteHTTP "github.com/sllt/kite/pkg/kite/http"
+	"github.com/sllt/kite/pkg/kite/infra"
+)
+
+func newVersioningTestApp(cfg map[string]string) *App {
+	return &App{
+		httpServer: &httpServer{
+			router:   kiteHTTP.NewRouter(),
+			registry: newRouteRegistry(),
+			port:     8080,
+		},
+		container:      infra.NewContainer(config.NewMockConfig(nil)),
+		Config:         config.NewMockConfig(cfg),
+		httpRegistered: true,
+	}
+}
+
+func TestApp_Version_PrefixesRoutes(t *testing.T) {
+	app := newVersioningTestApp(nil)
+
+	app.Version("v1").GET("/users", func(*Context) (any, error) {
+		return "v1-users", nil
+	})
+	app.Version("/v2/").GET("/users", func(*Context) (any, error) {
+		return "v2-users", nil
+	})
+
+	app.httpServer.registry.compile(app.httpServer.router.Mux(), app.container, 0)
+
+	for _, path := range []string{"/v1/users", "/v2/users"} {
+		rec := httptest.NewRecorder()
+		app.httpServer.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, http.NoBody))
+
+		assert.Equal(t, http.StatusOK, rec.Code, path)
+		assert.Empty(t, rec.Header().Get("Deprecation"), path)
+		assert.Empty(t, rec.Header().Get("Sunset"), path)
+	}
+}
+
+func TestApp_Version_DeprecationHeaders(t *testing.T) {
+	app := newVersioningTestApp(map[string]string{
+		"API_VERSION_V1_DEPRECATED": "2025-01-01T00:00:00Z",
+		"API_VERSION_V1_SUNSET":     "2026-01-01",
+		"API_VERSION_V1_LINK":       "https://example.com/migrate",
+		"API_VERSION_V1_5_SUNSET":   "not-a-date",
+	})
+
+	app.Version("v1").GET("/users", func(*Context) (any, error) {
+		return "users", nil
+	})
+	app.Version("v1.5").GET("/users", func(*Context) (any, error) {
+		return "users", nil
+	})
+
+	app.httpServer.registry.compile(app.httpServer.router.Mux(), app.container, 0)
+
+	rec := httptest.NewRecorder()
+	app.httpServer.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/users", http.NoBody))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "@1735689600", rec.Header().Get("Deprecation"))
+	assert.Equal(t, "Thu, 01 Jan 2026 00:00:00 GMT", rec.Header().Get("Sunset"))
+	assert.Equal(t, `<https://example.com/migrate>; rel="deprecation"`, rec.Header().Get("Link"))
+
+	rec = httptest.NewRecorder()
+	app.httpServer.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1.5/users", http.NoBody))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("Deprecation"))
+	assert.Empty(t, rec.Header().Get("Sunset"))
+}
+
+func TestApp_Version_ReturnsSameGroup(t *testing.T) {
+	app := newVersioningTestApp(map[string]string{"API_VERSION_V1_DEPRECATED": "true"})
+
+	v1 := app.Version("v1")
+	again := app.Version("v1")
+
+	assert.Same(t, v1.node, again.node)
+	assert.Len(t, v1.node.httpMWs, 1, "version middleware must only be attached once")
+}
+
+func TestRouteGroup_Version_Nested(t *testing.T) {
+	app := newVersioningTestApp(map[string]string{"API_VERSION_V2_DEPRECATED": "true"})
+
+	app.Group("/api").Version("v2").GET("/orders", func(*Context) (any, error) {
+		return "orders", nil
+	})
+
+	app.httpServer.registry.compile(app.httpServer.router.Mux(), app.container, 0)
+
+	rec := httptest.NewRecorder()
+	app.httpServer.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v2/orders", http.NoBody))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "true", rec.Header().Get("Deprecation"))
+}
+
+func TestRouteGroup_Version_Empty(t *testing.T) {
+	app := newVersioningTestApp(nil)
+	root := app.rootRouteGroup()
+
+	assert.Same(t, root, root.Version("  "))
+}
+
+func Test_parseDeprecation(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+		valid    bool
+	}{
+		{value: "true", expected: "true", valid: true},
+		{value: "false", expected: "", valid: true},
+		{value: "Wed, 01 Jan 2025 00:00:00 GMT", expected: "@1735689600", valid: true},
+		{value: "2025-01-01", expected: "@1735689600", valid: true},
+		{value: "soon", expected: "", valid: false},
+	}
+
+	for _, tc := range tests {
+		got, ok := parseDeprecation(tc.value)
+
+		assert.Equal(t, tc.expected, got, tc.value)
+		assert.Equal(t, tc.valid, ok, tc.value)
+	}
+}
+
+func Test_versionConfigKey(t *testing.T) {
+	assert.Equal(t, "V1", versionConfigKey("v1"))
+	assert.Equal(t, "V1_2", versionConfigKey("v1.2"))
+	assert.Equal(t, "2024_01_BETA", versionConfigKey("2024-01-beta"))
+}
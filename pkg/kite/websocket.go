@@ -11,6 +11,10 @@ import (
 	"time"
 
 	gWebsocket "github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/sllt/kite/pkg/kite/websocket"
 )
@@ -24,10 +28,43 @@ func (a *App) OverrideWebsocketUpgrader(wsUpgrader websocket.Upgrader) {
 	a.httpServer.ws.WebSocketUpgrader.Upgrader = wsUpgrader
 }
 
+// WebSocketOption configures a WebSocket route registered with App.WebSocket.
+type WebSocketOption func(*webSocketRoute)
+
+type webSocketRoute struct {
+	upgradeAuth websocket.UpgradeAuthFunc
+}
+
+// WithUpgradeAuth authenticates the opening handshake of the WebSocket route with fn, which can inspect its headers,
+// cookies and query params before the connection is upgraded:
+//
+//	app.WebSocket("/ws", handler, kite.WithUpgradeAuth(func(r *http.Request) (any, error) {
+//		return verifyToken(r.URL.Query().Get("token"))
+//	}))
+//
+// An error rejects the upgrade with the status code returned by its StatusCode() int method, or 401 Unauthorized,
+// and the handler is not called. The principal returned by fn is available to the handler, from the first message,
+// with ctx.WebSocketPrincipal().
+func WithUpgradeAuth(fn websocket.UpgradeAuthFunc) WebSocketOption {
+	return func(r *webSocketRoute) {
+		r.upgradeAuth = fn
+	}
+}
+
 // WebSocket registers a handler function for a WebSocket route. This method allows you to define a route handler for
 // WebSocket connections. It internally handles the WebSocket handshake and provides a `websocket.Connection` object
 // within the handler context. User can access the underlying WebSocket connection using `ctx.GetWebsocketConnection()`.
-func (a *App) WebSocket(route string, handler Handler) {
+func (a *App) WebSocket(route string, handler Handler, opts ...WebSocketOption) {
+	var r webSocketRoute
+
+	for _, opt := range opts {
+		opt(&r)
+	}
+
+	if r.upgradeAuth != nil {
+		a.httpServer.ws.SetUpgradeAuth(route, r.upgradeAuth)
+	}
+
 	a.GET(route, func(ctx *Context) (any, error) {
 		connID := ctx.Request.Context().Value(websocket.WSConnectionKey).(string)
 
@@ -97,6 +134,42 @@ func (a *App) handleReconnection(serviceName, url string, headers http.Header, r
 	}()
 }
 
+// WebSocketDial connects to the WebSocket server at url and returns a managed client connection. The client
+// reconnects with an exponential backoff when the connection is lost, keeps it alive with heartbeats and records
+// the app_ws_client_* metrics. The trace of the context is propagated to the server with the opening handshake.
+//
+// The client outlives the request and must be closed by the caller.
+func (c *Context) WebSocketDial(url string, headers http.Header, opts ...websocket.ClientOption) (*websocket.Client, error) {
+	ctx, span := otel.GetTracerProvider().Tracer("kite-websocket").Start(c.Context, "ws-dial",
+		trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+
+	h := headers.Clone()
+	if h == nil {
+		h = http.Header{}
+	}
+
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(h))
+
+	client, err := websocket.Dial(ctx, url, h, c.Container.Logger, c.Metrics(), opts...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		c.Errorf("failed to connect to WebSocket server %s: %v", url, err)
+
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// WebSocketPrincipal returns the principal authenticated by the upgrade hook of the WebSocket route, see
+// WithUpgradeAuth, or nil.
+func (c *Context) WebSocketPrincipal() any {
+	return c.Context.Value(websocket.WSPrincipalKey)
+}
+
 func handleWebSocketConnection(ctx *Context, conn *websocket.Connection, handler Handler) {
 	for {
 		response, err := handler(ctx)
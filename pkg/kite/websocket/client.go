@@ -0,0 +1,404 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Metrics of the client connections. They are registered by the container.
+const (
+	clientDialDuration = "app_ws_client_dial_duration_seconds"
+	clientMessages     = "app_ws_client_messages_total"
+	clientReconnects   = "app_ws_client_reconnects_total"
+)
+
+const (
+	defaultHeartbeatInterval = 30 * time.Second
+	defaultInitialBackoff    = time.Second
+	defaultMaxBackoff        = 30 * time.Second
+	closeWriteTimeout        = time.Second
+	messageBufferSize        = 64
+)
+
+var (
+	// ErrClientClosed is returned by the methods of a Client which has been closed, or which gave up reconnecting.
+	ErrClientClosed = errors.New("websocket client is closed")
+	// ErrClientNotConnected is returned by WriteMessage while the Client is reconnecting.
+	ErrClientNotConnected = errors.New("websocket client is not connected")
+)
+
+// Logger is the logger used by a Client.
+type Logger interface {
+	Debugf(format string, args ...any)
+	Infof(format string, args ...any)
+	Errorf(format string, args ...any)
+}
+
+// Metrics is the metrics manager used by a Client.
+type Metrics interface {
+	IncrementCounter(ctx context.Context, name string, labels ...string)
+	RecordHistogram(ctx context.Context, name string, value float64, labels ...string)
+}
+
+// ClientConfig holds the settings of a Client.
+type ClientConfig struct {
+	// HeartbeatInterval is the interval of the pings sent to the server. The connection is considered lost when
+	// nothing is received from the server for two intervals. Zero disables the heartbeats.
+	HeartbeatInterval time.Duration
+	// InitialBackoff is the delay before the first reconnection attempt. It doubles after each failed attempt,
+	// up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// MaxReconnectAttempts is the number of consecutive failed reconnection attempts after which the client is
+	// closed. Zero retries forever.
+	MaxReconnectAttempts int
+	// HandshakeTimeout bounds the opening handshake of every connection attempt. Zero means no timeout.
+	HandshakeTimeout time.Duration
+}
+
+// ClientOption is a function type that applies a configuration to a Client.
+type ClientOption func(c *ClientConfig)
+
+// WithHeartbeat sets the interval of the pings sent to the server. Zero disables the heartbeats.
+func WithHeartbeat(interval time.Duration) ClientOption {
+	return func(c *ClientConfig) {
+		c.HeartbeatInterval = interval
+	}
+}
+
+// WithReconnectBackoff sets the bounds of the exponential backoff between reconnection attempts.
+func WithReconnectBackoff(initial, maxBackoff time.Duration) ClientOption {
+	return func(c *ClientConfig) {
+		c.InitialBackoff = initial
+		c.MaxBackoff = maxBackoff
+	}
+}
+
+// WithMaxReconnectAttempts sets the number of consecutive failed reconnection attempts after which the client
+// is closed. Zero retries forever.
+func WithMaxReconnectAttempts(n int) ClientOption {
+	return func(c *ClientConfig) {
+		c.MaxReconnectAttempts = n
+	}
+}
+
+// WithClientHandshakeTimeout sets the timeout of the opening handshake of every connection attempt.
+func WithClientHandshakeTimeout(t time.Duration) ClientOption {
+	return func(c *ClientConfig) {
+		c.HandshakeTimeout = t
+	}
+}
+
+type clientMessage struct {
+	messageType int
+	data        []byte
+}
+
+// Client is a managed connection to a WebSocket server. It reconnects with an exponential backoff when the
+// connection is lost, keeps it alive with heartbeats and records the metrics of the connection.
+//
+// Messages received while the application is not reading are buffered, so ReadMessage does not miss the
+// messages received around a reconnection. Messages written while the client is reconnecting fail.
+type Client struct {
+	url     string
+	headers http.Header
+	config  ClientConfig
+	dialer  *websocket.Dialer
+	logger  Logger
+	metrics Metrics
+
+	mu   sync.RWMutex
+	conn *Connection
+	err  error
+
+	messages  chan clientMessage
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Dial connects to the WebSocket server at url, sending headers with the opening handshake, and returns the
+// managed client of the connection. ctx only bounds the first connection attempt.
+func Dial(ctx context.Context, url string, headers http.Header, logger Logger, metrics Metrics,
+	opts ...ClientOption) (*Client, error) {
+	config := ClientConfig{
+		HeartbeatInterval: defaultHeartbeatInterval,
+		InitialBackoff:    defaultInitialBackoff,
+		MaxBackoff:        defaultMaxBackoff,
+	}
+
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	dialer := *websocket.DefaultDialer
+	dialer.HandshakeTimeout = config.HandshakeTimeout
+
+	c := &Client{
+		url:      url,
+		headers:  headers,
+		config:   config,
+		dialer:   &dialer,
+		logger:   logger,
+		metrics:  metrics,
+		messages: make(chan clientMessage, messageBufferSize),
+		done:     make(chan struct{}),
+	}
+
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.conn = conn
+
+	go c.run(conn)
+
+	return c, nil
+}
+
+// ReadMessage returns the next message received from the server. It blocks until a message is received,
+// ctx is done or the client is closed.
+func (c *Client) ReadMessage(ctx context.Context) (messageType int, data []byte, err error) {
+	select {
+	case msg := <-c.messages:
+		return msg.messageType, msg.data, nil
+	case <-ctx.Done():
+		return 0, nil, ctx.Err()
+	case <-c.done:
+		return 0, nil, c.closeErr()
+	}
+}
+
+// WriteMessage writes a message to the server. It fails if the client is reconnecting or closed.
+func (c *Client) WriteMessage(messageType int, data []byte) error {
+	select {
+	case <-c.done:
+		return c.closeErr()
+	default:
+	}
+
+	c.mu.RLock()
+	conn := c.conn
+	c.mu.RUnlock()
+
+	if conn == nil {
+		return ErrClientNotConnected
+	}
+
+	if err := conn.WriteMessage(messageType, data); err != nil {
+		return err
+	}
+
+	c.recordMessage("sent")
+
+	return nil
+}
+
+// Close sends a close message to the server and closes the connection. The client does not reconnect afterward.
+func (c *Client) Close() error {
+	var err error
+
+	c.closeOnce.Do(func() {
+		close(c.done)
+
+		c.mu.Lock()
+		conn := c.conn
+		c.conn = nil
+		c.mu.Unlock()
+
+		if conn == nil {
+			return
+		}
+
+		_ = conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(closeWriteTimeout))
+
+		err = conn.Close()
+	})
+
+	return err
+}
+
+// closeErr returns the error which closed the client, if it gave up reconnecting, or ErrClientClosed.
+func (c *Client) closeErr() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.err != nil {
+		return c.err
+	}
+
+	return ErrClientClosed
+}
+
+func (c *Client) dial(ctx context.Context) (*Connection, error) {
+	start := time.Now()
+
+	conn, resp, err := c.dialer.DialContext(ctx, c.url, c.headers)
+	if resp != nil {
+		resp.Body.Close()
+	}
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	if c.metrics != nil {
+		c.metrics.RecordHistogram(context.Background(), clientDialDuration, time.Since(start).Seconds(),
+			"url", c.url, "status", status)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Connection{Conn: conn}, nil
+}
+
+// run reads the messages of conn, and of the connections replacing it, until the client is closed.
+func (c *Client) run(conn *Connection) {
+	for {
+		err := c.read(conn)
+
+		select {
+		case <-c.done:
+			return
+		default:
+		}
+
+		c.logger.Errorf("websocket connection to %s lost: %v", c.url, err)
+
+		conn.Close()
+
+		if conn = c.reconnect(); conn == nil {
+			return
+		}
+	}
+}
+
+// read dispatches the messages of conn until it fails, sending heartbeats meanwhile.
+func (c *Client) read(conn *Connection) error {
+	if interval := c.config.HeartbeatInterval; interval > 0 {
+		stop := make(chan struct{})
+		defer close(stop)
+
+		// any message of the server, including the pongs, proves that the connection is alive.
+		_ = conn.SetReadDeadline(time.Now().Add(2 * interval))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(2 * interval))
+		})
+
+		go c.heartbeat(conn, interval, stop)
+	}
+
+	for {
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		if interval := c.config.HeartbeatInterval; interval > 0 {
+			_ = conn.SetReadDeadline(time.Now().Add(2 * interval))
+		}
+
+		c.recordMessage("received")
+
+		select {
+		case c.messages <- clientMessage{messageType: messageType, data: data}:
+		case <-c.done:
+			return ErrClientClosed
+		}
+	}
+}
+
+func (c *Client) heartbeat(conn *Connection, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(interval)); err != nil {
+				c.logger.Debugf("failed to send heartbeat to %s: %v", c.url, err)
+			}
+		}
+	}
+}
+
+// reconnect dials the server with an exponential backoff until it succeeds, the client is closed or the attempts
+// are exhausted, in which case it closes the client and returns nil.
+func (c *Client) reconnect() *Connection {
+	c.mu.Lock()
+	c.conn = nil
+	c.mu.Unlock()
+
+	var (
+		backoff = c.config.InitialBackoff
+		err     error
+	)
+
+	for attempt := 1; c.config.MaxReconnectAttempts == 0 || attempt <= c.config.MaxReconnectAttempts; attempt++ {
+		select {
+		case <-c.done:
+			return nil
+		case <-time.After(backoff):
+		}
+
+		c.logger.Debugf("reconnecting to %s, attempt %d", c.url, attempt)
+
+		var conn *Connection
+
+		conn, err = c.dial(context.Background())
+		if err == nil {
+			if c.metrics != nil {
+				c.metrics.IncrementCounter(context.Background(), clientReconnects, "url", c.url)
+			}
+
+			c.mu.Lock()
+			c.conn = conn
+			c.mu.Unlock()
+
+			// the client may have been closed while dialing.
+			select {
+			case <-c.done:
+				conn.Close()
+
+				return nil
+			default:
+			}
+
+			c.logger.Infof("reconnected to %s", c.url)
+
+			return conn
+		}
+
+		c.logger.Errorf("failed to reconnect to %s: %v", c.url, err)
+
+		backoff = min(2*backoff, c.config.MaxBackoff)
+	}
+
+	c.mu.Lock()
+	c.err = fmt.Errorf("%w: gave up reconnecting: %w", ErrClientClosed, err)
+	c.mu.Unlock()
+
+	c.logger.Errorf("giving up reconnecting to %s after %d attempts", c.url, c.config.MaxReconnectAttempts)
+
+	_ = c.Close()
+
+	return nil
+}
+
+func (c *Client) recordMessage(direction string) {
+	if c.metrics != nil {
+		c.metrics.IncrementCounter(context.Background(), clientMessages, "url", c.url, "direction", direction)
+	}
+}
@@ -0,0 +1,208 @@
+package websocket
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testLogger struct{}
+
+func (testLogger) Debugf(string, ...any) {}
+func (testLogger) Infof(string, ...any)  {}
+func (testLogger) Errorf(string, ...any) {}
+
+type testMetrics struct {
+	mu       sync.Mutex
+	counters map[string][][]string
+	hists    map[string][][]string
+}
+
+func newTestMetrics() *testMetrics {
+	return &testMetrics{counters: make(map[string][][]string), hists: make(map[string][][]string)}
+}
+
+func (m *testMetrics) IncrementCounter(_ context.Context, name string, labels ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.counters[name] = append(m.counters[name], labels)
+}
+
+func (m *testMetrics) RecordHistogram(_ context.Context, name string, _ float64, labels ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.hists[name] = append(m.hists[name], labels)
+}
+
+func (m *testMetrics) counter(name string) [][]string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.counters[name]
+}
+
+func wsURL(server *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func TestDial_ReadWriteClose(t *testing.T) {
+	server := setupWebSocketServer(t, func(conn *Connection) {
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			_ = conn.WriteMessage(messageType, data)
+		}
+	})
+	defer server.Close()
+
+	metrics := newTestMetrics()
+
+	client, err := Dial(t.Context(), wsURL(server), http.Header{"X-Test": {"1"}}, testLogger{}, metrics)
+	require.NoError(t, err)
+
+	require.NoError(t, client.WriteMessage(TextMessage, []byte("hello")))
+
+	messageType, data, err := client.ReadMessage(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, TextMessage, messageType)
+	assert.Equal(t, "hello", string(data))
+
+	assert.Equal(t, [][]string{{"url", wsURL(server), "status", "success"}}, metrics.hists[clientDialDuration])
+	assert.Len(t, metrics.counter(clientMessages), 2)
+
+	require.NoError(t, client.Close())
+
+	_, _, err = client.ReadMessage(t.Context())
+	require.ErrorIs(t, err, ErrClientClosed)
+	require.ErrorIs(t, client.WriteMessage(TextMessage, []byte("hello")), ErrClientClosed)
+}
+
+func TestDial_Error(t *testing.T) {
+	metrics := newTestMetrics()
+
+	client, err := Dial(t.Context(), "ws://127.0.0.1:1/ws", nil, testLogger{}, metrics)
+
+	assert.Nil(t, client)
+	require.Error(t, err)
+	assert.Equal(t, [][]string{{"url", "ws://127.0.0.1:1/ws", "status", "error"}}, metrics.hists[clientDialDuration])
+}
+
+func TestClient_ReadMessage_ContextDone(t *testing.T) {
+	server := setupWebSocketServer(t, func(conn *Connection) {
+		_, _, _ = conn.ReadMessage()
+	})
+	defer server.Close()
+
+	client, err := Dial(t.Context(), wsURL(server), nil, testLogger{}, nil)
+	require.NoError(t, err)
+
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+	defer cancel()
+
+	_, _, err = client.ReadMessage(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestClient_Reconnect(t *testing.T) {
+	var connections atomic.Int32
+
+	server := setupWebSocketServer(t, func(conn *Connection) {
+		// the first connection is dropped by the server after its first message.
+		if connections.Add(1) == 1 {
+			_ = conn.WriteMessage(TextMessage, []byte("first"))
+
+			return
+		}
+
+		_ = conn.WriteMessage(TextMessage, []byte("second"))
+		_, _, _ = conn.ReadMessage()
+	})
+	defer server.Close()
+
+	metrics := newTestMetrics()
+
+	client, err := Dial(t.Context(), wsURL(server), nil, testLogger{}, metrics,
+		WithReconnectBackoff(10*time.Millisecond, 50*time.Millisecond))
+	require.NoError(t, err)
+
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+
+	for _, want := range []string{"first", "second"} {
+		_, data, err := client.ReadMessage(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, want, string(data))
+	}
+
+	assert.Equal(t, [][]string{{"url", wsURL(server)}}, metrics.counter(clientReconnects))
+}
+
+func TestClient_GivesUpReconnecting(t *testing.T) {
+	server := setupWebSocketServer(t, func(*Connection) {})
+
+	client, err := Dial(t.Context(), wsURL(server), nil, testLogger{}, nil,
+		WithReconnectBackoff(5*time.Millisecond, 10*time.Millisecond), WithMaxReconnectAttempts(2))
+	require.NoError(t, err)
+
+	server.Close()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+
+	_, _, err = client.ReadMessage(ctx)
+	require.ErrorIs(t, err, ErrClientClosed)
+	assert.Contains(t, err.Error(), "gave up reconnecting")
+}
+
+func TestClient_Heartbeat(t *testing.T) {
+	pings := make(chan struct{}, 1)
+
+	server := setupWebSocketServer(t, func(conn *Connection) {
+		conn.SetPingHandler(func(string) error {
+			select {
+			case pings <- struct{}{}:
+			default:
+			}
+
+			return conn.WriteControl(websocket.PongMessage, nil, time.Now().Add(time.Second))
+		})
+
+		_, _, _ = conn.ReadMessage()
+	})
+	defer server.Close()
+
+	client, err := Dial(t.Context(), wsURL(server), nil, testLogger{}, nil, WithHeartbeat(10*time.Millisecond))
+	require.NoError(t, err)
+
+	defer client.Close()
+
+	select {
+	case <-pings:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no heartbeat received by the server")
+	}
+}
+
+func TestClient_WriteMessage_NotConnected(t *testing.T) {
+	client := &Client{done: make(chan struct{})}
+
+	require.ErrorIs(t, client.WriteMessage(TextMessage, []byte("hello")), ErrClientNotConnected)
+}
@@ -14,8 +14,18 @@ import (
 // WSKey defines the key type for WSConnectionKey.
 type WSKey string
 
-// WSConnectionKey is a key constant that stores the connection id in the request context.
-const WSConnectionKey WSKey = "ws-connection-key"
+const (
+	// WSConnectionKey is a key constant that stores the connection id in the request context.
+	WSConnectionKey WSKey = "ws-connection-key"
+	// WSPrincipalKey is a key constant that stores the principal authenticated by the upgrade hook of the route in the
+	// request context.
+	WSPrincipalKey WSKey = "ws-principal"
+)
+
+// UpgradeAuthFunc authenticates the opening handshake of a WebSocket connection, from its headers, cookies or query
+// params, and returns the authenticated principal. An error rejects the upgrade, with the status code returned by its
+// StatusCode() int method, or 401 Unauthorized.
+type UpgradeAuthFunc func(r *http.Request) (principal any, err error)
 
 // Connection is a wrapper for gorilla websocket connection.
 type Connection struct {
@@ -116,6 +126,9 @@ func (*Connection) HostName() string {
 type Manager struct {
 	ConnectionHub
 	WebSocketUpgrader *WSUpgrader
+
+	authMu      sync.RWMutex
+	upgradeAuth map[string]UpgradeAuthFunc
 }
 
 // ConnectionHub stores and provide functionality to work with
@@ -193,6 +206,26 @@ func (ws *Manager) CloseConnection(connID string) {
 	}
 }
 
+// SetUpgradeAuth sets the hook authenticating the upgrades of the WebSocket route, matched against the route pattern.
+func (ws *Manager) SetUpgradeAuth(route string, fn UpgradeAuthFunc) {
+	ws.authMu.Lock()
+	defer ws.authMu.Unlock()
+
+	if ws.upgradeAuth == nil {
+		ws.upgradeAuth = make(map[string]UpgradeAuthFunc)
+	}
+
+	ws.upgradeAuth[route] = fn
+}
+
+// UpgradeAuth returns the hook authenticating the upgrades of the WebSocket route, or nil.
+func (ws *Manager) UpgradeAuth(route string) UpgradeAuthFunc {
+	ws.authMu.RLock()
+	defer ws.authMu.RUnlock()
+
+	return ws.upgradeAuth[route]
+}
+
 func (*Connection) Params(string) []string {
 	return nil
 }
@@ -1458,3 +1458,17 @@ func createLargeJSON() []byte {
 
 	return jsonData
 }
+
+func TestManager_UpgradeAuth(t *testing.T) {
+	manager := New()
+
+	assert.Nil(t, manager.UpgradeAuth("/ws"), "routes have no upgrade hook by default")
+
+	manager.SetUpgradeAuth("/ws", func(*http.Request) (any, error) { return "alice", nil })
+
+	principal, err := manager.UpgradeAuth("/ws")(httptest.NewRequest(http.MethodGet, "/ws", http.NoBody))
+
+	require.NoError(t, err)
+	assert.Equal(t, "alice", principal)
+	assert.Nil(t, manager.UpgradeAuth("/chat"))
+}
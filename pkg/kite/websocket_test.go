@@ -7,17 +7,23 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 
+	"github.com/sllt/kite/pkg/kite/infra"
 	"github.com/sllt/kite/pkg/kite/testutil"
 )
 
-var errWebSocketNotReady = errors.New("websocket server not ready")
+var (
+	errWebSocketNotReady = errors.New("websocket server not ready")
+	errInvalidToken      = errors.New("invalid token")
+)
 
 func Test_WebSocket_Success(t *testing.T) {
 	testutil.NewServerConfigs(t)
@@ -70,6 +76,55 @@ func Test_WebSocket_Success(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func Test_WebSocket_UpgradeAuth(t *testing.T) {
+	testutil.NewServerConfigs(t)
+
+	app := New()
+
+	server := httptest.NewServer(app.httpServer.router)
+	defer server.Close()
+
+	app.WebSocket("/ws", func(ctx *Context) (any, error) {
+		var message string
+
+		if err := ctx.Bind(&message); err != nil {
+			return nil, err
+		}
+
+		return fmt.Sprintf("%v: %s", ctx.WebSocketPrincipal(), message), nil
+	}, WithUpgradeAuth(func(r *http.Request) (any, error) {
+		if token := r.URL.Query().Get("token"); token != "" {
+			return token, nil
+		}
+
+		return nil, errInvalidToken
+	}))
+
+	go app.Run()
+
+	time.Sleep(100 * time.Millisecond)
+
+	wsURL := "ws" + server.URL[len("http"):] + "/ws"
+
+	_, resp, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	require.ErrorIs(t, err, websocket.ErrBadHandshake)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp.Body.Close()
+
+	ws, resp, err := websocket.DefaultDialer.Dial(wsURL+"?token=alice", nil)
+	require.NoError(t, err)
+
+	defer ws.Close()
+	defer resp.Body.Close()
+
+	require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte("hello")))
+
+	_, message, err := ws.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, "alice: hello", string(message))
+}
+
 func Test_AddWSService(t *testing.T) {
 	port := testutil.GetFreePort(t)
 	t.Setenv("HTTP_PORT", fmt.Sprint(port))
@@ -105,6 +160,41 @@ func Test_AddWSService(t *testing.T) {
 	require.NotNil(t, conn, "Connection should be registered")
 }
 
+func TestContext_WebSocketDial(t *testing.T) {
+	headers := make(chan http.Header, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header
+
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		_ = conn.WriteMessage(websocket.TextMessage, []byte("tick"))
+		_, _, _ = conn.ReadMessage()
+	}))
+	defer server.Close()
+
+	mockContainer, mocks := infra.NewMockContainer(t)
+	mocks.Metrics.EXPECT().RecordHistogram(gomock.Any(), "app_ws_client_dial_duration_seconds", gomock.Any(), gomock.Any()).AnyTimes()
+	mocks.Metrics.EXPECT().IncrementCounter(gomock.Any(), "app_ws_client_messages_total", gomock.Any()).AnyTimes()
+
+	ctx := &Context{Context: t.Context(), Container: mockContainer}
+
+	client, err := ctx.WebSocketDial("ws"+strings.TrimPrefix(server.URL, "http"), http.Header{"X-Feed": {"prices"}})
+	require.NoError(t, err)
+
+	defer client.Close()
+
+	assert.Equal(t, "prices", (<-headers).Get("X-Feed"))
+
+	_, data, err := client.ReadMessage(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "tick", string(data))
+}
+
 func waitForWebSocketReady(wsURL string, timeout time.Duration) error {
 	deadline := time.Now().Add(timeout)
 